@@ -0,0 +1,117 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package updater
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+const (
+	// signatureSuffix is appended to the path of the location of an update tarball to get
+	// the location of its detached signature
+	signatureSuffix = ".sig"
+	// maxSignatureSize bounds the size of the detached signature read from the response
+	maxSignatureSize = 16 * 1024
+)
+
+// loadSigningPublicKey reads the PEM encoded PKIX public key at path. Only RSA and ECDSA
+// keys are supported.
+func loadSigningPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read update signing public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("update signing public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse update signing public key: %w", err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported update signing public key type %T", key)
+	}
+}
+
+// verifySignature verifies signature is a signature of the SHA-256 digest by the private key
+// of key, as created by `openssl dgst -sha256 -sign`
+func verifySignature(key crypto.PublicKey, digest, signature []byte) error {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature); err != nil {
+			return errors.New("Signature verification failed")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, signature) {
+			return errors.New("Signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported update signing public key type %T", key)
+	}
+}
+
+// signatureLocation returns the location of the detached signature of the update tarball
+// at location, i.e. the same URL with the suffix appended to its path. The query is dropped:
+// it only authorizes the download of the tarball, e.g. a presigned URL signs the path of the
+// tarball, so it can't be reused for the signature.
+func signatureLocation(location string) (string, error) {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	parsed.Path += signatureSuffix
+	if parsed.RawPath != "" {
+		parsed.RawPath += signatureSuffix
+	}
+	parsed.RawQuery = ""
+	parsed.ForceQuery = false
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String(), nil
+}
+
+// downloadSignature downloads the detached signature of the update tarball at location
+func (u *updater) downloadSignature(location string) ([]byte, error) {
+	sigLocation, err := signatureLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.httpclient.Get(sigLocation)
+	if resp != nil && resp.Body != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to download signature: unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize))
+}
@@ -14,6 +14,7 @@
 package updater
 
 import (
+	"crypto"
 	"crypto/sha256"
 	"errors"
 	"fmt"
@@ -188,6 +189,15 @@ func (u *updater) download(info *ecsacs.UpdateInfo) (err error) {
 	if info.Signature == nil {
 		return errors.New("No signature given")
 	}
+	// The key is loaded before the download so that a misconfigured key fails the update
+	// early, rather than letting an unverified tarball through
+	var signingKey crypto.PublicKey
+	if u.config.UpdateSigningPublicKey != "" {
+		signingKey, err = loadSigningPublicKey(u.config.UpdateSigningPublicKey)
+		if err != nil {
+			return err
+		}
+	}
 	resp, err := u.httpclient.Get(*info.Location)
 	if resp != nil && resp.Body != nil {
 		defer resp.Body.Close()
@@ -222,6 +232,17 @@ func (u *updater) download(info *ecsacs.UpdateInfo) (err error) {
 		return errors.New("Hashsum validation failed")
 	}
 
+	if signingKey != nil {
+		var signature []byte
+		signature, err = u.downloadSignature(*info.Location)
+		if err != nil {
+			return err
+		}
+		if err = verifySignature(signingKey, shasum, signature); err != nil {
+			return err
+		}
+	}
+
 	err = writeFile(filepath.Join(u.config.UpdateDownloadDir, desiredImageFile), []byte(outFileBasename+"\n"), 0644)
 	return err
 }
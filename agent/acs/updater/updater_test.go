@@ -18,9 +18,18 @@ package updater
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -482,3 +491,183 @@ func TestValidationError(t *testing.T) {
 
 	assert.Equal(t, "update-tar-data", writtenFile.String(), "incorrect data written")
 }
+
+// writeSigningPublicKey writes the PEM encoded public key of key to a temporary file and
+// returns its path
+func writeSigningPublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "update-signing-key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644))
+	return path
+}
+
+func stageSignedUpdate(u *updater, location string) {
+	u.stageUpdateHandler()(&ecsacs.StageUpdateMessage{
+		ClusterArn:           ptr("cluster").(*string),
+		ContainerInstanceArn: ptr("containerInstance").(*string),
+		MessageId:            ptr("StageMID").(*string),
+		UpdateInfo: &ecsacs.UpdateInfo{
+			Location:  ptr(location).(*string),
+			Signature: ptr("6caeef375a080e3241781725b357890758d94b15d7ce63f6b2ff1cb5589f2007").(*string),
+		},
+	})
+}
+
+func TestSignedUpdate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("update-tar-data"))
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+	tamperedSignature := append([]byte{}, ecdsaSignature...)
+	tamperedSignature[len(tamperedSignature)-1] ^= 0xff
+
+	testCases := []struct {
+		name      string
+		publicKey crypto.PublicKey
+		signature []byte
+		ack       bool
+	}{
+		{name: "rsa", publicKey: &rsaKey.PublicKey, signature: rsaSignature, ack: true},
+		{name: "ecdsa", publicKey: &ecdsaKey.PublicKey, signature: ecdsaSignature, ack: true},
+		{name: "signed by another key", publicKey: &rsaKey.PublicKey, signature: ecdsaSignature},
+		{name: "tampered signature", publicKey: &ecdsaKey.PublicKey, signature: tamperedSignature},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, ctrl, mockacs, mockhttp := mocks(t, &config.Config{
+				UpdatesEnabled:         config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled},
+				UpdateDownloadDir:      filepath.Clean("/tmp/test/"),
+				UpdateSigningPublicKey: writeSigningPublicKey(t, tc.publicKey),
+			})
+			defer ctrl.Finish()
+			defer mockOS()()
+
+			var response gomock.Matcher = gomock.Eq(&ecsacs.AckRequest{
+				Cluster:           ptr("cluster").(*string),
+				ContainerInstance: ptr("containerInstance").(*string),
+				MessageId:         ptr("StageMID").(*string),
+			})
+			if !tc.ack {
+				response = &nackRequestMatcher{&ecsacs.NackRequest{
+					MessageId: ptr("StageMID").(*string),
+					Reason:    ptr("Unable to download: Signature verification failed").(*string),
+				}}
+			}
+			gomock.InOrder(
+				mockhttp.EXPECT().RoundTrip(mock_http.NewHTTPSimpleMatcher("GET", "https://s3.amazonaws.com/amazon-ecs-agent/update.tar")).Return(mock_http.SuccessResponse("update-tar-data"), nil),
+				mockhttp.EXPECT().RoundTrip(mock_http.NewHTTPSimpleMatcher("GET", "https://s3.amazonaws.com/amazon-ecs-agent/update.tar.sig")).Return(mock_http.SuccessResponse(string(tc.signature)), nil),
+				mockacs.EXPECT().MakeRequest(response),
+			)
+
+			stageSignedUpdate(u, "https://s3.amazonaws.com/amazon-ecs-agent/update.tar")
+			assert.Equal(t, tc.ack, u.stage == updateDownloaded)
+		})
+	}
+}
+
+func TestSignatureLocation(t *testing.T) {
+	for location, expected := range map[string]string{
+		"https://s3.amazonaws.com/amazon-ecs-agent/update.tar":                             "https://s3.amazonaws.com/amazon-ecs-agent/update.tar.sig",
+		"https://bucket.s3.amazonaws.com/update.tar?X-Amz-Expires=300&X-Amz-Signature=abc": "https://bucket.s3.amazonaws.com/update.tar.sig",
+		"https://s3.amazonaws.com/amazon-ecs-agent/update%2Bv1.tar":                        "https://s3.amazonaws.com/amazon-ecs-agent/update%2Bv1.tar.sig",
+	} {
+		actual, err := signatureLocation(location)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+	_, err := signatureLocation("https://s3.amazonaws.com/%zz")
+	assert.Error(t, err)
+}
+
+// TestSignedUpdatePresignedLocation tests that the signature of a tarball at a presigned URL is
+// downloaded without the query of the URL, whose signature only covers the path of the tarball.
+func TestSignedUpdatePresignedLocation(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("update-tar-data"))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	u, ctrl, mockacs, mockhttp := mocks(t, &config.Config{
+		UpdatesEnabled:         config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled},
+		UpdateDownloadDir:      filepath.Clean("/tmp/test/"),
+		UpdateSigningPublicKey: writeSigningPublicKey(t, &key.PublicKey),
+	})
+	defer ctrl.Finish()
+	defer mockOS()()
+
+	location := "https://bucket.s3.us-west-2.amazonaws.com/update.tar?X-Amz-Algorithm=AWS4-HMAC-SHA256" +
+		"&X-Amz-Credential=AKIDEXAMPLE%2F20240101%2Fus-west-2%2Fs3%2Faws4_request&X-Amz-Date=20240101T000000Z" +
+		"&X-Amz-Expires=300&X-Amz-SignedHeaders=host&X-Amz-Signature=abc"
+	gomock.InOrder(
+		mockhttp.EXPECT().RoundTrip(mock_http.NewHTTPSimpleMatcher("GET", location)).Return(mock_http.SuccessResponse("update-tar-data"), nil),
+		mockhttp.EXPECT().RoundTrip(mock_http.NewHTTPSimpleMatcher("GET", "https://bucket.s3.us-west-2.amazonaws.com/update.tar.sig")).Return(mock_http.SuccessResponse(string(signature)), nil),
+		mockacs.EXPECT().MakeRequest(&ecsacs.AckRequest{
+			Cluster:           ptr("cluster").(*string),
+			ContainerInstance: ptr("containerInstance").(*string),
+			MessageId:         ptr("StageMID").(*string),
+		}),
+	)
+
+	stageSignedUpdate(u, location)
+	assert.Equal(t, updateDownloaded, u.stage)
+}
+
+func TestSignedUpdateMissingSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	u, ctrl, mockacs, mockhttp := mocks(t, &config.Config{
+		UpdatesEnabled:         config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled},
+		UpdateDownloadDir:      filepath.Clean("/tmp/test/"),
+		UpdateSigningPublicKey: writeSigningPublicKey(t, &key.PublicKey),
+	})
+	defer ctrl.Finish()
+	defer mockOS()()
+
+	gomock.InOrder(
+		mockhttp.EXPECT().RoundTrip(mock_http.NewHTTPSimpleMatcher("GET", "https://s3.amazonaws.com/amazon-ecs-agent/update.tar")).Return(mock_http.SuccessResponse("update-tar-data"), nil),
+		mockhttp.EXPECT().RoundTrip(mock_http.NewHTTPSimpleMatcher("GET", "https://s3.amazonaws.com/amazon-ecs-agent/update.tar.sig")).Return(&http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewReader(nil)),
+		}, nil),
+		mockacs.EXPECT().MakeRequest(&nackRequestMatcher{&ecsacs.NackRequest{
+			MessageId: ptr("StageMID").(*string),
+		}}),
+	)
+
+	stageSignedUpdate(u, "https://s3.amazonaws.com/amazon-ecs-agent/update.tar")
+	assert.Equal(t, updateNone, u.stage)
+}
+
+func TestSignedUpdateInvalidPublicKey(t *testing.T) {
+	invalidKey := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidKey, []byte("not a key"), 0644))
+
+	for name, keyPath := range map[string]string{
+		"missing": filepath.Join(t.TempDir(), "missing.pem"),
+		"invalid": invalidKey,
+	} {
+		t.Run(name, func(t *testing.T) {
+			u, ctrl, mockacs, _ := mocks(t, &config.Config{
+				UpdatesEnabled:         config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled},
+				UpdateDownloadDir:      filepath.Clean("/tmp/test/"),
+				UpdateSigningPublicKey: keyPath,
+			})
+			defer ctrl.Finish()
+			defer mockOS()()
+
+			// The update is rejected without downloading the tarball
+			mockacs.EXPECT().MakeRequest(&nackRequestMatcher{&ecsacs.NackRequest{
+				MessageId: ptr("StageMID").(*string),
+			}})
+
+			stageSignedUpdate(u, "https://s3.amazonaws.com/amazon-ecs-agent/update.tar")
+			assert.Equal(t, updateNone, u.stage)
+		})
+	}
+}
@@ -664,6 +664,15 @@ func (c *Container) RestartPolicyEnabled() bool {
 	return c.RestartPolicy.Enabled
 }
 
+// HasPendingRestart returns whether a restart of the container is being delayed by
+// the restart backoff
+func (c *Container) HasPendingRestart() bool {
+	if !c.RestartPolicyEnabled() || c.RestartTracker == nil {
+		return false
+	}
+	return !c.RestartTracker.GetNextRestartAt().IsZero()
+}
+
 // AWSLogAuthExecutionRole returns true if the auth is by execution role
 func (c *Container) AWSLogAuthExecutionRole() bool {
 	return c.LogsAuthStrategy == awslogsAuthExecutionRole
@@ -72,3 +72,21 @@ func PortBindingFromDockerPortBinding(dockerPortBindings nat.PortMap) ([]PortBin
 	}
 	return portBindings, nil
 }
+
+// PortBindingsEqual returns true if both slices contain the same port bindings, in any order
+func PortBindingsEqual(a, b []PortBinding) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[PortBinding]int, len(a))
+	for _, binding := range a {
+		counts[binding]++
+	}
+	for _, binding := range b {
+		if counts[binding] == 0 {
+			return false
+		}
+		counts[binding]--
+	}
+	return true
+}
@@ -23,6 +23,7 @@ import (
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 
 	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestPortBindingFromDockerPortBinding(t *testing.T) {
@@ -128,3 +129,15 @@ func TestPortBindingErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestPortBindingsEqual(t *testing.T) {
+	http := PortBinding{ContainerPort: 80, HostPort: 32768, BindIP: "0.0.0.0", Protocol: TransportProtocolTCP}
+	https := PortBinding{ContainerPort: 443, HostPort: 32769, BindIP: "0.0.0.0", Protocol: TransportProtocolTCP}
+	remapped := PortBinding{ContainerPort: 80, HostPort: 32770, BindIP: "0.0.0.0", Protocol: TransportProtocolTCP}
+
+	assert.True(t, PortBindingsEqual(nil, []PortBinding{}))
+	assert.True(t, PortBindingsEqual([]PortBinding{http, https}, []PortBinding{https, http}))
+	assert.False(t, PortBindingsEqual([]PortBinding{http, https}, []PortBinding{remapped, https}))
+	assert.False(t, PortBindingsEqual([]PortBinding{http, http}, []PortBinding{http, https}))
+	assert.False(t, PortBindingsEqual([]PortBinding{http}, []PortBinding{http, https}))
+}
@@ -0,0 +1,217 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/docker/go-units"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+)
+
+// Docker labels of the containers of a task that set the task-level resource controls of the
+// task cgroup. The device throttles use the syntax of the corresponding docker run options,
+// e.g. "/dev/nvme1n1:10mb" for bytes per second and "/dev/nvme1n1:1000" for IO per second,
+// and accept a comma separated list of devices.
+const (
+	TaskBlkioWeightLabel     = "com.amazonaws.ecs.task-blkio-weight"
+	TaskDeviceReadBpsLabel   = "com.amazonaws.ecs.task-device-read-bps"
+	TaskDeviceWriteBpsLabel  = "com.amazonaws.ecs.task-device-write-bps"
+	TaskDeviceReadIOpsLabel  = "com.amazonaws.ecs.task-device-read-iops"
+	TaskDeviceWriteIOpsLabel = "com.amazonaws.ecs.task-device-write-iops"
+	TaskPidsLimitLabel       = "com.amazonaws.ecs.task-pids-limit"
+
+	minimumBlkioWeight = 10
+	maximumBlkioWeight = 1000
+	maximumPidsLimit   = 4194304
+)
+
+var taskResourceControlLabels = []string{
+	TaskBlkioWeightLabel,
+	TaskDeviceReadBpsLabel,
+	TaskDeviceWriteBpsLabel,
+	TaskDeviceReadIOpsLabel,
+	TaskDeviceWriteIOpsLabel,
+	TaskPidsLimitLabel,
+}
+
+// sysBlockDir lists the block devices of the host. The devices of the host aren't in /dev in
+// the agent container, but sysfs doesn't namespace block devices.
+var sysBlockDir = "/sys/class/block"
+
+var resolveDevice = blockDeviceNumbers
+
+// blockDeviceNumbers returns the major and minor numbers of the host block device at path,
+// which must be the kernel name of the device, e.g. /dev/nvme1n1.
+func blockDeviceNumbers(path string) (int64, int64, error) {
+	name := strings.TrimPrefix(path, "/dev/")
+	if name == "" || strings.Contains(name, "/") {
+		return 0, 0, errors.Errorf("%s is not the kernel name of a block device, such as /dev/nvme1n1", path)
+	}
+	data, err := os.ReadFile(filepath.Join(sysBlockDir, name, "dev"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, errors.Errorf("block device %s not found on the host", path)
+		}
+		return 0, 0, err
+	}
+	majorValue, minorValue, found := strings.Cut(strings.TrimSpace(string(data)), ":")
+	if !found {
+		return 0, 0, errors.Errorf("unexpected device number %q for block device %s", string(data), path)
+	}
+	major, err := strconv.ParseInt(majorValue, 10, 64)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "unexpected major number for block device %s", path)
+	}
+	minor, err := strconv.ParseInt(minorValue, 10, 64)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "unexpected minor number for block device %s", path)
+	}
+	return major, minor, nil
+}
+
+// resourceControlLabels returns the values of the resource control labels set by the
+// containers of the task. A label may be set by several containers as long as they agree
+// on its value.
+func (task *Task) resourceControlLabels() (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, container := range task.Containers {
+		if container.DockerConfig.Config == nil {
+			continue
+		}
+		var containerConfig struct {
+			Labels map[string]string `json:"Labels"`
+		}
+		if err := json.Unmarshal([]byte(aws.ToString(container.DockerConfig.Config)), &containerConfig); err != nil {
+			return nil, errors.Wrapf(err, "unable to decode docker config of container %s", container.Name)
+		}
+		for _, label := range taskResourceControlLabels {
+			value, ok := containerConfig.Labels[label]
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			if existing, ok := labels[label]; ok && existing != value {
+				return nil, errors.Errorf("conflicting values specified for label %s by the containers of the task", label)
+			}
+			labels[label] = value
+		}
+	}
+	return labels, nil
+}
+
+// applyResourceControls sets the block IO and pids limits specified by the docker labels of
+// the containers of the task to the linux resources spec. The pids limit of the instance, when
+// set, is a ceiling the pids limit of the task can only lower.
+func (task *Task) applyResourceControls(linuxResourceSpec *specs.LinuxResources) error {
+	labels, err := task.resourceControlLabels()
+	if err != nil {
+		return err
+	}
+
+	if value, ok := labels[TaskPidsLimitLabel]; ok {
+		pidsLimit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || pidsLimit <= 0 || pidsLimit > maximumPidsLimit {
+			return errors.Errorf("invalid value %q for label %s, expected integer greater than 0 and less than %d",
+				value, TaskPidsLimitLabel, maximumPidsLimit+1)
+		}
+		if linuxResourceSpec.Pids == nil || pidsLimit < linuxResourceSpec.Pids.Limit {
+			linuxResourceSpec.Pids = &specs.LinuxPids{
+				Limit: pidsLimit,
+			}
+		}
+	}
+
+	blockIO := &specs.LinuxBlockIO{}
+	hasBlockIO := false
+	if value, ok := labels[TaskBlkioWeightLabel]; ok {
+		weight, err := strconv.ParseUint(value, 10, 16)
+		if err != nil || weight < minimumBlkioWeight || weight > maximumBlkioWeight {
+			return errors.Errorf("invalid value %q for label %s, expected integer between %d and %d",
+				value, TaskBlkioWeightLabel, minimumBlkioWeight, maximumBlkioWeight)
+		}
+		blkioWeight := uint16(weight)
+		blockIO.Weight = &blkioWeight
+		hasBlockIO = true
+	}
+	for _, throttle := range []struct {
+		label   string
+		bytes   bool
+		devices *[]specs.LinuxThrottleDevice
+	}{
+		{TaskDeviceReadBpsLabel, true, &blockIO.ThrottleReadBpsDevice},
+		{TaskDeviceWriteBpsLabel, true, &blockIO.ThrottleWriteBpsDevice},
+		{TaskDeviceReadIOpsLabel, false, &blockIO.ThrottleReadIOPSDevice},
+		{TaskDeviceWriteIOpsLabel, false, &blockIO.ThrottleWriteIOPSDevice},
+	} {
+		value, ok := labels[throttle.label]
+		if !ok {
+			continue
+		}
+		devices, err := parseThrottleDevices(value, throttle.bytes)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value %q for label %s", value, throttle.label)
+		}
+		*throttle.devices = devices
+		hasBlockIO = true
+	}
+	if hasBlockIO {
+		linuxResourceSpec.BlockIO = blockIO
+	}
+	return nil
+}
+
+// parseThrottleDevices parses a comma separated list of <device path>:<rate> throttles. The
+// rate is a size such as "10mb" if bytes is true, and a number of operations otherwise.
+func parseThrottleDevices(value string, bytes bool) ([]specs.LinuxThrottleDevice, error) {
+	var devices []specs.LinuxThrottleDevice
+	for _, throttle := range strings.Split(value, ",") {
+		path, rateValue, found := strings.Cut(strings.TrimSpace(throttle), ":")
+		if !found || !strings.HasPrefix(path, "/dev/") {
+			return nil, errors.Errorf("expected <device path>:<rate>, got %q", throttle)
+		}
+		var rate uint64
+		if bytes {
+			size, err := units.RAMInBytes(rateValue)
+			if err != nil || size <= 0 {
+				return nil, errors.Errorf("invalid rate %q for device %s", rateValue, path)
+			}
+			rate = uint64(size)
+		} else {
+			iops, err := strconv.ParseUint(rateValue, 10, 64)
+			if err != nil || iops == 0 {
+				return nil, errors.Errorf("invalid rate %q for device %s", rateValue, path)
+			}
+			rate = iops
+		}
+		major, minor, err := resolveDevice(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve device %s", path)
+		}
+		device := specs.LinuxThrottleDevice{Rate: rate}
+		device.Major = major
+		device.Minor = minor
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
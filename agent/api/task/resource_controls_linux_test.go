@@ -0,0 +1,197 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func containerWithLabels(t *testing.T, name string, labels map[string]string) *apicontainer.Container {
+	config, err := json.Marshal(dockercontainer.Config{Labels: labels})
+	require.NoError(t, err)
+	return &apicontainer.Container{
+		Name: name,
+		DockerConfig: apicontainer.DockerConfig{
+			Config: aws.String(string(config)),
+		},
+	}
+}
+
+func mockResolveDevice(t *testing.T) {
+	resolveDevice = func(path string) (int64, int64, error) {
+		switch path {
+		case "/dev/nvme1n1":
+			return 259, 1, nil
+		case "/dev/xvdb":
+			return 202, 16, nil
+		}
+		return 0, 0, errors.New("no such device")
+	}
+	t.Cleanup(func() {
+		resolveDevice = blockDeviceNumbers
+	})
+}
+
+func TestBuildLinuxResourceSpecWithResourceControls(t *testing.T) {
+	mockResolveDevice(t)
+	task := &Task{
+		Arn: validTaskArn,
+		CPU: float64(taskVCPULimit),
+		Containers: []*apicontainer.Container{
+			containerWithLabels(t, "c1", map[string]string{
+				TaskBlkioWeightLabel:   "500",
+				TaskDeviceReadBpsLabel: "/dev/nvme1n1:10mb, /dev/xvdb:1mb",
+				TaskPidsLimitLabel:     "256",
+			}),
+			containerWithLabels(t, "c2", map[string]string{
+				TaskDeviceWriteBpsLabel:  "/dev/nvme1n1:5mb",
+				TaskDeviceReadIOpsLabel:  "/dev/nvme1n1:1000",
+				TaskDeviceWriteIOpsLabel: "/dev/xvdb:500",
+				TaskPidsLimitLabel:       "256",
+			}),
+			{Name: "c3"},
+		},
+	}
+
+	linuxResourceSpec, err := task.BuildLinuxResourceSpec(defaultCPUPeriod, 1000)
+	require.NoError(t, err)
+
+	expectedWeight := uint16(500)
+	throttle := func(major, minor int64, rate uint64) specs.LinuxThrottleDevice {
+		device := specs.LinuxThrottleDevice{Rate: rate}
+		device.Major = major
+		device.Minor = minor
+		return device
+	}
+	assert.Equal(t, &specs.LinuxPids{Limit: 256}, linuxResourceSpec.Pids)
+	assert.Equal(t, &specs.LinuxBlockIO{
+		Weight: &expectedWeight,
+		ThrottleReadBpsDevice: []specs.LinuxThrottleDevice{
+			throttle(259, 1, 10*1024*1024),
+			throttle(202, 16, 1024*1024),
+		},
+		ThrottleWriteBpsDevice:  []specs.LinuxThrottleDevice{throttle(259, 1, 5*1024*1024)},
+		ThrottleReadIOPSDevice:  []specs.LinuxThrottleDevice{throttle(259, 1, 1000)},
+		ThrottleWriteIOPSDevice: []specs.LinuxThrottleDevice{throttle(202, 16, 500)},
+	}, linuxResourceSpec.BlockIO)
+}
+
+func TestBuildLinuxResourceSpecWithoutResourceControls(t *testing.T) {
+	task := &Task{
+		Arn:        validTaskArn,
+		CPU:        float64(taskVCPULimit),
+		Containers: []*apicontainer.Container{containerWithLabels(t, "c1", map[string]string{"foo": "bar"})},
+	}
+
+	linuxResourceSpec, err := task.BuildLinuxResourceSpec(defaultCPUPeriod, 100)
+	require.NoError(t, err)
+	assert.Nil(t, linuxResourceSpec.BlockIO)
+	assert.Equal(t, &specs.LinuxPids{Limit: 100}, linuxResourceSpec.Pids)
+}
+
+func TestBuildLinuxResourceSpecWithInvalidResourceControls(t *testing.T) {
+	mockResolveDevice(t)
+	testCases := []struct {
+		name       string
+		containers []map[string]string
+	}{
+		{"weight too low", []map[string]string{{TaskBlkioWeightLabel: "5"}}},
+		{"weight not a number", []map[string]string{{TaskBlkioWeightLabel: "high"}}},
+		{"pids limit zero", []map[string]string{{TaskPidsLimitLabel: "0"}}},
+		{"pids limit too high", []map[string]string{{TaskPidsLimitLabel: "4194305"}}},
+		{"throttle without rate", []map[string]string{{TaskDeviceReadBpsLabel: "/dev/nvme1n1"}}},
+		{"throttle with invalid rate", []map[string]string{{TaskDeviceReadIOpsLabel: "/dev/nvme1n1:1mb"}}},
+		{"throttle of non device path", []map[string]string{{TaskDeviceWriteBpsLabel: "/tmp/foo:1mb"}}},
+		{"throttle of unknown device", []map[string]string{{TaskDeviceWriteBpsLabel: "/dev/sdz:1mb"}}},
+		{"conflicting values", []map[string]string{{TaskPidsLimitLabel: "100"}, {TaskPidsLimitLabel: "200"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{
+				Arn: validTaskArn,
+				CPU: float64(taskVCPULimit),
+			}
+			for _, labels := range tc.containers {
+				task.Containers = append(task.Containers, containerWithLabels(t, "c", labels))
+			}
+			_, err := task.BuildLinuxResourceSpec(defaultCPUPeriod, 0)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBlockDeviceNumbers(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nvme1n1"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nvme1n1", "dev"), []byte("259:1\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "broken"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken", "dev"), []byte("259"), 0644))
+	sysBlockDir = dir
+	defer func() {
+		sysBlockDir = "/sys/class/block"
+	}()
+
+	major, minor, err := blockDeviceNumbers("/dev/nvme1n1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(259), major)
+	assert.Equal(t, int64(1), minor)
+
+	for _, path := range []string{"/dev/sdz", "/dev/broken", "/dev/disk/by-id/nvme-vol0", "/dev/"} {
+		_, _, err := blockDeviceNumbers(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestBuildLinuxResourceSpecPidsLimitCeiling(t *testing.T) {
+	testCases := []struct {
+		name              string
+		instancePidsLimit int
+		label             string
+		expectedPidsLimit int64
+	}{
+		{"lower than the instance limit", 100, "50", 50},
+		{"higher than the instance limit", 100, "256", 100},
+		{"no instance limit", 0, "256", 256},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{
+				Arn: validTaskArn,
+				CPU: float64(taskVCPULimit),
+				Containers: []*apicontainer.Container{
+					containerWithLabels(t, "c1", map[string]string{TaskPidsLimitLabel: tc.label}),
+				},
+			}
+			linuxResourceSpec, err := task.BuildLinuxResourceSpec(defaultCPUPeriod, tc.instancePidsLimit)
+			require.NoError(t, err)
+			assert.Equal(t, &specs.LinuxPids{Limit: tc.expectedPidsLimit}, linuxResourceSpec.Pids)
+		})
+	}
+}
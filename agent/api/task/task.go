@@ -50,7 +50,9 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 	nlappmesh "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 	commonutils "github.com/aws/amazon-ecs-agent/ecs-agent/utils"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/arn"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime"
@@ -304,6 +306,10 @@ type Task struct {
 
 	EnableFaultInjection bool `json:"enableFaultInjection,omitempty"`
 
+	// EgressPolicy is the egress policy enforced inside the network namespace of the task.
+	// It is only set for tasks in awsvpc network mode.
+	EgressPolicy *egresspolicy.EgressPolicy `json:"EgressPolicy,omitempty"`
+
 	// DefaultIfname is used to reference the default network interface name on the task network namespace
 	// For AWSVPC mode, it can be eth0 which corresponds to the interface name on the task ENI
 	// For Host mode, it can vary based on the hardware/network config on the host instance (e.g. eth0, ens5, etc.) and will need to be obtained on the host.
@@ -445,6 +451,13 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		})
 		return apierrors.NewResourceInitError(task.Arn, err)
 	}
+	if err := task.initializeEgressPolicy(cfg); err != nil {
+		logger.Error("Could not initialize egress policy", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Error:  err,
+		})
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
 	// Adds necessary Pause containers for sharing PID or IPC namespaces
 	task.addNamespaceSharingProvisioningDependency(cfg)
 
@@ -482,7 +495,7 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		}
 	}
 
-	task.initRestartTrackers()
+	task.initRestartTrackers(cfg)
 
 	for _, opt := range options {
 		if err := opt(task); err != nil {
@@ -542,10 +555,24 @@ func (task *Task) initNetworkMode(acsTaskNetworkMode *string) {
 }
 
 // initRestartTrackers initializes the restart policy tracker for each container
-// that has a restart policy configured and enabled.
-func (task *Task) initRestartTrackers() {
+// that has a restart policy configured and enabled. The restart attempt cap, backoff
+// and whether to stop the task once the attempts are exhausted are taken from the
+// agent config when they are not set in the restart policy.
+func (task *Task) initRestartTrackers(cfg *config.Config) {
 	for _, c := range task.Containers {
 		if c.RestartPolicyEnabled() {
+			if c.RestartPolicy.MaxRestartAttempts == 0 {
+				c.RestartPolicy.MaxRestartAttempts = cfg.ContainerRestartMaxAttempts
+			}
+			if c.RestartPolicy.BackoffInitialDelay == 0 {
+				c.RestartPolicy.BackoffInitialDelay = int(cfg.ContainerRestartBackoffInitialDelay.Seconds())
+			}
+			if c.RestartPolicy.BackoffMaxDelay == 0 {
+				c.RestartPolicy.BackoffMaxDelay = int(cfg.ContainerRestartBackoffMaxDelay.Seconds())
+			}
+			if c.RestartPolicy.StopTaskOnMaxRestartAttempts == nil {
+				c.RestartPolicy.StopTaskOnMaxRestartAttempts = aws.Bool(cfg.ContainerRestartStopTaskOnMaxAttempts.Enabled())
+			}
 			c.RestartTracker = restart.NewRestartTracker(*c.RestartPolicy)
 		}
 	}
@@ -1464,10 +1491,24 @@ func (task *Task) addNetworkResourceProvisioningDependency(cfg *config.Config) e
 		return task.addNetworkResourceProvisioningDependencyAwsvpc(cfg)
 	} else if task.IsNetworkModeBridge() && task.IsServiceConnectEnabled() {
 		return task.addNetworkResourceProvisioningDependencyServiceConnectBridge(cfg)
+	} else if task.IsNetworkModeBridge() && cfg.TaskBridgeEnabled.Enabled() {
+		// The task is connected to the task bridge through a single pause container, the same way
+		// as an awsvpc task is connected to its ENI.
+		return task.addNetworkResourceProvisioningDependencyAwsvpc(cfg)
 	}
 	return nil
 }
 
+// UsesTaskBridge checks if the task is in bridge network mode and connected to the task bridge
+// through its pause container rather than to the docker bridge.
+func (task *Task) UsesTaskBridge() bool {
+	if !task.IsNetworkModeBridge() || task.IsServiceConnectEnabled() {
+		return false
+	}
+	_, ok := task.ContainerByName(NetworkPauseContainerName)
+	return ok
+}
+
 func (task *Task) addNetworkResourceProvisioningDependencyAwsvpc(cfg *config.Config) error {
 	pauseContainer := apicontainer.NewContainerWithSteadyState(apicontainerstatus.ContainerResourcesProvisioned)
 	pauseContainer.TransitionDependenciesMap = make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet)
@@ -1578,6 +1619,25 @@ func (task *Task) addNetworkResourceProvisioningDependencyServiceConnectBridge(c
 	return nil
 }
 
+// initializeEgressPolicy sets the egress policy of a task in awsvpc network mode. The policy
+// specified by the docker labels of the containers of the task can only narrow the one of
+// the instance: the traffic is allowed if both policies allow it.
+func (task *Task) initializeEgressPolicy(cfg *config.Config) error {
+	if !task.IsNetworkModeAWSVPC() {
+		return nil
+	}
+	var containerConfigs []string
+	for _, container := range task.Containers {
+		containerConfigs = append(containerConfigs, aws.ToString(container.DockerConfig.Config))
+	}
+	policy, err := egresspolicy.FromContainerConfigs(containerConfigs...)
+	if err != nil {
+		return err
+	}
+	task.EgressPolicy = egresspolicy.Intersect(cfg.AWSVPCEgressPolicy, policy)
+	return nil
+}
+
 // GetBridgeModePauseContainerForTaskContainer retrieves the associated pause container for a task container (SC container
 // or customer-defined containers) in a bridge-mode SC-enabled task.
 // For a container with name "abc", the pause container will always be named "~internal~ecs~pause-abc"
@@ -2086,6 +2146,8 @@ func (task *Task) shouldOverrideNetworkMode(container *apicontainer.Container, d
 		return task.shouldOverrideNetworkModeAwsvpc(container, dockerContainerMap)
 	} else if task.IsNetworkModeBridge() && task.IsServiceConnectEnabled() {
 		return task.shouldOverrideNetworkModeServiceConnectBridge(container, dockerContainerMap)
+	} else if task.UsesTaskBridge() {
+		return task.shouldOverrideNetworkModeAwsvpc(container, dockerContainerMap)
 	}
 	return false, ""
 }
@@ -2469,6 +2531,12 @@ func (task *Task) dockerPortMap(container *apicontainer.Container, dynamicHostPo
 	containerPortSet := make(map[int]struct{})
 	containerPortRangeMap := make(map[string]string)
 
+	// For tasks connected to the task bridge, the ports are forwarded to the network namespace of the
+	// pause container by the agent, see AssignTaskBridgePortBindings.
+	if task.UsesTaskBridge() {
+		return dockerPortMap, nil
+	}
+
 	// For service connect bridge network mode task, we will create port bindings for task containers,
 	// including both application containers and service connect AppNet container, and let them be published
 	// by the associated pause containers.
@@ -2600,6 +2668,62 @@ func (task *Task) dockerPortMap(container *apicontainer.Container, dynamicHostPo
 	return dockerPortMap, nil
 }
 
+// AssignTaskBridgePortBindings assigns a host port within the dynamic host port range to the port
+// mappings of the containers of a task connected to the task bridge that don't specify one, and
+// records the port bindings of the containers so that they are reported to ECS. It returns the
+// port mappings the host forwards to the network namespace of the task. Container port ranges
+// aren't supported.
+func (task *Task) AssignTaskBridgePortBindings(dynamicHostPortRange string) ([]tasknetworkconfig.PortMapping, error) {
+	var portMappings []tasknetworkconfig.PortMapping
+	for _, container := range task.Containers {
+		if container.IsInternal() {
+			continue
+		}
+		var portBindings []apicontainer.PortBinding
+		containerPortSet := make(map[int]struct{})
+		for _, portBinding := range container.Ports {
+			if portBinding.ContainerPortRange != "" {
+				return nil, errors.Errorf("container port ranges are not supported with the task bridge: %s",
+					portBinding.ContainerPortRange)
+			}
+			protocolStr := portBinding.Protocol.String()
+			hostPort := portBinding.HostPort
+			if hostPort == 0 {
+				hostPortStr, err := getHostPort(protocolStr, dynamicHostPortRange)
+				if err != nil {
+					logger.Error("Unable to find a host port for container within the given dynamic host port range", logger.Fields{
+						field.TaskID:           task.GetID(),
+						field.Container:        container.Name,
+						"dynamicHostPortRange": dynamicHostPortRange,
+						field.Error:            err,
+					})
+					return nil, err
+				}
+				parsedHostPort, err := strconv.ParseUint(hostPortStr, 10, 16)
+				if err != nil {
+					return nil, err
+				}
+				hostPort = uint16(parsedHostPort)
+			}
+			portMappings = append(portMappings, tasknetworkconfig.PortMapping{
+				ContainerPort: portBinding.ContainerPort,
+				HostPort:      hostPort,
+				Protocol:      protocolStr,
+			})
+			portBindings = append(portBindings, apicontainer.PortBinding{
+				ContainerPort: portBinding.ContainerPort,
+				HostPort:      hostPort,
+				BindIP:        "0.0.0.0",
+				Protocol:      portBinding.Protocol,
+			})
+			containerPortSet[int(portBinding.ContainerPort)] = struct{}{}
+		}
+		container.SetKnownPortBindings(portBindings)
+		container.SetContainerPortSet(containerPortSet)
+	}
+	return portMappings, nil
+}
+
 func (task *Task) dockerVolumesFrom(container *apicontainer.Container,
 	dockerContainerMap map[string]*apicontainer.DockerContainer) ([]string, error) {
 	volumesFrom := make([]string, len(container.VolumesFrom))
@@ -2674,6 +2798,20 @@ func (task *Task) UpdateDesiredStatus() {
 	task.updateResourceDesiredStatusUnsafe(task.DesiredStatusUnsafe)
 }
 
+// SetDesiredStatusStopped sets the desired status of the task, of its containers and of its
+// resources to stopped. It returns false if the task was already desired to be stopped.
+func (task *Task) SetDesiredStatusStopped() bool {
+	task.lock.Lock()
+	defer task.lock.Unlock()
+	if task.DesiredStatusUnsafe.Terminal() {
+		return false
+	}
+	task.DesiredStatusUnsafe = apitaskstatus.TaskStopped
+	task.updateContainerDesiredStatusUnsafe(task.DesiredStatusUnsafe)
+	task.updateResourceDesiredStatusUnsafe(task.DesiredStatusUnsafe)
+	return true
+}
+
 // updateTaskDesiredStatusUnsafe determines what status the task should properly be at based on the containers' statuses
 // Invariant: task desired status must be stopped if any essential container is stopped
 func (task *Task) updateTaskDesiredStatusUnsafe() {
@@ -3218,6 +3356,56 @@ func populateContainerSecrets(hostConfig *dockercontainer.HostConfig, container
 	container.MergeEnvironmentVariables(envVars)
 }
 
+// GetContainerSecretValues returns the values of the environment variable secrets of the
+// container by secret name, as cached by the ASM and SSM secret resources of the task
+func (task *Task) GetContainerSecretValues(container *apicontainer.Container) map[string]string {
+	var ssmRes *ssmsecret.SSMSecretResource
+	var asmRes *asmsecret.ASMSecretResource
+
+	if resource, ok := task.getSSMSecretsResource(); ok {
+		ssmRes = resource[0].(*ssmsecret.SSMSecretResource)
+	}
+	if resource, ok := task.getASMSecretsResource(); ok {
+		asmRes = resource[0].(*asmsecret.ASMSecretResource)
+	}
+
+	values := make(map[string]string)
+	for _, secret := range container.Secrets {
+		if secret.Type != apicontainer.SecretTypeEnv {
+			continue
+		}
+
+		k := secret.GetSecretResourceCacheKey()
+		if secret.Provider == apicontainer.SecretProviderSSM && ssmRes != nil {
+			if secretValue, ok := ssmRes.GetCachedSecretValue(k); ok {
+				values[secret.Name] = secretValue
+			}
+		}
+		if secret.Provider == apicontainer.SecretProviderASM && asmRes != nil {
+			if secretValue, ok := asmRes.GetCachedSecretValue(k); ok {
+				values[secret.Name] = secretValue
+			}
+		}
+	}
+	return values
+}
+
+// RefreshSecrets retrieves the values of the ASM and SSM secrets of the task again with the
+// execution role credentials of the task
+func (task *Task) RefreshSecrets() error {
+	if resource, ok := task.getSSMSecretsResource(); ok {
+		if err := resource[0].(*ssmsecret.SSMSecretResource).Refresh(); err != nil {
+			return err
+		}
+	}
+	if resource, ok := task.getASMSecretsResource(); ok {
+		if err := resource[0].(*asmsecret.ASMSecretResource).Refresh(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PopulateSecretLogOptionsToFirelensContainer collects secret log option values for awsfirelens log driver from task
 // resource and specified then as envs of firelens container. Firelens container will use the envs to resolve config
 // file variables constructed for secret log options when loading the config file.
@@ -3858,6 +4046,14 @@ func (task *Task) SetNetworkNamespace(netNs string) {
 	task.NetworkNamespace = netNs
 }
 
+// GetEgressPolicy returns the egress policy enforced inside the network namespace of the task.
+func (task *Task) GetEgressPolicy() *egresspolicy.EgressPolicy {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	return task.EgressPolicy
+}
+
 func (task *Task) GetDefaultIfname() string {
 	task.lock.RLock()
 	defer task.lock.RUnlock()
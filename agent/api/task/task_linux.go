@@ -160,6 +160,11 @@ func (task *Task) BuildLinuxResourceSpec(cGroupCPUPeriod time.Duration, taskPids
 		linuxResourceSpec.Pids = pidsLimit
 	}
 
+	// Set task block IO limits and lower the pids limit if set via task docker labels
+	if err := task.applyResourceControls(&linuxResourceSpec); err != nil {
+		return specs.LinuxResources{}, err
+	}
+
 	return linuxResourceSpec, nil
 }
 
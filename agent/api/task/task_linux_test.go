@@ -33,11 +33,13 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/control/mock_control"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
 	mock_ioutilwrapper "github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper/mocks"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	nlappmesh "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 	"github.com/golang/mock/gomock"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -127,6 +129,95 @@ func TestAddNetworkResourceProvisioningDependencyWithENI(t *testing.T) {
 		"pause container should use configured image")
 }
 
+func TestAddNetworkResourceProvisioningDependencyWithTaskBridge(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			testTask := &Task{
+				Containers: []*apicontainer.Container{
+					{
+						Name:                      "c1",
+						TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+						Ports:                     []apicontainer.PortBinding{{ContainerPort: 80, HostPort: 8080}},
+					},
+				},
+				NetworkMode: BridgeNetworkMode,
+			}
+			cfg := &config.Config{
+				PauseContainerImageName: "pause-container-image-name",
+				PauseContainerTag:       "pause-container-tag",
+				TaskBridgeEnabled:       config.BooleanDefaultFalse{Value: config.ExplicitlyDisabled},
+			}
+			if enabled {
+				cfg.TaskBridgeEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+			}
+			require.NoError(t, testTask.addNetworkResourceProvisioningDependency(cfg))
+			assert.Equal(t, enabled, testTask.UsesTaskBridge())
+			if !enabled {
+				assert.Len(t, testTask.Containers, 1)
+				return
+			}
+
+			require.Len(t, testTask.Containers, 2)
+			pauseContainer, ok := testTask.ContainerByName(NetworkPauseContainerName)
+			require.True(t, ok, "Expected to find pause container")
+			assert.Equal(t, apicontainer.ContainerCNIPause, pauseContainer.Type)
+			dockerContainerMap := map[string]*apicontainer.DockerContainer{
+				NetworkPauseContainerName: {DockerID: "pause-docker-id"},
+			}
+			override, networkMode := testTask.shouldOverrideNetworkMode(pauseContainer, dockerContainerMap)
+			assert.True(t, override)
+			assert.Equal(t, networkModeNone, networkMode)
+			override, networkMode = testTask.shouldOverrideNetworkMode(testTask.Containers[0], dockerContainerMap)
+			assert.True(t, override)
+			assert.Equal(t, "container:pause-docker-id", networkMode)
+			portMap, err := testTask.dockerPortMap(testTask.Containers[0], "40000-60000")
+			require.NoError(t, err)
+			assert.Empty(t, portMap, "ports should be forwarded to the pause container netns by the agent")
+		})
+	}
+}
+
+func TestAssignTaskBridgePortBindings(t *testing.T) {
+	defer func() {
+		getHostPort = utils.GetHostPort
+	}()
+	getHostPort = func(protocol, dynamicHostPortRange string) (string, error) {
+		return "40000", nil
+	}
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				Ports: []apicontainer.PortBinding{
+					{ContainerPort: 80, HostPort: 8080},
+					{ContainerPort: 53, Protocol: apicontainer.TransportProtocolUDP},
+				},
+			},
+			{
+				Name: NetworkPauseContainerName,
+				Type: apicontainer.ContainerCNIPause,
+			},
+		},
+		NetworkMode: BridgeNetworkMode,
+	}
+
+	portMappings, err := testTask.AssignTaskBridgePortBindings("40000-60000")
+	require.NoError(t, err)
+	assert.Equal(t, []tasknetworkconfig.PortMapping{
+		{ContainerPort: 80, HostPort: 8080, Protocol: "tcp"},
+		{ContainerPort: 53, HostPort: 40000, Protocol: "udp"},
+	}, portMappings)
+	assert.Equal(t, []apicontainer.PortBinding{
+		{ContainerPort: 80, HostPort: 8080, BindIP: "0.0.0.0", Protocol: apicontainer.TransportProtocolTCP},
+		{ContainerPort: 53, HostPort: 40000, BindIP: "0.0.0.0", Protocol: apicontainer.TransportProtocolUDP},
+	}, testTask.Containers[0].GetKnownPortBindings())
+	assert.Equal(t, map[int]struct{}{80: {}, 53: {}}, testTask.Containers[0].GetContainerPortSet())
+
+	testTask.Containers[0].Ports = []apicontainer.PortBinding{{ContainerPortRange: "80-81"}}
+	_, err = testTask.AssignTaskBridgePortBindings("40000-60000")
+	assert.Error(t, err, "container port ranges should be rejected")
+}
+
 func TestAddNetworkResourceProvisioningDependencyWithAppMesh(t *testing.T) {
 	pauseConfig := dockercontainer.Config{
 		User: "1337:35",
@@ -55,6 +55,7 @@ import (
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	commonutils "github.com/aws/amazon-ecs-agent/ecs-agent/utils"
 	dockertypes "github.com/docker/docker/api/types"
@@ -1913,6 +1914,20 @@ func TestTaskFromACS(t *testing.T) {
 	assert.EqualValues(t, expectedTask, task)
 }
 
+func TestSetDesiredStatusStopped(t *testing.T) {
+	testTask := &Task{
+		DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		Containers: []*apicontainer.Container{
+			{DesiredStatusUnsafe: apicontainerstatus.ContainerRunning},
+		},
+	}
+
+	assert.True(t, testTask.SetDesiredStatusStopped())
+	assert.Equal(t, apitaskstatus.TaskStopped, testTask.GetDesiredStatus())
+	assert.Equal(t, apicontainerstatus.ContainerStopped, testTask.Containers[0].GetDesiredStatus())
+	assert.False(t, testTask.SetDesiredStatusStopped(), "Task is already desired to be stopped")
+}
+
 func TestTaskUpdateKnownStatusHappyPath(t *testing.T) {
 	testTask := &Task{
 		KnownStatusUnsafe: apitaskstatus.TaskStatusNone,
@@ -3332,6 +3347,58 @@ func TestPopulateSecrets(t *testing.T) {
 	assert.Equal(t, "option", hostConfig.LogConfig.Config["splunk-option"])
 }
 
+func TestGetContainerSecretValues(t *testing.T) {
+	secret1 := apicontainer.Secret{
+		Provider:  "ssm",
+		Name:      "secret1",
+		Region:    "us-west-2",
+		Type:      "ENVIRONMENT_VARIABLE",
+		ValueFrom: "/test/secretName",
+	}
+
+	secret2 := apicontainer.Secret{
+		Provider:  "asm",
+		Name:      "secret2",
+		Region:    "us-west-2",
+		Type:      "ENVIRONMENT_VARIABLE",
+		ValueFrom: "arn:aws:secretsmanager:us-west-2:11111:secret:/test/secretName",
+	}
+
+	secret3 := apicontainer.Secret{
+		Provider:  "ssm",
+		Name:      "splunk-token",
+		Region:    "us-west-1",
+		Target:    "LOG_DRIVER",
+		ValueFrom: "/test/secretName1",
+	}
+
+	container := &apicontainer.Container{
+		Name:    "myName",
+		Secrets: []apicontainer.Secret{secret1, secret2, secret3},
+	}
+
+	task := &Task{
+		Arn:                "test",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers:         []*apicontainer.Container{container},
+	}
+	assert.Empty(t, task.GetContainerSecretValues(container))
+	assert.NoError(t, task.RefreshSecrets())
+
+	ssmRes := &ssmsecret.SSMSecretResource{}
+	ssmRes.SetCachedSecretValue(secretKeyWest1, "secretValue1")
+	ssmRes.SetCachedSecretValue(secKeyLogDriver, "secretValue3")
+	asmRes := &asmsecret.ASMSecretResource{}
+	asmRes.SetCachedSecretValue(asmSecretKeyWest1, "secretValue2")
+	task.AddResource(ssmsecret.ResourceName, ssmRes)
+	task.AddResource(asmsecret.ResourceName, asmRes)
+
+	assert.Equal(t, map[string]string{
+		"secret1": "secretValue1",
+		"secret2": "secretValue2",
+	}, task.GetContainerSecretValues(container))
+}
+
 func TestPopulateSecretsNoConfigInHostConfig(t *testing.T) {
 	secret1 := apicontainer.Secret{
 		Provider:  "ssm",
@@ -3772,6 +3839,87 @@ func TestInitializeContainerOrderingWithLinksAndVolumesFrom(t *testing.T) {
 	assert.Equal(t, 0, len(containerResultWithNoVolumeOrLink.DependsOnUnsafe))
 }
 
+func TestInitializeEgressPolicy(t *testing.T) {
+	defaultPolicy := &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionAllow}
+	cfg := &config.Config{AWSVPCEgressPolicy: defaultPolicy}
+	labeledConfig := aws.String(`{"Labels":{"com.amazonaws.ecs.egress-policy":"{\"DefaultAction\":\"DENY\"}"}}`)
+
+	testCases := []struct {
+		name           string
+		networkMode    string
+		dockerConfig   *string
+		cfg            *config.Config
+		expectedPolicy *egresspolicy.EgressPolicy
+		expectedError  bool
+	}{
+		{
+			name:           "default policy",
+			networkMode:    AWSVPCNetworkMode,
+			expectedPolicy: defaultPolicy,
+		},
+		{
+			name:           "policy from docker label",
+			networkMode:    AWSVPCNetworkMode,
+			dockerConfig:   labeledConfig,
+			expectedPolicy: &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionDeny},
+		},
+		{
+			name:         "docker label cannot widen the instance policy",
+			networkMode:  AWSVPCNetworkMode,
+			dockerConfig: aws.String(`{"Labels":{"com.amazonaws.ecs.egress-policy":"{\"DefaultAction\":\"ALLOW\"}"}}`),
+			cfg: &config.Config{AWSVPCEgressPolicy: &egresspolicy.EgressPolicy{
+				DefaultAction: egresspolicy.ActionDeny,
+				Rules: []egresspolicy.Rule{
+					{Action: egresspolicy.ActionAllow, CIDR: "10.0.0.0/16"},
+				},
+			}},
+			expectedPolicy: &egresspolicy.EgressPolicy{
+				DefaultAction: egresspolicy.ActionDeny,
+				Rules: []egresspolicy.Rule{
+					{Action: egresspolicy.ActionAllow, CIDR: "10.0.0.0/16"},
+				},
+			},
+		},
+		{
+			name:          "invalid policy from docker label",
+			networkMode:   AWSVPCNetworkMode,
+			dockerConfig:  aws.String(`{"Labels":{"com.amazonaws.ecs.egress-policy":"invalid"}}`),
+			expectedError: true,
+		},
+		{
+			name:         "bridge network mode",
+			networkMode:  BridgeNetworkMode,
+			dockerConfig: labeledConfig,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{
+				NetworkMode: tc.networkMode,
+				Containers: []*apicontainer.Container{
+					{
+						Name: "c1",
+						DockerConfig: apicontainer.DockerConfig{
+							Config: tc.dockerConfig,
+						},
+					},
+				},
+			}
+			taskCfg := cfg
+			if tc.cfg != nil {
+				taskCfg = tc.cfg
+			}
+			err := task.initializeEgressPolicy(taskCfg)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPolicy, task.GetEgressPolicy())
+		})
+	}
+}
+
 func TestInitializeContainerOrderingWithError(t *testing.T) {
 	containerWithVolumeError := &apicontainer.Container{
 		Name:        "myName",
@@ -3949,6 +4097,80 @@ func TestPostUnmarshalTaskContainerRestartPolicy(t *testing.T) {
 	}
 }
 
+func TestPostUnmarshalTaskContainerRestartPolicyConfigDefaults(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   restart.RestartPolicy
+		expected restart.RestartPolicy
+	}{
+		{
+			name:   "defaults from agent config",
+			policy: restart.RestartPolicy{Enabled: true},
+			expected: restart.RestartPolicy{
+				Enabled:                      true,
+				MaxRestartAttempts:           3,
+				BackoffInitialDelay:          10,
+				BackoffMaxDelay:              120,
+				StopTaskOnMaxRestartAttempts: aws.Bool(true),
+			},
+		},
+		{
+			name: "values from restart policy take precedence",
+			policy: restart.RestartPolicy{
+				Enabled:                      true,
+				MaxRestartAttempts:           1,
+				BackoffInitialDelay:          5,
+				BackoffMaxDelay:              30,
+				StopTaskOnMaxRestartAttempts: aws.Bool(false),
+			},
+			expected: restart.RestartPolicy{
+				Enabled:                      true,
+				MaxRestartAttempts:           1,
+				BackoffInitialDelay:          5,
+				BackoffMaxDelay:              30,
+				StopTaskOnMaxRestartAttempts: aws.Bool(false),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := tc.policy
+			container := &apicontainer.Container{
+				Name:                      "containerName",
+				Image:                     "image:tag",
+				RestartPolicy:             &policy,
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			}
+			task := &Task{
+				Arn:                testTaskARN,
+				ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+				Containers:         []*apicontainer.Container{container},
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cfg := &config.Config{
+				ContainerRestartMaxAttempts:           3,
+				ContainerRestartBackoffInitialDelay:   10 * time.Second,
+				ContainerRestartBackoffMaxDelay:       2 * time.Minute,
+				ContainerRestartStopTaskOnMaxAttempts: config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled},
+			}
+			credentialsManager := mock_credentials.NewMockManager(ctrl)
+			resFields := &taskresource.ResourceFields{
+				ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
+					CredentialsManager: credentialsManager,
+				},
+			}
+
+			err := task.PostUnmarshalTask(cfg, credentialsManager, resFields, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, container.RestartTracker.RestartPolicy)
+		})
+	}
+}
+
 func TestInitializeAndGetEnvfilesResource(t *testing.T) {
 	envfile1 := apicontainer.EnvironmentFile{
 		Value: "s3://bucket/envfile1",
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	agentacs "github.com/aws/amazon-ecs-agent/agent/acs/session"
@@ -40,12 +41,15 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/eni/pause"
 	"github.com/aws/amazon-ecs-agent/agent/eni/watcher"
 	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
+	"github.com/aws/amazon-ecs-agent/agent/eventhandler/localsink"
 	"github.com/aws/amazon-ecs-agent/agent/handlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/amazon-ecs-agent/agent/standalone"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/stats/reporter"
+	"github.com/aws/amazon-ecs-agent/agent/stats/sink"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/agent/utils/loader"
@@ -111,6 +115,14 @@ const (
 	// as the number of messages in the channel is equal to the number of times we call `getInstanceMetrics`, which collects
 	// metrics from all tasks and containers and put them into one TelemetryMessage object.
 	telemetryChannelDefaultBufferSize = 15
+
+	// eventQueueDir is the directory under the data directory where the state change events
+	// not yet delivered to the event webhooks are queued
+	eventQueueDir = "event-queue"
+
+	// standaloneStateChangeLogFile is the default file, in the data directory, the state
+	// changes are written to in standalone mode
+	standaloneStateChangeLogFile = "standalone-state-changes.log"
 )
 
 var (
@@ -165,6 +177,7 @@ type ecsAgent struct {
 	resourceFields              *taskresource.ResourceFields
 	availabilityZone            string
 	latestSeqNumberTaskManifest *int64
+	metricsFactory              metricsfactory.EntryFactory
 }
 
 // newAgent returns a new ecsAgent object, but does not start anything
@@ -234,7 +247,7 @@ func newAgent(blackholeEC2Metadata bool, acceptInsecureCert *bool) (agent, error
 
 	var dataClient data.Client
 	if cfg.Checkpoint.Enabled() {
-		dataClient, err = data.New(cfg.DataDir)
+		dataClient, err = data.NewWithBackend(cfg.DataBackend, cfg.DataDir)
 		if err != nil {
 			logger.Critical("Error creating data client", logger.Fields{
 				field.Error: err,
@@ -254,6 +267,12 @@ func newAgent(blackholeEC2Metadata bool, acceptInsecureCert *bool) (agent, error
 		metadataManager = containermetadata.NewManager(dockerClient, cfg)
 	}
 
+	metricsFactory := metricsfactory.NewNopEntryFactory()
+	if cfg.PrometheusMetricsEnabled {
+		logger.Info("Prometheus metrics are enabled, agent metrics will be served by the introspection server")
+		metricsFactory = metricsfactory.NewPrometheusEntryFactory()
+	}
+
 	credentialsCache := providers.NewInstanceCredentialsCache(
 		cfg.External.Enabled(),
 		providers.NewRotatingSharedCredentialsProviderV2(),
@@ -283,6 +302,7 @@ func newAgent(blackholeEC2Metadata bool, acceptInsecureCert *bool) (agent, error
 		terminationHandler:          sighandlers.StartDefaultTerminationHandler,
 		mobyPlugins:                 mobypkgwrapper.NewPlugins(),
 		latestSeqNumberTaskManifest: &initialSeqNumber,
+		metricsFactory:              metricsFactory,
 	}, nil
 }
 
@@ -290,6 +310,15 @@ func (agent *ecsAgent) getConfig() *config.Config {
 	return agent.cfg
 }
 
+// getMetricsFactory returns the factory used to record agent metrics. Metrics are
+// discarded if no factory was set.
+func (agent *ecsAgent) getMetricsFactory() metricsfactory.EntryFactory {
+	if agent.metricsFactory == nil {
+		return metricsfactory.NewNopEntryFactory()
+	}
+	return agent.metricsFactory
+}
+
 // printECSAttributes prints the Agent's ECS Attributes based on its
 // environment
 func (agent *ecsAgent) printECSAttributes() int {
@@ -311,6 +340,7 @@ func (agent *ecsAgent) setTerminationHandler(handler sighandlers.TerminationHand
 // start starts the ECS Agent
 func (agent *ecsAgent) start() int {
 	sighandlers.StartDebugHandler()
+	sighandlers.StartLogConfigHandler(agent.cfg.DebugLogDuration)
 
 	containerChangeEventStream := eventstream.NewEventStream(containerChangeEventStreamName, agent.ctx)
 	credentialsManager := credentials.NewManager()
@@ -324,7 +354,8 @@ func (agent *ecsAgent) start() int {
 		return exitcodes.ExitError
 	}
 	clientFactory := ecsclient.NewECSClientFactory(agent.credentialsCache, cfgAccessor, agent.ec2MetadataClient,
-		version.String(), ecsclient.WithIPv6PortBindingExcluded(true))
+		version.String(), ecsclient.WithIPv6PortBindingExcluded(true),
+		ecsclient.WithMetricsFactory(agent.getMetricsFactory()))
 	client, err := clientFactory.NewClient()
 	if err != nil {
 		logger.Critical("Unable to create new ECS client", logger.Fields{
@@ -332,6 +363,15 @@ func (agent *ecsAgent) start() int {
 		})
 		return exitcodes.ExitError
 	}
+	if agent.cfg.StandaloneTasksDir != "" {
+		client, err = standalone.NewStateChangeLogClient(client, agent.standaloneStateChangeLogPath())
+		if err != nil {
+			logger.Critical("Unable to open the standalone state change log", logger.Fields{
+				field.Error: err,
+			})
+			return exitcodes.ExitTerminal
+		}
+	}
 	agent.initializeResourceFields(credentialsManager)
 	return agent.doStart(containerChangeEventStream, credentialsManager, state, imageManager, client, execcmd.NewManager())
 }
@@ -410,6 +450,12 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		seelog.Errorf("Failed to load pause container: %v", loadPauseErr)
 	}
 
+	// In standalone mode, the tasks are read from local files instead of ACS
+	if agent.cfg.StandaloneTasksDir != "" {
+		return agent.startStandalone(containerChangeEventStream, credentialsManager, state, imageManager,
+			taskEngine, client)
+	}
+
 	var vpcSubnetAttributes []types.Attribute
 	// Check if Task ENI is enabled
 	if agent.cfg.TaskENIEnabled.Enabled() {
@@ -515,6 +561,11 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 	// Begin listening to the docker daemon and saving changes
 	taskEngine.SetDataClient(agent.dataClient)
 	imageManager.SetDataClient(agent.dataClient)
+	if dockerTaskEngine, ok := taskEngine.(*engine.DockerTaskEngine); ok {
+		dockerTaskEngine.SetMetricsFactory(agent.getMetricsFactory())
+		// The tags of the queued tasks are only fetched with the priority task queue policy
+		dockerTaskEngine.SetTaskTagsGetter(client)
+	}
 	taskEngine.MustInit(agent.ctx)
 
 	// Start back ground routines, including the telemetry session
@@ -672,6 +723,15 @@ func (agent *ecsAgent) newDoctorWithHealthchecks(cluster, containerInstanceARN s
 		runtimeHealthCheck,
 	}
 
+	// add the operator-defined healthchecks, skipping the invalid ones
+	if agent.cfg.CustomHealthchecksDir != "" {
+		customHealthchecks, err := dockerdoctor.LoadCustomHealthchecks(agent.cfg.CustomHealthchecksDir)
+		if err != nil {
+			seelog.Warnf("Error loading custom healthchecks: %v", err)
+		}
+		healthcheckList = append(healthcheckList, customHealthchecks...)
+	}
+
 	// set up the doctor and return it
 	return doctor.NewDoctor(healthcheckList, cluster, containerInstanceARN)
 }
@@ -959,13 +1019,23 @@ func (agent *ecsAgent) startAsyncRoutines(
 		go imageManager.StartImageCleanupProcess(agent.ctx)
 	}
 
+	// Pull the images listed in the prefetch file ahead of the tasks that use them
+	if agent.cfg.ImagePrefetchFile != "" {
+		if dockerTaskEngine, ok := taskEngine.(*engine.DockerTaskEngine); ok {
+			if err := dockerTaskEngine.PrefetchImagesFromFile(agent.cfg.ImagePrefetchFile); err != nil {
+				seelog.Warnf("Unable to prefetch images from %s: %v", agent.cfg.ImagePrefetchFile, err)
+			}
+		}
+	}
+
 	// Start automatic spot instance draining poller routine
 	if agent.cfg.SpotInstanceDrainingEnabled.Enabled() {
 		go agent.startSpotInstanceDrainingPoller(agent.ctx, client)
 	}
 
 	// Agent introspection api
-	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, agent.cfg)
+	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, agent.cfg,
+		doctor, agent.getMetricsFactory())
 
 	telemetryMessages := make(chan ecstcs.TelemetryMessage, telemetryChannelDefaultBufferSize)
 	healthMessages := make(chan ecstcs.HealthMessage, telemetryChannelDefaultBufferSize)
@@ -975,13 +1045,16 @@ func (agent *ecsAgent) startAsyncRoutines(
 	// Start serving the endpoint to fetch IAM Role credentials and other task metadata
 	if agent.cfg.TaskMetadataAZDisabled {
 		// send empty availability zone
-		go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, "", agent.vpc)
+		go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, "", agent.vpc,
+			agent.getMetricsFactory(), agent.dataClient)
 	} else {
-		go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, agent.availabilityZone, agent.vpc)
+		go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, agent.availabilityZone, agent.vpc,
+			agent.getMetricsFactory(), agent.dataClient)
 	}
 
 	// Start sending events to the backend
-	go eventhandler.HandleEngineEvents(agent.ctx, taskEngine, client, taskHandler, attachmentEventHandler)
+	go eventhandler.HandleEngineEvents(agent.ctx, taskEngine, client, taskHandler, attachmentEventHandler,
+		agent.newLocalEventSubscribers()...)
 
 	err := statsEngine.MustInit(agent.ctx, taskEngine, agent.cfg.Cluster, agent.containerInstanceARN)
 	if err != nil {
@@ -990,8 +1063,13 @@ func (agent *ecsAgent) startAsyncRoutines(
 	}
 	go statsEngine.StartMetricsPublish()
 
+	tcsTelemetryMessages, startTelemetrySession := agent.startLocalMetricsSink(telemetryMessages, healthMessages)
+	if !startTelemetrySession {
+		return
+	}
+
 	session, err := reporter.NewDockerTelemetrySession(agent.containerInstanceARN, agent.credentialProvider, agent.cfg, deregisterInstanceEventStream,
-		client, taskEngine, telemetryMessages, healthMessages, doctor)
+		client, taskEngine, tcsTelemetryMessages, healthMessages, doctor, agent.getMetricsFactory())
 	if err != nil {
 		seelog.Warnf("Error creating telemetry session: %v", err)
 		return
@@ -1004,6 +1082,67 @@ func (agent *ecsAgent) startAsyncRoutines(
 	go session.Start(agent.ctx)
 }
 
+// startLocalMetricsSink starts publishing the metrics of the stats engine to the local metrics sink, if
+// one is configured. It returns the channel from which the telemetry session should read the metrics, and
+// whether the telemetry session should be started at all.
+func (agent *ecsAgent) startLocalMetricsSink(
+	telemetryMessages <-chan ecstcs.TelemetryMessage,
+	healthMessages <-chan ecstcs.HealthMessage,
+) (<-chan ecstcs.TelemetryMessage, bool) {
+	if agent.cfg.LocalMetricsSink == "" {
+		return telemetryMessages, true
+	}
+	metricsSink, err := sink.New(agent.cfg.LocalMetricsSink, agent.cfg.LocalMetricsEndpoint)
+	if err != nil {
+		seelog.Warnf("Error creating local metrics sink: %v", err)
+		return telemetryMessages, true
+	}
+
+	if agent.cfg.LocalMetricsOnly.Enabled() {
+		seelog.Infof("Publishing metrics to the local %s sink instead of the telemetry session", agent.cfg.LocalMetricsSink)
+		go sink.Run(agent.ctx, metricsSink, telemetryMessages, nil)
+		// Nothing reports the health metrics without the telemetry session, discard them
+		// so that the stats engine doesn't time out publishing them.
+		go func() {
+			for {
+				select {
+				case <-healthMessages:
+				case <-agent.ctx.Done():
+					return
+				}
+			}
+		}()
+		return nil, false
+	}
+
+	seelog.Infof("Publishing metrics to the local %s sink alongside the telemetry session", agent.cfg.LocalMetricsSink)
+	tcsTelemetryMessages := make(chan ecstcs.TelemetryMessage, telemetryChannelDefaultBufferSize)
+	go sink.Run(agent.ctx, metricsSink, telemetryMessages, tcsTelemetryMessages)
+	return tcsTelemetryMessages, true
+}
+
+// newLocalEventSubscribers returns the subscriber delivering the state change events to the
+// configured webhooks and Unix socket, if any
+func (agent *ecsAgent) newLocalEventSubscribers() []eventhandler.EventSubscriber {
+	var sinks []localsink.Sink
+	queueRootDir := filepath.Join(agent.cfg.DataDir, eventQueueDir)
+	for _, webhookURL := range agent.cfg.EventWebhookURLs {
+		sink, err := localsink.NewWebhookSink(webhookURL, queueRootDir, agent.cfg.EventWebhookQueueSize)
+		if err != nil {
+			seelog.Warnf("Unable to set up event webhook %s: %v", webhookURL, err)
+			continue
+		}
+		sinks = append(sinks, sink)
+	}
+	if agent.cfg.EventSocketPath != "" {
+		sinks = append(sinks, localsink.NewSocketSink(agent.cfg.EventSocketPath))
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	return []eventhandler.EventSubscriber{localsink.NewSubscriber(agent.ctx, sinks...)}
+}
+
 func (agent *ecsAgent) startSpotInstanceDrainingPoller(ctx context.Context, client ecs.ECSClient) {
 	for !agent.spotInstanceDrainingPoller(client) {
 		select {
@@ -1104,7 +1243,7 @@ func (agent *ecsAgent) startACSSession(
 		agent.credentialsCache,
 		inactiveInstanceCB,
 		acsclient.NewACSClientFactory(),
-		metricsfactory.NewNopEntryFactory(),
+		agent.getMetricsFactory(),
 		version.Version,
 		version.GitHashString(),
 		dockerVersion,
@@ -0,0 +1,100 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"path/filepath"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
+	"github.com/aws/amazon-ecs-agent/agent/handlers"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/amazon-ecs-agent/agent/standalone"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
+
+	"github.com/cihub/seelog"
+)
+
+// standaloneStateChangeLogPath returns the path to the file the state changes are written to
+// in standalone mode
+func (agent *ecsAgent) standaloneStateChangeLogPath() string {
+	if agent.cfg.StandaloneStateChangeLog != "" {
+		return agent.cfg.StandaloneStateChangeLog
+	}
+	return filepath.Join(agent.cfg.DataDir, standaloneStateChangeLogFile)
+}
+
+// startStandalone runs the tasks of the files in the standalone tasks directory, without
+// registering the container instance nor starting the ACS and telemetry sessions. The
+// client writes the state changes to the local state change log. It blocks until the
+// agent is stopped.
+func (agent *ecsAgent) startStandalone(
+	containerChangeEventStream *eventstream.EventStream,
+	credentialsManager credentials.Manager,
+	state dockerstate.TaskEngineState,
+	imageManager engine.ImageManager,
+	taskEngine engine.TaskEngine,
+	client ecs.ECSClient) int {
+	logger.Info("Starting in standalone mode", logger.Fields{
+		"tasksDir":       agent.cfg.StandaloneTasksDir,
+		"stateChangeLog": agent.standaloneStateChangeLogPath(),
+	})
+
+	doctor, err := agent.newDoctorWithHealthchecks(agent.cfg.Cluster, agent.containerInstanceARN)
+	if err != nil {
+		seelog.Warnf("Error starting doctor, healthchecks won't be running: %v", err)
+	}
+
+	taskEngine.SetDataClient(agent.dataClient)
+	imageManager.SetDataClient(agent.dataClient)
+	if dockerTaskEngine, ok := taskEngine.(*engine.DockerTaskEngine); ok {
+		dockerTaskEngine.SetMetricsFactory(agent.getMetricsFactory())
+		dockerTaskEngine.SetTaskTagsGetter(client)
+	}
+	taskEngine.MustInit(agent.ctx)
+
+	if !agent.cfg.ImageCleanupDisabled.Enabled() {
+		go imageManager.StartImageCleanupProcess(agent.ctx)
+	}
+
+	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, agent.cfg,
+		doctor, agent.getMetricsFactory())
+
+	// The stats engine serves the stats of the task metadata endpoint, the metrics aren't
+	// published without a telemetry session
+	telemetryMessages := make(chan ecstcs.TelemetryMessage, telemetryChannelDefaultBufferSize)
+	healthMessages := make(chan ecstcs.HealthMessage, telemetryChannelDefaultBufferSize)
+	statsEngine := stats.NewDockerStatsEngine(agent.cfg, agent.dockerClient, containerChangeEventStream,
+		telemetryMessages, healthMessages, agent.dataClient)
+	if err := statsEngine.MustInit(agent.ctx, taskEngine, agent.cfg.Cluster, agent.containerInstanceARN); err != nil {
+		seelog.Warnf("Error initializing metrics engine: %v", err)
+	}
+	go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN,
+		agent.cfg, statsEngine, "", agent.vpc, agent.getMetricsFactory(), agent.dataClient)
+
+	taskHandler := eventhandler.NewTaskHandler(agent.ctx, agent.dataClient, state, client)
+	attachmentEventHandler := eventhandler.NewAttachmentEventHandler(agent.ctx, agent.dataClient, client)
+	go eventhandler.HandleEngineEvents(agent.ctx, taskEngine, client, taskHandler, attachmentEventHandler,
+		agent.newLocalEventSubscribers()...)
+
+	standalone.NewTaskFileWatcher(agent.cfg.StandaloneTasksDir, agent.cfg.Cluster, agent.cfg.AWSRegion,
+		taskEngine, agent.dataClient).Run(agent.ctx)
+	return exitcodes.ExitSuccess
+}
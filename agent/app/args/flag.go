@@ -28,6 +28,10 @@ const (
 	blacholeEC2MetadataUsage = "Blackhole the EC2 Metadata requests. Setting this option can cause the ECS Agent to fail to work properly.  We do not recommend setting this option"
 	windowsServiceUsage      = "Run the ECS agent as a Windows Service"
 	healthcheckServiceUsage  = "Run the agent healthcheck"
+	stateExportUsage         = "Write the agent's persisted state as JSON to the given file ('-' for stdout) and exit. The agent must not be running"
+	stateImportUsage         = "Load the agent's persisted state from the given JSON file, as written by -state-export, and exit. The agent must not be running"
+	stateBackendUsage        = "Data backend used by -state-export and -state-import: [<boltdb>|<wal>]. Defaults to ECS_DATA_BACKEND"
+	stateDataDirUsage        = "Directory holding the state used by -state-export and -state-import. Defaults to ECS_DATADIR"
 
 	versionFlagName              = "version"
 	logLevelFlagName             = "loglevel"
@@ -39,6 +43,10 @@ const (
 	blackholeEC2MetadataFlagName = "blackhole-ec2-metadata"
 	windowsServiceFlagName       = "windows-service"
 	healthCheckFlagName          = "healthcheck"
+	stateExportFlagName          = "state-export"
+	stateImportFlagName          = "state-import"
+	stateBackendFlagName         = "state-backend"
+	stateDataDirFlagName         = "state-data-dir"
 )
 
 // Args wraps various ECS Agent arguments
@@ -65,6 +73,14 @@ type Args struct {
 	WindowsService *bool
 	// Healthcheck indicates that agent should run healthcheck
 	Healthcheck *bool
+	// StateExport is the file to which the persisted state should be exported
+	StateExport *string
+	// StateImport is the file from which the persisted state should be imported
+	StateImport *string
+	// StateBackend is the data backend used to export or import the persisted state
+	StateBackend *string
+	// StateDataDir is the directory holding the persisted state to export or import
+	StateDataDir *string
 }
 
 // New creates a new Args object from the argument list
@@ -82,6 +98,10 @@ func New(arguments []string) (*Args, error) {
 		ECSAttributes:        flagset.Bool(ecsAttributesFlagName, false, ecsAttributesUsage),
 		WindowsService:       flagset.Bool(windowsServiceFlagName, false, windowsServiceUsage),
 		Healthcheck:          flagset.Bool(healthCheckFlagName, false, healthcheckServiceUsage),
+		StateExport:          flagset.String(stateExportFlagName, "", stateExportUsage),
+		StateImport:          flagset.String(stateImportFlagName, "", stateImportUsage),
+		StateBackend:         flagset.String(stateBackendFlagName, "", stateBackendUsage),
+		StateDataDir:         flagset.String(stateDataDirFlagName, "", stateDataDirUsage),
 	}
 
 	err := flagset.Parse(arguments)
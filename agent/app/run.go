@@ -58,6 +58,10 @@ func Run(arguments []string) int {
 		}
 		healthcheckUrl := fmt.Sprintf("http://%s:51678/v1/metadata", localhost)
 		return runHealthcheck(healthcheckUrl, time.Second*25)
+	} else if *parsedArgs.StateExport != "" {
+		return runStateExport(*parsedArgs.StateBackend, *parsedArgs.StateDataDir, *parsedArgs.StateExport)
+	} else if *parsedArgs.StateImport != "" {
+		return runStateImport(*parsedArgs.StateBackend, *parsedArgs.StateDataDir, *parsedArgs.StateImport)
 	}
 
 	if *parsedArgs.LogLevel != "" {
@@ -0,0 +1,114 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+
+	"github.com/cihub/seelog"
+)
+
+const stdioPath = "-"
+
+// runStateExport writes the state persisted in dataDir with the given backend as JSON to
+// the file at path, or to stdout if path is "-".
+func runStateExport(backend, dataDir, path string) int {
+	dataClient, err := newStateDataClient(backend, dataDir, data.NewExistingWithBackend)
+	if err != nil {
+		seelog.Criticalf("Unable to open agent state: %v", err)
+		return exitcodes.ExitTerminal
+	}
+	defer dataClient.Close()
+
+	state, err := data.Export(dataClient)
+	if err != nil {
+		seelog.Criticalf("Unable to read agent state: %v", err)
+		return exitcodes.ExitError
+	}
+	out, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		seelog.Criticalf("Unable to marshal agent state: %v", err)
+		return exitcodes.ExitError
+	}
+	out = append(out, '\n')
+	if path == stdioPath {
+		_, err = os.Stdout.Write(out)
+	} else {
+		err = os.WriteFile(path, out, 0600)
+	}
+	if err != nil {
+		seelog.Criticalf("Unable to write agent state to %s: %v", path, err)
+		return exitcodes.ExitError
+	}
+	return exitcodes.ExitSuccess
+}
+
+// runStateImport saves the state read as JSON from the file at path, or from stdin if
+// path is "-", to dataDir with the given backend.
+func runStateImport(backend, dataDir, path string) int {
+	var (
+		in  []byte
+		err error
+	)
+	if path == stdioPath {
+		in, err = io.ReadAll(os.Stdin)
+	} else {
+		in, err = os.ReadFile(path)
+	}
+	if err != nil {
+		seelog.Criticalf("Unable to read agent state from %s: %v", path, err)
+		return exitcodes.ExitTerminal
+	}
+	state := &data.State{}
+	if err := json.Unmarshal(in, state); err != nil {
+		seelog.Criticalf("Unable to parse agent state from %s: %v", path, err)
+		return exitcodes.ExitTerminal
+	}
+
+	dataClient, err := newStateDataClient(backend, dataDir, data.NewWithBackend)
+	if err != nil {
+		seelog.Criticalf("Unable to open agent state: %v", err)
+		return exitcodes.ExitTerminal
+	}
+	defer dataClient.Close()
+
+	if err := data.Import(dataClient, state); err != nil {
+		seelog.Criticalf("Unable to save agent state: %v", err)
+		return exitcodes.ExitError
+	}
+	return exitcodes.ExitSuccess
+}
+
+// newStateDataClient opens the persisted state with open, falling back to the agent's
+// environment configuration for the backend and the data directory.
+func newStateDataClient(backend, dataDir string,
+	open func(backend, dataDir string) (data.Client, error)) (data.Client, error) {
+	if backend == "" {
+		backend = strings.ToLower(os.Getenv("ECS_DATA_BACKEND"))
+	}
+	if dataDir == "" {
+		dataDir = os.Getenv("ECS_DATADIR")
+	}
+	if dataDir == "" {
+		dataDir = config.DefaultConfig().DataDir
+	}
+	return open(backend, dataDir)
+}
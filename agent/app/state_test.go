@@ -0,0 +1,79 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStateTaskArn = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/abc"
+
+func TestStateExportImport(t *testing.T) {
+	sourceDir, destinationDir := t.TempDir(), t.TempDir()
+	source, err := data.NewWAL(sourceDir)
+	require.NoError(t, err)
+	require.NoError(t, source.SaveTask(&apitask.Task{Arn: testStateTaskArn}))
+	require.NoError(t, source.SaveMetadata(data.ClusterNameKey, "test-cluster"))
+	require.NoError(t, source.Close())
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	require.Equal(t, exitcodes.ExitSuccess, runStateExport(data.WALBackend, sourceDir, statePath))
+	require.Equal(t, exitcodes.ExitSuccess, runStateImport(data.WALBackend, destinationDir, statePath))
+
+	destination, err := data.NewWAL(destinationDir)
+	require.NoError(t, err)
+	defer destination.Close()
+	tasks, err := destination.GetTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, testStateTaskArn, tasks[0].Arn)
+	cluster, err := destination.GetMetadata(data.ClusterNameKey)
+	require.NoError(t, err)
+	assert.Equal(t, "test-cluster", cluster)
+}
+
+func TestStateImportInvalidFile(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(statePath, []byte("not json"), 0600))
+	assert.Equal(t, exitcodes.ExitTerminal, runStateImport(data.WALBackend, t.TempDir(), statePath))
+	assert.Equal(t, exitcodes.ExitTerminal, runStateImport(data.WALBackend, t.TempDir(), filepath.Join(t.TempDir(), "missing.json")))
+}
+
+func TestStateExportUnsupportedBackend(t *testing.T) {
+	assert.Equal(t, exitcodes.ExitTerminal, runStateExport("sqlite", t.TempDir(), "-"))
+}
+
+func TestStateExportMissingState(t *testing.T) {
+	for _, backend := range []string{data.BoltDBBackend, data.WALBackend} {
+		t.Run(backend, func(t *testing.T) {
+			dataDir := t.TempDir()
+			assert.Equal(t, exitcodes.ExitTerminal, runStateExport(backend, dataDir, "-"))
+			entries, err := os.ReadDir(dataDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries, "no empty state is created by the export")
+		})
+	}
+}
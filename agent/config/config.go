@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"reflect"
 	"strings"
@@ -70,6 +71,22 @@ const (
 	// remove the images pulled by agent.
 	DefaultImageCleanupTimeInterval = 30 * time.Minute
 
+	// DefaultImageCleanupDiskCheckInterval specifies the default interval at which the disk usage is checked
+	// when disk pressure image cleanup is enabled.
+	DefaultImageCleanupDiskCheckInterval = time.Minute
+
+	// DefaultImageCleanupWatermarkGap specifies the default gap between the high and the low watermarks of
+	// disk pressure image cleanup, when no low watermark is configured.
+	DefaultImageCleanupWatermarkGap = 10
+
+	// DefaultImagePrefetchPinDuration specifies the default duration for which prefetched images are kept
+	// from being removed by the image cleanup.
+	DefaultImagePrefetchPinDuration = 3 * time.Hour
+
+	// DefaultDebugLogDuration specifies the default duration for which the log level stays at
+	// debug after the agent receives a SIGHUP.
+	DefaultDebugLogDuration = 15 * time.Minute
+
 	// DefaultNumImagesToDeletePerCycle specifies the default number of images to delete when agent performs
 	// image cleanup.
 	DefaultNumImagesToDeletePerCycle = 5
@@ -78,6 +95,14 @@ const (
 	// nonecs containers cleanup.
 	DefaultNumNonECSContainersToDeletePerCycle = 5
 
+	// DefaultTaskQueuePriorityTag is the default key of the task tag holding the priority
+	// of a task waiting for host resources
+	DefaultTaskQueuePriorityTag = "ecs-agent-queue-priority"
+
+	// DefaultEventWebhookQueueSize specifies the default number of events queued for each
+	// event webhook
+	DefaultEventWebhookQueueSize = 1000
+
 	// DefaultImageDeletionAge specifies the default value for minimum amount of elapsed time after an image
 	// has been pulled before it can be deleted.
 	DefaultImageDeletionAge = 1 * time.Hour
@@ -112,10 +137,26 @@ const (
 	// image cleanup.
 	minimumImageCleanupInterval = 10 * time.Minute
 
+	// minimumSecretRotationInterval specifies the minimum interval at which secrets are
+	// retrieved again when the secret files are enabled
+	minimumSecretRotationInterval = 1 * time.Minute
+
+	// minimumDebugLogDuration specifies the minimum duration for which the log level stays at
+	// debug after the agent receives a SIGHUP
+	minimumDebugLogDuration = 1 * time.Minute
+
+	// minimumImageCleanupDiskCheckInterval specifies the minimum interval at which the disk usage is checked
+	// when disk pressure image cleanup is enabled.
+	minimumImageCleanupDiskCheckInterval = 10 * time.Second
+
 	// minimumNumImagesToDeletePerCycle specifies the minimum number of images that to be deleted when
 	// performing image cleanup.
 	minimumNumImagesToDeletePerCycle = 1
 
+	// localMetricsSinkOTLP and localMetricsSinkStatsD are the supported values of ECS_LOCAL_METRICS_SINK.
+	localMetricsSinkOTLP   = "otlp"
+	localMetricsSinkStatsD = "statsd"
+
 	// defaultCNIPluginsPath is the default path where cni binaries are located
 	defaultCNIPluginsPath = "/amazon-ecs-cni-plugins"
 
@@ -149,6 +190,16 @@ const (
 	DefaultContainerMetricsPublishInterval = 20 * time.Second
 )
 
+const (
+	// ContainerMetadataFormatEnv writes the container metadata as an env-file of shell
+	// variable assignments, in addition to the JSON metadata file.
+	ContainerMetadataFormatEnv = "env"
+
+	// ContainerMetadataFormatYAML writes the container metadata as YAML, in addition to the
+	// JSON metadata file.
+	ContainerMetadataFormatYAML = "yaml"
+)
+
 const (
 	// ImagePullDefaultBehavior specifies the behavior that if an image pull API call fails,
 	// agent tries to start from the Docker image cache anyway, assuming that the image has not changed.
@@ -168,6 +219,34 @@ const (
 	ImagePullPreferCachedBehavior
 )
 
+const (
+	// TaskQueueFIFOPolicy specifies that the tasks waiting for host resources are started
+	// in the order they were received. A task that doesn't fit blocks the tasks after it.
+	TaskQueueFIFOPolicy TaskQueuePolicyType = iota
+
+	// TaskQueueFirstFitPolicy specifies that the tasks waiting for host resources are
+	// started as soon as they fit, in the order they were received, so that smaller tasks
+	// can start while a larger task waits.
+	TaskQueueFirstFitPolicy
+
+	// TaskQueuePriorityPolicy specifies that the tasks waiting for host resources are
+	// started in decreasing order of the priority set in their TaskQueuePriorityTag tag,
+	// and in the order they were received for the same priority.
+	TaskQueuePriorityPolicy
+)
+
+// String returns the value of ECS_TASK_QUEUE_POLICY corresponding to the policy
+func (policy TaskQueuePolicyType) String() string {
+	switch policy {
+	case TaskQueueFirstFitPolicy:
+		return "first-fit"
+	case TaskQueuePriorityPolicy:
+		return "priority"
+	default:
+		return "fifo"
+	}
+}
+
 const (
 	// When ContainerInstancePropagateTagsFromNoneType is specified, no DescribeTags
 	// API call will be made.
@@ -358,6 +437,17 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		cfg.ImageCleanupInterval = DefaultImageCleanupTimeInterval
 	}
 
+	if cfg.SecretRotationInterval > 0 && cfg.SecretRotationInterval < minimumSecretRotationInterval {
+		seelog.Warnf("ECS_SECRET_ROTATION_INTERVAL parsed value (%s) is less than the minimum of %s. Setting rotation interval to minimum.",
+			cfg.SecretRotationInterval, minimumSecretRotationInterval)
+		cfg.SecretRotationInterval = minimumSecretRotationInterval
+	}
+
+	if cfg.DebugLogDuration < minimumDebugLogDuration {
+		seelog.Warnf("Invalid value for ECS_DEBUG_LOG_DURATION, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultDebugLogDuration.String(), cfg.DebugLogDuration, minimumDebugLogDuration)
+		cfg.DebugLogDuration = DefaultDebugLogDuration
+	}
+
 	if cfg.NumImagesToDeletePerCycle < minimumNumImagesToDeletePerCycle {
 		seelog.Warnf("Invalid value for number of images to delete for image cleanup, will be overridden with the default value: %d. Parsed value: %d, minimum value: %d.", DefaultImageDeletionAge, cfg.NumImagesToDeletePerCycle, minimumNumImagesToDeletePerCycle)
 		cfg.NumImagesToDeletePerCycle = DefaultNumImagesToDeletePerCycle
@@ -369,9 +459,27 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		cfg.TaskMetadataBurstRate = DefaultTaskMetadataBurstRate
 	}
 
+	if cfg.EventWebhookQueueSize <= 0 {
+		seelog.Warnf("Invalid value for ECS_EVENT_WEBHOOK_QUEUE_SIZE, will be overridden with the default value: %d. Parsed value: %d.", DefaultEventWebhookQueueSize, cfg.EventWebhookQueueSize)
+		cfg.EventWebhookQueueSize = DefaultEventWebhookQueueSize
+	}
+
+	if cfg.TaskBridgeSubnet != "" {
+		if ip, _, err := net.ParseCIDR(cfg.TaskBridgeSubnet); err != nil || ip.To4() == nil {
+			seelog.Warnf("Invalid value for ECS_TASK_BRIDGE_SUBNET, will be overridden with the default subnet. Parsed value: %s, expected an IPv4 CIDR block.", cfg.TaskBridgeSubnet)
+			cfg.TaskBridgeSubnet = ""
+		}
+	}
+
+	cfg.imageCleanupWatermarkOverrides()
+
 	// check the PollMetrics specific configurations
 	cfg.pollMetricsOverrides()
 
+	cfg.containerRestartOverrides()
+
+	cfg.localMetricsOverrides()
+
 	cfg.platformOverrides()
 
 	return nil
@@ -393,6 +501,75 @@ func (cfg *Config) pollMetricsOverrides() {
 	}
 }
 
+func (cfg *Config) imageCleanupWatermarkOverrides() {
+	if cfg.ImageCleanupHighWatermarkPercent < 0 || cfg.ImageCleanupHighWatermarkPercent > 100 {
+		seelog.Warnf("Invalid value for ECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT, disk pressure image cleanup will be disabled. Parsed value: %d, expected a value between 1 and 100.",
+			cfg.ImageCleanupHighWatermarkPercent)
+		cfg.ImageCleanupHighWatermarkPercent = 0
+	}
+	if cfg.ImageCleanupHighWatermarkPercent == 0 {
+		return
+	}
+	if cfg.ImageCleanupDiskPath == "" && imageCleanupDiskPathRequired {
+		seelog.Warn("ECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT is set without ECS_IMAGE_CLEANUP_DISK_PATH, disk pressure image cleanup will be disabled. Mount the Docker data root into the Agent container and set ECS_IMAGE_CLEANUP_DISK_PATH to its path in the container.")
+		cfg.ImageCleanupHighWatermarkPercent = 0
+		return
+	}
+
+	if cfg.ImageCleanupLowWatermarkPercent < 0 || cfg.ImageCleanupLowWatermarkPercent >= cfg.ImageCleanupHighWatermarkPercent {
+		seelog.Warnf("Invalid value for ECS_IMAGE_CLEANUP_LOW_WATERMARK_PERCENT, will be overridden with the default value. Parsed value: %d, expected a value below the high watermark of %d.",
+			cfg.ImageCleanupLowWatermarkPercent, cfg.ImageCleanupHighWatermarkPercent)
+		cfg.ImageCleanupLowWatermarkPercent = 0
+	}
+	if cfg.ImageCleanupLowWatermarkPercent == 0 {
+		cfg.ImageCleanupLowWatermarkPercent = cfg.ImageCleanupHighWatermarkPercent - DefaultImageCleanupWatermarkGap
+		if cfg.ImageCleanupLowWatermarkPercent < 0 {
+			cfg.ImageCleanupLowWatermarkPercent = 0
+		}
+	}
+
+	if cfg.ImageCleanupDiskCheckInterval == 0 {
+		cfg.ImageCleanupDiskCheckInterval = DefaultImageCleanupDiskCheckInterval
+	} else if cfg.ImageCleanupDiskCheckInterval < minimumImageCleanupDiskCheckInterval {
+		seelog.Warnf("ECS_IMAGE_CLEANUP_DISK_CHECK_INTERVAL parsed value (%v) is less than the minimum of %v. Setting it to the minimum.",
+			cfg.ImageCleanupDiskCheckInterval, minimumImageCleanupDiskCheckInterval)
+		cfg.ImageCleanupDiskCheckInterval = minimumImageCleanupDiskCheckInterval
+	}
+}
+
+func (cfg *Config) localMetricsOverrides() {
+	switch cfg.LocalMetricsSink {
+	case "", localMetricsSinkOTLP, localMetricsSinkStatsD:
+	default:
+		seelog.Warnf("Invalid value for ECS_LOCAL_METRICS_SINK, local metrics will be disabled. Parsed value: %s, expected %s or %s.",
+			cfg.LocalMetricsSink, localMetricsSinkOTLP, localMetricsSinkStatsD)
+		cfg.LocalMetricsSink = ""
+	}
+	if cfg.LocalMetricsSink == "" && cfg.LocalMetricsOnly.Enabled() {
+		seelog.Warn("ECS_LOCAL_METRICS_ONLY is set without a valid ECS_LOCAL_METRICS_SINK, metrics will be sent to the ECS telemetry endpoint.")
+		cfg.LocalMetricsOnly = BooleanDefaultFalse{Value: ExplicitlyDisabled}
+	}
+}
+
+func (cfg *Config) containerRestartOverrides() {
+	if cfg.ContainerRestartMaxAttempts < 0 {
+		seelog.Warnf("Invalid value for ECS_CONTAINER_RESTART_MAX_ATTEMPTS, will be overridden to 0 (unlimited). Parsed value: %d.",
+			cfg.ContainerRestartMaxAttempts)
+		cfg.ContainerRestartMaxAttempts = 0
+	}
+	if cfg.ContainerRestartBackoffInitialDelay < 0 {
+		seelog.Warnf("Invalid value for ECS_CONTAINER_RESTART_BACKOFF_INITIAL_DELAY, will be overridden to 0 (disabled). Parsed value: %v.",
+			cfg.ContainerRestartBackoffInitialDelay)
+		cfg.ContainerRestartBackoffInitialDelay = 0
+	}
+	if cfg.ContainerRestartBackoffMaxDelay != 0 &&
+		cfg.ContainerRestartBackoffMaxDelay < cfg.ContainerRestartBackoffInitialDelay {
+		seelog.Warnf("ECS_CONTAINER_RESTART_BACKOFF_MAX_DELAY parsed value (%v) is less than the initial delay of %v. Setting it to the initial delay.",
+			cfg.ContainerRestartBackoffMaxDelay, cfg.ContainerRestartBackoffInitialDelay)
+		cfg.ContainerRestartBackoffMaxDelay = cfg.ContainerRestartBackoffInitialDelay
+	}
+}
+
 // checkMissingAndDeprecated checks all zero-valued fields for tags of the form
 // missing:STRING and acts based on that string. Current options are: fatal,
 // warn. Fatal will result in an error being returned, warn will result in a
@@ -527,6 +704,8 @@ func environmentConfig() (Config, error) {
 
 	additionalLocalRoutes, errs := parseAdditionalLocalRoutes(errs)
 
+	egressPolicy, errs := parseEgressPolicy(errs)
+
 	var err error
 	if len(errs) > 0 {
 		err = apierrors.NewMultiError(errs...)
@@ -540,11 +719,16 @@ func environmentConfig() (Config, error) {
 		ReservedPortsUDP:                    parseReservedPorts("ECS_RESERVED_PORTS_UDP"),
 		DataDir:                             dataDir,
 		Checkpoint:                          parseCheckpoint(dataDir),
+		DataBackend:                         strings.ToLower(os.Getenv("ECS_DATA_BACKEND")),
 		EngineAuthType:                      os.Getenv("ECS_ENGINE_AUTH_TYPE"),
 		EngineAuthData:                      NewSensitiveRawMessage([]byte(os.Getenv("ECS_ENGINE_AUTH_DATA"))),
 		UpdatesEnabled:                      parseBooleanDefaultFalseConfig("ECS_UPDATES_ENABLED"),
 		UpdateDownloadDir:                   os.Getenv("ECS_UPDATE_DOWNLOAD_DIR"),
+		UpdateSigningPublicKey:              os.Getenv("ECS_UPDATE_SIGNING_PUBLIC_KEY"),
 		DisableMetrics:                      parseBooleanDefaultFalseConfig("ECS_DISABLE_METRICS"),
+		LocalMetricsSink:                    strings.ToLower(os.Getenv("ECS_LOCAL_METRICS_SINK")),
+		LocalMetricsEndpoint:                os.Getenv("ECS_LOCAL_METRICS_ENDPOINT"),
+		LocalMetricsOnly:                    parseBooleanDefaultFalseConfig("ECS_LOCAL_METRICS_ONLY"),
 		ReservedMemory:                      parseEnvVariableUint16("ECS_RESERVED_MEMORY"),
 		AvailableLoggingDrivers:             parseAvailableLoggingDrivers(),
 		PrivilegedDisabled:                  parseBooleanDefaultFalseConfig("ECS_DISABLE_PRIVILEGED"),
@@ -571,14 +755,26 @@ func environmentConfig() (Config, error) {
 		NonECSMinimumImageDeletionAge:       parseEnvVariableDuration("NON_ECS_IMAGE_MINIMUM_CLEANUP_AGE"),
 		ImageCleanupInterval:                parseEnvVariableDuration("ECS_IMAGE_CLEANUP_INTERVAL"),
 		NumImagesToDeletePerCycle:           parseNumImagesToDeletePerCycle(),
+		ImageCleanupHighWatermarkPercent:    parseImageCleanupWatermarkPercent("ECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT"),
+		ImageCleanupLowWatermarkPercent:     parseImageCleanupWatermarkPercent("ECS_IMAGE_CLEANUP_LOW_WATERMARK_PERCENT"),
+		ImageCleanupDiskCheckInterval:       parseEnvVariableDuration("ECS_IMAGE_CLEANUP_DISK_CHECK_INTERVAL"),
+		ImageCleanupDiskPath:                os.Getenv("ECS_IMAGE_CLEANUP_DISK_PATH"),
+		ImagePrefetchFile:                   os.Getenv("ECS_IMAGE_PREFETCH_FILE"),
+		ImagePrefetchPinDuration:            parseEnvVariableDuration("ECS_IMAGE_PREFETCH_PIN_DURATION"),
 		NumNonECSContainersToDeletePerCycle: parseNumNonECSContainersToDeletePerCycle(),
 		ImagePullBehavior:                   parseImagePullBehavior(),
+		TaskQueuePolicy:                     parseTaskQueuePolicy(),
+		TaskQueuePriorityTag:                os.Getenv("ECS_TASK_QUEUE_PRIORITY_TAG"),
 		ImageCleanupExclusionList:           parseImageCleanupExclusionList("ECS_EXCLUDE_UNTRACKED_IMAGE"),
 		InstanceAttributes:                  instanceAttributes,
 		CNIPluginsPath:                      os.Getenv("ECS_CNI_PLUGINS_PATH"),
 		AWSVPCBlockInstanceMetdata:          parseBooleanDefaultFalseConfig("ECS_AWSVPC_BLOCK_IMDS"),
 		AWSVPCAdditionalLocalRoutes:         additionalLocalRoutes,
+		AWSVPCEgressPolicy:                  egressPolicy,
+		TaskBridgeEnabled:                   parseBooleanDefaultFalseConfig("ECS_ENABLE_TASK_BRIDGE"),
+		TaskBridgeSubnet:                    os.Getenv("ECS_TASK_BRIDGE_SUBNET"),
 		ContainerMetadataEnabled:            parseBooleanDefaultFalseConfig("ECS_ENABLE_CONTAINER_METADATA"),
+		ContainerMetadataFormats:            parseContainerMetadataFormats(),
 		DataDirOnHost:                       os.Getenv("ECS_HOST_DATA_DIR"),
 		OverrideAWSLogsExecutionRole:        parseBooleanDefaultFalseConfig("ECS_ENABLE_AWSLOGS_EXECUTIONROLE_OVERRIDE"),
 		CgroupPath:                          os.Getenv("ECS_CGROUP_PATH"),
@@ -590,6 +786,17 @@ func environmentConfig() (Config, error) {
 		PollMetrics:                         parseBooleanDefaultFalseConfig("ECS_POLL_METRICS"),
 		PollingMetricsWaitDuration:          parseEnvVariableDuration("ECS_POLLING_METRICS_WAIT_DURATION"),
 		DisableDockerHealthCheck:            parseBooleanDefaultFalseConfig("ECS_DISABLE_DOCKER_HEALTH_CHECK"),
+		CustomHealthchecksDir:               os.Getenv("ECS_CUSTOM_HEALTHCHECKS_DIR"),
+		StandaloneTasksDir:                  os.Getenv("ECS_STANDALONE_TASKS_DIR"),
+		StandaloneStateChangeLog:            os.Getenv("ECS_STANDALONE_STATE_CHANGE_LOG"),
+		SecretRotationInterval:              parseEnvVariableDuration("ECS_SECRET_ROTATION_INTERVAL"),
+		SecretFilesDir:                      os.Getenv("ECS_SECRET_FILES_DIR"),
+		IntrospectionLogConfigToken:         NewSensitiveRawMessage([]byte(os.Getenv("ECS_INTROSPECTION_LOG_CONFIG_TOKEN"))),
+		IntrospectionImagePrefetchToken:     NewSensitiveRawMessage([]byte(os.Getenv("ECS_INTROSPECTION_IMAGE_PREFETCH_TOKEN"))),
+		DebugLogDuration:                    parseEnvVariableDuration("ECS_DEBUG_LOG_DURATION"),
+		EventWebhookURLs:                    parseEventWebhookURLs(),
+		EventWebhookQueueSize:               parseEventWebhookQueueSize(),
+		EventSocketPath:                     os.Getenv("ECS_EVENT_SOCKET_PATH"),
 		GPUSupportEnabled:                   utils.ParseBool(os.Getenv("ECS_ENABLE_GPU_SUPPORT"), false),
 		EBSTASupportEnabled:                 utils.ParseBool(os.Getenv("ECS_EBSTA_SUPPORTED"), true),
 		InferentiaSupportEnabled:            utils.ParseBool(os.Getenv("ECS_ENABLE_INF_SUPPORT"), false),
@@ -608,6 +815,11 @@ func environmentConfig() (Config, error) {
 		DynamicHostPortRange:                parseDynamicHostPortRange("ECS_DYNAMIC_HOST_PORT_RANGE"),
 		TaskPidsLimit:                       parseTaskPidsLimit(),
 		FirelensAsyncEnabled:                parseBooleanDefaultTrueConfig("ECS_ENABLE_FIRELENS_ASYNC"),
+		ContainerRestartMaxAttempts:         parseContainerRestartMaxAttempts(),
+		ContainerRestartBackoffInitialDelay: parseEnvVariableDuration("ECS_CONTAINER_RESTART_BACKOFF_INITIAL_DELAY"),
+		ContainerRestartBackoffMaxDelay:     parseEnvVariableDuration("ECS_CONTAINER_RESTART_BACKOFF_MAX_DELAY"),
+		ContainerRestartStopTaskOnMaxAttempts: parseBooleanDefaultFalseConfig(
+			"ECS_CONTAINER_RESTART_STOP_TASK_ON_MAX_ATTEMPTS"),
 	}, err
 }
 
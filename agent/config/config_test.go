@@ -28,6 +28,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	ec2testutil "github.com/aws/amazon-ecs-agent/agent/utils/test/ec2util"
 	mock_ec2 "github.com/aws/amazon-ecs-agent/ecs-agent/ec2/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 
 	"github.com/golang/mock/gomock"
@@ -321,6 +322,13 @@ func TestDefaultCheckpointWithECSDataDir(t *testing.T) {
 	assert.True(t, conf.Checkpoint.Enabled())
 }
 
+func TestDataBackend(t *testing.T) {
+	defer setTestEnv("ECS_DATA_BACKEND", "WAL")()
+	conf, err := environmentConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "wal", conf.DataBackend)
+}
+
 func TestCheckpointWithoutECSDataDir(t *testing.T) {
 	defer setTestEnv("ECS_CHECKPOINT", "true")()
 	conf, err := environmentConfig()
@@ -685,6 +693,197 @@ func TestImageCleanupMinimumNumImagesToDeletePerCycle(t *testing.T) {
 	assert.Equal(t, DefaultNumImagesToDeletePerCycle, cfg.NumImagesToDeletePerCycle, "Wrong value for NumImagesToDeletePerCycle")
 }
 
+func TestImageCleanupWatermarkConfig(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT", "85")()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_LOW_WATERMARK_PERCENT", "60")()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_CHECK_INTERVAL", "30s")()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_PATH", "/host/docker")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, 85, cfg.ImageCleanupHighWatermarkPercent)
+	assert.Equal(t, 60, cfg.ImageCleanupLowWatermarkPercent)
+	assert.Equal(t, 30*time.Second, cfg.ImageCleanupDiskCheckInterval)
+	assert.Equal(t, "/host/docker", cfg.ImageCleanupDiskPath)
+}
+
+func TestImageCleanupWatermarkConfigDefaults(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT", "85")()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_PATH", "/host/docker")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, 75, cfg.ImageCleanupLowWatermarkPercent)
+	assert.Equal(t, DefaultImageCleanupDiskCheckInterval, cfg.ImageCleanupDiskCheckInterval)
+}
+
+func TestImagePrefetchConfig(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_PREFETCH_FILE", "/etc/ecs/prefetch.json")()
+	defer setTestEnv("ECS_IMAGE_PREFETCH_PIN_DURATION", "30m")()
+	defer setTestEnv("ECS_INTROSPECTION_IMAGE_PREFETCH_TOKEN", "s3cr3t")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/ecs/prefetch.json", cfg.ImagePrefetchFile)
+	assert.Equal(t, 30*time.Minute, cfg.ImagePrefetchPinDuration)
+	assert.Equal(t, "s3cr3t", string(cfg.IntrospectionImagePrefetchToken.Contents()))
+	assert.NotContains(t, cfg.String(), "s3cr3t")
+}
+
+func TestImagePrefetchConfigDefaults(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.ImagePrefetchFile)
+	assert.Equal(t, DefaultImagePrefetchPinDuration, cfg.ImagePrefetchPinDuration)
+}
+
+func TestImageCleanupWatermarkConfigInvalidValues(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		high, low, interval   string
+		expectedHigh          int
+		expectedLow           int
+		expectedCheckInterval time.Duration
+	}{
+		{
+			name:         "high watermark above 100 disables the mode",
+			high:         "120",
+			low:          "50",
+			expectedHigh: 0,
+			expectedLow:  50,
+		},
+		{
+			name:                  "low watermark above high watermark",
+			high:                  "80",
+			low:                   "90",
+			expectedHigh:          80,
+			expectedLow:           70,
+			expectedCheckInterval: DefaultImageCleanupDiskCheckInterval,
+		},
+		{
+			name:                  "check interval below minimum",
+			high:                  "5",
+			interval:              "1s",
+			expectedHigh:          5,
+			expectedLow:           0,
+			expectedCheckInterval: minimumImageCleanupDiskCheckInterval,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer setTestRegion()()
+			defer setTestEnv("ECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT", tc.high)()
+			defer setTestEnv("ECS_IMAGE_CLEANUP_LOW_WATERMARK_PERCENT", tc.low)()
+			defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_CHECK_INTERVAL", tc.interval)()
+			defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_PATH", "/host/docker")()
+			cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedHigh, cfg.ImageCleanupHighWatermarkPercent)
+			assert.Equal(t, tc.expectedLow, cfg.ImageCleanupLowWatermarkPercent)
+			assert.Equal(t, tc.expectedCheckInterval, cfg.ImageCleanupDiskCheckInterval)
+		})
+	}
+}
+
+func TestLocalMetricsConfig(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_LOCAL_METRICS_SINK", "StatsD")()
+	defer setTestEnv("ECS_LOCAL_METRICS_ENDPOINT", "127.0.0.1:9125")()
+	defer setTestEnv("ECS_LOCAL_METRICS_ONLY", "true")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, "statsd", cfg.LocalMetricsSink)
+	assert.Equal(t, "127.0.0.1:9125", cfg.LocalMetricsEndpoint)
+	assert.True(t, cfg.LocalMetricsOnly.Enabled())
+}
+
+func TestLocalMetricsConfigInvalidSink(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_LOCAL_METRICS_SINK", "graphite")()
+	defer setTestEnv("ECS_LOCAL_METRICS_ONLY", "true")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.LocalMetricsSink)
+	assert.False(t, cfg.LocalMetricsOnly.Enabled(), "metrics should still be sent to TCS without a local sink")
+}
+
+func TestTaskBridgeConfig(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_TASK_BRIDGE", "true")()
+	defer setTestEnv("ECS_TASK_BRIDGE_SUBNET", "172.31.128.0/20")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.True(t, cfg.TaskBridgeEnabled.Enabled())
+	assert.Equal(t, "172.31.128.0/20", cfg.TaskBridgeSubnet)
+}
+
+func TestTaskBridgeConfigInvalidSubnet(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ENABLE_TASK_BRIDGE", "true")()
+	defer setTestEnv("ECS_TASK_BRIDGE_SUBNET", "fd00::/64")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.TaskBridgeSubnet, "the default subnet should be used")
+}
+
+func TestContainerRestartConfig(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CONTAINER_RESTART_MAX_ATTEMPTS", "5")()
+	defer setTestEnv("ECS_CONTAINER_RESTART_BACKOFF_INITIAL_DELAY", "10s")()
+	defer setTestEnv("ECS_CONTAINER_RESTART_BACKOFF_MAX_DELAY", "2m")()
+	defer setTestEnv("ECS_CONTAINER_RESTART_STOP_TASK_ON_MAX_ATTEMPTS", "true")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cfg.ContainerRestartMaxAttempts)
+	assert.Equal(t, 10*time.Second, cfg.ContainerRestartBackoffInitialDelay)
+	assert.Equal(t, 2*time.Minute, cfg.ContainerRestartBackoffMaxDelay)
+	assert.True(t, cfg.ContainerRestartStopTaskOnMaxAttempts.Enabled())
+}
+
+func TestContainerRestartConfigInvalidValues(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CONTAINER_RESTART_MAX_ATTEMPTS", "-1")()
+	defer setTestEnv("ECS_CONTAINER_RESTART_BACKOFF_INITIAL_DELAY", "1m")()
+	defer setTestEnv("ECS_CONTAINER_RESTART_BACKOFF_MAX_DELAY", "10s")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.ContainerRestartMaxAttempts)
+	assert.Equal(t, time.Minute, cfg.ContainerRestartBackoffInitialDelay)
+	assert.Equal(t, time.Minute, cfg.ContainerRestartBackoffMaxDelay)
+	assert.False(t, cfg.ContainerRestartStopTaskOnMaxAttempts.Enabled())
+}
+
+func TestContainerMetadataFormats(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CONTAINER_METADATA_FORMATS", "json, YAML,env,invalid,yaml")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ContainerMetadataFormatYAML, ContainerMetadataFormatEnv}, cfg.ContainerMetadataFormats)
+}
+
+func TestEventSinkConfig(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_EVENT_WEBHOOK_URLS", `["http://localhost:8080/events","https://example.com/hook"]`)()
+	defer setTestEnv("ECS_EVENT_WEBHOOK_QUEUE_SIZE", "50")()
+	defer setTestEnv("ECS_EVENT_SOCKET_PATH", "/var/run/ecs/events.sock")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:8080/events", "https://example.com/hook"}, cfg.EventWebhookURLs)
+	assert.Equal(t, 50, cfg.EventWebhookQueueSize)
+	assert.Equal(t, "/var/run/ecs/events.sock", cfg.EventSocketPath)
+}
+
+func TestEventSinkConfigInvalidValues(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_EVENT_WEBHOOK_URLS", "http://localhost:8080/events")()
+	defer setTestEnv("ECS_EVENT_WEBHOOK_QUEUE_SIZE", "-1")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.EventWebhookURLs)
+	assert.Equal(t, DefaultEventWebhookQueueSize, cfg.EventWebhookQueueSize)
+}
+
 func TestInvalidImagePullBehavior(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_IMAGE_PULL_BEHAVIOR", "invalid")()
@@ -751,6 +950,121 @@ func TestParseImagePullBehavior(t *testing.T) {
 	}
 }
 
+func TestParseTaskQueuePolicy(t *testing.T) {
+	testcases := []struct {
+		name           string
+		envVarVal      string
+		expectedPolicy TaskQueuePolicyType
+	}{
+		{
+			name:           "unset",
+			expectedPolicy: TaskQueueFIFOPolicy,
+		},
+		{
+			name:           "fifo",
+			envVarVal:      "fifo",
+			expectedPolicy: TaskQueueFIFOPolicy,
+		},
+		{
+			name:           "first-fit",
+			envVarVal:      "first-fit",
+			expectedPolicy: TaskQueueFirstFitPolicy,
+		},
+		{
+			name:           "priority",
+			envVarVal:      "priority",
+			expectedPolicy: TaskQueuePriorityPolicy,
+		},
+		{
+			name:           "invalid",
+			envVarVal:      "lifo",
+			expectedPolicy: TaskQueueFIFOPolicy,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer setTestEnv("ECS_TASK_QUEUE_POLICY", tc.envVarVal)()
+			policy := parseTaskQueuePolicy()
+			assert.Equal(t, tc.expectedPolicy, policy)
+			if tc.envVarVal != "" && tc.envVarVal != "lifo" {
+				assert.Equal(t, tc.envVarVal, policy.String())
+			}
+		})
+	}
+}
+
+func TestTaskQueuePriorityTag(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTaskQueuePriorityTag, cfg.TaskQueuePriorityTag)
+
+	defer setTestEnv("ECS_TASK_QUEUE_PRIORITY_TAG", "priority")()
+	cfg, err = NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, "priority", cfg.TaskQueuePriorityTag)
+}
+
+func TestStandaloneMode(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.StandaloneTasksDir)
+	assert.Empty(t, cfg.StandaloneStateChangeLog)
+
+	defer setTestEnv("ECS_STANDALONE_TASKS_DIR", "/etc/ecs/tasks.d")()
+	defer setTestEnv("ECS_STANDALONE_STATE_CHANGE_LOG", "/var/log/ecs/state-changes.log")()
+	cfg, err = NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/ecs/tasks.d", cfg.StandaloneTasksDir)
+	assert.Equal(t, "/var/log/ecs/state-changes.log", cfg.StandaloneStateChangeLog)
+}
+
+func TestSecretRotationInterval(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.SecretRotationInterval)
+
+	defer setTestEnv("ECS_SECRET_ROTATION_INTERVAL", "10m")()
+	cfg, err = NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, cfg.SecretRotationInterval)
+}
+
+func TestSecretRotationIntervalBelowMinimum(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_SECRET_ROTATION_INTERVAL", "5s")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, minimumSecretRotationInterval, cfg.SecretRotationInterval)
+}
+
+func TestRuntimeLogConfig(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.IntrospectionLogConfigToken)
+	assert.Equal(t, DefaultDebugLogDuration, cfg.DebugLogDuration)
+
+	defer setTestEnv("ECS_INTROSPECTION_LOG_CONFIG_TOKEN", "s3cr3t")()
+	defer setTestEnv("ECS_DEBUG_LOG_DURATION", "1h")()
+	cfg, err = NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", string(cfg.IntrospectionLogConfigToken.Contents()))
+	assert.NotContains(t, cfg.String(), "s3cr3t")
+	assert.Equal(t, time.Hour, cfg.DebugLogDuration)
+}
+
+func TestDebugLogDurationBelowMinimum(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DEBUG_LOG_DURATION", "5s")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultDebugLogDuration, cfg.DebugLogDuration)
+}
+
 func TestTaskResourceLimitsOverride(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_ENABLE_TASK_CPU_MEM_LIMIT", "false")()
@@ -775,6 +1089,31 @@ func TestInvalidAWSVPCAdditionalLocalRoutes(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestAWSVPCEgressPolicy(t *testing.T) {
+	defer setTestEnv("ECS_AWSVPC_EGRESS_POLICY",
+		`{"DefaultAction":"DENY","Rules":[{"Action":"ALLOW","CIDR":"10.0.0.0/8","Protocol":"tcp","Ports":["443"]}]}`)()
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	require.NoError(t, err)
+	require.NotNil(t, cfg.AWSVPCEgressPolicy)
+	assert.Equal(t, egresspolicy.ActionDeny, cfg.AWSVPCEgressPolicy.DefaultAction)
+	assert.Equal(t, []egresspolicy.Rule{
+		{
+			Action:   egresspolicy.ActionAllow,
+			CIDR:     "10.0.0.0/8",
+			Protocol: egresspolicy.ProtocolTCP,
+			Ports:    []string{"443"},
+		},
+	}, cfg.AWSVPCEgressPolicy.Rules)
+}
+
+func TestInvalidAWSVPCEgressPolicy(t *testing.T) {
+	os.Setenv("ECS_AWSVPC_EGRESS_POLICY", `{"DefaultAction":"DROP"}`)
+	defer os.Unsetenv("ECS_AWSVPC_EGRESS_POLICY")
+	_, err := environmentConfig()
+	assert.Error(t, err)
+}
+
 func TestAWSLogsExecutionRole(t *testing.T) {
 	setTestEnv("ECS_ENABLE_AWSLOGS_EXECUTIONROLE_OVERRIDE", "true")
 	conf, err := environmentConfig()
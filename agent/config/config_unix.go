@@ -73,6 +73,8 @@ const (
 	nodeStageTimeout = 2 * time.Second
 	// nodeUnstageTimeout is the deafult timeout for unstaging an EBS TA volume
 	nodeUnstageTimeout = 30 * time.Second
+	// defaultSecretFilesDir is the tmpfs mounted by ecs-init that the secret files are written to
+	defaultSecretFilesDir = "/var/lib/ecs/secrets"
 )
 
 var (
@@ -101,11 +103,16 @@ func DefaultConfig() Config {
 		ImageCleanupDisabled:                BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		MinimumImageDeletionAge:             DefaultImageDeletionAge,
 		NonECSMinimumImageDeletionAge:       DefaultNonECSImageDeletionAge,
+		ImagePrefetchPinDuration:            DefaultImagePrefetchPinDuration,
+		DebugLogDuration:                    DefaultDebugLogDuration,
 		ImageCleanupInterval:                DefaultImageCleanupTimeInterval,
 		ImagePullInactivityTimeout:          defaultImagePullInactivityTimeout,
 		ImagePullTimeout:                    DefaultImagePullTimeout,
 		NumImagesToDeletePerCycle:           DefaultNumImagesToDeletePerCycle,
 		NumNonECSContainersToDeletePerCycle: DefaultNumNonECSContainersToDeletePerCycle,
+		EventWebhookQueueSize:               DefaultEventWebhookQueueSize,
+		SecretFilesDir:                      defaultSecretFilesDir,
+		TaskQueuePriorityTag:                DefaultTaskQueuePriorityTag,
 		CNIPluginsPath:                      defaultCNIPluginsPath,
 		PauseContainerTarballPath:           pauseContainerTarballPath,
 		PauseContainerImageName:             DefaultPauseContainerImageName,
@@ -47,6 +47,7 @@ func TestConfigDefault(t *testing.T) {
 
 	assert.Equal(t, "unix:///var/run/docker.sock", cfg.DockerEndpoint, "Default docker endpoint set incorrectly")
 	assert.Equal(t, "/data/", cfg.DataDir, "Default datadir set incorrectly")
+	assert.Equal(t, "/var/lib/ecs/secrets", cfg.SecretFilesDir, "Default secret files dir set incorrectly")
 	assert.False(t, cfg.DisableMetrics.Enabled(), "Default disablemetrics set incorrectly")
 	assert.Equal(t, 5, len(cfg.ReservedPorts), "Default reserved ports set incorrectly")
 	assert.Equal(t, uint16(0), cfg.ReservedMemory, "Default reserved memory set incorrectly")
@@ -236,6 +237,16 @@ func TestENITrunkingDisabled(t *testing.T) {
 	assert.False(t, cfg.ENITrunkingEnabled.Enabled(), "ENI trunking should be disabled")
 }
 
+// TestImageCleanupWatermarkConfigWithoutDiskPath tests that disk pressure image cleanup is disabled
+// when the Docker data root isn't mounted into the Agent container
+func TestImageCleanupWatermarkConfigWithoutDiskPath(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT", "85")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	require.NoError(t, err)
+	assert.Zero(t, cfg.ImageCleanupHighWatermarkPercent)
+}
+
 // setupFileConfiguration create a temp file store the configuration
 func setupFileConfiguration(t *testing.T, configContent string) string {
 	file, err := ioutil.TempFile("", "ecs-test")
@@ -146,9 +146,13 @@ func DefaultConfig() Config {
 		ImageCleanupDisabled:                BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		MinimumImageDeletionAge:             DefaultImageDeletionAge,
 		NonECSMinimumImageDeletionAge:       DefaultNonECSImageDeletionAge,
+		ImagePrefetchPinDuration:            DefaultImagePrefetchPinDuration,
+		DebugLogDuration:                    DefaultDebugLogDuration,
 		ImageCleanupInterval:                DefaultImageCleanupTimeInterval,
 		NumImagesToDeletePerCycle:           DefaultNumImagesToDeletePerCycle,
 		NumNonECSContainersToDeletePerCycle: DefaultNumNonECSContainersToDeletePerCycle,
+		EventWebhookQueueSize:               DefaultEventWebhookQueueSize,
+		TaskQueuePriorityTag:                DefaultTaskQueuePriorityTag,
 		ContainerMetadataEnabled:            BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		TaskCPUMemLimit:                     BooleanDefaultTrue{Value: ExplicitlyDisabled},
 		PlatformVariables:                   platformVariables,
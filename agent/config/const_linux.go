@@ -23,4 +23,7 @@ const (
 	ManagedDaemonSocketPathHostRoot = "/var/run/ecs"
 	// This is the path that will be used to store the log file for the CSI Driver Managed Daemon
 	ManagedDaemonLogPathHostRoot = "/log/daemons"
+	// imageCleanupDiskPathRequired is set as Agent runs in a container where the Docker root dir
+	// isn't visible, unless it's mounted at ImageCleanupDiskPath
+	imageCleanupDiskPathRequired = true
 )
@@ -16,4 +16,8 @@
 
 package config
 
-const OSType = "unknown"
+const (
+	OSType = "unknown"
+	// imageCleanupDiskPathRequired is set as Agent is assumed to run in a container
+	imageCleanupDiskPathRequired = true
+)
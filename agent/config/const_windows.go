@@ -25,4 +25,7 @@ const (
 	ManagedDaemonSocketPathHostRoot = "C:\\ProgramData\\Amazon\\ECS\\ebs-csi-driver"
 	// This is the path that will be used to store the log file for the CSI Driver Managed Daemon
 	ManagedDaemonLogPathHostRoot = "C:\\ProgramData\\Amazon\\ECS\\log\\daemons"
+	// imageCleanupDiskPathRequired isn't set as Agent runs on the host, where the Docker root dir
+	// reported by Docker is visible
+	imageCleanupDiskPathRequired = false
 )
@@ -18,12 +18,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 
 	"github.com/cihub/seelog"
 	cniTypes "github.com/containernetworking/cni/pkg/types"
@@ -195,6 +197,72 @@ func parseNumNonECSContainersToDeletePerCycle() int {
 	return numNonEcsContainersToDeletePerCycle
 }
 
+func parseEventWebhookURLs() []string {
+	// Format: json array, e.g. ["http://localhost:8080/events"]
+	webhookURLsEnv := os.Getenv("ECS_EVENT_WEBHOOK_URLS")
+	if webhookURLsEnv == "" {
+		return nil
+	}
+	var webhookURLs []string
+	if err := json.Unmarshal([]byte(webhookURLsEnv), &webhookURLs); err != nil {
+		seelog.Warnf("Invalid format for \"ECS_EVENT_WEBHOOK_URLS\", expected a JSON array of URLs. err %v", err)
+		return nil
+	}
+	return webhookURLs
+}
+
+func parseEventWebhookQueueSize() int {
+	queueSizeEnvVal := os.Getenv("ECS_EVENT_WEBHOOK_QUEUE_SIZE")
+	queueSize, err := strconv.Atoi(queueSizeEnvVal)
+	if queueSizeEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_EVENT_WEBHOOK_QUEUE_SIZE\", expected an integer. err %v", err)
+	}
+	return queueSize
+}
+
+func parseImageCleanupWatermarkPercent(envVar string) int {
+	watermarkEnvVal := os.Getenv(envVar)
+	watermark, err := strconv.Atoi(watermarkEnvVal)
+	if watermarkEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"%s\", expected an integer. err %v", envVar, err)
+	}
+
+	return watermark
+}
+
+func parseContainerRestartMaxAttempts() int {
+	maxAttemptsEnvVal := os.Getenv("ECS_CONTAINER_RESTART_MAX_ATTEMPTS")
+	maxAttempts, err := strconv.Atoi(maxAttemptsEnvVal)
+	if maxAttemptsEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_CONTAINER_RESTART_MAX_ATTEMPTS\", expected an integer. err %v", err)
+	}
+
+	return maxAttempts
+}
+
+func parseContainerMetadataFormats() []string {
+	// Format: comma separated list, e.g. "env,yaml"
+	formatsEnv := os.Getenv("ECS_CONTAINER_METADATA_FORMATS")
+	if formatsEnv == "" {
+		return nil
+	}
+	var formats []string
+	for _, format := range strings.Split(formatsEnv, ",") {
+		format = strings.ToLower(strings.TrimSpace(format))
+		switch format {
+		case "json":
+			// The metadata is always written in JSON
+		case ContainerMetadataFormatEnv, ContainerMetadataFormatYAML:
+			if !slices.Contains(formats, format) {
+				formats = append(formats, format)
+			}
+		default:
+			seelog.Warnf("Invalid format for \"ECS_CONTAINER_METADATA_FORMATS\", ignoring unsupported container metadata format %q", format)
+		}
+	}
+	return formats
+}
+
 func parseImagePullBehavior() ImagePullBehaviorType {
 	ImagePullBehaviorString := os.Getenv("ECS_IMAGE_PULL_BEHAVIOR")
 	switch ImagePullBehaviorString {
@@ -211,6 +279,21 @@ func parseImagePullBehavior() ImagePullBehaviorType {
 	}
 }
 
+func parseTaskQueuePolicy() TaskQueuePolicyType {
+	taskQueuePolicyString := os.Getenv("ECS_TASK_QUEUE_POLICY")
+	switch taskQueuePolicyString {
+	case "", "fifo":
+		return TaskQueueFIFOPolicy
+	case "first-fit":
+		return TaskQueueFirstFitPolicy
+	case "priority":
+		return TaskQueuePriorityPolicy
+	default:
+		seelog.Warnf("Invalid format for \"ECS_TASK_QUEUE_POLICY\", expected one of fifo, first-fit or priority, using fifo. Parsed value: %s", taskQueuePolicyString)
+		return TaskQueueFIFOPolicy
+	}
+}
+
 func parseInstanceAttributes(errs []error) (map[string]string, []error) {
 	var instanceAttributes map[string]string
 	instanceAttributesEnv := os.Getenv("ECS_INSTANCE_ATTRIBUTES")
@@ -243,6 +326,19 @@ func parseAdditionalLocalRoutes(errs []error) ([]cniTypes.IPNet, []error) {
 	return additionalLocalRoutes, errs
 }
 
+func parseEgressPolicy(errs []error) (*egresspolicy.EgressPolicy, []error) {
+	egressPolicyEnv := os.Getenv("ECS_AWSVPC_EGRESS_POLICY")
+	if egressPolicyEnv == "" {
+		return nil, errs
+	}
+	egressPolicy, err := egresspolicy.Parse(egressPolicyEnv)
+	if err != nil {
+		seelog.Errorf("Invalid format for ECS_AWSVPC_EGRESS_POLICY, expected a json egress policy: %v", err)
+		errs = append(errs, err)
+	}
+	return egressPolicy, errs
+}
+
 func parseBooleanDefaultFalseConfig(envVarName string) BooleanDefaultFalse {
 	boolDefaultFalseConfig := BooleanDefaultFalse{Value: NotSet}
 	configString := strings.TrimSpace(os.Getenv(envVarName))
@@ -64,6 +64,18 @@ func TestParseNumImagesToDeletePerCycle(t *testing.T) {
 	assert.Zero(t, v)
 }
 
+func TestParseContainerRestartMaxAttempts(t *testing.T) {
+	// unset value
+	t.Setenv("ECS_CONTAINER_RESTART_MAX_ATTEMPTS", "")
+	assert.Zero(t, parseContainerRestartMaxAttempts())
+	// valid value
+	t.Setenv("ECS_CONTAINER_RESTART_MAX_ATTEMPTS", "3")
+	assert.Equal(t, 3, parseContainerRestartMaxAttempts())
+	// invalid value
+	t.Setenv("ECS_CONTAINER_RESTART_MAX_ATTEMPTS", "foobar")
+	assert.Zero(t, parseContainerRestartMaxAttempts())
+}
+
 func TestParseNumNonECSContainersToDeletePerCycle(t *testing.T) {
 	// unset value
 	t.Setenv("NONECS_NUM_CONTAINERS_DELETE_PER_CYCLE", "")
@@ -20,12 +20,18 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/config/ipcompatibility"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 )
 
 // ImagePullBehaviorType is an enum variable type corresponding to different agent pull
 // behaviors including default, always, never and once.
 type ImagePullBehaviorType int8
 
+// TaskQueuePolicyType is an enum variable type corresponding to the different orders in
+// which the tasks waiting for host resources are started, including fifo (default),
+// first-fit and priority.
+type TaskQueuePolicyType int8
+
 // ContainerInstancePropagateTagsFromType is an enum variable type corresponding to different
 // ways to propagate tags, it includes none (default) and ec2_instance.
 type ContainerInstancePropagateTagsFromType int8
@@ -72,6 +78,9 @@ type Config struct {
 	// file, in DataDir, such that on instance or agent restarts it will resume
 	// as the same ContainerInstance. It defaults to false.
 	Checkpoint BooleanDefaultFalse
+	// DataBackend selects how the checkpointed data is stored in DataDir. It can be
+	// "boltdb" (the default) or "wal", an append-only log of JSON records.
+	DataBackend string `trim:"true"`
 
 	// EngineAuthType configures what type of data is in EngineAuthData.
 	// Supported types, right now, can be found in the dockerauth package: https://godoc.org/github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerauth
@@ -87,11 +96,26 @@ type Config struct {
 	// within the container in order for the external updating process to
 	// correctly handle them.
 	UpdateDownloadDir string
+	// UpdateSigningPublicKey is the path to a PEM encoded public key. When set, update
+	// tarballs are only accepted with a detached signature verified by this key.
+	UpdateSigningPublicKey string
 
 	// DisableMetrics configures whether task utilization metrics should be
 	// sent to the ECS telemetry endpoint
 	DisableMetrics BooleanDefaultFalse
 
+	// LocalMetricsSink enables publishing task utilization metrics to a local metrics
+	// collector, either "otlp" for OTLP over HTTP or "statsd" for StatsD over UDP
+	LocalMetricsSink string
+
+	// LocalMetricsEndpoint is the OTLP/HTTP metrics URL or the StatsD address to which the
+	// local metrics sink publishes. It defaults to the standard local endpoint of the sink
+	LocalMetricsEndpoint string
+
+	// LocalMetricsOnly configures whether task utilization metrics should only be published
+	// to the local metrics sink, instead of alongside the ECS telemetry endpoint
+	LocalMetricsOnly BooleanDefaultFalse
+
 	// PollMetrics configures whether metrics are constantly streamed for each container or
 	// polled on interval instead.
 	PollMetrics BooleanDefaultFalse
@@ -104,6 +128,32 @@ type Config struct {
 	// on the instance
 	DisableDockerHealthCheck BooleanDefaultFalse
 
+	// EventWebhookURLs are the URLs the state change events of tasks, containers and managed
+	// agents are posted to as JSON
+	EventWebhookURLs []string
+
+	// EventWebhookQueueSize is the maximum number of events queued on disk for each webhook
+	// while they can't be delivered
+	EventWebhookQueueSize int
+
+	// EventSocketPath is the path to a Unix socket the state change events are written to as
+	// JSON lines
+	EventSocketPath string
+
+	// CustomHealthchecksDir is the path to a directory of JSON files defining custom instance
+	// healthchecks, which are run by the doctor along with the built-in ones
+	CustomHealthchecksDir string
+
+	// StandaloneTasksDir is the path to a directory of task-definition JSON files. When set,
+	// the agent runs in standalone mode: it doesn't register the container instance, runs a
+	// task for each file instead of receiving tasks from ACS, and writes the state changes to
+	// StandaloneStateChangeLog instead of submitting them to ECS
+	StandaloneTasksDir string
+
+	// StandaloneStateChangeLog is the path to the file the state changes are written to as
+	// JSON lines in standalone mode. It defaults to a file in DataDir
+	StandaloneStateChangeLog string
+
 	// ReservedMemory specifies Reduction, in MiB, of the memory capacity of the instance
 	// that is reported to Amazon ECS. Used by Amazon ECS when placing tasks on container instances.
 	// This doesn't reserve memory usage on the instance
@@ -205,6 +255,32 @@ type Config struct {
 	// when Agent performs cleanup
 	NumImagesToDeletePerCycle int
 
+	// ImageCleanupHighWatermarkPercent enables disk pressure image cleanup when set. Once
+	// the usage of the filesystem holding the Docker data root goes above this percentage,
+	// Agent removes unused images until the usage drops below ImageCleanupLowWatermarkPercent
+	ImageCleanupHighWatermarkPercent int
+
+	// ImageCleanupLowWatermarkPercent is the filesystem usage percentage down to which
+	// Agent removes images once ImageCleanupHighWatermarkPercent has been reached
+	ImageCleanupLowWatermarkPercent int
+
+	// ImageCleanupDiskCheckInterval specifies how often the filesystem usage is checked
+	// against ImageCleanupHighWatermarkPercent
+	ImageCleanupDiskCheckInterval time.Duration
+
+	// ImageCleanupDiskPath is a path on the filesystem holding the Docker data root, as
+	// seen by Agent. It's required on Linux, where Agent runs in a container, and defaults
+	// to the Docker root dir reported by Docker on Windows
+	ImageCleanupDiskPath string
+
+	// ImagePrefetchFile is the path to a JSON file listing images, or task definitions whose
+	// images, are pulled ahead of time when Agent starts
+	ImagePrefetchFile string
+
+	// ImagePrefetchPinDuration specifies how long prefetched images are kept from being
+	// removed by the image cleanup, starting from when they are pulled
+	ImagePrefetchPinDuration time.Duration
+
 	// NumNonECSContainersToDeletePerCycle specifies the num of NonECS containers to delete every time
 	// when Agent performs cleanup
 	NumNonECSContainersToDeletePerCycle int
@@ -213,6 +289,14 @@ type Config struct {
 	// local Docker image cache
 	ImagePullBehavior ImagePullBehaviorType
 
+	// TaskQueuePolicy specifies the order in which the tasks waiting for host resources
+	// are started
+	TaskQueuePolicy TaskQueuePolicyType
+
+	// TaskQueuePriorityTag is the key of the task tag holding the priority of a task in
+	// the queue of tasks waiting for host resources, when TaskQueuePolicy is priority
+	TaskQueuePriorityTag string
+
 	// InstanceAttributes contains key/value pairs representing
 	// attributes to be associated with this instance within the
 	// ECS service and used to influence behavior such as launch
@@ -259,10 +343,51 @@ type Config struct {
 	// instance bridge interface rather than via the ENI.
 	AWSVPCAdditionalLocalRoutes []cniTypes.IPNet
 
+	// AWSVPCEgressPolicy is the egress policy enforced inside the network namespace of tasks
+	// launched with network mode "awsvpc" that don't specify their own through the
+	// "com.amazonaws.ecs.egress-policy" docker label.
+	AWSVPCEgressPolicy *egresspolicy.EgressPolicy
+
+	// TaskBridgeEnabled specifies if tasks launched with network mode "bridge" without
+	// Service Connect are connected to the task bridge through a pause container, rather
+	// than to the docker bridge. Only supported on Linux.
+	TaskBridgeEnabled BooleanDefaultFalse
+
+	// TaskBridgeSubnet is the IPv4 subnet the network namespaces of the tasks connected to
+	// the task bridge are assigned their address from, e.g. "172.30.0.0/16".
+	TaskBridgeSubnet string
+
 	// ContainerMetadataEnabled specifies if the agent should provide a metadata
 	// file for containers.
 	ContainerMetadataEnabled BooleanDefaultFalse
 
+	// ContainerMetadataFormats are the formats in which the container metadata
+	// file is also written, in addition to JSON. Supported formats are "env"
+	// and "yaml".
+	ContainerMetadataFormats []string
+
+	// SecretRotationInterval specifies the interval at which the ASM and SSM secrets of the
+	// running tasks are retrieved again, and their values written to the secret files mounted
+	// into the containers. A value of 0 disables the secret files.
+	SecretRotationInterval time.Duration
+
+	// SecretFilesDir is the directory the secret files of containers are written to. It must
+	// be a tmpfs, at the same path on the host as in the agent container.
+	SecretFilesDir string
+
+	// IntrospectionLogConfigToken is the bearer token authenticating the requests changing
+	// the logger configuration through the introspection API. The endpoint is disabled when
+	// it's not set.
+	IntrospectionLogConfigToken *SensitiveRawMessage
+
+	// IntrospectionImagePrefetchToken is the bearer token authenticating the image prefetch
+	// requests made through the introspection API. The endpoint is disabled when it's not set.
+	IntrospectionImagePrefetchToken *SensitiveRawMessage
+
+	// DebugLogDuration is how long the log level stays at debug after the agent receives a
+	// SIGHUP, before the previous log configuration is restored.
+	DebugLogDuration time.Duration
+
 	// OverrideAWSLogsExecutionRole is config option used to enable awslogs
 	// driver authentication over the task's execution role
 	OverrideAWSLogsExecutionRole BooleanDefaultFalse
@@ -402,4 +527,23 @@ type Config struct {
 
 	// IP version compatibility for the container instance's default network
 	InstanceIPCompatibility ipcompatibility.IPCompatibility
+
+	// ContainerRestartMaxAttempts specifies the maximum number of times a container with
+	// an enabled restart policy is restarted, when the restart policy of the container
+	// does not specify one. A value of 0 means the container is restarted without limit.
+	ContainerRestartMaxAttempts int
+
+	// ContainerRestartBackoffInitialDelay specifies the delay before the first restart of
+	// a container with an enabled restart policy, when the restart policy of the container
+	// does not specify one. The delay doubles after each restart, up to
+	// ContainerRestartBackoffMaxDelay. A value of 0 disables the restart backoff.
+	ContainerRestartBackoffInitialDelay time.Duration
+
+	// ContainerRestartBackoffMaxDelay specifies the ceiling of the delay before a container
+	// is restarted, when the restart policy of the container does not specify one.
+	ContainerRestartBackoffMaxDelay time.Duration
+
+	// ContainerRestartStopTaskOnMaxAttempts specifies whether a task should be stopped once
+	// one of its containers exits after ContainerRestartMaxAttempts restarts.
+	ContainerRestartStopTaskOnMaxAttempts BooleanDefaultFalse
 }
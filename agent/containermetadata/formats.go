@@ -0,0 +1,167 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package containermetadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	metadataEnvFile = "ecs-container-metadata.env"
+	// metadataEnvFileEnvironmentVariable is the environment variable passed to the
+	// container for the metadata env-file path
+	metadataEnvFileEnvironmentVariable = "ECS_CONTAINER_METADATA_ENV_FILE"
+	metadataYAMLFile                   = "ecs-container-metadata.yaml"
+	// metadataYAMLFileEnvironmentVariable is the environment variable passed to the
+	// container for the YAML metadata file path
+	metadataYAMLFileEnvironmentVariable = "ECS_CONTAINER_METADATA_YAML_FILE"
+	// metadataVersionFile contains the version of the metadata last written. It is written
+	// after the metadata files, so that watching it is enough to be notified of changes.
+	metadataVersionFile = "ecs-container-metadata.version"
+	// metadataEnvPrefix is the prefix of the variables in the metadata env-file
+	metadataEnvPrefix = "ECS_METADATA_"
+)
+
+// metadataFormat is an additional format the metadata is written in
+type metadataFormat struct {
+	fileName            string
+	environmentVariable string
+	marshal             func(metadata Metadata, jsonData []byte) ([]byte, error)
+}
+
+var metadataFormats = map[string]metadataFormat{
+	config.ContainerMetadataFormatEnv: {
+		fileName:            metadataEnvFile,
+		environmentVariable: metadataEnvFileEnvironmentVariable,
+		marshal: func(metadata Metadata, _ []byte) ([]byte, error) {
+			return marshalEnv(metadata), nil
+		},
+	},
+	config.ContainerMetadataFormatYAML: {
+		fileName:            metadataYAMLFile,
+		environmentVariable: metadataYAMLFileEnvironmentVariable,
+		marshal: func(_ Metadata, jsonData []byte) ([]byte, error) {
+			return marshalYAML(jsonData)
+		},
+	},
+}
+
+// getMetadataFormats returns the additional formats enabled in the config, ignoring the
+// unsupported ones
+func getMetadataFormats(names []string) []metadataFormat {
+	var formats []metadataFormat
+	for _, name := range names {
+		if format, ok := metadataFormats[name]; ok {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// marshalEnv renders the metadata as shell variable assignments that can be sourced.
+// Lists are flattened into indexed variables, e.g. ECS_METADATA_PORT_MAPPING_0_HOST_PORT.
+func marshalEnv(metadata Metadata) []byte {
+	m := metadata.serializer()
+	var buf bytes.Buffer
+	writeVar := func(name, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&buf, "%s%s=%s\n", metadataEnvPrefix, name, shellQuote(value))
+	}
+
+	writeVar("CLUSTER", m.Cluster)
+	writeVar("CONTAINER_INSTANCE_ARN", m.ContainerInstanceARN)
+	writeVar("TASK_ARN", m.TaskARN)
+	writeVar("TASK_DEFINITION_FAMILY", m.TaskDefinitionFamily)
+	writeVar("TASK_DEFINITION_REVISION", m.TaskDefinitionRevision)
+	writeVar("CONTAINER_ID", m.ContainerID)
+	writeVar("CONTAINER_NAME", m.ContainerName)
+	writeVar("DOCKER_CONTAINER_NAME", m.DockerContainerName)
+	writeVar("IMAGE_ID", m.ImageID)
+	writeVar("IMAGE_NAME", m.ImageName)
+	for i, port := range m.Ports {
+		prefix := fmt.Sprintf("PORT_MAPPING_%d_", i)
+		if port.ContainerPort != 0 {
+			writeVar(prefix+"CONTAINER_PORT", strconv.Itoa(int(port.ContainerPort)))
+		}
+		writeVar(prefix+"CONTAINER_PORT_RANGE", port.ContainerPortRange)
+		writeVar(prefix+"HOST_PORT", strconv.Itoa(int(port.HostPort)))
+		writeVar(prefix+"BIND_IP", port.BindIP)
+		writeVar(prefix+"PROTOCOL", port.Protocol.String())
+	}
+	for i, network := range m.Networks {
+		prefix := fmt.Sprintf("NETWORK_%d_", i)
+		writeVar(prefix+"MODE", network.NetworkMode)
+		writeVar(prefix+"IPV4_ADDRESSES", strings.Join(network.IPv4Addresses, ","))
+		writeVar(prefix+"IPV6_ADDRESSES", strings.Join(network.IPv6Addresses, ","))
+	}
+	if status, err := m.MetadataFileStatus.MarshalText(); err == nil {
+		writeVar("FILE_STATUS", string(status))
+	}
+	writeVar("AVAILABILITY_ZONE", m.AvailabilityZone)
+	writeVar("HOST_PRIVATE_IPV4_ADDRESS", m.HostPrivateIPv4Address)
+	writeVar("HOST_PUBLIC_IPV4_ADDRESS", m.HostPublicIPv4Address)
+	writeVar("HEALTH_STATUS", m.HealthStatus)
+	writeVar("VERSION", strconv.FormatUint(m.MetadataVersion, 10))
+	return buf.Bytes()
+}
+
+// shellQuote quotes a value so that it's preserved as is when the env-file is sourced
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// marshalYAML renders the JSON metadata as YAML, keeping the same keys in the same order
+func marshalYAML(jsonData []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(jsonData, &node); err != nil {
+		return nil, err
+	}
+	clearYAMLStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// clearYAMLStyle resets the flow and quoting style of the nodes parsed from JSON, so that
+// they are rendered in the default block style. The encoder still quotes the strings that
+// would otherwise be read back as another type.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// versionFileData returns the content of the version file
+func versionFileData(version uint64) []byte {
+	return []byte(strconv.FormatUint(version, 10) + "\n")
+}
+
+// parseVersionFileData parses the content of the version file
+func parseVersionFileData(data []byte) (uint64, error) {
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// marshalJSON renders the metadata as indented JSON
+func marshalJSON(metadata Metadata) ([]byte, error) {
+	return json.MarshalIndent(metadata, "", "\t")
+}
@@ -0,0 +1,125 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package containermetadata
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	tmdsresponse "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFormatsMetadata() Metadata {
+	return Metadata{
+		cluster: "cluster",
+		taskMetadata: TaskMetadata{
+			containerName:          containerName,
+			taskARN:                validTaskARN,
+			taskDefinitionFamily:   taskDefinitionFamily,
+			taskDefinitionRevision: taskDefinitionRevision,
+		},
+		dockerContainerMetadata: DockerContainerMetadata{
+			containerID: dockerID,
+			imageName:   "it's:latest",
+			ports: []apicontainer.PortBinding{{
+				ContainerPort: 80,
+				HostPort:      32768,
+				BindIP:        "0.0.0.0",
+				Protocol:      apicontainer.TransportProtocolTCP,
+			}},
+			networkInfo: NetworkMetadata{
+				networks: []tmdsresponse.Network{{
+					NetworkMode:   "bridge",
+					IPv4Addresses: []string{"172.17.0.2"},
+				}},
+			},
+			healthStatus: "healthy",
+		},
+		metadataStatus: MetadataReady,
+		version:        3,
+	}
+}
+
+func TestMarshalEnv(t *testing.T) {
+	expected := `ECS_METADATA_CLUSTER='cluster'
+ECS_METADATA_TASK_ARN='arn:aws:ecs:region:account-id:task/task-id'
+ECS_METADATA_TASK_DEFINITION_FAMILY='taskdefinitionfamily'
+ECS_METADATA_TASK_DEFINITION_REVISION='8'
+ECS_METADATA_CONTAINER_ID='888888888887'
+ECS_METADATA_CONTAINER_NAME='container'
+ECS_METADATA_IMAGE_NAME='it'\''s:latest'
+ECS_METADATA_PORT_MAPPING_0_CONTAINER_PORT='80'
+ECS_METADATA_PORT_MAPPING_0_HOST_PORT='32768'
+ECS_METADATA_PORT_MAPPING_0_BIND_IP='0.0.0.0'
+ECS_METADATA_PORT_MAPPING_0_PROTOCOL='tcp'
+ECS_METADATA_NETWORK_0_MODE='bridge'
+ECS_METADATA_NETWORK_0_IPV4_ADDRESSES='172.17.0.2'
+ECS_METADATA_FILE_STATUS='READY'
+ECS_METADATA_HEALTH_STATUS='healthy'
+ECS_METADATA_VERSION='3'
+`
+	assert.Equal(t, expected, string(marshalEnv(testFormatsMetadata())))
+}
+
+func TestMarshalYAML(t *testing.T) {
+	jsonData, err := marshalJSON(testFormatsMetadata())
+	require.NoError(t, err)
+	data, err := marshalYAML(jsonData)
+	require.NoError(t, err)
+
+	expected := `Cluster: cluster
+TaskARN: arn:aws:ecs:region:account-id:task/task-id
+TaskDefinitionFamily: taskdefinitionfamily
+TaskDefinitionRevision: "8"
+ContainerID: "888888888887"
+ContainerName: container
+ImageName: it's:latest
+PortMappings:
+    - ContainerPort: 80
+      ContainerPortRange: ""
+      HostPort: 32768
+      BindIp: 0.0.0.0
+      Protocol: tcp
+Networks:
+    - NetworkMode: bridge
+      IPv4Addresses:
+        - 172.17.0.2
+MetadataFileStatus: READY
+HealthStatus: healthy
+MetadataVersion: 3
+`
+	assert.Equal(t, expected, string(data))
+}
+
+func TestGetMetadataFormats(t *testing.T) {
+	formats := getMetadataFormats([]string{config.ContainerMetadataFormatYAML, "unknown", config.ContainerMetadataFormatEnv})
+	require.Len(t, formats, 2)
+	assert.Equal(t, metadataYAMLFile, formats[0].fileName)
+	assert.Equal(t, metadataEnvFile, formats[1].fileName)
+	assert.Empty(t, getMetadataFormats(nil))
+}
+
+func TestParseVersionFileData(t *testing.T) {
+	version, err := parseVersionFileData(versionFileData(42))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), version)
+	_, err = parseVersionFileData([]byte("invalid"))
+	assert.Error(t, err)
+}
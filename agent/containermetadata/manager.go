@@ -14,10 +14,13 @@
 package containermetadata
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/config"
@@ -66,6 +69,21 @@ type metadataManager struct {
 	hostPrivateIPv4Address string
 	// hostPublicIPv4Address is the public IPv4 address associated with the EC2 instance
 	hostPublicIPv4Address string
+	// formats are the formats the metadata is written in, in addition to JSON
+	formats []metadataFormat
+	// written is the metadata last written for each container, keyed by the container's
+	// metadata directory
+	written map[string]writtenMetadata
+	// writeLock serializes the writes of the metadata files
+	writeLock sync.Mutex
+}
+
+// writtenMetadata is the metadata last written for a container
+type writtenMetadata struct {
+	// version is the version of the metadata last written
+	version uint64
+	// data is the JSON metadata last written, without its version
+	data []byte
 }
 
 // NewManager creates a metadataManager for a given DockerTaskEngine settings.
@@ -75,6 +93,8 @@ func NewManager(client DockerMetadataClient, cfg *config.Config) Manager {
 		cluster:       cfg.Cluster,
 		dataDir:       cfg.DataDir,
 		dataDirOnHost: cfg.DataDirOnHost,
+		formats:       getMetadataFormats(cfg.ContainerMetadataFormats),
+		written:       make(map[string]writtenMetadata),
 	}
 }
 
@@ -130,13 +150,15 @@ func (manager *metadataManager) Create(config *dockercontainer.Config, hostConfi
 
 	// Add the directory of this container's metadata to the container's mount binds
 	// Then add the destination directory as an environment variable in the container $METADATA
-	binds, env := createBindsEnv(hostConfig.Binds, config.Env, manager.dataDirOnHost, metadataDirectoryPath, dockerSecurityOptions)
+	binds, env := createBindsEnv(hostConfig.Binds, config.Env, manager.dataDirOnHost, metadataDirectoryPath, dockerSecurityOptions, manager.formats)
 	config.Env = env
 	hostConfig.Binds = binds
 	return nil
 }
 
-// Update updates the metadata file after container starts and dynamic metadata is available
+// Update updates the metadata file after container starts and dynamic metadata is available.
+// It's also called when the health status or the network bindings of the container may have
+// changed, the files are only rewritten if the metadata changed.
 func (manager *metadataManager) Update(ctx context.Context, dockerID string, task *apitask.Task, containerName string) error {
 	// Get docker container information through api call
 	dockerContainer, err := manager.client.InspectContainer(ctx, dockerID, dockerclient.InspectContainerTimeout)
@@ -162,15 +184,88 @@ func (manager *metadataManager) Clean(taskARN string) error {
 	if err != nil {
 		return fmt.Errorf("clean task metadata: unable to get metadata directory for task %s: %v", taskARN, err)
 	}
+
+	manager.writeLock.Lock()
+	for dir := range manager.written {
+		if strings.HasPrefix(dir, metadataPath+string(filepath.Separator)) {
+			delete(manager.written, dir)
+		}
+	}
+	manager.writeLock.Unlock()
 	return removeAll(metadataPath)
 }
 
+// marshalAndWrite writes the metadata of a container in each format if it changed since it
+// was last written, incrementing its version. The version file is written last.
 func (manager *metadataManager) marshalAndWrite(metadata Metadata, taskARN string, containerName string) error {
-	data, err := json.MarshalIndent(metadata, "", "\t")
+	metadataDirectoryPath, err := getMetadataFilePath(taskARN, containerName, manager.dataDir)
+	if err != nil {
+		return fmt.Errorf("write metadata for container %s in task %s: %v", containerName, taskARN, err)
+	}
+
+	manager.writeLock.Lock()
+	defer manager.writeLock.Unlock()
+	if manager.written == nil {
+		manager.written = make(map[string]writtenMetadata)
+	}
+
+	// The metadata is compared without its version to find if it changed
+	metadata.version = 0
+	unversionedData, err := marshalJSON(metadata)
 	if err != nil {
 		return fmt.Errorf("create metadata for container %s in task %s: failed to marshal metadata: %v", containerName, taskARN, err)
 	}
+	last, ok := manager.written[metadataDirectoryPath]
+	if ok && bytes.Equal(last.data, unversionedData) {
+		return nil
+	}
+	if !ok {
+		// Continue from the version written before the agent restarted, if any
+		last.version = readMetadataVersion(metadataDirectoryPath)
+	}
 
-	// Write the metadata to file
-	return writeToMetadataFile(data, taskARN, containerName, manager.dataDir)
+	metadata.version = last.version + 1
+	data, err := marshalJSON(metadata)
+	if err != nil {
+		return fmt.Errorf("create metadata for container %s in task %s: failed to marshal metadata: %v", containerName, taskARN, err)
+	}
+	if err := writeMetadataFile(data, metadataFile, taskARN, containerName, manager.dataDir); err != nil {
+		return err
+	}
+	for _, format := range manager.formats {
+		formatData, err := format.marshal(metadata, data)
+		if err != nil {
+			return fmt.Errorf("create metadata for container %s in task %s: failed to marshal metadata to %s: %v",
+				containerName, taskARN, format.fileName, err)
+		}
+		if err := writeMetadataFile(formatData, format.fileName, taskARN, containerName, manager.dataDir); err != nil {
+			return err
+		}
+	}
+	if err := writeMetadataFile(versionFileData(metadata.version), metadataVersionFile, taskARN, containerName,
+		manager.dataDir); err != nil {
+		return err
+	}
+
+	manager.written[metadataDirectoryPath] = writtenMetadata{
+		version: metadata.version,
+		data:    unversionedData,
+	}
+	return nil
+}
+
+var readFile = os.ReadFile
+
+// readMetadataVersion returns the version of the metadata written in a directory, or 0 if
+// none was written
+func readMetadataVersion(metadataDirectoryPath string) uint64 {
+	data, err := readFile(filepath.Join(metadataDirectoryPath, metadataVersionFile))
+	if err != nil {
+		return 0
+	}
+	version, err := parseVersionFileData(data)
+	if err != nil {
+		return 0
+	}
+	return version
 }
@@ -18,10 +18,13 @@ package containermetadata
 
 import (
 	"context"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/utils/oswrapper"
 	"github.com/docker/docker/api/types"
@@ -29,6 +32,7 @@ import (
 	"github.com/docker/docker/api/types/network"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -126,3 +130,80 @@ func TestUpdate(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+// TestUpdateWritesFormatsOnChange checks that the metadata files are rewritten with a new
+// version only when the metadata changes
+func TestUpdateWritesFormatsOnChange(t *testing.T) {
+	mockClient, _, done := managerSetup(t)
+	defer done()
+
+	cfg := &config.Config{
+		Cluster:                  "cluster",
+		DataDir:                  t.TempDir(),
+		ContainerMetadataFormats: []string{config.ContainerMetadataFormatEnv, config.ContainerMetadataFormatYAML},
+	}
+	mockTask := &apitask.Task{Arn: validTaskARN}
+	health := &types.Health{Status: "starting"}
+	mockContainer := &types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         dockerID,
+			State:      &types.ContainerState{Running: true, Health: health},
+			HostConfig: &dockercontainer.HostConfig{NetworkMode: "bridge"},
+		},
+		Config:          &dockercontainer.Config{Image: "image"},
+		NetworkSettings: &types.NetworkSettings{},
+	}
+	mockClient.EXPECT().InspectContainer(gomock.Any(), dockerID, dockerclient.InspectContainerTimeout).
+		Return(mockContainer, nil).AnyTimes()
+
+	metadataDir, err := getMetadataFilePath(validTaskARN, containerName, cfg.DataDir)
+	require.NoError(t, err)
+	readMetadata := func() metadataSerializer {
+		data, err := os.ReadFile(filepath.Join(metadataDir, metadataFile))
+		require.NoError(t, err)
+		var metadata metadataSerializer
+		require.NoError(t, json.Unmarshal(data, &metadata))
+		return metadata
+	}
+	readVersion := func() string {
+		data, err := os.ReadFile(filepath.Join(metadataDir, metadataVersionFile))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	manager := NewManager(mockClient, cfg)
+	mockConfig := &dockercontainer.Config{}
+	require.NoError(t, manager.Create(mockConfig, &dockercontainer.HostConfig{}, mockTask, containerName, nil))
+	assert.Len(t, mockConfig.Env, 3)
+	assert.Equal(t, "1\n", readVersion())
+
+	ctx := context.TODO()
+	require.NoError(t, manager.Update(ctx, dockerID, mockTask, containerName))
+	require.NoError(t, manager.Update(ctx, dockerID, mockTask, containerName))
+	assert.Equal(t, "2\n", readVersion())
+	assert.Equal(t, "starting", readMetadata().HealthStatus)
+
+	health.Status = "healthy"
+	require.NoError(t, manager.Update(ctx, dockerID, mockTask, containerName))
+	assert.Equal(t, "3\n", readVersion())
+	metadata := readMetadata()
+	assert.Equal(t, "healthy", metadata.HealthStatus)
+	assert.Equal(t, uint64(3), metadata.MetadataVersion)
+	envData, err := os.ReadFile(filepath.Join(metadataDir, metadataEnvFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(envData), "ECS_METADATA_HEALTH_STATUS='healthy'\n")
+	assert.Contains(t, string(envData), "ECS_METADATA_VERSION='3'\n")
+	yamlData, err := os.ReadFile(filepath.Join(metadataDir, metadataYAMLFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(yamlData), "MetadataVersion: 3\n")
+
+	// The version continues from the version file after a restart
+	manager = NewManager(mockClient, cfg)
+	require.NoError(t, manager.Update(ctx, dockerID, mockTask, containerName))
+	assert.Equal(t, "4\n", readVersion())
+
+	require.NoError(t, manager.Clean(validTaskARN))
+	assert.Empty(t, manager.(*metadataManager).written)
+	_, err = os.Stat(metadataDir)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -15,6 +15,7 @@ package containermetadata
 
 import (
 	"fmt"
+	"sort"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
@@ -109,6 +110,16 @@ func parseDockerContainerMetadata(taskARN string, containerName string, dockerCo
 		seelog.Warnf("Failed to parse container metadata for task %s container %s: %v", taskARN, containerName, err)
 	}
 
+	// Sort the ports so that the metadata is only rewritten when the port bindings change
+	sort.Slice(ports, func(i, j int) bool {
+		return portBindingKey(ports[i]) < portBindingKey(ports[j])
+	})
+
+	healthStatus := ""
+	if dockerContainer.State != nil && dockerContainer.State.Health != nil {
+		healthStatus = dockerContainer.State.Health.Status
+	}
+
 	return DockerContainerMetadata{
 		containerID:         dockerContainer.ID,
 		dockerContainerName: dockerContainer.Name,
@@ -116,6 +127,7 @@ func parseDockerContainerMetadata(taskARN string, containerName string, dockerCo
 		imageName:           imageNameFromConfig,
 		ports:               ports,
 		networkInfo:         networkMetadata,
+		healthStatus:        healthStatus,
 	}
 }
 
@@ -151,6 +163,9 @@ func parseNetworkMetadata(settings *types.NetworkSettings, hostConfig *dockercon
 			network := tmdsresponse.Network{NetworkMode: networkMode, IPv4Addresses: ipv4Addresses}
 			networkList = append(networkList, network)
 		}
+		sort.Slice(networkList, func(i, j int) bool {
+			return networkList[i].NetworkMode < networkList[j].NetworkMode
+		})
 	} else {
 		ipv4Addresses := []string{ipv4AddressFromSettings}
 		network := tmdsresponse.Network{NetworkMode: networkModeFromHostConfig, IPv4Addresses: ipv4Addresses}
@@ -161,3 +176,8 @@ func parseNetworkMetadata(settings *types.NetworkSettings, hostConfig *dockercon
 		networks: networkList,
 	}, nil
 }
+
+func portBindingKey(port apicontainer.PortBinding) string {
+	return fmt.Sprintf("%05d/%s/%s/%05d/%s", port.ContainerPort, port.ContainerPortRange,
+		port.Protocol.String(), port.HostPort, port.BindIP)
+}
@@ -101,6 +101,7 @@ type DockerContainerMetadata struct {
 	imageName           string
 	ports               []apicontainer.PortBinding
 	networkInfo         NetworkMetadata
+	healthStatus        string
 }
 
 // TaskMetadata keeps track of all metadata associated with a task
@@ -125,6 +126,8 @@ type Metadata struct {
 	availabilityZone        string
 	hostPrivateIPv4Address  string
 	hostPublicIPv4Address   string
+	// version is incremented every time the metadata of the container is rewritten
+	version uint64
 }
 
 // metadataSerializer is an intermediate struct that converts the information
@@ -146,26 +149,33 @@ type metadataSerializer struct {
 	AvailabilityZone       string                     `json:"AvailabilityZone,omitempty"`
 	HostPrivateIPv4Address string                     `json:"HostPrivateIPv4Address,omitempty"`
 	HostPublicIPv4Address  string                     `json:"HostPublicIPv4Address,omitempty"`
+	HealthStatus           string                     `json:"HealthStatus,omitempty"`
+	MetadataVersion        uint64                     `json:"MetadataVersion,omitempty"`
 }
 
 func (m Metadata) MarshalJSON() ([]byte, error) {
-	return json.Marshal(
-		metadataSerializer{
-			Cluster:                m.cluster,
-			ContainerInstanceARN:   m.containerInstanceARN,
-			TaskARN:                m.taskMetadata.taskARN,
-			TaskDefinitionFamily:   m.taskMetadata.taskDefinitionFamily,
-			TaskDefinitionRevision: m.taskMetadata.taskDefinitionRevision,
-			ContainerID:            m.dockerContainerMetadata.containerID,
-			ContainerName:          m.taskMetadata.containerName,
-			DockerContainerName:    m.dockerContainerMetadata.dockerContainerName,
-			ImageID:                m.dockerContainerMetadata.imageID,
-			ImageName:              m.dockerContainerMetadata.imageName,
-			Ports:                  m.dockerContainerMetadata.ports,
-			Networks:               m.dockerContainerMetadata.networkInfo.networks,
-			MetadataFileStatus:     m.metadataStatus,
-			AvailabilityZone:       m.availabilityZone,
-			HostPrivateIPv4Address: m.hostPrivateIPv4Address,
-			HostPublicIPv4Address:  m.hostPublicIPv4Address,
-		})
+	return json.Marshal(m.serializer())
+}
+
+func (m Metadata) serializer() metadataSerializer {
+	return metadataSerializer{
+		Cluster:                m.cluster,
+		ContainerInstanceARN:   m.containerInstanceARN,
+		TaskARN:                m.taskMetadata.taskARN,
+		TaskDefinitionFamily:   m.taskMetadata.taskDefinitionFamily,
+		TaskDefinitionRevision: m.taskMetadata.taskDefinitionRevision,
+		ContainerID:            m.dockerContainerMetadata.containerID,
+		ContainerName:          m.taskMetadata.containerName,
+		DockerContainerName:    m.dockerContainerMetadata.dockerContainerName,
+		ImageID:                m.dockerContainerMetadata.imageID,
+		ImageName:              m.dockerContainerMetadata.imageName,
+		Ports:                  m.dockerContainerMetadata.ports,
+		Networks:               m.dockerContainerMetadata.networkInfo.networks,
+		MetadataFileStatus:     m.metadataStatus,
+		AvailabilityZone:       m.availabilityZone,
+		HostPrivateIPv4Address: m.hostPrivateIPv4Address,
+		HostPublicIPv4Address:  m.hostPublicIPv4Address,
+		HealthStatus:           m.dockerContainerMetadata.healthStatus,
+		MetadataVersion:        m.version,
+	}
 }
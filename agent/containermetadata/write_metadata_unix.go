@@ -38,7 +38,7 @@ const (
 // createBindsEnv will do the appropriate formatting to add a new mount in a container's HostConfig
 // and add the metadata file path as an environment variable ECS_CONTAINER_METADATA_FILE
 // We add an additional uuid to the path to ensure it does not conflict with user mounts
-func createBindsEnv(binds []string, env []string, dataDirOnHost string, metadataDirectoryPath string, dockerSecurityOptions []string) ([]string, []string) {
+func createBindsEnv(binds []string, env []string, dataDirOnHost string, metadataDirectoryPath string, dockerSecurityOptions []string, formats []metadataFormat) ([]string, []string) {
 	selinuxEnabled := false
 	for _, option := range dockerSecurityOptions {
 		if option == selinuxSecurityOption {
@@ -55,6 +55,9 @@ func createBindsEnv(binds []string, env []string, dataDirOnHost string, metadata
 	metadataEnvVariable := fmt.Sprintf("%s=%s/%s/%s", metadataEnvironmentVariable, mountPoint, randID, metadataFile)
 	binds = append(binds, instanceBind)
 	env = append(env, metadataEnvVariable)
+	for _, format := range formats {
+		env = append(env, fmt.Sprintf("%s=%s/%s/%s", format.environmentVariable, mountPoint, randID, format.fileName))
+	}
 	return binds, env
 }
 
@@ -67,12 +70,16 @@ var TempFile = func(dir, pattern string) (oswrapper.File, error) {
 // writeToMetadata puts the metadata into JSON format and writes into
 // the metadata file
 func writeToMetadataFile(data []byte, taskARN string, containerName string, dataDir string) error {
+	return writeMetadataFile(data, metadataFile, taskARN, containerName, dataDir)
+}
+
+func writeMetadataFile(data []byte, fileName string, taskARN string, containerName string, dataDir string) error {
 	metadataFileDir, err := getMetadataFilePath(taskARN, containerName, dataDir)
 	// Boundary case if file path is bad (Such as if task arn is incorrectly formatted)
 	if err != nil {
 		return fmt.Errorf("write to metadata file for task %s container %s: %v", taskARN, containerName, err)
 	}
-	metadataFileName := filepath.Join(metadataFileDir, metadataFile)
+	metadataFileName := filepath.Join(metadataFileDir, fileName)
 
 	temp, err := TempFile(metadataFileDir, tempFile)
 	if err != nil {
@@ -137,7 +137,7 @@ func TestCreateBindEnv(t *testing.T) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			binds, _ := createBindsEnv(mockBinds, mockEnv, mockDataDirOnHost, mockMetadataDirectoryPath, tc.securityOptions)
+			binds, _ := createBindsEnv(mockBinds, mockEnv, mockDataDirOnHost, mockMetadataDirectoryPath, tc.securityOptions, nil)
 			actualBindMode := binds[0][len(binds[0])-2:]
 			if tc.selinuxEnabled {
 				assert.Equal(t, expectedBindMode, actualBindMode)
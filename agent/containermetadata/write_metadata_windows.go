@@ -32,12 +32,15 @@ const (
 
 // createBindsEnv will do the appropriate formatting to add a new mount in a container's HostConfig
 // and add the metadata file path as an environment variable ECS_CONTAINER_METADATA_FILE
-func createBindsEnv(binds []string, env []string, dataDirOnHost string, metadataDirectoryPath string, dockerSecurityOptions []string) ([]string, []string) {
+func createBindsEnv(binds []string, env []string, dataDirOnHost string, metadataDirectoryPath string, dockerSecurityOptions []string, formats []metadataFormat) ([]string, []string) {
 	randID := uuid.New()
 	instanceBind := fmt.Sprintf(`%s:%s\%s`, metadataDirectoryPath, mountPoint, randID)
 	metadataEnvVariable := fmt.Sprintf(`%s=%s\%s\%s`, metadataEnvironmentVariable, mountPoint, randID, metadataFile)
 	binds = append(binds, instanceBind)
 	env = append(env, metadataEnvVariable)
+	for _, format := range formats {
+		env = append(env, fmt.Sprintf(`%s=%s\%s\%s`, format.environmentVariable, mountPoint, randID, format.fileName))
+	}
 	return binds, env
 }
 
@@ -48,14 +51,18 @@ var openFile = func(name string, flag int, perm os.FileMode) (oswrapper.File, er
 // writeToMetadata puts the metadata into JSON format and writes into
 // the metadata file
 func writeToMetadataFile(data []byte, taskARN string, containerName string, dataDir string) error {
+	return writeMetadataFile(data, metadataFile, taskARN, containerName, dataDir)
+}
+
+func writeMetadataFile(data []byte, fileName string, taskARN string, containerName string, dataDir string) error {
 	metadataFileDir, err := getMetadataFilePath(taskARN, containerName, dataDir)
 	// Boundary case if file path is bad (Such as if task arn is incorrectly formatted)
 	if err != nil {
 		return fmt.Errorf("write to metadata file for task %s container %s: %v", taskARN, containerName, err)
 	}
-	metadataFileName := filepath.Join(metadataFileDir, metadataFile)
+	metadataFileName := filepath.Join(metadataFileDir, fileName)
 
-	file, err := openFile(metadataFileName, os.O_WRONLY|os.O_CREATE, metadataPerm)
+	file, err := openFile(metadataFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, metadataPerm)
 	if err != nil {
 		return err
 	}
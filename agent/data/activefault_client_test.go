@@ -0,0 +1,68 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	faulttypes "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/fault/v1/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageActiveFaults(t *testing.T) {
+	for backend, testClient := range map[string]Client{
+		BoltDBBackend: newTestClient(t),
+		WALBackend:    newTestWALClient(t, t.TempDir()),
+	} {
+		t.Run(backend, func(t *testing.T) {
+			latencyFault := &faulttypes.ActiveFault{
+				FaultType: faulttypes.LatencyFaultType,
+				TaskARN:   testTaskArn,
+				Request:   json.RawMessage(`{"DelayMilliseconds":100}`),
+			}
+			require.NoError(t, testClient.SaveActiveFault(latencyFault))
+			latencyFault.ExpiresAt = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+			require.NoError(t, testClient.SaveActiveFault(latencyFault))
+			blackholePortFault := &faulttypes.ActiveFault{
+				FaultType: faulttypes.BlackHolePortFaultType,
+				ID:        "egress-tcp-443",
+				TaskARN:   testTaskArn,
+				Request:   json.RawMessage(`{"Port":443}`),
+			}
+			require.NoError(t, testClient.SaveActiveFault(blackholePortFault))
+
+			res, err := testClient.GetActiveFaults()
+			require.NoError(t, err)
+			// The faults are ordered by key, and the keys of the faults of a task are ordered by fault type.
+			require.Len(t, res, 2)
+			assert.Equal(t, blackholePortFault, res[0])
+			assert.Equal(t, latencyFault, res[1])
+
+			require.NoError(t, testClient.DeleteActiveFault(latencyFault.Key()))
+			require.NoError(t, testClient.DeleteActiveFault(blackholePortFault.Key()))
+			res, err = testClient.GetActiveFaults()
+			require.NoError(t, err)
+			assert.Len(t, res, 0)
+
+			assert.Error(t, testClient.SaveActiveFault(&faulttypes.ActiveFault{}))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+func (c *client) SaveBridgeConfig(bridgeConfig *tasknetworkconfig.BridgeConfig) error {
+	if bridgeConfig.TaskID == "" {
+		return errors.New("failed to generate database id for bridge config without task id")
+	}
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bridgeConfigsBucketName))
+		return c.Accessor.PutObject(b, bridgeConfig.TaskID, bridgeConfig)
+	})
+}
+
+func (c *client) DeleteBridgeConfig(taskID string) error {
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bridgeConfigsBucketName))
+		return b.Delete([]byte(taskID))
+	})
+}
+
+func (c *client) GetBridgeConfigs() ([]*tasknetworkconfig.BridgeConfig, error) {
+	var bridgeConfigs []*tasknetworkconfig.BridgeConfig
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(bridgeConfigsBucketName))
+		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+			bridgeConfig := tasknetworkconfig.BridgeConfig{}
+			if err := json.Unmarshal(data, &bridgeConfig); err != nil {
+				return err
+			}
+			bridgeConfigs = append(bridgeConfigs, &bridgeConfig)
+			return nil
+		})
+	})
+	return bridgeConfigs, err
+}
@@ -0,0 +1,55 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageBridgeConfigs(t *testing.T) {
+	for backend, testClient := range map[string]Client{
+		BoltDBBackend: newTestClient(t),
+		WALBackend:    newTestWALClient(t, t.TempDir()),
+	} {
+		t.Run(backend, func(t *testing.T) {
+			bridgeConfig := tasknetworkconfig.NewBridgeConfig("task1", "ecs-task-bridge", "172.30.0.0/16")
+			require.NoError(t, testClient.SaveBridgeConfig(bridgeConfig))
+			bridgeConfig.IPV4Address = "172.30.0.2/16"
+			require.NoError(t, testClient.SaveBridgeConfig(bridgeConfig))
+			require.NoError(t, testClient.SaveBridgeConfig(
+				tasknetworkconfig.NewBridgeConfig("task2", "ecs-task-bridge", "172.30.0.0/16")))
+
+			res, err := testClient.GetBridgeConfigs()
+			require.NoError(t, err)
+			require.Len(t, res, 2)
+			assert.Equal(t, bridgeConfig, res[0])
+
+			require.NoError(t, testClient.DeleteBridgeConfig("task1"))
+			require.NoError(t, testClient.DeleteBridgeConfig("task2"))
+			res, err = testClient.GetBridgeConfigs()
+			require.NoError(t, err)
+			assert.Len(t, res, 0)
+
+			assert.Error(t, testClient.SaveBridgeConfig(&tasknetworkconfig.BridgeConfig{}))
+		})
+	}
+}
@@ -14,6 +14,7 @@
 package data
 
 import (
+	"os"
 	"path/filepath"
 	"sync"
 
@@ -23,22 +24,37 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	generaldata "github.com/aws/amazon-ecs-agent/ecs-agent/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/modeltransformer"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	faulttypes "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/fault/v1/types"
+
+	"github.com/pkg/errors"
 	bolt "go.etcd.io/bbolt"
 )
 
 const (
 	dbName = "agent.db"
 	dbMode = 0600
+	// migratedSuffix is appended to the name of a data store once its data is migrated to
+	// another backend.
+	migratedSuffix = ".migrated"
 
 	containersBucketName     = "containers"
 	tasksBucketName          = "tasks"
 	imagesBucketName         = "images"
 	eniAttachmentsBucketName = "eniattachments"
 	resAttachmentsBucketName = "resattachments"
+	bridgeConfigsBucketName  = "bridgeconfigs"
+	activeFaultsBucketName   = "activefaults"
 	metadataBucketName       = "metadata"
 	emptyAgentVersionMsg     = "No version info available in boltDB. Either this is a fresh instance, or we were using state file to persist data. Transformer not applicable."
+
+	// BoltDBBackend persists the agent's data in a boltdb file. This is the default backend.
+	BoltDBBackend = "boltdb"
+	// WALBackend persists the agent's data in an append-only log of JSON records.
+	WALBackend = "wal"
 )
 
 var (
@@ -51,6 +67,8 @@ var (
 		tasksBucketName,
 		eniAttachmentsBucketName,
 		resAttachmentsBucketName,
+		bridgeConfigsBucketName,
+		activeFaultsBucketName,
 		metadataBucketName,
 	}
 )
@@ -98,6 +116,21 @@ type Client interface {
 	// GetResourceAttachments gets the data of all the resouce attachments.
 	GetResourceAttachments() ([]*resource.ResourceAttachment, error)
 
+	// SaveBridgeConfig saves the data of the connection of a task to the task bridge.
+	SaveBridgeConfig(*tasknetworkconfig.BridgeConfig) error
+	// DeleteBridgeConfig deletes the data of the connection of a task to the task bridge.
+	DeleteBridgeConfig(string) error
+	// GetBridgeConfigs gets the data of all the connections of tasks to the task bridge.
+	GetBridgeConfigs() ([]*tasknetworkconfig.BridgeConfig, error)
+
+	// SaveActiveFault saves the data of a fault injected in the network of a task that the agent stops
+	// once it expires.
+	SaveActiveFault(*faulttypes.ActiveFault) error
+	// DeleteActiveFault deletes the data of an active fault.
+	DeleteActiveFault(string) error
+	// GetActiveFaults gets the data of all the active faults.
+	GetActiveFaults() ([]*faulttypes.ActiveFault, error)
+
 	// SaveMetadata saves a key value pair of metadata.
 	SaveMetadata(string, string) error
 	// GetMetadata gets the value of a certain kind of metadata.
@@ -124,6 +157,90 @@ func New(dataDir string) (Client, error) {
 	return dbClient, nil
 }
 
+// NewWithBackend returns a data client that persists data in dataDir with the given backend.
+// An empty backend selects the default boltdb backend. If dataDir has no data of the given
+// backend but has data of the other one, that data is migrated to the given backend so that
+// switching backends doesn't start the agent from an empty state.
+func NewWithBackend(backend, dataDir string) (Client, error) {
+	switch backend {
+	case BoltDBBackend, "":
+		return newWithMigration(dataDir, dbName, New, walName, NewWAL)
+	case WALBackend:
+		return newWithMigration(dataDir, walName, NewWAL, dbName, NewWithSetup)
+	default:
+		return nil, errors.Errorf("unsupported data backend %q, supported backends are %q and %q",
+			backend, BoltDBBackend, WALBackend)
+	}
+}
+
+// newWithMigration opens the data store named name in dataDir with open. If it doesn't exist
+// and the data store named otherName does, the data of the latter is imported first, and the
+// latter is renamed with a migratedSuffix so that it is not migrated again.
+func newWithMigration(dataDir, name string, open func(string) (Client, error),
+	otherName string, openOther func(string) (Client, error)) (Client, error) {
+	path := filepath.Join(dataDir, name)
+	otherPath := filepath.Join(dataDir, otherName)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return open(dataDir)
+	}
+	if _, err := os.Stat(otherPath); os.IsNotExist(err) {
+		return open(dataDir)
+	}
+
+	logger.Info("Migrating the agent state to a new data backend", logger.Fields{
+		"from": otherPath,
+		"to":   path,
+	})
+	otherClient, err := openOther(dataDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open agent state at %s to migrate it", otherPath)
+	}
+	state, err := Export(otherClient)
+	otherClient.Close()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read agent state at %s to migrate it", otherPath)
+	}
+	c, err := open(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := Import(c, state); err != nil {
+		// Remove the partial copy so that the migration is retried on the next start.
+		c.Close()
+		os.Remove(path)
+		return nil, errors.Wrapf(err, "failed to migrate agent state to %s", path)
+	}
+	if err := os.Rename(otherPath, otherPath+migratedSuffix); err != nil {
+		c.Close()
+		os.Remove(path)
+		return nil, errors.Wrapf(err, "failed to rename migrated agent state at %s", otherPath)
+	}
+	return c, nil
+}
+
+// NewExistingWithBackend returns a data client for the data persisted in dataDir with the
+// given backend. Unlike NewWithBackend, it returns an error rather than creating an empty
+// store when no data was persisted there.
+func NewExistingWithBackend(backend, dataDir string) (Client, error) {
+	var name string
+	switch backend {
+	case BoltDBBackend, "":
+		name = dbName
+	case WALBackend:
+		name = walName
+	default:
+		return NewWithBackend(backend, dataDir)
+	}
+	path := filepath.Join(dataDir, name)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("no agent state found at %s", path)
+		}
+		return nil, err
+	}
+	return NewWithBackend(backend, dataDir)
+}
+
 // NewWithSetup returns a data client that implements the Client interface with boltdb.
 // It always runs the db setup. Used for testing.
 func NewWithSetup(dataDir string) (Client, error) {
@@ -19,6 +19,8 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	faulttypes "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/fault/v1/types"
 )
 
 type noopClient struct{}
@@ -93,6 +95,30 @@ func (c *noopClient) GetResourceAttachments() ([]*resource.ResourceAttachment, e
 	return nil, nil
 }
 
+func (c *noopClient) SaveBridgeConfig(*tasknetworkconfig.BridgeConfig) error {
+	return nil
+}
+
+func (c *noopClient) DeleteBridgeConfig(string) error {
+	return nil
+}
+
+func (c *noopClient) GetBridgeConfigs() ([]*tasknetworkconfig.BridgeConfig, error) {
+	return nil, nil
+}
+
+func (c *noopClient) SaveActiveFault(*faulttypes.ActiveFault) error {
+	return nil
+}
+
+func (c *noopClient) DeleteActiveFault(string) error {
+	return nil
+}
+
+func (c *noopClient) GetActiveFaults() ([]*faulttypes.ActiveFault, error) {
+	return nil, nil
+}
+
 func (c *noopClient) SaveMetadata(string, string) error {
 	return nil
 }
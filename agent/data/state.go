@@ -0,0 +1,134 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	faulttypes "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/fault/v1/types"
+
+	"github.com/pkg/errors"
+)
+
+// metadataKeys are the metadata keys saved by the agent.
+var metadataKeys = []string{
+	AgentVersionKey,
+	AvailabilityZoneKey,
+	ClusterNameKey,
+	ContainerInstanceARNKey,
+	EC2InstanceIDKey,
+	TaskManifestSeqNumKey,
+}
+
+// State holds all the data persisted by the agent. It is used to dump the persisted data
+// as JSON, and to move it from one backend to another.
+type State struct {
+	Tasks               []*task.Task                      `json:"tasks"`
+	Containers          []*container.DockerContainer      `json:"containers"`
+	ImageStates         []*image.ImageState               `json:"imageStates"`
+	ENIAttachments      []*networkinterface.ENIAttachment `json:"eniAttachments"`
+	ResourceAttachments []*resource.ResourceAttachment    `json:"resourceAttachments"`
+	BridgeConfigs       []*tasknetworkconfig.BridgeConfig `json:"bridgeConfigs,omitempty"`
+	ActiveFaults        []*faulttypes.ActiveFault         `json:"activeFaults,omitempty"`
+	Metadata            map[string]string                 `json:"metadata"`
+}
+
+// Export reads all the data persisted with the client.
+func Export(c Client) (*State, error) {
+	var (
+		state = &State{Metadata: make(map[string]string)}
+		err   error
+	)
+	if state.Tasks, err = c.GetTasks(); err != nil {
+		return nil, errors.Wrap(err, "failed to get tasks")
+	}
+	if state.Containers, err = c.GetContainers(); err != nil {
+		return nil, errors.Wrap(err, "failed to get containers")
+	}
+	if state.ImageStates, err = c.GetImageStates(); err != nil {
+		return nil, errors.Wrap(err, "failed to get image states")
+	}
+	if state.ENIAttachments, err = c.GetENIAttachments(); err != nil {
+		return nil, errors.Wrap(err, "failed to get eni attachments")
+	}
+	if state.ResourceAttachments, err = c.GetResourceAttachments(); err != nil {
+		return nil, errors.Wrap(err, "failed to get resource attachments")
+	}
+	if state.BridgeConfigs, err = c.GetBridgeConfigs(); err != nil {
+		return nil, errors.Wrap(err, "failed to get bridge configs")
+	}
+	if state.ActiveFaults, err = c.GetActiveFaults(); err != nil {
+		return nil, errors.Wrap(err, "failed to get active faults")
+	}
+	for _, key := range metadataKeys {
+		// A missing key means the metadata was never saved.
+		if val, err := c.GetMetadata(key); err == nil {
+			state.Metadata[key] = val
+		}
+	}
+	return state, nil
+}
+
+// Import saves all the data of the state with the client. Data already persisted with
+// the client under the same keys is overwritten.
+func Import(c Client, state *State) error {
+	for _, t := range state.Tasks {
+		if err := c.SaveTask(t); err != nil {
+			return errors.Wrapf(err, "failed to save task %s", t.Arn)
+		}
+	}
+	for _, dockerContainer := range state.Containers {
+		if dockerContainer.Container == nil {
+			return errors.Errorf("container %s has no container data", dockerContainer.DockerID)
+		}
+		if err := c.SaveDockerContainer(dockerContainer); err != nil {
+			return errors.Wrapf(err, "failed to save container %s", dockerContainer.Container.Name)
+		}
+	}
+	for _, imageState := range state.ImageStates {
+		if err := c.SaveImageState(imageState); err != nil {
+			return errors.Wrapf(err, "failed to save image state %s", imageState.GetImageID())
+		}
+	}
+	for _, eniAttachment := range state.ENIAttachments {
+		if err := c.SaveENIAttachment(eniAttachment); err != nil {
+			return errors.Wrapf(err, "failed to save eni attachment %s", eniAttachment.AttachmentARN)
+		}
+	}
+	for _, resAttachment := range state.ResourceAttachments {
+		if err := c.SaveResourceAttachment(resAttachment); err != nil {
+			return errors.Wrapf(err, "failed to save resource attachment %s", resAttachment.AttachmentARN)
+		}
+	}
+	for _, bridgeConfig := range state.BridgeConfigs {
+		if err := c.SaveBridgeConfig(bridgeConfig); err != nil {
+			return errors.Wrapf(err, "failed to save bridge config of task %s", bridgeConfig.TaskID)
+		}
+	}
+	for _, fault := range state.ActiveFaults {
+		if err := c.SaveActiveFault(fault); err != nil {
+			return errors.Wrapf(err, "failed to save active %s fault of task %s", fault.FaultType, fault.TaskARN)
+		}
+	}
+	for key, val := range state.Metadata {
+		if err := c.SaveMetadata(key, val); err != nil {
+			return errors.Wrapf(err, "failed to save metadata %s", key)
+		}
+	}
+	return nil
+}
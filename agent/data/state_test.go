@@ -0,0 +1,98 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	faulttypes "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/fault/v1/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	source := newTestClient(t)
+	require.NoError(t, source.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	require.NoError(t, source.SaveDockerContainer(&apicontainer.DockerContainer{
+		DockerID: testDockerID,
+		Container: &apicontainer.Container{
+			Name:          testContainerName,
+			TaskARNUnsafe: testTaskArn,
+		},
+	}))
+	require.NoError(t, source.SaveImageState(&image.ImageState{Image: &image.Image{ImageID: "sha256:1"}}))
+	require.NoError(t, source.SaveENIAttachment(&ni.ENIAttachment{
+		AttachmentInfo: attachment.AttachmentInfo{AttachmentARN: testAttachmentArn},
+	}))
+	require.NoError(t, source.SaveResourceAttachment(&resource.ResourceAttachment{
+		AttachmentInfo: attachment.AttachmentInfo{AttachmentARN: testAttachmentArn3},
+	}))
+	require.NoError(t, source.SaveBridgeConfig(
+		tasknetworkconfig.NewBridgeConfig("task1", "ecs-task-bridge", "172.30.0.0/16")))
+	require.NoError(t, source.SaveActiveFault(&faulttypes.ActiveFault{
+		FaultType: faulttypes.LatencyFaultType,
+		TaskARN:   testTaskArn,
+	}))
+	require.NoError(t, source.SaveMetadata(ClusterNameKey, "test-cluster"))
+
+	state, err := Export(source)
+	require.NoError(t, err)
+	assert.Len(t, state.Tasks, 1)
+	assert.Len(t, state.Containers, 1)
+	assert.Len(t, state.ImageStates, 1)
+	assert.Len(t, state.ENIAttachments, 1)
+	assert.Len(t, state.ResourceAttachments, 1)
+	assert.Len(t, state.BridgeConfigs, 1)
+	assert.Len(t, state.ActiveFaults, 1)
+	assert.Equal(t, map[string]string{ClusterNameKey: "test-cluster"}, state.Metadata)
+
+	// The state goes through JSON, as it does with the state export and import flags.
+	stateJSON, err := json.Marshal(state)
+	require.NoError(t, err)
+	importedState := &State{}
+	require.NoError(t, json.Unmarshal(stateJSON, importedState))
+
+	destination := newTestWALClient(t, t.TempDir())
+	require.NoError(t, Import(destination, importedState))
+	exported, err := Export(destination)
+	require.NoError(t, err)
+	require.Len(t, exported.Tasks, 1)
+	assert.Equal(t, testTaskArn, exported.Tasks[0].Arn)
+	require.Len(t, exported.Containers, 1)
+	assert.Equal(t, testDockerID, exported.Containers[0].DockerID)
+	assert.Equal(t, testContainerName, exported.Containers[0].Container.Name)
+	require.Len(t, exported.ImageStates, 1)
+	assert.Equal(t, "sha256:1", exported.ImageStates[0].GetImageID())
+	require.Len(t, exported.ENIAttachments, 1)
+	assert.Equal(t, testAttachmentArn, exported.ENIAttachments[0].AttachmentARN)
+	require.Len(t, exported.ResourceAttachments, 1)
+	assert.Equal(t, testAttachmentArn3, exported.ResourceAttachments[0].AttachmentARN)
+	require.Len(t, exported.BridgeConfigs, 1)
+	assert.Equal(t, "task1", exported.BridgeConfigs[0].TaskID)
+	require.Len(t, exported.ActiveFaults, 1)
+	assert.Equal(t, testTaskArn, exported.ActiveFaults[0].TaskARN)
+	assert.Equal(t, state.Metadata, exported.Metadata)
+}
@@ -0,0 +1,537 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data/transformationfunctions"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/aws/amazon-ecs-agent/agent/version"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/modeltransformer"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	faulttypes "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/fault/v1/types"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	walName = "agent.wal"
+
+	walOpPut    = "put"
+	walOpDelete = "delete"
+
+	// walCompactionThreshold is the number of superseded records after which the log
+	// is rewritten to only contain the live records.
+	walCompactionThreshold = 1000
+)
+
+// walRecord is a single line of the write-ahead log. Records are applied in order
+// when the log is replayed, the last record of a key wins.
+type walRecord struct {
+	Op     string          `json:"op"`
+	Bucket string          `json:"bucket"`
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value,omitempty"`
+}
+
+// walClient implements the Client interface using an append-only log of JSON records
+// as the backing data store. The live records are kept in memory, and every change is
+// appended and synced to the log before it is applied. The log is a plain text file,
+// one JSON record per line, so that it can be inspected with standard tools.
+type walClient struct {
+	lock         sync.RWMutex
+	path         string
+	file         *os.File
+	buckets      map[string]map[string]json.RawMessage
+	staleRecords int
+	transformer  *modeltransformer.Transformer
+}
+
+// NewWAL returns a data client that implements the Client interface with an append-only
+// log stored in dataDir.
+func NewWAL(dataDir string) (Client, error) {
+	c := &walClient{
+		path:        filepath.Join(dataDir, walName),
+		buckets:     make(map[string]map[string]json.RawMessage),
+		transformer: modeltransformer.NewTransformer(),
+	}
+	for _, b := range buckets {
+		c.buckets[b] = make(map[string]json.RawMessage)
+	}
+	transformationfunctions.RegisterTaskTransformationFunctions(c.transformer)
+
+	if err := c.replay(); err != nil {
+		return nil, err
+	}
+	// Start every run from a compacted log, this also drops a record that may have been
+	// partially written when the previous run was interrupted.
+	if err := c.compact(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// replay loads the live records from the log into memory.
+func (c *walClient) replay() error {
+	f, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to open write-ahead log")
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return errors.Wrap(err, "failed to read write-ahead log")
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			var record walRecord
+			if unmarshalErr := json.Unmarshal(line, &record); unmarshalErr != nil {
+				if err == io.EOF {
+					// A record without a trailing newline was being written when the agent
+					// stopped, it was never acknowledged to the caller.
+					logger.Warn("Discarding incomplete record at the end of the write-ahead log", logger.Fields{
+						field.Error: unmarshalErr,
+						"line":      lineNum,
+					})
+					return nil
+				}
+				return errors.Wrapf(unmarshalErr, "failed to parse write-ahead log record at line %d", lineNum)
+			}
+			c.apply(record)
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// apply updates the in-memory records. Callers must hold the lock.
+func (c *walClient) apply(record walRecord) {
+	bucket, ok := c.buckets[record.Bucket]
+	if !ok {
+		bucket = make(map[string]json.RawMessage)
+		c.buckets[record.Bucket] = bucket
+	}
+	if _, ok := bucket[record.Key]; ok {
+		c.staleRecords++
+	}
+	switch record.Op {
+	case walOpDelete:
+		delete(bucket, record.Key)
+		c.staleRecords++
+	default:
+		bucket[record.Key] = record.Value
+	}
+}
+
+// compact rewrites the log with only the live records and reopens it for appending.
+// Callers must hold the lock.
+func (c *walClient) compact() error {
+	tmpPath := c.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, dbMode)
+	if err != nil {
+		return errors.Wrap(err, "failed to create write-ahead log")
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, bucketName := range sortedKeys(c.buckets) {
+		bucket := c.buckets[bucketName]
+		for _, key := range sortedKeys(bucket) {
+			line, err := json.Marshal(walRecord{Op: walOpPut, Bucket: bucketName, Key: key, Value: bucket[key]})
+			if err != nil {
+				tmp.Close()
+				return err
+			}
+			if _, err := writer.Write(append(line, '\n')); err != nil {
+				tmp.Close()
+				return errors.Wrap(err, "failed to write write-ahead log")
+			}
+		}
+	}
+	if err = writer.Flush(); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write write-ahead log")
+	}
+	if c.file != nil {
+		c.file.Close()
+		c.file = nil
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return errors.Wrap(err, "failed to replace write-ahead log")
+	}
+	// The rename is only durable once the directory entry is synced.
+	if err := syncDir(filepath.Dir(c.path)); err != nil {
+		return errors.Wrap(err, "failed to sync write-ahead log directory")
+	}
+	c.file, err = os.OpenFile(c.path, os.O_APPEND|os.O_WRONLY, dbMode)
+	if err != nil {
+		return errors.Wrap(err, "failed to open write-ahead log")
+	}
+	c.staleRecords = 0
+	return nil
+}
+
+// syncDir syncs a directory to disk, so that the files created or renamed in it persist.
+// Directories can't be synced on Windows, where renames are persisted by the filesystem.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// write appends a record to the log and applies it once it is synced to disk.
+func (c *walClient) write(record walRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.file == nil {
+		return errors.New("write-ahead log is closed")
+	}
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "failed to append to write-ahead log")
+	}
+	if err := c.file.Sync(); err != nil {
+		return errors.Wrap(err, "failed to sync write-ahead log")
+	}
+	c.apply(record)
+	if c.staleRecords >= walCompactionThreshold {
+		return c.compact()
+	}
+	return nil
+}
+
+func (c *walClient) put(bucket, key string, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return c.write(walRecord{Op: walOpPut, Bucket: bucket, Key: key, Value: data})
+}
+
+func (c *walClient) delete(bucket, key string) error {
+	return c.write(walRecord{Op: walOpDelete, Bucket: bucket, Key: key})
+}
+
+func (c *walClient) get(bucket, key string, out interface{}) error {
+	c.lock.RLock()
+	data, ok := c.buckets[bucket][key]
+	c.lock.RUnlock()
+	if !ok {
+		return errors.Errorf("no record found for key %s in %s", key, bucket)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// walk calls the callback with every record of the bucket, ordered by key.
+func (c *walClient) walk(bucket string, callback func(id string, data []byte) error) error {
+	c.lock.RLock()
+	records := make(map[string]json.RawMessage, len(c.buckets[bucket]))
+	for key, data := range c.buckets[bucket] {
+		records[key] = data
+	}
+	c.lock.RUnlock()
+	for _, key := range sortedKeys(records) {
+		if err := callback(key, records[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveDockerContainer saves a docker container to the containers log.
+func (c *walClient) SaveDockerContainer(container *apicontainer.DockerContainer) error {
+	id, err := GetContainerID(container.Container)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.put(containersBucketName, id, container)
+}
+
+// SaveContainer saves a container to the containers log.
+func (c *walClient) SaveContainer(container *apicontainer.Container) error {
+	id, err := GetContainerID(container)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	dockerContainer := &apicontainer.DockerContainer{}
+	if err := c.get(containersBucketName, id, dockerContainer); err != nil {
+		dockerContainer = &apicontainer.DockerContainer{}
+	}
+	dockerContainer.Container = container
+	return c.put(containersBucketName, id, dockerContainer)
+}
+
+// DeleteContainer deletes a container from the containers log.
+func (c *walClient) DeleteContainer(id string) error {
+	return c.delete(containersBucketName, id)
+}
+
+// GetContainers returns all the containers in the containers log.
+func (c *walClient) GetContainers() ([]*apicontainer.DockerContainer, error) {
+	var containers []*apicontainer.DockerContainer
+	err := c.walk(containersBucketName, func(id string, data []byte) error {
+		container := apicontainer.DockerContainer{}
+		if err := json.Unmarshal(data, &container); err != nil {
+			return err
+		}
+		containers = append(containers, &container)
+		return nil
+	})
+	return containers, err
+}
+
+// SaveTask saves a task to the tasks log.
+func (c *walClient) SaveTask(task *apitask.Task) error {
+	id, err := utils.GetTaskID(task.Arn)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.put(tasksBucketName, id, task)
+}
+
+// DeleteTask deletes a task from the tasks log.
+func (c *walClient) DeleteTask(id string) error {
+	return c.delete(tasksBucketName, id)
+}
+
+// GetTasks returns all the tasks in the tasks log.
+func (c *walClient) GetTasks() ([]*apitask.Task, error) {
+	agentVersionInDB, versionErr := c.GetMetadata(AgentVersionKey)
+	if versionErr != nil {
+		logger.Info(emptyAgentVersionMsg)
+	}
+	var tasks []*apitask.Task
+	err := c.walk(tasksBucketName, func(id string, data []byte) error {
+		task := apitask.Task{}
+		// transform the model before loading it to agent state.
+		if versionErr == nil && c.transformer.IsUpgrade(version.Version, agentVersionInDB) {
+			var err error
+			data, err = c.transformer.TransformTask(agentVersionInDB, data)
+			if err != nil {
+				return err
+			}
+		}
+		if err := json.Unmarshal(data, &task); err != nil {
+			return err
+		}
+		tasks = append(tasks, &task)
+		return nil
+	})
+	return tasks, err
+}
+
+// SaveImageState saves an image state to the images log.
+func (c *walClient) SaveImageState(img *image.ImageState) error {
+	id := img.GetImageID()
+	if id == "" {
+		return errors.New("failed to generate database image id")
+	}
+	return c.put(imagesBucketName, id, img)
+}
+
+// DeleteImageState deletes an image state from the images log.
+func (c *walClient) DeleteImageState(id string) error {
+	return c.delete(imagesBucketName, id)
+}
+
+// GetImageStates returns all the image states in the images log.
+func (c *walClient) GetImageStates() ([]*image.ImageState, error) {
+	var imageStates []*image.ImageState
+	err := c.walk(imagesBucketName, func(id string, data []byte) error {
+		imageState := image.ImageState{}
+		if err := json.Unmarshal(data, &imageState); err != nil {
+			return err
+		}
+		imageStates = append(imageStates, &imageState)
+		return nil
+	})
+	return imageStates, err
+}
+
+// SaveENIAttachment saves an ENI attachment to the ENI attachments log.
+func (c *walClient) SaveENIAttachment(eni *ni.ENIAttachment) error {
+	id, err := utils.GetAttachmentId(eni.AttachmentARN)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.put(eniAttachmentsBucketName, id, eni)
+}
+
+// DeleteENIAttachment deletes an ENI attachment from the ENI attachments log.
+func (c *walClient) DeleteENIAttachment(id string) error {
+	return c.delete(eniAttachmentsBucketName, id)
+}
+
+// GetENIAttachments returns all the ENI attachments in the ENI attachments log.
+func (c *walClient) GetENIAttachments() ([]*ni.ENIAttachment, error) {
+	var eniAttachments []*ni.ENIAttachment
+	err := c.walk(eniAttachmentsBucketName, func(id string, data []byte) error {
+		eniAttachment := ni.ENIAttachment{}
+		if err := json.Unmarshal(data, &eniAttachment); err != nil {
+			return err
+		}
+		eniAttachments = append(eniAttachments, &eniAttachment)
+		return nil
+	})
+	return eniAttachments, err
+}
+
+// SaveResourceAttachment saves a resource attachment to the resource attachments log.
+func (c *walClient) SaveResourceAttachment(res *resource.ResourceAttachment) error {
+	id, err := utils.GetAttachmentId(res.AttachmentARN)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.put(resAttachmentsBucketName, id, res)
+}
+
+// DeleteResourceAttachment deletes a resource attachment from the resource attachments log.
+func (c *walClient) DeleteResourceAttachment(id string) error {
+	return c.delete(resAttachmentsBucketName, id)
+}
+
+// GetResourceAttachments returns all the resource attachments in the resource attachments log.
+func (c *walClient) GetResourceAttachments() ([]*resource.ResourceAttachment, error) {
+	var resAttachments []*resource.ResourceAttachment
+	err := c.walk(resAttachmentsBucketName, func(id string, data []byte) error {
+		resAttachment := resource.ResourceAttachment{}
+		if err := json.Unmarshal(data, &resAttachment); err != nil {
+			return err
+		}
+		resAttachments = append(resAttachments, &resAttachment)
+		return nil
+	})
+	return resAttachments, err
+}
+
+// SaveBridgeConfig saves the connection of a task to the task bridge to the bridge configs log.
+func (c *walClient) SaveBridgeConfig(bridgeConfig *tasknetworkconfig.BridgeConfig) error {
+	if bridgeConfig.TaskID == "" {
+		return errors.New("failed to generate database id for bridge config without task id")
+	}
+	return c.put(bridgeConfigsBucketName, bridgeConfig.TaskID, bridgeConfig)
+}
+
+// DeleteBridgeConfig deletes the connection of a task to the task bridge from the bridge configs log.
+func (c *walClient) DeleteBridgeConfig(taskID string) error {
+	return c.delete(bridgeConfigsBucketName, taskID)
+}
+
+// GetBridgeConfigs returns all the connections of tasks to the task bridge in the bridge configs log.
+func (c *walClient) GetBridgeConfigs() ([]*tasknetworkconfig.BridgeConfig, error) {
+	var bridgeConfigs []*tasknetworkconfig.BridgeConfig
+	err := c.walk(bridgeConfigsBucketName, func(id string, data []byte) error {
+		bridgeConfig := tasknetworkconfig.BridgeConfig{}
+		if err := json.Unmarshal(data, &bridgeConfig); err != nil {
+			return err
+		}
+		bridgeConfigs = append(bridgeConfigs, &bridgeConfig)
+		return nil
+	})
+	return bridgeConfigs, err
+}
+
+// SaveActiveFault saves an active fault to the active faults log.
+func (c *walClient) SaveActiveFault(fault *faulttypes.ActiveFault) error {
+	if fault.TaskARN == "" {
+		return errors.New("failed to generate database id for active fault without task arn")
+	}
+	return c.put(activeFaultsBucketName, fault.Key(), fault)
+}
+
+// DeleteActiveFault deletes an active fault from the active faults log.
+func (c *walClient) DeleteActiveFault(key string) error {
+	return c.delete(activeFaultsBucketName, key)
+}
+
+// GetActiveFaults returns all the active faults in the active faults log.
+func (c *walClient) GetActiveFaults() ([]*faulttypes.ActiveFault, error) {
+	var faults []*faulttypes.ActiveFault
+	err := c.walk(activeFaultsBucketName, func(id string, data []byte) error {
+		fault := faulttypes.ActiveFault{}
+		if err := json.Unmarshal(data, &fault); err != nil {
+			return err
+		}
+		faults = append(faults, &fault)
+		return nil
+	})
+	return faults, err
+}
+
+// SaveMetadata saves a key value pair of metadata to the metadata log.
+func (c *walClient) SaveMetadata(key, val string) error {
+	return c.put(metadataBucketName, key, val)
+}
+
+// GetMetadata returns the value of a key in the metadata log.
+func (c *walClient) GetMetadata(key string) (string, error) {
+	var val string
+	err := c.get(metadataBucketName, key, &val)
+	return val, err
+}
+
+// Close closes the write-ahead log.
+func (c *walClient) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,185 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWALClient(t *testing.T, dataDir string) Client {
+	testClient, err := NewWAL(dataDir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, testClient.Close())
+	})
+	return testClient
+}
+
+func TestWALManageTask(t *testing.T) {
+	testClient := newTestWALClient(t, t.TempDir())
+
+	require.NoError(t, testClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	res, err := testClient.GetTasks()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, testTaskArn, res[0].Arn)
+
+	require.NoError(t, testClient.DeleteTask("abc"))
+	res, err = testClient.GetTasks()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+
+	assert.Error(t, testClient.SaveTask(&apitask.Task{Arn: "invalid-arn"}))
+}
+
+func TestWALManageContainers(t *testing.T) {
+	testClient := newTestWALClient(t, t.TempDir())
+
+	testDockerContainer := &apicontainer.DockerContainer{
+		DockerID:   testDockerID,
+		DockerName: testDockerName,
+		Container: &apicontainer.Container{
+			Name:          testContainerName,
+			TaskARNUnsafe: testTaskArn,
+		},
+	}
+	require.NoError(t, testClient.SaveDockerContainer(testDockerContainer))
+	testDockerContainer.Container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	require.NoError(t, testClient.SaveContainer(testDockerContainer.Container))
+	res, err := testClient.GetContainers()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, apicontainerstatus.ContainerRunning, res[0].Container.GetKnownStatus())
+	assert.Equal(t, testDockerID, res[0].DockerID)
+
+	require.NoError(t, testClient.DeleteContainer("abc-test-name"))
+	res, err = testClient.GetContainers()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
+
+func TestWALManageMetadata(t *testing.T) {
+	testClient := newTestWALClient(t, t.TempDir())
+
+	_, err := testClient.GetMetadata(AgentVersionKey)
+	assert.Error(t, err)
+	require.NoError(t, testClient.SaveMetadata(AgentVersionKey, "1.0.0"))
+	val, err := testClient.GetMetadata(AgentVersionKey)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", val)
+}
+
+func TestWALReplay(t *testing.T) {
+	dataDir := t.TempDir()
+	testClient, err := NewWAL(dataDir)
+	require.NoError(t, err)
+	require.NoError(t, testClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	require.NoError(t, testClient.SaveImageState(&image.ImageState{Image: &image.Image{ImageID: "sha256:1"}}))
+	require.NoError(t, testClient.SaveImageState(&image.ImageState{Image: &image.Image{ImageID: "sha256:2"}}))
+	require.NoError(t, testClient.DeleteImageState("sha256:1"))
+	require.NoError(t, testClient.Close())
+
+	// Simulate a record that was being written when the agent stopped.
+	f, err := os.OpenFile(filepath.Join(dataDir, walName), os.O_APPEND|os.O_WRONLY, dbMode)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"put","bucket":"tasks","key":"def","val`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	testClient = newTestWALClient(t, dataDir)
+	tasks, err := testClient.GetTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, testTaskArn, tasks[0].Arn)
+	images, err := testClient.GetImageStates()
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "sha256:2", images[0].GetImageID())
+
+	// The log is compacted when it is opened, only the live records are left.
+	contents, err := os.ReadFile(filepath.Join(dataDir, walName))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(contents), "\n"))
+}
+
+func TestWALCorruptRecord(t *testing.T) {
+	dataDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, walName),
+		[]byte("not a record\n{\"op\":\"put\",\"bucket\":\"metadata\",\"key\":\"k\",\"value\":\"v\"}\n"), dbMode))
+	_, err := NewWAL(dataDir)
+	assert.Error(t, err)
+}
+
+func TestWALCompaction(t *testing.T) {
+	dataDir := t.TempDir()
+	testClient := newTestWALClient(t, dataDir)
+	for i := 0; i <= walCompactionThreshold; i++ {
+		require.NoError(t, testClient.SaveMetadata(AgentVersionKey, "1.0.0"))
+	}
+	contents, err := os.ReadFile(filepath.Join(dataDir, walName))
+	require.NoError(t, err)
+	assert.Less(t, strings.Count(string(contents), "\n"), walCompactionThreshold)
+	val, err := testClient.GetMetadata(AgentVersionKey)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", val)
+}
+
+func TestNewWithBackend(t *testing.T) {
+	testClient, err := NewWithBackend(WALBackend, t.TempDir())
+	require.NoError(t, err)
+	assert.IsType(t, &walClient{}, testClient)
+	require.NoError(t, testClient.Close())
+
+	_, err = NewWithBackend("sqlite", t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestNewWithBackendMigratesState(t *testing.T) {
+	dataDir := t.TempDir()
+	boltClient, err := NewWithSetup(dataDir)
+	require.NoError(t, err)
+	require.NoError(t, boltClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	require.NoError(t, boltClient.Close())
+
+	testClient, err := NewWithBackend(WALBackend, dataDir)
+	require.NoError(t, err)
+	res, err := testClient.GetTasks()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, testTaskArn, res[0].Arn)
+	require.NoError(t, testClient.DeleteTask("abc"))
+	require.NoError(t, testClient.Close())
+
+	// The migrated store is kept aside and isn't migrated again.
+	assert.NoFileExists(t, filepath.Join(dataDir, dbName))
+	assert.FileExists(t, filepath.Join(dataDir, dbName+migratedSuffix))
+	testClient = newTestWALClient(t, dataDir)
+	res, err = testClient.GetTasks()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
@@ -0,0 +1,302 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/doctor/statustracker"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CustomHealthcheckTypePrefix prefixes the reported type of custom healthchecks, which
+	// is followed by the name of the healthcheck
+	CustomHealthcheckTypePrefix = "Custom:"
+
+	// CustomHealthcheckTypeExec runs a command that must exit with a zero status
+	CustomHealthcheckTypeExec = "exec"
+	// CustomHealthcheckTypeHTTP sends a GET request that must get a 2xx or 3xx response
+	CustomHealthcheckTypeHTTP = "http"
+	// CustomHealthcheckTypeFile checks that a path exists
+	CustomHealthcheckTypeFile = "file"
+
+	// DefaultCustomHealthcheckTimeout is the timeout of custom healthchecks that don't
+	// specify one
+	DefaultCustomHealthcheckTimeout = 5 * time.Second
+	// maxCustomHealthcheckTimeout bounds the timeout of custom healthchecks, which are run
+	// one after the other by the doctor
+	maxCustomHealthcheckTimeout = 30 * time.Second
+	// customHealthcheckHistorySize is the number of results kept by custom healthchecks
+	customHealthcheckHistorySize = 10
+	// maxCustomHealthcheckMessageSize bounds the size of the messages of the results
+	maxCustomHealthcheckMessageSize = 256
+
+	// execProbeWaitDelay bounds the wait for the output of exec healthchecks once killed
+	execProbeWaitDelay = time.Second
+
+	customHealthcheckFileExt = ".json"
+)
+
+// CustomHealthcheckConfig is the definition of an operator-defined healthcheck, read from a
+// JSON file of the custom healthchecks directory.
+type CustomHealthcheckConfig struct {
+	// Name identifies the healthcheck, it's reported as part of the healthcheck type
+	Name string `json:"Name"`
+	// Type is one of "exec", "http" or "file"
+	Type string `json:"Type"`
+	// Command is the command run by exec healthchecks, as a list of arguments
+	Command []string `json:"Command,omitempty"`
+	// URL is the URL requested by http healthchecks
+	URL string `json:"URL,omitempty"`
+	// Path is the path checked by file healthchecks
+	Path string `json:"Path,omitempty"`
+	// Timeout is the duration after which the healthcheck fails, such as "10s"
+	Timeout string `json:"Timeout,omitempty"`
+}
+
+// HealthcheckResult is the result of a run of a healthcheck
+type HealthcheckResult struct {
+	Status  doctor.HealthcheckStatus
+	Time    time.Time
+	Message string
+}
+
+// HealthcheckWithHistory is a healthcheck that keeps the results of its last runs
+type HealthcheckWithHistory interface {
+	doctor.Healthcheck
+	GetHealthcheckHistory() []HealthcheckResult
+}
+
+type customHealthcheck struct {
+	name    string
+	timeout time.Duration
+	probe   func(ctx context.Context) error
+
+	history     []HealthcheckResult
+	historyLock sync.RWMutex
+	*statustracker.HealthCheckStatusTracker
+}
+
+// NewCustomHealthcheck returns the healthcheck of a custom healthcheck definition
+func NewCustomHealthcheck(cfg CustomHealthcheckConfig) (HealthcheckWithHistory, error) {
+	if strings.TrimSpace(cfg.Name) == "" {
+		return nil, errors.New("missing healthcheck name")
+	}
+	timeout := DefaultCustomHealthcheckTimeout
+	if cfg.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil || timeout <= 0 || timeout > maxCustomHealthcheckTimeout {
+			return nil, errors.Errorf("invalid timeout %q, expected a duration greater than 0 and at most %s",
+				cfg.Timeout, maxCustomHealthcheckTimeout)
+		}
+	}
+
+	hc := &customHealthcheck{
+		name:                     cfg.Name,
+		timeout:                  timeout,
+		HealthCheckStatusTracker: statustracker.NewHealthCheckStatusTracker(),
+	}
+	switch cfg.Type {
+	case CustomHealthcheckTypeExec:
+		if len(cfg.Command) == 0 {
+			return nil, errors.New("missing command of exec healthcheck")
+		}
+		hc.probe = execProbe(cfg.Command)
+	case CustomHealthcheckTypeHTTP:
+		if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+			return nil, errors.Errorf("invalid URL %q of http healthcheck", cfg.URL)
+		}
+		hc.probe = httpProbe(cfg.URL)
+	case CustomHealthcheckTypeFile:
+		if !filepath.IsAbs(cfg.Path) {
+			return nil, errors.Errorf("invalid path %q of file healthcheck, expected an absolute path", cfg.Path)
+		}
+		hc.probe = fileProbe(cfg.Path)
+	default:
+		return nil, errors.Errorf("invalid healthcheck type %q", cfg.Type)
+	}
+	return hc, nil
+}
+
+// RunCheck runs the probe of the healthcheck, which is impaired if the probe fails or
+// doesn't complete within the timeout of the healthcheck.
+func (c *customHealthcheck) RunCheck() doctor.HealthcheckStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	status := doctor.HealthcheckStatusOk
+	message := ""
+	if err := c.probe(ctx); err != nil {
+		if ctx.Err() != nil {
+			err = errors.Errorf("timed out after %s", c.timeout)
+		}
+		logger.Warn("Custom health check failed", logger.Fields{
+			"healthcheck": c.name,
+			field.Error:   err,
+		})
+		status = doctor.HealthcheckStatusImpaired
+		message = err.Error()
+		if len(message) > maxCustomHealthcheckMessageSize {
+			message = message[:maxCustomHealthcheckMessageSize]
+		}
+	}
+	c.SetHealthcheckStatus(status)
+	c.recordResult(HealthcheckResult{
+		Status:  status,
+		Time:    c.GetHealthcheckTime(),
+		Message: message,
+	})
+	return status
+}
+
+func (c *customHealthcheck) GetHealthcheckType() string {
+	return CustomHealthcheckTypePrefix + c.name
+}
+
+// GetHealthcheckHistory returns the results of the last runs of the healthcheck, oldest first
+func (c *customHealthcheck) GetHealthcheckHistory() []HealthcheckResult {
+	c.historyLock.RLock()
+	defer c.historyLock.RUnlock()
+	history := make([]HealthcheckResult, len(c.history))
+	copy(history, c.history)
+	return history
+}
+
+func (c *customHealthcheck) recordResult(result HealthcheckResult) {
+	c.historyLock.Lock()
+	defer c.historyLock.Unlock()
+	c.history = append(c.history, result)
+	if len(c.history) > customHealthcheckHistorySize {
+		c.history = c.history[len(c.history)-customHealthcheckHistorySize:]
+	}
+}
+
+func execProbe(command []string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		// Don't wait for the output of the children of a killed command beyond the delay
+		cmd.WaitDelay = execProbeWaitDelay
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			if out := strings.TrimSpace(string(output)); out != "" {
+				return errors.Wrap(err, out)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+func httpProbe(url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+			return errors.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// fileProbe checks the existence of a path. The check runs in its own goroutine as stat may
+// hang on an unresponsive network file system.
+func fileProbe(path string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		result := make(chan error, 1)
+		go func() {
+			_, err := os.Stat(path)
+			result <- err
+		}()
+		select {
+		case err := <-result:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// LoadCustomHealthchecks returns the custom healthchecks defined by the JSON files of a
+// directory, in the order of their file names. Invalid definitions are skipped and returned
+// as errors along with the valid healthchecks.
+func LoadCustomHealthchecks(dir string) ([]doctor.Healthcheck, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read custom healthchecks directory %s", dir)
+	}
+	var fileNames []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == customHealthcheckFileExt {
+			fileNames = append(fileNames, entry.Name())
+		}
+	}
+	sort.Strings(fileNames)
+
+	var healthchecks []doctor.Healthcheck
+	var errs []error
+	names := make(map[string]bool)
+	for _, fileName := range fileNames {
+		hc, err := loadCustomHealthcheck(filepath.Join(dir, fileName))
+		if err == nil && names[hc.GetHealthcheckType()] {
+			err = fmt.Errorf("duplicate healthcheck %s", hc.GetHealthcheckType())
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid custom healthcheck %s", fileName))
+			continue
+		}
+		names[hc.GetHealthcheckType()] = true
+		healthchecks = append(healthchecks, hc)
+	}
+	if len(errs) > 0 {
+		return healthchecks, errors.Errorf("%d invalid custom healthchecks: %v", len(errs), errs)
+	}
+	return healthchecks, nil
+}
+
+func loadCustomHealthcheck(path string) (doctor.Healthcheck, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg CustomHealthcheckConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return NewCustomHealthcheck(cfg)
+}
@@ -0,0 +1,157 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomHealthcheckExec(t *testing.T) {
+	hc, err := NewCustomHealthcheck(CustomHealthcheckConfig{
+		Name:    "exec",
+		Type:    CustomHealthcheckTypeExec,
+		Command: []string{"sh", "-c", "test -e \"$0\" || (echo missing; exit 1)", t.TempDir()},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Custom:exec", hc.GetHealthcheckType())
+	assert.Equal(t, doctor.HealthcheckStatusInitializing, hc.GetHealthcheckStatus())
+	assert.Equal(t, doctor.HealthcheckStatusOk, hc.RunCheck())
+
+	hc, err = NewCustomHealthcheck(CustomHealthcheckConfig{
+		Name:    "exec",
+		Type:    CustomHealthcheckTypeExec,
+		Command: []string{"sh", "-c", "echo missing; exit 1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, doctor.HealthcheckStatusImpaired, hc.RunCheck())
+	history := hc.GetHealthcheckHistory()
+	require.Len(t, history, 1)
+	assert.Equal(t, doctor.HealthcheckStatusImpaired, history[0].Status)
+	assert.Contains(t, history[0].Message, "missing")
+}
+
+func TestCustomHealthcheckExecTimeout(t *testing.T) {
+	hc, err := NewCustomHealthcheck(CustomHealthcheckConfig{
+		Name:    "hung",
+		Type:    CustomHealthcheckTypeExec,
+		Command: []string{"sleep", "10"},
+		Timeout: "100ms",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, doctor.HealthcheckStatusImpaired, hc.RunCheck())
+	assert.Equal(t, "timed out after 100ms", hc.GetHealthcheckHistory()[0].Message)
+}
+
+func TestCustomHealthcheckHTTP(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	hc, err := NewCustomHealthcheck(CustomHealthcheckConfig{
+		Name: "http",
+		Type: CustomHealthcheckTypeHTTP,
+		URL:  server.URL,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, doctor.HealthcheckStatusOk, hc.RunCheck())
+
+	status = http.StatusServiceUnavailable
+	assert.Equal(t, doctor.HealthcheckStatusImpaired, hc.RunCheck())
+	assert.Equal(t, doctor.HealthcheckStatusOk, hc.GetLastHealthcheckStatus())
+	assert.Equal(t, "unexpected status code 503", hc.GetHealthcheckHistory()[1].Message)
+}
+
+func TestCustomHealthcheckFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mounted")
+	hc, err := NewCustomHealthcheck(CustomHealthcheckConfig{
+		Name: "file",
+		Type: CustomHealthcheckTypeFile,
+		Path: path,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, doctor.HealthcheckStatusImpaired, hc.RunCheck())
+
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	assert.Equal(t, doctor.HealthcheckStatusOk, hc.RunCheck())
+}
+
+func TestCustomHealthcheckHistoryIsBounded(t *testing.T) {
+	hc, err := NewCustomHealthcheck(CustomHealthcheckConfig{
+		Name: "file",
+		Type: CustomHealthcheckTypeFile,
+		Path: t.TempDir(),
+	})
+	require.NoError(t, err)
+	for i := 0; i < customHealthcheckHistorySize+5; i++ {
+		hc.RunCheck()
+	}
+	assert.Len(t, hc.GetHealthcheckHistory(), customHealthcheckHistorySize)
+}
+
+func TestNewCustomHealthcheckInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  CustomHealthcheckConfig
+	}{
+		{"missing name", CustomHealthcheckConfig{Type: CustomHealthcheckTypeFile, Path: "/tmp"}},
+		{"invalid type", CustomHealthcheckConfig{Name: "a", Type: "tcp"}},
+		{"exec without command", CustomHealthcheckConfig{Name: "a", Type: CustomHealthcheckTypeExec}},
+		{"http without scheme", CustomHealthcheckConfig{Name: "a", Type: CustomHealthcheckTypeHTTP, URL: "localhost"}},
+		{"file with relative path", CustomHealthcheckConfig{Name: "a", Type: CustomHealthcheckTypeFile, Path: "tmp"}},
+		{"invalid timeout", CustomHealthcheckConfig{Name: "a", Type: CustomHealthcheckTypeFile, Path: "/tmp", Timeout: "5"}},
+		{"timeout too long", CustomHealthcheckConfig{Name: "a", Type: CustomHealthcheckTypeFile, Path: "/tmp", Timeout: "1h"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewCustomHealthcheck(tc.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadCustomHealthchecks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-mount.json":     `{"Name":"mount","Type":"file","Path":"/var/lib/data"}`,
+		"20-logging.json":   `{"Name":"logging","Type":"http","URL":"http://localhost:24220/","Timeout":"2s"}`,
+		"30-invalid.json":   `{"Name":"invalid","Type":"tcp"}`,
+		"40-unknown.json":   `{"Name":"unknown","Type":"file","Path":"/tmp","Interval":"1s"}`,
+		"50-duplicate.json": `{"Name":"mount","Type":"file","Path":"/var/lib/other"}`,
+		"README":            `not a healthcheck`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	healthchecks, err := LoadCustomHealthchecks(dir)
+	assert.Error(t, err)
+	require.Len(t, healthchecks, 2)
+	assert.Equal(t, "Custom:mount", healthchecks[0].GetHealthcheckType())
+	assert.Equal(t, "Custom:logging", healthchecks[1].GetHealthcheckType())
+
+	_, err = LoadCustomHealthchecks(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
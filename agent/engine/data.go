@@ -14,6 +14,8 @@
 package engine
 
 import (
+	"sync"
+
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/data"
@@ -21,6 +23,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 
 	"github.com/cihub/seelog"
 	"github.com/pkg/errors"
@@ -44,7 +47,11 @@ func (engine *DockerTaskEngine) LoadState() error {
 		return err
 	}
 
-	return engine.loadENIAttachments()
+	if err := engine.loadENIAttachments(); err != nil {
+		return err
+	}
+
+	return engine.loadBridgeConfigs()
 }
 
 func (engine *DockerTaskEngine) loadTasks() error {
@@ -152,6 +159,18 @@ func (engine *DockerTaskEngine) loadENIAttachments() error {
 	return nil
 }
 
+func (engine *DockerTaskEngine) loadBridgeConfigs() error {
+	bridgeConfigs, err := engine.dataClient.GetBridgeConfigs()
+	if err != nil {
+		return err
+	}
+
+	for _, bridgeConfig := range bridgeConfigs {
+		engine.taskBridgeData.addBridgeConfig(bridgeConfig)
+	}
+	return nil
+}
+
 // SaveState saves all the data in task engine state to db.
 func (engine *DockerTaskEngine) SaveState() error {
 	state := engine.state
@@ -281,3 +300,81 @@ func (imageManager *dockerImageManager) removeImageStateData(imageId string) {
 		seelog.Errorf("Failed to remove data for image state %s:, %v", imageId, err)
 	}
 }
+
+// taskBridgeDataClient is the data client of the network builder that connects tasks in bridge
+// network mode to the task bridge. It keeps the bridge configs of the tasks in memory, and saves
+// them in db so that their port forwarding can be removed after the agent restarts.
+type taskBridgeDataClient struct {
+	engine        *DockerTaskEngine
+	lock          sync.RWMutex
+	bridgeConfigs map[string]*tasknetworkconfig.BridgeConfig
+}
+
+func newTaskBridgeDataClient(engine *DockerTaskEngine) *taskBridgeDataClient {
+	return &taskBridgeDataClient{
+		engine:        engine,
+		bridgeConfigs: make(map[string]*tasknetworkconfig.BridgeConfig),
+	}
+}
+
+func (client *taskBridgeDataClient) addBridgeConfig(bridgeConfig *tasknetworkconfig.BridgeConfig) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	client.bridgeConfigs[bridgeConfig.TaskID] = bridgeConfig
+}
+
+// SaveBridgeConfig keeps the bridge config of a task, and saves it in db.
+func (client *taskBridgeDataClient) SaveBridgeConfig(bridgeConfig *tasknetworkconfig.BridgeConfig) error {
+	client.addBridgeConfig(bridgeConfig)
+	return client.engine.dataClient.SaveBridgeConfig(bridgeConfig)
+}
+
+// GetBridgeConfig returns the bridge config of a task.
+func (client *taskBridgeDataClient) GetBridgeConfig(taskID string) (*tasknetworkconfig.BridgeConfig, error) {
+	client.lock.RLock()
+	defer client.lock.RUnlock()
+
+	bridgeConfig, ok := client.bridgeConfigs[taskID]
+	if !ok {
+		return nil, errors.Errorf("bridge config not found for task %s", taskID)
+	}
+	return bridgeConfig, nil
+}
+
+// DeleteBridgeConfig removes the bridge config of a task, and deletes it from db.
+func (client *taskBridgeDataClient) DeleteBridgeConfig(taskID string) error {
+	client.lock.Lock()
+	delete(client.bridgeConfigs, taskID)
+	client.lock.Unlock()
+
+	return client.engine.dataClient.DeleteBridgeConfig(taskID)
+}
+
+// GetNetworkNamespacesByTaskID is not supported, the network namespaces of tasks are managed
+// by the task engine.
+func (client *taskBridgeDataClient) GetNetworkNamespacesByTaskID(taskID string) ([]*tasknetworkconfig.NetworkNamespace, error) {
+	return nil, errors.New("network namespaces are not saved by the task engine")
+}
+
+// SaveNetworkNamespace is not supported, the network namespaces of tasks are managed by the
+// task engine.
+func (client *taskBridgeDataClient) SaveNetworkNamespace(netNS *tasknetworkconfig.NetworkNamespace) error {
+	return errors.New("network namespaces are not saved by the task engine")
+}
+
+// GetNetworkNamespace is not supported, the network namespaces of tasks are managed by the
+// task engine.
+func (client *taskBridgeDataClient) GetNetworkNamespace(netNSName string) (*tasknetworkconfig.NetworkNamespace, error) {
+	return nil, errors.New("network namespaces are not saved by the task engine")
+}
+
+// AssignGeneveDstPort is not supported, tasks in bridge network mode have no GENEVE interface.
+func (client *taskBridgeDataClient) AssignGeneveDstPort(vni string) (uint16, error) {
+	return 0, errors.New("GENEVE interfaces are not supported by the task engine")
+}
+
+// ReleaseGeneveDstPort is not supported, tasks in bridge network mode have no GENEVE interface.
+func (client *taskBridgeDataClient) ReleaseGeneveDstPort(port uint16, vni string) error {
+	return errors.New("GENEVE interfaces are not supported by the task engine")
+}
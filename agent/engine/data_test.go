@@ -28,6 +28,7 @@ import (
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -137,6 +138,8 @@ func TestLoadState(t *testing.T) {
 	require.NoError(t, dataClient.SaveDockerContainer(testPulledDockerContainer))
 	require.NoError(t, dataClient.SaveENIAttachment(testENIAttachment))
 	require.NoError(t, dataClient.SaveImageState(testImageState))
+	require.NoError(t, dataClient.SaveBridgeConfig(tasknetworkconfig.NewBridgeConfig("task-id", "ecs-task-bridge", "172.30.0.0/16")))
+	engine.taskBridgeData = newTaskBridgeDataClient(engine)
 
 	require.NoError(t, engine.LoadState())
 	task, ok := engine.state.TaskByArn(testTaskARN)
@@ -151,6 +154,9 @@ func TestLoadState(t *testing.T) {
 	assert.True(t, ok)
 	assert.Len(t, engine.state.AllImageStates(), 1)
 	assert.Len(t, engine.state.AllENIAttachments(), 1)
+	bridgeConfig, err := engine.taskBridgeData.GetBridgeConfig("task-id")
+	require.NoError(t, err)
+	assert.Equal(t, "172.30.0.0/16", bridgeConfig.IPV4Subnet)
 
 	// Check ip <-> task arn mapping is loaded in state.
 	ip, ok := engine.state.GetIPAddressByTaskARN(testTaskARN)
@@ -0,0 +1,274 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dependencygraph
+
+import (
+	"fmt"
+	"sort"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+)
+
+const (
+	// BlockerExecutionCredentials is a container waiting for the credentials of the task
+	// execution role to pull its image
+	BlockerExecutionCredentials = "ExecutionCredentials"
+	// BlockerContainerOrdering is a container waiting for a condition of the "dependsOn"
+	// container ordering of its task definition
+	BlockerContainerOrdering = "ContainerOrdering"
+	// BlockerSteadyStateDependency is a container waiting for a container it's linked to, or
+	// mounts volumes from, to reach its steady state
+	BlockerSteadyStateDependency = "SteadyStateDependency"
+	// BlockerContainerDependency is a container or a resource waiting for a container the
+	// agent set up a transition dependency on to reach a status
+	BlockerContainerDependency = "ContainerDependency"
+	// BlockerResourceDependency is a container waiting for a task resource to reach a status
+	BlockerResourceDependency = "ResourceDependency"
+	// BlockerShutdownOrder is a container waiting for the containers depending on it to stop
+	// before it stops
+	BlockerShutdownOrder = "ShutdownOrder"
+)
+
+// Blocker is a dependency preventing a container or a task resource from transitioning
+// to its next status.
+type Blocker struct {
+	// Type is the type of the dependency, one of the Blocker* constants
+	Type string `json:"Type"`
+	// Name is the name of the container or resource depended on, if any
+	Name string `json:"Name,omitempty"`
+	// Condition is the container ordering condition or the status required from the
+	// container or resource depended on
+	Condition string `json:"Condition,omitempty"`
+	// KnownStatus is the known status of the container or resource depended on
+	KnownStatus string `json:"KnownStatus,omitempty"`
+	// Terminal is true if the dependency can never be resolved
+	Terminal bool `json:"Terminal,omitempty"`
+	// Reason describes why the dependency isn't resolved
+	Reason string `json:"Reason"`
+}
+
+// ExplainContainerDependencies returns all the dependencies preventing the `target`
+// container from transitioning given the current known state of the containers in `by`,
+// following the same rules as DependenciesAreResolved. Unlike DependenciesAreResolved,
+// it doesn't stop at the first unresolved dependency.
+func ExplainContainerDependencies(target *apicontainer.Container,
+	by []*apicontainer.Container,
+	id string,
+	manager credentials.Manager,
+	resources []taskresource.TaskResource,
+	cfg *config.Config) []Blocker {
+	if target.GetKnownStatus() >= target.GetDesiredStatus() {
+		return nil
+	}
+
+	var blockers []Blocker
+	if !executionCredentialsResolved(target, id, manager) {
+		blockers = append(blockers, Blocker{
+			Type:   BlockerExecutionCredentials,
+			Reason: "task execution role credentials are not available yet",
+		})
+	}
+
+	nameMap := make(map[string]*apicontainer.Container)
+	for _, cont := range by {
+		nameMap[cont.Name] = cont
+	}
+	resourcesMap := make(map[string]taskresource.TaskResource)
+	for _, resource := range resources {
+		resourcesMap[resource.GetName()] = resource
+	}
+
+	blockers = append(blockers, explainContainerOrdering(target, nameMap, cfg)...)
+	blockers = append(blockers, explainSteadyStateDependencies(target, nameMap)...)
+	blockers = append(blockers, explainTransitionDependencies(target, nameMap, resourcesMap)...)
+	if target.DesiredTerminal() && !target.KnownTerminal() {
+		blockers = append(blockers, explainShutdownOrder(target, nameMap)...)
+	}
+	return blockers
+}
+
+// ExplainTaskResourceDependencies returns all the dependencies preventing the `target`
+// resource from transitioning given the current known state of the containers in `by`.
+func ExplainTaskResourceDependencies(target taskresource.TaskResource, by []*apicontainer.Container) []Blocker {
+	if target.GetKnownStatus() >= target.GetDesiredStatus() {
+		return nil
+	}
+
+	nameMap := make(map[string]*apicontainer.Container)
+	for _, cont := range by {
+		nameMap[cont.Name] = cont
+	}
+
+	var blockers []Blocker
+	for _, containerDependency := range target.GetContainerDependencies(target.NextKnownState()) {
+		if blocker, blocked := explainContainerDependency(containerDependency, nameMap); blocked {
+			blockers = append(blockers, blocker)
+		}
+	}
+	return blockers
+}
+
+func explainContainerOrdering(target *apicontainer.Container, existingContainers map[string]*apicontainer.Container,
+	cfg *config.Config) []Blocker {
+	targetGoal := target.GetDesiredStatus()
+	if targetGoal != target.GetSteadyStateStatus() && targetGoal != apicontainerstatus.ContainerCreated {
+		return nil
+	}
+
+	var blockers []Blocker
+	for _, dependency := range target.GetDependsOn() {
+		resolved, err := verifyContainerOrderingDependencyResolvable(target, dependency, existingContainers, cfg,
+			containerOrderingDependenciesIsResolved)
+		if resolved {
+			continue
+		}
+		blocker := Blocker{
+			Type:      BlockerContainerOrdering,
+			Name:      dependency.ContainerName,
+			Condition: dependency.Condition,
+			Reason:    fmt.Sprintf("waiting for container %s to satisfy condition %s", dependency.ContainerName, dependency.Condition),
+		}
+		if dependencyContainer, ok := existingContainers[dependency.ContainerName]; ok {
+			blocker.KnownStatus = dependencyContainer.GetKnownStatus().String()
+		}
+		if err != nil {
+			blocker.Terminal = err.IsTerminal()
+			blocker.Reason = err.Error()
+		}
+		blockers = append(blockers, blocker)
+	}
+	return blockers
+}
+
+func explainSteadyStateDependencies(target *apicontainer.Container,
+	existingContainers map[string]*apicontainer.Container) []Blocker {
+	targetGoal := target.GetDesiredStatus()
+	if targetGoal != target.GetSteadyStateStatus() && targetGoal != apicontainerstatus.ContainerCreated {
+		return nil
+	}
+
+	var blockers []Blocker
+	for _, dependency := range target.SteadyStateDependencies {
+		dependencyContainer, exists := existingContainers[dependency]
+		if !exists {
+			blockers = append(blockers, Blocker{
+				Type:     BlockerSteadyStateDependency,
+				Name:     dependency,
+				Terminal: true,
+				Reason:   fmt.Sprintf("container %s does not exist", dependency),
+			})
+			continue
+		}
+		if !onSteadyStateIsResolved(target, dependencyContainer) {
+			blockers = append(blockers, Blocker{
+				Type:        BlockerSteadyStateDependency,
+				Name:        dependency,
+				Condition:   dependencyContainer.GetSteadyStateStatus().String(),
+				KnownStatus: dependencyContainer.GetKnownStatus().String(),
+				Reason:      fmt.Sprintf("waiting for container %s to reach its steady state", dependency),
+			})
+		}
+	}
+	return blockers
+}
+
+func explainTransitionDependencies(target *apicontainer.Container,
+	existingContainers map[string]*apicontainer.Container,
+	existingResources map[string]taskresource.TaskResource) []Blocker {
+	targetNext := target.GetNextKnownStateProgression()
+	transitionDependencies := target.TransitionDependenciesMap[targetNext]
+
+	var blockers []Blocker
+	for _, containerDependency := range transitionDependencies.ContainerDependencies {
+		if blocker, blocked := explainContainerDependency(containerDependency, existingContainers); blocked {
+			blockers = append(blockers, blocker)
+		}
+	}
+	for _, resourceDependency := range transitionDependencies.ResourceDependencies {
+		dep, exists := existingResources[resourceDependency.Name]
+		if !exists {
+			blockers = append(blockers, Blocker{
+				Type:     BlockerResourceDependency,
+				Name:     resourceDependency.Name,
+				Terminal: true,
+				Reason:   fmt.Sprintf("resource %s does not exist", resourceDependency.Name),
+			})
+			continue
+		}
+		requiredStatus := resourceDependency.GetRequiredStatus()
+		if dep.GetKnownStatus() < requiredStatus {
+			blockers = append(blockers, Blocker{
+				Type:        BlockerResourceDependency,
+				Name:        resourceDependency.Name,
+				Condition:   dep.StatusString(requiredStatus),
+				KnownStatus: dep.StatusString(dep.GetKnownStatus()),
+				Reason: fmt.Sprintf("waiting for resource %s to reach status %s", resourceDependency.Name,
+					dep.StatusString(requiredStatus)),
+			})
+		}
+	}
+	return blockers
+}
+
+func explainContainerDependency(containerDependency apicontainer.ContainerDependency,
+	existingContainers map[string]*apicontainer.Container) (Blocker, bool) {
+	dep, exists := existingContainers[containerDependency.ContainerName]
+	if !exists {
+		return Blocker{
+			Type:     BlockerContainerDependency,
+			Name:     containerDependency.ContainerName,
+			Terminal: true,
+			Reason:   fmt.Sprintf("container %s does not exist", containerDependency.ContainerName),
+		}, true
+	}
+	if dep.GetKnownStatus() >= containerDependency.SatisfiedStatus {
+		return Blocker{}, false
+	}
+	return Blocker{
+		Type:        BlockerContainerDependency,
+		Name:        containerDependency.ContainerName,
+		Condition:   containerDependency.SatisfiedStatus.String(),
+		KnownStatus: dep.GetKnownStatus().String(),
+		Reason: fmt.Sprintf("waiting for container %s to reach status %s", containerDependency.ContainerName,
+			containerDependency.SatisfiedStatus.String()),
+	}, true
+}
+
+func explainShutdownOrder(target *apicontainer.Container, existingContainers map[string]*apicontainer.Container) []Blocker {
+	var blockers []Blocker
+	for _, existingContainer := range existingContainers {
+		if existingContainer.KnownTerminal() {
+			continue
+		}
+		for _, dependency := range existingContainer.GetDependsOn() {
+			if dependency.ContainerName == target.Name {
+				blockers = append(blockers, Blocker{
+					Type:        BlockerShutdownOrder,
+					Name:        existingContainer.Name,
+					Condition:   apicontainerstatus.ContainerStopped.String(),
+					KnownStatus: existingContainer.GetKnownStatus().String(),
+					Reason:      fmt.Sprintf("waiting for container %s, which depends on it, to stop", existingContainer.Name),
+				})
+				break
+			}
+		}
+	}
+	// existingContainers is a map, sort the blockers for a stable output
+	sort.Slice(blockers, func(i, j int) bool { return blockers[i].Name < blockers[j].Name })
+	return blockers
+}
@@ -0,0 +1,188 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dependencygraph
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	mock_taskresource "github.com/aws/amazon-ecs-agent/agent/taskresource/mocks"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainContainerDependenciesResolved(t *testing.T) {
+	dependency := steadyStateContainer("init", nil, apicontainerstatus.ContainerStopped, apicontainerstatus.ContainerRunning)
+	dependency.KnownStatusUnsafe = apicontainerstatus.ContainerRunning
+	target := steadyStateContainer("app", []apicontainer.DependsOn{{ContainerName: "init", Condition: startCondition}},
+		apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+
+	blockers := ExplainContainerDependencies(target, []*apicontainer.Container{dependency, target}, "", nil, nil,
+		&config.Config{})
+	assert.Empty(t, blockers)
+}
+
+func TestExplainContainerDependenciesContainerOrdering(t *testing.T) {
+	dependency := steadyStateContainer("init", nil, apicontainerstatus.ContainerStopped, apicontainerstatus.ContainerRunning)
+	dependency.KnownStatusUnsafe = apicontainerstatus.ContainerRunning
+	target := steadyStateContainer("app", []apicontainer.DependsOn{
+		{ContainerName: "init", Condition: successCondition},
+		{ContainerName: "missing", Condition: startCondition},
+	}, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	// containers can always be pulled regardless of their container ordering
+	target.KnownStatusUnsafe = apicontainerstatus.ContainerPulled
+
+	blockers := ExplainContainerDependencies(target, []*apicontainer.Container{dependency, target}, "", nil, nil,
+		&config.Config{})
+	require.Len(t, blockers, 2)
+	assert.Equal(t, Blocker{
+		Type:        BlockerContainerOrdering,
+		Name:        "init",
+		Condition:   successCondition,
+		KnownStatus: apicontainerstatus.ContainerRunning.String(),
+		Reason:      "waiting for container init to satisfy condition SUCCESS",
+	}, blockers[0])
+	assert.Equal(t, BlockerContainerOrdering, blockers[1].Type)
+	assert.Equal(t, "missing", blockers[1].Name)
+	assert.True(t, blockers[1].Terminal)
+	assert.Empty(t, blockers[1].KnownStatus)
+}
+
+func TestExplainContainerDependenciesExecutionCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	credentialsManager.EXPECT().GetTaskCredentials("id").Return(credentials.TaskIAMRoleCredentials{}, false)
+
+	target := steadyStateContainer("app", nil, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	target.RegistryAuthentication = &apicontainer.RegistryAuthenticationData{
+		Type:        apicontainer.AuthTypeECR,
+		ECRAuthData: &apicontainer.ECRAuthData{UseExecutionRole: true},
+	}
+
+	blockers := ExplainContainerDependencies(target, []*apicontainer.Container{target}, "id", credentialsManager,
+		nil, &config.Config{})
+	require.Len(t, blockers, 1)
+	assert.Equal(t, BlockerExecutionCredentials, blockers[0].Type)
+}
+
+func TestExplainContainerDependenciesSteadyState(t *testing.T) {
+	dependency := steadyStateContainer("volumes", nil, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	dependency.KnownStatusUnsafe = apicontainerstatus.ContainerCreated
+	target := steadyStateContainer("app", nil, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	target.SteadyStateDependencies = []string{"volumes"}
+
+	blockers := ExplainContainerDependencies(target, []*apicontainer.Container{dependency, target}, "", nil, nil,
+		&config.Config{})
+	assert.Equal(t, []Blocker{{
+		Type:        BlockerSteadyStateDependency,
+		Name:        "volumes",
+		Condition:   apicontainerstatus.ContainerRunning.String(),
+		KnownStatus: apicontainerstatus.ContainerCreated.String(),
+		Reason:      "waiting for container volumes to reach its steady state",
+	}}, blockers)
+}
+
+func TestExplainContainerDependenciesTransitionDependencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockResource := mock_taskresource.NewMockTaskResource(ctrl)
+	mockResource.EXPECT().GetName().Return("resource").AnyTimes()
+	mockResource.EXPECT().GetKnownStatus().Return(resourcestatus.ResourceStatus(0)).AnyTimes()
+	mockResource.EXPECT().StatusString(resourcestatus.ResourceStatus(0)).Return("NONE").AnyTimes()
+	mockResource.EXPECT().StatusString(resourcestatus.ResourceStatus(1)).Return("CREATED").AnyTimes()
+
+	pause := steadyStateContainer("pause", nil, apicontainerstatus.ContainerResourcesProvisioned,
+		apicontainerstatus.ContainerResourcesProvisioned)
+	target := steadyStateContainer("app", nil, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	target.TransitionDependenciesMap = make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet)
+	target.BuildContainerDependency("pause", apicontainerstatus.ContainerResourcesProvisioned,
+		apicontainerstatus.ContainerManifestPulled)
+	target.BuildResourceDependency("resource", resourcestatus.ResourceStatus(1), apicontainerstatus.ContainerManifestPulled)
+
+	blockers := ExplainContainerDependencies(target, []*apicontainer.Container{pause, target}, "", nil,
+		[]taskresource.TaskResource{mockResource}, &config.Config{})
+	assert.Equal(t, []Blocker{
+		{
+			Type:        BlockerContainerDependency,
+			Name:        "pause",
+			Condition:   apicontainerstatus.ContainerResourcesProvisioned.String(),
+			KnownStatus: apicontainerstatus.ContainerStatusNone.String(),
+			Reason:      "waiting for container pause to reach status RESOURCES_PROVISIONED",
+		},
+		{
+			Type:        BlockerResourceDependency,
+			Name:        "resource",
+			Condition:   "CREATED",
+			KnownStatus: "NONE",
+			Reason:      "waiting for resource resource to reach status CREATED",
+		},
+	}, blockers)
+}
+
+func TestExplainContainerDependenciesShutdownOrder(t *testing.T) {
+	target := steadyStateContainer("db", nil, apicontainerstatus.ContainerStopped, apicontainerstatus.ContainerRunning)
+	target.KnownStatusUnsafe = apicontainerstatus.ContainerRunning
+	app := steadyStateContainer("app", []apicontainer.DependsOn{{ContainerName: "db", Condition: startCondition}},
+		apicontainerstatus.ContainerStopped, apicontainerstatus.ContainerRunning)
+	app.KnownStatusUnsafe = apicontainerstatus.ContainerRunning
+	stopped := steadyStateContainer("worker", []apicontainer.DependsOn{{ContainerName: "db", Condition: startCondition}},
+		apicontainerstatus.ContainerStopped, apicontainerstatus.ContainerRunning)
+	stopped.KnownStatusUnsafe = apicontainerstatus.ContainerStopped
+
+	blockers := ExplainContainerDependencies(target, []*apicontainer.Container{target, app, stopped}, "", nil, nil,
+		&config.Config{})
+	assert.Equal(t, []Blocker{{
+		Type:        BlockerShutdownOrder,
+		Name:        "app",
+		Condition:   apicontainerstatus.ContainerStopped.String(),
+		KnownStatus: apicontainerstatus.ContainerRunning.String(),
+		Reason:      "waiting for container app, which depends on it, to stop",
+	}}, blockers)
+}
+
+func TestExplainTaskResourceDependencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockResource := mock_taskresource.NewMockTaskResource(ctrl)
+	mockResource.EXPECT().GetKnownStatus().Return(resourcestatus.ResourceStatus(0)).AnyTimes()
+	mockResource.EXPECT().GetDesiredStatus().Return(resourcestatus.ResourceStatus(1)).AnyTimes()
+	mockResource.EXPECT().NextKnownState().Return(resourcestatus.ResourceStatus(1))
+	mockResource.EXPECT().GetContainerDependencies(resourcestatus.ResourceStatus(1)).Return(
+		[]apicontainer.ContainerDependency{
+			{ContainerName: "pause", SatisfiedStatus: apicontainerstatus.ContainerResourcesProvisioned},
+			{ContainerName: "missing", SatisfiedStatus: apicontainerstatus.ContainerRunning},
+		})
+
+	pause := steadyStateContainer("pause", nil, apicontainerstatus.ContainerResourcesProvisioned,
+		apicontainerstatus.ContainerResourcesProvisioned)
+	blockers := ExplainTaskResourceDependencies(mockResource, []*apicontainer.Container{pause})
+	require.Len(t, blockers, 2)
+	assert.Equal(t, BlockerContainerDependency, blockers[0].Type)
+	assert.Equal(t, "pause", blockers[0].Name)
+	assert.False(t, blockers[0].Terminal)
+	assert.Equal(t, "missing", blockers[1].Name)
+	assert.True(t, blockers[1].Terminal)
+}
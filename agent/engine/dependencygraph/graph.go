@@ -268,7 +268,6 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 	cfg *config.Config, resolves func(*apicontainer.Container, *apicontainer.Container, string, *config.Config) bool) (*apicontainer.DependsOn, DependencyError) {
 
 	targetGoal := target.GetDesiredStatus()
-	targetKnown := target.GetKnownStatus()
 	if targetGoal != target.GetSteadyStateStatus() && targetGoal != apicontainerstatus.ContainerCreated {
 		// A container can always stop, die, or reach whatever other state it
 		// wants regardless of what dependencies it has
@@ -279,34 +278,11 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 
 	targetDependencies := target.GetDependsOn()
 	for _, dependency := range targetDependencies {
-		dependencyContainer, ok := existingContainers[dependency.ContainerName]
-		if !ok {
-			return nil, &dependencyError{err: fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] does not exist.", dependencyContainer, target), isTerminal: true}
-		}
-
-		// We want to check whether the dependency container has timed out only if target has not been created yet.
-		// If the target is already created, then everything is normal and dependency can be and is resolved.
-		// However, if dependency container has already stopped, then it cannot time out.
-		if targetKnown < apicontainerstatus.ContainerCreated && dependencyContainer.GetKnownStatus() != apicontainerstatus.ContainerStopped {
-			if hasDependencyTimedOut(dependencyContainer, dependency.Condition) {
-				return nil, &dependencyError{err: fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] has timed out.", dependencyContainer, target), isTerminal: true}
-			}
-		}
-
-		// We want to fail fast if the dependency container has stopped but did not exit successfully because target container
-		// can then never progress to its desired state when the dependency condition is 'SUCCESS'
-		if dependency.Condition == successCondition && dependencyContainer.GetKnownStatus() == apicontainerstatus.ContainerStopped &&
-			!hasDependencyStoppedSuccessfully(dependencyContainer) {
-			return nil, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] as dependency did not exit successfully.", dependencyContainer, target), isTerminal: true}
-		}
-
-		// For any of the dependency conditions - START/COMPLETE/SUCCESS/HEALTHY, if the dependency container has
-		// not started and will not start in the future, this dependency can never be resolved.
-		if dependencyContainer.HasNotAndWillNotStart() {
-			return nil, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] because dependency will never start", dependencyContainer, target), isTerminal: true}
+		resolved, err := verifyContainerOrderingDependencyResolvable(target, dependency, existingContainers, cfg, resolves)
+		if err != nil {
+			return nil, err
 		}
-
-		if !resolves(target, dependencyContainer, dependency.Condition, cfg) {
+		if !resolved {
 			blockedDependency = &dependency
 		}
 	}
@@ -316,6 +292,43 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 	return nil, nil
 }
 
+// verifyContainerOrderingDependencyResolvable validates a single container ordering dependency of `target`.
+// It returns false if the dependency isn't resolved yet, and a terminal error if it can never be resolved.
+func verifyContainerOrderingDependencyResolvable(target *apicontainer.Container, dependency apicontainer.DependsOn,
+	existingContainers map[string]*apicontainer.Container, cfg *config.Config,
+	resolves func(*apicontainer.Container, *apicontainer.Container, string, *config.Config) bool) (bool, DependencyError) {
+
+	targetKnown := target.GetKnownStatus()
+	dependencyContainer, ok := existingContainers[dependency.ContainerName]
+	if !ok {
+		return false, &dependencyError{err: fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] does not exist.", dependencyContainer, target), isTerminal: true}
+	}
+
+	// We want to check whether the dependency container has timed out only if target has not been created yet.
+	// If the target is already created, then everything is normal and dependency can be and is resolved.
+	// However, if dependency container has already stopped, then it cannot time out.
+	if targetKnown < apicontainerstatus.ContainerCreated && dependencyContainer.GetKnownStatus() != apicontainerstatus.ContainerStopped {
+		if hasDependencyTimedOut(dependencyContainer, dependency.Condition) {
+			return false, &dependencyError{err: fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] has timed out.", dependencyContainer, target), isTerminal: true}
+		}
+	}
+
+	// We want to fail fast if the dependency container has stopped but did not exit successfully because target container
+	// can then never progress to its desired state when the dependency condition is 'SUCCESS'
+	if dependency.Condition == successCondition && dependencyContainer.GetKnownStatus() == apicontainerstatus.ContainerStopped &&
+		!hasDependencyStoppedSuccessfully(dependencyContainer) {
+		return false, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] as dependency did not exit successfully.", dependencyContainer, target), isTerminal: true}
+	}
+
+	// For any of the dependency conditions - START/COMPLETE/SUCCESS/HEALTHY, if the dependency container has
+	// not started and will not start in the future, this dependency can never be resolved.
+	if dependencyContainer.HasNotAndWillNotStart() {
+		return false, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] because dependency will never start", dependencyContainer, target), isTerminal: true}
+	}
+
+	return resolves(target, dependencyContainer, dependency.Condition, cfg), nil
+}
+
 func verifyTransitionDependenciesResolved(target *apicontainer.Container,
 	existingContainers map[string]*apicontainer.Container,
 	existingResources map[string]taskresource.TaskResource) DependencyError {
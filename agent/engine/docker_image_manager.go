@@ -48,6 +48,8 @@ type ImageManager interface {
 	StartImageCleanupProcess(ctx context.Context)
 	SetDataClient(dataClient data.Client)
 	AddImageToCleanUpExclusionList(image string)
+	RecordImage(imageName string, pullSucceeded bool) (*image.ImageState, error)
+	PinImage(imageName string, until time.Time)
 }
 
 // dockerImageManager accounts all the images and their states in the instance.
@@ -69,6 +71,11 @@ type dockerImageManager struct {
 	nonECSContainerCleanupWaitDuration time.Duration
 	numNonECSContainersToDelete        int
 	nonECSMinimumAgeBeforeDeletion     time.Duration
+	highWatermarkPercent               int
+	lowWatermarkPercent                int
+	diskCheckInterval                  time.Duration
+	diskPath                           string
+	getDiskUsage                       func(path string) (uint64, uint64, error)
 }
 
 // ImageStatesForDeletion is used for implementing the sort interface
@@ -88,6 +95,11 @@ func NewImageManager(cfg *config.Config, client dockerapi.DockerClient, state do
 		nonECSContainerCleanupWaitDuration: cfg.TaskCleanupWaitDuration,
 		numNonECSContainersToDelete:        cfg.NumNonECSContainersToDeletePerCycle,
 		nonECSMinimumAgeBeforeDeletion:     cfg.NonECSMinimumImageDeletionAge,
+		highWatermarkPercent:               cfg.ImageCleanupHighWatermarkPercent,
+		lowWatermarkPercent:                cfg.ImageCleanupLowWatermarkPercent,
+		diskCheckInterval:                  cfg.ImageCleanupDiskCheckInterval,
+		diskPath:                           cfg.ImageCleanupDiskPath,
+		getDiskUsage:                       diskUsage,
 	}
 }
 
@@ -120,6 +132,64 @@ func (imageManager *dockerImageManager) AddImageToCleanUpExclusionList(image str
 	})
 }
 
+// PinImage excludes the image from cleanup until the given time. Pinning an image again
+// replaces its previous deadline. The pin is saved with the image state, so that it's kept
+// when the agent restarts.
+func (imageManager *dockerImageManager) PinImage(imageName string, until time.Time) {
+	imageState, ok := imageManager.GetImageStateFromImageName(imageName)
+	if !ok {
+		logger.Warn("Unable to pin unknown image against cleanup", logger.Fields{
+			field.Image: imageName,
+		})
+		return
+	}
+	imageState.SetPinnedUntil(until)
+	imageManager.saveImageStateData(imageState)
+	logger.Info("Image pinned against cleanup", logger.Fields{
+		field.Image:   imageName,
+		"pinnedUntil": until.UTC().Format(time.RFC3339),
+	})
+}
+
+// RecordImage adds the image to the image states without any container reference, so that
+// it's known to the cleanup before a task uses it. pullSucceeded is recorded in the image
+// state when true.
+func (imageManager *dockerImageManager) RecordImage(imageName string, pullSucceeded bool) (*image.ImageState, error) {
+	if imageName == "" {
+		return nil, fmt.Errorf("Invalid image reference: Empty image name")
+	}
+	imageInspected, err := imageManager.client.InspectImage(imageName)
+	if err != nil {
+		logger.Error("Error inspecting image", logger.Fields{
+			field.Image: imageName,
+			field.Error: err,
+		})
+		return nil, err
+	}
+
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+	imageManager.removeExistingImageNameOfDifferentID(imageName, imageInspected.ID)
+	imageState, ok := imageManager.getImageState(imageInspected.ID)
+	if !ok {
+		imageState = &image.ImageState{
+			Image: &image.Image{
+				ImageID: imageInspected.ID,
+				Size:    imageInspected.Size,
+			},
+			PulledAt:   time.Now(),
+			LastUsedAt: time.Now(),
+		}
+		imageManager.imageStates = append(imageManager.imageStates, imageState)
+	}
+	imageState.AddImageName(imageName)
+	if pullSucceeded {
+		imageState.SetPullSucceeded(true)
+	}
+	imageManager.saveImageStateData(imageState)
+	return imageState, nil
+}
+
 func (imageManager *dockerImageManager) AddAllImageStates(imageStates []*image.ImageState) {
 	imageManager.updateLock.Lock()
 	defer imageManager.updateLock.Unlock()
@@ -361,10 +431,23 @@ func (imageManager *dockerImageManager) StartImageCleanupProcess(ctx context.Con
 
 func (imageManager *dockerImageManager) performPeriodicImageCleanup(ctx context.Context, imageCleanupInterval time.Duration) {
 	imageManager.imageCleanupTicker = time.NewTicker(imageCleanupInterval)
+	var diskCheckTickerC <-chan time.Time
+	if imageManager.highWatermarkPercent > 0 {
+		logger.Info("Disk pressure image cleanup enabled", logger.Fields{
+			"highWatermarkPercent": imageManager.highWatermarkPercent,
+			"lowWatermarkPercent":  imageManager.lowWatermarkPercent,
+			"diskCheckInterval":    imageManager.diskCheckInterval.String(),
+		})
+		diskCheckTicker := time.NewTicker(imageManager.diskCheckInterval)
+		defer diskCheckTicker.Stop()
+		diskCheckTickerC = diskCheckTicker.C
+	}
 	for {
 		select {
 		case <-imageManager.imageCleanupTicker.C:
 			go imageManager.removeUnusedImages(ctx)
+		case <-diskCheckTickerC:
+			go imageManager.removeImagesUnderDiskPressure(ctx)
 		case <-ctx.Done():
 			imageManager.imageCleanupTicker.Stop()
 			return
@@ -404,6 +487,116 @@ func (imageManager *dockerImageManager) removeUnusedImages(ctx context.Context)
 	}
 }
 
+// removeImagesUnderDiskPressure removes unused images when the usage of the filesystem holding
+// the Docker data root is above the high watermark, until the usage is estimated to be below
+// the low watermark. The images to remove are picked with the same rules as the periodic
+// cleanup: ECS images first, in LRU order, then non-ECS images if their cleanup is enabled.
+// The space freed is estimated from the size of the images.
+func (imageManager *dockerImageManager) removeImagesUnderDiskPressure(ctx context.Context) {
+	diskPath, err := imageManager.getDiskPath(ctx)
+	if err != nil {
+		logger.Warn("Unable to determine the Docker data root for disk pressure image cleanup", logger.Fields{
+			field.Error: err,
+		})
+		return
+	}
+	if !imageManager.isAboveHighWatermark(diskPath) {
+		return
+	}
+
+	ImagePullDeleteLock.Lock()
+	defer ImagePullDeleteLock.Unlock()
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+
+	// Check again as another cleanup may have run while waiting for the locks.
+	usedBytes, totalBytes, err := imageManager.getDiskUsage(diskPath)
+	if err != nil || diskUsagePercent(usedBytes, totalBytes) < float64(imageManager.highWatermarkPercent) {
+		return
+	}
+	bytesToFree := int64(usedBytes) - int64(totalBytes*uint64(imageManager.lowWatermarkPercent)/100)
+	fields := logger.Fields{
+		"diskPath":             diskPath,
+		"diskUsagePercent":     fmt.Sprintf("%.1f", diskUsagePercent(usedBytes, totalBytes)),
+		"highWatermarkPercent": imageManager.highWatermarkPercent,
+		"lowWatermarkPercent":  imageManager.lowWatermarkPercent,
+	}
+	logger.Info("Disk usage is above the high watermark, removing unused images", fields, logger.Fields{
+		"bytesToFree": bytesToFree,
+	})
+
+	var freedBytes int64
+	imageManager.imageStatesConsideredForDeletion = imageManager.imagesConsiderForDeletion(imageManager.getAllImageStates())
+	for freedBytes < bytesToFree {
+		imageState := imageManager.getUnusedImageForDeletion()
+		if imageState == nil {
+			break
+		}
+		logger.Info("Image ready for deletion", imageState.Fields())
+		imageManager.removeImage(ctx, imageState)
+		if _, ok := imageManager.getImageState(imageState.Image.ImageID); !ok {
+			freedBytes += imageState.Image.Size
+		}
+	}
+	if freedBytes < bytesToFree && imageManager.deleteNonECSImagesEnabled.Enabled() {
+		imageManager.removeNonECSContainers(ctx)
+		freedBytes += imageManager.removeNonECSImagesToFree(ctx, bytesToFree-freedBytes)
+	}
+
+	if freedBytes < bytesToFree {
+		logger.Warn("No more unused images to remove, disk usage is still above the low watermark", fields, logger.Fields{
+			"freedBytes": freedBytes,
+		})
+		return
+	}
+	logger.Info("Removed unused images under disk pressure", fields, logger.Fields{
+		"freedBytes": freedBytes,
+	})
+}
+
+// getDiskPath returns the path whose filesystem usage is checked against the watermarks,
+// which defaults to the Docker root dir. The default is only used on Windows, where Agent runs
+// on the host: elsewhere the configuration requires the path, since the Docker root dir isn't
+// visible from the Agent container.
+func (imageManager *dockerImageManager) getDiskPath(ctx context.Context) (string, error) {
+	imageManager.updateLock.RLock()
+	diskPath := imageManager.diskPath
+	imageManager.updateLock.RUnlock()
+	if diskPath != "" {
+		return diskPath, nil
+	}
+	info, err := imageManager.client.Info(ctx, dockerclient.InfoTimeout)
+	if err != nil {
+		return "", err
+	}
+	if info.DockerRootDir == "" {
+		return "", fmt.Errorf("Docker did not report its root dir")
+	}
+	imageManager.updateLock.Lock()
+	imageManager.diskPath = info.DockerRootDir
+	imageManager.updateLock.Unlock()
+	return info.DockerRootDir, nil
+}
+
+func (imageManager *dockerImageManager) isAboveHighWatermark(diskPath string) bool {
+	usedBytes, totalBytes, err := imageManager.getDiskUsage(diskPath)
+	if err != nil {
+		logger.Warn("Unable to get disk usage for disk pressure image cleanup", logger.Fields{
+			"diskPath":  diskPath,
+			field.Error: err,
+		})
+		return false
+	}
+	return diskUsagePercent(usedBytes, totalBytes) >= float64(imageManager.highWatermarkPercent)
+}
+
+func diskUsagePercent(usedBytes, totalBytes uint64) float64 {
+	if totalBytes == 0 {
+		return 0
+	}
+	return float64(usedBytes) * 100 / float64(totalBytes)
+}
+
 func (imageManager *dockerImageManager) removeNonECSContainers(ctx context.Context) {
 	nonECSContainersIDs, err := imageManager.getNonECSContainerIDs(ctx)
 	if err != nil {
@@ -492,36 +685,71 @@ func (imageManager *dockerImageManager) removeNonECSImages(ctx context.Context,
 		if !imageManager.nonECSImageOldEnough(image) {
 			continue
 		}
-		fields := logger.Fields{
-			field.ImageID:        image.ImageID,
-			field.ImageSizeBytes: image.Size,
-			"repoTags":           image.RepoTags,
-		}
-		if len(image.RepoTags) > 1 {
-			logger.Debug("Non-ECS image has more than one tag", fields)
-			for _, tag := range image.RepoTags {
-				err := imageManager.client.RemoveImage(ctx, tag, dockerclient.RemoveImageTimeout)
-				if err != nil {
-					logger.Error("Error removing non-ECS RepoTag", fields, logger.Fields{
-						field.Error: err,
-						"imageTag":  tag,
-					})
-				} else {
-					logger.Info("Non-ECS image tag removed", fields, logger.Fields{"imageTag": tag})
-					numImagesAlreadyDeleted++
-				}
-			}
-		} else {
-			logger.Debug("Removing non-ECS image", fields)
-			err := imageManager.client.RemoveImage(ctx, image.ImageID, dockerclient.RemoveImageTimeout)
+		numImagesAlreadyDeleted += imageManager.removeNonECSImage(ctx, image)
+	}
+}
+
+// removeNonECSImagesToFree removes non-ECS images, largest first, until the total size of the
+// removed images reaches bytesToFree. It returns the total size of the removed images.
+func (imageManager *dockerImageManager) removeNonECSImagesToFree(ctx context.Context, bytesToFree int64) int64 {
+	nonECSImages := imageManager.getNonECSImages(ctx)
+	sort.Slice(nonECSImages, func(i, j int) bool {
+		return nonECSImages[i].Size > nonECSImages[j].Size
+	})
+
+	var freedBytes int64
+	for _, image := range nonECSImages {
+		if freedBytes >= bytesToFree {
+			break
+		}
+		if !imageManager.nonECSImageOldEnough(image) {
+			continue
+		}
+		numReferences := len(image.RepoTags)
+		if numReferences == 0 {
+			numReferences = 1
+		}
+		if imageManager.removeNonECSImage(ctx, image) == numReferences {
+			freedBytes += image.Size
+		}
+	}
+	return freedBytes
+}
+
+// removeNonECSImage removes a non-ECS image, untagging each of its tags if it has more than
+// one. It returns the number of tags or images removed.
+func (imageManager *dockerImageManager) removeNonECSImage(ctx context.Context, image ImageWithSizeID) int {
+	var numRemoved int
+	fields := logger.Fields{
+		field.ImageID:        image.ImageID,
+		field.ImageSizeBytes: image.Size,
+		"repoTags":           image.RepoTags,
+	}
+	if len(image.RepoTags) > 1 {
+		logger.Debug("Non-ECS image has more than one tag", fields)
+		for _, tag := range image.RepoTags {
+			err := imageManager.client.RemoveImage(ctx, tag, dockerclient.RemoveImageTimeout)
 			if err != nil {
-				logger.Error("Error removing non-ECS image", fields, logger.Fields{field.Error: err})
+				logger.Error("Error removing non-ECS RepoTag", fields, logger.Fields{
+					field.Error: err,
+					"imageTag":  tag,
+				})
 			} else {
-				logger.Info("Non-ECS image removed", fields)
-				numImagesAlreadyDeleted++
+				logger.Info("Non-ECS image tag removed", fields, logger.Fields{"imageTag": tag})
+				numRemoved++
 			}
 		}
+	} else {
+		logger.Debug("Removing non-ECS image", fields)
+		err := imageManager.client.RemoveImage(ctx, image.ImageID, dockerclient.RemoveImageTimeout)
+		if err != nil {
+			logger.Error("Error removing non-ECS image", fields, logger.Fields{field.Error: err})
+		} else {
+			logger.Info("Non-ECS image removed", fields)
+			numRemoved++
+		}
 	}
+	return numRemoved
 }
 
 // getNonECSImages returns type ImageWithSizeID with all fields populated.
@@ -630,7 +858,7 @@ func (imageManager *dockerImageManager) isExcludedFromCleanup(imageState *image.
 			}
 		}
 	}
-	return false
+	return imageState.IsPinned(time.Now())
 }
 
 func (imageManager *dockerImageManager) removeLeastRecentlyUsedImage(ctx context.Context) error {
@@ -720,6 +720,71 @@ func TestImageCleanupExclusionListWithSingleName(t *testing.T) {
 	}
 }
 
+func TestRecordImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	imageManager := NewImageManager(defaultTestConfig(), client, dockerstate.NewTaskEngineState())
+	imageManager.SetDataClient(data.NewNoopClient())
+
+	client.EXPECT().InspectImage("busybox:latest").Return(&types.ImageInspect{ID: "sha256:qwerty", Size: 1024}, nil)
+	imageState, err := imageManager.RecordImage("busybox:latest", true)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:qwerty", imageState.Image.ImageID)
+	assert.Equal(t, int64(1024), imageState.Image.Size)
+	assert.Equal(t, []string{"busybox:latest"}, imageState.Image.Names)
+	assert.True(t, imageState.GetPullSucceeded())
+	assert.True(t, imageState.HasNoAssociatedContainers())
+
+	// Recording another name of the same image updates the existing image state
+	client.EXPECT().InspectImage("busybox:1").Return(&types.ImageInspect{ID: "sha256:qwerty", Size: 1024}, nil)
+	sameImageState, err := imageManager.RecordImage("busybox:1", false)
+	require.NoError(t, err)
+	assert.Same(t, imageState, sameImageState)
+	assert.Equal(t, []string{"busybox:latest", "busybox:1"}, imageState.Image.Names)
+	assert.Equal(t, 1, imageManager.(*dockerImageManager).GetImageStatesCount())
+
+	client.EXPECT().InspectImage("missing").Return(nil, errors.New("no such image"))
+	_, err = imageManager.RecordImage("missing", true)
+	assert.Error(t, err)
+}
+
+func TestPinnedImagesExcludedFromCleanup(t *testing.T) {
+	dataClient := newTestDataClient(t)
+	imageManager := &dockerImageManager{
+		minimumAgeBeforeDeletion: config.DefaultImageDeletionAge,
+		dataClient:               dataClient,
+	}
+	pinnedImageState := &image.ImageState{
+		Image:    &image.Image{ImageID: "sha256:qwerty1", Names: []string{"pinned"}},
+		PulledAt: time.Now().AddDate(0, -2, 0),
+	}
+	expiredImageState := &image.ImageState{
+		Image:    &image.Image{ImageID: "sha256:qwerty2", Names: []string{"expired"}},
+		PulledAt: time.Now().AddDate(0, -2, 0),
+	}
+	imageManager.AddAllImageStates([]*image.ImageState{pinnedImageState, expiredImageState})
+	imageManager.PinImage("pinned", time.Now().Add(time.Hour))
+	imageManager.PinImage("expired", time.Now().Add(-time.Second))
+	imageManager.PinImage("unknown", time.Now().Add(time.Hour))
+
+	result := imageManager.imagesConsiderForDeletion([]*image.ImageState{pinnedImageState, expiredImageState})
+	assert.Equal(t, map[string]*image.ImageState{"sha256:qwerty2": expiredImageState}, result)
+
+	// The pins are saved with the image states, and restored when the agent restarts
+	imageStates, err := dataClient.GetImageStates()
+	require.NoError(t, err)
+	restoredManager := &dockerImageManager{
+		minimumAgeBeforeDeletion: config.DefaultImageDeletionAge,
+		dataClient:               dataClient,
+	}
+	restoredManager.AddAllImageStates(imageStates)
+	result = restoredManager.imagesConsiderForDeletion(restoredManager.getAllImageStates())
+	require.Len(t, result, 1)
+	assert.Contains(t, result, "sha256:qwerty2")
+}
+
 func TestImageCleanupExclusionListWithMultipleNames(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1942,3 +2007,105 @@ func TestImageCleanupProcessNotStart(t *testing.T) {
 	imageManager.StartImageCleanupProcess(ctx)
 	// Nothing should happen.
 }
+
+func newDiskPressureTestImageManager(client dockerapi.DockerClient, usedBytes, totalBytes uint64) *dockerImageManager {
+	imageManager := &dockerImageManager{
+		client:                   client,
+		state:                    dockerstate.NewTaskEngineState(),
+		minimumAgeBeforeDeletion: config.DefaultImageDeletionAge,
+		highWatermarkPercent:     90,
+		lowWatermarkPercent:      50,
+		diskPath:                 "/var/lib/docker",
+		getDiskUsage: func(path string) (uint64, uint64, error) {
+			return usedBytes, totalBytes, nil
+		},
+	}
+	imageManager.SetDataClient(data.NewNoopClient())
+	return imageManager
+}
+
+func TestRemoveImagesUnderDiskPressure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newDiskPressureTestImageManager(client, 95, 100)
+	imageManager.imageCleanupExclusionList = []string{"excluded"}
+
+	newImageState := func(id, name string, size int64, lastUsedAt time.Time) *image.ImageState {
+		return &image.ImageState{
+			Image:      &image.Image{ImageID: id, Names: []string{name}, Size: size},
+			PulledAt:   time.Now().AddDate(0, -2, 0),
+			LastUsedAt: lastUsedAt,
+		}
+	}
+	// 45 bytes need to be freed to get back to the low watermark.
+	imageManager.addImageState(newImageState("sha256:old", "old", 30, time.Now().AddDate(0, -2, 0)))
+	imageManager.addImageState(newImageState("sha256:recent", "recent", 30, time.Now().AddDate(0, -1, 0)))
+	imageManager.addImageState(newImageState("sha256:newest", "newest", 30, time.Now()))
+	imageManager.addImageState(newImageState("sha256:excluded", "excluded", 100, time.Now().AddDate(-1, 0, 0)))
+
+	gomock.InOrder(
+		client.EXPECT().RemoveImage(gomock.Any(), "old", dockerclient.RemoveImageTimeout).Return(nil),
+		client.EXPECT().RemoveImage(gomock.Any(), "recent", dockerclient.RemoveImageTimeout).Return(nil),
+	)
+
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	require.Len(t, imageManager.imageStates, 2)
+	for _, imageState := range imageManager.imageStates {
+		assert.Contains(t, []string{"sha256:newest", "sha256:excluded"}, imageState.Image.ImageID)
+	}
+}
+
+func TestRemoveImagesUnderDiskPressureBelowHighWatermark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newDiskPressureTestImageManager(client, 80, 100)
+	imageManager.addImageState(&image.ImageState{
+		Image:    &image.Image{ImageID: "sha256:old", Names: []string{"old"}, Size: 30},
+		PulledAt: time.Now().AddDate(0, -2, 0),
+	})
+
+	client.EXPECT().RemoveImage(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	assert.Len(t, imageManager.imageStates, 1)
+}
+
+func TestRemoveImagesUnderDiskPressureNonECSImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newDiskPressureTestImageManager(client, 95, 100)
+	imageManager.deleteNonECSImagesEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+	imageManager.imageCleanupExclusionList = []string{"excluded:latest"}
+
+	client.EXPECT().ListContainers(gomock.Any(), gomock.Any(), gomock.Any()).Return(dockerapi.ListContainersResponse{})
+	client.EXPECT().ListImages(gomock.Any(), dockerclient.ListImagesTimeout).Return(dockerapi.ListImagesResponse{
+		ImageIDs: []string{"sha256:small", "sha256:large", "sha256:excluded"},
+	})
+	client.EXPECT().InspectImage("sha256:small").Return(&types.ImageInspect{Size: 10, RepoTags: []string{"small:latest"}}, nil)
+	client.EXPECT().InspectImage("sha256:large").Return(&types.ImageInspect{Size: 50, RepoTags: []string{"large:latest"}}, nil)
+	client.EXPECT().InspectImage("sha256:excluded").Return(&types.ImageInspect{Size: 100, RepoTags: []string{"excluded:latest"}}, nil)
+	// the largest image frees enough space on its own
+	client.EXPECT().RemoveImage(gomock.Any(), "sha256:large", dockerclient.RemoveImageTimeout).Return(nil)
+
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+}
+
+func TestRemoveImagesUnderDiskPressureDockerRootDir(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newDiskPressureTestImageManager(client, 0, 100)
+	imageManager.diskPath = ""
+	var checkedPath string
+	imageManager.getDiskUsage = func(path string) (uint64, uint64, error) {
+		checkedPath = path
+		return 0, 100, nil
+	}
+
+	client.EXPECT().Info(gomock.Any(), dockerclient.InfoTimeout).Return(types.Info{DockerRootDir: "/data/docker"}, nil).Times(1)
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	assert.Equal(t, "/data/docker", checkedPath)
+}
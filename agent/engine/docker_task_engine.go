@@ -40,6 +40,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/execcmd"
 	"github.com/aws/amazon-ecs-agent/agent/engine/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/agent/secretfiles"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/credentialspec"
@@ -54,11 +55,16 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/aws/smithy-go/ptr"
 	"github.com/docker/docker/api/types"
 	dockercontainer "github.com/docker/docker/api/types/container"
@@ -146,6 +152,13 @@ var (
 	}
 )
 
+// taskNetworkBuilder sets up and removes the network namespaces of tasks. It is implemented by
+// the netlib network builder on Linux.
+type taskNetworkBuilder interface {
+	Start(ctx context.Context, mode ecstypes.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error
+	Stop(ctx context.Context, mode ecstypes.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error
+}
+
 // DockerTaskEngine is a state machine for managing a task and its containers
 // in ECS.
 //
@@ -168,8 +181,11 @@ type DockerTaskEngine struct {
 	state        dockerstate.TaskEngineState
 	managedTasks map[string]*managedTask
 
-	// waitingTasksQueue is a FIFO queue of tasks waiting to acquire host resources
+	// waitingTasksQueue is the queue of tasks waiting to acquire host resources, ordered
+	// according to the task queue policy of the config
 	waitingTaskQueue []*managedTask
+	// taskTagsGetter gets the tags holding the priority of the queued tasks
+	taskTagsGetter TaskTagsGetter
 
 	events                 <-chan dockerapi.DockerContainerChangeEvent
 	monitorQueuedTaskEvent chan struct{}
@@ -203,6 +219,7 @@ type DockerTaskEngine struct {
 	imageManager                        ImageManager
 	containerStatusToTransitionFunction map[apicontainerstatus.ContainerStatus]transitionApplyFunc
 	metadataManager                     containermetadata.Manager
+	secretFilesManager                  secretfiles.Manager
 	serviceconnectManager               serviceconnect.Manager
 
 	// daemonManagers map is threadsafe for reads as it's written only once at startup
@@ -213,6 +230,15 @@ type DockerTaskEngine struct {
 	daemonTasksLock sync.RWMutex
 	daemonTasks     map[string]*apitask.Task
 
+	// prefetchedImages tracks the images requested to be pulled ahead of the tasks that use them
+	prefetchedImagesLock sync.RWMutex
+	prefetchedImages     map[string]*PrefetchedImage
+
+	// metadataFileRefreshes tracks the containers whose metadata file is being refreshed, keyed by
+	// docker ID. The value is true if the file has to be refreshed again once the refresh completes.
+	metadataFileRefreshesLock sync.Mutex
+	metadataFileRefreshes     map[string]bool
+
 	// taskSteadyStatePollInterval is the duration that a managed task waits
 	// once the task gets into steady state before polling the state of all of
 	// the task's containers to re-evaluate if the task is still in steady state
@@ -233,6 +259,13 @@ type DockerTaskEngine struct {
 	stopContainerBackoffMin   time.Duration
 	stopContainerBackoffMax   time.Duration
 	namespaceHelper           ecscni.NamespaceHelper
+	egressPolicyEnforcer      egresspolicy.Enforcer
+	// taskNetworkBuilder connects the network namespaces of tasks in bridge network mode to
+	// the task bridge. It is created on first use, see getTaskNetworkBuilder.
+	taskNetworkBuilder     taskNetworkBuilder
+	taskNetworkBuilderLock sync.Mutex
+	taskBridgeData         *taskBridgeDataClient
+	metricsFactory         metrics.EntryFactory
 }
 
 // NewDockerTaskEngine returns a created, but uninitialized, DockerTaskEngine.
@@ -270,6 +303,7 @@ func NewDockerTaskEngine(cfg *config.Config,
 		appnetClient:               appnet.CreateClient(),
 
 		metadataManager:                   metadataManager,
+		secretFilesManager:                secretfiles.NewManager(cfg),
 		serviceconnectManager:             serviceConnectManager,
 		daemonManagers:                    daemonManagers,
 		taskSteadyStatePollInterval:       defaultTaskSteadyStatePollInterval,
@@ -281,9 +315,14 @@ func NewDockerTaskEngine(cfg *config.Config,
 		stopContainerBackoffMin:           defaultStopContainerBackoffMin,
 		stopContainerBackoffMax:           defaultStopContainerBackoffMax,
 		namespaceHelper:                   ecscni.NewNamespaceHelper(client),
+		egressPolicyEnforcer:              egresspolicy.NewEnforcer(execwrapper.NewExec()),
 		daemonTasks:                       make(map[string]*apitask.Task),
+		prefetchedImages:                  make(map[string]*PrefetchedImage),
+		metadataFileRefreshes:             make(map[string]bool),
+		metricsFactory:                    metrics.NewNopEntryFactory(),
 	}
 
+	dockerTaskEngine.taskBridgeData = newTaskBridgeDataClient(dockerTaskEngine)
 	dockerTaskEngine.initializeContainerStatusToTransitionFunction()
 
 	return dockerTaskEngine
@@ -369,6 +408,7 @@ func (engine *DockerTaskEngine) Init(ctx context.Context) error {
 	engine.initialized = true
 	go engine.startPeriodicExecAgentsMonitoring(derivedCtx)
 	go engine.watchAppNetImage(derivedCtx)
+	go engine.startPeriodicSecretRotation(derivedCtx)
 	return nil
 }
 
@@ -380,9 +420,8 @@ func (engine *DockerTaskEngine) Init(ctx context.Context) error {
 // but does not block if monitorQueuedTasks is already processing queued tasks
 // Buffered channel of size 1 is sufficient because we only want to go through the queue
 // once at any point and schedule as many tasks as possible (as many resources are available)
-// Calls on 'wakeUpTaskQueueMonitor' when 'monitorQueuedTasks' is doing work are redundant
-// as new tasks are enqueued at the end and will be taken into account in the continued loop
-// if permitted by design
+// Calls on 'wakeUpTaskQueueMonitor' when 'monitorQueuedTasks' is doing work leave an event
+// in the channel, so that the tasks enqueued meanwhile are considered in another pass
 func (engine *DockerTaskEngine) wakeUpTaskQueueMonitor() {
 	select {
 	case engine.monitorQueuedTaskEvent <- struct{}{}:
@@ -401,28 +440,53 @@ func (engine *DockerTaskEngine) topTask() (*managedTask, error) {
 }
 
 func (engine *DockerTaskEngine) enqueueTask(task *managedTask) {
+	policy := engine.taskQueuePolicy()
+	priority := 0
+	if policy == config.TaskQueuePriorityPolicy && !engine.canStartImmediately(task) {
+		priority = engine.getTaskQueuePriority(task)
+	}
 	engine.waitingTasksLock.Lock()
-	engine.waitingTaskQueue = append(engine.waitingTaskQueue, task)
+	task.enqueuedAt = time.Now()
+	task.queuePriority = priority
+	task.queueWaitMetric = engine.getMetricsFactory().New(metrics.TaskQueueWaitMetricName)
+	engine.waitingTaskQueue = insertQueuedTask(engine.waitingTaskQueue, task, policy)
+	depth := len(engine.waitingTaskQueue)
 	engine.waitingTasksLock.Unlock()
-	logger.Debug("Enqueued task in Waiting Task Queue", logger.Fields{field.TaskARN: task.Arn})
+	engine.getMetricsFactory().New(metrics.TaskQueueDepthMetricName).WithGauge(depth).Done(nil)
+	logger.Debug("Enqueued task in Waiting Task Queue", logger.Fields{
+		field.TaskARN:   task.Arn,
+		"queuePriority": priority,
+	})
 	engine.wakeUpTaskQueueMonitor()
 }
 
-func (engine *DockerTaskEngine) dequeueTask() (*managedTask, error) {
+// dequeueTask removes a task from the waiting queue, recording how long it waited
+func (engine *DockerTaskEngine) dequeueTask(task *managedTask, err error) {
 	engine.waitingTasksLock.Lock()
-	defer engine.waitingTasksLock.Unlock()
-	if len(engine.waitingTaskQueue) > 0 {
-		task := engine.waitingTaskQueue[0]
-		engine.waitingTaskQueue = engine.waitingTaskQueue[1:]
-		logger.Debug("Dequeued task from Waiting Task Queue", logger.Fields{field.TaskARN: task.Arn})
-		return task, nil
+	for i, queuedTask := range engine.waitingTaskQueue {
+		if queuedTask == task {
+			engine.waitingTaskQueue = append(engine.waitingTaskQueue[:i:i], engine.waitingTaskQueue[i+1:]...)
+			break
+		}
 	}
+	depth := len(engine.waitingTaskQueue)
+	waited := time.Since(task.enqueuedAt)
+	waitMetric := task.queueWaitMetric
+	task.queueWaitMetric = nil
+	engine.waitingTasksLock.Unlock()
 
-	return nil, fmt.Errorf("no tasks in waiting queue")
+	engine.getMetricsFactory().New(metrics.TaskQueueDepthMetricName).WithGauge(depth).Done(nil)
+	if waitMetric != nil {
+		waitMetric.WithGauge(waited).Done(err)
+	}
+	logger.Debug("Dequeued task from Waiting Task Queue", logger.Fields{
+		field.TaskARN: task.Arn,
+		"waited":      waited.String(),
+	})
 }
 
-// monitorQueuedTasks starts as many tasks as possible based on FIFO order of waitingTaskQueue
-// and availability of host resources. When no more tasks can be started, it will wait on
+// monitorQueuedTasks starts as many tasks as possible based on the order of waitingTaskQueue,
+// the task queue policy and availability of host resources. When no more tasks can be started, it will wait on
 // monitorQueuedTaskEvent channel. This channel receives (best effort) messages when
 // - a task stops
 // - a new task is queued up
@@ -436,16 +500,7 @@ func (engine *DockerTaskEngine) monitorQueuedTasks(ctx context.Context) {
 			return
 		case <-engine.monitorQueuedTaskEvent:
 			// Dequeue as many tasks as possible and start wake up their goroutines
-			for {
-				task, err := engine.topTask()
-				if err != nil {
-					break
-				}
-				dequeuedTask := engine.tryDequeueWaitingTasks(task)
-				if !dequeuedTask {
-					break
-				}
-			}
+			engine.startQueuedTasks()
 			logger.Debug("No more tasks could be started at this moment, waiting")
 		}
 	}
@@ -461,17 +516,17 @@ func (engine *DockerTaskEngine) tryDequeueWaitingTasks(task *managedTask) bool {
 	taskDesiredStatus := task.GetDesiredStatus()
 	if taskDesiredStatus.Terminal() {
 		logger.Info("Task desired status changed to STOPPED while waiting for host resources, progressing without consuming resources", logger.Fields{field.TaskARN: task.Arn})
-		engine.returnWaitingTask()
+		engine.returnWaitingTask(task)
 		return true
 	}
 	taskHostResources := task.ToHostResources()
 	consumed, err := task.engine.hostResourceManager.consume(task.Arn, taskHostResources)
 	if err != nil {
-		engine.failWaitingTask(err)
+		engine.failWaitingTask(task, err)
 		return true
 	}
 	if consumed {
-		engine.startWaitingTask()
+		engine.startWaitingTask(task)
 		return true
 	}
 	return false
@@ -479,20 +534,20 @@ func (engine *DockerTaskEngine) tryDequeueWaitingTasks(task *managedTask) bool {
 }
 
 // To be called when resources are not to be consumed by host resource manager, just dequeues and returns
-func (engine *DockerTaskEngine) returnWaitingTask() {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) returnWaitingTask(task *managedTask) {
+	engine.dequeueTask(task, nil)
 	task.consumedHostResourceEvent <- struct{}{}
 }
 
-func (engine *DockerTaskEngine) failWaitingTask(err error) {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) failWaitingTask(task *managedTask, err error) {
+	engine.dequeueTask(task, err)
 	logger.Error(fmt.Sprintf("Error consuming resources due to invalid task config : %s", err.Error()), logger.Fields{field.TaskARN: task.Arn})
 	task.SetDesiredStatus(apitaskstatus.TaskStopped)
 	task.consumedHostResourceEvent <- struct{}{}
 }
 
-func (engine *DockerTaskEngine) startWaitingTask() {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) startWaitingTask(task *managedTask) {
+	engine.dequeueTask(task, nil)
 	logger.Info("Host resources consumed, progressing task", logger.Fields{field.TaskARN: task.Arn})
 	task.consumedHostResourceEvent <- struct{}{}
 }
@@ -852,6 +907,17 @@ func (engine *DockerTaskEngine) synchronizeContainerStatus(container *apicontain
 			go engine.updateMetadataFile(task, container)
 		}
 	}
+	if currentState == apicontainerstatus.ContainerStopped && metadata.Error == nil &&
+		container.Container.HasPendingRestart() && !container.Container.GetDesiredStatus().Terminal() {
+		// The container exited while its restart was being delayed by the restart backoff.
+		// Leave it known as running so that the managed task schedules the restart again.
+		logger.Info("Found container with a pending restart", logger.Fields{
+			field.TaskID:    task.GetID(),
+			field.Container: container.DockerName,
+			"nextRestartAt": container.Container.RestartTracker.GetNextRestartAt().UTC().Format(time.RFC3339),
+		})
+		return
+	}
 	if currentState > container.Container.GetKnownStatus() {
 		// update the container known status
 		container.Container.SetKnownStatus(currentState)
@@ -920,6 +986,17 @@ func (engine *DockerTaskEngine) sweepTask(task *apitask.Task) {
 			})
 		}
 	}
+
+	// Clean secret files directory for task
+	if engine.cfg.SecretRotationInterval > 0 {
+		err := engine.secretFilesManager.Clean(task.Arn)
+		if err != nil {
+			logger.Warn("Error cleaning task secret files", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Error:  err,
+			})
+		}
+	}
 }
 
 var removeAll = os.RemoveAll
@@ -1138,6 +1215,7 @@ func (engine *DockerTaskEngine) handleDockerEvent(event dockerapi.DockerContaine
 			})
 			cont.Container.SetHealthStatus(event.DockerContainerMetadata.Health)
 		}
+		engine.refreshMetadataFile(task, cont.Container)
 		return
 	}
 
@@ -2118,6 +2196,24 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 		}
 	}
 
+	// Write the values of the container's environment variable secrets to files and add their
+	// directory to the container's mounts. The container isn't created without its secret files.
+	if engine.cfg.SecretRotationInterval > 0 && !container.IsInternal() && container.HasSecret(isSecretAsEnv) {
+		info, infoErr := engine.client.Info(engine.ctx, dockerclient.InfoTimeout)
+		if infoErr != nil {
+			logger.Warn("Unable to get docker info", logger.Fields{
+				field.TaskID:    task.GetID(),
+				field.Container: container.Name,
+				field.Error:     infoErr,
+			})
+		}
+		if err := engine.secretFilesManager.Create(config, hostConfig, task, container, info.SecurityOptions); err != nil {
+			return dockerapi.DockerContainerMetadata{
+				Error: apierrors.NamedError(&apierrors.DockerClientConfigError{Msg: "unable to create secret files: " + err.Error()}),
+			}
+		}
+	}
+
 	createContainerBegin := time.Now()
 	metadata := client.CreateContainer(engine.ctx, config, hostConfig,
 		dockerContainerName, engine.cfg.ContainerCreateTimeout)
@@ -2487,10 +2583,26 @@ func (engine *DockerTaskEngine) provisionContainerResourcesAwsvpc(task *apitask.
 		}
 	}
 
+	err = engine.applyEgressPolicy(task)
+	if err != nil {
+		logger.Error("Unable to apply egress policy in pause container namespace", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Error:  err,
+		})
+		return dockerapi.DockerContainerMetadata{
+			DockerID: cniConfig.ContainerID,
+			Error: ContainerNetworkingError{fmt.Errorf(
+				"container resource provisioning: failed to apply egress policy: %+v", err)},
+		}
+	}
+
 	return dockerapi.MetadataFromContainer(containerInspectOutput)
 }
 
 func (engine *DockerTaskEngine) provisionContainerResourcesBridgeMode(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
+	if task.UsesTaskBridge() && container.Type == apicontainer.ContainerCNIPause {
+		return engine.provisionContainerResourcesTaskBridge(task, container)
+	}
 	if !task.IsServiceConnectEnabled() || container.Type != apicontainer.ContainerCNIPause {
 		return dockerapi.DockerContainerMetadata{
 			Error: ContainerNetworkingError{fromError: fmt.Errorf(
@@ -2539,10 +2651,46 @@ func (engine *DockerTaskEngine) provisionContainerResourcesBridgeMode(task *apit
 	return dockerapi.MetadataFromContainer(containerInspectOutput)
 }
 
+// provisionContainerResourcesTaskBridge connects the network namespace of the pause container of a task
+// to the task bridge, and forwards the host ports of the task to it.
+func (engine *DockerTaskEngine) provisionContainerResourcesTaskBridge(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
+	containerInspectOutput, err := engine.inspectContainer(task, container)
+	if err != nil || containerInspectOutput == nil {
+		return dockerapi.DockerContainerMetadata{
+			Error: ContainerNetworkingError{fromError: fmt.Errorf(
+				"container resource provisioning task bridge: cannot setup netns - error inspecting container %s: %+v", container.Name, err),
+			},
+		}
+	}
+
+	taskIP, err := engine.setupTaskBridge(task, containerInspectOutput.State.Pid)
+	if err != nil {
+		logger.Error("Unable to connect pause container namespace to the task bridge", logger.Fields{
+			field.TaskID:    task.GetID(),
+			field.Container: container.Name,
+			field.Error:     err,
+		})
+		return dockerapi.DockerContainerMetadata{
+			DockerID: containerInspectOutput.ID,
+			Error: ContainerNetworkingError{fmt.Errorf(
+				"container resource provisioning: failed to connect to the task bridge: %+v", err)},
+		}
+	}
+
+	logger.Info("Task associated with ip address", logger.Fields{
+		field.TaskID: task.GetID(),
+		"ip":         taskIP,
+	})
+	engine.state.AddTaskIPAddress(taskIP, task.Arn)
+	task.SetLocalIPAddress(taskIP)
+	engine.saveTaskData(task)
+	return dockerapi.MetadataFromContainer(containerInspectOutput)
+}
+
 // checkTearDownPauseContainer idempotently tears down the pause container network when the pause container's known
 // or desired status is stopped.
 func (engine *DockerTaskEngine) checkTearDownPauseContainer(task *apitask.Task) {
-	if !task.IsNetworkModeAWSVPC() || (task.IsNetworkModeBridge() && !task.IsServiceConnectEnabled()) {
+	if !task.IsNetworkModeAWSVPC() && !task.UsesTaskBridge() {
 		return
 	}
 	for _, container := range task.Containers {
@@ -2578,6 +2726,19 @@ func (engine *DockerTaskEngine) cleanupPauseContainerNetwork(task *apitask.Task,
 		})
 		engine.handleDelay(delay)
 	}
+
+	if task.UsesTaskBridge() {
+		logger.Info("Disconnecting the network namespace from the task bridge", logger.Fields{
+			field.TaskID:    task.GetID(),
+			field.Container: container.Name,
+		})
+		if err := engine.teardownTaskBridge(task); err != nil {
+			return err
+		}
+		container.SetContainerTornDown(true)
+		return nil
+	}
+
 	containerInspectOutput, err := engine.inspectContainer(task, container)
 	if err != nil {
 		return errors.Wrap(err, "engine: cannot cleanup task network namespace due to error inspecting pause container")
@@ -2602,6 +2763,17 @@ func (engine *DockerTaskEngine) cleanupPauseContainerNetwork(task *apitask.Task,
 			"engine: failed cleanup task network namespace, task: %s", task.String())
 	}
 
+	if task.IsNetworkModeAWSVPC() {
+		// The network namespace goes away along with the pause container, hence failing to
+		// remove the egress policy is not fatal to the cleanup.
+		if err := engine.removeEgressPolicy(task); err != nil {
+			logger.Warn("Unable to remove egress policy from pause container namespace", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Error:  err,
+			})
+		}
+	}
+
 	err = engine.cniClient.CleanupNS(engine.ctx, cniConfig, cniCleanupTimeout)
 	if err != nil {
 		return err
@@ -2718,7 +2890,8 @@ func (engine *DockerTaskEngine) stopContainer(task *apitask.Task, container *api
 
 	// Cleanup the pause container network namespace before stop the container
 	if container.Type == apicontainer.ContainerCNIPause {
-		if task.IsNetworkModeAWSVPC() || (task.IsNetworkModeBridge() && task.IsServiceConnectEnabled()) {
+		if task.IsNetworkModeAWSVPC() || (task.IsNetworkModeBridge() && task.IsServiceConnectEnabled()) ||
+			task.UsesTaskBridge() {
 			err := engine.cleanupPauseContainerNetwork(task, container)
 			if err != nil {
 				logger.Error("Unable to cleanup pause container network namespace", logger.Fields{
@@ -2903,6 +3076,52 @@ func (engine *DockerTaskEngine) updateMetadataFile(task *apitask.Task, cont *api
 	}
 }
 
+// refreshMetadataFile rewrites the metadata file of a running container in the background, as
+// its health status or network bindings may have changed. This is a no-op until the metadata
+// file is first updated after the container starts. The refreshes of a container run one at a
+// time, so that a refresh that inspected the container earlier can't overwrite the file written
+// by a later one with a stale health status.
+func (engine *DockerTaskEngine) refreshMetadataFile(task *apitask.Task, container *apicontainer.Container) {
+	if !engine.cfg.ContainerMetadataEnabled.Enabled() || container.IsInternal() || !container.IsMetadataFileUpdated() {
+		return
+	}
+	dockerID := container.GetRuntimeID()
+	engine.metadataFileRefreshesLock.Lock()
+	defer engine.metadataFileRefreshesLock.Unlock()
+	if _, ok := engine.metadataFileRefreshes[dockerID]; ok {
+		// The refresh in progress may have inspected the container before the change, so the file is
+		// refreshed again once it completes.
+		engine.metadataFileRefreshes[dockerID] = true
+		return
+	}
+	engine.metadataFileRefreshes[dockerID] = false
+	go engine.runMetadataFileRefreshes(task, container.Name, dockerID)
+}
+
+// runMetadataFileRefreshes rewrites the metadata file of a container until no other refresh was
+// requested while it was being written.
+func (engine *DockerTaskEngine) runMetadataFileRefreshes(task *apitask.Task, containerName, dockerID string) {
+	for {
+		err := engine.metadataManager.Update(engine.ctx, dockerID, task, containerName)
+		if err != nil {
+			logger.Warn("Failed to refresh metadata file for container", logger.Fields{
+				field.TaskID:    task.GetID(),
+				field.Container: containerName,
+				field.Error:     err,
+			})
+		}
+
+		engine.metadataFileRefreshesLock.Lock()
+		if !engine.metadataFileRefreshes[dockerID] {
+			delete(engine.metadataFileRefreshes, dockerID)
+			engine.metadataFileRefreshesLock.Unlock()
+			return
+		}
+		engine.metadataFileRefreshes[dockerID] = false
+		engine.metadataFileRefreshesLock.Unlock()
+	}
+}
+
 func getContainerHostIP(networkSettings *types.NetworkSettings) (string, bool) {
 	if networkSettings == nil {
 		return "", false
@@ -19,17 +19,27 @@ package engine
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/ecscni"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/platform"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	dockercontainer "github.com/docker/docker/api/types/container"
 )
 
@@ -154,3 +164,126 @@ func (engine *DockerTaskEngine) updateCredentialSpecMapping(taskID string, conta
 		}
 	}
 }
+
+// applyEgressPolicy enforces the egress policy of a task in awsvpc network mode inside the
+// network namespace of its pause container.
+func (engine *DockerTaskEngine) applyEgressPolicy(task *apitask.Task) error {
+	policy := task.GetEgressPolicy()
+	if policy == nil {
+		return nil
+	}
+	ipv6 := egressPolicyIPv6Enabled(task, policy)
+	logger.Info("Applying egress policy to task", logger.Fields{
+		field.TaskID:    task.GetID(),
+		"defaultAction": policy.DefaultAction,
+		"ipv6":          ipv6,
+	})
+	return engine.egressPolicyEnforcer.Apply(engine.ctx, task.GetNetworkNamespace(), policy, ipv6)
+}
+
+// removeEgressPolicy stops enforcing the egress policy of a task in awsvpc network mode.
+func (engine *DockerTaskEngine) removeEgressPolicy(task *apitask.Task) error {
+	policy := task.GetEgressPolicy()
+	if policy == nil || task.GetNetworkNamespace() == "" {
+		return nil
+	}
+	ipv6 := egressPolicyIPv6Enabled(task, policy)
+	return engine.egressPolicyEnforcer.Remove(engine.ctx, task.GetNetworkNamespace(), ipv6)
+}
+
+// egressPolicyIPv6Enabled returns true if the IPv6 traffic of the task needs to be subject
+// to the egress policy.
+func egressPolicyIPv6Enabled(task *apitask.Task, policy *egresspolicy.EgressPolicy) bool {
+	primaryENI := task.GetPrimaryENI()
+	return (primaryENI != nil && len(primaryENI.GetIPV6Addresses()) > 0) || policy.HasIPv6Rules()
+}
+
+// getTaskNetworkBuilder returns the network builder that connects the network namespaces of
+// tasks in bridge network mode to the task bridge. It is created on first use, once the metrics
+// factory of the engine is set.
+func (engine *DockerTaskEngine) getTaskNetworkBuilder() (taskNetworkBuilder, error) {
+	engine.taskNetworkBuilderLock.Lock()
+	defer engine.taskNetworkBuilderLock.Unlock()
+
+	if engine.taskNetworkBuilder != nil {
+		return engine.taskNetworkBuilder, nil
+	}
+	if !engine.cfg.TaskBridgeEnabled.Enabled() {
+		return nil, errors.New("the task bridge is not enabled")
+	}
+	builder, err := netlib.NewNetworkBuilder(platform.Config{
+		Name:             platform.EC2Platform,
+		TaskBridgeSubnet: engine.cfg.TaskBridgeSubnet,
+		CNIPluginsPath:   engine.cfg.CNIPluginsPath,
+	}, engine.getMetricsFactory(), nil, engine.taskBridgeData, engine.cfg.DataDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to set up the task bridge")
+	}
+	engine.taskNetworkBuilder = builder
+	return builder, nil
+}
+
+// setupTaskBridge connects the network namespace of the pause container of a task to the task
+// bridge and forwards the host ports of the task to it. It returns the IP address of the task.
+func (engine *DockerTaskEngine) setupTaskBridge(task *apitask.Task, pausePID int) (string, error) {
+	builder, err := engine.getTaskNetworkBuilder()
+	if err != nil {
+		return "", err
+	}
+	portMappings, err := task.AssignTaskBridgePortBindings(engine.cfg.DynamicHostPortRange)
+	if err != nil {
+		return "", err
+	}
+	subnet := engine.cfg.TaskBridgeSubnet
+	if subnet == "" {
+		subnet = platform.DefaultTaskBridgeSubnet
+	}
+	bridgeConfig := tasknetworkconfig.NewBridgeConfig(task.GetID(), platform.TaskBridgeName, subnet)
+	bridgeConfig.PortMappings = portMappings
+
+	netNSPath := fmt.Sprintf(ecscni.NetnsFormat, strconv.Itoa(pausePID))
+	netNS, err := tasknetworkconfig.NewNetworkNamespace(task.GetID(), netNSPath, 0, nil)
+	if err != nil {
+		return "", err
+	}
+	netNS.BridgeConfig = bridgeConfig
+	task.SetNetworkNamespace(netNSPath)
+	if err := builder.Start(engine.ctx, types.NetworkModeBridge, task.GetID(), netNS); err != nil {
+		// Remove what was set up, the pause container is stopped when its resources
+		// cannot be provisioned.
+		netNS.DesiredState = status.NetworkDeleted
+		if stopErr := builder.Stop(engine.ctx, types.NetworkModeBridge, task.GetID(), netNS); stopErr != nil {
+			logger.Warn("Unable to remove the partial task bridge setup", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Error:  stopErr,
+			})
+		}
+		return "", err
+	}
+	ip, _, err := net.ParseCIDR(bridgeConfig.IPV4Address)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid task bridge address %s", bridgeConfig.IPV4Address)
+	}
+	return ip.String(), nil
+}
+
+// teardownTaskBridge removes the port forwarding of a task and disconnects the network namespace
+// of its pause container from the task bridge.
+func (engine *DockerTaskEngine) teardownTaskBridge(task *apitask.Task) error {
+	bridgeConfig, err := engine.taskBridgeData.GetBridgeConfig(task.GetID())
+	if err != nil {
+		// The task was not connected to the task bridge, or was already disconnected.
+		return nil
+	}
+	builder, err := engine.getTaskNetworkBuilder()
+	if err != nil {
+		return err
+	}
+	netNS, err := tasknetworkconfig.NewNetworkNamespace(task.GetID(), task.GetNetworkNamespace(), 0, nil)
+	if err != nil {
+		return err
+	}
+	netNS.BridgeConfig = bridgeConfig
+	netNS.DesiredState = status.NetworkDeleted
+	return builder.Stop(engine.ctx, types.NetworkModeBridge, task.GetID(), netNS)
+}
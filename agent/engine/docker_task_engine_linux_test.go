@@ -57,10 +57,16 @@ import (
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	mock_netlib "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/mocks"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
+	mock_egresspolicy "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy/mocks"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	cniTypesCurrent "github.com/containernetworking/cni/pkg/types/100"
 	"github.com/docker/docker/api/types"
 	dockercontainer "github.com/docker/docker/api/types/container"
@@ -1624,3 +1630,237 @@ func TestCredentialSpecResourceTaskFile(t *testing.T) {
 	ret := taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
 	assert.Nil(t, ret.Error)
 }
+
+func TestProvisionContainerResourcesAwsvpcWithEgressPolicy(t *testing.T) {
+	testCases := []struct {
+		name       string
+		applyError error
+	}{
+		{
+			name: "egress policy applied",
+		},
+		{
+			name:       "egress policy apply failure",
+			applyError: errors.New("iptables error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			ctrl, dockerClient, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+			defer ctrl.Finish()
+
+			mockNamespaceHelper := mock_ecscni.NewMockNamespaceHelper(ctrl)
+			mockCNIClient := mock_ecscni.NewMockCNIClient(ctrl)
+			mockEnforcer := mock_egresspolicy.NewMockEnforcer(ctrl)
+			taskEngine.(*DockerTaskEngine).namespaceHelper = mockNamespaceHelper
+			taskEngine.(*DockerTaskEngine).cniClient = mockCNIClient
+			taskEngine.(*DockerTaskEngine).egressPolicyEnforcer = mockEnforcer
+
+			testTask := testdata.LoadTask("sleep5")
+			pauseContainer := &apicontainer.Container{
+				Name: "pausecontainer",
+				Type: apicontainer.ContainerCNIPause,
+			}
+			testTask.Containers = append(testTask.Containers, pauseContainer)
+			testTask.AddTaskENI(mockENI)
+			testTask.NetworkMode = apitask.AWSVPCNetworkMode
+			testTask.EgressPolicy = &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionDeny}
+			taskEngine.(*DockerTaskEngine).State().AddTask(testTask)
+			taskEngine.(*DockerTaskEngine).State().AddContainer(&apicontainer.DockerContainer{
+				DockerID:   containerID,
+				DockerName: dockerContainerName,
+				Container:  pauseContainer,
+			}, testTask)
+
+			gomock.InOrder(
+				dockerClient.EXPECT().InspectContainer(gomock.Any(), containerID, gomock.Any()).Return(&types.ContainerJSON{
+					ContainerJSONBase: &types.ContainerJSONBase{
+						ID:    containerID,
+						State: &types.ContainerState{Pid: containerPid},
+						HostConfig: &dockercontainer.HostConfig{
+							NetworkMode: containerNetworkMode,
+						},
+					},
+				}, nil),
+				mockCNIClient.EXPECT().SetupNS(gomock.Any(), gomock.Any(), gomock.Any()).Return(nsResult, nil),
+				mockNamespaceHelper.EXPECT().ConfigureTaskNamespaceRouting(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+				// The IPv6 traffic is subject to the policy as the task ENI has an IPv6 address.
+				mockEnforcer.EXPECT().Apply(gomock.Any(), ExpectedNetworkNamespace, testTask.EgressPolicy, true).
+					Return(tc.applyError),
+			)
+
+			err := taskEngine.(*DockerTaskEngine).provisionContainerResources(testTask, pauseContainer).Error
+			if tc.applyError != nil {
+				assert.Error(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestCleanupPauseContainerNetworkRemovesEgressPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, dockerClient, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	mockCNIClient := mock_ecscni.NewMockCNIClient(ctrl)
+	mockEnforcer := mock_egresspolicy.NewMockEnforcer(ctrl)
+	taskEngine.(*DockerTaskEngine).cniClient = mockCNIClient
+	taskEngine.(*DockerTaskEngine).egressPolicyEnforcer = mockEnforcer
+	taskEngine.(*DockerTaskEngine).handleDelay = func(time.Duration) {}
+
+	testTask := testdata.LoadTask("sleep5")
+	pauseContainer := &apicontainer.Container{
+		Name: "pausecontainer",
+		Type: apicontainer.ContainerCNIPause,
+	}
+	testTask.Containers = append(testTask.Containers, pauseContainer)
+	testTask.AddTaskENI(mockENI)
+	testTask.NetworkMode = apitask.AWSVPCNetworkMode
+	testTask.EgressPolicy = &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionDeny}
+	testTask.SetNetworkNamespace(ExpectedNetworkNamespace)
+	taskEngine.(*DockerTaskEngine).State().AddTask(testTask)
+	taskEngine.(*DockerTaskEngine).State().AddContainer(&apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: dockerContainerName,
+		Container:  pauseContainer,
+	}, testTask)
+
+	gomock.InOrder(
+		dockerClient.EXPECT().InspectContainer(gomock.Any(), containerID, gomock.Any()).Return(&types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:    containerID,
+				State: &types.ContainerState{Pid: containerPid},
+				HostConfig: &dockercontainer.HostConfig{
+					NetworkMode: containerNetworkMode,
+				},
+			},
+		}, nil),
+		// Failing to remove the egress policy doesn't fail the cleanup.
+		mockEnforcer.EXPECT().Remove(gomock.Any(), ExpectedNetworkNamespace, true).Return(errors.New("iptables error")),
+		mockCNIClient.EXPECT().CleanupNS(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	require.NoError(t, taskEngine.(*DockerTaskEngine).cleanupPauseContainerNetwork(testTask, pauseContainer))
+	assert.True(t, pauseContainer.IsContainerTornDown())
+}
+
+func TestProvisionContainerResourcesTaskBridgeStartFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, dockerClient, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	mockNetworkBuilder := mock_netlib.NewMockNetworkBuilder(ctrl)
+	taskEngine.(*DockerTaskEngine).taskNetworkBuilder = mockNetworkBuilder
+
+	testTask := testdata.LoadTask("sleep5")
+	testTask.NetworkMode = apitask.BridgeNetworkMode
+	pauseContainer := &apicontainer.Container{
+		Name: apitask.NetworkPauseContainerName,
+		Type: apicontainer.ContainerCNIPause,
+	}
+	testTask.Containers = append(testTask.Containers, pauseContainer)
+	taskEngine.(*DockerTaskEngine).State().AddTask(testTask)
+	taskEngine.(*DockerTaskEngine).State().AddContainer(&apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: dockerContainerName,
+		Container:  pauseContainer,
+	}, testTask)
+
+	gomock.InOrder(
+		dockerClient.EXPECT().InspectContainer(gomock.Any(), containerID, gomock.Any()).Return(&types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:    containerID,
+				State: &types.ContainerState{Pid: containerPid},
+			},
+		}, nil),
+		mockNetworkBuilder.EXPECT().Start(gomock.Any(), ecstypes.NetworkModeBridge, testTask.GetID(), gomock.Any()).
+			Return(errors.New("error")),
+		// What was set up before the failure is removed right away.
+		mockNetworkBuilder.EXPECT().Stop(gomock.Any(), ecstypes.NetworkModeBridge, testTask.GetID(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, mode ecstypes.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error {
+				assert.Equal(t, status.NetworkDeleted, netNS.DesiredState)
+				return nil
+			}),
+	)
+
+	result := taskEngine.(*DockerTaskEngine).provisionContainerResources(testTask, pauseContainer)
+	assert.Error(t, result.Error)
+	assert.Empty(t, testTask.GetLocalIPAddress())
+}
+
+func TestProvisionAndCleanupContainerResourcesTaskBridge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, dockerClient, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	mockNetworkBuilder := mock_netlib.NewMockNetworkBuilder(ctrl)
+	taskEngine.(*DockerTaskEngine).taskNetworkBuilder = mockNetworkBuilder
+	taskEngine.(*DockerTaskEngine).handleDelay = func(time.Duration) {}
+
+	testTask := testdata.LoadTask("sleep5")
+	testTask.NetworkMode = apitask.BridgeNetworkMode
+	testTask.Containers[0].Ports = []apicontainer.PortBinding{{ContainerPort: 80, HostPort: 8080}}
+	pauseContainer := &apicontainer.Container{
+		Name: apitask.NetworkPauseContainerName,
+		Type: apicontainer.ContainerCNIPause,
+	}
+	testTask.Containers = append(testTask.Containers, pauseContainer)
+	taskEngine.(*DockerTaskEngine).State().AddTask(testTask)
+	taskEngine.(*DockerTaskEngine).State().AddContainer(&apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: dockerContainerName,
+		Container:  pauseContainer,
+	}, testTask)
+
+	gomock.InOrder(
+		dockerClient.EXPECT().InspectContainer(gomock.Any(), containerID, gomock.Any()).Return(&types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:    containerID,
+				State: &types.ContainerState{Pid: containerPid},
+				HostConfig: &dockercontainer.HostConfig{
+					NetworkMode: "none",
+				},
+			},
+		}, nil),
+		mockNetworkBuilder.EXPECT().Start(gomock.Any(), ecstypes.NetworkModeBridge, testTask.GetID(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, mode ecstypes.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error {
+				assert.Equal(t, ExpectedNetworkNamespace, netNS.Path)
+				assert.Equal(t, status.NetworkReadyPull, netNS.DesiredState)
+				bridgeConfig := netNS.BridgeConfig
+				assert.Equal(t, "ecs-task-bridge", bridgeConfig.BridgeName)
+				assert.Equal(t, "172.30.0.0/16", bridgeConfig.IPV4Subnet)
+				assert.Equal(t, []tasknetworkconfig.PortMapping{
+					{ContainerPort: 80, HostPort: 8080, Protocol: "tcp"},
+				}, bridgeConfig.PortMappings)
+				bridgeConfig.IPV4Address = "172.30.0.2/16"
+				bridgeConfig.KnownStatus = status.NetworkReadyPull
+				return taskEngine.(*DockerTaskEngine).taskBridgeData.SaveBridgeConfig(bridgeConfig)
+			}),
+		// The port forwarding and the address are removed with the bridge config they were set up with.
+		mockNetworkBuilder.EXPECT().Stop(gomock.Any(), ecstypes.NetworkModeBridge, testTask.GetID(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, mode ecstypes.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error {
+				assert.Equal(t, ExpectedNetworkNamespace, netNS.Path)
+				assert.Equal(t, status.NetworkDeleted, netNS.DesiredState)
+				assert.Equal(t, "172.30.0.2/16", netNS.BridgeConfig.IPV4Address)
+				assert.Len(t, netNS.BridgeConfig.PortMappings, 1)
+				return taskEngine.(*DockerTaskEngine).taskBridgeData.DeleteBridgeConfig(taskID)
+			}),
+	)
+
+	require.True(t, testTask.UsesTaskBridge())
+	require.NoError(t, taskEngine.(*DockerTaskEngine).provisionContainerResources(testTask, pauseContainer).Error)
+	assert.Equal(t, "172.30.0.2", testTask.GetLocalIPAddress())
+	assert.Equal(t, []apicontainer.PortBinding{
+		{ContainerPort: 80, HostPort: 8080, BindIP: "0.0.0.0", Protocol: apicontainer.TransportProtocolTCP},
+	}, testTask.Containers[0].GetKnownPortBindings())
+
+	require.NoError(t, taskEngine.(*DockerTaskEngine).cleanupPauseContainerNetwork(testTask, pauseContainer))
+	assert.True(t, pauseContainer.IsContainerTornDown())
+}
@@ -59,6 +59,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/container/restart"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
@@ -2299,6 +2300,38 @@ func TestSynchronizeContainerStatus(t *testing.T) {
 	}
 }
 
+// TestSynchronizeContainerStatusPendingRestart tests that a container that exited while
+// its restart was delayed is left known as running, for its restart to be scheduled again
+func TestSynchronizeContainerStatusPendingRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, imageManager, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	testContainer := &apicontainer.Container{
+		Name:          "c1",
+		RestartPolicy: &restart.RestartPolicy{Enabled: true, BackoffInitialDelay: 60},
+	}
+	testContainer.RestartTracker = restart.NewRestartTracker(*testContainer.RestartPolicy)
+	testContainer.RestartTracker.NextRestartAt = time.Now().Add(time.Minute)
+	testContainer.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	testContainer.SetDesiredStatus(apicontainerstatus.ContainerRunning)
+	testTask := &apitask.Task{Containers: []*apicontainer.Container{testContainer}}
+	dockerContainer := &apicontainer.DockerContainer{
+		DockerID:   "1234",
+		DockerName: "c1",
+		Container:  testContainer,
+	}
+
+	exitCode := 1
+	client.EXPECT().DescribeContainer(gomock.Any(), "1234").Return(apicontainerstatus.ContainerStopped,
+		dockerapi.DockerContainerMetadata{DockerID: "1234", ExitCode: &exitCode})
+	imageManager.EXPECT().RecordContainerReference(testContainer)
+	taskEngine.(*DockerTaskEngine).synchronizeContainerStatus(dockerContainer, testTask)
+	assert.Equal(t, apicontainerstatus.ContainerRunning, testContainer.GetKnownStatus())
+	assert.Equal(t, &exitCode, testContainer.GetKnownExitCode())
+}
+
 // TestHandleDockerHealthEvent tests the docker health event will only cause the
 // container health status change
 func TestHandleDockerHealthEvent(t *testing.T) {
@@ -2331,6 +2364,102 @@ func TestHandleDockerHealthEvent(t *testing.T) {
 	assert.Equal(t, testContainer.Health.Status, apicontainerstatus.ContainerHealthy)
 }
 
+func TestHandleDockerHealthEventRefreshesMetadataFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	metadataConfig := defaultConfig
+	metadataConfig.ContainerMetadataEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+	ctrl, _, _, taskEngine, _, _, metadataManager, _ := mocks(t, ctx, &metadataConfig)
+	defer ctrl.Finish()
+
+	state := taskEngine.(*DockerTaskEngine).State()
+	testTask := testdata.LoadTask("sleep5")
+	testContainer := testTask.Containers[0]
+	testContainer.HealthCheckType = "docker"
+	testContainer.SetRuntimeID("id")
+	testContainer.SetMetadataFileUpdated()
+
+	state.AddTask(testTask)
+	state.AddContainer(&apicontainer.DockerContainer{DockerID: "id",
+		DockerName: "container_name",
+		Container:  testContainer,
+	}, testTask)
+
+	updated := make(chan struct{})
+	metadataManager.EXPECT().Update(gomock.Any(), "id", testTask, testContainer.Name).Do(
+		func(interface{}, interface{}, interface{}, interface{}) {
+			close(updated)
+		}).Return(nil)
+
+	taskEngine.(*DockerTaskEngine).handleDockerEvent(dockerapi.DockerContainerChangeEvent{
+		Status: apicontainerstatus.ContainerRunning,
+		Type:   apicontainer.ContainerHealthEvent,
+		DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+			DockerID: "id",
+			Health: apicontainer.HealthStatus{
+				Status: apicontainerstatus.ContainerUnhealthy,
+			},
+		},
+	})
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the metadata file to be refreshed")
+	}
+}
+
+func TestRefreshMetadataFileSerialized(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	metadataConfig := defaultConfig
+	metadataConfig.ContainerMetadataEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+	ctrl, _, _, taskEngine, _, _, metadataManager, _ := mocks(t, ctx, &metadataConfig)
+	defer ctrl.Finish()
+
+	testTask := testdata.LoadTask("sleep5")
+	testContainer := testTask.Containers[0]
+	testContainer.SetRuntimeID("id")
+	testContainer.SetMetadataFileUpdated()
+
+	firstUpdateStarted := make(chan struct{})
+	unblockFirstUpdate := make(chan struct{})
+	secondUpdateDone := make(chan struct{})
+	gomock.InOrder(
+		metadataManager.EXPECT().Update(gomock.Any(), "id", testTask, testContainer.Name).Do(
+			func(interface{}, interface{}, interface{}, interface{}) {
+				close(firstUpdateStarted)
+				<-unblockFirstUpdate
+			}).Return(nil),
+		// The refreshes requested while the first one runs are coalesced into a single one, which
+		// only starts once the first one completes.
+		metadataManager.EXPECT().Update(gomock.Any(), "id", testTask, testContainer.Name).Do(
+			func(interface{}, interface{}, interface{}, interface{}) {
+				close(secondUpdateDone)
+			}).Return(nil),
+	)
+
+	engine := taskEngine.(*DockerTaskEngine)
+	engine.refreshMetadataFile(testTask, testContainer)
+	select {
+	case <-firstUpdateStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the metadata file to be refreshed")
+	}
+	engine.refreshMetadataFile(testTask, testContainer)
+	engine.refreshMetadataFile(testTask, testContainer)
+	close(unblockFirstUpdate)
+	select {
+	case <-secondUpdateDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the metadata file to be refreshed again")
+	}
+	assert.Eventually(t, func() bool {
+		engine.metadataFileRefreshesLock.Lock()
+		defer engine.metadataFileRefreshesLock.Unlock()
+		return len(engine.metadataFileRefreshes) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestContainerMetadataUpdatedOnRestart(t *testing.T) {
 	dockerID := "dockerID_created"
 	labels := map[string]string{
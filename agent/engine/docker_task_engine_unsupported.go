@@ -18,6 +18,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
@@ -55,3 +56,27 @@ func (engine *DockerTaskEngine) reloadAppNetImage() error {
 // with updated AppNet image
 func (engine *DockerTaskEngine) restartInstanceTask() {
 }
+
+// applyEgressPolicy returns an error if the task has an egress policy, as egress policies
+// are only supported on Linux.
+func (engine *DockerTaskEngine) applyEgressPolicy(task *apitask.Task) error {
+	if task.GetEgressPolicy() != nil {
+		return errors.New("egress policies are not supported on this platform")
+	}
+	return nil
+}
+
+// removeEgressPolicy is a no-op, as egress policies are only supported on Linux.
+func (engine *DockerTaskEngine) removeEgressPolicy(task *apitask.Task) error {
+	return nil
+}
+
+// setupTaskBridge returns an error, as the task bridge is only supported on Linux.
+func (engine *DockerTaskEngine) setupTaskBridge(task *apitask.Task, pausePID int) (string, error) {
+	return "", errors.New("the task bridge is not supported on this platform")
+}
+
+// teardownTaskBridge is a no-op, as the task bridge is only supported on Linux.
+func (engine *DockerTaskEngine) teardownTaskBridge(task *apitask.Task) error {
+	return nil
+}
@@ -98,3 +98,26 @@ func (engine *DockerTaskEngine) updateCredentialSpecMapping(taskID string, conta
 		}
 	}
 }
+
+// applyEgressPolicy returns an error if the task has an egress policy, as egress policies
+// cannot be enforced on Windows.
+func (engine *DockerTaskEngine) applyEgressPolicy(task *apitask.Task) error {
+	if task.GetEgressPolicy() != nil {
+		return errors.New("egress policies are not supported on windows")
+	}
+	return nil
+}
+
+func (engine *DockerTaskEngine) removeEgressPolicy(task *apitask.Task) error {
+	return nil
+}
+
+// setupTaskBridge returns an error, as the task bridge is only supported on Linux.
+func (engine *DockerTaskEngine) setupTaskBridge(task *apitask.Task, pausePID int) (string, error) {
+	return "", errors.New("the task bridge is not supported on windows")
+}
+
+// teardownTaskBridge is a no-op, as the task bridge is only supported on Linux.
+func (engine *DockerTaskEngine) teardownTaskBridge(task *apitask.Task) error {
+	return nil
+}
@@ -17,6 +17,7 @@ package engine
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/aws/amazon-ecs-agent/agent/utils"
@@ -132,6 +133,16 @@ func (h *HostResourceManager) consume(taskArn string, resources map[string]types
 	return false, nil
 }
 
+// unavailableResources returns the keys of the resources that can't be consumed for a task
+// with the current account of the host resources, sorted, without consuming them.
+func (h *HostResourceManager) unavailableResources(resources map[string]types.Resource) ([]string, error) {
+	h.hostResourceManagerRWLock.Lock()
+	defer h.hostResourceManagerRWLock.Unlock()
+	_, failedResourceKeys, err := h.consumable(resources)
+	sort.Strings(failedResourceKeys)
+	return failedResourceKeys, err
+}
+
 // Functions checkConsumableIntType and checkConsumableStringSetType to be called
 // only after checking for resource map health
 func (h *HostResourceManager) checkConsumableIntType(resourceName string, resources map[string]types.Resource) bool {
@@ -50,7 +50,10 @@ type ImageState struct {
 	// PullSucceeded defines whether this image has been pulled successfully before,
 	// this should be set to true when one of the pull image call succeeds.
 	PullSucceeded bool
-	lock          sync.RWMutex
+	// PinnedUntil is the time until which the image is excluded from cleanup, as it was
+	// prefetched ahead of the tasks that use it.
+	PinnedUntil time.Time
+	lock        sync.RWMutex
 }
 
 // UpdateContainerReference updates container reference in image state
@@ -156,6 +159,22 @@ func (imageState *ImageState) SetPullSucceeded(pullSucceeded bool) {
 	imageState.PullSucceeded = pullSucceeded
 }
 
+// SetPinnedUntil sets the time until which the image is excluded from cleanup
+func (imageState *ImageState) SetPinnedUntil(pinnedUntil time.Time) {
+	imageState.lock.Lock()
+	defer imageState.lock.Unlock()
+
+	imageState.PinnedUntil = pinnedUntil
+}
+
+// IsPinned returns whether the image is excluded from cleanup at the given time
+func (imageState *ImageState) IsPinned(now time.Time) bool {
+	imageState.lock.RLock()
+	defer imageState.lock.RUnlock()
+
+	return now.Before(imageState.PinnedUntil)
+}
+
 // GetPullSucceeded safely returns the PullSucceeded of the imageState
 func (imageState *ImageState) GetPullSucceeded() bool {
 	imageState.lock.RLock()
@@ -174,11 +193,13 @@ func (imageState *ImageState) MarshalJSON() ([]byte, error) {
 		PulledAt      time.Time
 		LastUsedAt    time.Time
 		PullSucceeded bool
+		PinnedUntil   time.Time
 	}{
 		Image:         imageState.Image,
 		PulledAt:      imageState.PulledAt,
 		LastUsedAt:    imageState.LastUsedAt,
 		PullSucceeded: imageState.PullSucceeded,
+		PinnedUntil:   imageState.PinnedUntil,
 	})
 }
 
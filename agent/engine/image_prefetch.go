@@ -0,0 +1,253 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+const (
+	// prefetchContainerName is the name of the placeholder container used to pull prefetched
+	// images, which aren't associated with any task yet
+	prefetchContainerName = "~internal~ecs~prefetch"
+
+	// ImagePrefetchPending means that the image is waiting to be, or being, pulled
+	ImagePrefetchPending ImagePrefetchStatus = "PENDING"
+	// ImagePrefetchPulled means that the image has been pulled
+	ImagePrefetchPulled ImagePrefetchStatus = "PULLED"
+	// ImagePrefetchCached means that the image was not pulled, as the pull behavior of Agent
+	// allows the use of the cached image
+	ImagePrefetchCached ImagePrefetchStatus = "CACHED"
+	// ImagePrefetchFailed means that the image couldn't be pulled
+	ImagePrefetchFailed ImagePrefetchStatus = "FAILED"
+)
+
+// ecrImageRegex matches the image references of ECR private repositories, capturing the
+// registry ID and the region.
+var ecrImageRegex = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?/`)
+
+// ImagePrefetchStatus is the status of a prefetched image
+type ImagePrefetchStatus string
+
+// ImagePrefetchRequest lists the images to pull ahead of the tasks that use them. Images
+// can be listed directly or through task definitions, in the format of the ECS
+// RegisterTaskDefinition API, of which only the container images are used.
+type ImagePrefetchRequest struct {
+	Images          []string                 `json:"images,omitempty"`
+	TaskDefinitions []PrefetchTaskDefinition `json:"taskDefinitions,omitempty"`
+}
+
+// PrefetchTaskDefinition is a task definition whose container images are prefetched
+type PrefetchTaskDefinition struct {
+	Family               string                        `json:"family,omitempty"`
+	ContainerDefinitions []PrefetchContainerDefinition `json:"containerDefinitions"`
+}
+
+// PrefetchContainerDefinition is a container definition whose image is prefetched
+type PrefetchContainerDefinition struct {
+	Name  string `json:"name,omitempty"`
+	Image string `json:"image"`
+}
+
+// PrefetchedImage is the status of an image requested to be prefetched
+type PrefetchedImage struct {
+	Image       string              `json:"Image"`
+	Status      ImagePrefetchStatus `json:"Status"`
+	Error       string              `json:"Error,omitempty"`
+	PinnedUntil *time.Time          `json:"PinnedUntil,omitempty"`
+}
+
+// imageNames returns the distinct images of the request, in the order in which they are listed.
+func (request ImagePrefetchRequest) imageNames() []string {
+	var imageNames []string
+	seen := make(map[string]struct{})
+	add := func(imageName string) {
+		if _, ok := seen[imageName]; ok || imageName == "" {
+			return
+		}
+		seen[imageName] = struct{}{}
+		imageNames = append(imageNames, imageName)
+	}
+	for _, imageName := range request.Images {
+		add(imageName)
+	}
+	for _, taskDefinition := range request.TaskDefinitions {
+		for _, containerDefinition := range taskDefinition.ContainerDefinitions {
+			add(containerDefinition.Image)
+		}
+	}
+	return imageNames
+}
+
+// PrefetchImagesFromFile prefetches the images listed in the file, which contains an
+// ImagePrefetchRequest in JSON.
+func (engine *DockerTaskEngine) PrefetchImagesFromFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var request ImagePrefetchRequest
+	if err := json.Unmarshal(content, &request); err != nil {
+		return fmt.Errorf("unable to parse image prefetch file %s: %w", path, err)
+	}
+	_, err = engine.PrefetchImages(request)
+	return err
+}
+
+// PrefetchImages starts pulling the images of the request in the background, and returns
+// the images accepted for prefetching. Images already being prefetched are skipped.
+func (engine *DockerTaskEngine) PrefetchImages(request ImagePrefetchRequest) ([]string, error) {
+	imageNames := request.imageNames()
+	if len(imageNames) == 0 {
+		return nil, errors.New("no images to prefetch")
+	}
+
+	engine.prefetchedImagesLock.Lock()
+	defer engine.prefetchedImagesLock.Unlock()
+	if engine.prefetchedImages == nil {
+		engine.prefetchedImages = make(map[string]*PrefetchedImage)
+	}
+	var accepted []string
+	for _, imageName := range imageNames {
+		if prefetched, ok := engine.prefetchedImages[imageName]; ok && prefetched.Status == ImagePrefetchPending {
+			continue
+		}
+		engine.prefetchedImages[imageName] = &PrefetchedImage{
+			Image:  imageName,
+			Status: ImagePrefetchPending,
+		}
+		accepted = append(accepted, imageName)
+	}
+	if len(accepted) > 0 {
+		logger.Info("Prefetching images", logger.Fields{
+			"images": accepted,
+		})
+		go engine.prefetchImages(accepted)
+	}
+	return accepted, nil
+}
+
+// GetPrefetchedImages returns the status of the images requested to be prefetched, sorted by image.
+func (engine *DockerTaskEngine) GetPrefetchedImages() []PrefetchedImage {
+	engine.prefetchedImagesLock.RLock()
+	defer engine.prefetchedImagesLock.RUnlock()
+	prefetchedImages := make([]PrefetchedImage, 0, len(engine.prefetchedImages))
+	for _, prefetched := range engine.prefetchedImages {
+		prefetchedImages = append(prefetchedImages, *prefetched)
+	}
+	sort.Slice(prefetchedImages, func(i, j int) bool {
+		return prefetchedImages[i].Image < prefetchedImages[j].Image
+	})
+	return prefetchedImages
+}
+
+// prefetchImages pulls the images one at a time, so that prefetching doesn't compete
+// with the image pulls of tasks more than one image at a time.
+func (engine *DockerTaskEngine) prefetchImages(imageNames []string) {
+	for _, imageName := range imageNames {
+		if engine.ctx.Err() != nil {
+			engine.setPrefetchResult(imageName, ImagePrefetchFailed, engine.ctx.Err(), nil)
+			continue
+		}
+		status, err := engine.prefetchImage(imageName)
+		if err != nil {
+			logger.Error("Failed to prefetch image", logger.Fields{
+				field.Image: imageName,
+				field.Error: err,
+			})
+			engine.setPrefetchResult(imageName, ImagePrefetchFailed, err, nil)
+			continue
+		}
+		var pinnedUntil *time.Time
+		if engine.cfg.ImagePrefetchPinDuration > 0 {
+			until := engine.time().Now().Add(engine.cfg.ImagePrefetchPinDuration)
+			engine.imageManager.PinImage(imageName, until)
+			pinnedUntil = &until
+		}
+		logger.Info("Prefetched image", logger.Fields{
+			field.Image: imageName,
+			"status":    status,
+		})
+		engine.setPrefetchResult(imageName, status, nil, pinnedUntil)
+	}
+}
+
+// prefetchImage pulls the image if required by the image pull behavior of Agent, and records
+// it in the image manager.
+func (engine *DockerTaskEngine) prefetchImage(imageName string) (ImagePrefetchStatus, error) {
+	container := &apicontainer.Container{
+		Name:                   prefetchContainerName,
+		Image:                  imageName,
+		RegistryAuthentication: prefetchRegistryAuthData(imageName),
+	}
+	status := ImagePrefetchCached
+	if engine.imagePullRequired(engine.cfg.ImagePullBehavior, container, "") {
+		ImagePullDeleteLock.RLock()
+		metadata := engine.client.PullImage(engine.ctx, imageName, container.RegistryAuthentication,
+			engine.cfg.ImagePullTimeout)
+		ImagePullDeleteLock.RUnlock()
+		if metadata.Error != nil {
+			return "", metadata.Error
+		}
+		status = ImagePrefetchPulled
+	}
+	imageState, err := engine.imageManager.RecordImage(imageName, status == ImagePrefetchPulled)
+	if err != nil {
+		return "", err
+	}
+	engine.state.AddImageState(imageState)
+	return status, nil
+}
+
+func (engine *DockerTaskEngine) setPrefetchResult(imageName string, status ImagePrefetchStatus, err error,
+	pinnedUntil *time.Time) {
+	engine.prefetchedImagesLock.Lock()
+	defer engine.prefetchedImagesLock.Unlock()
+	prefetched := &PrefetchedImage{
+		Image:       imageName,
+		Status:      status,
+		PinnedUntil: pinnedUntil,
+	}
+	if err != nil {
+		prefetched.Error = err.Error()
+	}
+	engine.prefetchedImages[imageName] = prefetched
+}
+
+// prefetchRegistryAuthData returns the registry authentication data to pull images from ECR
+// with the credentials of the instance, as there is no task execution role to use when
+// prefetching. Images from other registries use the Docker auth configuration of Agent.
+func prefetchRegistryAuthData(imageName string) *apicontainer.RegistryAuthenticationData {
+	matches := ecrImageRegex.FindStringSubmatch(imageName)
+	if matches == nil {
+		return nil
+	}
+	return &apicontainer.RegistryAuthenticationData{
+		Type: apicontainer.AuthTypeECR,
+		ECRAuthData: &apicontainer.ECRAuthData{
+			RegistryID: matches[1],
+			Region:     matches[2],
+		},
+	}
+}
@@ -0,0 +1,190 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testECRImage = "123456789012.dkr.ecr.us-west-2.amazonaws.com/app:v1"
+
+func TestImagePrefetchRequestImageNames(t *testing.T) {
+	request := ImagePrefetchRequest{
+		Images: []string{"busybox:latest", "", "busybox:latest"},
+		TaskDefinitions: []PrefetchTaskDefinition{
+			{
+				Family: "web",
+				ContainerDefinitions: []PrefetchContainerDefinition{
+					{Name: "app", Image: testECRImage},
+					{Name: "sidecar", Image: "busybox:latest"},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{"busybox:latest", testECRImage}, request.imageNames())
+}
+
+func TestPrefetchRegistryAuthData(t *testing.T) {
+	assert.Nil(t, prefetchRegistryAuthData("busybox:latest"))
+	assert.Nil(t, prefetchRegistryAuthData("public.ecr.aws/docker/library/busybox:latest"))
+
+	authData := prefetchRegistryAuthData(testECRImage)
+	require.NotNil(t, authData)
+	assert.Equal(t, apicontainer.AuthTypeECR, authData.Type)
+	assert.Equal(t, "123456789012", authData.ECRAuthData.RegistryID)
+	assert.Equal(t, "us-west-2", authData.ECRAuthData.Region)
+	assert.False(t, authData.ECRAuthData.UseExecutionRole)
+
+	authData = prefetchRegistryAuthData("123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn/app")
+	require.NotNil(t, authData)
+	assert.Equal(t, "cn-north-1", authData.ECRAuthData.Region)
+}
+
+func waitForPrefetch(t *testing.T, taskEngine *DockerTaskEngine) []PrefetchedImage {
+	var prefetched []PrefetchedImage
+	require.Eventually(t, func() bool {
+		prefetched = taskEngine.GetPrefetchedImages()
+		for _, p := range prefetched {
+			if p.Status == ImagePrefetchPending {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	return prefetched
+}
+
+func TestPrefetchImages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := defaultTestConfig()
+	cfg.ImagePullBehavior = config.ImagePullAlwaysBehavior
+	cfg.ImagePrefetchPinDuration = time.Hour
+	ctrl, client, mockTime, taskEngine, _, imageManager, _, _ := mocks(t, ctx, cfg)
+	defer ctrl.Finish()
+
+	now := time.Unix(1700000000, 0)
+	mockTime.EXPECT().Now().Return(now).AnyTimes()
+	client.EXPECT().PullImage(gomock.Any(), "busybox:latest", nil, cfg.ImagePullTimeout).
+		Return(dockerapi.DockerContainerMetadata{})
+	client.EXPECT().PullImage(gomock.Any(), testECRImage, prefetchRegistryAuthData(testECRImage), cfg.ImagePullTimeout).
+		Return(dockerapi.DockerContainerMetadata{Error: dockerapi.CannotPullECRContainerError{FromError: errors.New("denied")}})
+	imageManager.EXPECT().RecordImage("busybox:latest", true).
+		Return(&image.ImageState{Image: &image.Image{ImageID: "sha256:busybox"}}, nil)
+	imageManager.EXPECT().PinImage("busybox:latest", now.Add(time.Hour))
+
+	dockerTaskEngine := taskEngine.(*DockerTaskEngine)
+	accepted, err := dockerTaskEngine.PrefetchImages(ImagePrefetchRequest{Images: []string{"busybox:latest", testECRImage}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"busybox:latest", testECRImage}, accepted)
+
+	prefetched := waitForPrefetch(t, dockerTaskEngine)
+	require.Len(t, prefetched, 2)
+	assert.Equal(t, testECRImage, prefetched[0].Image)
+	assert.Equal(t, ImagePrefetchFailed, prefetched[0].Status)
+	assert.Contains(t, prefetched[0].Error, "denied")
+	assert.Nil(t, prefetched[0].PinnedUntil)
+	assert.Equal(t, "busybox:latest", prefetched[1].Image)
+	assert.Equal(t, ImagePrefetchPulled, prefetched[1].Status)
+	require.NotNil(t, prefetched[1].PinnedUntil)
+	assert.Equal(t, now.Add(time.Hour), *prefetched[1].PinnedUntil)
+}
+
+func TestPrefetchImagesRespectsPullBehavior(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := defaultTestConfig()
+	cfg.ImagePullBehavior = config.ImagePullOnceBehavior
+	cfg.ImagePrefetchPinDuration = time.Hour
+	ctrl, _, mockTime, taskEngine, _, imageManager, _, _ := mocks(t, ctx, cfg)
+	defer ctrl.Finish()
+
+	imageState := &image.ImageState{Image: &image.Image{ImageID: "sha256:busybox"}, PullSucceeded: true}
+	mockTime.EXPECT().Now().Return(time.Now()).AnyTimes()
+	imageManager.EXPECT().GetImageStateFromImageName("busybox:latest").Return(imageState, true)
+	imageManager.EXPECT().RecordImage("busybox:latest", false).Return(imageState, nil)
+	imageManager.EXPECT().PinImage("busybox:latest", gomock.Any())
+
+	dockerTaskEngine := taskEngine.(*DockerTaskEngine)
+	_, err := dockerTaskEngine.PrefetchImages(ImagePrefetchRequest{Images: []string{"busybox:latest"}})
+	require.NoError(t, err)
+
+	prefetched := waitForPrefetch(t, dockerTaskEngine)
+	require.Len(t, prefetched, 1)
+	assert.Equal(t, ImagePrefetchCached, prefetched[0].Status)
+}
+
+func TestPrefetchImagesNoImages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, _, _, taskEngine, _, _, _, _ := mocks(t, ctx, defaultTestConfig())
+	defer ctrl.Finish()
+
+	_, err := taskEngine.(*DockerTaskEngine).PrefetchImages(ImagePrefetchRequest{
+		TaskDefinitions: []PrefetchTaskDefinition{{Family: "empty"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestPrefetchImagesFromFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	cfg := defaultTestConfig()
+	cfg.ImagePullBehavior = config.ImagePullAlwaysBehavior
+	cfg.ImagePrefetchPinDuration = time.Hour
+	ctrl, client, mockTime, taskEngine, _, imageManager, _, _ := mocks(t, ctx, cfg)
+	defer ctrl.Finish()
+
+	path := filepath.Join(t.TempDir(), "prefetch.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"taskDefinitions": [{
+			"family": "web",
+			"cpu": "256",
+			"containerDefinitions": [{"name": "app", "image": "busybox:latest", "essential": true}]
+		}]
+	}`), 0600))
+
+	mockTime.EXPECT().Now().Return(time.Now()).AnyTimes()
+	client.EXPECT().PullImage(gomock.Any(), "busybox:latest", nil, cfg.ImagePullTimeout).
+		Return(dockerapi.DockerContainerMetadata{})
+	imageManager.EXPECT().RecordImage("busybox:latest", true).
+		Return(&image.ImageState{Image: &image.Image{ImageID: "sha256:busybox"}}, nil)
+	imageManager.EXPECT().PinImage("busybox:latest", gomock.Any())
+
+	dockerTaskEngine := taskEngine.(*DockerTaskEngine)
+	require.NoError(t, dockerTaskEngine.PrefetchImagesFromFile(path))
+	prefetched := waitForPrefetch(t, dockerTaskEngine)
+	require.Len(t, prefetched, 1)
+	assert.Equal(t, ImagePrefetchPulled, prefetched[0].Status)
+
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0600))
+	assert.Error(t, dockerTaskEngine.PrefetchImagesFromFile(path))
+}
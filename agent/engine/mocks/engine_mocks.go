@@ -21,6 +21,7 @@ package mock_engine
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	container "github.com/aws/amazon-ecs-agent/agent/api/container"
 	task "github.com/aws/amazon-ecs-agent/agent/api/task"
@@ -346,6 +347,18 @@ func (mr *MockImageManagerMockRecorder) GetImageStateFromImageName(arg0 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageStateFromImageName", reflect.TypeOf((*MockImageManager)(nil).GetImageStateFromImageName), arg0)
 }
 
+// PinImage mocks base method.
+func (m *MockImageManager) PinImage(arg0 string, arg1 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PinImage", arg0, arg1)
+}
+
+// PinImage indicates an expected call of PinImage.
+func (mr *MockImageManagerMockRecorder) PinImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinImage", reflect.TypeOf((*MockImageManager)(nil).PinImage), arg0, arg1)
+}
+
 // RecordContainerReference mocks base method.
 func (m *MockImageManager) RecordContainerReference(arg0 *container.Container) error {
 	m.ctrl.T.Helper()
@@ -360,6 +373,21 @@ func (mr *MockImageManagerMockRecorder) RecordContainerReference(arg0 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordContainerReference", reflect.TypeOf((*MockImageManager)(nil).RecordContainerReference), arg0)
 }
 
+// RecordImage mocks base method.
+func (m *MockImageManager) RecordImage(arg0 string, arg1 bool) (*image.ImageState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordImage", arg0, arg1)
+	ret0, _ := ret[0].(*image.ImageState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordImage indicates an expected call of RecordImage.
+func (mr *MockImageManagerMockRecorder) RecordImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordImage", reflect.TypeOf((*MockImageManager)(nil).RecordImage), arg0, arg1)
+}
+
 // RemoveContainerReferenceFromImageState mocks base method.
 func (m *MockImageManager) RemoveContainerReferenceFromImageState(arg0 *container.Container) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,100 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// isSecretAsEnv returns whether a secret is passed to its container as an environment variable
+func isSecretAsEnv(s apicontainer.Secret) bool {
+	return s.Type == apicontainer.SecretTypeEnv
+}
+
+// startPeriodicSecretRotation retrieves the secrets of the running tasks again at the secret
+// rotation interval, until the context is cancelled. It returns immediately when the secret
+// files are disabled.
+func (engine *DockerTaskEngine) startPeriodicSecretRotation(ctx context.Context) {
+	if engine.cfg.SecretRotationInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(engine.cfg.SecretRotationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			engine.rotateSecrets()
+		}
+	}
+}
+
+// rotateSecrets retrieves the secrets of the running tasks with secret files again, and
+// rewrites the secret files of their containers whose values changed. The previous values
+// are kept when the secrets of a task can't be retrieved.
+func (engine *DockerTaskEngine) rotateSecrets() {
+	for _, task := range engine.state.AllTasks() {
+		if task.GetKnownStatus() != apitaskstatus.TaskRunning || task.GetDesiredStatus().Terminal() {
+			continue
+		}
+		containers := secretFilesContainers(task)
+		if len(containers) == 0 {
+			continue
+		}
+
+		if err := task.RefreshSecrets(); err != nil {
+			logger.Warn("Unable to refresh the secrets of task, keeping their previous values", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Error:  err,
+			})
+			continue
+		}
+		for _, container := range containers {
+			changed, err := engine.secretFilesManager.Update(task, container)
+			if err != nil {
+				logger.Warn("Unable to update the secret files of container", logger.Fields{
+					field.TaskID:    task.GetID(),
+					field.Container: container.Name,
+					field.Error:     err,
+				})
+				continue
+			}
+			if changed {
+				logger.Info("Updated the secret files of container with rotated secrets", logger.Fields{
+					field.TaskID:    task.GetID(),
+					field.Container: container.Name,
+				})
+			}
+		}
+	}
+}
+
+// secretFilesContainers returns the containers of a task that have secret files
+func secretFilesContainers(task *apitask.Task) []*apicontainer.Container {
+	var containers []*apicontainer.Container
+	for _, container := range task.Containers {
+		if !container.IsInternal() && container.HasSecret(isSecretAsEnv) {
+			containers = append(containers, container)
+		}
+	}
+	return containers
+}
@@ -0,0 +1,162 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	mock_secretfiles "github.com/aws/amazon-ecs-agent/agent/secretfiles/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const secretFilesTaskARN = "arn:aws:ecs:us-west-2:123456789012:task/cluster/task-id"
+
+func secretFilesTask(knownStatus apitaskstatus.TaskStatus) *apitask.Task {
+	secret := apicontainer.Secret{
+		Name:      "DB_PASSWORD",
+		ValueFrom: "arn:aws:secretsmanager:us-west-2:123456789012:secret:db-password",
+		Region:    "us-west-2",
+		Provider:  apicontainer.SecretProviderASM,
+		Type:      apicontainer.SecretTypeEnv,
+	}
+	asmRes := asmsecret.NewASMSecretResource(secretFilesTaskARN, nil, "", nil, nil)
+	asmRes.SetCachedSecretValue(secret.GetSecretResourceCacheKey(), "hunter2")
+	task := &apitask.Task{
+		Arn: secretFilesTaskARN,
+		Containers: []*apicontainer.Container{
+			{Name: "app", Secrets: []apicontainer.Secret{secret}},
+			{Name: "sidecar"},
+		},
+		ResourcesMapUnsafe: map[string][]taskresource.TaskResource{
+			asmsecret.ResourceName: {asmRes},
+		},
+	}
+	task.SetKnownStatus(knownStatus)
+	task.SetDesiredStatus(apitaskstatus.TaskRunning)
+	return task
+}
+
+func TestCreateContainerSecretFiles(t *testing.T) {
+	testcases := []struct {
+		name  string
+		error error
+	}{
+		{
+			name: "Secret files created",
+		},
+		{
+			name:  "Secret files error",
+			error: errors.New("not a tmpfs"),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			ctrl, client, _, privateTaskEngine, _, _, _, _ := mocks(t, ctx, &config.Config{})
+			defer ctrl.Finish()
+
+			taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+			taskEngine.cfg.SecretRotationInterval = time.Hour
+			secretFilesManager := mock_secretfiles.NewMockManager(ctrl)
+			taskEngine.secretFilesManager = secretFilesManager
+
+			task := secretFilesTask(apitaskstatus.TaskCreated)
+			info := types.Info{SecurityOptions: []string{"selinux"}}
+			client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+			client.EXPECT().Info(ctx, dockerclient.InfoTimeout).Return(info, nil)
+			secretFilesManager.EXPECT().Create(gomock.Any(), gomock.Any(), task, task.Containers[0], info.SecurityOptions).
+				Return(tc.error)
+			if tc.error == nil {
+				client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			}
+
+			metadata := taskEngine.createContainer(task, task.Containers[0])
+			if tc.error != nil {
+				assert.Error(t, metadata.Error)
+			} else {
+				assert.NoError(t, metadata.Error)
+			}
+		})
+	}
+}
+
+func TestCreateContainerWithoutSecretFiles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, privateTaskEngine, _, _, _, _ := mocks(t, ctx, &config.Config{})
+	defer ctrl.Finish()
+
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	taskEngine.cfg.SecretRotationInterval = time.Hour
+	taskEngine.secretFilesManager = mock_secretfiles.NewMockManager(ctrl)
+
+	task := secretFilesTask(apitaskstatus.TaskCreated)
+	client.EXPECT().APIVersion().Return(defaultDockerClientAPIVersion, nil).AnyTimes()
+	client.EXPECT().CreateContainer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+
+	metadata := taskEngine.createContainer(task, task.Containers[1])
+	assert.NoError(t, metadata.Error)
+}
+
+func TestRotateSecrets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, _, _, privateTaskEngine, credentialsManager, _, _, _ := mocks(t, ctx, &config.Config{})
+	defer ctrl.Finish()
+
+	taskEngine, _ := privateTaskEngine.(*DockerTaskEngine)
+	secretFilesManager := mock_secretfiles.NewMockManager(ctrl)
+	taskEngine.secretFilesManager = secretFilesManager
+
+	// The secret files of the containers of a task are updated once its secrets were refreshed
+	runningTask := secretFilesTask(apitaskstatus.TaskRunning)
+	delete(runningTask.ResourcesMapUnsafe, asmsecret.ResourceName)
+	// The secret files are kept when the secrets of the task can't be refreshed
+	failingTask := secretFilesTask(apitaskstatus.TaskRunning)
+	failingTask.Arn = "arn:aws:ecs:us-west-2:123456789012:task/cluster/failing-task-id"
+	failingTask.ResourcesMapUnsafe[asmsecret.ResourceName] = []taskresource.TaskResource{
+		asmsecret.NewASMSecretResource(failingTask.Arn, nil, "exec-creds-id", credentialsManager, nil),
+	}
+	stoppingTask := secretFilesTask(apitaskstatus.TaskRunning)
+	stoppingTask.Arn = "arn:aws:ecs:us-west-2:123456789012:task/cluster/stopping-task-id"
+	stoppingTask.SetDesiredStatus(apitaskstatus.TaskStopped)
+	createdTask := secretFilesTask(apitaskstatus.TaskCreated)
+	createdTask.Arn = "arn:aws:ecs:us-west-2:123456789012:task/cluster/created-task-id"
+	for _, task := range []*apitask.Task{runningTask, failingTask, stoppingTask, createdTask} {
+		taskEngine.state.AddTask(task)
+	}
+
+	credentialsManager.EXPECT().GetTaskCredentials("exec-creds-id").Return(credentials.TaskIAMRoleCredentials{}, false)
+	secretFilesManager.EXPECT().Update(runningTask, runningTask.Containers[0]).Return(true, nil)
+	taskEngine.rotateSecrets()
+}
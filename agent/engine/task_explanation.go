@@ -0,0 +1,174 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+)
+
+// TaskExplanation describes what the containers and resources of a task are waiting for
+// to reach their desired status
+type TaskExplanation struct {
+	TaskARN          string    `json:"TaskARN"`
+	KnownStatus      string    `json:"KnownStatus"`
+	DesiredStatus    string    `json:"DesiredStatus"`
+	KnownStatusSince time.Time `json:"KnownStatusSince"`
+	// HostResources is set when the task is waiting for host resources before its
+	// containers can be created
+	HostResources *HostResourcesWait  `json:"HostResources,omitempty"`
+	Containers    []EntityExplanation `json:"Containers"`
+	Resources     []EntityExplanation `json:"Resources,omitempty"`
+}
+
+// HostResourcesWait describes a task waiting in the queue of tasks waiting for host resources
+type HostResourcesWait struct {
+	// QueuePosition is the position of the task in the queue, starting at 0
+	QueuePosition int `json:"QueuePosition"`
+	// UnavailableResources are the host resources the task needs that are not available
+	UnavailableResources []string  `json:"UnavailableResources,omitempty"`
+	Error                string    `json:"Error,omitempty"`
+	WaitingSince         time.Time `json:"WaitingSince"`
+	WaitingFor           string    `json:"WaitingFor"`
+}
+
+// EntityExplanation describes what a container or a task resource is waiting for to
+// transition to its next status
+type EntityExplanation struct {
+	Name          string                    `json:"Name"`
+	KnownStatus   string                    `json:"KnownStatus"`
+	DesiredStatus string                    `json:"DesiredStatus"`
+	BlockedBy     []dependencygraph.Blocker `json:"BlockedBy,omitempty"`
+	// WaitingSince is the time since when the transitions of the container or resource
+	// have been blocked by its dependencies
+	WaitingSince *time.Time `json:"WaitingSince,omitempty"`
+	WaitingFor   string     `json:"WaitingFor,omitempty"`
+}
+
+// ExplainTask returns what the containers and resources of a task are waiting for, or
+// false if the task isn't managed by the engine.
+func (engine *DockerTaskEngine) ExplainTask(taskARN string) (*TaskExplanation, bool) {
+	engine.tasksLock.RLock()
+	mtask, ok := engine.managedTasks[taskARN]
+	engine.tasksLock.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	now := time.Now()
+	explanation := &TaskExplanation{
+		TaskARN:          mtask.Arn,
+		KnownStatus:      mtask.GetKnownStatus().String(),
+		DesiredStatus:    mtask.GetDesiredStatus().String(),
+		KnownStatusSince: mtask.GetKnownStatusTime(),
+		HostResources:    engine.explainHostResources(mtask, now),
+		Containers:       []EntityExplanation{},
+	}
+
+	executionCredentialsID := mtask.GetExecutionCredentialsID()
+	resources := mtask.GetResources()
+	for _, container := range mtask.Containers {
+		entity := EntityExplanation{
+			Name:          container.Name,
+			KnownStatus:   container.GetKnownStatus().String(),
+			DesiredStatus: container.GetDesiredStatus().String(),
+			BlockedBy: dependencygraph.ExplainContainerDependencies(container, mtask.Containers,
+				executionCredentialsID, mtask.credentialsManager, resources, mtask.cfg),
+		}
+		mtask.setWaitingTime(&entity, containerEntityKey(container.Name), now)
+		explanation.Containers = append(explanation.Containers, entity)
+	}
+	for _, resource := range resources {
+		entity := EntityExplanation{
+			Name:          resource.GetName(),
+			KnownStatus:   resource.StatusString(resource.GetKnownStatus()),
+			DesiredStatus: resource.StatusString(resource.GetDesiredStatus()),
+			BlockedBy:     dependencygraph.ExplainTaskResourceDependencies(resource, mtask.Containers),
+		}
+		mtask.setWaitingTime(&entity, resourceEntityKey(resource.GetName()), now)
+		explanation.Resources = append(explanation.Resources, entity)
+	}
+	return explanation, true
+}
+
+// explainHostResources returns the position of the task in the queue of tasks waiting for
+// host resources, and the resources it's waiting for, or nil if the task isn't queued.
+func (engine *DockerTaskEngine) explainHostResources(mtask *managedTask, now time.Time) *HostResourcesWait {
+	engine.waitingTasksLock.Lock()
+	position := -1
+	for i, task := range engine.waitingTaskQueue {
+		if task == mtask {
+			position = i
+			break
+		}
+	}
+	enqueuedAt := mtask.enqueuedAt
+	engine.waitingTasksLock.Unlock()
+	if position < 0 {
+		return nil
+	}
+
+	wait := &HostResourcesWait{
+		QueuePosition: position,
+		WaitingSince:  enqueuedAt,
+		WaitingFor:    now.Sub(enqueuedAt).Round(time.Second).String(),
+	}
+	unavailable, err := engine.hostResourceManager.unavailableResources(mtask.ToHostResources())
+	if err != nil {
+		wait.Error = err.Error()
+	}
+	wait.UnavailableResources = unavailable
+	return wait
+}
+
+func containerEntityKey(name string) string {
+	return "container/" + name
+}
+
+func resourceEntityKey(name string) string {
+	return "resource/" + name
+}
+
+// recordBlocked records whether the transitions of a container or resource, identified by
+// its entity key, are blocked by its dependencies, keeping the time since when it's blocked.
+func (mtask *managedTask) recordBlocked(key string, blocked bool) {
+	mtask.blockedSinceLock.Lock()
+	defer mtask.blockedSinceLock.Unlock()
+	if !blocked {
+		delete(mtask.blockedSince, key)
+		return
+	}
+	if mtask.blockedSince == nil {
+		mtask.blockedSince = make(map[string]time.Time)
+	}
+	if _, ok := mtask.blockedSince[key]; !ok {
+		mtask.blockedSince[key] = time.Now()
+	}
+}
+
+// setWaitingTime sets how long a blocked container or resource has been waiting
+func (mtask *managedTask) setWaitingTime(entity *EntityExplanation, key string, now time.Time) {
+	if len(entity.BlockedBy) == 0 {
+		return
+	}
+	mtask.blockedSinceLock.RLock()
+	since, ok := mtask.blockedSince[key]
+	mtask.blockedSinceLock.RUnlock()
+	if !ok {
+		return
+	}
+	entity.WaitingSince = &since
+	entity.WaitingFor = now.Sub(since).Round(time.Second).String()
+}
@@ -0,0 +1,89 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainTask(t *testing.T) {
+	task := &apitask.Task{
+		Arn:                 "arn:aws:ecs:us-west-2:123456789012:task/cluster/abc",
+		CPU:                 4,
+		DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		Containers: []*apicontainer.Container{
+			{
+				Name:                "init",
+				DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+				KnownStatusUnsafe:   apicontainerstatus.ContainerRunning,
+			},
+			{
+				Name:                "app",
+				DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+				KnownStatusUnsafe:   apicontainerstatus.ContainerPulled,
+				DependsOnUnsafe:     []apicontainer.DependsOn{{ContainerName: "init", Condition: "SUCCESS"}},
+			},
+		},
+	}
+	mtask := &managedTask{Task: task, cfg: &config.Config{}}
+	taskEngine := &DockerTaskEngine{
+		managedTasks:        map[string]*managedTask{task.Arn: mtask},
+		waitingTaskQueue:    []*managedTask{{Task: &apitask.Task{}}, mtask},
+		hostResourceManager: getTestHostResourceManager(int32(2048), int32(2048), nil, nil, nil),
+	}
+	mtask.enqueuedAt = time.Now().Add(-time.Minute)
+	mtask.recordBlocked(containerEntityKey("app"), true)
+
+	_, ok := taskEngine.ExplainTask("unknown")
+	assert.False(t, ok)
+
+	explanation, ok := taskEngine.ExplainTask(task.Arn)
+	require.True(t, ok)
+	assert.Equal(t, task.Arn, explanation.TaskARN)
+	assert.Equal(t, apitaskstatus.TaskRunning.String(), explanation.DesiredStatus)
+
+	require.NotNil(t, explanation.HostResources)
+	assert.Equal(t, 1, explanation.HostResources.QueuePosition)
+	assert.Equal(t, []string{"CPU"}, explanation.HostResources.UnavailableResources)
+	assert.Equal(t, "1m0s", explanation.HostResources.WaitingFor)
+
+	require.Len(t, explanation.Containers, 2)
+	assert.Empty(t, explanation.Containers[0].BlockedBy)
+	assert.Nil(t, explanation.Containers[0].WaitingSince)
+	app := explanation.Containers[1]
+	require.Len(t, app.BlockedBy, 1)
+	assert.Equal(t, dependencygraph.BlockerContainerOrdering, app.BlockedBy[0].Type)
+	assert.Equal(t, "init", app.BlockedBy[0].Name)
+	assert.NotNil(t, app.WaitingSince)
+	assert.NotEmpty(t, app.WaitingFor)
+
+	// once unblocked, the waiting time is reset
+	mtask.recordBlocked(containerEntityKey("app"), false)
+	explanation, _ = taskEngine.ExplainTask(task.Arn)
+	assert.Nil(t, explanation.Containers[1].WaitingSince)
+}
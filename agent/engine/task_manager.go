@@ -43,6 +43,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime"
 )
@@ -170,6 +171,21 @@ type managedTask struct {
 	// verification logic gets executed to set it to a low interval
 	steadyStatePollInterval       time.Duration
 	steadyStatePollIntervalJitter time.Duration
+
+	// pendingContainerRestarts holds the names of the containers whose restart is
+	// being delayed by their restart backoff.
+	pendingContainerRestarts map[string]struct{}
+
+	// enqueuedAt is the time the task was queued waiting for host resources, and
+	// queuePriority its priority in the queue. They're protected by the waitingTasksLock
+	// of the engine, as is queueWaitMetric, which records how long the task waited.
+	enqueuedAt      time.Time
+	queuePriority   int
+	queueWaitMetric metrics.Entry
+	// blockedSince holds the time since when each container and resource of the task
+	// has been blocked by its dependencies, keyed by containerEntityKey or resourceEntityKey
+	blockedSince     map[string]time.Time
+	blockedSinceLock sync.RWMutex
 }
 
 // newManagedTask is a method on DockerTaskEngine to create a new managedTask.
@@ -194,6 +210,7 @@ func (engine *DockerTaskEngine) newManagedTask(task *apitask.Task) *managedTask
 		dockerClient:                  engine.client,
 		steadyStatePollInterval:       engine.taskSteadyStatePollInterval,
 		steadyStatePollIntervalJitter: engine.taskSteadyStatePollIntervalJitter,
+		pendingContainerRestarts:      make(map[string]struct{}),
 	}
 	engine.managedTasks[task.Arn] = t
 	return t
@@ -219,6 +236,9 @@ func (mtask *managedTask) overseeTask() {
 	// If this was a 'state restore', send all unsent statuses
 	mtask.emitCurrentStatus()
 
+	// If this was a 'state restore', schedule the container restarts that were pending
+	mtask.reschedulePendingContainerRestarts()
+
 	// Main infinite loop. This is where we receive messages and dispatch work.
 	for {
 		if mtask.shouldExit() {
@@ -473,7 +493,13 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 
 		// Only update container metadata when status stays RUNNING
 		if event.Status == containerKnownStatus && event.Status == apicontainerstatus.ContainerRunning {
+			// Rewrite the metadata file if the network bindings of the container changed since it started
+			portBindingsChanged := len(event.DockerContainerMetadata.PortBindings) != 0 &&
+				!apicontainer.PortBindingsEqual(event.DockerContainerMetadata.PortBindings, container.GetKnownPortBindings())
 			updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task)
+			if portBindingsChanged {
+				mtask.engine.refreshMetadataFile(mtask.Task, container)
+			}
 		}
 		return
 	}
@@ -488,6 +514,18 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 		shouldRestart, reason := container.RestartTracker.ShouldRestart(exitCode, container.GetStartedAt(),
 			container.GetDesiredStatus())
 		if shouldRestart {
+			if restartDelay := container.RestartTracker.RestartDelay(); restartDelay > 0 {
+				mtask.scheduleContainerRestart(containerChange, restartDelay)
+				logger.Info("Delaying container restart", eventLogFields,
+					logger.Fields{
+						"restartCount":  container.RestartTracker.GetRestartCount(),
+						"nextRestartAt": container.RestartTracker.GetNextRestartAt().UTC().Format(time.RFC3339),
+					})
+				// return here because the container will be restarted once the backoff has
+				// elapsed, and we don't want to complete the rest of the "container stop" workflow
+				return
+			}
+			delete(mtask.pendingContainerRestarts, container.Name)
 			container.RestartTracker.RecordRestart()
 			resp := mtask.engine.startContainer(mtask.Task, container)
 			if resp.Error == nil {
@@ -513,6 +551,9 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 					"lastRestartAt": container.RestartTracker.GetLastRestartAt().UTC().Format(time.RFC3339),
 					"reason":        reason,
 				})
+			if container.RestartTracker.ShouldStopTask() {
+				mtask.stopTaskOnMaxRestartAttempts(container)
+			}
 		}
 	}
 
@@ -557,6 +598,72 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 	}
 }
 
+// scheduleContainerRestart emits the container's stop event again once the restart
+// backoff has elapsed, at which point the container is restarted. At most one restart
+// is scheduled for a container at a time.
+func (mtask *managedTask) scheduleContainerRestart(containerChange dockerContainerChange, delay time.Duration) {
+	containerName := containerChange.container.Name
+	if _, ok := mtask.pendingContainerRestarts[containerName]; ok {
+		return
+	}
+	if mtask.pendingContainerRestarts == nil {
+		mtask.pendingContainerRestarts = make(map[string]struct{})
+	}
+	mtask.pendingContainerRestarts[containerName] = struct{}{}
+	go func() {
+		select {
+		case <-mtask.ctx.Done():
+		case <-mtask.time().After(delay):
+			mtask.emitDockerContainerChange(containerChange)
+		}
+	}()
+}
+
+// reschedulePendingContainerRestarts schedules the restarts of the containers that
+// exited while their restart was being delayed by the restart backoff, when the task is
+// restored from the state. The restart is due at the deadline saved in the restart
+// tracker, or right away if it has passed.
+func (mtask *managedTask) reschedulePendingContainerRestarts() {
+	for _, container := range mtask.Containers {
+		if !container.HasPendingRestart() || container.GetKnownStatus() != apicontainerstatus.ContainerRunning {
+			continue
+		}
+		logger.Info("Rescheduling delayed container restart", logger.Fields{
+			field.TaskID:    mtask.GetID(),
+			field.Container: container.Name,
+			"nextRestartAt": container.RestartTracker.GetNextRestartAt().UTC().Format(time.RFC3339),
+		})
+		mtask.scheduleContainerRestart(dockerContainerChange{
+			container: container,
+			event: dockerapi.DockerContainerChangeEvent{
+				Status: apicontainerstatus.ContainerStopped,
+				DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+					DockerID:        container.GetRuntimeID(),
+					ExitCode:        container.GetKnownExitCode(),
+					NetworkMode:     container.GetNetworkMode(),
+					NetworkSettings: container.GetNetworkSettings(),
+				},
+			},
+		}, container.RestartTracker.RestartDelay())
+	}
+}
+
+// stopTaskOnMaxRestartAttempts stops the task once one of its containers has exited
+// after exhausting its restart attempts, if the container's restart policy says so.
+func (mtask *managedTask) stopTaskOnMaxRestartAttempts(container *apicontainer.Container) {
+	if !mtask.SetDesiredStatusStopped() {
+		return
+	}
+	logger.Info("Container exhausted its restart attempts; stopping task", logger.Fields{
+		field.TaskID:    mtask.GetID(),
+		field.Container: container.Name,
+		"restartCount":  container.RestartTracker.GetRestartCount(),
+	})
+	mtask.SetTerminalReason(fmt.Sprintf("container %s exited after %d restart attempts",
+		container.Name, container.RestartTracker.GetRestartCount()))
+	mtask.engine.saveTaskData(mtask.Task)
+}
+
 // handleResourceStateChange attempts to update resource's known status depending on
 // the current status and errors during transition
 func (mtask *managedTask) handleResourceStateChange(resChange resourceStateChange) {
@@ -1158,6 +1265,8 @@ func (mtask *managedTask) startContainerTransitions(transitionFunc containerTran
 	transitions := make(map[string]apicontainerstatus.ContainerStatus)
 	for _, cont := range mtask.Containers {
 		transition := mtask.containerNextState(cont)
+		mtask.recordBlocked(containerEntityKey(cont.Name),
+			transition.reason != nil && transition.reason != dependencygraph.ContainerPastDesiredStatusErr)
 		if transition.reason != nil {
 			if transition.reason.IsTerminal() {
 				mtask.handleTerminalDependencyError(cont, transition.reason)
@@ -1244,10 +1353,12 @@ func (mtask *managedTask) startResourceTransitions(transitionFunc resourceTransi
 					field.KnownStatus:   res.StatusString(knownStatus),
 					field.DesiredStatus: res.StatusString(desiredStatus),
 				})
+			mtask.recordBlocked(resourceEntityKey(res.GetName()), false)
 			continue
 		}
 		anyCanTransition = true
 		transition := mtask.resourceNextState(res)
+		mtask.recordBlocked(resourceEntityKey(res.GetName()), transition.reason != nil)
 		// If the resource is already in a transition, skip
 		if transition.actionRequired && !res.SetAppliedStatus(transition.nextState) {
 			// At least one resource is able to be moved forwards, so we're not deadlocked
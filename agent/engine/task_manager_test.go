@@ -1814,6 +1814,7 @@ func TestHandleContainerChangeUpdateMetadataRedundant(t *testing.T) {
 		ctx:                        context.TODO(),
 		engine: &DockerTaskEngine{
 			dataClient: data.NewNoopClient(),
+			cfg:        &config.Config{},
 		},
 	}
 	// Discard all the statechange events
@@ -2071,6 +2072,170 @@ func TestHandleContainerChangeStopped_WithRestartPolicy_DesiredStopped(t *testin
 	assert.Equal(t, 0, container.RestartTracker.GetRestartCount(), "After stop event, container should NOT have been restarted")
 }
 
+func TestHandleContainerChangeStopped_WithRestartPolicy_Backoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	containerChangeEventStream := eventstream.NewEventStream(t.Name(), ctx)
+	containerChangeEventStream.StartListening()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	defer ctrl.Finish()
+
+	cfg := getTestConfig()
+	hostResourceManager := NewHostResourceManager(getTestHostResources())
+	dockerMessages := make(chan dockerContainerChange)
+	mTask := &managedTask{
+		Task:                       testdata.LoadTask("sleep5RestartPolicy"),
+		containerChangeEventStream: containerChangeEventStream,
+		stateChangeEvents:          make(chan statechange.Event),
+		dockerMessages:             dockerMessages,
+		ctx:                        ctx,
+		_time:                      mockTime,
+		engine: &DockerTaskEngine{
+			ctx:                 context.TODO(),
+			cfg:                 &cfg,
+			dataClient:          data.NewNoopClient(),
+			hostResourceManager: &hostResourceManager,
+			client:              mockClient,
+		},
+	}
+	// Discard all the statechange events
+	defer discardEvents(mTask.stateChangeEvents)()
+
+	mTask.SetKnownStatus(apitaskstatus.TaskRunning)
+	mTask.SetSentStatus(apitaskstatus.TaskRunning)
+	container := mTask.Containers[0]
+	container.RestartPolicy.BackoffInitialDelay = 10
+	container.RestartTracker = restart.NewRestartTracker(*container.RestartPolicy)
+	container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+
+	exitCode := int(100)
+	containerChange := dockerContainerChange{
+		container: container,
+		event: dockerapi.DockerContainerChangeEvent{
+			Status: apicontainerstatus.ContainerStopped,
+			DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+				ExitCode: &exitCode,
+			},
+		},
+	}
+
+	backoffElapsed := make(chan time.Time)
+	mockTime.EXPECT().After(10 * time.Second).Return(backoffElapsed)
+	mockClient.EXPECT().StartContainer(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mTask.handleContainerChange(containerChange)
+	// duplicate stop events must not schedule another restart
+	mTask.handleContainerChange(containerChange)
+
+	assert.Equal(t, 0, container.RestartTracker.GetRestartCount(), "Container should not be restarted before the backoff elapses")
+	assert.False(t, container.RestartTracker.GetNextRestartAt().IsZero(), "Expected a pending restart")
+	assert.Equal(t, apicontainerstatus.ContainerRunning, container.GetKnownStatus())
+	assert.Equal(t, apitaskstatus.TaskRunning.String(), mTask.GetDesiredStatus().String())
+
+	// once the backoff elapses, the stop event is emitted again so the container gets restarted
+	backoffElapsed <- time.Now()
+	select {
+	case change := <-dockerMessages:
+		assert.Equal(t, container, change.container)
+		assert.Equal(t, apicontainerstatus.ContainerStopped, change.event.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the delayed restart event")
+	}
+}
+
+func TestReschedulePendingContainerRestarts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl := gomock.NewController(t)
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	defer ctrl.Finish()
+
+	dockerMessages := make(chan dockerContainerChange)
+	mTask := &managedTask{
+		Task:                     testdata.LoadTask("sleep5RestartPolicy"),
+		dockerMessages:           dockerMessages,
+		ctx:                      ctx,
+		_time:                    mockTime,
+		pendingContainerRestarts: make(map[string]struct{}),
+	}
+	// The restart of the container was pending when the task was saved
+	container := mTask.Containers[0]
+	container.RestartPolicy.BackoffInitialDelay = 60
+	container.RestartTracker = restart.NewRestartTracker(*container.RestartPolicy)
+	container.RestartTracker.NextRestartAt = time.Now().Add(30 * time.Second)
+	container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	exitCode := 100
+	container.SetKnownExitCode(&exitCode)
+
+	backoffElapsed := make(chan time.Time)
+	mockTime.EXPECT().After(gomock.Any()).Do(func(delay time.Duration) {
+		assert.True(t, delay > 0 && delay <= 30*time.Second, "Expected the remaining backoff, got %v", delay)
+	}).Return(backoffElapsed)
+	mTask.reschedulePendingContainerRestarts()
+
+	backoffElapsed <- time.Now()
+	select {
+	case change := <-dockerMessages:
+		assert.Equal(t, container, change.container)
+		assert.Equal(t, apicontainerstatus.ContainerStopped, change.event.Status)
+		assert.Equal(t, &exitCode, change.event.DockerContainerMetadata.ExitCode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the rescheduled restart event")
+	}
+}
+
+func TestHandleContainerChangeStopped_WithRestartPolicy_MaxAttemptsStopsTask(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	containerChangeEventStream := eventstream.NewEventStream(t.Name(), ctx)
+	containerChangeEventStream.StartListening()
+
+	cfg := getTestConfig()
+	hostResourceManager := NewHostResourceManager(getTestHostResources())
+	mTask := &managedTask{
+		Task:                       testdata.LoadTask("sleep5RestartPolicy"),
+		containerChangeEventStream: containerChangeEventStream,
+		stateChangeEvents:          make(chan statechange.Event),
+		ctx:                        context.TODO(),
+		engine: &DockerTaskEngine{
+			ctx:                 context.TODO(),
+			cfg:                 &cfg,
+			dataClient:          data.NewNoopClient(),
+			hostResourceManager: &hostResourceManager,
+		},
+	}
+	// Discard all the statechange events
+	defer discardEvents(mTask.stateChangeEvents)()
+
+	mTask.SetKnownStatus(apitaskstatus.TaskRunning)
+	mTask.SetSentStatus(apitaskstatus.TaskRunning)
+	container := mTask.Containers[0]
+	// make the container non-essential so that only the restart policy can stop the task
+	container.Essential = false
+	container.RestartPolicy.MaxRestartAttempts = 1
+	container.RestartPolicy.StopTaskOnMaxRestartAttempts = aws.Bool(true)
+	container.RestartTracker = restart.NewRestartTracker(*container.RestartPolicy)
+	container.RestartTracker.RecordRestart()
+
+	exitCode := int(100)
+	containerChange := dockerContainerChange{
+		container: container,
+		event: dockerapi.DockerContainerChangeEvent{
+			Status: apicontainerstatus.ContainerStopped,
+			DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+				ExitCode: &exitCode,
+			},
+		},
+	}
+
+	mTask.handleContainerChange(containerChange)
+	assert.Equal(t, 1, container.RestartTracker.GetRestartCount(), "Container should not be restarted past its maximum restart attempts")
+	assert.Equal(t, apitaskstatus.TaskStopped.String(), mTask.GetDesiredStatus().String(), "Expected task to be stopped once the container exhausted its restart attempts")
+	assert.Contains(t, mTask.GetTerminalReason(), "restart attempts")
+}
+
 func TestWaitForResourceTransition(t *testing.T) {
 	task := &managedTask{
 		Task: &apitask.Task{
@@ -0,0 +1,213 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// TaskTagsGetter gets the tags of a task, to find its priority in the queue of tasks
+// waiting for host resources
+type TaskTagsGetter interface {
+	GetResourceTags(resourceArn string) ([]types.Tag, error)
+}
+
+// TaskQueue is a snapshot of the queue of tasks waiting for host resources
+type TaskQueue struct {
+	Policy string       `json:"Policy"`
+	Tasks  []QueuedTask `json:"Tasks"`
+}
+
+// QueuedTask is a task waiting for host resources
+type QueuedTask struct {
+	TaskARN string `json:"TaskARN"`
+	// Position is the position of the task in the queue, starting at 0
+	Position int `json:"Position"`
+	Priority int `json:"Priority"`
+	// UnavailableResources are the host resources the task needs that are not available
+	UnavailableResources []string  `json:"UnavailableResources,omitempty"`
+	Error                string    `json:"Error,omitempty"`
+	WaitingSince         time.Time `json:"WaitingSince"`
+	WaitingFor           string    `json:"WaitingFor"`
+}
+
+// SetTaskTagsGetter sets the getter used to find the priority of the tasks waiting for
+// host resources, from their TaskQueuePriorityTag tag, when the task queue policy is priority.
+func (engine *DockerTaskEngine) SetTaskTagsGetter(getter TaskTagsGetter) {
+	engine.taskTagsGetter = getter
+}
+
+// SetMetricsFactory sets the factory used to record the task queue depth and wait time.
+func (engine *DockerTaskEngine) SetMetricsFactory(metricsFactory metrics.EntryFactory) {
+	engine.metricsFactory = metricsFactory
+}
+
+func (engine *DockerTaskEngine) getMetricsFactory() metrics.EntryFactory {
+	if engine.metricsFactory == nil {
+		return metrics.NewNopEntryFactory()
+	}
+	return engine.metricsFactory
+}
+
+func (engine *DockerTaskEngine) taskQueuePolicy() config.TaskQueuePolicyType {
+	if engine.cfg == nil {
+		return config.TaskQueueFIFOPolicy
+	}
+	return engine.cfg.TaskQueuePolicy
+}
+
+// Injection point for testing purposes
+var taskQueuePriorityTimeout = 5 * time.Second
+
+// taskTagsResult is the result of getting the tags of a task
+type taskTagsResult struct {
+	tags []types.Tag
+	err  error
+}
+
+// canStartImmediately returns whether a task being queued would be started right away: no
+// task is waiting before it and its host resources are available. Its priority doesn't
+// matter then.
+func (engine *DockerTaskEngine) canStartImmediately(task *managedTask) bool {
+	engine.waitingTasksLock.RLock()
+	queued := len(engine.waitingTaskQueue)
+	engine.waitingTasksLock.RUnlock()
+	if queued > 0 {
+		return false
+	}
+	unavailable, err := engine.hostResourceManager.unavailableResources(task.ToHostResources())
+	return err == nil && len(unavailable) == 0
+}
+
+// getTaskQueuePriority returns the priority of a task from its TaskQueuePriorityTag tag.
+// Tasks without a valid priority, or whose tags can't be retrieved within
+// taskQueuePriorityTimeout, have priority 0.
+func (engine *DockerTaskEngine) getTaskQueuePriority(task *managedTask) int {
+	if engine.taskTagsGetter == nil || engine.cfg.TaskQueuePriorityTag == "" {
+		return 0
+	}
+	result := make(chan taskTagsResult, 1)
+	go func() {
+		tags, err := engine.taskTagsGetter.GetResourceTags(task.Arn)
+		result <- taskTagsResult{tags: tags, err: err}
+	}()
+	var tags []types.Tag
+	var err error
+	select {
+	case res := <-result:
+		tags, err = res.tags, res.err
+	case <-time.After(taskQueuePriorityTimeout):
+		err = fmt.Errorf("timed out after %s", taskQueuePriorityTimeout)
+	}
+	if err != nil {
+		logger.Warn("Unable to get the task tags for its queue priority, using the default priority", logger.Fields{
+			field.TaskARN: task.Arn,
+			field.Error:   err,
+		})
+		return 0
+	}
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) != engine.cfg.TaskQueuePriorityTag {
+			continue
+		}
+		priority, err := strconv.Atoi(aws.ToString(tag.Value))
+		if err != nil {
+			logger.Warn("Invalid task queue priority, using the default priority", logger.Fields{
+				field.TaskARN: task.Arn,
+				"tag":         aws.ToString(tag.Key),
+				"value":       aws.ToString(tag.Value),
+			})
+			return 0
+		}
+		return priority
+	}
+	return 0
+}
+
+// insertQueuedTask adds a task to the queue of tasks waiting for host resources. With the
+// priority policy, the queue is kept ordered by decreasing priority, and tasks with the same
+// priority stay in the order they were queued. Otherwise the task is added at the end.
+func insertQueuedTask(queue []*managedTask, task *managedTask, policy config.TaskQueuePolicyType) []*managedTask {
+	if policy != config.TaskQueuePriorityPolicy {
+		return append(queue, task)
+	}
+	position := sort.Search(len(queue), func(i int) bool {
+		return queue[i].queuePriority < task.queuePriority
+	})
+	queue = append(queue, nil)
+	copy(queue[position+1:], queue[position:])
+	queue[position] = task
+	return queue
+}
+
+// startQueuedTasks goes through the queue of tasks waiting for host resources and starts the
+// tasks whose resources can be consumed. With the first-fit policy, the tasks that don't fit
+// are skipped, otherwise the first task that doesn't fit blocks the tasks after it.
+func (engine *DockerTaskEngine) startQueuedTasks() {
+	engine.waitingTasksLock.RLock()
+	queue := make([]*managedTask, len(engine.waitingTaskQueue))
+	copy(queue, engine.waitingTaskQueue)
+	engine.waitingTasksLock.RUnlock()
+
+	backfill := engine.taskQueuePolicy() == config.TaskQueueFirstFitPolicy
+	for _, task := range queue {
+		// Only this goroutine dequeues tasks, so the tasks of the snapshot are still queued
+		if !engine.tryDequeueWaitingTasks(task) && !backfill {
+			return
+		}
+	}
+}
+
+// GetTaskQueue returns the tasks waiting for host resources, in the order they will be
+// considered for starting, along with the host resources they are waiting for.
+func (engine *DockerTaskEngine) GetTaskQueue() TaskQueue {
+	now := time.Now()
+	engine.waitingTasksLock.RLock()
+	queue := TaskQueue{
+		Policy: engine.taskQueuePolicy().String(),
+		Tasks:  make([]QueuedTask, 0, len(engine.waitingTaskQueue)),
+	}
+	tasks := make([]*managedTask, 0, len(engine.waitingTaskQueue))
+	for i, task := range engine.waitingTaskQueue {
+		queue.Tasks = append(queue.Tasks, QueuedTask{
+			TaskARN:      task.Arn,
+			Position:     i,
+			Priority:     task.queuePriority,
+			WaitingSince: task.enqueuedAt,
+			WaitingFor:   now.Sub(task.enqueuedAt).Round(time.Second).String(),
+		})
+		tasks = append(tasks, task)
+	}
+	engine.waitingTasksLock.RUnlock()
+
+	for i, task := range tasks {
+		unavailable, err := engine.hostResourceManager.unavailableResources(task.ToHostResources())
+		if err != nil {
+			queue.Tasks[i].Error = err.Error()
+		}
+		queue.Tasks[i].UnavailableResources = unavailable
+	}
+	return queue
+}
@@ -0,0 +1,220 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTaskTagsGetter struct {
+	tags map[string][]types.Tag
+	// calls counts the calls of GetResourceTags
+	calls int
+	// block, if set, blocks GetResourceTags until it's closed
+	block chan struct{}
+}
+
+func (g *fakeTaskTagsGetter) GetResourceTags(resourceArn string) ([]types.Tag, error) {
+	g.calls++
+	if g.block != nil {
+		<-g.block
+	}
+	tags, ok := g.tags[resourceArn]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return tags, nil
+}
+
+func newTaskQueueTestEngine(policy config.TaskQueuePolicyType) *DockerTaskEngine {
+	taskEngine := &DockerTaskEngine{
+		cfg: &config.Config{
+			TaskQueuePolicy:      policy,
+			TaskQueuePriorityTag: config.DefaultTaskQueuePriorityTag,
+		},
+		hostResourceManager: getTestHostResourceManager(int32(2048), int32(2048), nil, nil, nil),
+		metricsFactory:      metrics.NewPrometheusEntryFactory(),
+	}
+	// Half of the host CPU is used by a running task
+	taskEngine.hostResourceManager.consume("running", getTestTaskResourceMap(int32(1024), int32(0), nil, nil, nil))
+	return taskEngine
+}
+
+func newTaskQueueTestTask(taskEngine *DockerTaskEngine, arn string, cpu float64) *managedTask {
+	return &managedTask{
+		Task: &apitask.Task{
+			Arn:                 arn,
+			CPU:                 cpu,
+			DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		},
+		engine:                    taskEngine,
+		consumedHostResourceEvent: make(chan struct{}, 1),
+	}
+}
+
+func queuedTaskARNs(taskEngine *DockerTaskEngine) []string {
+	var arns []string
+	for _, task := range taskEngine.GetTaskQueue().Tasks {
+		arns = append(arns, task.TaskARN)
+	}
+	return arns
+}
+
+func TestStartQueuedTasksFIFO(t *testing.T) {
+	taskEngine := newTaskQueueTestEngine(config.TaskQueueFIFOPolicy)
+	large := newTaskQueueTestTask(taskEngine, "large", 1.5)
+	small := newTaskQueueTestTask(taskEngine, "small", 0.5)
+	taskEngine.enqueueTask(large)
+	taskEngine.enqueueTask(small)
+
+	taskEngine.startQueuedTasks()
+	// the large task doesn't fit and blocks the small task
+	assert.Equal(t, []string{"large", "small"}, queuedTaskARNs(taskEngine))
+	assert.Len(t, small.consumedHostResourceEvent, 0)
+
+	taskEngine.hostResourceManager.release("running", getTestTaskResourceMap(int32(1024), int32(0), nil, nil, nil))
+	taskEngine.startQueuedTasks()
+	assert.Empty(t, queuedTaskARNs(taskEngine))
+	assert.Len(t, large.consumedHostResourceEvent, 1)
+	assert.Len(t, small.consumedHostResourceEvent, 1)
+}
+
+func TestStartQueuedTasksFirstFit(t *testing.T) {
+	taskEngine := newTaskQueueTestEngine(config.TaskQueueFirstFitPolicy)
+	large := newTaskQueueTestTask(taskEngine, "large", 1.5)
+	small := newTaskQueueTestTask(taskEngine, "small", 0.5)
+	stopped := newTaskQueueTestTask(taskEngine, "stopped", 4)
+	stopped.SetDesiredStatus(apitaskstatus.TaskStopped)
+	taskEngine.enqueueTask(large)
+	taskEngine.enqueueTask(small)
+	taskEngine.enqueueTask(stopped)
+
+	taskEngine.startQueuedTasks()
+	// the small task is started while the large task waits, and the stopped task
+	// progresses without consuming resources
+	assert.Equal(t, []string{"large"}, queuedTaskARNs(taskEngine))
+	assert.Len(t, large.consumedHostResourceEvent, 0)
+	assert.Len(t, small.consumedHostResourceEvent, 1)
+	assert.Len(t, stopped.consumedHostResourceEvent, 1)
+
+	queue := taskEngine.GetTaskQueue()
+	assert.Equal(t, "first-fit", queue.Policy)
+	require.Len(t, queue.Tasks, 1)
+	assert.Equal(t, []string{"CPU"}, queue.Tasks[0].UnavailableResources)
+}
+
+func TestEnqueueTaskPriority(t *testing.T) {
+	taskEngine := newTaskQueueTestEngine(config.TaskQueuePriorityPolicy)
+	priorityTag := func(value string) []types.Tag {
+		return []types.Tag{{Key: aws.String(config.DefaultTaskQueuePriorityTag), Value: aws.String(value)}}
+	}
+	taskEngine.SetTaskTagsGetter(&fakeTaskTagsGetter{tags: map[string][]types.Tag{
+		"low":     priorityTag("-1"),
+		"high":    priorityTag("10"),
+		"medium1": priorityTag("5"),
+		"medium2": priorityTag("5"),
+		"invalid": priorityTag("high"),
+		"untagged": {
+			{Key: aws.String("team"), Value: aws.String("web")},
+		},
+	}})
+	for _, arn := range []string{"low", "medium1", "untagged", "high", "medium2", "invalid", "unknown"} {
+		taskEngine.enqueueTask(newTaskQueueTestTask(taskEngine, arn, 2))
+	}
+
+	assert.Equal(t, []string{"high", "medium1", "medium2", "untagged", "invalid", "unknown", "low"},
+		queuedTaskARNs(taskEngine))
+	queue := taskEngine.GetTaskQueue()
+	assert.Equal(t, "priority", queue.Policy)
+	assert.Equal(t, 10, queue.Tasks[0].Priority)
+	assert.Equal(t, -1, queue.Tasks[6].Priority)
+	assert.Equal(t, 6, queue.Tasks[6].Position)
+}
+
+func TestEnqueueTaskPriorityNotNeeded(t *testing.T) {
+	taskEngine := newTaskQueueTestEngine(config.TaskQueuePriorityPolicy)
+	getter := &fakeTaskTagsGetter{}
+	taskEngine.SetTaskTagsGetter(getter)
+
+	// The tags of a task that can start right away aren't retrieved
+	taskEngine.enqueueTask(newTaskQueueTestTask(taskEngine, "small", 0.5))
+	assert.Equal(t, 0, getter.calls)
+
+	// Once a task is waiting, the tasks queued after it may wait too
+	taskEngine.enqueueTask(newTaskQueueTestTask(taskEngine, "other", 0.5))
+	assert.Equal(t, 1, getter.calls)
+}
+
+func TestEnqueueTaskPriorityTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { taskQueuePriorityTimeout = timeout }(taskQueuePriorityTimeout)
+	taskQueuePriorityTimeout = 10 * time.Millisecond
+
+	taskEngine := newTaskQueueTestEngine(config.TaskQueuePriorityPolicy)
+	block := make(chan struct{})
+	defer close(block)
+	taskEngine.SetTaskTagsGetter(&fakeTaskTagsGetter{
+		tags: map[string][]types.Tag{
+			"large": {{Key: aws.String(config.DefaultTaskQueuePriorityTag), Value: aws.String("10")}},
+		},
+		block: block,
+	})
+
+	taskEngine.enqueueTask(newTaskQueueTestTask(taskEngine, "large", 2))
+	queue := taskEngine.GetTaskQueue()
+	require.Len(t, queue.Tasks, 1)
+	assert.Equal(t, 0, queue.Tasks[0].Priority)
+}
+
+func TestTaskQueueMetrics(t *testing.T) {
+	taskEngine := newTaskQueueTestEngine(config.TaskQueueFirstFitPolicy)
+	taskEngine.enqueueTask(newTaskQueueTestTask(taskEngine, "large", 1.5))
+	taskEngine.enqueueTask(newTaskQueueTestTask(taskEngine, "small", 0.5))
+	taskEngine.startQueuedTasks()
+
+	var depthGauge *float64
+	var waitCount *float64
+	for _, family := range taskEngine.metricsFactory.(metrics.PrometheusEntryFactory).Gather() {
+		for _, metric := range family.Metric {
+			labels := map[string]string{}
+			for _, label := range metric.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+			switch {
+			case family.GetName() == "ecs_agent_operation_gauge" && labels["operation"] == metrics.TaskQueueDepthMetricName:
+				depthGauge = metric.Gauge.Value
+			case family.GetName() == "ecs_agent_operations_total" && labels["operation"] == metrics.TaskQueueWaitMetricName:
+				waitCount = metric.Counter.Value
+			}
+		}
+	}
+	require.NotNil(t, depthGauge)
+	assert.Equal(t, float64(1), *depthGauge)
+	require.NotNil(t, waitCount)
+	assert.Equal(t, float64(1), *waitCount)
+}
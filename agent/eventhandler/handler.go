@@ -23,10 +23,16 @@ import (
 	"github.com/cihub/seelog"
 )
 
+// EventSubscriber is notified of every state change event of the task engine, in addition to
+// the event being sent to the backend. Notify must not block the handling of the events.
+type EventSubscriber interface {
+	Notify(event statechange.Event)
+}
+
 // HandleEngineEvents handles state change events from the state change event channel by sending it to
-// responsible event handler
+// responsible event handler, and notifies the subscribers of each event
 func HandleEngineEvents(ctx context.Context, taskEngine engine.TaskEngine, client ecs.ECSClient,
-	taskHandler *TaskHandler, attachmentEventHandler *AttachmentEventHandler) {
+	taskHandler *TaskHandler, attachmentEventHandler *AttachmentEventHandler, subscribers ...EventSubscriber) {
 
 	for {
 		stateChangeEvents := taskEngine.StateChangeEvents()
@@ -42,6 +48,9 @@ func HandleEngineEvents(ctx context.Context, taskEngine engine.TaskEngine, clien
 					seelog.Error("Unable to handle state change event. The events channel is closed")
 					break
 				}
+				for _, subscriber := range subscribers {
+					subscriber.Notify(event)
+				}
 				err := handleEngineEvent(event, client, taskHandler, attachmentEventHandler)
 				if err != nil {
 					seelog.Errorf("Handler unable to add state change event %v: %v", event, err)
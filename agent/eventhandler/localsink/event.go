@@ -0,0 +1,88 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package localsink delivers the state change events of the task engine to local
+// subscribers such as webhooks and Unix sockets.
+package localsink
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	"github.com/aws/amazon-ecs-agent/agent/statechange"
+)
+
+// Types of the events delivered to the local sinks
+const (
+	EventTypeTask         = "TaskStateChange"
+	EventTypeContainer    = "ContainerStateChange"
+	EventTypeManagedAgent = "ManagedAgentStateChange"
+	EventTypeAttachment   = "AttachmentStateChange"
+)
+
+// Event is the JSON representation of a state change event delivered to the local sinks
+type Event struct {
+	Type             string    `json:"Type"`
+	Time             time.Time `json:"Time"`
+	TaskARN          string    `json:"TaskARN,omitempty"`
+	ContainerName    string    `json:"ContainerName,omitempty"`
+	RuntimeID        string    `json:"RuntimeID,omitempty"`
+	ManagedAgentName string    `json:"ManagedAgentName,omitempty"`
+	AttachmentARN    string    `json:"AttachmentARN,omitempty"`
+	Status           string    `json:"Status"`
+	Reason           string    `json:"Reason,omitempty"`
+	ExitCode         *int      `json:"ExitCode,omitempty"`
+	ImageDigest      string    `json:"ImageDigest,omitempty"`
+}
+
+// NewEvent returns the local representation of a state change event of the task engine, or
+// false if the event is of an unknown type.
+func NewEvent(change statechange.Event, now time.Time) (*Event, bool) {
+	event := &Event{Time: now.UTC()}
+	switch change := change.(type) {
+	case api.TaskStateChange:
+		event.Type = EventTypeTask
+		event.TaskARN = change.TaskARN
+		event.Status = change.Status.String()
+		event.Reason = change.Reason
+	case api.ContainerStateChange:
+		event.Type = EventTypeContainer
+		event.TaskARN = change.TaskArn
+		event.ContainerName = change.ContainerName
+		event.RuntimeID = change.RuntimeID
+		event.Status = change.Status.String()
+		event.Reason = change.Reason
+		event.ExitCode = change.ExitCode
+		event.ImageDigest = change.ImageDigest
+	case api.ManagedAgentStateChange:
+		event.Type = EventTypeManagedAgent
+		event.TaskARN = change.TaskArn
+		event.ManagedAgentName = change.Name
+		event.Status = change.Status.String()
+		event.Reason = change.Reason
+		if change.Container != nil {
+			event.ContainerName = change.Container.Name
+		}
+	case api.AttachmentStateChange:
+		if change.Attachment == nil {
+			return nil, false
+		}
+		event.Type = EventTypeAttachment
+		event.AttachmentARN = change.Attachment.GetAttachmentARN()
+		status := change.Attachment.GetAttachmentStatus()
+		event.Status = status.String()
+	default:
+		return nil, false
+	}
+	return event, true
+}
@@ -0,0 +1,89 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTaskARN = "arn:aws:ecs:us-west-2:123456789012:task/cluster/abc"
+
+func TestNewEvent(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	exitCode := 1
+
+	event, ok := NewEvent(api.TaskStateChange{
+		TaskARN: testTaskARN,
+		Status:  apitaskstatus.TaskStopped,
+		Reason:  "Essential container exited",
+	}, now)
+	require.True(t, ok)
+	assert.Equal(t, &Event{
+		Type:    EventTypeTask,
+		Time:    now,
+		TaskARN: testTaskARN,
+		Status:  "STOPPED",
+		Reason:  "Essential container exited",
+	}, event)
+
+	event, ok = NewEvent(api.ContainerStateChange{
+		TaskArn:       testTaskARN,
+		ContainerName: "app",
+		RuntimeID:     "runtime-id",
+		Status:        apicontainerstatus.ContainerStopped,
+		ExitCode:      &exitCode,
+		ImageDigest:   "sha256:digest",
+	}, now)
+	require.True(t, ok)
+	assert.Equal(t, &Event{
+		Type:          EventTypeContainer,
+		Time:          now,
+		TaskARN:       testTaskARN,
+		ContainerName: "app",
+		RuntimeID:     "runtime-id",
+		Status:        "STOPPED",
+		ExitCode:      &exitCode,
+		ImageDigest:   "sha256:digest",
+	}, event)
+
+	event, ok = NewEvent(api.ManagedAgentStateChange{
+		TaskArn:   testTaskARN,
+		Name:      "ExecuteCommandAgent",
+		Container: &apicontainer.Container{Name: "app"},
+		Status:    apicontainerstatus.ManagedAgentRunning,
+	}, now)
+	require.True(t, ok)
+	assert.Equal(t, &Event{
+		Type:             EventTypeManagedAgent,
+		Time:             now,
+		TaskARN:          testTaskARN,
+		ContainerName:    "app",
+		ManagedAgentName: "ExecuteCommandAgent",
+		Status:           "RUNNING",
+	}, event)
+
+	_, ok = NewEvent(api.AttachmentStateChange{}, now)
+	assert.False(t, ok)
+}
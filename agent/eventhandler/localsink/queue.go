@@ -0,0 +1,152 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	queueFileExt  = ".json"
+	queueFilePerm = 0600
+	queueDirPerm  = 0700
+)
+
+// diskQueue is a FIFO queue of events persisted as one file per event in a directory, so
+// that the events not yet delivered survive restarts of the agent. The queue is bounded and
+// drops its oldest events when full.
+type diskQueue struct {
+	dir     string
+	maxSize int
+	// seqs are the sequence numbers of the queued events, oldest first
+	seqs    []uint64
+	nextSeq uint64
+	lock    sync.Mutex
+}
+
+// newDiskQueue returns the queue persisted in dir, loading the events queued by a previous
+// run of the agent.
+func newDiskQueue(dir string, maxSize int) (*diskQueue, error) {
+	if maxSize <= 0 {
+		return nil, errors.Errorf("invalid queue size %d", maxSize)
+	}
+	if err := os.MkdirAll(dir, queueDirPerm); err != nil {
+		return nil, errors.Wrapf(err, "unable to create queue directory %s", dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read queue directory %s", dir)
+	}
+	q := &diskQueue{
+		dir:     dir,
+		maxSize: maxSize,
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, queueFileExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, queueFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		q.seqs = append(q.seqs, seq)
+	}
+	sort.Slice(q.seqs, func(i, j int) bool { return q.seqs[i] < q.seqs[j] })
+	if len(q.seqs) > 0 {
+		q.nextSeq = q.seqs[len(q.seqs)-1] + 1
+	}
+	for len(q.seqs) > maxSize {
+		q.dropOldest()
+	}
+	return q, nil
+}
+
+// push appends an event to the queue. It returns true if the oldest event was dropped to
+// make room for it.
+func (q *diskQueue) push(data []byte) (bool, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	seq := q.nextSeq
+	path := q.path(seq)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, queueFilePerm); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return false, err
+	}
+	q.nextSeq++
+	q.seqs = append(q.seqs, seq)
+
+	dropped := false
+	for len(q.seqs) > q.maxSize {
+		q.dropOldest()
+		dropped = true
+	}
+	return dropped, nil
+}
+
+// peek returns the oldest event of the queue and its sequence number, or false if the queue
+// is empty. Events whose file can't be read are dropped.
+func (q *diskQueue) peek() (uint64, []byte, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for len(q.seqs) > 0 {
+		seq := q.seqs[0]
+		data, err := os.ReadFile(q.path(seq))
+		if err == nil {
+			return seq, data, true
+		}
+		q.dropOldest()
+	}
+	return 0, nil, false
+}
+
+// remove removes an event from the queue once delivered. It's a no-op if the event was
+// dropped in the meantime.
+func (q *diskQueue) remove(seq uint64) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.seqs) > 0 && q.seqs[0] == seq {
+		q.dropOldest()
+	}
+}
+
+func (q *diskQueue) len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.seqs)
+}
+
+func (q *diskQueue) dropOldest() {
+	os.Remove(q.path(q.seqs[0]))
+	q.seqs = q.seqs[1:]
+}
+
+func (q *diskQueue) path(seq uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seq, queueFileExt))
+}
@@ -0,0 +1,70 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskQueue(t *testing.T) {
+	dir := t.TempDir()
+	q, err := newDiskQueue(dir, 2)
+	require.NoError(t, err)
+
+	_, _, ok := q.peek()
+	assert.False(t, ok)
+
+	for _, data := range []string{"a", "b", "c"} {
+		dropped, err := q.push([]byte(data))
+		require.NoError(t, err)
+		assert.Equal(t, data == "c", dropped)
+	}
+	assert.Equal(t, 2, q.len())
+
+	seq, data, ok := q.peek()
+	require.True(t, ok)
+	assert.Equal(t, "b", string(data))
+	q.remove(seq)
+	// removing an event twice is a no-op
+	q.remove(seq)
+	assert.Equal(t, 1, q.len())
+
+	// the queued events are loaded again after a restart
+	q, err = newDiskQueue(dir, 2)
+	require.NoError(t, err)
+	_, data, ok = q.peek()
+	require.True(t, ok)
+	assert.Equal(t, "c", string(data))
+	_, err = q.push([]byte("d"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, q.len())
+
+	// a smaller queue drops the oldest events
+	q, err = newDiskQueue(dir, 1)
+	require.NoError(t, err)
+	_, data, ok = q.peek()
+	require.True(t, ok)
+	assert.Equal(t, "d", string(data))
+}
+
+func TestDiskQueueInvalidSize(t *testing.T) {
+	_, err := newDiskQueue(t.TempDir(), 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsink
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/statechange"
+)
+
+// Sink delivers events to a local subscriber in the background
+type Sink interface {
+	// Start starts delivering the events sent to the sink until the context is done
+	Start(ctx context.Context)
+	// Send queues an event for delivery, it must not block
+	Send(event *Event)
+}
+
+// Subscriber converts the state change events of the task engine and sends them to the
+// local sinks.
+type Subscriber struct {
+	sinks []Sink
+	now   func() time.Time
+}
+
+// NewSubscriber returns a subscriber sending events to the given sinks, and starts the sinks.
+func NewSubscriber(ctx context.Context, sinks ...Sink) *Subscriber {
+	for _, sink := range sinks {
+		sink.Start(ctx)
+	}
+	return &Subscriber{
+		sinks: sinks,
+		now:   time.Now,
+	}
+}
+
+// Notify sends a state change event to the sinks of the subscriber
+func (s *Subscriber) Notify(change statechange.Event) {
+	event, ok := NewEvent(change, s.now())
+	if !ok {
+		return
+	}
+	for _, sink := range s.sinks {
+		sink.Send(event)
+	}
+}
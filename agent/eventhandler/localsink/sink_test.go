@@ -0,0 +1,179 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEventTimeout = 5 * time.Second
+
+// webhookServer records the events it receives, after failing the first requests with the
+// given status codes
+type webhookServer struct {
+	failures []int
+	events   chan Event
+	lock     sync.Mutex
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		s.lock.Unlock()
+		w.WriteHeader(status)
+		return
+	}
+	s.lock.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	var event Event
+	if err := json.Unmarshal(body, &event); err == nil {
+		s.events <- event
+	}
+}
+
+func newTestWebhookSink(t *testing.T, url string, queueSize int) *webhookSink {
+	sink, err := NewWebhookSink(url, t.TempDir(), queueSize)
+	require.NoError(t, err)
+	w := sink.(*webhookSink)
+	w.retryBackoff = retry.NewConstantBackoff(time.Millisecond)
+	w.failureBackoff = retry.NewConstantBackoff(time.Millisecond)
+	return w
+}
+
+func receiveEvent(t *testing.T, events <-chan Event) Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(testEventTimeout):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestWebhookSinkDeliversEventsInOrder(t *testing.T) {
+	server := &webhookServer{
+		// the first event is retried until it's delivered
+		failures: []int{http.StatusInternalServerError, http.StatusTooManyRequests,
+			http.StatusBadGateway, http.StatusServiceUnavailable},
+		events: make(chan Event, 10),
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := newTestWebhookSink(t, httpServer.URL, 10)
+	subscriber := NewSubscriber(ctx, sink)
+
+	subscriber.Notify(api.TaskStateChange{TaskARN: testTaskARN, Status: apitaskstatus.TaskRunning})
+	subscriber.Notify(api.TaskStateChange{TaskARN: testTaskARN, Status: apitaskstatus.TaskStopped})
+
+	assert.Equal(t, "RUNNING", receiveEvent(t, server.events).Status)
+	assert.Equal(t, "STOPPED", receiveEvent(t, server.events).Status)
+	assert.Eventually(t, func() bool { return sink.queue.len() == 0 }, testEventTimeout, 10*time.Millisecond)
+}
+
+func TestWebhookSinkDropsRejectedEvents(t *testing.T) {
+	server := &webhookServer{
+		failures: []int{http.StatusBadRequest},
+		events:   make(chan Event, 10),
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscriber := NewSubscriber(ctx, newTestWebhookSink(t, httpServer.URL, 10))
+
+	subscriber.Notify(api.TaskStateChange{TaskARN: testTaskARN, Status: apitaskstatus.TaskRunning})
+	subscriber.Notify(api.TaskStateChange{TaskARN: testTaskARN, Status: apitaskstatus.TaskStopped})
+
+	assert.Equal(t, "STOPPED", receiveEvent(t, server.events).Status)
+}
+
+func TestWebhookSinkQueueIsBounded(t *testing.T) {
+	sink := newTestWebhookSink(t, "http://localhost:1/events", 2)
+	for _, status := range []apitaskstatus.TaskStatus{apitaskstatus.TaskPulled, apitaskstatus.TaskRunning, apitaskstatus.TaskStopped} {
+		event, _ := NewEvent(api.TaskStateChange{TaskARN: testTaskARN, Status: status}, time.Now())
+		sink.Send(event)
+	}
+	assert.Equal(t, 2, sink.queue.len())
+	_, data, _ := sink.queue.peek()
+	var event Event
+	require.NoError(t, json.Unmarshal(data, &event))
+	assert.Equal(t, "RUNNING", event.Status)
+}
+
+func TestNewWebhookSinkInvalidURL(t *testing.T) {
+	for _, url := range []string{"", "localhost:8080", "ftp://localhost/events", "http://"} {
+		_, err := NewWebhookSink(url, t.TempDir(), 10)
+		assert.Error(t, err, url)
+	}
+}
+
+func TestSocketSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := NewSocketSink(path).(*socketSink)
+	sink.backoff = retry.NewConstantBackoff(10 * time.Millisecond)
+	subscriber := NewSubscriber(ctx, sink)
+
+	// the event is kept until the subscriber listens on the socket
+	subscriber.Notify(api.TaskStateChange{TaskARN: testTaskARN, Status: apitaskstatus.TaskRunning})
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	events := make(chan Event, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var event Event
+			if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+				events <- event
+			}
+		}
+	}()
+	subscriber.Notify(api.TaskStateChange{TaskARN: testTaskARN, Status: apitaskstatus.TaskStopped})
+
+	assert.Equal(t, "RUNNING", receiveEvent(t, events).Status)
+	assert.Equal(t, "STOPPED", receiveEvent(t, events).Status)
+}
@@ -0,0 +1,124 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsink
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+)
+
+const (
+	socketDialTimeout  = 2 * time.Second
+	socketWriteTimeout = 2 * time.Second
+	socketBufferSize   = 1000
+	socketReconnectMin = 500 * time.Millisecond
+	socketReconnectMax = 30 * time.Second
+)
+
+// socketSink writes each event as a line of JSON to a Unix socket listened on by a local
+// subscriber. The agent connects to the socket and reconnects when the connection is lost.
+// The events are buffered in memory while the subscriber is unavailable, and new events are
+// dropped once the buffer is full.
+type socketSink struct {
+	path    string
+	events  chan []byte
+	backoff retry.Backoff
+}
+
+// NewSocketSink returns a sink writing events to the Unix socket at path
+func NewSocketSink(path string) Sink {
+	return &socketSink{
+		path:    path,
+		events:  make(chan []byte, socketBufferSize),
+		backoff: retry.NewExponentialBackoff(socketReconnectMin, socketReconnectMax, 0.2, 2),
+	}
+}
+
+func (s *socketSink) Send(event *Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("Unable to marshal event for socket", logger.Fields{field.Error: err})
+		return
+	}
+	select {
+	case s.events <- append(data, '\n'):
+	default:
+		logger.Warn("Socket event buffer is full, dropping event", logger.Fields{
+			"socket": s.path,
+		})
+	}
+}
+
+func (s *socketSink) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+func (s *socketSink) run(ctx context.Context) {
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	for {
+		var data []byte
+		select {
+		case <-ctx.Done():
+			return
+		case data = <-s.events:
+		}
+
+		// Write the event, reconnecting as needed, until it's written or the agent stops
+		for {
+			if conn == nil {
+				var err error
+				conn, err = net.DialTimeout("unix", s.path, socketDialTimeout)
+				if err != nil {
+					conn = nil
+					wait := s.backoff.Duration()
+					logger.Debug("Unable to connect to event socket", logger.Fields{
+						"socket":    s.path,
+						"retryIn":   wait.String(),
+						field.Error: err,
+					})
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(wait):
+					}
+					continue
+				}
+				s.backoff.Reset()
+			}
+			conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+			if _, err := conn.Write(data); err != nil {
+				logger.Warn("Unable to write event to socket, reconnecting", logger.Fields{
+					"socket":    s.path,
+					field.Error: err,
+				})
+				conn.Close()
+				conn = nil
+				continue
+			}
+			break
+		}
+	}
+}
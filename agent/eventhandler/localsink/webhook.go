@@ -0,0 +1,183 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+	"github.com/pkg/errors"
+)
+
+const (
+	webhookRequestTimeout = 5 * time.Second
+	webhookRetries        = 3
+	webhookRetryMin       = 100 * time.Millisecond
+	webhookRetryMax       = time.Second
+	// webhookFailureBackoffMin and webhookFailureBackoffMax bound the wait before trying to
+	// deliver an event again once the retries of a delivery are exhausted
+	webhookFailureBackoffMin = time.Second
+	webhookFailureBackoffMax = time.Minute
+)
+
+// webhookSink POSTs each event as JSON to a URL, in order. The events are queued on disk
+// until they are delivered, and are retried as long as the webhook returns retriable
+// errors. Events rejected by the webhook with a client error are dropped.
+type webhookSink struct {
+	url            string
+	client         *http.Client
+	queue          *diskQueue
+	pending        chan struct{}
+	retryBackoff   retry.Backoff
+	failureBackoff retry.Backoff
+}
+
+// NewWebhookSink returns a sink posting events to a webhook URL. The events not delivered
+// yet are queued in a directory under queueRootDir, up to queueSize events.
+func NewWebhookSink(webhookURL, queueRootDir string, queueSize int) (Sink, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Errorf("invalid webhook URL %q", webhookURL)
+	}
+	queue, err := newDiskQueue(webhookQueueDir(queueRootDir, webhookURL), queueSize)
+	if err != nil {
+		return nil, err
+	}
+	return &webhookSink{
+		url:            webhookURL,
+		client:         &http.Client{Timeout: webhookRequestTimeout},
+		queue:          queue,
+		pending:        make(chan struct{}, 1),
+		retryBackoff:   retry.NewExponentialBackoff(webhookRetryMin, webhookRetryMax, 0.2, 2),
+		failureBackoff: retry.NewExponentialBackoff(webhookFailureBackoffMin, webhookFailureBackoffMax, 0.2, 2),
+	}, nil
+}
+
+// webhookQueueDir returns the queue directory of a webhook, which is derived from its URL so
+// that the events queued for a webhook are delivered to it after a restart.
+func webhookQueueDir(queueRootDir, webhookURL string) string {
+	return filepath.Join(queueRootDir, fmt.Sprintf("%x", sha256.Sum256([]byte(webhookURL)))[:16])
+}
+
+func (w *webhookSink) Send(event *Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("Unable to marshal event for webhook", logger.Fields{field.Error: err})
+		return
+	}
+	dropped, err := w.queue.push(data)
+	if err != nil {
+		logger.Error("Unable to queue event for webhook", logger.Fields{
+			"webhook":   w.url,
+			field.Error: err,
+		})
+		return
+	}
+	if dropped {
+		logger.Warn("Webhook event queue is full, dropped the oldest event", logger.Fields{
+			"webhook": w.url,
+		})
+	}
+	select {
+	case w.pending <- struct{}{}:
+	default:
+	}
+}
+
+func (w *webhookSink) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+func (w *webhookSink) run(ctx context.Context) {
+	for {
+		seq, data, ok := w.queue.peek()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.pending:
+			}
+			continue
+		}
+
+		w.retryBackoff.Reset()
+		err := retry.RetryNWithBackoffCtx(ctx, w.retryBackoff, webhookRetries, func() error {
+			return w.post(ctx, data)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if retriable, ok := err.(apierrors.Retriable); !ok || retriable.Retry() {
+				wait := w.failureBackoff.Duration()
+				logger.Warn("Unable to deliver event to webhook, will try again", logger.Fields{
+					"webhook":   w.url,
+					"retryIn":   wait.String(),
+					field.Error: err,
+				})
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+				continue
+			}
+			logger.Warn("Webhook rejected event, dropping it", logger.Fields{
+				"webhook":   w.url,
+				field.Error: err,
+			})
+		}
+		w.failureBackoff.Reset()
+		w.queue.remove(seq)
+	}
+}
+
+// post sends an event to the webhook. Client errors other than timeouts and throttling are
+// not retriable.
+func (w *webhookSink) post(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return apierrors.NewRetriableError(apierrors.NewRetriable(false), err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return apierrors.NewRetriableError(apierrors.NewRetriable(false),
+			errors.Errorf("unexpected status code %d", resp.StatusCode))
+	default:
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
@@ -30,7 +31,8 @@ import (
 )
 
 // ServeIntrospectionHTTPEndpoint serves information about this agent/containerInstance and tasks running on it.
-func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *string, taskEngine engine.TaskEngine, cfg *config.Config) {
+func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *string, taskEngine engine.TaskEngine,
+	cfg *config.Config, doctor *doctor.Doctor, metricsFactory metrics.EntryFactory) {
 	// Is this the right level to type assert, assuming we'd abstract multiple taskengines here?
 	// Revisit if we ever add another type..
 	dockerTaskEngine := taskEngine.(*engine.DockerTaskEngine)
@@ -40,13 +42,32 @@ func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *s
 		TaskEngine:           dockerTaskEngine,
 	}
 
-	server, err := introspection.NewServer(
-		agentState,
-		metrics.NewNopEntryFactory(),
+	options := []introspection.ConfigOpt{
 		introspection.WithReadTimeout(readTimeout),
 		introspection.WithWriteTimeout(writeTimeout),
 		introspection.WithRuntimeStats(cfg.EnableRuntimeStats.Enabled()),
-	)
+		introspection.WithHandler(v1.HealthchecksPath, v1.HealthchecksHandler(doctor)),
+		introspection.WithHandler(v1.TaskExplanationPath, v1.TaskExplanationHandler(dockerTaskEngine)),
+		introspection.WithHandler(v1.TaskQueuePath, v1.TaskQueueHandler(dockerTaskEngine)),
+	}
+	// The logger configuration can only be changed when a token authenticating the requests
+	// is configured
+	if cfg.IntrospectionLogConfigToken != nil {
+		options = append(options, introspection.WithHandler(v1.LogConfigPath,
+			v1.LogConfigHandler(cfg.IntrospectionLogConfigToken.Contents())))
+	}
+	// Images can only be prefetched through the introspection API when a token authenticating
+	// the requests is configured
+	if cfg.IntrospectionImagePrefetchToken != nil {
+		options = append(options, introspection.WithHandler(v1.ImagePrefetchPath,
+			v1.ImagePrefetchHandler(dockerTaskEngine, cfg.IntrospectionImagePrefetchToken.Contents())))
+	}
+	// Expose the agent metrics when they are being recorded in the Prometheus data model
+	if prometheusFactory, ok := metricsFactory.(metrics.PrometheusEntryFactory); ok {
+		options = append(options, introspection.WithMetricsHandler(prometheusFactory))
+	}
+
+	server, err := introspection.NewServer(agentState, metricsFactory, options...)
 
 	if err != nil {
 		seelog.Criticalf("Failed to set up Introspection Server: %v", err)
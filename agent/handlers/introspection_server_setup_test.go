@@ -28,6 +28,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection/v1/handlers"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/aws-sdk-go/aws"
 
 	"github.com/stretchr/testify/assert"
@@ -49,7 +50,8 @@ func TestServeIntrospectionHTTPEndpoint(t *testing.T) {
 		return fmt.Errorf("timed out waiting for server %s to come up: %w", serverAddress, err)
 	}
 
-	go ServeIntrospectionHTTPEndpoint(context.Background(), aws.String("test_container_instance_arn"), &engine.DockerTaskEngine{}, &config.Config{Cluster: clusterName})
+	go ServeIntrospectionHTTPEndpoint(context.Background(), aws.String("test_container_instance_arn"), &engine.DockerTaskEngine{}, &config.Config{Cluster: clusterName},
+		nil, metrics.NewNopEntryFactory())
 
 	client := http.DefaultClient
 	err := waitForServer(client, serverAddress)
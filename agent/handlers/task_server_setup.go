@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	tpfactory "github.com/aws/amazon-ecs-agent/agent/handlers/agentapi/taskprotection"
 	v2 "github.com/aws/amazon-ecs-agent/agent/handlers/v2"
@@ -72,6 +73,8 @@ func taskServerSetup(
 	vpcID string,
 	containerInstanceArn string,
 	taskProtectionClientFactory tp.TaskProtectionClientFactoryInterface,
+	metricsFactory metrics.EntryFactory,
+	faultOpts ...fault.Option,
 ) (*http.Server, error) {
 	muxRouter := mux.NewRouter()
 
@@ -83,7 +86,6 @@ func taskServerSetup(
 		tmdsv1.CredentialsHandler(credentialsManager, auditLogger))
 
 	tmdsAgentState := v4.NewTMDSAgentState(state, statsEngine, ecsClient, cluster, availabilityZone, vpcID, containerInstanceArn)
-	metricsFactory := metrics.NewNopEntryFactory()
 
 	v2HandlersSetup(muxRouter, state, ecsClient, statsEngine, cluster, credentialsManager, auditLogger, availabilityZone, containerInstanceArn)
 
@@ -96,7 +98,7 @@ func taskServerSetup(
 		taskProtectionClientFactory, metricsFactory)
 
 	execWrapper := execwrapper.NewExec()
-	registerFaultHandlers(muxRouter, tmdsAgentState, metricsFactory, execWrapper)
+	registerFaultHandlers(muxRouter, tmdsAgentState, metricsFactory, execWrapper, faultOpts...)
 
 	return tmds.NewServer(auditLogger,
 		tmds.WithHandler(muxRouter),
@@ -165,6 +167,7 @@ func v4HandlersSetup(muxRouter *mux.Router,
 	muxRouter.HandleFunc(tmdsv4.TaskMetadataWithTagsPath(), tmdsv4.TaskMetadataWithTagsHandler(tmdsAgentState, metricsFactory))
 	muxRouter.HandleFunc(tmdsv4.ContainerStatsPath(), tmdsv4.ContainerStatsHandler(tmdsAgentState, metricsFactory))
 	muxRouter.HandleFunc(tmdsv4.TaskStatsPath(), tmdsv4.TaskStatsHandler(tmdsAgentState, metricsFactory))
+	muxRouter.HandleFunc(tmdsv4.TaskStatsHistoryPath(), tmdsv4.TaskStatsHistoryHandler(tmdsAgentState, metricsFactory))
 	muxRouter.HandleFunc(v4.ContainerAssociationsPath, v4.ContainerAssociationsHandler(state))
 	muxRouter.HandleFunc(v4.ContainerAssociationPathWithSlash, v4.ContainerAssociationHandler(state))
 	muxRouter.HandleFunc(v4.ContainerAssociationPath, v4.ContainerAssociationHandler(state))
@@ -201,8 +204,9 @@ func registerFaultHandlers(
 	agentState *v4.TMDSAgentState,
 	metricsFactory metrics.EntryFactory,
 	execWrapper execwrapper.Exec,
+	faultOpts ...fault.Option,
 ) {
-	handler := fault.New(agentState, metricsFactory, execWrapper)
+	handler := fault.New(agentState, metricsFactory, execWrapper, faultOpts...)
 
 	if muxRouter == nil {
 		return
@@ -322,6 +326,82 @@ func registerFaultHandlers(
 		),
 	).Methods("POST")
 
+	// Setting up handler endpoints for network bandwidth fault injections
+	muxRouter.Handle(
+		fault.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.StartNetworkFaultPostfix),
+		fault.TelemetryMiddleware(
+			tollbooth.LimitFuncHandler(
+				createRateLimiter(),
+				handler.StartNetworkBandwidth(),
+			),
+			metricsFactory,
+			faulttype.StartNetworkFaultPostfix,
+			faulttype.BandwidthFaultType,
+		),
+	).Methods("POST")
+	muxRouter.Handle(
+		fault.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.StopNetworkFaultPostfix),
+		fault.TelemetryMiddleware(
+			tollbooth.LimitFuncHandler(
+				createRateLimiter(),
+				handler.StopNetworkBandwidth(),
+			),
+			metricsFactory,
+			faulttype.StopNetworkFaultPostfix,
+			faulttype.BandwidthFaultType,
+		),
+	).Methods("POST")
+	muxRouter.Handle(
+		fault.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.CheckNetworkFaultPostfix),
+		fault.TelemetryMiddleware(
+			tollbooth.LimitFuncHandler(
+				createRateLimiter(),
+				handler.CheckNetworkBandwidth(),
+			),
+			metricsFactory,
+			faulttype.CheckNetworkFaultPostfix,
+			faulttype.BandwidthFaultType,
+		),
+	).Methods("POST")
+
+	// Setting up handler endpoints for network DNS fault injections
+	muxRouter.Handle(
+		fault.NetworkFaultPath(faulttype.DNSFaultType, faulttype.StartNetworkFaultPostfix),
+		fault.TelemetryMiddleware(
+			tollbooth.LimitFuncHandler(
+				createRateLimiter(),
+				handler.StartNetworkDNS(),
+			),
+			metricsFactory,
+			faulttype.StartNetworkFaultPostfix,
+			faulttype.DNSFaultType,
+		),
+	).Methods("POST")
+	muxRouter.Handle(
+		fault.NetworkFaultPath(faulttype.DNSFaultType, faulttype.StopNetworkFaultPostfix),
+		fault.TelemetryMiddleware(
+			tollbooth.LimitFuncHandler(
+				createRateLimiter(),
+				handler.StopNetworkDNS(),
+			),
+			metricsFactory,
+			faulttype.StopNetworkFaultPostfix,
+			faulttype.DNSFaultType,
+		),
+	).Methods("POST")
+	muxRouter.Handle(
+		fault.NetworkFaultPath(faulttype.DNSFaultType, faulttype.CheckNetworkFaultPostfix),
+		fault.TelemetryMiddleware(
+			tollbooth.LimitFuncHandler(
+				createRateLimiter(),
+				handler.CheckNetworkDNS(),
+			),
+			metricsFactory,
+			faulttype.CheckNetworkFaultPostfix,
+			faulttype.DNSFaultType,
+		),
+	).Methods("POST")
+
 	seelog.Debug("Successfully set up Fault TMDS handlers")
 }
 
@@ -344,6 +424,8 @@ func ServeTaskHTTPEndpoint(
 	statsEngine stats.Engine,
 	availabilityZone string,
 	vpcID string,
+	metricsFactory metrics.EntryFactory,
+	dataClient data.Client,
 ) {
 	// Create and initialize the audit log
 	logger, err := seelog.LoggerFromConfigAsString(audit.AuditLoggerConfig(cfg))
@@ -360,7 +442,8 @@ func ServeTaskHTTPEndpoint(
 	}
 	server, err := taskServerSetup(credentialsManager, auditLogger, state, ecsClient, cfg.Cluster,
 		statsEngine, cfg.TaskMetadataSteadyStateRate, cfg.TaskMetadataBurstRate,
-		availabilityZone, vpcID, containerInstanceArn, taskProtectionClientFactory)
+		availabilityZone, vpcID, containerInstanceArn, taskProtectionClientFactory, metricsFactory,
+		fault.WithActiveFaultStore(dataClient))
 	if err != nil {
 		seelog.Criticalf("Failed to set up Task Metadata Server: %v", err)
 		return
@@ -36,6 +36,7 @@ import (
 	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
 	v3 "github.com/aws/amazon-ecs-agent/agent/handlers/v3"
 	agentV4 "github.com/aws/amazon-ecs-agent/agent/handlers/v4"
+	agentstats "github.com/aws/amazon-ecs-agent/agent/stats"
 	mock_stats "github.com/aws/amazon-ecs-agent/agent/stats/mock"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
@@ -44,6 +45,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
 	mock_audit "github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	mock_metrics "github.com/aws/amazon-ecs-agent/ecs-agent/metrics/mocks"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
@@ -123,20 +125,21 @@ const (
 	hostNetworkNamespace       = "host"
 	defaultIfname              = "eth0"
 
-	port                              = 1234
-	protocol                          = "tcp"
-	trafficType                       = "ingress"
-	delayMilliseconds                 = 123456789
-	jitterMilliseconds                = 4567
-	lossPercent                       = 6
-	invalidNetworkMode                = "invalid"
-	iptablesChainNotFoundError        = "iptables: Bad rule (does a matching rule exist in that chain?)."
-	iptablesChainAlreadyExistError    = "iptables: Chain already exists."
-	tcLossFaultExistsCommandOutput    = `[{"kind":"netem","handle":"10:","dev":"eth0","parent":"1:1","options":{"limit":1000,"loss-random":{"loss":0.06,"correlation":0},"ecn":false,"gap":0}}]`
-	tcLatencyFaultExistsCommandOutput = `[{"kind":"netem","handle":"10:","parent":"1:1","options":{"limit":1000,"delay":{"delay":123456789,"jitter":4567,"correlation":0},"ecn":false,"gap":0}}]`
-	tcCommandEmptyOutput              = `[]`
-	requestTimeoutDuration            = 5 * time.Second
-	durationMetricPrefix              = "MetadataServer.%s%sDuration"
+	port                                = 1234
+	protocol                            = "tcp"
+	trafficType                         = "ingress"
+	delayMilliseconds                   = 123456789
+	jitterMilliseconds                  = 4567
+	lossPercent                         = 6
+	invalidNetworkMode                  = "invalid"
+	iptablesChainNotFoundError          = "iptables: Bad rule (does a matching rule exist in that chain?)."
+	iptablesChainAlreadyExistError      = "iptables: Chain already exists."
+	tcLossFaultExistsCommandOutput      = `[{"kind":"netem","handle":"10:","dev":"eth0","parent":"1:1","options":{"limit":1000,"loss-random":{"loss":0.06,"correlation":0},"ecn":false,"gap":0}}]`
+	tcLatencyFaultExistsCommandOutput   = `[{"kind":"netem","handle":"10:","parent":"1:1","options":{"limit":1000,"delay":{"delay":123456789,"jitter":4567,"correlation":0},"ecn":false,"gap":0}}]`
+	tcBandwidthFaultExistsCommandOutput = `[{"kind":"tbf","handle":"10:","parent":"1:1","options":{"rate":125000,"burst":32768,"lat":100000}}]`
+	tcCommandEmptyOutput                = `[]`
+	requestTimeoutDuration              = 5 * time.Second
+	durationMetricPrefix                = "MetadataServer.%s%sDuration"
 )
 
 var (
@@ -501,6 +504,17 @@ var (
 		"Sources":         ipSources,
 		"SourcesToFilter": ipSourcesToFilter,
 	}
+
+	happyNetworkBandwidthReqBody = map[string]interface{}{
+		"RateKbps":        1000,
+		"Sources":         ipSources,
+		"SourcesToFilter": ipSourcesToFilter,
+	}
+
+	happyNetworkDNSReqBody = map[string]interface{}{
+		"Domains":     []string{"example.com"},
+		"FailureMode": "timeout",
+	}
 )
 
 func standardTask() *apitask.Task {
@@ -1013,7 +1027,7 @@ func testErrorResponsesFromServer(t *testing.T, path string, expectedErrorMessag
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 	server, err := taskServerSetup(credentialsManager, auditLog, nil, ecsClient, "", nil,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 
 	recorder := httptest.NewRecorder()
@@ -1050,7 +1064,7 @@ func getResponseForCredentialsRequest(t *testing.T, expectedStatus int,
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 	server, err := taskServerSetup(credentialsManager, auditLog, nil, ecsClient, "", nil,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 
 	recorder := httptest.NewRecorder()
@@ -1109,7 +1123,7 @@ func TestV3ContainerAssociations(t *testing.T) {
 	)
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/associations/"+associationType, nil)
@@ -1141,7 +1155,7 @@ func TestV3ContainerAssociation(t *testing.T) {
 	)
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/associations/"+associationType+"/"+associationName, nil)
@@ -1172,7 +1186,7 @@ func TestV4ContainerAssociations(t *testing.T) {
 	)
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v4BasePath+v3EndpointID+"/associations/"+associationType, nil)
@@ -1204,7 +1218,7 @@ func TestV4ContainerAssociation(t *testing.T) {
 	)
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v4BasePath+v3EndpointID+"/associations/"+associationType+"/"+associationName, nil)
@@ -1231,7 +1245,7 @@ func TestTaskHTTPEndpoint301Redirect(t *testing.T) {
 
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 
 	for testPath, expectedPath := range testPathsMap {
@@ -1274,7 +1288,7 @@ func TestTaskHTTPEndpointErrorCode404(t *testing.T) {
 
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1314,7 +1328,7 @@ func TestTaskHTTPEndpointErrorCode400(t *testing.T) {
 
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1353,7 +1367,7 @@ func TestTaskHTTPEndpointErrorCode500(t *testing.T) {
 
 	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1423,7 +1437,7 @@ func TestV4TaskNotFoundError404(t *testing.T) {
 
 			server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 				config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 			require.NoError(t, err)
 
 			state.EXPECT().TaskARNByV3EndpointID(gomock.Any()).Return("", tc.taskFound).AnyTimes()
@@ -1479,7 +1493,7 @@ func TestV4Unexpected500Error(t *testing.T) {
 
 			server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 				config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), metrics.NewNopEntryFactory())
 			require.NoError(t, err)
 
 			// Initial lookups succeed
@@ -1517,6 +1531,7 @@ type TMDSResponse interface {
 		v4.StatsResponse |
 		map[string]*types.StatsJSON |
 		map[string]*v4.StatsResponse |
+		map[string]*v4.StatsHistoryResponse |
 		string
 }
 
@@ -1587,7 +1602,7 @@ func testTMDSRequest[R TMDSResponse](t *testing.T, tc TMDSTestCase[R]) {
 	server, err := taskServerSetup(credsManager, auditLog, state, ecsClient,
 		clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, availabilityzone, vpcID,
-		containerInstanceArn, taskProtectionClientFactory)
+		containerInstanceArn, taskProtectionClientFactory, metrics.NewNopEntryFactory())
 	require.NoError(t, err)
 
 	// Create the request
@@ -3312,6 +3327,112 @@ func TestV4TaskStats(t *testing.T) {
 	})
 }
 
+func TestV4TaskStatsHistory(t *testing.T) {
+	path := v4BasePath + v3EndpointID + "/task/stats/history"
+	containerMap := map[string]*apicontainer.DockerContainer{
+		containerName: {DockerID: containerID},
+	}
+	timestamp := time.Now().UTC().Truncate(time.Second)
+	t.Run("task not found", func(t *testing.T) {
+		testTMDSRequest(t, TMDSTestCase[string]{
+			path: path,
+			setStateExpectations: func(state *mock_dockerstate.MockTaskEngineState) {
+				state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return("", false)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: fmt.Sprintf(
+				"V4 task stats history handler: unable to get task arn from request: unable to get task Arn from v3 endpoint ID: %s",
+				v3EndpointID),
+		})
+	})
+	t.Run("samples", func(t *testing.T) {
+		testTMDSRequest(t, TMDSTestCase[map[string]*v4.StatsHistoryResponse]{
+			path: path,
+			setStateExpectations: func(state *mock_dockerstate.MockTaskEngineState) {
+				gomock.InOrder(
+					state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
+					state.EXPECT().ContainerMapByArn(taskARN).Return(containerMap, true),
+				)
+			},
+			setStatsEngineExpectations: func(engine *mock_stats.MockEngine) {
+				engine.EXPECT().ContainerStatsHistory(taskARN, containerID).Return([]agentstats.UsageStats{
+					{
+						Timestamp:         timestamp,
+						CPUUsagePerc:      25,
+						MemoryUsageInMegs: 128,
+						NetworkStats:      &agentstats.NetworkStats{RxBytesPerSecond: 52, TxBytesPerSecond: 84},
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: map[string]*v4.StatsHistoryResponse{containerID: {
+				Samples: []v4.StatsSample{
+					{
+						Timestamp:               timestamp,
+						CPUUsagePercent:         25,
+						MemoryUsageMiB:          128,
+						NetworkRxBytesPerSecond: aws.Float64(52),
+						NetworkTxBytesPerSecond: aws.Float64(84),
+					},
+				},
+			}},
+		})
+	})
+	t.Run("first sample of a fresh queue", func(t *testing.T) {
+		// The first sample of a queue has no CPU or network rates yet, which can't be encoded.
+		queue := agentstats.NewQueue(10)
+		require.NoError(t, queue.Add(&types.StatsJSON{
+			Stats: types.Stats{Read: timestamp},
+			Networks: map[string]types.NetworkStats{
+				"eth0": {RxBytes: 52, TxBytes: 84},
+			},
+		}, agentstats.NonDockerContainerStats{}))
+		for _, query := range []string{"", "?summary=true"} {
+			testTMDSRequest(t, TMDSTestCase[map[string]*v4.StatsHistoryResponse]{
+				path: path + query,
+				setStateExpectations: func(state *mock_dockerstate.MockTaskEngineState) {
+					gomock.InOrder(
+						state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
+						state.EXPECT().ContainerMapByArn(taskARN).Return(containerMap, true),
+					)
+				},
+				setStatsEngineExpectations: func(engine *mock_stats.MockEngine) {
+					engine.EXPECT().ContainerStatsHistory(taskARN, containerID).Return(queue.GetHistory(), nil)
+				},
+				expectedStatusCode:   http.StatusOK,
+				expectedResponseBody: map[string]*v4.StatsHistoryResponse{containerID: {}},
+			})
+		}
+	})
+	t.Run("summary", func(t *testing.T) {
+		testTMDSRequest(t, TMDSTestCase[map[string]*v4.StatsHistoryResponse]{
+			path: path + "?summary=true",
+			setStateExpectations: func(state *mock_dockerstate.MockTaskEngineState) {
+				gomock.InOrder(
+					state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
+					state.EXPECT().ContainerMapByArn(taskARN).Return(containerMap, true),
+				)
+			},
+			setStatsEngineExpectations: func(engine *mock_stats.MockEngine) {
+				engine.EXPECT().ContainerStatsHistory(taskARN, containerID).Return([]agentstats.UsageStats{
+					{Timestamp: timestamp, CPUUsagePerc: 20, MemoryUsageInMegs: 100},
+					{Timestamp: timestamp.Add(time.Second), CPUUsagePerc: 40, MemoryUsageInMegs: 200},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: map[string]*v4.StatsHistoryResponse{containerID: {
+				Summary: &v4.StatsSummary{
+					SampleCount:     2,
+					From:            timestamp,
+					To:              timestamp.Add(time.Second),
+					CPUUsagePercent: v4.MetricSummary{Min: 20, Max: 40, Average: 30},
+					MemoryUsageMiB:  v4.MetricSummary{Min: 100, Max: 200, Average: 150},
+				},
+			}},
+		})
+	})
+}
+
 func TestGetTaskProtection(t *testing.T) {
 	path := fmt.Sprintf("/api/%s/task-protection/v1/state", v3EndpointID)
 
@@ -4095,6 +4216,98 @@ func TestRegisterCheckPacketLossFaultHandler(t *testing.T) {
 	testRegisterFaultHandler(t, tcs, faulthandler.NetworkFaultPath(faulttype.PacketLossFaultType, faulttype.CheckNetworkFaultPostfix), faulttype.CheckNetworkFaultPostfix, faulttype.PacketLossFaultType)
 }
 
+func TestRegisterStartBandwidthFaultHandler(t *testing.T) {
+	setExecExpectations := func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutDuration)
+		mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+		gomock.InOrder(
+			exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+			exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+			mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcCommandEmptyOutput), nil),
+		)
+		exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(5).Return(mockCMD)
+		mockCMD.EXPECT().CombinedOutput().Times(5).Return([]byte(tcCommandEmptyOutput), nil)
+	}
+	tcs := generateCommonNetworkFaultInjectionTestCases("start bandwidth", "running", setExecExpectations, happyNetworkBandwidthReqBody)
+	testRegisterFaultHandler(t, tcs, faulthandler.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.StartNetworkFaultPostfix), faulttype.StartNetworkFaultPostfix, faulttype.BandwidthFaultType)
+}
+
+func TestRegisterStopBandwidthFaultHandler(t *testing.T) {
+	setExecExpectations := func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutDuration)
+		mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+		gomock.InOrder(
+			exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+			exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+			mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcCommandEmptyOutput), nil),
+		)
+	}
+	tcs := generateCommonNetworkFaultInjectionTestCases("stop bandwidth", "stopped", setExecExpectations, nil)
+	testRegisterFaultHandler(t, tcs, faulthandler.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.StopNetworkFaultPostfix), faulttype.StopNetworkFaultPostfix, faulttype.BandwidthFaultType)
+}
+
+func TestRegisterCheckBandwidthFaultHandler(t *testing.T) {
+	setExecExpectations := func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutDuration)
+		mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+		gomock.InOrder(
+			exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+			exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+			mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcBandwidthFaultExistsCommandOutput), nil),
+		)
+	}
+	tcs := generateCommonNetworkFaultInjectionTestCases("check bandwidth", "running", setExecExpectations, nil)
+	testRegisterFaultHandler(t, tcs, faulthandler.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.CheckNetworkFaultPostfix), faulttype.CheckNetworkFaultPostfix, faulttype.BandwidthFaultType)
+}
+
+func TestRegisterStartDNSFaultHandler(t *testing.T) {
+	setExecExpectations := func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutDuration)
+		cmdExec := mock_execwrapper.NewMockCmd(ctrl)
+		gomock.InOrder(
+			exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+			exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(cmdExec),
+			cmdExec.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotFoundError), errors.New("exit status 1")),
+			exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+			exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+		)
+		exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(4).Return(cmdExec)
+		cmdExec.EXPECT().CombinedOutput().Times(4).Return([]byte{}, nil)
+	}
+	tcs := generateCommonNetworkFaultInjectionTestCases("start dns", "running", setExecExpectations, happyNetworkDNSReqBody)
+	testRegisterFaultHandler(t, tcs, faulthandler.NetworkFaultPath(faulttype.DNSFaultType, faulttype.StartNetworkFaultPostfix), faulttype.StartNetworkFaultPostfix, faulttype.DNSFaultType)
+}
+
+func TestRegisterStopDNSFaultHandler(t *testing.T) {
+	setExecExpectations := func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutDuration)
+		cmdExec := mock_execwrapper.NewMockCmd(ctrl)
+		exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+		exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(5).Return(cmdExec)
+		cmdExec.EXPECT().CombinedOutput().Times(4).Return([]byte{}, nil)
+		// The dns-fault chain of the nat table only exists for the nxdomain failure mode.
+		cmdExec.EXPECT().CombinedOutput().Times(1).Return([]byte{}, errors.New("exit status 1"))
+		exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true)
+		exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1)
+	}
+	tcs := generateCommonNetworkFaultInjectionTestCases("stop dns", "stopped", setExecExpectations, nil)
+	testRegisterFaultHandler(t, tcs, faulthandler.NetworkFaultPath(faulttype.DNSFaultType, faulttype.StopNetworkFaultPostfix), faulttype.StopNetworkFaultPostfix, faulttype.DNSFaultType)
+}
+
+func TestRegisterCheckDNSFaultHandler(t *testing.T) {
+	setExecExpectations := func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeoutDuration)
+		cmdExec := mock_execwrapper.NewMockCmd(ctrl)
+		gomock.InOrder(
+			exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+			exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(cmdExec),
+			cmdExec.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+		)
+	}
+	tcs := generateCommonNetworkFaultInjectionTestCases("check dns", "running", setExecExpectations, nil)
+	testRegisterFaultHandler(t, tcs, faulthandler.NetworkFaultPath(faulttype.DNSFaultType, faulttype.CheckNetworkFaultPostfix), faulttype.CheckNetworkFaultPostfix, faulttype.DNSFaultType)
+}
+
 func testRegisterFaultHandler(t *testing.T, tcs []networkFaultTestCase, tmdsEndpoint, faultOperation, faultType string) {
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
@@ -4145,6 +4358,18 @@ func testRegisterFaultHandler(t *testing.T, tcs []networkFaultTestCase, tmdsEndp
 				tmdsAPI = "/api/%s/fault/v1/network-packet-loss/stop"
 			case faulthandler.NetworkFaultPath(faulttype.PacketLossFaultType, faulttype.CheckNetworkFaultPostfix):
 				tmdsAPI = "/api/%s/fault/v1/network-packet-loss/status"
+			case faulthandler.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.StartNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-bandwidth/start"
+			case faulthandler.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.StopNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-bandwidth/stop"
+			case faulthandler.NetworkFaultPath(faulttype.BandwidthFaultType, faulttype.CheckNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-bandwidth/status"
+			case faulthandler.NetworkFaultPath(faulttype.DNSFaultType, faulttype.StartNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-dns/start"
+			case faulthandler.NetworkFaultPath(faulttype.DNSFaultType, faulttype.StopNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-dns/stop"
+			case faulthandler.NetworkFaultPath(faulttype.DNSFaultType, faulttype.CheckNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-dns/status"
 			default:
 				t.Error("Unrecognized TMDS Endpoint")
 			}
@@ -0,0 +1,84 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	agentdoctor "github.com/aws/amazon-ecs-agent/agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// HealthchecksPath is the introspection path to list the instance healthchecks
+	HealthchecksPath = "/v1/healthchecks"
+
+	requestTypeHealthchecks = "introspection/healthchecks"
+)
+
+// HealthchecksResponse is the response listing the instance healthchecks run by the doctor
+type HealthchecksResponse struct {
+	Healthchecks []HealthcheckResponse `json:"Healthchecks"`
+}
+
+// HealthcheckResponse is the status of an instance healthcheck, along with the results of its
+// last runs for healthchecks that keep them
+type HealthcheckResponse struct {
+	Type             string                      `json:"Type"`
+	Status           string                      `json:"Status"`
+	LastUpdated      time.Time                   `json:"LastUpdated"`
+	LastStatusChange time.Time                   `json:"LastStatusChange"`
+	History          []HealthcheckResultResponse `json:"History,omitempty"`
+}
+
+// HealthcheckResultResponse is the result of a run of an instance healthcheck
+type HealthcheckResultResponse struct {
+	Status  string    `json:"Status"`
+	Time    time.Time `json:"Time"`
+	Message string    `json:"Message,omitempty"`
+}
+
+// HealthchecksHandler lists the instance healthchecks run by the doctor along with their status.
+func HealthchecksHandler(doc *doctor.Doctor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := HealthchecksResponse{Healthchecks: []HealthcheckResponse{}}
+		if doc != nil {
+			for _, healthcheck := range *doc.GetHealthchecks() {
+				response.Healthchecks = append(response.Healthchecks, newHealthcheckResponse(healthcheck))
+			}
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, response, requestTypeHealthchecks)
+	}
+}
+
+func newHealthcheckResponse(healthcheck doctor.Healthcheck) HealthcheckResponse {
+	response := HealthcheckResponse{
+		Type:             healthcheck.GetHealthcheckType(),
+		Status:           healthcheck.GetHealthcheckStatus().String(),
+		LastUpdated:      healthcheck.GetHealthcheckTime(),
+		LastStatusChange: healthcheck.GetStatusChangeTime(),
+	}
+	if withHistory, ok := healthcheck.(agentdoctor.HealthcheckWithHistory); ok {
+		for _, result := range withHistory.GetHealthcheckHistory() {
+			response.History = append(response.History, HealthcheckResultResponse{
+				Status:  result.Status.String(),
+				Time:    result.Time,
+				Message: result.Message,
+			})
+		}
+	}
+	return response
+}
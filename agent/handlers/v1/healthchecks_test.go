@@ -0,0 +1,64 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	agentdoctor "github.com/aws/amazon-ecs-agent/agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthchecksHandler(t *testing.T) {
+	missing, err := agentdoctor.NewCustomHealthcheck(agentdoctor.CustomHealthcheckConfig{
+		Name: "mount",
+		Type: agentdoctor.CustomHealthcheckTypeFile,
+		Path: "/nonexistent/mount",
+	})
+	require.NoError(t, err)
+	missing.RunCheck()
+	doc, err := doctor.NewDoctor([]doctor.Healthcheck{missing}, "cluster", "arn")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	HealthchecksHandler(doc)(recorder, httptest.NewRequest(http.MethodGet, HealthchecksPath, nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response HealthchecksResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Healthchecks, 1)
+	healthcheck := response.Healthchecks[0]
+	assert.Equal(t, "Custom:mount", healthcheck.Type)
+	assert.Equal(t, "IMPAIRED", healthcheck.Status)
+	require.Len(t, healthcheck.History, 1)
+	assert.Equal(t, "IMPAIRED", healthcheck.History[0].Status)
+	assert.Contains(t, healthcheck.History[0].Message, "no such file or directory")
+}
+
+func TestHealthchecksHandlerWithoutDoctor(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	HealthchecksHandler(nil)(recorder, httptest.NewRequest(http.MethodGet, HealthchecksPath, nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"Healthchecks":[]}`, recorder.Body.String())
+}
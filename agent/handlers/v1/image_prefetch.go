@@ -0,0 +1,104 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// ImagePrefetchPath is the introspection path to request and list image prefetches
+	ImagePrefetchPath = "/v1/images/prefetch"
+
+	requestTypeImagePrefetch    = "introspection/images/prefetch"
+	invalidImagePrefetchRequest = "InvalidRequest"
+	accessDeniedImagePrefetch   = "AccessDenied"
+	unauthorizedImagePrefetch   = "Unauthorized"
+	// maxImagePrefetchRequestSize bounds the size of prefetch requests, which may contain
+	// whole task definitions
+	maxImagePrefetchRequestSize = 1 << 20
+)
+
+// ImagePrefetcher pulls images ahead of the tasks that use them
+type ImagePrefetcher interface {
+	PrefetchImages(request engine.ImagePrefetchRequest) ([]string, error)
+	GetPrefetchedImages() []engine.PrefetchedImage
+}
+
+// ImagePrefetchResponse is the response listing the images requested to be prefetched
+type ImagePrefetchResponse struct {
+	Images []engine.PrefetchedImage `json:"Images"`
+}
+
+// ImagePrefetchAcceptedResponse is the response to a prefetch request, listing the images
+// whose prefetch has started
+type ImagePrefetchAcceptedResponse struct {
+	AcceptedImages []string `json:"AcceptedImages"`
+}
+
+// ImagePrefetchHandler lists the prefetched images on GET, and starts prefetching the images
+// of an engine.ImagePrefetchRequest on POST. Requests are only accepted from the loopback
+// interface, with the token as bearer token.
+func ImagePrefetchHandler(prefetcher ImagePrefetcher, token []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackRequest(r) {
+			writeImagePrefetchError(w, http.StatusForbidden, accessDeniedImagePrefetch, "requests are only accepted from localhost")
+			return
+		}
+		if !isAuthorizedRequest(r, token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeImagePrefetchError(w, http.StatusUnauthorized, unauthorizedImagePrefetch, "invalid or missing bearer token")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			tmdsutils.WriteJSONResponse(w, http.StatusOK,
+				ImagePrefetchResponse{Images: prefetcher.GetPrefetchedImages()}, requestTypeImagePrefetch)
+		case http.MethodPost:
+			var request engine.ImagePrefetchRequest
+			err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImagePrefetchRequestSize)).Decode(&request)
+			if err != nil {
+				writeImagePrefetchError(w, http.StatusBadRequest, invalidImagePrefetchRequest, "unable to parse the request: "+err.Error())
+				return
+			}
+			accepted, err := prefetcher.PrefetchImages(request)
+			if err != nil {
+				writeImagePrefetchError(w, http.StatusBadRequest, invalidImagePrefetchRequest, err.Error())
+				return
+			}
+			tmdsutils.WriteJSONResponse(w, http.StatusAccepted,
+				ImagePrefetchAcceptedResponse{AcceptedImages: accepted}, requestTypeImagePrefetch)
+		default:
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func writeImagePrefetchError(w http.ResponseWriter, statusCode int, code, message string) {
+	logger.Warn("Invalid image prefetch request", logger.Fields{
+		field.Error: message,
+	})
+	tmdsutils.WriteJSONResponse(w, statusCode, tmdsutils.ErrorMessage{
+		Code:          code,
+		Message:       message,
+		HTTPErrorCode: statusCode,
+	}, requestTypeImagePrefetch)
+}
@@ -0,0 +1,161 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeImagePrefetcher struct {
+	request  engine.ImagePrefetchRequest
+	accepted []string
+	err      error
+	images   []engine.PrefetchedImage
+}
+
+func (p *fakeImagePrefetcher) PrefetchImages(request engine.ImagePrefetchRequest) ([]string, error) {
+	p.request = request
+	return p.accepted, p.err
+}
+
+func (p *fakeImagePrefetcher) GetPrefetchedImages() []engine.PrefetchedImage {
+	return p.images
+}
+
+const testImagePrefetchToken = "s3cr3t"
+
+func newImagePrefetchRequest(method, body, remoteAddr, token string) *http.Request {
+	request := httptest.NewRequest(method, ImagePrefetchPath, strings.NewReader(body))
+	request.RemoteAddr = remoteAddr
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request
+}
+
+func serveImagePrefetch(prefetcher ImagePrefetcher, request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	ImagePrefetchHandler(prefetcher, []byte(testImagePrefetchToken))(recorder, request)
+	return recorder
+}
+
+func TestImagePrefetchHandlerGet(t *testing.T) {
+	prefetcher := &fakeImagePrefetcher{
+		images: []engine.PrefetchedImage{{Image: "busybox:latest", Status: engine.ImagePrefetchPulled}},
+	}
+	recorder := serveImagePrefetch(prefetcher,
+		newImagePrefetchRequest(http.MethodGet, "", "127.0.0.1:40000", testImagePrefetchToken))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response ImagePrefetchResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, prefetcher.images, response.Images)
+}
+
+func TestImagePrefetchHandlerPost(t *testing.T) {
+	prefetcher := &fakeImagePrefetcher{accepted: []string{"busybox:latest", "nginx:latest"}}
+	body := `{"images":["busybox:latest"],"taskDefinitions":[{"family":"web","containerDefinitions":[{"name":"web","image":"nginx:latest"}]}]}`
+	recorder := serveImagePrefetch(prefetcher,
+		newImagePrefetchRequest(http.MethodPost, body, "[::1]:40000", testImagePrefetchToken))
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, []string{"busybox:latest"}, prefetcher.request.Images)
+	require.Len(t, prefetcher.request.TaskDefinitions, 1)
+	assert.Equal(t, "nginx:latest", prefetcher.request.TaskDefinitions[0].ContainerDefinitions[0].Image)
+	var response ImagePrefetchAcceptedResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, prefetcher.accepted, response.AcceptedImages)
+}
+
+func TestImagePrefetchHandlerBadRequests(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+		err  error
+	}{
+		{
+			name: "invalid json",
+			body: `{"images":`,
+		},
+		{
+			name: "prefetch error",
+			body: `{}`,
+			err:  errors.New("no images to prefetch"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveImagePrefetch(&fakeImagePrefetcher{err: tc.err},
+				newImagePrefetchRequest(http.MethodPost, tc.body, "127.0.0.1:40000", testImagePrefetchToken))
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), invalidImagePrefetchRequest)
+		})
+	}
+}
+
+func TestImagePrefetchHandlerMethodNotAllowed(t *testing.T) {
+	recorder := serveImagePrefetch(&fakeImagePrefetcher{},
+		newImagePrefetchRequest(http.MethodDelete, "", "127.0.0.1:40000", testImagePrefetchToken))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET, POST", recorder.Header().Get("Allow"))
+}
+
+func TestImagePrefetchHandlerUnauthorized(t *testing.T) {
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "remote request",
+			remoteAddr:   "10.0.0.1:40000",
+			token:        testImagePrefetchToken,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "missing token",
+			remoteAddr:   "127.0.0.1:40000",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid token",
+			remoteAddr:   "127.0.0.1:40000",
+			token:        "guess",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prefetcher := &fakeImagePrefetcher{accepted: []string{"busybox:latest"}}
+			recorder := serveImagePrefetch(prefetcher,
+				newImagePrefetchRequest(http.MethodPost, `{"images":["busybox:latest"]}`, tc.remoteAddr, tc.token))
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Empty(t, prefetcher.request.Images)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const bearerAuthPrefix = "Bearer "
+
+// isLoopbackRequest returns whether the request was received from the loopback interface,
+// as the introspection server listens on all the interfaces
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isAuthorizedRequest returns whether the request carries the token as bearer token
+func isAuthorizedRequest(r *http.Request, token []byte) bool {
+	authorization := r.Header.Get("Authorization")
+	if len(token) == 0 || !strings.HasPrefix(authorization, bearerAuthPrefix) {
+		return false
+	}
+	requestToken := []byte(strings.TrimPrefix(authorization, bearerAuthPrefix))
+	return subtle.ConstantTimeCompare(requestToken, token) == 1
+}
@@ -0,0 +1,136 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// LogConfigPath is the introspection path to get and change the logger configuration
+	LogConfigPath = "/v1/logconfig"
+
+	requestTypeLogConfig    = "introspection/logconfig"
+	invalidLogConfigRequest = "InvalidRequest"
+	accessDeniedLogConfig   = "AccessDenied"
+	unauthorizedLogConfig   = "Unauthorized"
+	maxLogConfigRequestSize = 4 * 1024
+)
+
+// Injection points for testing purposes
+var (
+	getRuntimeLogConfig    = logger.GetRuntimeConfig
+	applyRuntimeLogConfig  = logger.ApplyRuntimeConfig
+	revertRuntimeLogConfig = logger.RevertRuntimeConfig
+)
+
+// LogConfigRequest changes the logger configuration. The fields that aren't set are left
+// unchanged.
+type LogConfigRequest struct {
+	// Level sets both the driver and the instance log levels, unless they are set
+	Level string `json:"Level,omitempty"`
+	logger.RuntimeConfig
+	// Duration is how long the change lasts before the previous configuration is restored,
+	// e.g. "15m". The change lasts until the agent restarts when it's not set.
+	Duration string `json:"Duration,omitempty"`
+}
+
+// LogConfigResponse is the current logger configuration
+type LogConfigResponse struct {
+	logger.RuntimeConfig
+	// RevertAt is when the previous configuration is restored, if it was changed for a
+	// limited duration
+	RevertAt string `json:"RevertAt,omitempty"`
+}
+
+// LogConfigHandler returns the logger configuration on GET, changes it on PUT and reverts
+// a change made for a limited duration on DELETE. Requests are only accepted from the
+// loopback interface, with the token as bearer token.
+func LogConfigHandler(token []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackRequest(r) {
+			writeLogConfigError(w, http.StatusForbidden, accessDeniedLogConfig, "requests are only accepted from localhost")
+			return
+		}
+		if !isAuthorizedRequest(r, token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeLogConfigError(w, http.StatusUnauthorized, unauthorizedLogConfig, "invalid or missing bearer token")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var request LogConfigRequest
+			err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLogConfigRequestSize)).Decode(&request)
+			if err != nil {
+				writeLogConfigError(w, http.StatusBadRequest, invalidLogConfigRequest, "unable to parse the request: "+err.Error())
+				return
+			}
+			var duration time.Duration
+			if request.Duration != "" {
+				duration, err = time.ParseDuration(request.Duration)
+				if err != nil || duration <= 0 {
+					writeLogConfigError(w, http.StatusBadRequest, invalidLogConfigRequest, "invalid duration: "+request.Duration)
+					return
+				}
+			}
+			if request.Level != "" {
+				if request.DriverLevel == "" {
+					request.DriverLevel = request.Level
+				}
+				if request.InstanceLevel == "" {
+					request.InstanceLevel = request.Level
+				}
+			}
+			if err := applyRuntimeLogConfig(request.RuntimeConfig, duration); err != nil {
+				writeLogConfigError(w, http.StatusBadRequest, invalidLogConfigRequest, err.Error())
+				return
+			}
+		case http.MethodDelete:
+			revertRuntimeLogConfig()
+		default:
+			w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPut, http.MethodDelete}, ", "))
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeLogConfig(w)
+	}
+}
+
+func writeLogConfig(w http.ResponseWriter) {
+	config, revertAt := getRuntimeLogConfig()
+	response := LogConfigResponse{RuntimeConfig: config}
+	if !revertAt.IsZero() {
+		response.RevertAt = revertAt.UTC().Format(time.RFC3339)
+	}
+	tmdsutils.WriteJSONResponse(w, http.StatusOK, response, requestTypeLogConfig)
+}
+
+func writeLogConfigError(w http.ResponseWriter, statusCode int, code, message string) {
+	logger.Warn("Invalid log config request", logger.Fields{
+		field.Error: message,
+	})
+	tmdsutils.WriteJSONResponse(w, statusCode, tmdsutils.ErrorMessage{
+		Code:          code,
+		Message:       message,
+		HTTPErrorCode: statusCode,
+	}, requestTypeLogConfig)
+}
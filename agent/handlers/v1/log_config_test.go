@@ -0,0 +1,204 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLogConfigToken = "s3cr3t"
+
+// fakeRuntimeLogConfig replaces the logger runtime configuration functions for the duration
+// of the test
+type fakeRuntimeLogConfig struct {
+	config      logger.RuntimeConfig
+	revertAt    time.Time
+	revertAfter time.Duration
+	reverted    bool
+}
+
+func newFakeRuntimeLogConfig(t *testing.T) *fakeRuntimeLogConfig {
+	fake := &fakeRuntimeLogConfig{config: logger.RuntimeConfig{
+		DriverLevel:   "info",
+		InstanceLevel: "info",
+		OutputFormat:  "logfmt",
+		RolloverType:  "date",
+	}}
+	getRuntimeLogConfig = func() (logger.RuntimeConfig, time.Time) {
+		return fake.config, fake.revertAt
+	}
+	applyRuntimeLogConfig = func(update logger.RuntimeConfig, revertAfter time.Duration) error {
+		if update.OutputFormat == "xml" {
+			return errors.New("invalid log output format: xml")
+		}
+		fake.config = update
+		fake.revertAfter = revertAfter
+		if revertAfter > 0 {
+			fake.revertAt = time.Date(2026, time.January, 1, 0, 15, 0, 0, time.UTC)
+		}
+		return nil
+	}
+	revertRuntimeLogConfig = func() bool {
+		fake.reverted = true
+		fake.revertAt = time.Time{}
+		return true
+	}
+	t.Cleanup(func() {
+		getRuntimeLogConfig = logger.GetRuntimeConfig
+		applyRuntimeLogConfig = logger.ApplyRuntimeConfig
+		revertRuntimeLogConfig = logger.RevertRuntimeConfig
+	})
+	return fake
+}
+
+func newLogConfigRequest(method, body, remoteAddr, token string) *http.Request {
+	request := httptest.NewRequest(method, LogConfigPath, strings.NewReader(body))
+	request.RemoteAddr = remoteAddr
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request
+}
+
+func serveLogConfig(request *http.Request) (*httptest.ResponseRecorder, LogConfigResponse) {
+	recorder := httptest.NewRecorder()
+	LogConfigHandler([]byte(testLogConfigToken))(recorder, request)
+	var response LogConfigResponse
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	return recorder, response
+}
+
+func TestLogConfigHandlerGet(t *testing.T) {
+	fake := newFakeRuntimeLogConfig(t)
+	recorder, response := serveLogConfig(newLogConfigRequest(http.MethodGet, "", "127.0.0.1:40000", testLogConfigToken))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, fake.config, response.RuntimeConfig)
+	assert.Empty(t, response.RevertAt)
+}
+
+func TestLogConfigHandlerPut(t *testing.T) {
+	fake := newFakeRuntimeLogConfig(t)
+	recorder, response := serveLogConfig(newLogConfigRequest(http.MethodPut,
+		`{"Level": "debug", "InstanceLevel": "warn", "OutputFormat": "json", "Duration": "15m"}`,
+		"[::1]:40000", testLogConfigToken))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, logger.RuntimeConfig{
+		DriverLevel:   "debug",
+		InstanceLevel: "warn",
+		OutputFormat:  "json",
+	}, fake.config)
+	assert.Equal(t, 15*time.Minute, fake.revertAfter)
+	assert.Equal(t, "debug", response.DriverLevel)
+	assert.Equal(t, "2026-01-01T00:15:00Z", response.RevertAt)
+
+	recorder, _ = serveLogConfig(newLogConfigRequest(http.MethodPut, `{"RolloverType": "size"}`,
+		"127.0.0.1:40000", testLogConfigToken))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Zero(t, fake.revertAfter)
+}
+
+func TestLogConfigHandlerDelete(t *testing.T) {
+	fake := newFakeRuntimeLogConfig(t)
+	recorder, _ := serveLogConfig(newLogConfigRequest(http.MethodDelete, "", "127.0.0.1:40000", testLogConfigToken))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, fake.reverted)
+}
+
+func TestLogConfigHandlerErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		body         string
+		remoteAddr   string
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "remote request",
+			method:       http.MethodGet,
+			remoteAddr:   "10.0.0.1:40000",
+			token:        testLogConfigToken,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "missing token",
+			method:       http.MethodGet,
+			remoteAddr:   "127.0.0.1:40000",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid token",
+			method:       http.MethodPut,
+			body:         `{"Level": "debug"}`,
+			remoteAddr:   "127.0.0.1:40000",
+			token:        "guess",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid body",
+			method:       http.MethodPut,
+			body:         `{"Level": `,
+			remoteAddr:   "127.0.0.1:40000",
+			token:        testLogConfigToken,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid duration",
+			method:       http.MethodPut,
+			body:         `{"Level": "debug", "Duration": "-5m"}`,
+			remoteAddr:   "127.0.0.1:40000",
+			token:        testLogConfigToken,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid config",
+			method:       http.MethodPut,
+			body:         `{"OutputFormat": "xml"}`,
+			remoteAddr:   "127.0.0.1:40000",
+			token:        testLogConfigToken,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unsupported method",
+			method:       http.MethodPost,
+			remoteAddr:   "127.0.0.1:40000",
+			token:        testLogConfigToken,
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newFakeRuntimeLogConfig(t)
+			original := fake.config
+			recorder, _ := serveLogConfig(newLogConfigRequest(tc.method, tc.body, tc.remoteAddr, tc.token))
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, original, fake.config)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// TaskExplanationPath is the introspection path to explain what a task is waiting for
+	TaskExplanationPath = "/v1/tasks/explain"
+
+	requestTypeTaskExplanation    = "introspection/tasks/explain"
+	taskExplanationARNQuery       = "taskarn"
+	invalidTaskExplanationRequest = "InvalidRequest"
+	taskExplanationNotFound       = "TaskNotFound"
+)
+
+// TaskExplainer explains what the containers and resources of a task are waiting for
+type TaskExplainer interface {
+	ExplainTask(taskARN string) (*engine.TaskExplanation, bool)
+}
+
+// TaskExplanationHandler returns, for the task whose ARN is in the "taskarn" query parameter,
+// the known and desired status of its containers and resources, the dependencies blocking
+// them and how long they have been waiting.
+func TaskExplanationHandler(explainer TaskExplainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		taskARN := r.URL.Query().Get(taskExplanationARNQuery)
+		if taskARN == "" {
+			tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, tmdsutils.ErrorMessage{
+				Code:          invalidTaskExplanationRequest,
+				Message:       "the " + taskExplanationARNQuery + " query parameter is required",
+				HTTPErrorCode: http.StatusBadRequest,
+			}, requestTypeTaskExplanation)
+			return
+		}
+		explanation, ok := explainer.ExplainTask(taskARN)
+		if !ok {
+			tmdsutils.WriteJSONResponse(w, http.StatusNotFound, tmdsutils.ErrorMessage{
+				Code:          taskExplanationNotFound,
+				Message:       "task " + taskARN + " is not managed by the agent",
+				HTTPErrorCode: http.StatusNotFound,
+			}, requestTypeTaskExplanation)
+			return
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, explanation, requestTypeTaskExplanation)
+	}
+}
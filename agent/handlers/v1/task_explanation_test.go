@@ -0,0 +1,92 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const explainedTaskARN = "arn:aws:ecs:us-west-2:123456789012:task/cluster/abc"
+
+type fakeTaskExplainer struct {
+	explanations map[string]*engine.TaskExplanation
+}
+
+func (e *fakeTaskExplainer) ExplainTask(taskARN string) (*engine.TaskExplanation, bool) {
+	explanation, ok := e.explanations[taskARN]
+	return explanation, ok
+}
+
+func TestTaskExplanationHandler(t *testing.T) {
+	explainer := &fakeTaskExplainer{explanations: map[string]*engine.TaskExplanation{
+		explainedTaskARN: {
+			TaskARN:       explainedTaskARN,
+			KnownStatus:   "NONE",
+			DesiredStatus: "RUNNING",
+			Containers: []engine.EntityExplanation{{
+				Name:          "app",
+				KnownStatus:   "NONE",
+				DesiredStatus: "RUNNING",
+				BlockedBy: []dependencygraph.Blocker{{
+					Type:      dependencygraph.BlockerContainerOrdering,
+					Name:      "init",
+					Condition: "SUCCESS",
+					Reason:    "waiting for container init to satisfy condition SUCCESS",
+				}},
+			}},
+		},
+	}}
+	recorder := httptest.NewRecorder()
+	TaskExplanationHandler(explainer)(recorder,
+		httptest.NewRequest(http.MethodGet, TaskExplanationPath+"?taskarn="+explainedTaskARN, nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response engine.TaskExplanation
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, *explainer.explanations[explainedTaskARN], response)
+}
+
+func TestTaskExplanationHandlerErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		query        string
+		expectedCode int
+	}{
+		{name: "missing task ARN", method: http.MethodGet, expectedCode: http.StatusBadRequest},
+		{name: "unknown task", method: http.MethodGet, query: "?taskarn=unknown", expectedCode: http.StatusNotFound},
+		{name: "invalid method", method: http.MethodPost, query: "?taskarn=" + explainedTaskARN,
+			expectedCode: http.StatusMethodNotAllowed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			TaskExplanationHandler(&fakeTaskExplainer{})(recorder,
+				httptest.NewRequest(tc.method, TaskExplanationPath+tc.query, nil))
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// TaskQueuePath is the introspection path to list the tasks waiting for host resources
+	TaskQueuePath = "/v1/tasks/queue"
+
+	requestTypeTaskQueue = "introspection/tasks/queue"
+)
+
+// TaskQueueGetter lists the tasks waiting for host resources
+type TaskQueueGetter interface {
+	GetTaskQueue() engine.TaskQueue
+}
+
+// TaskQueueHandler lists the tasks waiting for host resources in the order they will be
+// considered for starting, along with the queue policy.
+func TaskQueueHandler(getter TaskQueueGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, getter.GetTaskQueue(), requestTypeTaskQueue)
+	}
+}
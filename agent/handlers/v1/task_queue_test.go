@@ -0,0 +1,60 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTaskQueueGetter struct {
+	queue engine.TaskQueue
+}
+
+func (g *fakeTaskQueueGetter) GetTaskQueue() engine.TaskQueue {
+	return g.queue
+}
+
+func TestTaskQueueHandler(t *testing.T) {
+	getter := &fakeTaskQueueGetter{queue: engine.TaskQueue{
+		Policy: "priority",
+		Tasks: []engine.QueuedTask{{
+			TaskARN:              explainedTaskARN,
+			Priority:             10,
+			UnavailableResources: []string{"CPU"},
+			WaitingFor:           "1m0s",
+		}},
+	}}
+	recorder := httptest.NewRecorder()
+	TaskQueueHandler(getter)(recorder, httptest.NewRequest(http.MethodGet, TaskQueuePath, nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response engine.TaskQueue
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, getter.queue, response)
+
+	recorder = httptest.NewRecorder()
+	TaskQueueHandler(getter)(recorder, httptest.NewRequest(http.MethodPost, TaskQueuePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
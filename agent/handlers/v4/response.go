@@ -29,6 +29,11 @@ import (
 	"github.com/pkg/errors"
 )
 
+// containerRestartingStatus is the known status reported for a container that exited while
+// its restart is delayed by the restart backoff. The container stays known as running to the
+// engine and to ECS, which don't support a container transitioning from stopped to running.
+const containerRestartingStatus = "RESTARTING"
+
 // NewTaskResponse creates a new v4 response object for the task. It augments v2 task response
 // with additional fields for the v4 response.
 func NewTaskResponse(
@@ -146,6 +151,16 @@ func augmentContainerResponse(
 	if dockerContainer.Container.RestartPolicyEnabled() {
 		restartCount := dockerContainer.Container.RestartTracker.GetRestartCount()
 		v4Response.RestartCount = &restartCount
+		if backoff := dockerContainer.Container.RestartTracker.GetRestartBackoff(); backoff > 0 {
+			backoffSeconds := int(backoff.Seconds())
+			v4Response.RestartBackoffSeconds = &backoffSeconds
+		}
+		if nextRestartAt := dockerContainer.Container.RestartTracker.GetNextRestartAt(); !nextRestartAt.IsZero() {
+			v4Response.NextRestartAt = &nextRestartAt
+			if v4Response.ContainerResponse != nil {
+				v4Response.KnownStatus = containerRestartingStatus
+			}
+		}
 	}
 	return v4Response
 }
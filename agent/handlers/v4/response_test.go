@@ -24,10 +24,13 @@ import (
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/container/restart"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	tmdsv2 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v2"
+	tmdsv4 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
 
 	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
@@ -159,3 +162,45 @@ func TestNewTaskContainerResponses(t *testing.T) {
 	assert.Equal(t, "192.168.0.0/24", containerResponse.Networks[0].IPV4SubnetCIDRBlock)
 	assert.Equal(t, subnetGatewayIPV4Address, containerResponse.Networks[0].SubnetGatewayIPV4Address)
 }
+
+func TestAugmentContainerResponseRestartPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	container := &apicontainer.Container{
+		Name: containerName,
+		RestartPolicy: &restart.RestartPolicy{
+			Enabled:             true,
+			BackoffInitialDelay: 10,
+		},
+	}
+	container.RestartTracker = restart.NewRestartTracker(*container.RestartPolicy)
+	container.RestartTracker.RecordRestart()
+	container.RestartTracker.RestartDelay()
+	dockerContainer := &apicontainer.DockerContainer{
+		DockerID:  containerID,
+		Container: container,
+	}
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true)
+
+	resp := augmentContainerResponse(containerID, state, &tmdsv4.ContainerResponse{
+		ContainerResponse: &tmdsv2.ContainerResponse{KnownStatus: "RUNNING"},
+	})
+	require.NotNil(t, resp.RestartCount)
+	assert.Equal(t, 1, *resp.RestartCount)
+	require.NotNil(t, resp.RestartBackoffSeconds)
+	assert.Equal(t, 20, *resp.RestartBackoffSeconds)
+	require.NotNil(t, resp.NextRestartAt)
+	assert.Equal(t, container.RestartTracker.GetNextRestartAt(), *resp.NextRestartAt)
+	// The container is reported as restarting while its restart is delayed by the backoff.
+	assert.Equal(t, containerRestartingStatus, resp.KnownStatus)
+
+	container.RestartTracker.RecordRestart()
+	state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true)
+	resp = augmentContainerResponse(containerID, state, &tmdsv4.ContainerResponse{
+		ContainerResponse: &tmdsv2.ContainerResponse{KnownStatus: "RUNNING"},
+	})
+	assert.Nil(t, resp.NextRestartAt)
+	assert.Equal(t, "RUNNING", resp.KnownStatus)
+}
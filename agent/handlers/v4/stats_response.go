@@ -14,6 +14,8 @@
 package v4
 
 import (
+	"math"
+
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	response "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
@@ -55,3 +57,56 @@ func NewV4TaskStatsResponse(taskARN string,
 
 	return resp, nil
 }
+
+// NewV4TaskStatsHistoryResponse returns the stats samples buffered for each container of the task
+func NewV4TaskStatsHistoryResponse(taskARN string,
+	state dockerstate.TaskEngineState,
+	statsEngine stats.Engine) (map[string][]response.StatsSample, error) {
+
+	containerMap, ok := state.ContainerMapByArn(taskARN)
+	if !ok {
+		return nil, errors.Errorf(
+			"v4 task stats history response: unable to lookup containers for task %s",
+			taskARN)
+	}
+
+	resp := make(map[string][]response.StatsSample)
+	for _, dockerContainer := range containerMap {
+		containerID := dockerContainer.DockerID
+		history, err := statsEngine.ContainerStatsHistory(taskARN, containerID)
+		if err != nil {
+			seelog.Warnf("V4 task stats history response: Unable to get stats for container '%s' for task '%s': %v",
+				containerID, taskARN, err)
+			resp[containerID] = []response.StatsSample{}
+			continue
+		}
+
+		samples := make([]response.StatsSample, 0, len(history))
+		for _, usageStats := range history {
+			if math.IsNaN(float64(usageStats.CPUUsagePerc)) {
+				// The first sample of a container has no utilization yet, as it is calculated
+				// against the previous sample.
+				continue
+			}
+			sample := response.StatsSample{
+				Timestamp:         usageStats.Timestamp,
+				CPUUsagePercent:   float64(usageStats.CPUUsagePerc),
+				MemoryUsageMiB:    uint64(usageStats.MemoryUsageInMegs),
+				StorageReadBytes:  usageStats.StorageReadBytes,
+				StorageWriteBytes: usageStats.StorageWriteBytes,
+			}
+			if usageStats.NetworkStats != nil &&
+				!math.IsNaN(float64(usageStats.NetworkStats.RxBytesPerSecond)) &&
+				!math.IsNaN(float64(usageStats.NetworkStats.TxBytesPerSecond)) {
+				rx := float64(usageStats.NetworkStats.RxBytesPerSecond)
+				tx := float64(usageStats.NetworkStats.TxBytesPerSecond)
+				sample.NetworkRxBytesPerSecond = &rx
+				sample.NetworkTxBytesPerSecond = &tx
+			}
+			samples = append(samples, sample)
+		}
+		resp[containerID] = samples
+	}
+
+	return resp, nil
+}
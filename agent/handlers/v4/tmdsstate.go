@@ -156,6 +156,7 @@ func (s *TMDSAgentState) getTaskMetadata(v3EndpointID string, includeTags bool,
 	}
 
 	taskResponse.FaultInjectionEnabled = task.IsFaultInjectionEnabled()
+	taskResponse.EgressPolicy = task.GetEgressPolicy()
 	if includeTaskNetworkConfig {
 		var taskNetworkConfig *tmdsv4.TaskNetworkConfig
 		if task.IsNetworkModeHost() {
@@ -216,3 +217,21 @@ func (s *TMDSAgentState) GetTaskStats(v3EndpointID string) (map[string]*tmdsv4.S
 
 	return taskStatsResponse, nil
 }
+
+func (s *TMDSAgentState) GetTaskStatsHistory(v3EndpointID string) (map[string][]tmdsv4.StatsSample, error) {
+	taskARN, ok := s.state.TaskARNByV3EndpointID(v3EndpointID)
+	if !ok {
+		return nil, tmdsv4.NewErrorStatsLookupFailure(fmt.Sprintf(
+			"unable to get task arn from request: unable to get task Arn from v3 endpoint ID: %s",
+			v3EndpointID))
+	}
+
+	history, err := NewV4TaskStatsHistoryResponse(taskARN, s.state, s.statsEngine)
+	if err != nil {
+		return nil, tmdsv4.NewErrorStatsFetchFailure(
+			fmt.Sprintf("Unable to get task stats history for: %s", taskARN),
+			err)
+	}
+
+	return history, nil
+}
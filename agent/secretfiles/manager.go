@@ -0,0 +1,277 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package secretfiles writes the values of the secrets of containers to files on a tmpfs
+// mounted into the containers, so that they pick up the values of rotated secrets without
+// being restarted.
+package secretfiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/arn"
+
+	"github.com/cihub/seelog"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/pborman/uuid"
+)
+
+const (
+	// secretFilesEnvironmentVariable is the environment variable passed to the container for
+	// the path of the directory of its secret files
+	secretFilesEnvironmentVariable = "ECS_CONTAINER_SECRETS_DIR"
+	mountPoint                     = "/opt/ecs/secrets"
+	// versionFile holds a version incremented each time the secret files of a container
+	// change. Secret names are environment variable names, which don't start with a dot.
+	versionFile = ".version"
+	// dataLink links to the directory of the current version of the secret files
+	dataLink = "..data"
+	// versionDirPrefix prefixes the version in the name of the directory of a version
+	versionDirPrefix = ".."
+	// tempLink is the temporary link renamed over the data link and the secret files
+	tempLink              = "..data_tmp"
+	secretFilePerm        = 0444
+	secretDirPerm         = 0755
+	selinuxSecurityOption = "selinux"
+)
+
+// Manager writes the values of the environment variable secrets of containers to files
+type Manager interface {
+	Create(*dockercontainer.Config, *dockercontainer.HostConfig, *apitask.Task, *apicontainer.Container, []string) error
+	Update(*apitask.Task, *apicontainer.Container) (bool, error)
+	Clean(string) error
+}
+
+// secretFilesManager implements the Manager interface
+type secretFilesManager struct {
+	// dir is the directory the secret files are written to. It must be a tmpfs, at the same
+	// path on the host as in the agent container.
+	dir string
+	// writeLock serializes the writes of the secret files
+	writeLock sync.Mutex
+}
+
+// NewManager creates a secretFilesManager writing to the secret files directory of the config
+func NewManager(cfg *config.Config) Manager {
+	return &secretFilesManager{
+		dir: cfg.SecretFilesDir,
+	}
+}
+
+// Injection point for testing purposes
+var checkSecretFilesDir = checkTmpfs
+
+// Create writes the secret files of a container and adds their directory to the container's
+// mounted host volumes. It's a no-op for containers without environment variable secrets.
+func (manager *secretFilesManager) Create(config *dockercontainer.Config, hostConfig *dockercontainer.HostConfig,
+	task *apitask.Task, container *apicontainer.Container, dockerSecurityOptions []string) error {
+	values := task.GetContainerSecretValues(container)
+	if len(values) == 0 {
+		return nil
+	}
+	if err := checkSecretFilesDir(manager.dir); err != nil {
+		return fmt.Errorf("create secret files for task %s container %s: %w", task.Arn, container.Name, err)
+	}
+
+	containerDir, err := manager.containerDir(task.Arn, container.Name)
+	if err != nil {
+		return err
+	}
+	if err := mkdirAll(containerDir, secretDirPerm); err != nil {
+		return fmt.Errorf("creating secret files directory for task %s: %w", task.Arn, err)
+	}
+	if _, err := manager.write(containerDir, values); err != nil {
+		return fmt.Errorf("write secret files for task %s container %s: %w", task.Arn, container.Name, err)
+	}
+
+	// The mount point has a random suffix so it doesn't conflict with the container's mounts
+	containerMountPoint := filepath.Join(mountPoint, uuid.New())
+	bindMode := "ro"
+	for _, option := range dockerSecurityOptions {
+		if option == selinuxSecurityOption {
+			bindMode += ",Z"
+		}
+	}
+	hostConfig.Binds = append(hostConfig.Binds, fmt.Sprintf("%s:%s:%s", containerDir, containerMountPoint, bindMode))
+	config.Env = append(config.Env, fmt.Sprintf("%s=%s", secretFilesEnvironmentVariable, containerMountPoint))
+	return nil
+}
+
+// Update writes the secret files of a container whose secret values changed, and returns
+// whether they changed. It's a no-op for containers whose secret files weren't created.
+func (manager *secretFilesManager) Update(task *apitask.Task, container *apicontainer.Container) (bool, error) {
+	containerDir, err := manager.containerDir(task.Arn, container.Name)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(containerDir); os.IsNotExist(err) {
+		return false, nil
+	}
+	changed, err := manager.write(containerDir, task.GetContainerSecretValues(container))
+	if err != nil {
+		return false, fmt.Errorf("write secret files for task %s container %s: %w", task.Arn, container.Name, err)
+	}
+	return changed, nil
+}
+
+var removeAll = os.RemoveAll
+
+// Clean removes the secret files of all the containers of a task
+func (manager *secretFilesManager) Clean(taskARN string) error {
+	taskDir, err := manager.taskDir(taskARN)
+	if err != nil {
+		return err
+	}
+	return removeAll(taskDir)
+}
+
+var mkdirAll = os.MkdirAll
+
+// taskDir returns the directory of the secret files of a task
+func (manager *secretFilesManager) taskDir(taskARN string) (string, error) {
+	taskID, err := arn.TaskIdFromArn(taskARN)
+	if err != nil {
+		return "", fmt.Errorf("get secret files directory of task %s: %w", taskARN, err)
+	}
+	return filepath.Join(manager.dir, taskID), nil
+}
+
+// containerDir returns the directory of the secret files of a container
+func (manager *secretFilesManager) containerDir(taskARN, containerName string) (string, error) {
+	taskDir, err := manager.taskDir(taskARN)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(taskDir, containerName), nil
+}
+
+// write writes the secret files of a directory if any value changed, and returns whether
+// any value changed. Each version of the secret files is written to a new directory that
+// the data link is then atomically switched to, so that containers never read a mix of
+// versions. The secret files and the version file are links to their name in the data link.
+func (manager *secretFilesManager) write(dir string, values map[string]string) (bool, error) {
+	manager.writeLock.Lock()
+	defer manager.writeLock.Unlock()
+
+	files := make(map[string][]byte, len(values)+1)
+	changed := false
+	for name, value := range values {
+		if !isValidFileName(name) {
+			seelog.Warnf("Skipping the secret file of secret %q in %s, its name isn't a valid file name", name, dir)
+			continue
+		}
+		files[name] = []byte(value)
+		if current, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(current) != value {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	version := readVersion(dir) + 1
+	files[versionFile] = []byte(strconv.FormatUint(version, 10) + "\n")
+	return true, writeVersionDir(dir, version, files)
+}
+
+// writeVersionDir writes the files of a version to its directory, switches the data link to
+// it and removes the directory of the previous version
+func writeVersionDir(dir string, version uint64, files map[string][]byte) error {
+	versionDir := versionDirPrefix + strconv.FormatUint(version, 10)
+	versionPath := filepath.Join(dir, versionDir)
+	// Leftover of a write that failed before the data link was switched
+	if err := os.RemoveAll(versionPath); err != nil {
+		return err
+	}
+	if err := os.Mkdir(versionPath, secretDirPerm); err != nil {
+		return err
+	}
+	for name, data := range files {
+		if err := writeFile(filepath.Join(versionPath, name), data); err != nil {
+			os.RemoveAll(versionPath)
+			return err
+		}
+	}
+
+	previousDir, _ := os.Readlink(filepath.Join(dir, dataLink))
+	if err := replaceLink(dir, dataLink, versionDir); err != nil {
+		os.RemoveAll(versionPath)
+		return err
+	}
+	for name := range files {
+		target := filepath.Join(dataLink, name)
+		if current, err := os.Readlink(filepath.Join(dir, name)); err == nil && current == target {
+			continue
+		}
+		if err := replaceLink(dir, name, target); err != nil {
+			return err
+		}
+	}
+	if previousDir != "" && previousDir != versionDir {
+		if err := os.RemoveAll(filepath.Join(dir, previousDir)); err != nil {
+			seelog.Warnf("Unable to remove the previous secret files %s: %v", filepath.Join(dir, previousDir), err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes a read-only file
+func writeFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, secretFilePerm); err != nil {
+		return err
+	}
+	return os.Chmod(path, secretFilePerm)
+}
+
+// replaceLink atomically replaces a file of a directory with a relative symbolic link, by
+// renaming a temporary link created in the same directory
+func replaceLink(dir, name, target string) error {
+	tempPath := filepath.Join(dir, tempLink)
+	if err := os.Remove(tempPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Symlink(target, tempPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, filepath.Join(dir, name)); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+// isValidFileName returns whether a secret name can be used as the name of its file, without
+// escaping the directory nor replacing the version file
+func isValidFileName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
+
+// readVersion returns the version of the secret files in a directory, or 0 if none was written
+func readVersion(dir string) uint64 {
+	data, err := os.ReadFile(filepath.Join(dir, versionFile))
+	if err != nil {
+		return 0
+	}
+	version, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return version
+}
@@ -0,0 +1,195 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN       = "arn:aws:ecs:us-west-2:123456789012:task/cluster/task-id"
+	containerName = "app"
+	valueFrom     = "arn:aws:secretsmanager:us-west-2:123456789012:secret:db-password"
+)
+
+func setup(t *testing.T) (Manager, string) {
+	checkSecretFilesDir = func(string) error { return nil }
+	t.Cleanup(func() { checkSecretFilesDir = checkTmpfs })
+	dir := t.TempDir()
+	return NewManager(&config.Config{SecretFilesDir: dir}), dir
+}
+
+func testTask(secretValue string) (*apitask.Task, *asmsecret.ASMSecretResource) {
+	secrets := []apicontainer.Secret{
+		{
+			Name:      "DB_PASSWORD",
+			ValueFrom: valueFrom,
+			Region:    "us-west-2",
+			Provider:  apicontainer.SecretProviderASM,
+			Type:      apicontainer.SecretTypeEnv,
+		},
+		{
+			Name:      "splunk-token",
+			ValueFrom: valueFrom,
+			Region:    "us-west-2",
+			Provider:  apicontainer.SecretProviderASM,
+			Target:    apicontainer.SecretTargetLogDriver,
+		},
+	}
+	asmRes := asmsecret.NewASMSecretResource(taskARN, nil, "", nil, nil)
+	asmRes.SetCachedSecretValue(secrets[0].GetSecretResourceCacheKey(), secretValue)
+	task := &apitask.Task{
+		Arn:        taskARN,
+		Containers: []*apicontainer.Container{{Name: containerName, Secrets: secrets}},
+		ResourcesMapUnsafe: map[string][]taskresource.TaskResource{
+			asmsecret.ResourceName: {asmRes},
+		},
+	}
+	return task, asmRes
+}
+
+func readSecretFile(t *testing.T, dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "task-id", containerName, name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCreateUpdateClean(t *testing.T) {
+	manager, dir := setup(t)
+	task, asmRes := testTask("hunter2")
+	config := &dockercontainer.Config{}
+	hostConfig := &dockercontainer.HostConfig{}
+
+	require.NoError(t, manager.Create(config, hostConfig, task, task.Containers[0], []string{"selinux"}))
+	assert.Equal(t, "hunter2", readSecretFile(t, dir, "DB_PASSWORD"))
+	assert.Equal(t, "1\n", readSecretFile(t, dir, versionFile))
+	_, err := os.Stat(filepath.Join(dir, "task-id", containerName, "splunk-token"))
+	assert.True(t, os.IsNotExist(err), "log driver secrets aren't written to files")
+
+	require.Len(t, hostConfig.Binds, 1)
+	bind := strings.Split(hostConfig.Binds[0], ":")
+	require.Len(t, bind, 3)
+	assert.Equal(t, filepath.Join(dir, "task-id", containerName), bind[0])
+	assert.True(t, strings.HasPrefix(bind[1], mountPoint+"/"))
+	assert.Equal(t, "ro,Z", bind[2])
+	assert.Equal(t, []string{secretFilesEnvironmentVariable + "=" + bind[1]}, config.Env)
+
+	changed, err := manager.Update(task, task.Containers[0])
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "1\n", readSecretFile(t, dir, versionFile))
+
+	asmRes.SetCachedSecretValue(task.Containers[0].Secrets[0].GetSecretResourceCacheKey(), "correct-horse")
+	changed, err = manager.Update(task, task.Containers[0])
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "correct-horse", readSecretFile(t, dir, "DB_PASSWORD"))
+	assert.Equal(t, "2\n", readSecretFile(t, dir, versionFile))
+
+	// The secret files link to the directory of the current version, the previous one is removed
+	containerDir := filepath.Join(dir, "task-id", containerName)
+	target, err := os.Readlink(filepath.Join(containerDir, dataLink))
+	require.NoError(t, err)
+	assert.Equal(t, "..2", target)
+	target, err = os.Readlink(filepath.Join(containerDir, "DB_PASSWORD"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dataLink, "DB_PASSWORD"), target)
+	_, err = os.Stat(filepath.Join(containerDir, "..1"))
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, manager.Clean(taskARN))
+	_, err = os.Stat(filepath.Join(dir, "task-id"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCreateWithoutEnvSecrets(t *testing.T) {
+	manager, dir := setup(t)
+	task, _ := testTask("hunter2")
+	task.Containers[0].Secrets = task.Containers[0].Secrets[1:]
+	hostConfig := &dockercontainer.HostConfig{}
+
+	require.NoError(t, manager.Create(&dockercontainer.Config{}, hostConfig, task, task.Containers[0], nil))
+	assert.Empty(t, hostConfig.Binds)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCreateNotTmpfs(t *testing.T) {
+	manager, dir := setup(t)
+	checkSecretFilesDir = func(string) error { return errors.New("not a tmpfs") }
+	task, _ := testTask("hunter2")
+	hostConfig := &dockercontainer.HostConfig{}
+
+	assert.Error(t, manager.Create(&dockercontainer.Config{}, hostConfig, task, task.Containers[0], nil))
+	assert.Empty(t, hostConfig.Binds)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no secret is written outside a tmpfs")
+}
+
+func TestUpdateWithoutSecretFiles(t *testing.T) {
+	manager, dir := setup(t)
+	task, _ := testTask("hunter2")
+
+	changed, err := manager.Update(task, task.Containers[0])
+	require.NoError(t, err)
+	assert.False(t, changed)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestUpdateReplacesRegularFiles(t *testing.T) {
+	manager, dir := setup(t)
+	task, _ := testTask("correct-horse")
+	containerDir := filepath.Join(dir, "task-id", containerName)
+	require.NoError(t, os.MkdirAll(containerDir, secretDirPerm))
+	require.NoError(t, os.WriteFile(filepath.Join(containerDir, "DB_PASSWORD"), []byte("hunter2"), secretFilePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(containerDir, versionFile), []byte("3\n"), secretFilePerm))
+
+	changed, err := manager.Update(task, task.Containers[0])
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "correct-horse", readSecretFile(t, dir, "DB_PASSWORD"))
+	assert.Equal(t, "4\n", readSecretFile(t, dir, versionFile))
+	target, err := os.Readlink(filepath.Join(containerDir, versionFile))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dataLink, versionFile), target)
+}
+
+func TestIsValidFileName(t *testing.T) {
+	assert.True(t, isValidFileName("DB_PASSWORD"))
+	assert.False(t, isValidFileName(""))
+	assert.False(t, isValidFileName(versionFile))
+	assert.False(t, isValidFileName(".."))
+	assert.False(t, isValidFileName("../escape"))
+}
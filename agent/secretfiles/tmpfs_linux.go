@@ -0,0 +1,36 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkTmpfs returns an error unless path is on a tmpfs, so that the values of secrets are
+// never written to a disk
+func checkTmpfs(path string) error {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return fmt.Errorf("unable to stat secret files directory %s: %w", path, err)
+	}
+	if stat.Type != unix.TMPFS_MAGIC {
+		return fmt.Errorf("secret files directory %s is not a tmpfs", path)
+	}
+	return nil
+}
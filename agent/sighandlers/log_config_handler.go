@@ -0,0 +1,54 @@
+//go:build !windows
+// +build !windows
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sighandlers
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// debugLogConfig is the logger configuration applied when the agent receives a SIGHUP
+var debugLogConfig = logger.RuntimeConfig{
+	DriverLevel:   "debug",
+	InstanceLevel: "debug",
+}
+
+// StartLogConfigHandler switches the log level to debug for debugLogDuration when the agent
+// receives a SIGHUP, before the previous log configuration is restored. Another SIGHUP in the
+// meantime restarts the countdown.
+func StartLogConfigHandler(debugLogDuration time.Duration) {
+	signalChannel := make(chan os.Signal, 1)
+	signal.Notify(signalChannel, syscall.SIGHUP)
+	go func() {
+		for range signalChannel {
+			if err := logger.ApplyRuntimeConfig(debugLogConfig, debugLogDuration); err != nil {
+				logger.Error("Unable to switch the log level to debug", logger.Fields{
+					field.Error: err,
+				})
+				continue
+			}
+			logger.Info("Switched the log level to debug after receiving SIGHUP", logger.Fields{
+				"duration": debugLogDuration.String(),
+			})
+		}
+	}()
+}
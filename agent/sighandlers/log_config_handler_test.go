@@ -0,0 +1,42 @@
+//go:build !windows && unit
+// +build !windows,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sighandlers
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogConfigHandler(t *testing.T) {
+	defer logger.RevertRuntimeConfig()
+	StartLogConfigHandler(time.Hour)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool {
+		_, revertAt := logger.GetRuntimeConfig()
+		return !revertAt.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+	config, _ := logger.GetRuntimeConfig()
+	assert.Equal(t, "debug", config.DriverLevel)
+	assert.Equal(t, "debug", config.InstanceLevel)
+}
@@ -0,0 +1,244 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package standalone runs the agent without the ECS control plane. Tasks are read from the
+// task-definition files of a local directory instead of being received in ACS payloads, and
+// the state changes are written to a local log instead of being submitted to ECS.
+package standalone
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/eventhandler/localsink"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+const (
+	// maxStateChangeLogSize is the size the state change log is rotated at
+	maxStateChangeLogSize = 10 * 1024 * 1024
+	// rotatedStateChangeLogSuffix is appended to the path of the state change log to keep
+	// its previous content when it's rotated. Only one rotated log is kept.
+	rotatedStateChangeLogSuffix = ".1"
+)
+
+var errNotSupported = errors.New("not supported in standalone mode")
+
+// stateChangeLogClient is an ECS client writing the state changes submitted by the agent to
+// a local log, as lines of JSON, instead of submitting them to ECS. The other ECS APIs
+// aren't available in standalone mode.
+type stateChangeLogClient struct {
+	// hostResourcesClient computes the resources of the host, which doesn't call ECS
+	hostResourcesClient ecs.ECSClient
+	lock                sync.Mutex
+	logPath             string
+	log                 *os.File
+	// size is the size of the log, which is rotated once it would exceed maxSize
+	size    int64
+	maxSize int64
+}
+
+// NewStateChangeLogClient returns an ECS client writing the submitted state changes to the
+// log at logPath. The log is rotated to logPath.1 once it reaches 10 MiB. The host resources
+// are still computed by the client passed in.
+func NewStateChangeLogClient(hostResourcesClient ecs.ECSClient, logPath string) (ecs.ECSClient, error) {
+	return newStateChangeLogClient(hostResourcesClient, logPath, maxStateChangeLogSize)
+}
+
+func newStateChangeLogClient(hostResourcesClient ecs.ECSClient, logPath string,
+	maxSize int64) (*stateChangeLogClient, error) {
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return nil, err
+	}
+	client := &stateChangeLogClient{
+		hostResourcesClient: hostResourcesClient,
+		logPath:             logPath,
+		maxSize:             maxSize,
+	}
+	if err := client.open(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// open opens the log for appending, the lock must be held by the caller once the client
+// is in use
+func (client *stateChangeLogClient) open() error {
+	log, err := os.OpenFile(client.logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := log.Stat()
+	if err != nil {
+		log.Close()
+		return err
+	}
+	client.log = log
+	client.size = info.Size()
+	return nil
+}
+
+// rotate replaces the previously rotated log with the current one and starts a new one, the
+// lock must be held by the caller
+func (client *stateChangeLogClient) rotate() error {
+	if err := client.log.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(client.logPath, client.logPath+rotatedStateChangeLogSuffix); err != nil {
+		// Keep appending to the current log rather than losing the state changes
+		if openErr := client.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	return client.open()
+}
+
+func (client *stateChangeLogClient) write(events ...*localsink.Event) error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		if client.size > 0 && client.size+int64(len(data)) > client.maxSize {
+			if err := client.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := client.log.Write(data)
+		client.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SubmitTaskStateChange writes the state change of the task, and of the containers and
+// managed agents submitted along with it, to the log
+func (client *stateChangeLogClient) SubmitTaskStateChange(change ecs.TaskStateChange) error {
+	now := time.Now().UTC()
+	events := []*localsink.Event{{
+		Type:    localsink.EventTypeTask,
+		Time:    now,
+		TaskARN: change.TaskARN,
+		Status:  change.Status.String(),
+		Reason:  change.Reason,
+	}}
+	for _, container := range change.Containers {
+		event := &localsink.Event{
+			Type:          localsink.EventTypeContainer,
+			Time:          now,
+			TaskARN:       change.TaskARN,
+			ContainerName: aws.ToString(container.ContainerName),
+			RuntimeID:     aws.ToString(container.RuntimeId),
+			Status:        aws.ToString(container.Status),
+			Reason:        aws.ToString(container.Reason),
+			ImageDigest:   aws.ToString(container.ImageDigest),
+		}
+		if container.ExitCode != nil {
+			exitCode := int(aws.ToInt32(container.ExitCode))
+			event.ExitCode = &exitCode
+		}
+		events = append(events, event)
+	}
+	for _, managedAgent := range change.ManagedAgents {
+		events = append(events, &localsink.Event{
+			Type:             localsink.EventTypeManagedAgent,
+			Time:             now,
+			TaskARN:          change.TaskARN,
+			ContainerName:    aws.ToString(managedAgent.ContainerName),
+			ManagedAgentName: string(managedAgent.ManagedAgentName),
+			Status:           aws.ToString(managedAgent.Status),
+			Reason:           aws.ToString(managedAgent.Reason),
+		})
+	}
+	return client.write(events...)
+}
+
+// SubmitContainerStateChange writes the state change of the container to the log
+func (client *stateChangeLogClient) SubmitContainerStateChange(change ecs.ContainerStateChange) error {
+	return client.write(&localsink.Event{
+		Type:          localsink.EventTypeContainer,
+		Time:          time.Now().UTC(),
+		TaskARN:       change.TaskArn,
+		ContainerName: change.ContainerName,
+		RuntimeID:     change.RuntimeID,
+		Status:        change.Status.String(),
+		Reason:        change.Reason,
+		ExitCode:      change.ExitCode,
+		ImageDigest:   change.ImageDigest,
+	})
+}
+
+// SubmitAttachmentStateChange writes the state change of the attachment to the log
+func (client *stateChangeLogClient) SubmitAttachmentStateChange(change ecs.AttachmentStateChange) error {
+	if change.Attachment == nil {
+		return errors.New("attachment state change without attachment")
+	}
+	status := change.Attachment.GetAttachmentStatus()
+	return client.write(&localsink.Event{
+		Type:          localsink.EventTypeAttachment,
+		Time:          time.Now().UTC(),
+		AttachmentARN: change.Attachment.GetAttachmentARN(),
+		Status:        status.String(),
+	})
+}
+
+// GetHostResources returns the resources of the host
+func (client *stateChangeLogClient) GetHostResources() (map[string]types.Resource, error) {
+	return client.hostResourcesClient.GetHostResources()
+}
+
+// GetResourceTags returns no tags, tasks don't have tags in standalone mode
+func (client *stateChangeLogClient) GetResourceTags(resourceArn string) ([]types.Tag, error) {
+	return nil, nil
+}
+
+func (client *stateChangeLogClient) RegisterContainerInstance(existingContainerInstanceArn string,
+	attributes []types.Attribute, tags []types.Tag, registrationToken string, platformDevices []types.PlatformDevice,
+	outpostARN string) (string, string, error) {
+	return "", "", errNotSupported
+}
+
+func (client *stateChangeLogClient) DiscoverPollEndpoint(containerInstanceArn string) (string, error) {
+	return "", errNotSupported
+}
+
+func (client *stateChangeLogClient) DiscoverTelemetryEndpoint(containerInstanceArn string) (string, error) {
+	return "", errNotSupported
+}
+
+func (client *stateChangeLogClient) DiscoverServiceConnectEndpoint(containerInstanceArn string) (string, error) {
+	return "", errNotSupported
+}
+
+func (client *stateChangeLogClient) DiscoverSystemLogsEndpoint(containerInstanceArn string,
+	availabilityZone string) (string, error) {
+	return "", errNotSupported
+}
+
+func (client *stateChangeLogClient) UpdateContainerInstancesState(instanceARN string,
+	status types.ContainerInstanceStatus) error {
+	return errNotSupported
+}
@@ -0,0 +1,143 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/eventhandler/localsink"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
+	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTaskARN = "arn:aws:ecs:us-west-2:000000000000:task/edge/app-0123456789ab"
+
+func readEvents(t *testing.T, path string) []localsink.Event {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var events []localsink.Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event localsink.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestStateChangeLogClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hostResourcesClient := mock_ecs.NewMockECSClient(ctrl)
+	logPath := filepath.Join(t.TempDir(), "logs", "state-changes.log")
+	client, err := NewStateChangeLogClient(hostResourcesClient, logPath)
+	require.NoError(t, err)
+
+	exitCode := 1
+	require.NoError(t, client.SubmitContainerStateChange(ecs.ContainerStateChange{
+		TaskArn:       testTaskARN,
+		ContainerName: "app",
+		RuntimeID:     "abc",
+		Status:        apicontainerstatus.ContainerRunning,
+	}))
+	require.NoError(t, client.SubmitTaskStateChange(ecs.TaskStateChange{
+		TaskARN: testTaskARN,
+		Status:  apitaskstatus.TaskStopped,
+		Reason:  "Essential container in task exited",
+		Containers: []types.ContainerStateChange{{
+			ContainerName: aws.String("app"),
+			Status:        aws.String("STOPPED"),
+			ExitCode:      aws.Int32(int32(exitCode)),
+		}},
+	}))
+
+	events := readEvents(t, logPath)
+	require.Len(t, events, 3)
+	assert.Equal(t, localsink.EventTypeContainer, events[0].Type)
+	assert.Equal(t, "RUNNING", events[0].Status)
+	assert.Equal(t, "abc", events[0].RuntimeID)
+	assert.Equal(t, localsink.EventTypeTask, events[1].Type)
+	assert.Equal(t, testTaskARN, events[1].TaskARN)
+	assert.Equal(t, "STOPPED", events[1].Status)
+	assert.Equal(t, "Essential container in task exited", events[1].Reason)
+	assert.Equal(t, localsink.EventTypeContainer, events[2].Type)
+	assert.Equal(t, testTaskARN, events[2].TaskARN)
+	assert.Equal(t, &exitCode, events[2].ExitCode)
+
+	resources := map[string]types.Resource{"CPU": {Name: aws.String("CPU")}}
+	hostResourcesClient.EXPECT().GetHostResources().Return(resources, nil)
+	hostResources, err := client.GetHostResources()
+	require.NoError(t, err)
+	assert.Equal(t, resources, hostResources)
+
+	tags, err := client.GetResourceTags(testTaskARN)
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+	_, _, err = client.RegisterContainerInstance("", nil, nil, "", nil, "")
+	assert.ErrorIs(t, err, errNotSupported)
+	_, err = client.DiscoverPollEndpoint("")
+	assert.ErrorIs(t, err, errNotSupported)
+}
+
+func TestStateChangeLogClientAppends(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "state-changes.log")
+	for i := 0; i < 2; i++ {
+		client, err := NewStateChangeLogClient(nil, logPath)
+		require.NoError(t, err)
+		require.NoError(t, client.SubmitTaskStateChange(ecs.TaskStateChange{
+			TaskARN: testTaskARN,
+			Status:  apitaskstatus.TaskRunning,
+		}))
+	}
+	assert.Len(t, readEvents(t, logPath), 2)
+}
+
+func TestStateChangeLogClientRotates(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "state-changes.log")
+	change := ecs.TaskStateChange{
+		TaskARN: testTaskARN,
+		Status:  apitaskstatus.TaskRunning,
+	}
+	client, err := NewStateChangeLogClient(nil, logPath)
+	require.NoError(t, err)
+	require.NoError(t, client.SubmitTaskStateChange(change))
+	info, err := os.Stat(logPath)
+	require.NoError(t, err)
+
+	// The size of the existing log counts, and the log fits about two events
+	rotatingClient, err := newStateChangeLogClient(nil, logPath, info.Size()*5/2)
+	require.NoError(t, err)
+	require.NoError(t, rotatingClient.SubmitTaskStateChange(change))
+	assert.Len(t, readEvents(t, logPath), 2)
+	assert.NoFileExists(t, logPath+rotatedStateChangeLogSuffix)
+
+	require.NoError(t, rotatingClient.SubmitTaskStateChange(change))
+	assert.Len(t, readEvents(t, logPath), 1)
+	assert.Len(t, readEvents(t, logPath+rotatedStateChangeLogSuffix), 2)
+}
@@ -0,0 +1,314 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	taskFileExtension = ".json"
+	// rescanInterval is the interval at which the directory is scanned, in case file
+	// system events are missed or not supported
+	rescanInterval = 30 * time.Second
+	// accountID is the account ID of the ARNs of the standalone tasks
+	accountID      = "000000000000"
+	defaultCluster = "default"
+	taskHashLength = 12
+)
+
+var invalidTaskIDChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// TaskFileWatcher watches a directory of task-definition files, and runs a task for each of
+// them. A task is stopped when its file is deleted, and replaced when its file changes.
+//
+// The files contain a task in the JSON format of the tasks of ACS payloads, e.g.
+// {"family": "app", "containers": [{"name": "app", "image": "app:1", "memory": 256}]}.
+// The ARN of the task is generated from the name and the content of the file.
+type TaskFileWatcher struct {
+	dir        string
+	cluster    string
+	region     string
+	taskEngine engine.TaskEngine
+	dataClient data.Client
+	// taskARNs are the ARNs of the tasks started from the files, by file name
+	taskARNs map[string]string
+	// rejectedTaskARNs are the ARNs of the tasks the engine rejected, by file name. A file
+	// is only started again once it changes.
+	rejectedTaskARNs map[string]string
+	// reconciled is set once the tasks of the engine are reconciled with the files
+	reconciled bool
+}
+
+// NewTaskFileWatcher returns a watcher running the tasks of the files in dir
+func NewTaskFileWatcher(dir, cluster, region string, taskEngine engine.TaskEngine,
+	dataClient data.Client) *TaskFileWatcher {
+	if cluster == "" {
+		cluster = defaultCluster
+	}
+	return &TaskFileWatcher{
+		dir:        dir,
+		cluster:    cluster,
+		region:     region,
+		taskEngine: taskEngine,
+		dataClient: dataClient,
+		taskARNs:   make(map[string]string),
+
+		rejectedTaskARNs: make(map[string]string),
+	}
+}
+
+// Run scans the directory for changes until the context is canceled
+func (w *TaskFileWatcher) Run(ctx context.Context) {
+	logger.Info("Running tasks from local task-definition files", logger.Fields{
+		"dir": w.dir,
+	})
+	w.scan()
+
+	var events chan fsnotify.Event
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		defer watcher.Close()
+		err = watcher.Add(w.dir)
+		events = watcher.Events
+	}
+	if err != nil {
+		logger.Warn("Unable to watch the task-definition files, they will be scanned periodically", logger.Fields{
+			"dir":       w.dir,
+			field.Error: err,
+		})
+	}
+
+	ticker := time.NewTicker(rescanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			w.scan()
+		case <-ticker.C:
+			w.scan()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scan starts the tasks of the new and changed files, and stops the tasks of the deleted
+// and changed files
+func (w *TaskFileWatcher) scan() {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		logger.Error("Unable to read the task-definition files", logger.Fields{
+			"dir":       w.dir,
+			field.Error: err,
+		})
+		return
+	}
+
+	found := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != taskFileExtension || strings.HasPrefix(name, ".") {
+			continue
+		}
+		found[name] = true
+		content, err := os.ReadFile(filepath.Join(w.dir, name))
+		if err != nil {
+			logger.Error("Unable to read task-definition file", logger.Fields{
+				"file":      name,
+				field.Error: err,
+			})
+			continue
+		}
+		taskARN := w.taskARN(name, content)
+		previousTaskARN, ok := w.taskARNs[name]
+		if (ok && previousTaskARN == taskARN) || w.rejectedTaskARNs[name] == taskARN {
+			continue
+		}
+		acsTask, err := w.parseTaskFile(name, taskARN, content)
+		if err != nil {
+			// The file may be being written, it's parsed again on the next change. The task
+			// of its previous content, if any, keeps running meanwhile.
+			logger.Error("Unable to parse task-definition file", logger.Fields{
+				"file":      name,
+				field.Error: err,
+			})
+			continue
+		}
+		if ok {
+			logger.Info("Task-definition file changed, replacing its task", logger.Fields{
+				"file":        name,
+				field.TaskARN: previousTaskARN,
+			})
+			w.stopTask(previousTaskARN)
+		}
+		if err := w.startTask(acsTask); err != nil {
+			logger.Error("Unable to start task of task-definition file", logger.Fields{
+				"file":      name,
+				field.Error: err,
+			})
+			delete(w.taskARNs, name)
+			w.rejectedTaskARNs[name] = taskARN
+			continue
+		}
+		w.taskARNs[name] = taskARN
+		delete(w.rejectedTaskARNs, name)
+	}
+
+	for name := range w.rejectedTaskARNs {
+		if !found[name] {
+			delete(w.rejectedTaskARNs, name)
+		}
+	}
+
+	for name, taskARN := range w.taskARNs {
+		if found[name] {
+			continue
+		}
+		logger.Info("Task-definition file deleted, stopping its task", logger.Fields{
+			"file":        name,
+			field.TaskARN: taskARN,
+		})
+		w.stopTask(taskARN)
+		delete(w.taskARNs, name)
+	}
+
+	if !w.reconciled {
+		w.reconcile()
+		w.reconciled = true
+	}
+}
+
+// reconcile stops the standalone tasks restored from the state of a previous run of the
+// agent whose files were deleted while the agent wasn't running
+func (w *TaskFileWatcher) reconcile() {
+	tasks, err := w.taskEngine.ListTasks()
+	if err != nil {
+		logger.Error("Unable to list the tasks of the engine", logger.Fields{field.Error: err})
+		return
+	}
+	current := make(map[string]bool)
+	for _, taskARN := range w.taskARNs {
+		current[taskARN] = true
+	}
+	for _, task := range tasks {
+		if current[task.Arn] || !strings.HasPrefix(task.Arn, w.taskARNPrefix()) ||
+			task.GetDesiredStatus().Terminal() {
+			continue
+		}
+		logger.Info("Stopping task whose task-definition file no longer exists", logger.Fields{
+			field.TaskARN: task.Arn,
+		})
+		w.stopTask(task.Arn)
+	}
+}
+
+func (w *TaskFileWatcher) taskARNPrefix() string {
+	return fmt.Sprintf("arn:aws:ecs:%s:%s:task/%s/", w.region, accountID, w.cluster)
+}
+
+// taskARN returns the ARN of the task of a file, which changes with the content of the file
+func (w *TaskFileWatcher) taskARN(name string, content []byte) string {
+	hash := sha256.Sum256(content)
+	id := invalidTaskIDChars.ReplaceAllString(strings.TrimSuffix(name, taskFileExtension), "-")
+	return w.taskARNPrefix() + id + "-" + hex.EncodeToString(hash[:])[:taskHashLength]
+}
+
+// parseTaskFile parses the ACS task of a task-definition file, filling in the fields ACS
+// always sets
+func (w *TaskFileWatcher) parseTaskFile(name, taskARN string, content []byte) (*ecsacs.Task, error) {
+	acsTask := &ecsacs.Task{}
+	if err := jsonutil.UnmarshalJSON(acsTask, bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+	if len(acsTask.Containers) == 0 {
+		return nil, fmt.Errorf("task has no containers")
+	}
+	acsTask.Arn = aws.String(taskARN)
+	if aws.StringValue(acsTask.Family) == "" {
+		acsTask.Family = aws.String(strings.TrimSuffix(name, taskFileExtension))
+	}
+	if aws.StringValue(acsTask.Version) == "" {
+		acsTask.Version = aws.String("1")
+	}
+	acsTask.DesiredStatus = aws.String(apitaskstatus.TaskRunning.String())
+	return acsTask, nil
+}
+
+// startTask converts the ACS task with the same transformation as the tasks of ACS
+// payloads, and adds it to the engine
+func (w *TaskFileWatcher) startTask(acsTask *ecsacs.Task) error {
+	task, err := apitask.TaskFromACS(acsTask, &ecsacs.PayloadMessage{
+		ClusterArn: aws.String(w.cluster),
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info("Starting task from task-definition file", logger.Fields{
+		field.TaskARN:     task.Arn,
+		field.TaskVersion: task.Version,
+	})
+	w.taskEngine.AddTask(task)
+	// The engine stops the tasks it can't initialize instead of adding them
+	if _, ok := w.taskEngine.GetTaskByArn(task.Arn); !ok {
+		return fmt.Errorf("task %s was rejected by the engine", task.Arn)
+	}
+	if err := w.dataClient.SaveTask(task); err != nil {
+		logger.Error("Failed to save data for task", logger.Fields{
+			field.TaskARN: task.Arn,
+			field.Error:   err,
+		})
+	}
+	return nil
+}
+
+// stopTask sets the desired status of a task to STOPPED. Only the ARN and the desired status
+// of the task are needed to stop it, but the task must be known to the engine, since
+// upserting an unknown task would add it.
+func (w *TaskFileWatcher) stopTask(taskARN string) {
+	if _, ok := w.taskEngine.GetTaskByArn(taskARN); !ok {
+		logger.Warn("Not stopping task unknown to the engine", logger.Fields{
+			field.TaskARN: taskARN,
+		})
+		return
+	}
+	task := &apitask.Task{Arn: taskARN}
+	task.SetDesiredStatus(apitaskstatus.TaskStopped)
+	w.taskEngine.UpsertTask(task)
+}
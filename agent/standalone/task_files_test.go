@@ -0,0 +1,191 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCluster  = "edge"
+	testRegion   = "us-west-2"
+	testTaskFile = `{"containers": [{"name": "app", "image": "app:1", "memory": 256, "essential": true}]}`
+)
+
+func writeTaskFile(t *testing.T, dir, name, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func newTestWatcher(t *testing.T) (*TaskFileWatcher, *mock_engine.MockTaskEngine, string) {
+	ctrl := gomock.NewController(t)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	dir := t.TempDir()
+	return NewTaskFileWatcher(dir, testCluster, testRegion, taskEngine, data.NewNoopClient()), taskEngine, dir
+}
+
+// expectStart expects a task to be added to the engine, which accepts it
+func expectStart(taskEngine *mock_engine.MockTaskEngine, started **apitask.Task) *gomock.Call {
+	return taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		if started != nil {
+			*started = task
+		}
+		taskEngine.EXPECT().GetTaskByArn(task.Arn).Return(task, true)
+	})
+}
+
+func expectStop(t *testing.T, taskEngine *mock_engine.MockTaskEngine, taskARN string) *gomock.Call {
+	return taskEngine.EXPECT().UpsertTask(gomock.Any()).Do(func(task *apitask.Task) {
+		assert.Equal(t, taskARN, task.Arn)
+		assert.Equal(t, apitaskstatus.TaskStopped, task.GetDesiredStatus())
+	})
+}
+
+// expectKnownStop expects a task known to the engine to be stopped
+func expectKnownStop(t *testing.T, taskEngine *mock_engine.MockTaskEngine, taskARN string) *gomock.Call {
+	taskEngine.EXPECT().GetTaskByArn(taskARN).Return(&apitask.Task{Arn: taskARN}, true)
+	return expectStop(t, taskEngine, taskARN)
+}
+
+func TestTaskFileWatcherLifecycle(t *testing.T) {
+	watcher, taskEngine, dir := newTestWatcher(t)
+	writeTaskFile(t, dir, "app.json", testTaskFile)
+	writeTaskFile(t, dir, "README.md", "not a task")
+
+	var started *apitask.Task
+	expectStart(taskEngine, &started)
+	taskEngine.EXPECT().ListTasks().Return(nil, nil)
+	watcher.scan()
+	require.NotNil(t, started)
+	assert.True(t, strings.HasPrefix(started.Arn, "arn:aws:ecs:us-west-2:000000000000:task/edge/app-"))
+	assert.Equal(t, "app", started.Family)
+	assert.Equal(t, "1", started.Version)
+	assert.Equal(t, apitaskstatus.TaskRunning, started.GetDesiredStatus())
+	require.Len(t, started.Containers, 1)
+	assert.Equal(t, "app:1", started.Containers[0].Image)
+
+	// Nothing changed
+	watcher.scan()
+
+	// The task is replaced when the file changes
+	var replacement *apitask.Task
+	writeTaskFile(t, dir, "app.json", strings.Replace(testTaskFile, "app:1", "app:2", 1))
+	gomock.InOrder(
+		expectKnownStop(t, taskEngine, started.Arn),
+		expectStart(taskEngine, &replacement),
+	)
+	watcher.scan()
+	require.NotNil(t, replacement)
+	assert.NotEqual(t, started.Arn, replacement.Arn)
+	assert.Equal(t, "app:2", replacement.Containers[0].Image)
+
+	// The task is stopped when the file is deleted
+	require.NoError(t, os.Remove(filepath.Join(dir, "app.json")))
+	expectKnownStop(t, taskEngine, replacement.Arn)
+	watcher.scan()
+	assert.Empty(t, watcher.taskARNs)
+}
+
+func TestTaskFileWatcherInvalidFile(t *testing.T) {
+	watcher, taskEngine, dir := newTestWatcher(t)
+	writeTaskFile(t, dir, "broken.json", `{"containers": [`)
+	writeTaskFile(t, dir, "empty.json", `{"family": "empty"}`)
+
+	taskEngine.EXPECT().ListTasks().Return(nil, nil)
+	watcher.scan()
+	assert.Empty(t, watcher.taskARNs)
+}
+
+func TestTaskFileWatcherKeepsTaskOnInvalidChange(t *testing.T) {
+	watcher, taskEngine, dir := newTestWatcher(t)
+	writeTaskFile(t, dir, "app.json", testTaskFile)
+	expectStart(taskEngine, nil)
+	taskEngine.EXPECT().ListTasks().Return(nil, nil)
+	watcher.scan()
+	taskARN := watcher.taskARNs["app.json"]
+
+	writeTaskFile(t, dir, "app.json", `{"containers": [`)
+	watcher.scan()
+	assert.Equal(t, taskARN, watcher.taskARNs["app.json"])
+}
+
+func TestTaskFileWatcherReconcile(t *testing.T) {
+	watcher, taskEngine, dir := newTestWatcher(t)
+	writeTaskFile(t, dir, "app.json", testTaskFile)
+	currentARN := watcher.taskARN("app.json", []byte(testTaskFile))
+
+	current := &apitask.Task{Arn: currentARN}
+	current.SetDesiredStatus(apitaskstatus.TaskRunning)
+	deleted := &apitask.Task{Arn: watcher.taskARNPrefix() + "deleted-0123456789ab"}
+	deleted.SetDesiredStatus(apitaskstatus.TaskRunning)
+	alreadyStopped := &apitask.Task{Arn: watcher.taskARNPrefix() + "stopped-0123456789ab"}
+	alreadyStopped.SetDesiredStatus(apitaskstatus.TaskStopped)
+	other := &apitask.Task{Arn: "arn:aws:ecs:us-west-2:123456789012:task/relay"}
+	other.SetDesiredStatus(apitaskstatus.TaskRunning)
+
+	// The task of a file restored from the state is added again, which only updates its
+	// desired status
+	expectStart(taskEngine, nil)
+	taskEngine.EXPECT().ListTasks().Return([]*apitask.Task{current, deleted, alreadyStopped, other}, nil)
+	expectKnownStop(t, taskEngine, deleted.Arn)
+	watcher.scan()
+
+	// Reconciliation only happens on the first scan
+	watcher.scan()
+}
+
+func TestTaskFileWatcherRejectedTask(t *testing.T) {
+	watcher, taskEngine, dir := newTestWatcher(t)
+	writeTaskFile(t, dir, "app.json", testTaskFile)
+	taskARN := watcher.taskARN("app.json", []byte(testTaskFile))
+
+	// The engine doesn't add a task it can't initialize
+	taskEngine.EXPECT().AddTask(gomock.Any())
+	taskEngine.EXPECT().GetTaskByArn(taskARN).Return(nil, false)
+	taskEngine.EXPECT().ListTasks().Return(nil, nil)
+	watcher.scan()
+	assert.Empty(t, watcher.taskARNs)
+	assert.Equal(t, taskARN, watcher.rejectedTaskARNs["app.json"])
+
+	// The file isn't started again until it changes
+	watcher.scan()
+	writeTaskFile(t, dir, "app.json", strings.Replace(testTaskFile, "app:1", "app:2", 1))
+	expectStart(taskEngine, nil)
+	watcher.scan()
+	assert.NotEmpty(t, watcher.taskARNs["app.json"])
+	assert.Empty(t, watcher.rejectedTaskARNs)
+}
+
+func TestTaskFileWatcherStopUnknownTask(t *testing.T) {
+	watcher, taskEngine, _ := newTestWatcher(t)
+	taskARN := watcher.taskARNPrefix() + "gone-0123456789ab"
+
+	// A task unknown to the engine isn't upserted, which would add it
+	taskEngine.EXPECT().GetTaskByArn(taskARN).Return(nil, false)
+	watcher.stopTask(taskARN)
+}
@@ -79,6 +79,7 @@ type DockerContainerMetadataResolver struct {
 type Engine interface {
 	GetInstanceMetrics(includeServiceConnectStats bool) (*ecstcs.MetricsMetadata, []*ecstcs.TaskMetric, error)
 	ContainerDockerStats(taskARN string, containerID string) (*types.StatsJSON, *stats.NetworkStatsPerSec, error)
+	ContainerStatsHistory(taskARN string, containerID string) ([]UsageStats, error)
 	GetTaskHealthMetrics() (*ecstcs.HealthMetadata, []*ecstcs.TaskHealth, error)
 	GetPublishServiceConnectTickerInterval() int32
 	SetPublishServiceConnectTickerInterval(int32)
@@ -1029,6 +1030,24 @@ func (engine *DockerStatsEngine) ContainerDockerStats(taskARN string, containerI
 	return containerStats, containerNetworkRateStats, nil
 }
 
+// ContainerStatsHistory returns the stats buffered for a container, ordered from the oldest
+func (engine *DockerStatsEngine) ContainerStatsHistory(taskARN string, containerID string) ([]UsageStats, error) {
+	engine.lock.RLock()
+	defer engine.lock.RUnlock()
+
+	containerIDToStatsContainer, ok := engine.tasksToContainers[taskARN]
+	if !ok {
+		return nil, errors.Errorf("stats engine: task '%s' for container '%s' not found",
+			taskARN, containerID)
+	}
+
+	container, ok := containerIDToStatsContainer[containerID]
+	if !ok {
+		return nil, errors.Errorf("stats engine: container not found: %s", containerID)
+	}
+	return container.statsQueue.GetHistory(), nil
+}
+
 // getTaskStatsToCollect returns a map of taskArns for which task metrics needs to collected
 func (engine *DockerStatsEngine) getTaskStatsToCollect() map[string]bool {
 	taskStatsToCollect := make(map[string]bool)
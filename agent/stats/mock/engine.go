@@ -22,7 +22,8 @@ import (
 	reflect "reflect"
 	time "time"
 
-	stats "github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+	stats "github.com/aws/amazon-ecs-agent/agent/stats"
+	stats0 "github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	ecstcs "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
 	types "github.com/docker/docker/api/types"
 	gomock "github.com/golang/mock/gomock"
@@ -52,11 +53,11 @@ func (m *MockEngine) EXPECT() *MockEngineMockRecorder {
 }
 
 // ContainerDockerStats mocks base method.
-func (m *MockEngine) ContainerDockerStats(arg0, arg1 string) (*types.StatsJSON, *stats.NetworkStatsPerSec, error) {
+func (m *MockEngine) ContainerDockerStats(arg0, arg1 string) (*types.StatsJSON, *stats0.NetworkStatsPerSec, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ContainerDockerStats", arg0, arg1)
 	ret0, _ := ret[0].(*types.StatsJSON)
-	ret1, _ := ret[1].(*stats.NetworkStatsPerSec)
+	ret1, _ := ret[1].(*stats0.NetworkStatsPerSec)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -67,6 +68,21 @@ func (mr *MockEngineMockRecorder) ContainerDockerStats(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerDockerStats", reflect.TypeOf((*MockEngine)(nil).ContainerDockerStats), arg0, arg1)
 }
 
+// ContainerStatsHistory mocks base method.
+func (m *MockEngine) ContainerStatsHistory(arg0, arg1 string) ([]stats.UsageStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerStatsHistory", arg0, arg1)
+	ret0, _ := ret[0].([]stats.UsageStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContainerStatsHistory indicates an expected call of ContainerStatsHistory.
+func (mr *MockEngineMockRecorder) ContainerStatsHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerStatsHistory", reflect.TypeOf((*MockEngine)(nil).ContainerStatsHistory), arg0, arg1)
+}
+
 // GetInstanceMetrics mocks base method.
 func (m *MockEngine) GetInstanceMetrics(arg0 bool) (*ecstcs.MetricsMetadata, []*ecstcs.TaskMetric, error) {
 	m.ctrl.T.Helper()
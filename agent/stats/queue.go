@@ -174,6 +174,16 @@ func (queue *Queue) GetLastNetworkStatPerSec() *stats.NetworkStatsPerSec {
 	return queue.lastNetworkStatPerSec
 }
 
+// GetHistory returns a copy of the stats buffered in the queue, ordered from the oldest.
+func (queue *Queue) GetHistory() []UsageStats {
+	queue.lock.RLock()
+	defer queue.lock.RUnlock()
+
+	history := make([]UsageStats, len(queue.buffer))
+	copy(history, queue.buffer)
+	return history
+}
+
 // GetCPUStatsSet gets the stats set for CPU utilization.
 func (queue *Queue) GetCPUStatsSet() (*ecstcs.CWStatsSet, error) {
 	return queue.getCWStatsSet(getCPUUsagePerc)
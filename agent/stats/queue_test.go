@@ -399,6 +399,22 @@ func validateNetStatsSet(t *testing.T, netStats *ecstcs.NetworkStatsSet, queueLe
 	assert.Equal(t, float64(5.540383824e+09), *netStats.TxBytesPerSecond.Sum, "incorrect TxBytesPerSecond sum")
 }
 
+func TestQueueGetHistory(t *testing.T) {
+	timestamps := getTimestamps()
+	queueLength := 5
+	queue := createQueue(queueLength, false)
+
+	history := queue.GetHistory()
+	require.Len(t, history, queueLength)
+	for i, stat := range history {
+		assert.Equal(t, timestamps[len(timestamps)-queueLength+i], stat.Timestamp)
+	}
+
+	// The history is a copy of the buffer.
+	history[0].CPUUsagePerc = -1
+	assert.NotEqual(t, float32(-1), queue.buffer[0].CPUUsagePerc)
+}
+
 func TestQueueUintStats(t *testing.T) {
 	queueLength := 3
 	queue := createQueue(queueLength, true)
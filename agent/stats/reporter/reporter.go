@@ -54,7 +54,8 @@ func NewDockerTelemetrySession(
 	taskEngine engine.TaskEngine,
 	metricsChannel <-chan ecstcs.TelemetryMessage,
 	healthChannel <-chan ecstcs.HealthMessage,
-	doctor *doctor.Doctor) (*DockerTelemetrySession, error) {
+	doctor *doctor.Doctor,
+	metricsFactory metrics.EntryFactory) (*DockerTelemetrySession, error) {
 	ok, cfgParseErr := isContainerHealthMetricsDisabled(cfg)
 	if cfgParseErr != nil {
 		logger.Warn("Error starting metrics session", logger.Fields{
@@ -87,7 +88,7 @@ func NewDockerTelemetrySession(
 		defaultHeartbeatJitter,
 		wsclient.DisconnectTimeout,
 		wsclient.DisconnectJitterMax,
-		metrics.NewNopEntryFactory(),
+		metricsFactory,
 		metricsChannel,
 		healthChannel,
 		doctor,
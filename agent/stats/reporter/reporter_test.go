@@ -26,6 +26,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/version"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -102,6 +103,7 @@ func TestNewDockerTelemetrySession(t *testing.T) {
 				nil,
 				nil,
 				emptyDoctor,
+				metrics.NewNopEntryFactory(),
 			)
 			if tc.expectedSession {
 				assert.NotNil(t, dockerTelemetrySession)
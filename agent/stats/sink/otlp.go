@@ -0,0 +1,202 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/version"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	otlpRequestTimeout = 5 * time.Second
+	otlpScopeName      = "github.com/aws/amazon-ecs-agent"
+	otlpServiceName    = "amazon-ecs-agent"
+
+	// otlpCumulativeTemporality is AGGREGATION_TEMPORALITY_CUMULATIVE in the OTLP metrics data model.
+	otlpCumulativeTemporality = 2
+)
+
+// The types below are the subset of the JSON encoding of the OTLP ExportMetricsServiceRequest
+// used by the agent.
+type otlpExportRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpMetric struct {
+	Name  string     `json:"name"`
+	Unit  string     `json:"unit"`
+	Gauge *otlpGauge `json:"gauge,omitempty"`
+	Sum   *otlpSum   `json:"sum,omitempty"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+}
+
+type otlpDataPoint struct {
+	Attributes   []otlpKeyValue `json:"attributes"`
+	TimeUnixNano string         `json:"timeUnixNano"`
+	AsDouble     float64        `json:"asDouble"`
+}
+
+type otlpKeyValue struct {
+	Key   string          `json:"key"`
+	Value otlpStringValue `json:"value"`
+}
+
+type otlpStringValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// otlpSink publishes metrics to an OTLP/HTTP endpoint with the JSON encoding.
+type otlpSink struct {
+	endpoint   string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+func newOTLPSink(endpoint string) *otlpSink {
+	return &otlpSink{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: otlpRequestTimeout},
+		now:        time.Now,
+	}
+}
+
+func (s *otlpSink) Publish(ctx context.Context, message ecstcs.TelemetryMessage) error {
+	metrics := containerMetrics(message)
+	if len(metrics) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(s.exportRequest(message, metrics))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d from OTLP endpoint %s", resp.StatusCode, s.endpoint)
+	}
+	return nil
+}
+
+// exportRequest groups the data points by metric, in the order in which the metrics were first seen.
+func (s *otlpSink) exportRequest(message ecstcs.TelemetryMessage, metrics []metric) otlpExportRequest {
+	timeUnixNano := strconv.FormatInt(s.now().UnixNano(), 10)
+	var otlpMetrics []otlpMetric
+	index := make(map[string]int)
+	for _, m := range metrics {
+		i, ok := index[m.name]
+		if !ok {
+			i = len(otlpMetrics)
+			index[m.name] = i
+			otlpMetric := otlpMetric{Name: m.name, Unit: m.unit}
+			if m.kind == counter {
+				otlpMetric.Sum = &otlpSum{
+					AggregationTemporality: otlpCumulativeTemporality,
+					IsMonotonic:            true,
+				}
+			} else {
+				otlpMetric.Gauge = &otlpGauge{}
+			}
+			otlpMetrics = append(otlpMetrics, otlpMetric)
+		}
+		dataPoint := otlpDataPoint{
+			Attributes:   otlpAttributes(m.attributes),
+			TimeUnixNano: timeUnixNano,
+			AsDouble:     m.value,
+		}
+		if otlpMetrics[i].Sum != nil {
+			otlpMetrics[i].Sum.DataPoints = append(otlpMetrics[i].Sum.DataPoints, dataPoint)
+		} else {
+			otlpMetrics[i].Gauge.DataPoints = append(otlpMetrics[i].Gauge.DataPoints, dataPoint)
+		}
+	}
+
+	resourceAttributes := map[string]string{"service.name": otlpServiceName}
+	if message.Metadata != nil {
+		resourceAttributes["aws.ecs.container_instance"] = aws.StringValue(message.Metadata.ContainerInstance)
+	}
+	return otlpExportRequest{
+		ResourceMetrics: []otlpResourceMetrics{
+			{
+				Resource: otlpResource{Attributes: otlpAttributes(resourceAttributes)},
+				ScopeMetrics: []otlpScopeMetrics{
+					{
+						Scope:   otlpScope{Name: otlpScopeName, Version: version.Version},
+						Metrics: otlpMetrics,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpAttributes(attributes map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	keyValues := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		keyValues = append(keyValues, otlpKeyValue{Key: key, Value: otlpStringValue{StringValue: attributes[key]}})
+	}
+	return keyValues
+}
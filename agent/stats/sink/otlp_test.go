@@ -0,0 +1,76 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPSinkPublish(t *testing.T) {
+	var received otlpExportRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	s := newOTLPSink(server.URL)
+	s.now = func() time.Time { return time.Unix(0, 1234) }
+	require.NoError(t, s.Publish(context.Background(), testTelemetryMessage()))
+
+	require.Len(t, received.ResourceMetrics, 1)
+	assert.Contains(t, received.ResourceMetrics[0].Resource.Attributes,
+		otlpKeyValue{Key: "service.name", Value: otlpStringValue{StringValue: otlpServiceName}})
+	require.Len(t, received.ResourceMetrics[0].ScopeMetrics, 1)
+	metrics := received.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Len(t, metrics, 4)
+
+	cpu := metrics[0]
+	assert.Equal(t, "ecs.container.cpu.utilization", cpu.Name)
+	require.NotNil(t, cpu.Gauge)
+	require.Len(t, cpu.Gauge.DataPoints, 1)
+	assert.Equal(t, float64(50), cpu.Gauge.DataPoints[0].AsDouble)
+	assert.Equal(t, "1234", cpu.Gauge.DataPoints[0].TimeUnixNano)
+	assert.Contains(t, cpu.Gauge.DataPoints[0].Attributes,
+		otlpKeyValue{Key: attributeTaskARN, Value: otlpStringValue{StringValue: testTaskARN}})
+
+	restarts := metrics[3]
+	assert.Equal(t, "ecs.container.restart_count", restarts.Name)
+	require.NotNil(t, restarts.Sum)
+	assert.True(t, restarts.Sum.IsMonotonic)
+	assert.Equal(t, otlpCumulativeTemporality, restarts.Sum.AggregationTemporality)
+	require.Len(t, restarts.Sum.DataPoints, 1)
+	assert.Equal(t, float64(2), restarts.Sum.DataPoints[0].AsDouble)
+}
+
+func TestOTLPSinkPublishErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	assert.Error(t, newOTLPSink(server.URL).Publish(context.Background(), testTelemetryMessage()))
+}
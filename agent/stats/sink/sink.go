@@ -0,0 +1,205 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package sink publishes the task metrics collected by the stats engine to a local
+// metrics collector, for instances that cannot, or should not only, report them to TCS.
+package sink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	// TypeOTLP publishes the metrics to an OpenTelemetry collector with OTLP over HTTP.
+	TypeOTLP = "otlp"
+	// TypeStatsD publishes the metrics to a StatsD server over UDP.
+	TypeStatsD = "statsd"
+
+	// DefaultOTLPEndpoint is the default OTLP/HTTP metrics endpoint of a local OpenTelemetry collector.
+	DefaultOTLPEndpoint = "http://localhost:4318/v1/metrics"
+	// DefaultStatsDEndpoint is the default address of a local StatsD server.
+	DefaultStatsDEndpoint = "localhost:8125"
+
+	// forwardTimeout is how long a message waits to be forwarded to the TCS session before being discarded.
+	forwardTimeout = 5 * time.Second
+)
+
+// Attributes set on every container metric.
+const (
+	attributeCluster      = "aws.ecs.cluster"
+	attributeTaskARN      = "aws.ecs.task.arn"
+	attributeTaskFamily   = "aws.ecs.task.family"
+	attributeTaskRevision = "aws.ecs.task.revision"
+	attributeContainer    = "container.name"
+)
+
+// Sink publishes the metrics of a telemetry message.
+type Sink interface {
+	Publish(ctx context.Context, message ecstcs.TelemetryMessage) error
+}
+
+// New returns the sink of the given type publishing to endpoint. The default endpoint
+// of the sink type is used if endpoint is empty.
+func New(sinkType, endpoint string) (Sink, error) {
+	switch sinkType {
+	case TypeOTLP:
+		if endpoint == "" {
+			endpoint = DefaultOTLPEndpoint
+		}
+		return newOTLPSink(endpoint), nil
+	case TypeStatsD:
+		if endpoint == "" {
+			endpoint = DefaultStatsDEndpoint
+		}
+		return newStatsDSink(endpoint)
+	default:
+		return nil, fmt.Errorf("unknown metrics sink type %q", sinkType)
+	}
+}
+
+// Run publishes the telemetry messages received on in to the sink until ctx is done. If out
+// is not nil, every message is forwarded to it first, so that the sink runs alongside the TCS
+// session reading from out.
+func Run(ctx context.Context, s Sink, in <-chan ecstcs.TelemetryMessage, out chan<- ecstcs.TelemetryMessage) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-in:
+			if out != nil {
+				forward(ctx, message, out)
+			}
+			if err := s.Publish(ctx, message); err != nil {
+				logger.Warn("Error publishing metrics to the local metrics sink", logger.Fields{
+					field.Error: err,
+				})
+			}
+		}
+	}
+}
+
+func forward(ctx context.Context, message ecstcs.TelemetryMessage, out chan<- ecstcs.TelemetryMessage) {
+	timer := time.NewTimer(forwardTimeout)
+	defer timer.Stop()
+	select {
+	case out <- message:
+	case <-ctx.Done():
+	case <-timer.C:
+		logger.Warn("Timed out forwarding telemetry message to the telemetry session, discarding metrics")
+	}
+}
+
+// metricKind is how a metric value evolves over time.
+type metricKind int
+
+const (
+	// gauge metrics are sampled values, e.g. the memory usage.
+	gauge metricKind = iota
+	// counter metrics are monotonic totals, e.g. the number of bytes received since the container started.
+	counter
+)
+
+// metric is a single data point of a container metric.
+type metric struct {
+	name       string
+	unit       string
+	kind       metricKind
+	value      float64
+	attributes map[string]string
+}
+
+// containerMetrics flattens the telemetry message into one data point per container and metric.
+func containerMetrics(message ecstcs.TelemetryMessage) []metric {
+	var metrics []metric
+	for _, task := range message.TaskMetrics {
+		for _, container := range task.ContainerMetrics {
+			attributes := map[string]string{
+				attributeTaskARN:      aws.StringValue(task.TaskArn),
+				attributeTaskFamily:   aws.StringValue(task.TaskDefinitionFamily),
+				attributeTaskRevision: aws.StringValue(task.TaskDefinitionVersion),
+				attributeContainer:    aws.StringValue(container.ContainerName),
+			}
+			if message.Metadata != nil {
+				attributes[attributeCluster] = aws.StringValue(message.Metadata.Cluster)
+			}
+			add := func(name, unit string, kind metricKind, value float64, ok bool) {
+				if ok {
+					metrics = append(metrics, metric{
+						name:       name,
+						unit:       unit,
+						kind:       kind,
+						value:      value,
+						attributes: attributes,
+					})
+				}
+			}
+
+			value, ok := cwStatsSetAverage(container.CpuStatsSet)
+			add("ecs.container.cpu.utilization", "%", gauge, value, ok)
+			value, ok = cwStatsSetAverage(container.MemoryStatsSet)
+			add("ecs.container.memory.usage", "MiBy", gauge, value, ok)
+			if network := container.NetworkStatsSet; network != nil {
+				value, ok = ulongStatsSetMax(network.RxBytes)
+				add("ecs.container.network.rx_bytes", "By", counter, value, ok)
+				value, ok = ulongStatsSetMax(network.TxBytes)
+				add("ecs.container.network.tx_bytes", "By", counter, value, ok)
+				value, ok = ulongStatsSetMax(network.RxPackets)
+				add("ecs.container.network.rx_packets", "{packet}", counter, value, ok)
+				value, ok = ulongStatsSetMax(network.TxPackets)
+				add("ecs.container.network.tx_packets", "{packet}", counter, value, ok)
+				value, ok = ulongStatsSetMax(network.RxErrors)
+				add("ecs.container.network.rx_errors", "{error}", counter, value, ok)
+				value, ok = ulongStatsSetMax(network.TxErrors)
+				add("ecs.container.network.tx_errors", "{error}", counter, value, ok)
+				value, ok = ulongStatsSetMax(network.RxDropped)
+				add("ecs.container.network.rx_dropped", "{packet}", counter, value, ok)
+				value, ok = ulongStatsSetMax(network.TxDropped)
+				add("ecs.container.network.tx_dropped", "{packet}", counter, value, ok)
+			}
+			if storage := container.StorageStatsSet; storage != nil {
+				value, ok = ulongStatsSetMax(storage.ReadSizeBytes)
+				add("ecs.container.storage.read_bytes", "By", counter, value, ok)
+				value, ok = ulongStatsSetMax(storage.WriteSizeBytes)
+				add("ecs.container.storage.write_bytes", "By", counter, value, ok)
+			}
+			if restart := container.RestartStatsSet; restart != nil && restart.RestartCount != nil {
+				add("ecs.container.restart_count", "{restart}", counter, float64(*restart.RestartCount), true)
+			}
+		}
+	}
+	return metrics
+}
+
+// cwStatsSetAverage returns the average of the samples of the stats set.
+func cwStatsSetAverage(set *ecstcs.CWStatsSet) (float64, bool) {
+	if set == nil || aws.Int64Value(set.SampleCount) == 0 {
+		return 0, false
+	}
+	return aws.Float64Value(set.Sum) / float64(*set.SampleCount), true
+}
+
+// ulongStatsSetMax returns the maximum of the samples of the stats set. For the cumulative
+// counters reported by the stats engine, this is the latest total.
+func ulongStatsSetMax(set *ecstcs.ULongStatsSet) (float64, bool) {
+	if set == nil || set.Max == nil {
+		return 0, false
+	}
+	return float64(*set.Max) + float64(aws.Int64Value(set.OverflowMax)), true
+}
@@ -0,0 +1,140 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCluster = "test-cluster"
+	testTaskARN = "arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc"
+)
+
+func testTelemetryMessage() ecstcs.TelemetryMessage {
+	return ecstcs.TelemetryMessage{
+		Metadata: &ecstcs.MetricsMetadata{
+			Cluster:           aws.String(testCluster),
+			ContainerInstance: aws.String("container-instance"),
+		},
+		TaskMetrics: []*ecstcs.TaskMetric{
+			{
+				TaskArn:               aws.String(testTaskARN),
+				TaskDefinitionFamily:  aws.String("family"),
+				TaskDefinitionVersion: aws.String("3"),
+				ContainerMetrics: []*ecstcs.ContainerMetric{
+					{
+						ContainerName: aws.String("web"),
+						CpuStatsSet: &ecstcs.CWStatsSet{
+							Sum:         aws.Float64(150),
+							SampleCount: aws.Int64(3),
+						},
+						MemoryStatsSet: &ecstcs.CWStatsSet{
+							Sum:         aws.Float64(300),
+							SampleCount: aws.Int64(3),
+						},
+						NetworkStatsSet: &ecstcs.NetworkStatsSet{
+							RxBytes: &ecstcs.ULongStatsSet{
+								Max:         aws.Int64(math.MaxInt64),
+								OverflowMax: aws.Int64(10),
+							},
+						},
+						RestartStatsSet: &ecstcs.RestartStatsSet{
+							RestartCount: aws.Int64(2),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestContainerMetrics(t *testing.T) {
+	metrics := containerMetrics(testTelemetryMessage())
+	require.Len(t, metrics, 4)
+
+	expectedAttributes := map[string]string{
+		attributeCluster:      testCluster,
+		attributeTaskARN:      testTaskARN,
+		attributeTaskFamily:   "family",
+		attributeTaskRevision: "3",
+		attributeContainer:    "web",
+	}
+	for _, m := range metrics {
+		assert.Equal(t, expectedAttributes, m.attributes)
+	}
+	assert.Equal(t, "ecs.container.cpu.utilization", metrics[0].name)
+	assert.Equal(t, gauge, metrics[0].kind)
+	assert.Equal(t, float64(50), metrics[0].value)
+	assert.Equal(t, "ecs.container.memory.usage", metrics[1].name)
+	assert.Equal(t, float64(100), metrics[1].value)
+	assert.Equal(t, "ecs.container.network.rx_bytes", metrics[2].name)
+	assert.Equal(t, counter, metrics[2].kind)
+	assert.Equal(t, float64(math.MaxInt64)+10, metrics[2].value)
+	assert.Equal(t, "ecs.container.restart_count", metrics[3].name)
+	assert.Equal(t, float64(2), metrics[3].value)
+}
+
+func TestContainerMetricsIdleInstance(t *testing.T) {
+	assert.Empty(t, containerMetrics(ecstcs.TelemetryMessage{
+		Metadata: &ecstcs.MetricsMetadata{Idle: aws.Bool(true), Fin: aws.Bool(true)},
+	}))
+}
+
+func TestNewUnknownSink(t *testing.T) {
+	_, err := New("graphite", "")
+	assert.Error(t, err)
+}
+
+type fakeSink struct {
+	published chan ecstcs.TelemetryMessage
+}
+
+func (s *fakeSink) Publish(ctx context.Context, message ecstcs.TelemetryMessage) error {
+	s.published <- message
+	return errors.New("publish error")
+}
+
+func TestRunForwardsMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan ecstcs.TelemetryMessage)
+	out := make(chan ecstcs.TelemetryMessage, 1)
+	s := &fakeSink{published: make(chan ecstcs.TelemetryMessage, 1)}
+	go Run(ctx, s, in, out)
+
+	message := testTelemetryMessage()
+	in <- message
+	select {
+	case published := <-s.published:
+		assert.Equal(t, message, published)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not published to the sink")
+	}
+	// Errors publishing to the sink don't prevent the message from being forwarded.
+	assert.Equal(t, message, <-out)
+}
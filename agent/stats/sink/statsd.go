@@ -0,0 +1,85 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
+)
+
+// statsDMaxPacketSize keeps the packets below the usual MTU of 1500 bytes, once IP and UDP headers are added.
+const statsDMaxPacketSize = 1432
+
+// statsDSink publishes metrics to a StatsD server. Every metric is sent as a gauge, cumulative
+// counters included, with its attributes as DogStatsD tags, which are understood by the
+// CloudWatch agent, the OpenTelemetry collector and Telegraf among others.
+type statsDSink struct {
+	conn net.Conn
+}
+
+func newStatsDSink(address string) (*statsDSink, error) {
+	// Dialing UDP doesn't send anything, it only resolves the address.
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &statsDSink{conn: conn}, nil
+}
+
+func (s *statsDSink) Publish(ctx context.Context, message ecstcs.TelemetryMessage) error {
+	var packet bytes.Buffer
+	for _, m := range containerMetrics(message) {
+		line := statsDLine(m)
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsDMaxPacketSize {
+			if _, err := s.conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() == 0 {
+		return nil
+	}
+	_, err := s.conn.Write(packet.Bytes())
+	return err
+}
+
+// statsDLine formats the metric as "<name>:<value>|g|#<key>:<value>,...".
+func statsDLine(m metric) string {
+	keys := make([]string, 0, len(m.attributes))
+	for key := range m.attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tags := make([]string, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, key+":"+statsDTagValue(m.attributes[key]))
+	}
+	return m.name + ":" + strconv.FormatFloat(m.value, 'f', -1, 64) + "|g|#" + strings.Join(tags, ",")
+}
+
+// statsDTagValue replaces the characters that delimit tags and lines in the StatsD format.
+func statsDTagValue(value string) string {
+	return strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_").Replace(value)
+}
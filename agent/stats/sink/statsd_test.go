@@ -0,0 +1,59 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsDLine(t *testing.T) {
+	line := statsDLine(metric{
+		name:  "ecs.container.memory.usage",
+		value: 12.5,
+		attributes: map[string]string{
+			attributeContainer: "web,app",
+			attributeTaskARN:   testTaskARN,
+		},
+	})
+	assert.Equal(t, "ecs.container.memory.usage:12.5|g|#aws.ecs.task.arn:"+testTaskARN+",container.name:web_app", line)
+}
+
+func TestStatsDSinkPublish(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := newStatsDSink(conn.LocalAddr().String())
+	require.NoError(t, err)
+	require.NoError(t, s.Publish(context.Background(), testTelemetryMessage()))
+
+	buf := make([]byte, statsDMaxPacketSize)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	lines := strings.Split(string(buf[:n]), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "ecs.container.cpu.utilization:50|g|#"))
+	assert.True(t, strings.HasPrefix(lines[3], "ecs.container.restart_count:2|g|#"))
+}
@@ -266,18 +266,51 @@ func (secret *ASMSecretResource) Create() error {
 	}
 	iamCredentials := executionCredentials.GetIAMRoleCredentials()
 
+	seelog.Debugf("ASM secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
+	secret.secretData = make(map[string]string)
+
+	if err := secret.retrieveASMSecretValues(iamCredentials, secret.secretData); err != nil {
+		secret.setTerminalReason(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Refresh retrieves the values of the secrets again with the execution role credentials of
+// the task. The cached values are only replaced once all the secrets were retrieved, and a
+// failure doesn't set the terminal reason of the resource.
+func (secret *ASMSecretResource) Refresh() error {
+	executionCredentials, ok := secret.credentialsManager.GetTaskCredentials(secret.getExecutionCredentialsID())
+	if !ok {
+		return errors.New("ASM secret resource: unable to find execution role credentials")
+	}
+
+	seelog.Debugf("ASM secret resource: refreshing secrets for containers in task: [%s]", secret.taskARN)
+	secretData := make(map[string]string)
+	if err := secret.retrieveASMSecretValues(executionCredentials.GetIAMRoleCredentials(), secretData); err != nil {
+		return err
+	}
+
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	secret.secretData = secretData
+	return nil
+}
+
+// retrieveASMSecretValues retrieves the values of all the secrets into secretData. It spins up
+// one goroutine per secret, and returns the errors of all the secrets that couldn't be retrieved.
+func (secret *ASMSecretResource) retrieveASMSecretValues(iamCredentials credentials.IAMRoleCredentials, secretData map[string]string) error {
 	var wg sync.WaitGroup
 
+	requiredSecrets := secret.getRequiredSecrets()
 	// Get the maximum number of errors to be returned, which will be one error per goroutine
-	errorEvents := make(chan error, len(secret.requiredSecrets))
-
-	seelog.Debugf("ASM secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
-	secret.secretData = make(map[string]string)
+	errorEvents := make(chan error, len(requiredSecrets))
 
-	for _, asmsecret := range secret.getRequiredSecrets() {
+	for _, asmsecret := range requiredSecrets {
 		wg.Add(1)
 		// Spin up goroutine per secret to speed up processing time
-		go secret.retrieveASMSecretValue(asmsecret, iamCredentials, &wg, errorEvents)
+		go secret.retrieveASMSecretValue(asmsecret, iamCredentials, secretData, &wg, errorEvents)
 	}
 
 	wg.Wait()
@@ -289,16 +322,15 @@ func (secret *ASMSecretResource) Create() error {
 			terminalReasons = append(terminalReasons, err.Error())
 		}
 
-		errorString := strings.Join(terminalReasons, ";")
-		secret.setTerminalReason(errorString)
-		return errors.New(errorString)
+		return errors.New(strings.Join(terminalReasons, ";"))
 	}
 	return nil
 }
 
 // retrieveASMSecretValue reads secret value from cache first, if not exists, call GetSecretFromASM to retrieve value
 // AWS secrets Manager
-func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials,
+	secretData map[string]string, wg *sync.WaitGroup, errorEvents chan error) {
 	defer wg.Done()
 
 	asmClient, err := secret.asmClientCreator.NewASMClient(apiSecret.Region, iamCredentials)
@@ -330,7 +362,7 @@ func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.S
 
 	// put secret value in secretData
 	secretKey := apiSecret.GetSecretResourceCacheKey()
-	secret.secretData[secretKey] = secretValue
+	secretData[secretKey] = secretValue
 }
 
 func pointerOrNil(in string) *string {
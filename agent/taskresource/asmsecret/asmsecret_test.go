@@ -158,6 +158,56 @@ func TestCreateReturnMultipleErrors(t *testing.T) {
 	assert.Contains(t, asmRes.GetTerminalReason(), expectedError)
 }
 
+func TestRefresh(t *testing.T) {
+	requiredSecretData := map[string]apicontainer.Secret{
+		secretKeyWest1: sampleSecret(secretName1, valueFrom1, region1),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	asmClientCreator := mock_factory.NewMockClientCreator(ctrl)
+	mockASMClient := mock_secretsmanageriface.NewMockSecretsManagerAPI(ctrl)
+
+	iamRoleCreds := credentials.IAMRoleCredentials{}
+	creds := credentials.TaskIAMRoleCredentials{
+		IAMRoleCredentials: iamRoleCreds,
+	}
+
+	asmSecretValue := &secretsmanager.GetSecretValueOutput{
+		SecretString: aws.String("rotated-value"),
+	}
+
+	credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(creds, true).Times(2)
+	asmClientCreator.EXPECT().NewASMClient(region1, iamRoleCreds).Return(mockASMClient, nil).Times(2)
+	gomock.InOrder(
+		mockASMClient.EXPECT().GetSecretValue(gomock.Any(), gomock.Any(), gomock.Any()).Return(asmSecretValue, nil),
+		mockASMClient.EXPECT().GetSecretValue(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error response")),
+	)
+
+	asmRes := &ASMSecretResource{
+		executionCredentialsID: executionCredentialsID,
+		requiredSecrets:        requiredSecretData,
+		credentialsManager:     credentialsManager,
+		asmClientCreator:       asmClientCreator,
+	}
+	asmRes.SetCachedSecretValue(secretKeyWest1, secretValue)
+
+	// The cached value is retrieved again
+	require.NoError(t, asmRes.Refresh())
+	value, ok := asmRes.GetCachedSecretValue(secretKeyWest1)
+	require.True(t, ok)
+	assert.Equal(t, "rotated-value", value)
+
+	// The cached value is kept when it can't be retrieved, without failing the resource
+	assert.Error(t, asmRes.Refresh())
+	value, ok = asmRes.GetCachedSecretValue(secretKeyWest1)
+	require.True(t, ok)
+	assert.Equal(t, "rotated-value", value)
+	assert.Empty(t, asmRes.GetTerminalReason())
+}
+
 func TestCreateReturnError(t *testing.T) {
 	requiredSecretData := map[string]apicontainer.Secret{
 		secretKeyWest1: sampleSecret(secretName1, valueFrom1, region1),
@@ -28,6 +28,11 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	minBlkioWeight = 10
+	maxBlkioWeight = 1000
+)
+
 // control is used to implement the cgroup Control interface
 type control struct {
 	factory.CgroupFactory
@@ -122,5 +127,13 @@ func validateCgroupSpec(cgroupSpec *Spec) error {
 	if cgroupSpec.Specs == nil {
 		return errors.New("cgroup spec validator: empty linux resource spec")
 	}
+
+	// Validate the block IO weight, which is expressed in the cgroup v1 range and converted
+	// to the cgroup v2 range when needed
+	if blockIO := cgroupSpec.Specs.BlockIO; blockIO != nil && blockIO.Weight != nil {
+		if *blockIO.Weight < minBlkioWeight || *blockIO.Weight > maxBlkioWeight {
+			return errors.Errorf("cgroup spec validator: invalid block IO weight %d", *blockIO.Weight)
+		}
+	}
 	return nil
 }
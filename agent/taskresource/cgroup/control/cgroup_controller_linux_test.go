@@ -49,6 +49,29 @@ func TestCreateHappyCase(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCreateWithBlockIOAndPids(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCgroup := mock_cgroups.NewMockCgroup(ctrl)
+	mockCgroupFactory := mock_factory.NewMockCgroupFactory(ctrl)
+
+	weight := uint16(500)
+	testSpecs := &specs.LinuxResources{
+		BlockIO: &specs.LinuxBlockIO{
+			Weight:                &weight,
+			ThrottleReadBpsDevice: []specs.LinuxThrottleDevice{{Rate: 10485760}},
+		},
+		Pids: &specs.LinuxPids{Limit: 100},
+	}
+	mockCgroupFactory.EXPECT().New(gomock.Any(), gomock.Any(), testSpecs).Return(mockCgroup, nil)
+
+	control := newControl(mockCgroupFactory)
+
+	err := control.Create(&Spec{testCgroupRoot, testSpecs})
+	assert.NoError(t, err)
+}
+
 func TestCreateErrorCase(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -72,6 +95,7 @@ func TestCreateWithBadSpecs(t *testing.T) {
 	mockCgroupFactory := mock_factory.NewMockCgroupFactory(ctrl)
 
 	cg := newControl(mockCgroupFactory)
+	invalidBlkioWeight := uint16(5)
 
 	testCases := []struct {
 		spec *Spec
@@ -82,6 +106,8 @@ func TestCreateWithBadSpecs(t *testing.T) {
 		{&Spec{"", &specs.LinuxResources{}}, "empty root with spec"},
 		{&Spec{}, "empty spec"},
 		{nil, "nil spec"},
+		{&Spec{"/ecs/foo", &specs.LinuxResources{BlockIO: &specs.LinuxBlockIO{Weight: &invalidBlkioWeight}}},
+			"invalid block IO weight"},
 	}
 
 	for _, tc := range testCases {
@@ -23,6 +23,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/cihub/seelog"
 	cgroupsv2 "github.com/containerd/cgroups/v3/cgroup2"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
 )
 
 const (
@@ -33,6 +34,12 @@ const (
 	// containers, then we use a dummy PID of -1.
 	// see https://github.com/containerd/cgroups/blob/1df78138f1e1e6ee593db155c6b369466f577651/v2/manager.go#L732-L735
 	generalSlicePID int = -1
+	// ioWeightFile is the cgroup file of the proportional io weight. The io.bfq.weight file
+	// written by the cgroups library only exists with the BFQ io scheduler.
+	ioWeightFile = "io.weight"
+	// minIOWeight and maxIOWeight bound the cgroup v2 io weights
+	minIOWeight = 1
+	maxIOWeight = 10000
 )
 
 // controlv2 is used to implement the cgroup Control interface
@@ -58,6 +65,14 @@ func (c *controlv2) Create(cgroupSpec *Spec) error {
 		return fmt.Errorf("cgroupv2 create: unable initialize cgroup controllers: %w", err)
 	}
 
+	// The systemd slice is only created with the cpu, memory and pids limits of the spec, so
+	// the io limits are written to the cgroup files of the slice.
+	if cgroupSpec.Specs.BlockIO != nil {
+		if err := setIOLimits(m, cgroupPath, cgroupSpec.Specs.BlockIO); err != nil {
+			return fmt.Errorf("cgroupv2 create: unable to set io limits: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -129,6 +144,40 @@ func initializeControllers(manager *cgroupsv2.Manager) error {
 	return nil
 }
 
+func setIOLimits(m *cgroupsv2.Manager, cgroupPath string, blockIO *specs.LinuxBlockIO) error {
+	if err := m.ToggleControllers([]string{"io"}, cgroupsv2.Enable); err != nil {
+		return fmt.Errorf("error enabling io controller: %w", err)
+	}
+	io := cgroupsv2.ToResources(&specs.LinuxResources{BlockIO: blockIO}).IO
+	if len(io.Max) > 0 {
+		if err := m.Update(&cgroupsv2.Resources{IO: &cgroupsv2.IO{Max: io.Max}}); err != nil {
+			return fmt.Errorf("error setting io throttles: %w", err)
+		}
+	}
+	if blockIO.Weight != nil && *blockIO.Weight != 0 {
+		weightPath := filepath.Join(fullCgroupPath(cgroupPath), ioWeightFile)
+		weight := blkioWeightToIOWeight(*blockIO.Weight)
+		if err := os.WriteFile(weightPath, []byte(fmt.Sprintf("default %d", weight)), 0); err != nil {
+			return fmt.Errorf("error setting io weight: %w", err)
+		}
+	}
+	return nil
+}
+
+// blkioWeightToIOWeight converts a cgroup v1 blkio weight, between 10 and 1000, to the
+// cgroup v2 io weight range of 1 to 10000. The conversion is done in uint64, as the one of
+// the cgroups library overflows uint16.
+func blkioWeightToIOWeight(blkioWeight uint16) uint64 {
+	if blkioWeight <= minBlkioWeight {
+		return minIOWeight
+	}
+	weight := minIOWeight + (uint64(blkioWeight)-minBlkioWeight)*(maxIOWeight-minIOWeight)/(maxBlkioWeight-minBlkioWeight)
+	if weight > maxIOWeight {
+		return maxIOWeight
+	}
+	return weight
+}
+
 func validateController(controller string, controllers []string) error {
 	for _, v := range controllers {
 		if controller == v {
@@ -0,0 +1,49 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package control
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlkioWeightToIOWeight(t *testing.T) {
+	testCases := []struct {
+		blkioWeight uint16
+		ioWeight    uint64
+	}{
+		{5, 1},
+		{10, 1},
+		{100, 910},
+		{500, 4950},
+		{1000, 10000},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.ioWeight, blkioWeightToIOWeight(tc.blkioWeight), "blkio weight %d", tc.blkioWeight)
+	}
+
+	// A higher blkio weight never results in a lower io weight
+	previous := blkioWeightToIOWeight(minBlkioWeight)
+	for blkioWeight := uint16(minBlkioWeight + 1); blkioWeight <= maxBlkioWeight; blkioWeight++ {
+		ioWeight := blkioWeightToIOWeight(blkioWeight)
+		assert.GreaterOrEqual(t, ioWeight, previous, "blkio weight %d", blkioWeight)
+		assert.GreaterOrEqual(t, ioWeight, uint64(minIOWeight), "blkio weight %d", blkioWeight)
+		assert.LessOrEqual(t, ioWeight, uint64(maxIOWeight), "blkio weight %d", blkioWeight)
+		previous = ioWeight
+	}
+}
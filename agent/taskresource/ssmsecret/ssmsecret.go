@@ -262,27 +262,58 @@ func (secret *SSMSecretResource) Create() error {
 	}
 	iamCredentials := executionCredentials.GetIAMRoleCredentials()
 
+	seelog.Infof("ssm secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
+	secret.secretData = make(map[string]string)
+
+	if err := secret.retrieveSSMSecretValuesInRegions(iamCredentials, secret.secretData); err != nil {
+		secret.setTerminalReason(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Refresh retrieves the values of the secrets again with the execution role credentials of
+// the task. The cached values are only replaced once all the secrets were retrieved, and a
+// failure doesn't set the terminal reason of the resource.
+func (secret *SSMSecretResource) Refresh() error {
+	executionCredentials, ok := secret.credentialsManager.GetTaskCredentials(secret.getExecutionCredentialsID())
+	if !ok {
+		return errors.New("ssm secret resource: unable to find execution role credentials")
+	}
+
+	seelog.Debugf("ssm secret resource: refreshing secrets for containers in task: [%s]", secret.taskARN)
+	secretData := make(map[string]string)
+	if err := secret.retrieveSSMSecretValuesInRegions(executionCredentials.GetIAMRoleCredentials(), secretData); err != nil {
+		return err
+	}
+
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	secret.secretData = secretData
+	return nil
+}
+
+// retrieveSSMSecretValuesInRegions retrieves the values of all the secrets into secretData. It
+// spins up one goroutine per region, and returns the first error returned by any of them.
+func (secret *SSMSecretResource) retrieveSSMSecretValuesInRegions(iamCredentials credentials.IAMRoleCredentials, secretData map[string]string) error {
 	var wg sync.WaitGroup
 
 	// Get the maximum number of errors can be returned, which will be one error per goroutine
 	chanLen := secret.getGoRoutineMaxNum()
 	errorEvents := make(chan error, chanLen)
 
-	seelog.Infof("ssm secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
-	secret.secretData = make(map[string]string)
-
 	for region, secrets := range secret.getRequiredSecrets() {
 		wg.Add(1)
 		// Spin up goroutine each region to speed up processing time
-		go secret.retrieveSSMSecretValuesByRegion(region, secrets, iamCredentials, &wg, errorEvents)
+		go secret.retrieveSSMSecretValuesByRegion(region, secrets, iamCredentials, secretData, &wg, errorEvents)
 	}
 
 	wg.Wait()
 
-	// Get the first error returned and set as terminal reason
+	// Get the first error returned
 	select {
 	case err := <-errorEvents:
-		secret.setTerminalReason(err.Error())
 		return err
 	default:
 		return nil
@@ -302,7 +333,8 @@ func (secret *SSMSecretResource) getGoRoutineMaxNum() int {
 
 // retrieveSSMSecretValuesByRegion reads secret values from cache first, if not exists, batches secrets based on field
 // valueFrom and call retrieveSSMSecretValues to retrieve values from SSM
-func (secret *SSMSecretResource) retrieveSSMSecretValuesByRegion(region string, secrets []apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+func (secret *SSMSecretResource) retrieveSSMSecretValuesByRegion(region string, secrets []apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials,
+	secretData map[string]string, wg *sync.WaitGroup, errorEvents chan error) {
 	seelog.Infof("ssm secret resource: retrieving secrets for region %s in task: [%s]", region, secret.taskARN)
 	defer wg.Done()
 
@@ -311,7 +343,10 @@ func (secret *SSMSecretResource) retrieveSSMSecretValuesByRegion(region string,
 
 	for _, s := range secrets {
 		secretKey := s.GetSecretResourceCacheKey()
-		if _, ok := secret.GetCachedSecretValue(secretKey); ok {
+		secret.lock.RLock()
+		_, ok := secretData[secretKey]
+		secret.lock.RUnlock()
+		if ok {
 			continue
 		}
 		secretNames = append(secretNames, s.ValueFrom)
@@ -319,20 +354,21 @@ func (secret *SSMSecretResource) retrieveSSMSecretValuesByRegion(region string,
 			secretNamesTmp := make([]string, MaxBatchNum)
 			copy(secretNamesTmp, secretNames)
 			wgPerRegion.Add(1)
-			go secret.retrieveSSMSecretValues(region, secretNamesTmp, iamCredentials, &wgPerRegion, errorEvents)
+			go secret.retrieveSSMSecretValues(region, secretNamesTmp, iamCredentials, secretData, &wgPerRegion, errorEvents)
 			secretNames = []string{}
 		}
 	}
 
 	if len(secretNames) > 0 {
 		wgPerRegion.Add(1)
-		go secret.retrieveSSMSecretValues(region, secretNames, iamCredentials, &wgPerRegion, errorEvents)
+		go secret.retrieveSSMSecretValues(region, secretNames, iamCredentials, secretData, &wgPerRegion, errorEvents)
 	}
 	wgPerRegion.Wait()
 }
 
 // retrieveSSMSecretValues retrieves secret values from SSM parameter store and caches them into memory
-func (secret *SSMSecretResource) retrieveSSMSecretValues(region string, names []string, iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+func (secret *SSMSecretResource) retrieveSSMSecretValues(region string, names []string, iamCredentials credentials.IAMRoleCredentials,
+	secretData map[string]string, wg *sync.WaitGroup, errorEvents chan error) {
 	defer wg.Done()
 
 	ssmClient := secret.ssmClientCreator.NewSSMClient(region, iamCredentials)
@@ -349,7 +385,7 @@ func (secret *SSMSecretResource) retrieveSSMSecretValues(region string, names []
 	// put secret value in secretData
 	for secretName, secretValue := range secValueMap {
 		secretKey := secretName + "_" + region
-		secret.secretData[secretKey] = secretValue
+		secretData[secretKey] = secretValue
 	}
 }
 
@@ -18,6 +18,7 @@ package ssmsecret
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
 	"testing"
 	"time"
@@ -395,6 +396,69 @@ func TestCreateReturnError(t *testing.T) {
 	assert.Equal(t, expectedError, ssmRes.GetTerminalReason())
 }
 
+func TestRefresh(t *testing.T) {
+	requiredSecretData := map[string][]apicontainer.Secret{
+		region1: {
+			{
+				Name:      secretName1,
+				ValueFrom: valueFrom1,
+				Region:    region1,
+				Provider:  "ssm",
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	ssmClientCreator := mock_factory.NewMockSSMClientCreator(ctrl)
+	mockSSMClient := mock_ssm.NewMockSSMClient(ctrl)
+
+	iamRoleCreds := credentials.IAMRoleCredentials{}
+	creds := credentials.TaskIAMRoleCredentials{
+		IAMRoleCredentials: iamRoleCreds,
+	}
+
+	ssmOutput := &ssm.GetParametersOutput{
+		InvalidParameters: []*string{},
+		Parameters: []*ssm.Parameter{
+			&ssm.Parameter{
+				Name:  aws.String(valueFrom1),
+				Value: aws.String("rotated-value"),
+			},
+		},
+	}
+
+	credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(creds, true).Times(2)
+	ssmClientCreator.EXPECT().NewSSMClient(region1, iamRoleCreds).Return(mockSSMClient).Times(2)
+	gomock.InOrder(
+		mockSSMClient.EXPECT().GetParameters(gomock.Any()).Return(ssmOutput, nil),
+		mockSSMClient.EXPECT().GetParameters(gomock.Any()).Return(nil, errors.New("error response")),
+	)
+
+	ssmRes := &SSMSecretResource{
+		executionCredentialsID: executionCredentialsID,
+		requiredSecrets:        requiredSecretData,
+		credentialsManager:     credentialsManager,
+		ssmClientCreator:       ssmClientCreator,
+	}
+	ssmRes.SetCachedSecretValue(secretKeyWest1, secretValue)
+
+	// The cached value is retrieved again
+	require.NoError(t, ssmRes.Refresh())
+	value, ok := ssmRes.GetCachedSecretValue(secretKeyWest1)
+	require.True(t, ok)
+	assert.Equal(t, "rotated-value", value)
+
+	// The cached value is kept when it can't be retrieved, without failing the resource
+	assert.Error(t, ssmRes.Refresh())
+	value, ok = ssmRes.GetCachedSecretValue(secretKeyWest1)
+	require.True(t, ok)
+	assert.Equal(t, "rotated-value", value)
+	assert.Empty(t, ssmRes.GetTerminalReason())
+}
+
 func TestGetGoRoutineMaxNumTwoRegions(t *testing.T) {
 	requiredSecretData := make(map[string][]apicontainer.Secret)
 	secretsInRegion1 := []apicontainer.Secret{
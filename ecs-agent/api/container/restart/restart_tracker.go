@@ -21,10 +21,21 @@ import (
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 )
 
+const (
+	// backoffMultiple is the factor by which the restart backoff grows after each restart.
+	backoffMultiple = 2
+	// defaultBackoffMaxDelay is the ceiling of the restart backoff when the restart
+	// policy does not specify one.
+	defaultBackoffMaxDelay = 5 * time.Minute
+)
+
 type RestartTracker struct {
 	RestartCount  int           `json:"restartCount,omitempty"`
 	LastRestartAt time.Time     `json:"lastRestartAt,omitempty"`
 	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+	// NextRestartAt is the time at which a restart that is being delayed by the
+	// restart backoff is due. It is zero when no restart is pending.
+	NextRestartAt time.Time `json:"nextRestartAt,omitempty"`
 	lock          sync.RWMutex
 }
 
@@ -34,6 +45,20 @@ type RestartPolicy struct {
 	Enabled              bool  `json:"enabled"`
 	IgnoredExitCodes     []int `json:"ignoredExitCodes"`
 	RestartAttemptPeriod int   `json:"restartAttemptPeriod"`
+	// MaxRestartAttempts is the maximum number of times the container is restarted.
+	// A value of 0 means the container is restarted without limit.
+	MaxRestartAttempts int `json:"maxRestartAttempts,omitempty"`
+	// BackoffInitialDelay is the delay, in seconds, before the first restart of the
+	// container. The delay doubles after each restart, up to BackoffMaxDelay. A value
+	// of 0 means the container is restarted as soon as it exits.
+	BackoffInitialDelay int `json:"backoffInitialDelay,omitempty"`
+	// BackoffMaxDelay is the ceiling, in seconds, of the delay before a restart.
+	// A value of 0 means the delay is capped at 5 minutes.
+	BackoffMaxDelay int `json:"backoffMaxDelay,omitempty"`
+	// StopTaskOnMaxRestartAttempts specifies whether the task should be stopped once
+	// the container exits after MaxRestartAttempts restarts have been made. When it is
+	// not set, the default of the agent config applies.
+	StopTaskOnMaxRestartAttempts *bool `json:"stopTaskOnMaxRestartAttempts,omitempty"`
 }
 
 func NewRestartTracker(restartPolicy RestartPolicy) *RestartTracker {
@@ -54,6 +79,79 @@ func (rt *RestartTracker) GetRestartCount() int {
 	return rt.RestartCount
 }
 
+// GetRestartBackoff returns the delay to apply before the next restart of the container.
+func (rt *RestartTracker) GetRestartBackoff() time.Duration {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	return rt.restartBackoffUnsafe()
+}
+
+func (rt *RestartTracker) restartBackoffUnsafe() time.Duration {
+	initialDelay := time.Duration(rt.RestartPolicy.BackoffInitialDelay) * time.Second
+	if initialDelay <= 0 {
+		return 0
+	}
+	maxDelay := time.Duration(rt.RestartPolicy.BackoffMaxDelay) * time.Second
+	if maxDelay <= 0 {
+		maxDelay = defaultBackoffMaxDelay
+	}
+	backoff := initialDelay
+	for i := 0; i < rt.RestartCount && backoff < maxDelay; i++ {
+		backoff *= backoffMultiple
+	}
+	if backoff > maxDelay {
+		return maxDelay
+	}
+	return backoff
+}
+
+// RestartDelay returns how long to wait before restarting the container. The first
+// call after the container has exited starts the backoff period, subsequent calls
+// return the time remaining until the end of that period. A value that is zero or
+// negative means the container can be restarted right away.
+func (rt *RestartTracker) RestartDelay() time.Duration {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	if rt.NextRestartAt.IsZero() {
+		backoff := rt.restartBackoffUnsafe()
+		if backoff <= 0 {
+			return 0
+		}
+		rt.NextRestartAt = time.Now().Add(backoff)
+		return backoff
+	}
+	return time.Until(rt.NextRestartAt)
+}
+
+// GetNextRestartAt returns the time at which a pending restart is due, or the zero
+// time if no restart is pending.
+func (rt *RestartTracker) GetNextRestartAt() time.Time {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	return rt.NextRestartAt
+}
+
+// MaxRestartAttemptsReached returns whether the container has been restarted the
+// maximum number of times allowed by the restart policy.
+func (rt *RestartTracker) MaxRestartAttemptsReached() bool {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	return rt.maxRestartAttemptsReachedUnsafe()
+}
+
+func (rt *RestartTracker) maxRestartAttemptsReachedUnsafe() bool {
+	return rt.RestartPolicy.MaxRestartAttempts > 0 && rt.RestartCount >= rt.RestartPolicy.MaxRestartAttempts
+}
+
+// ShouldStopTask returns whether the task should be stopped, as the container
+// has exhausted its restart attempts and the restart policy says to give up.
+func (rt *RestartTracker) ShouldStopTask() bool {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	stopTask := rt.RestartPolicy.StopTaskOnMaxRestartAttempts
+	return stopTask != nil && *stopTask && rt.maxRestartAttemptsReachedUnsafe()
+}
+
 // RecordRestart updates the restart tracker's metadata after a restart has occurred.
 // This metadata is used to calculate when restarts should occur and track how many
 // have occurred. It is not the job of this method to determine if a restart should
@@ -63,6 +161,7 @@ func (rt *RestartTracker) RecordRestart() {
 	defer rt.lock.Unlock()
 	rt.RestartCount++
 	rt.LastRestartAt = time.Now()
+	rt.NextRestartAt = time.Time{}
 }
 
 // ShouldRestart returns whether the container should restart and a reason string
@@ -87,6 +186,9 @@ func (rt *RestartTracker) ShouldRestart(exitCode *int, startedAt time.Time,
 			return false, fmt.Sprintf("exit code %d should be ignored", *exitCode)
 		}
 	}
+	if rt.maxRestartAttemptsReachedUnsafe() {
+		return false, fmt.Sprintf("maximum restart attempts (%d) reached", rt.RestartPolicy.MaxRestartAttempts)
+	}
 
 	startTime := startedAt
 	if !rt.LastRestartAt.IsZero() {
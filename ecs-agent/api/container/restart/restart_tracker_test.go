@@ -184,3 +184,126 @@ func TestRecordRestartPolicy(t *testing.T) {
 	assert.Equal(t, 0, len(rt.RestartPolicy.IgnoredExitCodes))
 	assert.NotNil(t, rt.RestartPolicy)
 }
+
+func TestShouldRestartMaxRestartAttempts(t *testing.T) {
+	rt := NewRestartTracker(RestartPolicy{
+		Enabled:            true,
+		IgnoredExitCodes:   []int{0},
+		MaxRestartAttempts: 2,
+	})
+	exitCode := 1
+	startedAt := time.Now().Add(-time.Minute)
+
+	for i := 0; i < 2; i++ {
+		shouldRestart, _ := rt.ShouldRestart(&exitCode, startedAt, apicontainerstatus.ContainerRunning)
+		assert.True(t, shouldRestart)
+		assert.False(t, rt.MaxRestartAttemptsReached())
+		rt.RecordRestart()
+	}
+
+	shouldRestart, reason := rt.ShouldRestart(&exitCode, startedAt, apicontainerstatus.ContainerRunning)
+	assert.False(t, shouldRestart)
+	assert.Equal(t, "maximum restart attempts (2) reached", reason)
+	assert.True(t, rt.MaxRestartAttemptsReached())
+	assert.False(t, rt.ShouldStopTask())
+
+	stopTask := false
+	rt.RestartPolicy.StopTaskOnMaxRestartAttempts = &stopTask
+	assert.False(t, rt.ShouldStopTask())
+	stopTask = true
+	assert.True(t, rt.ShouldStopTask())
+}
+
+func TestGetRestartBackoff(t *testing.T) {
+	testCases := []struct {
+		name         string
+		rp           RestartPolicy
+		restartCount int
+		expected     time.Duration
+	}{
+		{
+			name:     "backoff disabled",
+			rp:       RestartPolicy{Enabled: true},
+			expected: 0,
+		},
+		{
+			name:     "first restart",
+			rp:       RestartPolicy{Enabled: true, BackoffInitialDelay: 10, BackoffMaxDelay: 60},
+			expected: 10 * time.Second,
+		},
+		{
+			name:         "exponential growth",
+			rp:           RestartPolicy{Enabled: true, BackoffInitialDelay: 10, BackoffMaxDelay: 60},
+			restartCount: 2,
+			expected:     40 * time.Second,
+		},
+		{
+			name:         "capped at max delay",
+			rp:           RestartPolicy{Enabled: true, BackoffInitialDelay: 10, BackoffMaxDelay: 60},
+			restartCount: 3,
+			expected:     60 * time.Second,
+		},
+		{
+			name:         "capped at default max delay",
+			rp:           RestartPolicy{Enabled: true, BackoffInitialDelay: 10},
+			restartCount: 1000,
+			expected:     defaultBackoffMaxDelay,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := NewRestartTracker(tc.rp)
+			rt.RestartCount = tc.restartCount
+			assert.Equal(t, tc.expected, rt.GetRestartBackoff())
+		})
+	}
+}
+
+func TestRestartDelay(t *testing.T) {
+	rt := NewRestartTracker(RestartPolicy{
+		Enabled:             true,
+		BackoffInitialDelay: 10,
+		BackoffMaxDelay:     60,
+	})
+
+	// The first call starts the backoff period.
+	assert.Equal(t, 10*time.Second, rt.RestartDelay())
+	nextRestartAt := rt.GetNextRestartAt()
+	assert.False(t, nextRestartAt.IsZero())
+
+	// Subsequent calls return the remaining time of the same backoff period.
+	remaining := rt.RestartDelay()
+	assert.True(t, remaining > 0 && remaining <= 10*time.Second)
+	assert.Equal(t, nextRestartAt, rt.GetNextRestartAt())
+
+	// Restarting ends the backoff period and doubles the next backoff.
+	rt.RecordRestart()
+	assert.True(t, rt.GetNextRestartAt().IsZero())
+	assert.Equal(t, 20*time.Second, rt.RestartDelay())
+
+	// A backoff period that has elapsed allows an immediate restart.
+	rt.NextRestartAt = time.Now().Add(-time.Second)
+	assert.True(t, rt.RestartDelay() <= 0)
+}
+
+func TestRestartTrackerJSON(t *testing.T) {
+	stopTask := true
+	rt := NewRestartTracker(RestartPolicy{
+		Enabled:                      true,
+		MaxRestartAttempts:           3,
+		BackoffInitialDelay:          10,
+		BackoffMaxDelay:              60,
+		StopTaskOnMaxRestartAttempts: &stopTask,
+	})
+	rt.RecordRestart()
+	rt.RestartDelay()
+
+	data, err := json.Marshal(rt)
+	require.NoError(t, err)
+	restored := &RestartTracker{}
+	require.NoError(t, json.Unmarshal(data, restored))
+	assert.Equal(t, rt.RestartPolicy, restored.RestartPolicy)
+	assert.Equal(t, 1, restored.GetRestartCount())
+	assert.True(t, rt.GetNextRestartAt().Equal(restored.GetNextRestartAt()))
+}
@@ -0,0 +1,301 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ecsTargetPrefix    = "AmazonEC2ContainerServiceV20141113."
+	ecsContentType     = "application/x-amz-json-1.1"
+	maxRequestBodySize = 10 << 20
+
+	// OperationCreateCluster is the ECS API operation creating a cluster
+	OperationCreateCluster = "CreateCluster"
+	// OperationDiscoverPollEndpoint is the ECS API operation returning the ACS and TCS
+	// endpoints
+	OperationDiscoverPollEndpoint = "DiscoverPollEndpoint"
+	// OperationListTagsForResource is the ECS API operation returning the tags of a resource
+	OperationListTagsForResource = "ListTagsForResource"
+	// OperationRegisterContainerInstance is the ECS API operation registering a container
+	// instance
+	OperationRegisterContainerInstance = "RegisterContainerInstance"
+	// OperationSubmitAttachmentStateChanges is the ECS API operation submitting the state
+	// changes of attachments
+	OperationSubmitAttachmentStateChanges = "SubmitAttachmentStateChanges"
+	// OperationSubmitContainerStateChange is the ECS API operation submitting the state
+	// change of a container
+	OperationSubmitContainerStateChange = "SubmitContainerStateChange"
+	// OperationSubmitTaskStateChange is the ECS API operation submitting the state change
+	// of a task
+	OperationSubmitTaskStateChange = "SubmitTaskStateChange"
+	// OperationUpdateContainerInstancesState is the ECS API operation updating the status
+	// of container instances
+	OperationUpdateContainerInstancesState = "UpdateContainerInstancesState"
+)
+
+// TaskStateChange is a task state change submitted by the agent
+type TaskStateChange struct {
+	Cluster     string                   `json:"cluster"`
+	Task        string                   `json:"task"`
+	Status      string                   `json:"status"`
+	Reason      string                   `json:"reason,omitempty"`
+	Containers  []*ContainerStateChange  `json:"containers,omitempty"`
+	Attachments []*AttachmentStateChange `json:"attachments,omitempty"`
+}
+
+// ContainerStateChange is a container state change submitted by the agent, on its own or
+// along with a task state change
+type ContainerStateChange struct {
+	Cluster         string           `json:"cluster,omitempty"`
+	Task            string           `json:"task,omitempty"`
+	ContainerName   string           `json:"containerName"`
+	RuntimeID       string           `json:"runtimeId,omitempty"`
+	ImageDigest     string           `json:"imageDigest,omitempty"`
+	Status          string           `json:"status"`
+	Reason          string           `json:"reason,omitempty"`
+	ExitCode        *int             `json:"exitCode,omitempty"`
+	NetworkBindings []NetworkBinding `json:"networkBindings,omitempty"`
+}
+
+// NetworkBinding is a port binding of a container
+type NetworkBinding struct {
+	BindIP        string `json:"bindIP,omitempty"`
+	ContainerPort int    `json:"containerPort,omitempty"`
+	HostPort      int    `json:"hostPort,omitempty"`
+	Protocol      string `json:"protocol,omitempty"`
+}
+
+// AttachmentStateChange is an attachment state change submitted by the agent
+type AttachmentStateChange struct {
+	AttachmentARN string `json:"attachmentArn"`
+	Status        string `json:"status"`
+}
+
+// AttachmentStateChanges are attachment state changes submitted by the agent
+type AttachmentStateChanges struct {
+	Cluster     string                   `json:"cluster"`
+	Attachments []*AttachmentStateChange `json:"attachments"`
+}
+
+// ContainerInstanceRegistration is a container instance registration by the agent
+type ContainerInstanceRegistration struct {
+	Cluster              string          `json:"cluster"`
+	ContainerInstanceARN string          `json:"containerInstanceArn,omitempty"`
+	Attributes           []Attribute     `json:"attributes,omitempty"`
+	TotalResources       json.RawMessage `json:"totalResources,omitempty"`
+	VersionInfo          json.RawMessage `json:"versionInfo,omitempty"`
+}
+
+// Attribute is an attribute of a container instance
+type Attribute struct {
+	Name  string  `json:"name"`
+	Value *string `json:"value,omitempty"`
+}
+
+// ContainerInstancesStateUpdate is an update of the status of container instances by the
+// agent, e.g. to drain the instance
+type ContainerInstancesStateUpdate struct {
+	Cluster            string   `json:"cluster"`
+	ContainerInstances []string `json:"containerInstances"`
+	Status             string   `json:"status"`
+}
+
+type createClusterRequest struct {
+	ClusterName string `json:"clusterName"`
+}
+
+type discoverPollEndpointRequest struct {
+	Cluster           string `json:"cluster"`
+	ContainerInstance string `json:"containerInstance"`
+}
+
+type listTagsForResourceRequest struct {
+	ResourceARN string `json:"resourceArn"`
+}
+
+type tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// newECSRequest returns the request of an ECS API operation to decode the body into, or
+// false if the operation isn't supported
+func newECSRequest(operation string) (interface{}, bool) {
+	switch operation {
+	case OperationCreateCluster:
+		return &createClusterRequest{}, true
+	case OperationDiscoverPollEndpoint:
+		return &discoverPollEndpointRequest{}, true
+	case OperationListTagsForResource:
+		return &listTagsForResourceRequest{}, true
+	case OperationRegisterContainerInstance:
+		return &ContainerInstanceRegistration{}, true
+	case OperationSubmitAttachmentStateChanges:
+		return &AttachmentStateChanges{}, true
+	case OperationSubmitContainerStateChange:
+		return &ContainerStateChange{}, true
+	case OperationSubmitTaskStateChange:
+		return &TaskStateChange{}, true
+	case OperationUpdateContainerInstancesState:
+		return &ContainerInstancesStateUpdate{}, true
+	}
+	return nil, false
+}
+
+// handleECSAPI serves the ECS API operations used by the agent. Each operation is a POST
+// of a JSON document, with the operation in the X-Amz-Target header.
+func (s *Server) handleECSAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeECSError(w, http.StatusMethodNotAllowed, "InvalidRequestException", "only POST is supported")
+		return
+	}
+	operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), ecsTargetPrefix)
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize))
+	if err != nil {
+		writeECSError(w, http.StatusBadRequest, "InvalidRequestException", err.Error())
+		return
+	}
+
+	request, ok := newECSRequest(operation)
+	if !ok {
+		logger.Warn("Emulator received an unsupported ECS API operation", logger.Fields{"operation": operation})
+		writeECSError(w, http.StatusBadRequest, "UnknownOperationException", "unsupported operation "+operation)
+		return
+	}
+	if err := json.Unmarshal(body, request); err != nil {
+		writeECSError(w, http.StatusBadRequest, "InvalidParameterException", err.Error())
+		return
+	}
+
+	var response interface{}
+	switch req := request.(type) {
+	case *createClusterRequest:
+		response = s.createCluster(req)
+	case *discoverPollEndpointRequest:
+		response = s.discoverPollEndpoint()
+	case *listTagsForResourceRequest:
+		response = s.listTagsForResource(req)
+	case *ContainerInstanceRegistration:
+		response = s.registerContainerInstance(req)
+	case *ContainerInstancesStateUpdate:
+		response = s.updateContainerInstancesState(req)
+	default:
+		response = map[string]string{"acknowledgment": "true"}
+	}
+
+	s.recorder.add(Record{
+		Source:  SourceECS,
+		Type:    operation,
+		Message: request,
+		Raw:     body,
+	})
+	w.Header().Set("Content-Type", ecsContentType)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) createCluster(req *createClusterRequest) interface{} {
+	name := req.ClusterName
+	if name == "" {
+		name = "default"
+	}
+	return map[string]interface{}{
+		"cluster": map[string]string{
+			"clusterArn":  s.clusterARNFor(name),
+			"clusterName": name,
+			"status":      "ACTIVE",
+		},
+	}
+}
+
+func (s *Server) discoverPollEndpoint() interface{} {
+	base := s.URL()
+	return map[string]string{
+		"endpoint":          base + acsPath,
+		"telemetryEndpoint": base + tcsPath,
+	}
+}
+
+func (s *Server) listTagsForResource(req *listTagsForResourceRequest) interface{} {
+	tags := []tag{}
+	for key, value := range s.resourceTags[req.ResourceARN] {
+		tags = append(tags, tag{Key: key, Value: value})
+	}
+	return map[string]interface{}{"tags": tags}
+}
+
+func (s *Server) registerContainerInstance(req *ContainerInstanceRegistration) interface{} {
+	cluster := req.Cluster
+	if cluster == "" {
+		cluster = "default"
+	}
+	if idx := strings.LastIndex(cluster, "/"); idx >= 0 {
+		cluster = cluster[idx+1:]
+	}
+
+	s.lock.Lock()
+	s.clusterARN = s.clusterARNFor(cluster)
+	if req.ContainerInstanceARN != "" {
+		s.containerInstanceARN = req.ContainerInstanceARN
+	} else if s.containerInstanceARN == "" {
+		s.containerInstanceARN = s.containerInstanceARNFor(cluster, uuid.New().String())
+	}
+	containerInstanceARN := s.containerInstanceARN
+	s.lock.Unlock()
+
+	availabilityZone := s.availabilityZone
+	attributes := append([]Attribute{}, req.Attributes...)
+	attributes = append(attributes, Attribute{Name: availabilityZoneAttrName, Value: &availabilityZone})
+	return map[string]interface{}{
+		"containerInstance": map[string]interface{}{
+			"containerInstanceArn": containerInstanceARN,
+			"attributes":           attributes,
+			"status":               "ACTIVE",
+			"agentConnected":       true,
+		},
+	}
+}
+
+func (s *Server) updateContainerInstancesState(req *ContainerInstancesStateUpdate) interface{} {
+	instances := []map[string]string{}
+	for _, instance := range req.ContainerInstances {
+		instances = append(instances, map[string]string{
+			"containerInstanceArn": instance,
+			"status":               req.Status,
+		})
+	}
+	return map[string]interface{}{
+		"containerInstances": instances,
+		"failures":           []interface{}{},
+	}
+}
+
+// writeECSError writes an error the way the ECS API does, with the type of the error in
+// the "__type" field
+func writeECSError(w http.ResponseWriter, status int, errorType, message string) {
+	w.Header().Set("Content-Type", ecsContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"__type":  errorType,
+		"message": message,
+	})
+}
@@ -0,0 +1,235 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package emulator implements a local emulator of the ECS control plane: the subset of
+// the ECS API used by the agent, the Agent Communication Service (ACS) and the Telemetry
+// Communication Service (TCS). It lets the agent be tested offline, without an ECS
+// cluster.
+//
+// The agent is pointed at the emulator by setting ECS_BACKEND_HOST to the URL of the
+// emulator. Requests are signed by the agent but the signatures aren't verified, so any
+// static AWS credentials can be used. The emulator pushes payloads, task manifests and
+// credentials refreshes to the agent, either programmatically or from scripted scenarios,
+// and records the state changes and telemetry sent by the agent so that tests can assert
+// on them.
+package emulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+)
+
+const (
+	// DefaultRegion is the region of the ARNs generated by the emulator
+	DefaultRegion = "us-west-2"
+	// DefaultAccountID is the account ID of the ARNs generated by the emulator
+	DefaultAccountID = "123456789012"
+	// DefaultHeartbeatInterval is the interval at which heartbeats are sent to the agent
+	DefaultHeartbeatInterval = 30 * time.Second
+
+	defaultAddress           = "127.0.0.1:0"
+	defaultAvailabilityZone  = "us-west-2a"
+	acsPath                  = "/acs"
+	tcsPath                  = "/tcs"
+	websocketPath            = "/ws"
+	shutdownTimeout          = 5 * time.Second
+	availabilityZoneAttrName = "ecs.availability-zone"
+)
+
+// ErrNotConnected is returned when a message is sent to the agent before it connected to ACS
+var ErrNotConnected = errors.New("emulator: agent is not connected to ACS")
+
+// Option configures the emulator
+type Option func(*Server)
+
+// WithAddress sets the address the emulator listens on, a random local port by default
+func WithAddress(address string) Option {
+	return func(s *Server) {
+		s.address = address
+	}
+}
+
+// WithRegion sets the region of the ARNs generated by the emulator
+func WithRegion(region string) Option {
+	return func(s *Server) {
+		s.region = region
+	}
+}
+
+// WithAccountID sets the account ID of the ARNs generated by the emulator
+func WithAccountID(accountID string) Option {
+	return func(s *Server) {
+		s.accountID = accountID
+	}
+}
+
+// WithAvailabilityZone sets the availability zone of the registered container instance
+func WithAvailabilityZone(availabilityZone string) Option {
+	return func(s *Server) {
+		s.availabilityZone = availabilityZone
+	}
+}
+
+// WithHeartbeatInterval sets the interval at which heartbeats are sent to the agent on
+// the ACS and TCS connections
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.heartbeatInterval = interval
+	}
+}
+
+// WithResourceTags sets the tags returned by ListTagsForResource for a resource
+func WithResourceTags(resourceARN string, tags map[string]string) Option {
+	return func(s *Server) {
+		s.resourceTags[resourceARN] = tags
+	}
+}
+
+// Server is the emulator of the ECS control plane
+type Server struct {
+	address           string
+	region            string
+	accountID         string
+	availabilityZone  string
+	heartbeatInterval time.Duration
+	resourceTags      map[string]map[string]string
+
+	listener   net.Listener
+	httpServer *http.Server
+	done       chan struct{}
+
+	lock                 sync.RWMutex
+	clusterARN           string
+	containerInstanceARN string
+	seqNum               int64
+	acs                  *connection
+	tcs                  *connection
+
+	recorder *recorder
+}
+
+// NewServer returns a new emulator, which must be started with Start
+func NewServer(options ...Option) *Server {
+	s := &Server{
+		address:           defaultAddress,
+		region:            DefaultRegion,
+		accountID:         DefaultAccountID,
+		availabilityZone:  defaultAvailabilityZone,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		resourceTags:      make(map[string]map[string]string),
+		done:              make(chan struct{}),
+		recorder:          newRecorder(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Start starts serving the ECS API, ACS and TCS endpoints
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("emulator: unable to listen on %s: %w", s.address, err)
+	}
+	s.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(acsPath+websocketPath, s.handleACS)
+	mux.HandleFunc(tcsPath+websocketPath, s.handleTCS)
+	mux.HandleFunc("/", s.handleECSAPI)
+	s.httpServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Emulator stopped serving", logger.Fields{"error": err})
+		}
+	}()
+	logger.Info("Emulator started", logger.Fields{"url": s.URL()})
+	return nil
+}
+
+// URL returns the URL of the emulator, to be set as ECS_BACKEND_HOST for the agent
+func (s *Server) URL() string {
+	if s.listener == nil {
+		return ""
+	}
+	return "http://" + s.listener.Addr().String()
+}
+
+// Close closes the connections with the agent and stops the emulator
+func (s *Server) Close() error {
+	if s.httpServer == nil {
+		return nil
+	}
+	close(s.done)
+	s.lock.Lock()
+	acs, tcs := s.acs, s.tcs
+	s.acs, s.tcs = nil, nil
+	s.lock.Unlock()
+	for _, conn := range []*connection{acs, tcs} {
+		if conn != nil {
+			conn.close()
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
+}
+
+// ClusterARN returns the ARN of the cluster the agent registered in, if any
+func (s *Server) ClusterARN() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.clusterARN
+}
+
+// ContainerInstanceARN returns the ARN of the container instance registered by the agent,
+// if any
+func (s *Server) ContainerInstanceARN() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.containerInstanceARN
+}
+
+// ACSConnected returns true if the agent is connected to ACS
+func (s *Server) ACSConnected() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.acs != nil
+}
+
+// WaitForACSConnection waits until the agent is connected to ACS
+func (s *Server) WaitForACSConnection(ctx context.Context) error {
+	_, err := s.WaitForRecord(ctx, func(record Record) bool {
+		return s.ACSConnected()
+	})
+	return err
+}
+
+func (s *Server) clusterARNFor(cluster string) string {
+	return fmt.Sprintf("arn:aws:ecs:%s:%s:cluster/%s", s.region, s.accountID, cluster)
+}
+
+func (s *Server) containerInstanceARNFor(cluster, id string) string {
+	return fmt.Sprintf("arn:aws:ecs:%s:%s:container-instance/%s/%s", s.region, s.accountID, cluster, id)
+}
@@ -0,0 +1,254 @@
+//go:build integration
+// +build integration
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	acsclient "github.com/aws/amazon-ecs-agent/ecs-agent/acs/client"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/session"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
+	ecsclient "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/client"
+	mock_config "github.com/aws/amazon-ecs-agent/ecs-agent/config/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	tcshandler "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/handler"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/wsclient"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	credentialsv2 "github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	integTestCluster         = "integ-test-cluster"
+	integTestTaskARN         = "arn:aws:ecs:us-west-2:123456789012:task/integ-test-cluster/abc"
+	testContainerInstanceARN = "arn:aws:ecs:us-west-2:123456789012:container-instance/integ-test-cluster/abc"
+	testAccessKeyID          = "AKIDEXAMPLE"
+	testSecretAccessKey      = "SECRET"
+	testAgentVersion         = "1.0.0"
+	testAgentHash            = "0000000"
+	testDockerVersion        = "20.10.0"
+	integTestTimeout         = 30 * time.Second
+)
+
+// ackingPayloadHandler acknowledges the payloads it receives, the way the agent does once
+// it has added their tasks to the task engine
+type ackingPayloadHandler struct{}
+
+func (ackingPayloadHandler) ProcessMessage(message *ecsacs.PayloadMessage,
+	ackFunc func(*ecsacs.AckRequest, []*ecsacs.IAMRoleCredentialsAckRequest)) error {
+	go ackFunc(&ecsacs.AckRequest{
+		Cluster:           message.ClusterArn,
+		ContainerInstance: message.ContainerInstanceArn,
+		MessageId:         message.MessageId,
+	}, nil)
+	return nil
+}
+
+func startIntegServer(t *testing.T, options ...Option) *Server {
+	server := NewServer(options...)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// newRealECSClient returns the ECS client used by the agent, pointed at the emulator
+func newRealECSClient(t *testing.T, ctrl *gomock.Controller, server *Server) ecs.ECSClient {
+	cfgAccessor := mock_config.NewMockAgentConfigAccessor(ctrl)
+	cfgAccessor.EXPECT().APIEndpoint().Return(server.URL()).AnyTimes()
+	cfgAccessor.EXPECT().AWSRegion().Return(DefaultRegion).AnyTimes()
+	cfgAccessor.EXPECT().AcceptInsecureCert().Return(false).AnyTimes()
+	cfgAccessor.EXPECT().OSType().Return("linux").AnyTimes()
+	cfgAccessor.EXPECT().Cluster().Return(integTestCluster).AnyTimes()
+	credentialsCache := awsv2.NewCredentialsCache(
+		credentialsv2.NewStaticCredentialsProvider(testAccessKeyID, testSecretAccessKey, ""))
+	client, err := ecsclient.NewECSClient(credentialsCache, cfgAccessor, nil, testAgentVersion)
+	require.NoError(t, err)
+	return client
+}
+
+func waitForDisconnect(ctx context.Context, t *testing.T, server *Server, source string) {
+	_, err := server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == source && record.Type == RecordTypeDisconnect
+	})
+	require.NoError(t, err)
+}
+
+// TestACSSessionLifecycle runs the ACS session of the agent against the emulator: the
+// session connects, processes and acknowledges a payload, and disconnects once stopped.
+func TestACSSessionLifecycle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := startIntegServer(t, WithHeartbeatInterval(100*time.Millisecond))
+	ecsClient := newRealECSClient(t, ctrl, server)
+	clusterARN := server.clusterARNFor(integTestCluster)
+	testDoctor, err := doctor.NewDoctor(nil, clusterARN, testContainerInstanceARN)
+	require.NoError(t, err)
+	credentialsCache := awsv2.NewCredentialsCache(
+		credentialsv2.NewStaticCredentialsProvider(testAccessKeyID, testSecretAccessKey, ""))
+
+	acsSession := session.NewSession(testContainerInstanceARN,
+		clusterARN,
+		ecsClient,
+		credentialsCache,
+		func() {},
+		acsclient.NewACSClientFactory(),
+		metrics.NewNopEntryFactory(),
+		testAgentVersion,
+		testAgentHash,
+		testDockerVersion,
+		&wsclient.WSClientMinAgentConfig{AWSRegion: DefaultRegion, IsDocker: true},
+		ackingPayloadHandler{},
+		nil,
+		nil,
+		testDoctor,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), integTestTimeout)
+	defer cancel()
+	sessionCtx, stopSession := context.WithCancel(ctx)
+	sessionDone := make(chan error, 1)
+	go func() { sessionDone <- acsSession.Start(sessionCtx) }()
+
+	require.NoError(t, server.WaitForACSConnection(ctx))
+	connect, err := server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == SourceACS && record.Type == RecordTypeConnect
+	})
+	require.NoError(t, err)
+	query := connect.Message.(url.Values)
+	assert.Equal(t, []string{clusterARN}, query["clusterArn"])
+	assert.Equal(t, []string{testContainerInstanceARN}, query["containerInstanceArn"])
+	assert.Equal(t, []string{"true"}, query["sendCredentials"])
+
+	// The session acknowledges the heartbeats of the emulator.
+	_, err = server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == SourceACS && record.Type == "HeartbeatAckRequest"
+	})
+	require.NoError(t, err)
+
+	payload := &ecsacs.PayloadMessage{
+		ClusterArn:           aws.String(clusterARN),
+		ContainerInstanceArn: aws.String(testContainerInstanceARN),
+		Tasks:                []*ecsacs.Task{{Arn: aws.String(integTestTaskARN)}},
+	}
+	require.NoError(t, server.SendACSMessage(payload))
+	ack, err := server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == SourceACS && record.Type == "AckRequest"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payload.MessageId, ack.Message.(*ecsacs.AckRequest).MessageId)
+
+	stopSession()
+	select {
+	case err := <-sessionDone:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-ctx.Done():
+		t.Fatal("ACS session didn't stop")
+	}
+	waitForDisconnect(ctx, t, server, SourceACS)
+	assert.False(t, server.ACSConnected())
+}
+
+// TestTCSSessionLifecycle runs the TCS session of the agent against the emulator: the
+// session connects, publishes metrics that are acknowledged, and disconnects once stopped.
+func TestTCSSessionLifecycle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := startIntegServer(t, WithHeartbeatInterval(100*time.Millisecond))
+	ecsClient := newRealECSClient(t, ctrl, server)
+	clusterARN := server.clusterARNFor(integTestCluster)
+
+	metricsChannel := make(chan ecstcs.TelemetryMessage, 1)
+	healthChannel := make(chan ecstcs.HealthMessage, 1)
+	telemetrySession := tcshandler.NewTelemetrySession(
+		testContainerInstanceARN,
+		clusterARN,
+		testAgentVersion,
+		testAgentHash,
+		testDockerVersion,
+		false,
+		credentials.NewStaticCredentials(testAccessKeyID, testSecretAccessKey, ""),
+		&wsclient.WSClientMinAgentConfig{AWSRegion: DefaultRegion, IsDocker: true},
+		nil,
+		time.Minute,
+		time.Second,
+		wsclient.DisconnectTimeout,
+		wsclient.DisconnectJitterMax,
+		metrics.NewNopEntryFactory(),
+		metricsChannel,
+		healthChannel,
+		nil,
+		ecsClient,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), integTestTimeout)
+	defer cancel()
+	sessionCtx, stopSession := context.WithCancel(ctx)
+	sessionDone := make(chan error, 1)
+	go func() { sessionDone <- telemetrySession.Start(sessionCtx) }()
+
+	connect, err := server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == SourceTCS && record.Type == RecordTypeConnect
+	})
+	require.NoError(t, err)
+	query := connect.Message.(url.Values)
+	assert.Equal(t, []string{clusterARN}, query["cluster"])
+	assert.Equal(t, []string{testContainerInstanceARN}, query["containerInstance"])
+
+	metricsChannel <- ecstcs.TelemetryMessage{
+		Metadata: &ecstcs.MetricsMetadata{
+			Cluster:           aws.String(clusterARN),
+			ContainerInstance: aws.String(testContainerInstanceARN),
+			Idle:              aws.Bool(true),
+			MessageId:         aws.String("metrics-message"),
+		},
+	}
+	record, err := server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == SourceTCS && record.Type == "PublishMetricsRequest"
+	})
+	require.NoError(t, err)
+	request := record.Message.(*ecstcs.PublishMetricsRequest)
+	assert.Equal(t, clusterARN, aws.StringValue(request.Metadata.Cluster))
+	assert.True(t, aws.BoolValue(request.Metadata.Fin))
+
+	stopSession()
+	select {
+	case <-sessionDone:
+	case <-ctx.Done():
+		t.Fatal("TCS session didn't stop")
+	}
+	waitForDisconnect(ctx, t, server, SourceTCS)
+}
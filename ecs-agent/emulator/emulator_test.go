@@ -0,0 +1,211 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	acsclient "github.com/aws/amazon-ecs-agent/ecs-agent/acs/client"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	tcsclient "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/client"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/wsclient"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCluster = "test-cluster"
+	testTaskARN = "arn:aws:ecs:us-west-2:123456789012:task/test-cluster/abc"
+)
+
+func startServer(t *testing.T, options ...Option) *Server {
+	server := NewServer(options...)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+func newECSClient(server *Server) *ecs.Client {
+	return ecs.New(ecs.Options{
+		Region:       DefaultRegion,
+		BaseEndpoint: awsv2.String(server.URL()),
+		Credentials: awsv2.NewCredentialsCache(
+			credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "SECRET", "")),
+		RetryMaxAttempts: 1,
+	})
+}
+
+func dial(t *testing.T, server *Server, path string) *websocket.Conn {
+	url := strings.Replace(server.URL(), "http://", "ws://", 1) + path + websocketPath
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn, decoder wsclient.TypeDecoder) (interface{}, string) {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	message, messageType, err := wsclient.DecodeData(data, decoder)
+	require.NoError(t, err)
+	return message, messageType
+}
+
+func TestECSAPI(t *testing.T) {
+	server := startServer(t, WithResourceTags(testTaskARN, map[string]string{"team": "a"}))
+	client := newECSClient(server)
+	ctx := context.Background()
+
+	registration, err := client.RegisterContainerInstance(ctx, &ecs.RegisterContainerInstanceInput{
+		Cluster:    aws.String(testCluster),
+		Attributes: []ecstypes.Attribute{{Name: aws.String("ecs.os-type"), Value: aws.String("linux")}},
+	})
+	require.NoError(t, err)
+	instanceARN := aws.StringValue(registration.ContainerInstance.ContainerInstanceArn)
+	assert.True(t, strings.HasPrefix(instanceARN, "arn:aws:ecs:us-west-2:123456789012:container-instance/test-cluster/"))
+	assert.Equal(t, instanceARN, server.ContainerInstanceARN())
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:cluster/test-cluster", server.ClusterARN())
+	assert.Contains(t, registration.ContainerInstance.Attributes, ecstypes.Attribute{
+		Name:  aws.String(availabilityZoneAttrName),
+		Value: aws.String(defaultAvailabilityZone),
+	})
+
+	endpoints, err := client.DiscoverPollEndpoint(ctx, &ecs.DiscoverPollEndpointInput{
+		Cluster:           aws.String(testCluster),
+		ContainerInstance: aws.String(instanceARN),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, server.URL()+acsPath, aws.StringValue(endpoints.Endpoint))
+	assert.Equal(t, server.URL()+tcsPath, aws.StringValue(endpoints.TelemetryEndpoint))
+
+	tags, err := client.ListTagsForResource(ctx, &ecs.ListTagsForResourceInput{ResourceArn: aws.String(testTaskARN)})
+	require.NoError(t, err)
+	assert.Equal(t, []ecstypes.Tag{{Key: aws.String("team"), Value: aws.String("a")}}, tags.Tags)
+
+	_, err = client.SubmitTaskStateChange(ctx, &ecs.SubmitTaskStateChangeInput{
+		Cluster: aws.String(testCluster),
+		Task:    aws.String(testTaskARN),
+		Status:  aws.String("RUNNING"),
+		Containers: []ecstypes.ContainerStateChange{{
+			ContainerName: aws.String("app"),
+			Status:        aws.String("RUNNING"),
+			RuntimeId:     aws.String("id"),
+		}},
+	})
+	require.NoError(t, err)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, server.WaitForTaskStatus(waitCtx, testTaskARN, "RUNNING"))
+	changes := server.TaskStateChanges()
+	require.Len(t, changes, 1)
+	assert.Equal(t, testCluster, changes[0].Cluster)
+	require.Len(t, server.ContainerStateChanges(), 1)
+	assert.Equal(t, "app", server.ContainerStateChanges()[0].ContainerName)
+
+	_, err = client.DeleteCluster(ctx, &ecs.DeleteClusterInput{Cluster: aws.String(testCluster)})
+	assert.ErrorContains(t, err, "UnknownOperationException")
+}
+
+func TestWaitForTaskStatusTimeout(t *testing.T) {
+	server := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, server.WaitForTaskStatus(ctx, testTaskARN, "RUNNING"), context.DeadlineExceeded)
+}
+
+func TestSendACSMessage(t *testing.T) {
+	server := startServer(t, WithHeartbeatInterval(50*time.Millisecond))
+	assert.ErrorIs(t, server.SendPayload(), ErrNotConnected)
+
+	conn := dial(t, server, acsPath)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, server.WaitForACSConnection(ctx))
+
+	decoder := acsclient.NewACSDecoder()
+	_, messageType := readMessage(t, conn, decoder)
+	assert.Equal(t, "HeartbeatMessage", messageType)
+
+	require.NoError(t, server.SendPayload(&ecsacs.Task{Arn: aws.String(testTaskARN)}))
+	var payload *ecsacs.PayloadMessage
+	for payload == nil {
+		message, _ := readMessage(t, conn, decoder)
+		payload, _ = message.(*ecsacs.PayloadMessage)
+	}
+	assert.NotEmpty(t, aws.StringValue(payload.MessageId))
+	assert.Equal(t, int64(1), aws.Int64Value(payload.SeqNum))
+	require.Len(t, payload.Tasks, 1)
+	assert.Equal(t, testTaskARN, aws.StringValue(payload.Tasks[0].Arn))
+
+	ack, err := encodeMessage(decoder, &ecsacs.AckRequest{MessageId: payload.MessageId})
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, ack))
+	record, err := server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == SourceACS && record.Type == "AckRequest"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payload.MessageId, record.Message.(*ecsacs.AckRequest).MessageId)
+
+	conn.Close()
+	_, err = server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == SourceACS && record.Type == RecordTypeDisconnect
+	})
+	require.NoError(t, err)
+	assert.False(t, server.ACSConnected())
+}
+
+func TestTCSAcknowledgesTelemetry(t *testing.T) {
+	server := startServer(t)
+	conn := dial(t, server, tcsPath)
+	decoder := tcsclient.NewTCSDecoder()
+
+	request, err := encodeMessage(decoder, &ecstcs.PublishMetricsRequest{
+		Metadata: &ecstcs.MetricsMetadata{Cluster: aws.String(testCluster)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, request))
+
+	message, _ := readMessage(t, conn, decoder)
+	assert.IsType(t, &ecstcs.AckPublishMetric{}, message)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	record, err := server.WaitForRecord(ctx, func(record Record) bool {
+		return record.Source == SourceTCS && record.Type == "PublishMetricsRequest"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testCluster, aws.StringValue(record.Message.(*ecstcs.PublishMetricsRequest).Metadata.Cluster))
+}
+
+func TestStripSignedHeaders(t *testing.T) {
+	body := `{"type":"PublishMetricsRequest","message":{}}`
+	assert.Equal(t, body, string(stripSignedHeaders([]byte(body))))
+	signed := "Authorization: AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE\r\nHost: 127.0.0.1\r\n\r\n" + body
+	assert.Equal(t, body, string(stripSignedHeaders([]byte(signed))))
+}
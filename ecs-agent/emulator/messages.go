@@ -0,0 +1,147 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	acsclient "github.com/aws/amazon-ecs-agent/ecs-agent/acs/client"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	tcsclient "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/client"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/wsclient"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+)
+
+func newDecoder(source string) wsclient.TypeDecoder {
+	if source == SourceTCS {
+		return tcsclient.NewTCSDecoder()
+	}
+	return acsclient.NewACSDecoder()
+}
+
+func newMessageID() string {
+	return uuid.New().String()
+}
+
+// DecodeACSMessage decodes an ACS message of the given type, e.g. "PayloadMessage", from
+// its JSON representation
+func DecodeACSMessage(messageType string, message json.RawMessage) (interface{}, error) {
+	data, err := json.Marshal(&wsclient.RequestMessage{Type: messageType, Message: message})
+	if err != nil {
+		return nil, err
+	}
+	decoded, _, err := wsclient.DecodeData(data, acsclient.NewACSDecoder())
+	if err != nil {
+		return nil, fmt.Errorf("emulator: unable to decode ACS message of type %s: %w", messageType, err)
+	}
+	return decoded, nil
+}
+
+// NewCredentialsMessage returns a message refreshing the credentials of the role of a task.
+// roleType is either "TaskApplication" or "TaskExecution".
+func NewCredentialsMessage(taskARN, roleType string, credentials *ecsacs.IAMRoleCredentials) *ecsacs.IAMRoleCredentialsMessage {
+	return &ecsacs.IAMRoleCredentialsMessage{
+		TaskArn:         aws.String(taskARN),
+		RoleType:        aws.String(roleType),
+		RoleCredentials: credentials,
+	}
+}
+
+// SendACSMessage sends a message to the agent on its ACS connection. The message IDs,
+// cluster and container instance ARNs, sequence numbers and timestamps that aren't set
+// are filled in.
+func (s *Server) SendACSMessage(message interface{}) error {
+	s.lock.Lock()
+	conn := s.acs
+	if conn != nil {
+		s.fillACSMessage(message)
+	}
+	s.lock.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	return conn.send(message)
+}
+
+// SendPayload sends the tasks to the agent in a payload message
+func (s *Server) SendPayload(tasks ...*ecsacs.Task) error {
+	return s.SendACSMessage(&ecsacs.PayloadMessage{Tasks: tasks})
+}
+
+// SendTaskManifest sends the tasks that should be running on the instance to the agent,
+// which stops the tasks that aren't in the manifest
+func (s *Server) SendTaskManifest(tasks ...*ecsacs.TaskIdentifier) error {
+	return s.SendACSMessage(&ecsacs.TaskManifestMessage{Tasks: tasks})
+}
+
+// fillACSMessage fills in the fields of a message that aren't set. It must be called with
+// the lock held.
+func (s *Server) fillACSMessage(message interface{}) {
+	switch msg := message.(type) {
+	case *ecsacs.PayloadMessage:
+		s.seqNum++
+		if msg.MessageId == nil {
+			msg.MessageId = aws.String(newMessageID())
+		}
+		if msg.ClusterArn == nil {
+			msg.ClusterArn = aws.String(s.clusterARN)
+		}
+		if msg.ContainerInstanceArn == nil {
+			msg.ContainerInstanceArn = aws.String(s.containerInstanceARN)
+		}
+		if msg.GeneratedAt == nil {
+			msg.GeneratedAt = aws.Int64(time.Now().Unix())
+		}
+		if msg.SeqNum == nil {
+			msg.SeqNum = aws.Int64(s.seqNum)
+		}
+	case *ecsacs.TaskManifestMessage:
+		s.seqNum++
+		if msg.MessageId == nil {
+			msg.MessageId = aws.String(newMessageID())
+		}
+		if msg.ClusterArn == nil {
+			msg.ClusterArn = aws.String(s.clusterARN)
+		}
+		if msg.ContainerInstanceArn == nil {
+			msg.ContainerInstanceArn = aws.String(s.containerInstanceARN)
+		}
+		if msg.GeneratedAt == nil {
+			msg.GeneratedAt = aws.Int64(time.Now().Unix())
+		}
+		if msg.Timeline == nil {
+			msg.Timeline = aws.Int64(s.seqNum)
+		}
+		for _, task := range msg.Tasks {
+			if task.TaskClusterArn == nil {
+				task.TaskClusterArn = aws.String(s.clusterARN)
+			}
+		}
+	case *ecsacs.IAMRoleCredentialsMessage:
+		if msg.MessageId == nil {
+			msg.MessageId = aws.String(newMessageID())
+		}
+		if msg.TaskClusterArn == nil {
+			msg.TaskClusterArn = aws.String(s.clusterARN)
+		}
+	case *ecsacs.HeartbeatMessage:
+		if msg.MessageId == nil {
+			msg.MessageId = aws.String(newMessageID())
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// SourceECS is the source of the records of the ECS API calls made by the agent
+	SourceECS = "ECS"
+	// SourceACS is the source of the records of the messages sent by the agent to ACS
+	SourceACS = "ACS"
+	// SourceTCS is the source of the records of the messages sent by the agent to TCS
+	SourceTCS = "TCS"
+
+	// RecordTypeConnect is the type of the records of the agent connecting to ACS or TCS
+	RecordTypeConnect = "Connect"
+	// RecordTypeDisconnect is the type of the records of the agent disconnecting from ACS
+	// or TCS
+	RecordTypeDisconnect = "Disconnect"
+)
+
+// Record is a request or a message received from the agent
+type Record struct {
+	Time time.Time
+	// Source is where the record was received, one of SourceECS, SourceACS or SourceTCS
+	Source string
+	// Type is the ECS API operation, the type of the ACS or TCS message, or one of
+	// RecordTypeConnect and RecordTypeDisconnect
+	Type string
+	// Message is the decoded request or message: a *TaskStateChange, a
+	// *ContainerStateChange, an ecsacs or ecstcs message, the url.Values of the query of a
+	// connection, or nil if the message couldn't be decoded
+	Message interface{}
+	// Raw is the body of the request or message
+	Raw []byte
+}
+
+// recorder keeps the records received from the agent, in order
+type recorder struct {
+	lock    sync.Mutex
+	records []Record
+	// changed is closed and replaced every time a record is added
+	changed chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		changed: make(chan struct{}),
+	}
+}
+
+func (r *recorder) add(record Record) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	r.records = append(r.records, record)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func (r *recorder) snapshot(from int) ([]Record, chan struct{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	records := make([]Record, len(r.records)-from)
+	copy(records, r.records[from:])
+	return records, r.changed
+}
+
+// Records returns all the records received from the agent, in order
+func (s *Server) Records() []Record {
+	records, _ := s.recorder.snapshot(0)
+	return records
+}
+
+// TaskStateChanges returns the task state changes submitted by the agent, in order
+func (s *Server) TaskStateChanges() []*TaskStateChange {
+	var changes []*TaskStateChange
+	for _, record := range s.Records() {
+		if change, ok := record.Message.(*TaskStateChange); ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// ContainerStateChanges returns the container state changes submitted by the agent, in
+// order, including the ones submitted along with task state changes
+func (s *Server) ContainerStateChanges() []*ContainerStateChange {
+	var changes []*ContainerStateChange
+	for _, record := range s.Records() {
+		switch message := record.Message.(type) {
+		case *ContainerStateChange:
+			changes = append(changes, message)
+		case *TaskStateChange:
+			changes = append(changes, message.Containers...)
+		}
+	}
+	return changes
+}
+
+// WaitForRecord waits for a record matching the predicate, including the records already
+// received, and returns the first one.
+func (s *Server) WaitForRecord(ctx context.Context, match func(Record) bool) (Record, error) {
+	next := 0
+	for {
+		records, changed := s.recorder.snapshot(next)
+		for _, record := range records {
+			if match(record) {
+				return record, nil
+			}
+		}
+		next += len(records)
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Record{}, ctx.Err()
+		}
+	}
+}
+
+// WaitForTaskStatus waits until the agent submitted a task state change with the status
+// for the task
+func (s *Server) WaitForTaskStatus(ctx context.Context, taskARN, status string) error {
+	_, err := s.WaitForRecord(ctx, func(record Record) bool {
+		change, ok := record.Message.(*TaskStateChange)
+		return ok && change.Task == taskARN && change.Status == status
+	})
+	return err
+}
@@ -0,0 +1,186 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+)
+
+const defaultStepTimeout = 5 * time.Minute
+
+// Scenario is a script of messages sent to the agent and of conditions waited for, e.g.
+//
+//	{
+//	  "name": "start and stop a task",
+//	  "steps": [
+//	    {"send": {"type": "PayloadMessage", "message": {"tasks": [...]}}},
+//	    {"waitForTaskStatus": {"taskArn": "arn:...", "status": "RUNNING", "timeout": "2m"}},
+//	    {"sleep": "10s"},
+//	    {"send": {"type": "TaskManifestMessage", "message": {"tasks": []}}},
+//	    {"waitForTaskStatus": {"taskArn": "arn:...", "status": "STOPPED"}}
+//	  ]
+//	}
+type Scenario struct {
+	Name  string `json:"name"`
+	Steps []Step `json:"steps"`
+}
+
+// Step is a step of a scenario. Exactly one of its actions must be set.
+type Step struct {
+	Name string `json:"name,omitempty"`
+	// Sleep waits for a duration
+	Sleep Duration `json:"sleep,omitempty"`
+	// Send sends a message to the agent on its ACS connection, once it's connected
+	Send *Message `json:"send,omitempty"`
+	// WaitForTaskStatus waits until the agent submitted a task state change
+	WaitForTaskStatus *TaskStatusCondition `json:"waitForTaskStatus,omitempty"`
+}
+
+// Message is an ACS message, in the {"type": ..., "message": ...} envelope used on the
+// wire, e.g. {"type": "IAMRoleCredentialsMessage", "message": {"taskArn": ...}}
+type Message struct {
+	Type    string          `json:"type"`
+	Message json.RawMessage `json:"message"`
+}
+
+// TaskStatusCondition is a task status submitted by the agent
+type TaskStatusCondition struct {
+	TaskARN string `json:"taskArn"`
+	Status  string `json:"status"`
+	// Timeout is how long to wait for the status, 5 minutes by default
+	Timeout Duration `json:"timeout,omitempty"`
+}
+
+// Duration is a time.Duration represented in JSON as a string such as "1m30s"
+type Duration time.Duration
+
+// MarshalJSON marshals the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON unmarshals the duration from a string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\": %w", err)
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// LoadScenario reads a scenario from a JSON file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("emulator: unable to read scenario: %w", err)
+	}
+	scenario := &Scenario{}
+	if err := json.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("emulator: unable to parse scenario %s: %w", path, err)
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("emulator: invalid scenario %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+func (scenario *Scenario) validate() error {
+	for i, step := range scenario.Steps {
+		actions := 0
+		if step.Sleep != 0 {
+			actions++
+		}
+		if step.Send != nil {
+			actions++
+			if _, err := DecodeACSMessage(step.Send.Type, step.Send.Message); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+		}
+		if step.WaitForTaskStatus != nil {
+			actions++
+			if step.WaitForTaskStatus.TaskARN == "" || step.WaitForTaskStatus.Status == "" {
+				return fmt.Errorf("step %d: waitForTaskStatus requires a taskArn and a status", i)
+			}
+		}
+		if actions != 1 {
+			return fmt.Errorf("step %d: exactly one of sleep, send and waitForTaskStatus must be set", i)
+		}
+	}
+	return nil
+}
+
+// RunScenario runs the steps of a scenario in order, stopping at the first failed step
+func (s *Server) RunScenario(ctx context.Context, scenario *Scenario) error {
+	if err := scenario.validate(); err != nil {
+		return fmt.Errorf("emulator: invalid scenario %s: %w", scenario.Name, err)
+	}
+	for i, step := range scenario.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i)
+		}
+		logger.Info("Emulator running scenario step", logger.Fields{"scenario": scenario.Name, "step": name})
+		if err := s.runStep(ctx, step); err != nil {
+			return fmt.Errorf("emulator: scenario %s failed at %s: %w", scenario.Name, name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Server) runStep(ctx context.Context, step Step) error {
+	switch {
+	case step.Sleep != 0:
+		select {
+		case <-time.After(time.Duration(step.Sleep)):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case step.Send != nil:
+		message, err := DecodeACSMessage(step.Send.Type, step.Send.Message)
+		if err != nil {
+			return err
+		}
+		if err := s.WaitForACSConnection(ctx); err != nil {
+			return err
+		}
+		return s.SendACSMessage(message)
+	case step.WaitForTaskStatus != nil:
+		timeout := time.Duration(step.WaitForTaskStatus.Timeout)
+		if timeout == 0 {
+			timeout = defaultStepTimeout
+		}
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		err := s.WaitForTaskStatus(waitCtx, step.WaitForTaskStatus.TaskARN, step.WaitForTaskStatus.Status)
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return fmt.Errorf("task %s did not reach status %s within %s", step.WaitForTaskStatus.TaskARN,
+				step.WaitForTaskStatus.Status, timeout)
+		}
+		return err
+	}
+	return nil
+}
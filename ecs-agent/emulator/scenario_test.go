@@ -0,0 +1,129 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	acsclient "github.com/aws/amazon-ecs-agent/ecs-agent/acs/client"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testScenario = `{
+  "name": "credentials refresh",
+  "steps": [
+    {"name": "start", "send": {"type": "PayloadMessage", "message": {"tasks": [{"arn": "` + testTaskARN + `"}]}}},
+    {"waitForTaskStatus": {"taskArn": "` + testTaskARN + `", "status": "RUNNING", "timeout": "5s"}},
+    {"sleep": "10ms"},
+    {"send": {"type": "IAMRoleCredentialsMessage", "message": {"taskArn": "` + testTaskARN + `", "roleType": "TaskApplication", "roleCredentials": {"credentialsId": "creds"}}}}
+  ]
+}`
+
+func writeScenario(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadScenario(t *testing.T) {
+	scenario, err := LoadScenario(writeScenario(t, testScenario))
+	require.NoError(t, err)
+	assert.Equal(t, "credentials refresh", scenario.Name)
+	require.Len(t, scenario.Steps, 4)
+	assert.Equal(t, Duration(5*time.Second), scenario.Steps[1].WaitForTaskStatus.Timeout)
+	assert.Equal(t, Duration(10*time.Millisecond), scenario.Steps[2].Sleep)
+}
+
+func TestLoadScenarioInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		scenario string
+	}{
+		{"not json", `{`},
+		{"invalid duration", `{"steps": [{"sleep": "soon"}]}`},
+		{"no action", `{"steps": [{"name": "nothing"}]}`},
+		{"several actions", `{"steps": [{"sleep": "1s", "send": {"type": "PayloadMessage", "message": {}}}]}`},
+		{"unknown message type", `{"steps": [{"send": {"type": "UnknownMessage", "message": {}}}]}`},
+		{"incomplete condition", `{"steps": [{"waitForTaskStatus": {"status": "RUNNING"}}]}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadScenario(writeScenario(t, tc.scenario))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRunScenario(t *testing.T) {
+	server := startServer(t)
+	scenario, err := LoadScenario(writeScenario(t, testScenario))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.RunScenario(ctx, scenario)
+	}()
+
+	// the scenario waits for the agent to connect before sending the payload
+	conn := dial(t, server, acsPath)
+	decoder := acsclient.NewACSDecoder()
+	message, _ := readMessage(t, conn, decoder)
+	payload, ok := message.(*ecsacs.PayloadMessage)
+	require.True(t, ok)
+	assert.Equal(t, testTaskARN, aws.StringValue(payload.Tasks[0].Arn))
+
+	_, err = newECSClient(server).SubmitTaskStateChange(ctx, &ecs.SubmitTaskStateChangeInput{
+		Cluster: aws.String(testCluster),
+		Task:    aws.String(testTaskARN),
+		Status:  aws.String("RUNNING"),
+	})
+	require.NoError(t, err)
+
+	message, _ = readMessage(t, conn, decoder)
+	credentials, ok := message.(*ecsacs.IAMRoleCredentialsMessage)
+	require.True(t, ok)
+	assert.Equal(t, "creds", aws.StringValue(credentials.RoleCredentials.CredentialsId))
+	assert.NotEmpty(t, aws.StringValue(credentials.MessageId))
+	require.NoError(t, <-done)
+}
+
+func TestRunScenarioTimeout(t *testing.T) {
+	server := startServer(t)
+	scenario := &Scenario{
+		Name: "timeout",
+		Steps: []Step{{
+			WaitForTaskStatus: &TaskStatusCondition{
+				TaskARN: testTaskARN,
+				Status:  "RUNNING",
+				Timeout: Duration(10 * time.Millisecond),
+			},
+		}},
+	}
+	err := server.RunScenario(context.Background(), scenario)
+	assert.ErrorContains(t, err, "did not reach status RUNNING")
+}
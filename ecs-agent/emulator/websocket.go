@@ -0,0 +1,229 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package emulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/wsclient"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/gorilla/websocket"
+)
+
+const writeTimeout = 10 * time.Second
+
+// signedHeadersSeparator separates the signed headers of a TCS message from its body
+var signedHeadersSeparator = []byte("\r\n\r\n")
+
+var upgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024}
+
+// connection is a websocket connection of the agent to ACS or TCS
+type connection struct {
+	source    string
+	conn      *websocket.Conn
+	decoder   wsclient.TypeDecoder
+	writeLock sync.Mutex
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// send encodes the message the way ACS and TCS do and sends it to the agent
+func (c *connection) send(message interface{}) error {
+	data, err := encodeMessage(c.decoder, message)
+	if err != nil {
+		return err
+	}
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *connection) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.writeLock.Lock()
+		c.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		c.writeLock.Unlock()
+		c.conn.Close()
+	})
+}
+
+// encodeMessage frames a message in the {"type": ..., "message": ...} envelope used by ACS
+// and TCS, the type being the name of the message struct
+func encodeMessage(decoder wsclient.TypeDecoder, message interface{}) ([]byte, error) {
+	msg := &wsclient.RequestMessage{}
+	for typeStr, typeVal := range decoder.GetRecognizedTypes() {
+		if reflect.TypeOf(message) == reflect.PtrTo(typeVal) {
+			msg.Type = typeStr
+			break
+		}
+	}
+	if msg.Type == "" {
+		return nil, &wsclient.UnrecognizedWSRequestType{Type: reflect.TypeOf(message).String()}
+	}
+	data, err := jsonutil.BuildJSON(message)
+	if err != nil {
+		return nil, &wsclient.NotMarshallableWSRequest{Type: msg.Type, Err: err}
+	}
+	msg.Message = json.RawMessage(data)
+	return json.Marshal(msg)
+}
+
+func (s *Server) handleACS(w http.ResponseWriter, r *http.Request) {
+	s.serveWebsocket(w, r, SourceACS, func(conn *connection) { s.setConnection(&s.acs, conn) },
+		func(conn *connection) { s.clearConnection(&s.acs, conn) })
+}
+
+func (s *Server) handleTCS(w http.ResponseWriter, r *http.Request) {
+	s.serveWebsocket(w, r, SourceTCS, func(conn *connection) { s.setConnection(&s.tcs, conn) },
+		func(conn *connection) { s.clearConnection(&s.tcs, conn) })
+}
+
+// serveWebsocket upgrades the request of the agent to a websocket connection, sends it
+// heartbeats and records the messages it sends until the connection is closed.
+func (s *Server) serveWebsocket(w http.ResponseWriter, r *http.Request, source string,
+	register, unregister func(*connection)) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("Emulator unable to upgrade connection", logger.Fields{"source": source, "error": err})
+		return
+	}
+	conn := &connection{
+		source:  source,
+		conn:    ws,
+		decoder: newDecoder(source),
+		closed:  make(chan struct{}),
+	}
+	register(conn)
+	s.recorder.add(Record{
+		Source:  source,
+		Type:    RecordTypeConnect,
+		Message: r.URL.Query(),
+		Raw:     []byte(r.URL.RawQuery),
+	})
+	logger.Info("Agent connected to emulator", logger.Fields{"source": source})
+
+	go s.sendHeartbeats(conn)
+	defer func() {
+		unregister(conn)
+		conn.close()
+		s.recorder.add(Record{Source: source, Type: RecordTypeDisconnect})
+		logger.Info("Agent disconnected from emulator", logger.Fields{"source": source})
+	}()
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		message, messageType, err := wsclient.DecodeData(stripSignedHeaders(data), conn.decoder)
+		if err != nil {
+			logger.Warn("Emulator unable to decode message", logger.Fields{"source": source, "error": err})
+			message = nil
+		}
+		s.recorder.add(Record{
+			Source:  source,
+			Type:    messageType,
+			Message: message,
+			Raw:     data,
+		})
+		if ack := telemetryAck(message); ack != nil {
+			if err := conn.send(ack); err != nil {
+				logger.Warn("Emulator unable to acknowledge message", logger.Fields{"source": source, "error": err})
+			}
+		}
+	}
+}
+
+func (s *Server) setConnection(current **connection, conn *connection) {
+	s.lock.Lock()
+	previous := *current
+	*current = conn
+	s.lock.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+}
+
+func (s *Server) clearConnection(current **connection, conn *connection) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if *current == conn {
+		*current = nil
+	}
+}
+
+// sendHeartbeats sends heartbeats to the agent, without which it reconnects
+func (s *Server) sendHeartbeats(conn *connection) {
+	ticker := time.NewTicker(s.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			var heartbeat interface{}
+			if conn.source == SourceACS {
+				heartbeat = &ecsacs.HeartbeatMessage{
+					Healthy:   aws.Bool(true),
+					MessageId: aws.String(newMessageID()),
+				}
+			} else {
+				heartbeat = &ecstcs.HeartbeatMessage{Healthy: aws.Bool(true)}
+			}
+			if err := conn.send(heartbeat); err != nil {
+				logger.Warn("Emulator unable to send heartbeat", logger.Fields{"source": conn.source, "error": err})
+			}
+		case <-conn.closed:
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// stripSignedHeaders returns the body of a message signed by the TCS client, which
+// prefixes each message with the headers of a signed HTTP request. Unsigned messages are
+// returned as is.
+func stripSignedHeaders(data []byte) []byte {
+	if len(data) == 0 || data[0] == '{' {
+		return data
+	}
+	if i := bytes.Index(data, signedHeadersSeparator); i >= 0 {
+		return data[i+len(signedHeadersSeparator):]
+	}
+	return data
+}
+
+// telemetryAck returns the acknowledgement TCS sends for a message, if any
+func telemetryAck(message interface{}) interface{} {
+	switch message.(type) {
+	case *ecstcs.PublishMetricsRequest:
+		return &ecstcs.AckPublishMetric{Message: aws.String("ok")}
+	case *ecstcs.PublishHealthRequest:
+		return &ecstcs.AckPublishHealth{Message: aws.String("ok")}
+	case *ecstcs.PublishInstanceStatusRequest:
+		return &ecstcs.AckPublishInstanceStatus{Message: aws.String("ok")}
+	}
+	return nil
+}
@@ -60,6 +60,14 @@ type Config struct {
 	writeTimeout       time.Duration // http server write timeout
 	enableRuntimeStats bool          // enable profiling handlers
 	hideAgentVersion   bool          // if true, do not show Version in metadata
+	metricsHandler     http.Handler  // if set, serves agent metrics on metrics.PrometheusMetricsPath
+	handlers           []pathHandler // additional handlers, in the order in which they were added
+}
+
+// pathHandler is a handler served on a path in addition to the default handlers
+type pathHandler struct {
+	path    string
+	handler http.Handler
 }
 
 // Function type for updating Introspection Server config
@@ -94,6 +102,21 @@ func HideAgentVersion(isHidden bool) ConfigOpt {
 	}
 }
 
+// Set the handler serving agent metrics. If set, the handler is registered on
+// metrics.PrometheusMetricsPath.
+func WithMetricsHandler(metricsHandler http.Handler) ConfigOpt {
+	return func(c *Config) {
+		c.metricsHandler = metricsHandler
+	}
+}
+
+// Add a handler served on the given path, which is listed in the available commands
+func WithHandler(path string, handler http.Handler) ConfigOpt {
+	return func(c *Config) {
+		c.handlers = append(c.handlers, pathHandler{path: path, handler: handler})
+	}
+}
+
 // Create a new HTTP Introspection Server
 func NewServer(agentState v1.AgentState, metricsFactory metrics.EntryFactory, options ...ConfigOpt) (*http.Server, error) {
 	config := new(Config)
@@ -138,6 +161,14 @@ func setup(
 		paths = append(paths, pprofBasePath, pprofCMDLinePath, pprofProfilePath, pprofSymbolPath, pprofTracePath)
 	}
 
+	if config.metricsHandler != nil {
+		paths = append(paths, metrics.PrometheusMetricsPath)
+	}
+
+	for _, h := range config.handlers {
+		paths = append(paths, h.path)
+	}
+
 	availableCommands := &rootResponse{paths}
 	// Autogenerated list of the above serverFunctions paths
 	availableCommandResponse, err := json.Marshal(&availableCommands)
@@ -158,6 +189,12 @@ func setup(
 		pprofHandlerSetup(serveMux)
 		wTimeout = writeTimeoutForPprof
 	}
+	if config.metricsHandler != nil {
+		serveMux.Handle(metrics.PrometheusMetricsPath, config.metricsHandler)
+	}
+	for _, h := range config.handlers {
+		serveMux.Handle(h.path, h.handler)
+	}
 
 	loggingServeMux := http.NewServeMux()
 	loggingServeMux.Handle("/", logging.NewLoggingHandler(serveMux))
@@ -203,3 +203,68 @@ func TestPProfHandlerSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestMetricsHandlerSetup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	agentState := mock_v1.NewMockAgentState(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+
+	t.Run("metrics handler not set", func(t *testing.T) {
+		server, err := NewServer(agentState, metricsFactory)
+		require.NoError(t, err)
+		req, err := http.NewRequest("GET", "/metrics", nil)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `{"AvailableCommands":["/v1/metadata","/v1/tasks","/license"]}`, recorder.Body.String())
+	})
+
+	t.Run("metrics handler set", func(t *testing.T) {
+		metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("metrics"))
+		})
+		server, err := NewServer(agentState, metricsFactory, WithMetricsHandler(metricsHandler))
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("GET", "/metrics", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "metrics", recorder.Body.String())
+
+		req, err = http.NewRequest("GET", "/", nil)
+		require.NoError(t, err)
+		recorder = httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, req)
+		assert.Equal(t, `{"AvailableCommands":["/v1/metadata","/v1/tasks","/license","/metrics"]}`, recorder.Body.String())
+	})
+}
+
+func TestAdditionalHandlerSetup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	agentState := mock_v1.NewMockAgentState(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("custom"))
+	})
+	server, err := NewServer(agentState, metricsFactory, WithHandler("/v1/custom", handler))
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("GET", "/v1/custom", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "custom", recorder.Body.String())
+
+	req, err = http.NewRequest("GET", "/", nil)
+	require.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, req)
+	assert.Equal(t, `{"AvailableCommands":["/v1/metadata","/v1/tasks","/license","/v1/custom"]}`, recorder.Body.String())
+}
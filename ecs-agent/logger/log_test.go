@@ -19,6 +19,7 @@ package logger
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -28,6 +29,14 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// testLogFile returns a log file in a temporary directory of the test
+func testLogFile(t *testing.T) string {
+	logfile := filepath.Join(t.TempDir(), "foo.log")
+	// The logger writes asynchronously, flush it before the directory is removed
+	t.Cleanup(seelog.Flush)
+	return logfile
+}
+
 func TestEcsMsgFormat(t *testing.T) {
 	logfmt := ecsMsgFormatter("")
 	out := logfmt("This is my log message", seelog.InfoLvl, &LogContextMock{})
@@ -236,7 +245,7 @@ func TestSetLogLevels(t *testing.T) {
 			os.Setenv(LOG_DRIVER_ENV_VAR, test.logDriver)
 
 			Config = &logConfig{
-				logfile:       "foo.log",
+				logfile:       testLogFile(t),
 				driverLevel:   DEFAULT_LOGLEVEL,
 				instanceLevel: setInstanceLevelDefault(),
 				RolloverType:  DEFAULT_ROLLOVER_TYPE,
@@ -352,6 +352,9 @@ func TestSeelogConfig_NoOnInstanceLog(t *testing.T) {
 
 func TestSeelogConfig_DifferentLevels(t *testing.T) {
 	SetDefaultConfig()
+	// Setting the levels reloads the logger, which writes to the log file
+	logfile := testLogFile(t)
+	Config.logfile = logfile
 
 	SetDriverLogLevel("warn")
 	SetInstanceLogLevel("critical")
@@ -366,7 +369,7 @@ func TestSeelogConfig_DifferentLevels(t *testing.T) {
 			<console />
 		</filter>
 		<filter levels="info,warn,error,critical">
-			<rollingfile filename="foo.log" type="date"
+			<rollingfile filename="`+logfile+`" type="date"
 			 datepattern="2006-01-02-15" archivetype="none" maxrolls="24" />
 		</filter>
 	</outputs>
@@ -0,0 +1,236 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RuntimeConfig is the part of the logger configuration that can be changed while the
+// agent is running. Empty and zero fields are left unchanged when it's applied.
+type RuntimeConfig struct {
+	DriverLevel   string  `json:"DriverLevel,omitempty"`
+	InstanceLevel string  `json:"InstanceLevel,omitempty"`
+	OutputFormat  string  `json:"OutputFormat,omitempty"`
+	RolloverType  string  `json:"RolloverType,omitempty"`
+	MaxFileSizeMB float64 `json:"MaxFileSizeMB,omitempty"`
+	MaxRollCount  int     `json:"MaxRollCount,omitempty"`
+}
+
+// runtimeOverride tracks the configuration to revert to once a timed change expires
+type runtimeOverride struct {
+	lock     sync.Mutex
+	previous *RuntimeConfig
+	timer    *time.Timer
+	revertAt time.Time
+}
+
+var override = &runtimeOverride{}
+
+// parseLogLevel returns the seelog level of an ECS_LOGLEVEL value, or of a seelog level
+// as returned by GetRuntimeConfig
+func parseLogLevel(level string) (string, bool) {
+	level = strings.ToLower(level)
+	if parsedLevel, ok := logLevels[level]; ok {
+		return parsedLevel, true
+	}
+	for _, parsedLevel := range logLevels {
+		if parsedLevel == level {
+			return parsedLevel, true
+		}
+	}
+	return "", false
+}
+
+// validate checks the fields set in the configuration, and maps the levels to seelog levels
+func (c *RuntimeConfig) validate() error {
+	if c.DriverLevel != "" {
+		parsedLevel, ok := parseLogLevel(c.DriverLevel)
+		if !ok {
+			return fmt.Errorf("invalid driver log level: %s", c.DriverLevel)
+		}
+		c.DriverLevel = parsedLevel
+	}
+	if c.InstanceLevel != "" {
+		parsedLevel, ok := parseLogLevel(c.InstanceLevel)
+		if !ok {
+			return fmt.Errorf("invalid instance log level: %s", c.InstanceLevel)
+		}
+		c.InstanceLevel = parsedLevel
+	}
+	if c.OutputFormat != "" && c.OutputFormat != logFmt && c.OutputFormat != jsonFmt {
+		return fmt.Errorf("invalid log output format: %s", c.OutputFormat)
+	}
+	if c.RolloverType != "" && c.RolloverType != "date" && c.RolloverType != "size" && c.RolloverType != "none" {
+		return fmt.Errorf("invalid log rollover type: %s", c.RolloverType)
+	}
+	if c.MaxFileSizeMB < 0 {
+		return fmt.Errorf("invalid max log file size: %v", c.MaxFileSizeMB)
+	}
+	if c.MaxRollCount < 0 {
+		return fmt.Errorf("invalid max log roll count: %d", c.MaxRollCount)
+	}
+	return nil
+}
+
+// merge sets the fields of c to the fields set in update
+func (c *RuntimeConfig) merge(update RuntimeConfig) {
+	if update.DriverLevel != "" {
+		c.DriverLevel = update.DriverLevel
+	}
+	if update.InstanceLevel != "" {
+		c.InstanceLevel = update.InstanceLevel
+	}
+	if update.OutputFormat != "" {
+		c.OutputFormat = update.OutputFormat
+	}
+	if update.RolloverType != "" {
+		c.RolloverType = update.RolloverType
+	}
+	if update.MaxFileSizeMB > 0 {
+		c.MaxFileSizeMB = update.MaxFileSizeMB
+	}
+	if update.MaxRollCount > 0 {
+		c.MaxRollCount = update.MaxRollCount
+	}
+}
+
+func currentRuntimeConfig() RuntimeConfig {
+	Config.lock.Lock()
+	defer Config.lock.Unlock()
+
+	return Config.runtimeConfig()
+}
+
+// runtimeConfig returns the runtime configuration, the lock must be held by the caller
+func (c *logConfig) runtimeConfig() RuntimeConfig {
+	return RuntimeConfig{
+		DriverLevel:   c.driverLevel,
+		InstanceLevel: c.instanceLevel,
+		OutputFormat:  c.outputFormat,
+		RolloverType:  c.RolloverType,
+		MaxFileSizeMB: c.MaxFileSizeMB,
+		MaxRollCount:  c.MaxRollCount,
+	}
+}
+
+// setRuntimeConfig sets the fields set in update and reloads the logger once
+func setRuntimeConfig(update RuntimeConfig) {
+	Config.lock.Lock()
+	defer Config.lock.Unlock()
+
+	current := Config.runtimeConfig()
+	current.merge(update)
+	Config.driverLevel = current.DriverLevel
+	Config.instanceLevel = current.InstanceLevel
+	Config.outputFormat = current.OutputFormat
+	Config.RolloverType = current.RolloverType
+	Config.MaxFileSizeMB = current.MaxFileSizeMB
+	Config.MaxRollCount = current.MaxRollCount
+	reloadConfig()
+}
+
+// GetRuntimeConfig returns the current runtime configuration of the logger, and the time
+// it reverts at if it was changed for a limited duration.
+func GetRuntimeConfig() (RuntimeConfig, time.Time) {
+	override.lock.Lock()
+	defer override.lock.Unlock()
+
+	return currentRuntimeConfig(), override.revertAt
+}
+
+// ApplyRuntimeConfig validates and applies the fields set in update to the logger. When
+// revertAfter is positive, the configuration from before the change is restored once it
+// elapses. Changing the configuration again for a limited duration restarts the countdown
+// and still reverts to the configuration from before the first timed change, and changes
+// without a duration are also applied to the configuration reverted to.
+func ApplyRuntimeConfig(update RuntimeConfig, revertAfter time.Duration) error {
+	if err := update.validate(); err != nil {
+		return err
+	}
+
+	override.lock.Lock()
+	defer override.lock.Unlock()
+
+	if revertAfter > 0 {
+		if override.previous == nil {
+			previous := currentRuntimeConfig()
+			override.previous = &previous
+		}
+		if override.timer != nil {
+			override.timer.Stop()
+		}
+		revertAt := time.Now().Add(revertAfter)
+		override.revertAt = revertAt
+		override.timer = time.AfterFunc(revertAfter, func() { revertRuntimeConfig(revertAt) })
+	} else if override.previous != nil {
+		override.previous.merge(update)
+	}
+	setRuntimeConfig(update)
+	Info("Changed the logger configuration", Fields{
+		"driverLevel":   update.DriverLevel,
+		"instanceLevel": update.InstanceLevel,
+		"outputFormat":  update.OutputFormat,
+		"rolloverType":  update.RolloverType,
+		"maxFileSizeMB": update.MaxFileSizeMB,
+		"maxRollCount":  update.MaxRollCount,
+		"revertAfter":   revertAfter.String(),
+	})
+	return nil
+}
+
+// RevertRuntimeConfig restores the configuration from before a timed change right away. It
+// returns false if there is no timed change to revert.
+func RevertRuntimeConfig() bool {
+	override.lock.Lock()
+	defer override.lock.Unlock()
+
+	return override.revert()
+}
+
+// revertRuntimeConfig reverts the timed change expiring at revertAt, unless it was replaced
+// or reverted in the meantime
+func revertRuntimeConfig(revertAt time.Time) {
+	override.lock.Lock()
+	defer override.lock.Unlock()
+
+	if override.previous == nil || !override.revertAt.Equal(revertAt) {
+		return
+	}
+	override.revert()
+}
+
+func (o *runtimeOverride) revert() bool {
+	if o.previous == nil {
+		return false
+	}
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	previous := *o.previous
+	o.previous = nil
+	o.timer = nil
+	o.revertAt = time.Time{}
+	setRuntimeConfig(previous)
+	Info("Reverted the logger configuration", Fields{
+		"driverLevel":   previous.DriverLevel,
+		"instanceLevel": previous.InstanceLevel,
+		"outputFormat":  previous.OutputFormat,
+		"rolloverType":  previous.RolloverType,
+	})
+	return true
+}
@@ -0,0 +1,136 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetRuntimeConfig(t *testing.T) {
+	original := currentRuntimeConfig()
+	Config.lock.Lock()
+	logfile := Config.logfile
+	Config.logfile = testLogFile(t)
+	Config.lock.Unlock()
+	t.Cleanup(func() {
+		RevertRuntimeConfig()
+		Config.lock.Lock()
+		Config.logfile = logfile
+		Config.lock.Unlock()
+		setRuntimeConfig(original)
+	})
+	setRuntimeConfig(RuntimeConfig{
+		DriverLevel:   "info",
+		InstanceLevel: "info",
+		OutputFormat:  logFmt,
+		RolloverType:  "date",
+		MaxFileSizeMB: DEFAULT_MAX_FILE_SIZE,
+		MaxRollCount:  DEFAULT_MAX_ROLL_COUNT,
+	})
+}
+
+func TestApplyRuntimeConfigInvalid(t *testing.T) {
+	resetRuntimeConfig(t)
+	before := currentRuntimeConfig()
+
+	for _, update := range []RuntimeConfig{
+		{DriverLevel: "verbose"},
+		{InstanceLevel: "trace"},
+		{OutputFormat: "xml"},
+		{RolloverType: "weekly"},
+		{MaxFileSizeMB: -1},
+		{MaxRollCount: -1},
+	} {
+		assert.Error(t, ApplyRuntimeConfig(update, 0))
+	}
+	assert.Equal(t, before, currentRuntimeConfig())
+}
+
+func TestApplyRuntimeConfig(t *testing.T) {
+	resetRuntimeConfig(t)
+
+	require.NoError(t, ApplyRuntimeConfig(RuntimeConfig{
+		DriverLevel:  "crit",
+		OutputFormat: jsonFmt,
+		RolloverType: "size",
+		MaxRollCount: 5,
+	}, 0))
+	current, revertAt := GetRuntimeConfig()
+	assert.Equal(t, RuntimeConfig{
+		DriverLevel:   "critical",
+		InstanceLevel: "info",
+		OutputFormat:  jsonFmt,
+		RolloverType:  "size",
+		MaxFileSizeMB: DEFAULT_MAX_FILE_SIZE,
+		MaxRollCount:  5,
+	}, current)
+	assert.True(t, revertAt.IsZero())
+	assert.Equal(t, "critical", GetLevel())
+	assert.False(t, RevertRuntimeConfig())
+}
+
+func TestApplyRuntimeConfigTimed(t *testing.T) {
+	resetRuntimeConfig(t)
+	before := currentRuntimeConfig()
+
+	require.NoError(t, ApplyRuntimeConfig(RuntimeConfig{DriverLevel: "debug", InstanceLevel: "debug"}, 100*time.Millisecond))
+	current, revertAt := GetRuntimeConfig()
+	assert.Equal(t, "debug", current.DriverLevel)
+	assert.Equal(t, "debug", current.InstanceLevel)
+	assert.False(t, revertAt.IsZero())
+
+	require.Eventually(t, func() bool {
+		current, revertAt := GetRuntimeConfig()
+		return current == before && revertAt.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestApplyRuntimeConfigTimedTwice(t *testing.T) {
+	resetRuntimeConfig(t)
+
+	require.NoError(t, ApplyRuntimeConfig(RuntimeConfig{DriverLevel: "debug"}, time.Hour))
+	_, firstRevertAt := GetRuntimeConfig()
+	require.NoError(t, ApplyRuntimeConfig(RuntimeConfig{OutputFormat: jsonFmt}, 2*time.Hour))
+	// A change without a duration is kept once the timed changes are reverted
+	require.NoError(t, ApplyRuntimeConfig(RuntimeConfig{RolloverType: "none"}, 0))
+	current, revertAt := GetRuntimeConfig()
+	assert.True(t, revertAt.After(firstRevertAt))
+	assert.Equal(t, "debug", current.DriverLevel)
+	assert.Equal(t, jsonFmt, current.OutputFormat)
+	assert.Equal(t, "none", current.RolloverType)
+
+	assert.True(t, RevertRuntimeConfig())
+	current, revertAt = GetRuntimeConfig()
+	assert.True(t, revertAt.IsZero())
+	assert.Equal(t, "info", current.DriverLevel)
+	assert.Equal(t, logFmt, current.OutputFormat)
+	assert.Equal(t, "none", current.RolloverType)
+}
+
+func TestRevertRuntimeConfigExpiredTimer(t *testing.T) {
+	resetRuntimeConfig(t)
+
+	require.NoError(t, ApplyRuntimeConfig(RuntimeConfig{DriverLevel: "debug"}, time.Hour))
+	// The timer of a timed change that was replaced doesn't revert the new one
+	revertRuntimeConfig(time.Now())
+	current, _ := GetRuntimeConfig()
+	assert.Equal(t, "debug", current.DriverLevel)
+}
@@ -77,4 +77,9 @@ const (
 	DeleteNetworkNamespaceMetricName      = networkBuilderNamespace + ".DeleteNetworkNamespace"
 	V2NDestinationPortExhaustedMetricName = networkBuilderNamespace + ".V2NDestinationPortExhausted"
 	ReleaseGeneveDstPortMetricName        = dbClientMetricNamespace + ".ReleaseGeneveDstPort"
+
+	// Queue of the tasks waiting for host resources
+	taskQueueNamespace       = "TaskQueue"
+	TaskQueueDepthMetricName = taskQueueNamespace + ".Depth"
+	TaskQueueWaitMetricName  = taskQueueNamespace + ".Wait"
 )
@@ -0,0 +1,301 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// PrometheusMetricsPath is the path on which the Prometheus exposition is served.
+	PrometheusMetricsPath = "/metrics"
+
+	prometheusNamespace = "ecs_agent"
+
+	operationsTotalMetricName   = prometheusNamespace + "_operations_total"
+	operationDurationMetricName = prometheusNamespace + "_operation_duration_seconds"
+	operationGaugeMetricName    = prometheusNamespace + "_operation_gauge"
+
+	operationLabel = "operation"
+	resultLabel    = "result"
+	resultSuccess  = "success"
+	resultFailure  = "failure"
+)
+
+var (
+	// durationBuckets are the upper bounds (in seconds) of the operation duration histogram.
+	durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+	// Injection point for testing
+	now = time.Now
+)
+
+// PrometheusEntryFactory is an EntryFactory that aggregates the metric entries it creates
+// in memory and serves them over HTTP in the Prometheus exposition format.
+type PrometheusEntryFactory interface {
+	EntryFactory
+	http.Handler
+	// Gather returns a snapshot of all the metric families recorded so far.
+	Gather() []*dto.MetricFamily
+}
+
+// prometheusEntryFactory implements the PrometheusEntryFactory interface. Every entry
+// created by the factory is recorded against its operation name:
+//   - ecs_agent_operations_total{operation,result} counts completed entries, split by
+//     whether Done was called with an error.
+//   - ecs_agent_operation_duration_seconds{operation} tracks the time between New and Done.
+//   - ecs_agent_operation_gauge{operation} holds the last value set with WithGauge.
+//
+// Fields set with WithFields are not recorded, as they would result in unbounded label
+// cardinality.
+type prometheusEntryFactory struct {
+	lock      sync.RWMutex
+	counters  map[operationResult]float64
+	durations map[string]*durationHistogram
+	gauges    map[string]float64
+}
+
+type operationResult struct {
+	op     string
+	result string
+}
+
+type durationHistogram struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// NewPrometheusEntryFactory creates a metric entry factory that records metrics in
+// the Prometheus data model.
+func NewPrometheusEntryFactory() PrometheusEntryFactory {
+	return &prometheusEntryFactory{
+		counters:  make(map[operationResult]float64),
+		durations: make(map[string]*durationHistogram),
+		gauges:    make(map[string]float64),
+	}
+}
+
+func (f *prometheusEntryFactory) New(op string) Entry {
+	return &prometheusEntry{
+		factory:   f,
+		op:        op,
+		count:     1,
+		startTime: now(),
+	}
+}
+
+// Flush is a no-op as Prometheus metrics are pulled by the scraper.
+func (f *prometheusEntryFactory) Flush() {}
+
+// ServeHTTP writes all the recorded metric families in the format negotiated from the
+// Accept header of the request.
+func (f *prometheusEntryFactory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format := expfmt.Negotiate(r.Header)
+	w.Header().Set("Content-Type", string(format))
+	encoder := expfmt.NewEncoder(w, format)
+	for _, mf := range f.Gather() {
+		if err := encoder.Encode(mf); err != nil {
+			// Headers have already been written at this point, there is nothing
+			// more that can be reported to the client.
+			return
+		}
+	}
+}
+
+func (f *prometheusEntryFactory) Gather() []*dto.MetricFamily {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	var families []*dto.MetricFamily
+	if len(f.counters) > 0 {
+		family := newMetricFamily(operationsTotalMetricName,
+			"Number of completed operations, by operation name and result.", dto.MetricType_COUNTER)
+		for key, value := range f.counters {
+			family.Metric = append(family.Metric, &dto.Metric{
+				Label:   labelPairs(operationLabel, key.op, resultLabel, key.result),
+				Counter: &dto.Counter{Value: proto.Float64(value)},
+			})
+		}
+		families = append(families, family)
+	}
+	if len(f.durations) > 0 {
+		family := newMetricFamily(operationDurationMetricName,
+			"Time taken by operations, in seconds.", dto.MetricType_HISTOGRAM)
+		for op, histogram := range f.durations {
+			family.Metric = append(family.Metric, &dto.Metric{
+				Label:     labelPairs(operationLabel, op),
+				Histogram: histogram.toProto(),
+			})
+		}
+		families = append(families, family)
+	}
+	if len(f.gauges) > 0 {
+		family := newMetricFamily(operationGaugeMetricName,
+			"Last value reported for an operation.", dto.MetricType_GAUGE)
+		for op, value := range f.gauges {
+			family.Metric = append(family.Metric, &dto.Metric{
+				Label: labelPairs(operationLabel, op),
+				Gauge: &dto.Gauge{Value: proto.Float64(value)},
+			})
+		}
+		families = append(families, family)
+	}
+	for _, family := range families {
+		sortMetrics(family.Metric)
+	}
+	return families
+}
+
+func (f *prometheusEntryFactory) record(op string, count int, gauge *float64, duration time.Duration, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	result := resultSuccess
+	if err != nil {
+		result = resultFailure
+	}
+	f.counters[operationResult{op: op, result: result}] += float64(count)
+
+	histogram, ok := f.durations[op]
+	if !ok {
+		histogram = &durationHistogram{buckets: make([]uint64, len(durationBuckets))}
+		f.durations[op] = histogram
+	}
+	histogram.observe(duration.Seconds())
+
+	if gauge != nil {
+		f.gauges[op] = *gauge
+	}
+}
+
+func (h *durationHistogram) observe(value float64) {
+	h.count++
+	h.sum += value
+	for i, upperBound := range durationBuckets {
+		if value <= upperBound {
+			h.buckets[i]++
+		}
+	}
+}
+
+func (h *durationHistogram) toProto() *dto.Histogram {
+	buckets := make([]*dto.Bucket, len(durationBuckets))
+	for i, upperBound := range durationBuckets {
+		buckets[i] = &dto.Bucket{
+			UpperBound:      proto.Float64(upperBound),
+			CumulativeCount: proto.Uint64(h.buckets[i]),
+		}
+	}
+	return &dto.Histogram{
+		SampleCount: proto.Uint64(h.count),
+		SampleSum:   proto.Float64(h.sum),
+		Bucket:      buckets,
+	}
+}
+
+type prometheusEntry struct {
+	factory   *prometheusEntryFactory
+	op        string
+	count     int
+	gauge     *float64
+	startTime time.Time
+}
+
+func (e *prometheusEntry) WithFields(f map[string]interface{}) Entry {
+	return e
+}
+
+func (e *prometheusEntry) WithCount(count int) Entry {
+	e.count = count
+	return e
+}
+
+func (e *prometheusEntry) WithGauge(value interface{}) Entry {
+	if v, ok := toFloat64(value); ok {
+		e.gauge = &v
+	}
+	return e
+}
+
+func (e *prometheusEntry) Done(err error) {
+	e.factory.record(e.op, e.count, e.gauge, now().Sub(e.startTime), err)
+}
+
+// toFloat64 converts the numeric types that may be passed to WithGauge into a float64.
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case time.Duration:
+		return v.Seconds(), true
+	default:
+		return 0, false
+	}
+}
+
+func newMetricFamily(name, help string, metricType dto.MetricType) *dto.MetricFamily {
+	return &dto.MetricFamily{
+		Name: proto.String(name),
+		Help: proto.String(help),
+		Type: metricType.Enum(),
+	}
+}
+
+// labelPairs builds label pairs from alternating label names and values.
+func labelPairs(nameValues ...string) []*dto.LabelPair {
+	pairs := make([]*dto.LabelPair, 0, len(nameValues)/2)
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		pairs = append(pairs, &dto.LabelPair{
+			Name:  proto.String(nameValues[i]),
+			Value: proto.String(nameValues[i+1]),
+		})
+	}
+	return pairs
+}
+
+// sortMetrics orders metrics by their label values so that the exposition is stable
+// across scrapes.
+func sortMetrics(metrics []*dto.Metric) {
+	sort.Slice(metrics, func(i, j int) bool {
+		li, lj := metrics[i].Label, metrics[j].Label
+		for k := 0; k < len(li) && k < len(lj); k++ {
+			if li[k].GetValue() != lj[k].GetValue() {
+				return li[k].GetValue() < lj[k].GetValue()
+			}
+		}
+		return len(li) < len(lj)
+	})
+}
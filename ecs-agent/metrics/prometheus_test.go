@@ -0,0 +1,137 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOp = "Test.Operation"
+
+func familiesByName(families []*dto.MetricFamily) map[string]*dto.MetricFamily {
+	byName := make(map[string]*dto.MetricFamily)
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+	return byName
+}
+
+func TestPrometheusEntryFactoryEmpty(t *testing.T) {
+	factory := NewPrometheusEntryFactory()
+	assert.Empty(t, factory.Gather())
+}
+
+func TestPrometheusEntryFactoryCounts(t *testing.T) {
+	factory := NewPrometheusEntryFactory()
+	factory.New(testOp).Done(nil)
+	factory.New(testOp).WithCount(3).Done(nil)
+	factory.New(testOp).Done(errors.New("error"))
+
+	families := familiesByName(factory.Gather())
+	counters := families[operationsTotalMetricName]
+	require.NotNil(t, counters)
+	assert.Equal(t, dto.MetricType_COUNTER, counters.GetType())
+	require.Len(t, counters.Metric, 2)
+
+	// Metrics are sorted by label values, failure before success.
+	failure, success := counters.Metric[0], counters.Metric[1]
+	assert.Equal(t, testOp, failure.Label[0].GetValue())
+	assert.Equal(t, resultFailure, failure.Label[1].GetValue())
+	assert.Equal(t, float64(1), failure.Counter.GetValue())
+	assert.Equal(t, resultSuccess, success.Label[1].GetValue())
+	assert.Equal(t, float64(4), success.Counter.GetValue())
+}
+
+func TestPrometheusEntryFactoryDuration(t *testing.T) {
+	defer func() { now = time.Now }()
+	start := time.Now()
+	now = func() time.Time { return start }
+	factory := NewPrometheusEntryFactory()
+	entry := factory.New(testOp)
+	now = func() time.Time { return start.Add(200 * time.Millisecond) }
+	entry.Done(nil)
+
+	families := familiesByName(factory.Gather())
+	durations := families[operationDurationMetricName]
+	require.NotNil(t, durations)
+	require.Len(t, durations.Metric, 1)
+	histogram := durations.Metric[0].Histogram
+	assert.Equal(t, uint64(1), histogram.GetSampleCount())
+	assert.InDelta(t, 0.2, histogram.GetSampleSum(), 0.0001)
+	for _, bucket := range histogram.Bucket {
+		if bucket.GetUpperBound() < 0.2 {
+			assert.Equal(t, uint64(0), bucket.GetCumulativeCount())
+		} else {
+			assert.Equal(t, uint64(1), bucket.GetCumulativeCount())
+		}
+	}
+}
+
+func TestPrometheusEntryFactoryGauge(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected float64
+		recorded bool
+	}{
+		{name: "int", value: 5, expected: 5, recorded: true},
+		{name: "int64", value: int64(7), expected: 7, recorded: true},
+		{name: "float64", value: 1.5, expected: 1.5, recorded: true},
+		{name: "duration", value: 2 * time.Second, expected: 2, recorded: true},
+		{name: "unsupported", value: "5", recorded: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := NewPrometheusEntryFactory()
+			factory.New(testOp).WithGauge(tc.value).Done(nil)
+
+			gauges, ok := familiesByName(factory.Gather())[operationGaugeMetricName]
+			assert.Equal(t, tc.recorded, ok)
+			if tc.recorded {
+				require.Len(t, gauges.Metric, 1)
+				assert.Equal(t, tc.expected, gauges.Metric[0].Gauge.GetValue())
+			}
+		})
+	}
+}
+
+func TestPrometheusEntryFactoryServeHTTP(t *testing.T) {
+	factory := NewPrometheusEntryFactory()
+	factory.New(testOp).WithGauge(10).Done(nil)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", PrometheusMetricsPath, nil)
+	require.NoError(t, err)
+	factory.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(recorder.Body)
+	require.NoError(t, err)
+	assert.Contains(t, families, operationsTotalMetricName)
+	assert.Contains(t, families, operationDurationMetricName)
+	assert.Contains(t, families, operationGaugeMetricName)
+}
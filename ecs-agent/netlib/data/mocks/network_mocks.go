@@ -63,6 +63,35 @@ func (mr *MockNetworkDataClientMockRecorder) AssignGeneveDstPort(arg0 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignGeneveDstPort", reflect.TypeOf((*MockNetworkDataClient)(nil).AssignGeneveDstPort), arg0)
 }
 
+// DeleteBridgeConfig mocks base method.
+func (m *MockNetworkDataClient) DeleteBridgeConfig(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBridgeConfig", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBridgeConfig indicates an expected call of DeleteBridgeConfig.
+func (mr *MockNetworkDataClientMockRecorder) DeleteBridgeConfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBridgeConfig", reflect.TypeOf((*MockNetworkDataClient)(nil).DeleteBridgeConfig), arg0)
+}
+
+// GetBridgeConfig mocks base method.
+func (m *MockNetworkDataClient) GetBridgeConfig(arg0 string) (*tasknetworkconfig.BridgeConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBridgeConfig", arg0)
+	ret0, _ := ret[0].(*tasknetworkconfig.BridgeConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBridgeConfig indicates an expected call of GetBridgeConfig.
+func (mr *MockNetworkDataClientMockRecorder) GetBridgeConfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBridgeConfig", reflect.TypeOf((*MockNetworkDataClient)(nil).GetBridgeConfig), arg0)
+}
+
 // GetNetworkNamespace mocks base method.
 func (m *MockNetworkDataClient) GetNetworkNamespace(arg0 string) (*tasknetworkconfig.NetworkNamespace, error) {
 	m.ctrl.T.Helper()
@@ -107,6 +136,20 @@ func (mr *MockNetworkDataClientMockRecorder) ReleaseGeneveDstPort(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseGeneveDstPort", reflect.TypeOf((*MockNetworkDataClient)(nil).ReleaseGeneveDstPort), arg0, arg1)
 }
 
+// SaveBridgeConfig mocks base method.
+func (m *MockNetworkDataClient) SaveBridgeConfig(arg0 *tasknetworkconfig.BridgeConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBridgeConfig", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBridgeConfig indicates an expected call of SaveBridgeConfig.
+func (mr *MockNetworkDataClientMockRecorder) SaveBridgeConfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBridgeConfig", reflect.TypeOf((*MockNetworkDataClient)(nil).SaveBridgeConfig), arg0)
+}
+
 // SaveNetworkNamespace mocks base method.
 func (m *MockNetworkDataClient) SaveNetworkNamespace(arg0 *tasknetworkconfig.NetworkNamespace) error {
 	m.ctrl.T.Helper()
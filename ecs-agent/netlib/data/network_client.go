@@ -31,4 +31,12 @@ type NetworkDataClient interface {
 	// ReleaseGeneveDstPort tells the client that the port is no longer in use by the interface having
 	// the mentioned VNI. The port could be reused later.
 	ReleaseGeneveDstPort(port uint16, vni string) error
+
+	// SaveBridgeConfig persists the bridge configuration of a task in bridge network mode.
+	SaveBridgeConfig(bridgeConfig *tasknetworkconfig.BridgeConfig) error
+	// GetBridgeConfig returns the persisted bridge configuration of a task in bridge network mode.
+	GetBridgeConfig(taskID string) (*tasknetworkconfig.BridgeConfig, error)
+	// DeleteBridgeConfig deletes the bridge configuration of a task once its netns is
+	// disconnected from the bridge.
+	DeleteBridgeConfig(taskID string) error
 }
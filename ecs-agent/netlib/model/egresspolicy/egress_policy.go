@@ -0,0 +1,333 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package egresspolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DockerLabel is the docker label of the container definitions of a task through which
+	// the task can specify its egress policy, which can only narrow the policy of the instance.
+	DockerLabel = "com.amazonaws.ecs.egress-policy"
+
+	// ActionAllow lets the traffic through.
+	ActionAllow Action = "ALLOW"
+	// ActionDeny rejects the traffic.
+	ActionDeny Action = "DENY"
+
+	// ProtocolAll matches the traffic of any protocol.
+	ProtocolAll Protocol = "all"
+	// ProtocolTCP matches TCP traffic.
+	ProtocolTCP Protocol = "tcp"
+	// ProtocolUDP matches UDP traffic.
+	ProtocolUDP Protocol = "udp"
+	// ProtocolICMP matches ICMP traffic, or ICMPv6 traffic for IPv6 destinations.
+	ProtocolICMP Protocol = "icmp"
+
+	minPort = 1
+	maxPort = 65535
+)
+
+// Action is the action applied to the traffic matching a rule.
+type Action string
+
+// Protocol is the protocol of the traffic matched by a rule.
+type Protocol string
+
+// EgressPolicy restricts the destinations the containers of a task can connect to from
+// within the task network namespace. Rules are evaluated in order, and the first rule
+// matching the traffic applies. Traffic matching no rule is subject to the default action.
+type EgressPolicy struct {
+	DefaultAction Action `json:"DefaultAction"`
+	Rules         []Rule `json:"Rules,omitempty"`
+}
+
+// Rule matches the traffic sent to a CIDR block, optionally restricted to a protocol
+// and to destination ports.
+type Rule struct {
+	Action   Action   `json:"Action"`
+	CIDR     string   `json:"CIDR"`
+	Protocol Protocol `json:"Protocol,omitempty"`
+	// Ports lists destination ports or port ranges in the form of "8000-8080". It can
+	// only be set for the tcp and udp protocols.
+	Ports []string `json:"Ports,omitempty"`
+}
+
+// Parse parses and validates an egress policy in JSON. The default action of the
+// policy is ALLOW unless specified.
+func Parse(policyJSON string) (*EgressPolicy, error) {
+	var policy EgressPolicy
+	if err := json.Unmarshal([]byte(policyJSON), &policy); err != nil {
+		return nil, errors.Wrap(err, "unable to parse egress policy")
+	}
+	if policy.DefaultAction == "" {
+		policy.DefaultAction = ActionAllow
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FromDockerLabels returns the egress policy specified by the docker labels of the container
+// definitions of a task, or nil if none specifies one. All containers specifying a policy
+// must specify the same one, as the policy applies to the whole task network namespace.
+func FromDockerLabels(containerLabels ...map[string]string) (*EgressPolicy, error) {
+	var policyJSON string
+	for _, labels := range containerLabels {
+		value, ok := labels[DockerLabel]
+		if !ok {
+			continue
+		}
+		if policyJSON != "" && value != policyJSON {
+			return nil, errors.New("conflicting egress policies specified by the containers of the task")
+		}
+		policyJSON = value
+	}
+	if policyJSON == "" {
+		return nil, nil
+	}
+	return Parse(policyJSON)
+}
+
+// FromContainerConfigs returns the egress policy specified by the docker labels of the
+// containers of a task, given their docker container configs in JSON.
+func FromContainerConfigs(containerConfigs ...string) (*EgressPolicy, error) {
+	var containerLabels []map[string]string
+	for _, containerConfig := range containerConfigs {
+		if containerConfig == "" {
+			continue
+		}
+		var config struct {
+			Labels map[string]string `json:"Labels"`
+		}
+		if err := json.Unmarshal([]byte(containerConfig), &config); err != nil {
+			return nil, errors.Wrap(err, "unable to parse container config")
+		}
+		containerLabels = append(containerLabels, config.Labels)
+	}
+	return FromDockerLabels(containerLabels...)
+}
+
+// Intersect returns the policy allowing the traffic allowed by both the policy of the
+// instance and the one of the task. The rules of the instance policy are evaluated first:
+// the traffic it denies is denied whatever the task policy, and the traffic it allows is
+// subject to the task policy. Either policy may be nil.
+func Intersect(instance, task *EgressPolicy) *EgressPolicy {
+	if instance == nil {
+		return task
+	}
+	if task == nil {
+		return instance
+	}
+	policy := &EgressPolicy{}
+	for _, rule := range instance.Rules {
+		if rule.Action == ActionDeny {
+			policy.Rules = append(policy.Rules, rule)
+			continue
+		}
+		// The traffic allowed by the rule is subject to the task policy, the last rule
+		// catches the traffic matching none of the task rules
+		for _, taskRule := range task.Rules {
+			if intersection, ok := rule.intersect(taskRule); ok {
+				intersection.Action = taskRule.Action
+				policy.Rules = append(policy.Rules, intersection)
+			}
+		}
+		rule.Action = task.DefaultAction
+		policy.Rules = append(policy.Rules, rule)
+	}
+	if instance.DefaultAction == ActionDeny {
+		policy.DefaultAction = ActionDeny
+		return policy
+	}
+	policy.Rules = append(policy.Rules, task.Rules...)
+	policy.DefaultAction = task.DefaultAction
+	return policy
+}
+
+// Validate returns an error if the policy cannot be enforced.
+func (p *EgressPolicy) Validate() error {
+	if p.DefaultAction != ActionAllow && p.DefaultAction != ActionDeny {
+		return fmt.Errorf("invalid default action %q", p.DefaultAction)
+	}
+	for i, rule := range p.Rules {
+		if err := rule.validate(); err != nil {
+			return errors.Wrapf(err, "invalid egress policy rule %d", i)
+		}
+	}
+	return nil
+}
+
+// HasIPv6Rules returns true if any rule of the policy matches IPv6 destinations.
+func (p *EgressPolicy) HasIPv6Rules() bool {
+	for _, rule := range p.Rules {
+		if rule.isIPv6() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) validate() error {
+	if r.Action != ActionAllow && r.Action != ActionDeny {
+		return fmt.Errorf("invalid action %q", r.Action)
+	}
+	if _, _, err := net.ParseCIDR(r.CIDR); err != nil {
+		return fmt.Errorf("invalid CIDR %q", r.CIDR)
+	}
+	switch r.Protocol {
+	case "", ProtocolAll, ProtocolICMP:
+		if len(r.Ports) > 0 {
+			return fmt.Errorf("ports cannot be specified for protocol %q", r.protocol())
+		}
+	case ProtocolTCP, ProtocolUDP:
+		for _, port := range r.Ports {
+			if _, _, err := parsePortRange(port); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("invalid protocol %q", r.Protocol)
+	}
+	return nil
+}
+
+// protocol returns the protocol of the rule, which defaults to all protocols.
+func (r Rule) protocol() Protocol {
+	if r.Protocol == "" {
+		return ProtocolAll
+	}
+	return r.Protocol
+}
+
+// intersect returns the rule matching the traffic matched by both rules, and false if no
+// traffic is matched by both. The action of the returned rule is the one of r.
+func (r Rule) intersect(other Rule) (Rule, bool) {
+	cidr, ok := intersectCIDRs(r.CIDR, other.CIDR)
+	if !ok {
+		return Rule{}, false
+	}
+	protocol := r.protocol()
+	switch otherProtocol := other.protocol(); {
+	case protocol == ProtocolAll:
+		protocol = otherProtocol
+	case otherProtocol != ProtocolAll && otherProtocol != protocol:
+		return Rule{}, false
+	}
+	ports := r.Ports
+	if len(ports) == 0 {
+		ports = other.Ports
+	} else if len(other.Ports) > 0 {
+		ports = intersectPorts(r.Ports, other.Ports)
+		if len(ports) == 0 {
+			return Rule{}, false
+		}
+	}
+	return Rule{
+		Action:   r.Action,
+		CIDR:     cidr,
+		Protocol: protocol,
+		Ports:    ports,
+	}, true
+}
+
+// intersectCIDRs returns the narrower of two CIDRs if one contains the other, and false if
+// they don't overlap
+func intersectCIDRs(a, b string) (string, bool) {
+	_, aNet, errA := net.ParseCIDR(a)
+	_, bNet, errB := net.ParseCIDR(b)
+	if errA != nil || errB != nil || len(aNet.IP) != len(bNet.IP) {
+		return "", false
+	}
+	aOnes, _ := aNet.Mask.Size()
+	bOnes, _ := bNet.Mask.Size()
+	switch {
+	case aOnes <= bOnes && aNet.Contains(bNet.IP):
+		return bNet.String(), true
+	case bOnes <= aOnes && bNet.Contains(aNet.IP):
+		return aNet.String(), true
+	}
+	return "", false
+}
+
+// intersectPorts returns the port ranges contained in both lists of port ranges
+func intersectPorts(a, b []string) []string {
+	var ports []string
+	for _, aRange := range a {
+		aFrom, aTo, err := parsePortRange(aRange)
+		if err != nil {
+			continue
+		}
+		for _, bRange := range b {
+			bFrom, bTo, err := parsePortRange(bRange)
+			if err != nil {
+				continue
+			}
+			from, to := aFrom, aTo
+			if bFrom > from {
+				from = bFrom
+			}
+			if bTo < to {
+				to = bTo
+			}
+			switch {
+			case from > to:
+				continue
+			case from == to:
+				ports = append(ports, strconv.Itoa(int(from)))
+			default:
+				ports = append(ports, fmt.Sprintf("%d-%d", from, to))
+			}
+		}
+	}
+	return ports
+}
+
+func (r Rule) isIPv6() bool {
+	ip, _, err := net.ParseCIDR(r.CIDR)
+	return err == nil && ip.To4() == nil
+}
+
+// parsePortRange parses a port, or a range of ports in the form of "<from>-<to>".
+func parsePortRange(portRange string) (uint16, uint16, error) {
+	from, to, isRange := strings.Cut(portRange, "-")
+	fromPort, err := parsePort(from)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q", portRange)
+	}
+	if !isRange {
+		return fromPort, fromPort, nil
+	}
+	toPort, err := parsePort(to)
+	if err != nil || toPort < fromPort {
+		return 0, 0, fmt.Errorf("invalid port range %q", portRange)
+	}
+	return fromPort, toPort, nil
+}
+
+func parsePort(port string) (uint16, error) {
+	p, err := strconv.Atoi(strings.TrimSpace(port))
+	if err != nil || p < minPort || p > maxPort {
+		return 0, fmt.Errorf("invalid port %q", port)
+	}
+	return uint16(p), nil
+}
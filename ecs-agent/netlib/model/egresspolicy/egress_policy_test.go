@@ -0,0 +1,182 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package egresspolicy
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	policy, err := Parse(`{
+		"DefaultAction": "DENY",
+		"Rules": [
+			{"Action": "ALLOW", "CIDR": "10.0.0.0/16", "Protocol": "tcp", "Ports": ["443", "8000-8080"]},
+			{"Action": "ALLOW", "CIDR": "2600:1f14::/56"}
+		]
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, ActionDeny, policy.DefaultAction)
+	require.Len(t, policy.Rules, 2)
+	assert.Equal(t, []string{"443", "8000-8080"}, policy.Rules[0].Ports)
+	assert.True(t, policy.HasIPv6Rules())
+
+	policy, err = Parse(`{"Rules": [{"Action": "DENY", "CIDR": "169.254.169.254/32"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, ActionAllow, policy.DefaultAction)
+	assert.False(t, policy.HasIPv6Rules())
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy string
+	}{
+		{name: "invalid json", policy: `{"DefaultAction":`},
+		{name: "invalid default action", policy: `{"DefaultAction": "DROP"}`},
+		{name: "invalid rule action", policy: `{"Rules": [{"Action": "DROP", "CIDR": "10.0.0.0/8"}]}`},
+		{name: "invalid cidr", policy: `{"Rules": [{"Action": "DENY", "CIDR": "10.0.0.1"}]}`},
+		{name: "invalid protocol", policy: `{"Rules": [{"Action": "DENY", "CIDR": "10.0.0.0/8", "Protocol": "sctp"}]}`},
+		{name: "ports without protocol", policy: `{"Rules": [{"Action": "DENY", "CIDR": "10.0.0.0/8", "Ports": ["80"]}]}`},
+		{name: "invalid port", policy: `{"Rules": [{"Action": "DENY", "CIDR": "10.0.0.0/8", "Protocol": "tcp", "Ports": ["70000"]}]}`},
+		{name: "invalid port range", policy: `{"Rules": [{"Action": "DENY", "CIDR": "10.0.0.0/8", "Protocol": "udp", "Ports": ["90-80"]}]}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.policy)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFromDockerLabels(t *testing.T) {
+	policyJSON := `{"DefaultAction": "DENY"}`
+
+	policy, err := FromDockerLabels(map[string]string{"foo": "bar"}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	policy, err = FromDockerLabels(map[string]string{DockerLabel: policyJSON}, nil, map[string]string{DockerLabel: policyJSON})
+	require.NoError(t, err)
+	assert.Equal(t, &EgressPolicy{DefaultAction: ActionDeny}, policy)
+
+	_, err = FromDockerLabels(map[string]string{DockerLabel: policyJSON}, map[string]string{DockerLabel: `{}`})
+	assert.Error(t, err)
+}
+
+func TestFromContainerConfigs(t *testing.T) {
+	policy, err := FromContainerConfigs("", `{"Labels": {"com.amazonaws.ecs.egress-policy": "{\"DefaultAction\": \"DENY\"}"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, &EgressPolicy{DefaultAction: ActionDeny}, policy)
+
+	_, err = FromContainerConfigs(`{"Labels":`)
+	assert.Error(t, err)
+}
+
+// evaluate returns the action of the policy for traffic to ip with protocol and port, the
+// first matching rule wins
+func evaluate(t *testing.T, policy *EgressPolicy, ip string, protocol Protocol, port uint16) Action {
+	for _, rule := range policy.Rules {
+		_, cidr, err := net.ParseCIDR(rule.CIDR)
+		require.NoError(t, err)
+		if !cidr.Contains(net.ParseIP(ip)) {
+			continue
+		}
+		if rule.protocol() != ProtocolAll && rule.protocol() != protocol {
+			continue
+		}
+		portMatches := len(rule.Ports) == 0
+		for _, portRange := range rule.Ports {
+			from, to, err := parsePortRange(portRange)
+			require.NoError(t, err)
+			if port >= from && port <= to {
+				portMatches = true
+			}
+		}
+		if portMatches {
+			return rule.Action
+		}
+	}
+	return policy.DefaultAction
+}
+
+func TestIntersect(t *testing.T) {
+	instance, err := Parse(`{
+		"DefaultAction": "DENY",
+		"Rules": [
+			{"Action": "DENY", "CIDR": "10.0.5.0/24"},
+			{"Action": "ALLOW", "CIDR": "10.0.0.0/16", "Protocol": "tcp", "Ports": ["443", "8000-8080"]},
+			{"Action": "ALLOW", "CIDR": "192.168.1.0/24"}
+		]
+	}`)
+	require.NoError(t, err)
+	tasks := []string{
+		`{"DefaultAction": "ALLOW"}`,
+		`{"DefaultAction": "DENY"}`,
+		`{"DefaultAction": "ALLOW", "Rules": [{"Action": "DENY", "CIDR": "10.0.1.0/24", "Protocol": "tcp", "Ports": ["8050-9000"]}]}`,
+		`{"DefaultAction": "DENY", "Rules": [
+			{"Action": "ALLOW", "CIDR": "10.0.0.0/8", "Protocol": "tcp", "Ports": ["443"]},
+			{"Action": "ALLOW", "CIDR": "192.168.1.128/25", "Protocol": "udp"},
+			{"Action": "ALLOW", "CIDR": "0.0.0.0/0", "Protocol": "icmp"}
+		]}`,
+	}
+	ips := []string{"10.0.1.5", "10.0.5.5", "10.1.0.1", "192.168.1.5", "192.168.1.200", "8.8.8.8"}
+	protocols := []Protocol{ProtocolTCP, ProtocolUDP, ProtocolICMP}
+	ports := []uint16{22, 443, 8000, 8060, 9000}
+
+	for _, taskPolicy := range tasks {
+		task, err := Parse(taskPolicy)
+		require.NoError(t, err)
+		intersection := Intersect(instance, task)
+		require.NoError(t, intersection.Validate())
+		for _, ip := range ips {
+			for _, protocol := range protocols {
+				for _, port := range ports {
+					expected := ActionDeny
+					if evaluate(t, instance, ip, protocol, port) == ActionAllow &&
+						evaluate(t, task, ip, protocol, port) == ActionAllow {
+						expected = ActionAllow
+					}
+					assert.Equal(t, expected, evaluate(t, intersection, ip, protocol, port),
+						"task policy %s, traffic to %s %s %d", taskPolicy, ip, protocol, port)
+				}
+			}
+		}
+	}
+}
+
+func TestIntersectCannotWidenInstancePolicy(t *testing.T) {
+	instance, err := Parse(`{"DefaultAction": "DENY", "Rules": [{"Action": "ALLOW", "CIDR": "10.0.0.0/16"}]}`)
+	require.NoError(t, err)
+	task, err := Parse(`{"DefaultAction": "ALLOW"}`)
+	require.NoError(t, err)
+
+	policy := Intersect(instance, task)
+	assert.Equal(t, ActionDeny, policy.DefaultAction)
+	assert.Equal(t, ActionDeny, evaluate(t, policy, "8.8.8.8", ProtocolTCP, 443))
+	assert.Equal(t, ActionAllow, evaluate(t, policy, "10.0.1.1", ProtocolTCP, 443))
+}
+
+func TestIntersectNil(t *testing.T) {
+	policy := &EgressPolicy{DefaultAction: ActionDeny}
+	assert.Equal(t, policy, Intersect(policy, nil))
+	assert.Equal(t, policy, Intersect(nil, policy))
+	assert.Nil(t, Intersect(nil, nil))
+}
@@ -0,0 +1,199 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package egresspolicy
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Chain is the iptables chain holding the rules of the egress policy inside the task
+	// network namespace. It is inserted into the built-in OUTPUT chain of the filter table.
+	Chain = "ECS-EGRESS-POLICY"
+
+	iptablesCmd         = "iptables"
+	ip6tablesCmd        = "ip6tables"
+	nsenterCmd          = "nsenter"
+	outputChain         = "OUTPUT"
+	returnTarget        = "RETURN"
+	rejectTarget        = "REJECT"
+	icmpv6Protocol      = "ipv6-icmp"
+	commandTimeout      = 10 * time.Second
+	xtablesWaitSeconds  = "5"
+	taskMetadataIPv4    = "169.254.170.2/32"
+	loopbackInterface   = "lo"
+	establishedCTStates = "ESTABLISHED,RELATED"
+)
+
+// Enforcer enforces egress policies inside network namespaces.
+type Enforcer interface {
+	// Apply replaces the egress policy enforced inside the network namespace with the given
+	// one. IPv6 traffic is only subject to the policy if ipv6 is true.
+	Apply(ctx context.Context, netNSPath string, policy *EgressPolicy, ipv6 bool) error
+	// Remove stops enforcing the egress policy inside the network namespace.
+	Remove(ctx context.Context, netNSPath string, ipv6 bool) error
+}
+
+// iptablesEnforcer enforces egress policies with iptables rules that are set up inside
+// the network namespace through nsenter.
+type iptablesEnforcer struct {
+	exec execwrapper.Exec
+}
+
+// NewEnforcer creates an Enforcer backed by iptables.
+func NewEnforcer(exec execwrapper.Exec) Enforcer {
+	return &iptablesEnforcer{
+		exec: exec,
+	}
+}
+
+// Apply sets up a dedicated chain holding the rules of the policy. Loopback traffic, replies
+// to established connections and requests to the task metadata endpoint are always allowed,
+// so that the task keeps access to its credentials and metadata.
+func (e *iptablesEnforcer) Apply(ctx context.Context, netNSPath string, policy *EgressPolicy, ipv6 bool) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	logger.Info("Applying egress policy", logger.Fields{
+		"NetNSPath":     netNSPath,
+		"DefaultAction": policy.DefaultAction,
+		"RuleCount":     len(policy.Rules),
+	})
+
+	// Remove the chain left over by a previous application of a policy, if any.
+	e.Remove(ctx, netNSPath, ipv6)
+
+	if err := e.applyRules(ctx, netNSPath, iptablesCmd, policy, false); err != nil {
+		return err
+	}
+	if ipv6 {
+		return e.applyRules(ctx, netNSPath, ip6tablesCmd, policy, true)
+	}
+	return nil
+}
+
+func (e *iptablesEnforcer) applyRules(
+	ctx context.Context,
+	netNSPath, iptables string,
+	policy *EgressPolicy,
+	ipv6 bool,
+) error {
+	commands := [][]string{
+		{"-N", Chain},
+		{"-A", Chain, "-o", loopbackInterface, "-j", returnTarget},
+		{"-A", Chain, "-m", "conntrack", "--ctstate", establishedCTStates, "-j", returnTarget},
+	}
+	if !ipv6 {
+		commands = append(commands, []string{"-A", Chain, "-d", taskMetadataIPv4, "-j", returnTarget})
+	}
+	for _, rule := range policy.Rules {
+		if rule.isIPv6() != ipv6 {
+			continue
+		}
+		commands = append(commands, ruleArgs(rule, ipv6)...)
+	}
+	if policy.DefaultAction == ActionDeny {
+		commands = append(commands, []string{"-A", Chain, "-j", rejectTarget})
+	}
+	commands = append(commands, []string{"-I", outputChain, "-j", Chain})
+
+	for _, args := range commands {
+		if err := e.run(ctx, netNSPath, iptables, args...); err != nil {
+			return errors.Wrap(err, "failed to apply egress policy")
+		}
+	}
+	return nil
+}
+
+// ruleArgs returns the arguments of the iptables commands appending the rule to the chain.
+// A rule with several ports translates into a command per port.
+func ruleArgs(rule Rule, ipv6 bool) [][]string {
+	target := returnTarget
+	if rule.Action == ActionDeny {
+		target = rejectTarget
+	}
+	args := []string{"-A", Chain, "-d", rule.CIDR}
+	protocol := rule.protocol()
+	switch {
+	case protocol == ProtocolICMP && ipv6:
+		args = append(args, "-p", icmpv6Protocol)
+	case protocol != ProtocolAll:
+		args = append(args, "-p", string(protocol))
+	}
+	if len(rule.Ports) == 0 {
+		return [][]string{append(args, "-j", target)}
+	}
+
+	var commands [][]string
+	for _, portRange := range rule.Ports {
+		from, to, _ := parsePortRange(portRange)
+		dport := strconv.Itoa(int(from))
+		if to != from {
+			dport = fmt.Sprintf("%d:%d", from, to)
+		}
+		command := append(append([]string{}, args...), "--dport", dport, "-j", target)
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// Remove deletes the chain of the egress policy. It is a no-op if no policy is applied.
+func (e *iptablesEnforcer) Remove(ctx context.Context, netNSPath string, ipv6 bool) error {
+	iptablesCmds := []string{iptablesCmd}
+	if ipv6 {
+		iptablesCmds = append(iptablesCmds, ip6tablesCmd)
+	}
+	var errs []error
+	for _, iptables := range iptablesCmds {
+		// The chain doesn't exist if no policy is applied.
+		if err := e.run(ctx, netNSPath, iptables, "-L", Chain, "-n"); err != nil {
+			continue
+		}
+		for _, args := range [][]string{
+			{"-D", outputChain, "-j", Chain},
+			{"-F", Chain},
+			{"-X", Chain},
+		} {
+			if err := e.run(ctx, netNSPath, iptables, args...); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Wrap(goerrors.Join(errs...), "failed to remove egress policy")
+	}
+	return nil
+}
+
+// run executes an iptables command inside the network namespace.
+func (e *iptablesEnforcer) run(ctx context.Context, netNSPath, iptables string, args ...string) error {
+	ctx, cancel := e.exec.NewExecContextWithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmdArgs := append([]string{"--net=" + netNSPath, iptables, "-w", xtablesWaitSeconds}, args...)
+	out, err := e.exec.CommandContext(ctx, nsenterCmd, cmdArgs...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %v: %w: %s", iptables, args, err, string(out))
+	}
+	return nil
+}
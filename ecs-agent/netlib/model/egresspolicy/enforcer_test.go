@@ -0,0 +1,127 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package egresspolicy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNetNSPath = "/var/run/netns/task"
+
+// recordCommands makes the mock exec record the commands run, which fail if fail returns true.
+func recordCommands(ctrl *gomock.Controller, exec *mock_execwrapper.MockExec, fail func(string) bool) *[]string {
+	var commands []string
+	exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), commandTimeout).
+		DoAndReturn(func(ctx context.Context, _ interface{}) (context.Context, context.CancelFunc) {
+			return context.WithCancel(ctx)
+		}).AnyTimes()
+	exec.EXPECT().CommandContext(gomock.Any(), nsenterCmd, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, args ...string) *mock_execwrapper.MockCmd {
+			command := strings.Join(args, " ")
+			commands = append(commands, command)
+			cmd := mock_execwrapper.NewMockCmd(ctrl)
+			if fail(command) {
+				cmd.EXPECT().CombinedOutput().Return([]byte("failed"), errors.New("exit status 1"))
+			} else {
+				cmd.EXPECT().CombinedOutput().Return(nil, nil)
+			}
+			return cmd
+		}).AnyTimes()
+	return &commands
+}
+
+func TestEnforcerApply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	// The chain doesn't exist yet.
+	commands := recordCommands(ctrl, exec, func(command string) bool {
+		return strings.Contains(command, "-L "+Chain)
+	})
+
+	policy := &EgressPolicy{
+		DefaultAction: ActionDeny,
+		Rules: []Rule{
+			{Action: ActionAllow, CIDR: "10.0.0.0/16", Protocol: ProtocolTCP, Ports: []string{"443", "8000-8080"}},
+			{Action: ActionDeny, CIDR: "2600:1f14::/56", Protocol: ProtocolICMP},
+		},
+	}
+	require.NoError(t, NewEnforcer(exec).Apply(context.TODO(), testNetNSPath, policy, true))
+
+	prefix := "--net=" + testNetNSPath + " "
+	assert.Equal(t, []string{
+		prefix + "iptables -w 5 -L ECS-EGRESS-POLICY -n",
+		prefix + "ip6tables -w 5 -L ECS-EGRESS-POLICY -n",
+		prefix + "iptables -w 5 -N ECS-EGRESS-POLICY",
+		prefix + "iptables -w 5 -A ECS-EGRESS-POLICY -o lo -j RETURN",
+		prefix + "iptables -w 5 -A ECS-EGRESS-POLICY -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		prefix + "iptables -w 5 -A ECS-EGRESS-POLICY -d 169.254.170.2/32 -j RETURN",
+		prefix + "iptables -w 5 -A ECS-EGRESS-POLICY -d 10.0.0.0/16 -p tcp --dport 443 -j RETURN",
+		prefix + "iptables -w 5 -A ECS-EGRESS-POLICY -d 10.0.0.0/16 -p tcp --dport 8000:8080 -j RETURN",
+		prefix + "iptables -w 5 -A ECS-EGRESS-POLICY -j REJECT",
+		prefix + "iptables -w 5 -I OUTPUT -j ECS-EGRESS-POLICY",
+		prefix + "ip6tables -w 5 -N ECS-EGRESS-POLICY",
+		prefix + "ip6tables -w 5 -A ECS-EGRESS-POLICY -o lo -j RETURN",
+		prefix + "ip6tables -w 5 -A ECS-EGRESS-POLICY -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+		prefix + "ip6tables -w 5 -A ECS-EGRESS-POLICY -d 2600:1f14::/56 -p ipv6-icmp -j REJECT",
+		prefix + "ip6tables -w 5 -A ECS-EGRESS-POLICY -j REJECT",
+		prefix + "ip6tables -w 5 -I OUTPUT -j ECS-EGRESS-POLICY",
+	}, *commands)
+}
+
+func TestEnforcerApplyFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	recordCommands(ctrl, exec, func(command string) bool {
+		return strings.Contains(command, "-L "+Chain) || strings.Contains(command, "-N "+Chain)
+	})
+	err := NewEnforcer(exec).Apply(context.TODO(), testNetNSPath, &EgressPolicy{DefaultAction: ActionAllow}, false)
+	assert.Error(t, err)
+
+	// Invalid policies are not applied.
+	err = NewEnforcer(exec).Apply(context.TODO(), testNetNSPath, &EgressPolicy{}, false)
+	assert.Error(t, err)
+}
+
+func TestEnforcerRemove(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	commands := recordCommands(ctrl, exec, func(command string) bool { return false })
+	require.NoError(t, NewEnforcer(exec).Remove(context.TODO(), testNetNSPath, false))
+
+	prefix := "--net=" + testNetNSPath + " "
+	assert.Equal(t, []string{
+		prefix + "iptables -w 5 -L ECS-EGRESS-POLICY -n",
+		prefix + "iptables -w 5 -D OUTPUT -j ECS-EGRESS-POLICY",
+		prefix + "iptables -w 5 -F ECS-EGRESS-POLICY",
+		prefix + "iptables -w 5 -X ECS-EGRESS-POLICY",
+	}, *commands)
+}
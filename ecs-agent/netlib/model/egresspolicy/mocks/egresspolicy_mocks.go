@@ -0,0 +1,78 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy (interfaces: Enforcer)
+
+// Package mock_egresspolicy is a generated GoMock package.
+package mock_egresspolicy
+
+import (
+	context "context"
+	reflect "reflect"
+
+	egresspolicy "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEnforcer is a mock of Enforcer interface.
+type MockEnforcer struct {
+	ctrl     *gomock.Controller
+	recorder *MockEnforcerMockRecorder
+}
+
+// MockEnforcerMockRecorder is the mock recorder for MockEnforcer.
+type MockEnforcerMockRecorder struct {
+	mock *MockEnforcer
+}
+
+// NewMockEnforcer creates a new mock instance.
+func NewMockEnforcer(ctrl *gomock.Controller) *MockEnforcer {
+	mock := &MockEnforcer{ctrl: ctrl}
+	mock.recorder = &MockEnforcerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEnforcer) EXPECT() *MockEnforcerMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockEnforcer) Apply(arg0 context.Context, arg1 string, arg2 *egresspolicy.EgressPolicy, arg3 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockEnforcerMockRecorder) Apply(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockEnforcer)(nil).Apply), arg0, arg1, arg2, arg3)
+}
+
+// Remove mocks base method.
+func (m *MockEnforcer) Remove(arg0 context.Context, arg1 string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockEnforcerMockRecorder) Remove(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockEnforcer)(nil).Remove), arg0, arg1, arg2)
+}
@@ -0,0 +1,105 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tasknetworkconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	// PortMappingProtocolTCP and PortMappingProtocolUDP are the protocols of port mappings.
+	PortMappingProtocolTCP = "tcp"
+	PortMappingProtocolUDP = "udp"
+)
+
+// BridgeConfig is the model of the connection between the network namespace of a task
+// running in bridge network mode and the bridge on the host.
+type BridgeConfig struct {
+	TaskID string `json:"TaskID"`
+	// BridgeName is the name of the bridge on the host the netns is connected to.
+	BridgeName string `json:"BridgeName"`
+	// IPV4Subnet is the subnet from which the netns is assigned an address.
+	IPV4Subnet string `json:"IPV4Subnet"`
+	// IPV4Address is the address assigned to the netns, in CIDR notation. It is set
+	// once the netns is connected to the bridge.
+	IPV4Address string `json:"IPV4Address,omitempty"`
+	// PortMappings are the ports of the host forwarded to the netns.
+	PortMappings []PortMapping `json:"PortMappings,omitempty"`
+
+	KnownStatus   status.NetworkStatus `json:"KnownStatus"`
+	DesiredStatus status.NetworkStatus `json:"DesiredStatus"`
+}
+
+// NewBridgeConfig creates the bridge configuration of a task that is yet to be connected
+// to the bridge.
+func NewBridgeConfig(taskID, bridgeName, ipv4Subnet string) *BridgeConfig {
+	return &BridgeConfig{
+		TaskID:        taskID,
+		BridgeName:    bridgeName,
+		IPV4Subnet:    ipv4Subnet,
+		KnownStatus:   status.NetworkNone,
+		DesiredStatus: status.NetworkReadyPull,
+	}
+}
+
+// PortMapping forwards a port of the host to a port of the network namespace of a task in
+// bridge network mode.
+type PortMapping struct {
+	ContainerPort uint16 `json:"ContainerPort"`
+	HostPort      uint16 `json:"HostPort"`
+	// Protocol is either PortMappingProtocolTCP or PortMappingProtocolUDP.
+	Protocol string `json:"Protocol"`
+}
+
+// PortMappingsFromACS returns the port mappings of the containers of a task. A port mapping
+// without a host port forwards the container port of the host. Container port ranges aren't
+// supported.
+func PortMappingsFromACS(containers []*ecsacs.Container) ([]PortMapping, error) {
+	var portMappings []PortMapping
+	for _, container := range containers {
+		for _, portMapping := range container.PortMappings {
+			if aws.ToString(portMapping.ContainerPortRange) != "" {
+				return nil, fmt.Errorf("container port ranges are not supported in bridge network mode: %s",
+					aws.ToString(portMapping.ContainerPortRange))
+			}
+			containerPort := aws.ToInt64(portMapping.ContainerPort)
+			hostPort := aws.ToInt64(portMapping.HostPort)
+			if hostPort == 0 {
+				hostPort = containerPort
+			}
+			if containerPort <= 0 || containerPort > 65535 || hostPort < 0 || hostPort > 65535 {
+				return nil, fmt.Errorf("invalid port mapping %d:%d", hostPort, containerPort)
+			}
+			protocol := strings.ToLower(aws.ToString(portMapping.Protocol))
+			if protocol == "" {
+				protocol = PortMappingProtocolTCP
+			}
+			if protocol != PortMappingProtocolTCP && protocol != PortMappingProtocolUDP {
+				return nil, fmt.Errorf("invalid port mapping protocol: %s", protocol)
+			}
+			portMappings = append(portMappings, PortMapping{
+				ContainerPort: uint16(containerPort),
+				HostPort:      uint16(hostPort),
+				Protocol:      protocol,
+			})
+		}
+	}
+	return portMappings, nil
+}
@@ -0,0 +1,64 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tasknetworkconfig
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortMappingsFromACS(t *testing.T) {
+	portMappings, err := PortMappingsFromACS([]*ecsacs.Container{
+		{
+			PortMappings: []*ecsacs.PortMapping{
+				{ContainerPort: aws.Int64(80), HostPort: aws.Int64(8080), Protocol: aws.String("TCP")},
+			},
+		},
+		{},
+		{
+			PortMappings: []*ecsacs.PortMapping{
+				{ContainerPort: aws.Int64(53), Protocol: aws.String("udp")},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []PortMapping{
+		{ContainerPort: 80, HostPort: 8080, Protocol: PortMappingProtocolTCP},
+		{ContainerPort: 53, HostPort: 53, Protocol: PortMappingProtocolUDP},
+	}, portMappings)
+}
+
+func TestPortMappingsFromACSInvalid(t *testing.T) {
+	for name, portMapping := range map[string]*ecsacs.PortMapping{
+		"port range":       {ContainerPortRange: aws.String("8000-8080")},
+		"no port":          {HostPort: aws.Int64(80)},
+		"invalid port":     {ContainerPort: aws.Int64(70000)},
+		"invalid protocol": {ContainerPort: aws.Int64(80), Protocol: aws.String("sctp")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := PortMappingsFromACS([]*ecsacs.Container{
+				{PortMappings: []*ecsacs.PortMapping{portMapping}},
+			})
+			assert.Error(t, err)
+		})
+	}
+}
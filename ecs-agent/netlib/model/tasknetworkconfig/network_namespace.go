@@ -19,6 +19,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
@@ -39,6 +40,12 @@ type NetworkNamespace struct {
 	// ServiceConnectConfig holds ServiceConnect related parameters for the particular netns.
 	ServiceConnectConfig *serviceconnect.ServiceConnectConfig
 
+	// EgressPolicy restricts the destinations the task can connect to from within the netns.
+	EgressPolicy *egresspolicy.EgressPolicy
+
+	// BridgeConfig holds the bridge related parameters of the netns for tasks in bridge network mode.
+	BridgeConfig *BridgeConfig
+
 	KnownState   status.NetworkStatus
 	DesiredState status.NetworkStatus
 
@@ -100,6 +100,8 @@ func (nb *networkBuilder) Start(
 	switch mode {
 	case types.NetworkModeAwsvpc:
 		err = nb.startAWSVPC(ctx, taskID, netNS)
+	case types.NetworkModeBridge:
+		err = nb.startBridge(ctx, netNS)
 	case types.NetworkModeHost:
 		err = nb.platformAPI.HandleHostMode()
 	default:
@@ -130,6 +132,8 @@ func (nb *networkBuilder) Stop(ctx context.Context, mode types.NetworkMode, task
 	switch mode {
 	case types.NetworkModeAwsvpc:
 		err = nb.stopAWSVPC(ctx, netNS)
+	case types.NetworkModeBridge:
+		err = nb.stopBridge(ctx, taskID, netNS)
 	case types.NetworkModeHost:
 		err = nb.platformAPI.HandleHostMode()
 	default:
@@ -200,6 +204,18 @@ func (nb *networkBuilder) startAWSVPC(ctx context.Context, taskID string, netNS
 				return errors.Wrapf(err, "failed to configure ServiceConnect in netns %s", netNS.Name)
 			}
 		}
+
+		if netNS.EgressPolicy != nil {
+			logger.Debug("Configuring egress policy", logger.Fields{
+				"EgressPolicy": netNS.EgressPolicy,
+			})
+
+			err = nb.platformAPI.ConfigureEgressPolicy(
+				ctx, netNS.Path, netNS.GetPrimaryInterface(), netNS.EgressPolicy)
+			if err != nil {
+				return errors.Wrapf(err, "failed to configure egress policy in netns %s", netNS.Name)
+			}
+		}
 	}
 
 	return err
@@ -264,6 +280,14 @@ func (nb *networkBuilder) stopAWSVPC(ctx context.Context, netNS *tasknetworkconf
 		errs = multierror.Append(err, errs)
 	}
 
+	if netNS.EgressPolicy != nil {
+		err = nb.platformAPI.DeleteEgressPolicy(ctx, netNS.Path, netNS.GetPrimaryInterface(), netNS.EgressPolicy)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to delete egress policy: %v", err), logFields)
+			errs = multierror.Append(err, errs)
+		}
+	}
+
 	err = nb.platformAPI.DeleteDNSConfig(netNS.Name)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to cleanup DNS config files: %v", err))
@@ -278,3 +302,92 @@ func (nb *networkBuilder) stopAWSVPC(ctx context.Context, netNS *tasknetworkconf
 
 	return errs
 }
+
+// startBridge executes the required platform API methods in order to configure
+// the task's network namespace running in bridge mode.
+func (nb *networkBuilder) startBridge(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	if netNS.DesiredState == status.NetworkDeleted {
+		return errors.New("invalid transition state encountered: " + netNS.DesiredState.String())
+	}
+	if netNS.BridgeConfig == nil {
+		return errors.New("bridge config not found in netns " + netNS.Name)
+	}
+
+	if netNS.KnownState == status.NetworkNone &&
+		netNS.DesiredState == status.NetworkReadyPull {
+		logger.Debug("Creating netns: " + netNS.Path)
+		// Create network namespace on the host.
+		if err := nb.platformAPI.CreateNetNS(netNS.Path); err != nil {
+			return err
+		}
+	}
+
+	return nb.configureNetNSBridge(ctx, netNS)
+}
+
+// configureNetNSBridge executes the platform API to connect the network namespace to the
+// bridge as per the netns desired state, and persists the resulting bridge config.
+func (nb *networkBuilder) configureNetNSBridge(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
+	bridgeConfig := netNS.BridgeConfig
+	logFields := logger.Fields{
+		"BridgeName":    bridgeConfig.BridgeName,
+		"NetNSName":     netNS.Name,
+		"KnownStatus":   bridgeConfig.KnownStatus,
+		"DesiredStatus": bridgeConfig.DesiredStatus,
+	}
+	if bridgeConfig.KnownStatus == netNS.DesiredState {
+		logger.Debug("Bridge config already in desired state", logFields)
+		return nil
+	}
+
+	// The bridge config desired status is driven by the network namespace's desired state.
+	logger.Debug("Configuring bridge", logFields)
+	bridgeConfig.DesiredStatus = netNS.DesiredState
+
+	if err := nb.platformAPI.ConfigureBridge(ctx, netNS.Path, bridgeConfig); err != nil {
+		return err
+	}
+	bridgeConfig.KnownStatus = netNS.DesiredState
+
+	// Save new state of the bridge config in the database.
+	return nb.networkDAO.SaveBridgeConfig(bridgeConfig)
+}
+
+func (nb *networkBuilder) stopBridge(ctx context.Context, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error {
+	var errs error
+	if netNS.DesiredState != status.NetworkDeleted {
+		return errors.New("invalid transition state encountered: " + netNS.DesiredState.String())
+	}
+	if netNS.BridgeConfig == nil {
+		return errors.New("bridge config not found in netns " + netNS.Name)
+	}
+
+	logFields := logger.Fields{
+		"NetNSName": netNS.Name,
+	}
+	bridgeConfig := netNS.BridgeConfig
+	if bridgeConfig.KnownStatus != status.NetworkDeleted {
+		bridgeConfig.DesiredStatus = status.NetworkDeleted
+		err := nb.platformAPI.ConfigureBridge(ctx, netNS.Path, bridgeConfig)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to disconnect netns from bridge: %v", err), logFields)
+			errs = multierror.Append(err, errs)
+		} else {
+			bridgeConfig.KnownStatus = status.NetworkDeleted
+		}
+	}
+
+	err := nb.networkDAO.DeleteBridgeConfig(taskID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to delete bridge config: %v", err), logFields)
+		errs = multierror.Append(err, errs)
+	}
+
+	err = nb.platformAPI.DeleteNetNS(netNS.Path)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to delete network namespace: %v", err), logFields)
+		errs = multierror.Append(err, errs)
+	}
+
+	return errs
+}
@@ -19,6 +19,7 @@ package netlib
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"testing"
@@ -28,6 +29,8 @@ import (
 	mock_metrics "github.com/aws/amazon-ecs-agent/ecs-agent/metrics/mocks"
 	mock_data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data/mocks"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
@@ -68,11 +71,13 @@ func TestNetworkBuilder_BuildTaskNetworkConfiguration(t *testing.T) {
 
 func TestNetworkBuilder_Start(t *testing.T) {
 	t.Run("awsvpc", testNetworkBuilder_StartAWSVPC)
+	t.Run("bridge", testNetworkBuilder_StartBridge)
 }
 
-// TestNetworkBuilder_Stop verifies stop workflow for AWSVPC mode.
+// TestNetworkBuilder_Stop verifies stop workflow for AWSVPC and bridge modes.
 func TestNetworkBuilder_Stop(t *testing.T) {
 	t.Run("awsvpc", testNetworkBuilder_StopAWSVPC)
+	t.Run("bridge", testNetworkBuilder_StopBridge)
 }
 
 // getTestFunc returns a test function that verifies the capability of the networkBuilder
@@ -227,6 +232,17 @@ func testNetworkBuilder_StartAWSVPC(t *testing.T) {
 		netBuilder.Start(ctx, types.NetworkModeAwsvpc, taskID, netNS)
 	})
 
+	// Single ENI with an egress policy and desired state = READY.
+	// The egress policy should be enforced along with ServiceConnect.
+	netNS.EgressPolicy = &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionDeny}
+	mockEntry = mock_metrics.NewMockEntry(ctrl)
+	t.Run("single-eni-egress-policy-ready", func(*testing.T) {
+		gomock.InOrder(
+			getExpectedCalls_StartAWSVPC(ctx, platformAPI, metricsFactory, mockEntry, netDao, netNS)...,
+		)
+		netBuilder.Start(ctx, types.NetworkModeAwsvpc, taskID, netNS)
+	})
+
 	// Single netns with multi interface case.
 	_, taskNetConfig = getSingleNetNSMultiIfaceAWSVPCTestData(taskID)
 	netNS = taskNetConfig.GetPrimaryNetNS()
@@ -308,6 +324,10 @@ func getExpectedCalls_StartAWSVPC(
 			calls = append(calls, platformAPI.EXPECT().ConfigureServiceConnect(ctx, netNS.Path,
 				netNS.GetPrimaryInterface(), netNS.ServiceConnectConfig).Return(nil).Times(1))
 		}
+		if netNS.EgressPolicy != nil {
+			calls = append(calls, platformAPI.EXPECT().ConfigureEgressPolicy(ctx, netNS.Path,
+				netNS.GetPrimaryInterface(), netNS.EgressPolicy).Return(nil).Times(1))
+		}
 	}
 
 	calls = append(calls, mockEntry.EXPECT().Done(nil).Times(1))
@@ -340,6 +360,20 @@ func testNetworkBuilder_StopAWSVPC(t *testing.T) {
 		)
 		netBuilder.Stop(ctx, types.NetworkModeAwsvpc, taskID, netNS)
 	})
+	// The egress policy is removed before the netns is deleted.
+	netDao := mock_data.NewMockNetworkDataClient(ctrl)
+	netBuilder.networkDAO = netDao
+	netNS.EgressPolicy = &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionDeny}
+	netNS.DesiredState = status.NetworkDeleted
+	mockEntry = mock_metrics.NewMockEntry(ctrl)
+	t.Run("egress-policy", func(t *testing.T) {
+		netDao.EXPECT().SaveNetworkNamespace(netNS).Return(nil).AnyTimes()
+		gomock.InOrder(
+			append(getExpectedCalls_StopAWSVPC(ctx, platformAPI, metricsFactory, mockEntry, netNS),
+				mockEntry.EXPECT().Done(nil).Times(1))...,
+		)
+		require.NoError(t, netBuilder.Stop(ctx, types.NetworkModeAwsvpc, taskID, netNS))
+	})
 }
 
 // getExpectedCalls_StopAWSVPC returns a list of gomock calls that will be executed
@@ -374,7 +408,109 @@ func getExpectedCalls_StopAWSVPC(
 			platformAPI.EXPECT().ConfigureInterface(ctx, netNS.Path, iface, gomock.Any()).Return(nil).Times(1))
 	}
 
+	if netNS.EgressPolicy != nil {
+		calls = append(calls, platformAPI.EXPECT().DeleteEgressPolicy(ctx, netNS.Path,
+			netNS.GetPrimaryInterface(), netNS.EgressPolicy).Return(nil).Times(1))
+	}
+
 	return append(calls,
 		platformAPI.EXPECT().DeleteDNSConfig(netNS.Name).Return(nil).Times(1),
 		platformAPI.EXPECT().DeleteNetNS(netNS.Path).Return(nil).Times(1))
 }
+
+func getBridgeTestNetNS() *tasknetworkconfig.NetworkNamespace {
+	netNSName := networkinterface.NetNSName(taskID, "bridge")
+	netNS, _ := tasknetworkconfig.NewNetworkNamespace(netNSName, "/var/run/netns/"+netNSName, 0, nil)
+	netNS.BridgeConfig = tasknetworkconfig.NewBridgeConfig(taskID, platform.TaskBridgeName, platform.DefaultTaskBridgeSubnet)
+	return netNS
+}
+
+// testNetworkBuilder_StartBridge verifies that the network namespace of a bridge mode task
+// is created and connected to the bridge, and that the bridge config is persisted.
+func testNetworkBuilder_StartBridge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	platformAPI := mock_platform.NewMockAPI(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+	mockEntry := mock_metrics.NewMockEntry(ctrl)
+	netDao := mock_data.NewMockNetworkDataClient(ctrl)
+	netBuilder := &networkBuilder{
+		platformAPI:    platformAPI,
+		metricsFactory: metricsFactory,
+		networkDAO:     netDao,
+	}
+
+	netNS := getBridgeTestNetNS()
+	gomock.InOrder(
+		metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry).Times(1),
+		mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry).Times(1),
+		platformAPI.EXPECT().CreateNetNS(netNS.Path).Return(nil).Times(1),
+		platformAPI.EXPECT().ConfigureBridge(ctx, netNS.Path, netNS.BridgeConfig).Return(nil).Times(1),
+		netDao.EXPECT().SaveBridgeConfig(netNS.BridgeConfig).Return(nil).Times(1),
+		mockEntry.EXPECT().Done(nil).Times(1),
+	)
+	require.NoError(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS))
+	require.Equal(t, status.NetworkReadyPull, netNS.BridgeConfig.KnownStatus)
+
+	// The READY_PULL -> READY transition doesn't create the netns again.
+	netNS.KnownState = status.NetworkReadyPull
+	netNS.DesiredState = status.NetworkReady
+	mockEntry = mock_metrics.NewMockEntry(ctrl)
+	gomock.InOrder(
+		metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry).Times(1),
+		mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry).Times(1),
+		platformAPI.EXPECT().ConfigureBridge(ctx, netNS.Path, netNS.BridgeConfig).Return(nil).Times(1),
+		netDao.EXPECT().SaveBridgeConfig(netNS.BridgeConfig).Return(nil).Times(1),
+		mockEntry.EXPECT().Done(nil).Times(1),
+	)
+	require.NoError(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS))
+	require.Equal(t, status.NetworkReady, netNS.BridgeConfig.KnownStatus)
+
+	// A netns without bridge config cannot be started in bridge mode.
+	netNS.BridgeConfig = nil
+	mockEntry = mock_metrics.NewMockEntry(ctrl)
+	gomock.InOrder(
+		metricsFactory.EXPECT().New(metrics.BuildNetworkNamespaceMetricName).Return(mockEntry).Times(1),
+		mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry).Times(1),
+		mockEntry.EXPECT().Done(gomock.Any()).Times(1),
+	)
+	require.Error(t, netBuilder.Start(ctx, types.NetworkModeBridge, taskID, netNS))
+}
+
+// testNetworkBuilder_StopBridge verifies that the network namespace of a bridge mode task
+// is disconnected from the bridge and deleted, along with its persisted bridge config.
+func testNetworkBuilder_StopBridge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	platformAPI := mock_platform.NewMockAPI(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+	mockEntry := mock_metrics.NewMockEntry(ctrl)
+	netDao := mock_data.NewMockNetworkDataClient(ctrl)
+	netBuilder := &networkBuilder{
+		platformAPI:    platformAPI,
+		metricsFactory: metricsFactory,
+		networkDAO:     netDao,
+	}
+
+	netNS := getBridgeTestNetNS()
+	netNS.BridgeConfig.KnownStatus = status.NetworkReady
+	netNS.KnownState = status.NetworkReady
+	netNS.DesiredState = status.NetworkDeleted
+	gomock.InOrder(
+		metricsFactory.EXPECT().New(metrics.DeleteNetworkNamespaceMetricName).Return(mockEntry).Times(1),
+		mockEntry.EXPECT().WithFields(gomock.Any()).Return(mockEntry).Times(1),
+		platformAPI.EXPECT().ConfigureBridge(ctx, netNS.Path, netNS.BridgeConfig).
+			Return(errors.New("bridge error")).Times(1),
+		netDao.EXPECT().DeleteBridgeConfig(taskID).Return(nil).Times(1),
+		platformAPI.EXPECT().DeleteNetNS(netNS.Path).Return(nil).Times(1),
+		mockEntry.EXPECT().Done(gomock.Any()).Times(1),
+	)
+	// Cleanup continues past failures, which are returned.
+	require.Error(t, netBuilder.Stop(ctx, types.NetworkModeBridge, taskID, netNS))
+	require.Equal(t, status.NetworkDeleted, netNS.BridgeConfig.DesiredStatus)
+	require.Equal(t, status.NetworkReady, netNS.BridgeConfig.KnownStatus)
+}
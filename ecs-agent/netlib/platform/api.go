@@ -20,6 +20,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
@@ -78,6 +79,32 @@ type API interface {
 		primaryIf *networkinterface.NetworkInterface,
 		scConfig *serviceconnect.ServiceConnectConfig,
 	) error
+
+	// ConfigureEgressPolicy enforces the egress policy inside the task network namespace.
+	// IPv6 traffic is subject to the policy if the primary interface has IPv6 addresses
+	// or the policy has IPv6 rules.
+	ConfigureEgressPolicy(
+		ctx context.Context,
+		netNSPath string,
+		primaryIf *networkinterface.NetworkInterface,
+		policy *egresspolicy.EgressPolicy,
+	) error
+
+	// DeleteEgressPolicy stops enforcing the egress policy inside the task network namespace.
+	DeleteEgressPolicy(
+		ctx context.Context,
+		netNSPath string,
+		primaryIf *networkinterface.NetworkInterface,
+		policy *egresspolicy.EgressPolicy,
+	) error
+
+	// ConfigureBridge connects the network namespace of a task in bridge network mode to the
+	// bridge on the host, or disconnects it, as per the desired status of the bridge config.
+	ConfigureBridge(
+		ctx context.Context,
+		netNSPath string,
+		bridgeConfig *tasknetworkconfig.BridgeConfig,
+	) error
 }
 
 // Config contains platform-specific data.
@@ -87,4 +114,13 @@ type Config struct {
 	// ResolvConfPath specifies path to resolv.conf file for DNS config.
 	// Different platforms may have different paths for this file.
 	ResolvConfPath string
+	// EgressPolicy is the egress policy enforced for awsvpc tasks that don't specify their own
+	// through the egresspolicy.DockerLabel docker label.
+	EgressPolicy *egresspolicy.EgressPolicy
+	// TaskBridgeSubnet is the IPv4 subnet from which the network namespaces of tasks in bridge
+	// network mode are assigned their address. DefaultTaskBridgeSubnet is used when it's empty.
+	TaskBridgeSubnet string
+	// CNIPluginsPath is the directory of the CNI plugin binaries. CNIPluginPathDefault is used
+	// when it's empty.
+	CNIPluginsPath string
 }
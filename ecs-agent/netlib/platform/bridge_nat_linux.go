@@ -0,0 +1,171 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	iptablesCmd             = "iptables"
+	iptablesCommandTimeout  = 10 * time.Second
+	iptablesWaitSeconds     = "5"
+	natTable                = "nat"
+	filterTable             = "filter"
+	postroutingChain        = "POSTROUTING"
+	preroutingChain         = "PREROUTING"
+	outputChain             = "OUTPUT"
+	forwardChain            = "FORWARD"
+	loopbackSubnet          = "127.0.0.0/8"
+	taskBridgeNATRuleMarker = "ecs-task-bridge"
+)
+
+// iptablesRule is a rule of the host iptables set up for a task connected to the task bridge.
+type iptablesRule struct {
+	table string
+	chain string
+	// insert is whether the rule is inserted at the top of the chain instead of appended to
+	// it, so that it applies ahead of the rules of other bridges, such as docker's.
+	insert bool
+	spec   []string
+}
+
+// taskBridgeNATRules returns the host iptables rules that masquerade the traffic leaving the
+// task bridge from the address of the task, let it be forwarded through the host, and forward
+// the mapped ports of the host to the task.
+func taskBridgeNATRules(bridgeConfig *tasknetworkconfig.BridgeConfig) ([]iptablesRule, error) {
+	ip, _, err := net.ParseCIDR(bridgeConfig.IPV4Address)
+	if err != nil || ip.To4() == nil {
+		return nil, fmt.Errorf("invalid task bridge address: %s", bridgeConfig.IPV4Address)
+	}
+	taskIP := ip.String()
+	taskHost := taskIP + "/32"
+	comment := []string{"-m", "comment", "--comment", taskBridgeNATRuleMarker + ":" + bridgeConfig.TaskID}
+
+	withComment := func(spec ...string) []string {
+		return append(append([]string{}, spec...), comment...)
+	}
+	rules := []iptablesRule{
+		{
+			table: natTable,
+			chain: postroutingChain,
+			spec:  withComment("-s", taskHost, "!", "-o", bridgeConfig.BridgeName, "-j", "MASQUERADE"),
+		},
+		{
+			table:  filterTable,
+			chain:  forwardChain,
+			insert: true,
+			spec:   withComment("-s", taskHost, "-i", bridgeConfig.BridgeName, "-j", "ACCEPT"),
+		},
+		{
+			table:  filterTable,
+			chain:  forwardChain,
+			insert: true,
+			spec:   withComment("-d", taskHost, "-o", bridgeConfig.BridgeName, "-j", "ACCEPT"),
+		},
+	}
+	for _, portMapping := range bridgeConfig.PortMappings {
+		dnat := []string{
+			"-p", portMapping.Protocol,
+			"-m", "addrtype", "--dst-type", "LOCAL",
+			"--dport", strconv.Itoa(int(portMapping.HostPort)),
+			"-j", "DNAT",
+			"--to-destination", net.JoinHostPort(taskIP, strconv.Itoa(int(portMapping.ContainerPort))),
+		}
+		rules = append(rules,
+			iptablesRule{
+				table: natTable,
+				chain: preroutingChain,
+				spec:  withComment(dnat...),
+			},
+			// Connections to the mapped ports from the host itself don't go through the
+			// PREROUTING chain. The loopback addresses aren't routable to the bridge.
+			iptablesRule{
+				table: natTable,
+				chain: outputChain,
+				spec:  withComment(append([]string{"!", "-d", loopbackSubnet}, dnat...)...),
+			},
+		)
+	}
+	return rules, nil
+}
+
+// configureTaskBridgeNAT sets up the host iptables rules of a task connected to the task
+// bridge when add is true, and removes them otherwise. Both are idempotent.
+func (c *common) configureTaskBridgeNAT(
+	ctx context.Context,
+	bridgeConfig *tasknetworkconfig.BridgeConfig,
+	add bool,
+) error {
+	rules, err := taskBridgeNATRules(bridgeConfig)
+	if err != nil {
+		return err
+	}
+	logger.Info("Configuring task bridge NAT", map[string]interface{}{
+		"TaskID":           bridgeConfig.TaskID,
+		"IPV4Address":      bridgeConfig.IPV4Address,
+		"PortMappingCount": len(bridgeConfig.PortMappings),
+		"Add":              add,
+	})
+
+	var errs []error
+	for _, rule := range rules {
+		// The rule is checked first, so that it isn't added twice nor deleted when missing.
+		exists := c.runIPTables(ctx, append([]string{"-t", rule.table, "-C", rule.chain}, rule.spec...)...) == nil
+		var args []string
+		switch {
+		case add && !exists && rule.insert:
+			args = []string{"-t", rule.table, "-I", rule.chain}
+		case add && !exists:
+			args = []string{"-t", rule.table, "-A", rule.chain}
+		case !add && exists:
+			args = []string{"-t", rule.table, "-D", rule.chain}
+		default:
+			continue
+		}
+		if err := c.runIPTables(ctx, append(args, rule.spec...)...); err != nil {
+			if add {
+				return errors.Wrap(err, "failed to configure task bridge NAT")
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Wrap(goerrors.Join(errs...), "failed to remove task bridge NAT")
+	}
+	return nil
+}
+
+// runIPTables executes an iptables command in the host network namespace.
+func (c *common) runIPTables(ctx context.Context, args ...string) error {
+	ctx, cancel := c.exec.NewExecContextWithTimeout(ctx, iptablesCommandTimeout)
+	defer cancel()
+
+	cmdArgs := append([]string{"-w", iptablesWaitSeconds}, args...)
+	out, err := c.exec.CommandContext(ctx, iptablesCmd, cmdArgs...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %v: %w: %s", iptablesCmd, args, err, string(out))
+	}
+	return nil
+}
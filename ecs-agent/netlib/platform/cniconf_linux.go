@@ -26,6 +26,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 
 	"github.com/containernetworking/cni/pkg/types"
 )
@@ -55,6 +56,15 @@ const (
 
 	BridgeInterfaceName = "fargate-bridge"
 
+	// TaskBridgeName is the name of the bridge on the host that connects the network
+	// namespaces of tasks in bridge network mode.
+	TaskBridgeName = "ecs-task-bridge"
+	// DefaultTaskBridgeSubnet is the subnet from which the network namespaces of tasks in
+	// bridge network mode are assigned their address, unless the platform config sets another.
+	DefaultTaskBridgeSubnet = "172.30.0.0/16"
+	// defaultRouteDst is the destination of the default route of tasks in bridge network mode.
+	defaultRouteDst = "0.0.0.0/0"
+
 	IPAMDataFileName = "eni-ipam.db"
 
 	// Timeout duration for each network setup and cleanup operation before it is cancelled.
@@ -121,6 +131,40 @@ func createBridgePluginConfig(netNSPath string) ecscni.PluginConfig {
 	return bridgeConfig
 }
 
+// createTaskBridgePluginConfig constructs the configuration object for the bridge plugin to
+// connect the network namespace of a task in bridge network mode to the task bridge. Traffic
+// is routed through the bridge, whose address is the default gateway assigned by ipam.
+func createTaskBridgePluginConfig(netNSPath string, bridgeConfig *tasknetworkconfig.BridgeConfig) ecscni.PluginConfig {
+	cniConfig := ecscni.CNIConfig{
+		NetNSPath:      netNSPath,
+		CNISpecVersion: cniSpecVersion,
+		CNIPluginName:  BridgePluginName,
+	}
+
+	_, routeIPNet, _ := net.ParseCIDR(defaultRouteDst)
+	route := &types.Route{
+		Dst: *routeIPNet,
+	}
+
+	ipamConfig := &ecscni.IPAMConfig{
+		CNIConfig: ecscni.CNIConfig{
+			NetNSPath:      netNSPath,
+			CNISpecVersion: cniSpecVersion,
+			CNIPluginName:  IPAMPluginName,
+		},
+		IPV4Subnet:  bridgeConfig.IPV4Subnet,
+		IPV4Address: bridgeConfig.IPV4Address,
+		IPV4Routes:  []*types.Route{route},
+		ID:          netNSPath,
+	}
+
+	return &ecscni.BridgeConfig{
+		CNIConfig: cniConfig,
+		Name:      bridgeConfig.BridgeName,
+		IPAM:      *ipamConfig,
+	}
+}
+
 func createAppMeshPluginConfig(
 	netNSPath string,
 	cfg *appmesh.AppMesh,
@@ -31,6 +31,9 @@ const (
 	FirecrackerPlatform      = "firecracker"
 	ManagedPlatform          = "managed-instance"
 	ManagedDebugPlatform     = "ec2-debug-managed-instance"
+	// EC2Platform is the platform of the ECS agent running tasks with docker on EC2
+	// container instances.
+	EC2Platform = "ec2"
 )
 
 // executeCNIPlugin executes CNI plugins with the given network configs and a timeout context.
@@ -19,7 +19,9 @@ package platform
 import (
 	"bytes"
 	"context"
+	goerrors "errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
@@ -30,10 +32,12 @@ import (
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ioutilwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netlinkwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netwrapper"
@@ -65,6 +69,10 @@ const (
 	// It is assigned 100 because it is an unrealistically high
 	// value for interface index.
 	indexHighValue = 100
+
+	// bridgeNetNSSuffix is appended to the task ID to name the network namespace of
+	// tasks in bridge network mode.
+	bridgeNetNSSuffix = "bridge"
 )
 
 // common will be embedded within every implementation of the platform API.
@@ -80,6 +88,13 @@ type common struct {
 	cniClient         ecscni.CNI
 	net               netwrapper.Net
 	resolvConfPath    string
+	// egressPolicy is the egress policy enforced for tasks that don't specify their own.
+	egressPolicy         *egresspolicy.EgressPolicy
+	egressPolicyEnforcer egresspolicy.Enforcer
+	// taskBridgeSubnet is the subnet from which tasks in bridge network mode are assigned
+	// their address.
+	taskBridgeSubnet string
+	exec             execwrapper.Exec
 }
 
 // NewPlatform creates an implementation of the platform API depending on the
@@ -90,16 +105,33 @@ func NewPlatform(
 	stateDBDirectory string,
 	netWrapper netwrapper.Net,
 ) (API, error) {
+	taskBridgeSubnet := platformConfig.TaskBridgeSubnet
+	if taskBridgeSubnet == "" {
+		taskBridgeSubnet = DefaultTaskBridgeSubnet
+	}
+	if ip, _, err := net.ParseCIDR(taskBridgeSubnet); err != nil || ip.To4() == nil {
+		return nil, errors.New("invalid task bridge subnet: " + taskBridgeSubnet)
+	}
+	cniPluginsPath := platformConfig.CNIPluginsPath
+	if cniPluginsPath == "" {
+		cniPluginsPath = CNIPluginPathDefault
+	}
+
+	exec := execwrapper.NewExec()
 	commonPlatform := common{
-		nsUtil:            ecscni.NewNetNSUtil(),
-		dnsVolumeAccessor: volumeAccessor,
-		os:                oswrapper.NewOS(),
-		ioutil:            ioutilwrapper.NewIOUtil(),
-		netlink:           netlinkwrapper.New(),
-		stateDBDir:        stateDBDirectory,
-		cniClient:         ecscni.NewCNIClient([]string{CNIPluginPathDefault}),
-		net:               netWrapper,
-		resolvConfPath:    platformConfig.ResolvConfPath,
+		nsUtil:               ecscni.NewNetNSUtil(),
+		dnsVolumeAccessor:    volumeAccessor,
+		os:                   oswrapper.NewOS(),
+		ioutil:               ioutilwrapper.NewIOUtil(),
+		netlink:              netlinkwrapper.New(),
+		stateDBDir:           stateDBDirectory,
+		cniClient:            ecscni.NewCNIClient([]string{cniPluginsPath}),
+		net:                  netWrapper,
+		resolvConfPath:       platformConfig.ResolvConfPath,
+		egressPolicy:         platformConfig.EgressPolicy,
+		egressPolicyEnforcer: egresspolicy.NewEnforcer(exec),
+		taskBridgeSubnet:     taskBridgeSubnet,
+		exec:                 exec,
 	}
 
 	switch platformConfig.Name {
@@ -107,6 +139,12 @@ func NewPlatform(
 		return &containerd{
 			common: commonPlatform,
 		}, nil
+	case EC2Platform:
+		return &ec2Docker{
+			containerd: containerd{
+				common: commonPlatform,
+			},
+		}, nil
 	case FirecrackerPlatform:
 		return &firecraker{
 			common: commonPlatform,
@@ -153,8 +191,14 @@ func (c *common) buildTaskNetworkConfiguration(
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to translate network configuration")
 		}
+		if err = c.setEgressPolicy(taskPayload, netNSs); err != nil {
+			return nil, err
+		}
 	case types.NetworkModeBridge:
-		return nil, errors.New("not implemented")
+		netNSs, err = c.buildBridgeNetworkNamespaces(taskID, taskPayload)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to translate network configuration")
+		}
 	case types.NetworkModeHost:
 		return nil, errors.New("not implemented")
 	case types.NetworkModeNone:
@@ -322,6 +366,57 @@ func (c *common) buildNetNS(
 		ifaces...)
 }
 
+// setEgressPolicy sets the egress policy of the task, or the default one of the platform if
+// the task doesn't specify its own, on each of the task network namespaces.
+func (c *common) setEgressPolicy(taskPayload *ecsacs.Task, netNSs []*tasknetworkconfig.NetworkNamespace) error {
+	var containerConfigs []string
+	for _, container := range taskPayload.Containers {
+		if container.DockerConfig != nil {
+			containerConfigs = append(containerConfigs, aws.ToString(container.DockerConfig.Config))
+		}
+	}
+	policy, err := egresspolicy.FromContainerConfigs(containerConfigs...)
+	if err != nil {
+		return errors.Wrap(err, "failed to translate egress policy")
+	}
+	if policy == nil {
+		policy = c.egressPolicy
+	}
+	for _, netNS := range netNSs {
+		netNS.EgressPolicy = policy
+	}
+	return nil
+}
+
+// buildBridgeNetworkNamespaces returns the network namespace of a task in bridge network mode,
+// which is connected to the task bridge on the host instead of having network interfaces of its own.
+// The ports mapped by the containers of the task are forwarded from the host to the netns.
+func (c *common) buildBridgeNetworkNamespaces(
+	taskID string,
+	taskPayload *ecsacs.Task,
+) ([]*tasknetworkconfig.NetworkNamespace, error) {
+	portMappings, err := tasknetworkconfig.PortMappingsFromACS(taskPayload.Containers)
+	if err != nil {
+		return nil, err
+	}
+
+	netNSName := networkinterface.NetNSName(taskID, bridgeNetNSSuffix)
+	netNSPath := c.GetNetNSPath(netNSName)
+
+	logger.Info("Building network namespace model for bridge task", map[string]interface{}{
+		"NetNSName": netNSName,
+		"NetNSPath": netNSPath,
+	})
+	netNS, err := tasknetworkconfig.NewNetworkNamespace(netNSName, netNSPath, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	netNS.BridgeConfig = tasknetworkconfig.NewBridgeConfig(taskID, TaskBridgeName, c.taskBridgeSubnet)
+	netNS.BridgeConfig.PortMappings = portMappings
+
+	return []*tasknetworkconfig.NetworkNamespace{netNS}, nil
+}
+
 // CreateNetNS creates a new network namespace with the specified path.
 func (c *common) CreateNetNS(netNSPath string) error {
 	logger.Info("Creating network namespace", map[string]interface{}{
@@ -850,3 +945,107 @@ func (c *common) configureServiceConnect(
 
 	return nil
 }
+
+// configureEgressPolicy enforces the egress policy inside the task network namespace.
+func (c *common) configureEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	logger.Info("Configuring egress policy", map[string]interface{}{
+		"NetNSPath": netNSPath,
+	})
+
+	err := c.egressPolicyEnforcer.Apply(ctx, netNSPath, policy, egressPolicyIPv6Enabled(primaryIf, policy))
+	if err != nil {
+		return errors.Wrapf(err, "failed to configure egress policy in netns %s", netNSPath)
+	}
+	return nil
+}
+
+// deleteEgressPolicy stops enforcing the egress policy inside the task network namespace.
+func (c *common) deleteEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	logger.Info("Deleting egress policy", map[string]interface{}{
+		"NetNSPath": netNSPath,
+	})
+
+	return c.egressPolicyEnforcer.Remove(ctx, netNSPath, egressPolicyIPv6Enabled(primaryIf, policy))
+}
+
+// egressPolicyIPv6Enabled returns true if IPv6 traffic needs to be subject to the egress policy.
+func egressPolicyIPv6Enabled(primaryIf *networkinterface.NetworkInterface, policy *egresspolicy.EgressPolicy) bool {
+	return (primaryIf != nil && len(primaryIf.IPV6Addresses) > 0) || policy.HasIPv6Rules()
+}
+
+// configureBridge connects the network namespace of a task in bridge network mode to the
+// task bridge and sets up its NAT on the host when the bridge config is desired to be
+// READY_PULL, and removes the NAT, disconnects it and releases its address when it is desired
+// to be DELETED. No further setup is required for the READY state.
+func (c *common) configureBridge(
+	ctx context.Context,
+	netNSPath string,
+	bridgeConfig *tasknetworkconfig.BridgeConfig,
+) error {
+	logger.Info("Configuring task bridge", map[string]interface{}{
+		"BridgeName":    bridgeConfig.BridgeName,
+		"NetNSPath":     netNSPath,
+		"DesiredStatus": bridgeConfig.DesiredStatus,
+	})
+
+	var add bool
+	switch bridgeConfig.DesiredStatus {
+	case status.NetworkReadyPull:
+		add = true
+	case status.NetworkReady:
+		return nil
+	case status.NetworkDeleted:
+		add = false
+	default:
+		return errors.New("invalid bridge config desired status " + bridgeConfig.DesiredStatus.String())
+	}
+
+	c.os.Setenv(CNIPluginLogFileEnv, ecscni.PluginLogPath)
+	c.os.Setenv(IPAMDataPathEnv, filepath.Join(c.stateDBDir, IPAMDataFileName))
+
+	cniNetConf := createTaskBridgePluginConfig(netNSPath, bridgeConfig)
+	if !add {
+		// The NAT rules are set up once the address is assigned.
+		var errs []error
+		if bridgeConfig.IPV4Address != "" {
+			if err := c.configureTaskBridgeNAT(ctx, bridgeConfig, false); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if _, err := c.executeCNIPlugin(ctx, false, cniNetConf); err != nil {
+			errs = append(errs, errors.Wrapf(err,
+				"failed to configure task bridge netconfig %s", cniNetConf.String()))
+		}
+		return goerrors.Join(errs...)
+	}
+
+	result, err := c.executeCNIPlugin(ctx, true, cniNetConf)
+	if err != nil {
+		return errors.Wrapf(err, "failed to configure task bridge netconfig %s", cniNetConf.String())
+	}
+
+	// Record the address assigned by ipam, so that it can be released when the task stops.
+	if len(result) == 0 {
+		return errors.New("task bridge configuration: empty result from network setup")
+	}
+	newResult, err := cnitypes.GetResult(*result[0])
+	if err != nil {
+		return err
+	}
+	if len(newResult.IPs) == 0 {
+		return errors.New("task bridge configuration: no ip address assigned")
+	}
+	bridgeConfig.IPV4Address = newResult.IPs[0].Address.String()
+
+	return c.configureTaskBridgeNAT(ctx, bridgeConfig, true)
+}
@@ -24,21 +24,28 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	mock_data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data/mocks"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
 	mock_ecscni2 "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni/mocks_ecscni"
 	mock_ecscni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni/mocks_nsutil"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
+	mock_egresspolicy "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy/mocks"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
 	mock_ioutilwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/ioutilwrapper/mocks"
 	mock_netlinkwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/netlinkwrapper/mocks"
 	mock_netwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/netwrapper/mocks"
 	mock_oswrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/oswrapper/mocks"
 	mock_volume "github.com/aws/amazon-ecs-agent/ecs-agent/volume/mocks"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	currentCNITypes "github.com/containernetworking/cni/pkg/types/100"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -50,8 +57,17 @@ func TestNewPlatform(t *testing.T) {
 	_, err := NewPlatform(Config{Name: WarmpoolPlatform}, nil, "", nil)
 	assert.NoError(t, err)
 
+	ec2Platform, err := NewPlatform(Config{Name: EC2Platform, TaskBridgeSubnet: "10.200.0.0/16"}, nil, "", nil)
+	assert.NoError(t, err)
+	// The network namespaces of the pause containers are managed by docker.
+	assert.NoError(t, ec2Platform.CreateNetNS("/proc/1234/ns/net"))
+	assert.NoError(t, ec2Platform.DeleteNetNS("/proc/1234/ns/net"))
+
 	_, err = NewPlatform(Config{Name: "invalid-platform"}, nil, "", nil)
 	assert.Error(t, err)
+
+	_, err = NewPlatform(Config{Name: EC2Platform, TaskBridgeSubnet: "fd00::/64"}, nil, "", nil)
+	assert.Error(t, err)
 }
 
 // TestCommon_CreateNetNS verifies the precise set of operations are executed
@@ -445,3 +461,241 @@ func testGeneveInterfaceConfiguration(t *testing.T) {
 	err = commonPlatform.configureInterface(ctx, netNSPath, v2nIface, netDAO)
 	require.NoError(t, err)
 }
+
+// TestCommon_BuildBridgeNetworkConfiguration verifies that the network namespace of a task
+// in bridge network mode is connected to the task bridge.
+func TestCommon_BuildBridgeNetworkConfiguration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	taskID := "task-id"
+	nsUtil := mock_ecscni.NewMockNetNSUtil(ctrl)
+	commonPlatform := &common{
+		nsUtil:           nsUtil,
+		taskBridgeSubnet: "10.200.0.0/16",
+	}
+
+	expectedNetNSName := networkinterface.NetNSName(taskID, bridgeNetNSSuffix)
+	nsUtil.EXPECT().GetNetNSPath(expectedNetNSName).Return(netNSPath).Times(1)
+	taskNetConfig, err := commonPlatform.buildTaskNetworkConfiguration(taskID, &ecsacs.Task{
+		NetworkMode: aws.String(string(types.NetworkModeBridge)),
+		Containers: []*ecsacs.Container{
+			{
+				PortMappings: []*ecsacs.PortMapping{
+					{ContainerPort: aws.Int64(80), HostPort: aws.Int64(8080)},
+					{ContainerPort: aws.Int64(53), Protocol: aws.String("udp")},
+				},
+			},
+		},
+	}, false, nil)
+	require.NoError(t, err)
+	require.Len(t, taskNetConfig.NetworkNamespaces, 1)
+
+	netNS := taskNetConfig.GetPrimaryNetNS()
+	require.NotNil(t, netNS)
+	assert.Equal(t, expectedNetNSName, netNS.Name)
+	assert.Equal(t, netNSPath, netNS.Path)
+	assert.Empty(t, netNS.NetworkInterfaces)
+	expectedBridgeConfig := tasknetworkconfig.NewBridgeConfig(taskID, TaskBridgeName, "10.200.0.0/16")
+	expectedBridgeConfig.PortMappings = []tasknetworkconfig.PortMapping{
+		{ContainerPort: 80, HostPort: 8080, Protocol: "tcp"},
+		{ContainerPort: 53, HostPort: 53, Protocol: "udp"},
+	}
+	assert.Equal(t, expectedBridgeConfig, netNS.BridgeConfig)
+}
+
+// recordIPTablesCommands makes the mock exec record the iptables commands run. The rule
+// checks fail if exists returns false.
+func recordIPTablesCommands(ctrl *gomock.Controller, exec *mock_execwrapper.MockExec, exists func() bool) *[]string {
+	var commands []string
+	exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), iptablesCommandTimeout).
+		DoAndReturn(func(ctx context.Context, _ interface{}) (context.Context, context.CancelFunc) {
+			return context.WithCancel(ctx)
+		}).AnyTimes()
+	exec.EXPECT().CommandContext(gomock.Any(), iptablesCmd, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, args ...string) *mock_execwrapper.MockCmd {
+			command := strings.Join(args, " ")
+			commands = append(commands, command)
+			cmd := mock_execwrapper.NewMockCmd(ctrl)
+			if strings.Contains(command, " -C ") && !exists() {
+				cmd.EXPECT().CombinedOutput().Return([]byte("Bad rule"), errors.New("exit status 1"))
+			} else {
+				cmd.EXPECT().CombinedOutput().Return(nil, nil)
+			}
+			return cmd
+		}).AnyTimes()
+	return &commands
+}
+
+// TestCommon_ConfigureBridge verifies that the bridge plugin is invoked to connect the netns
+// to the task bridge, and to disconnect it along with releasing the assigned address.
+func TestCommon_ConfigureBridge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	taskID := "task-id"
+	osWrapper := mock_oswrapper.NewMockOS(ctrl)
+	cniClient := mock_ecscni2.NewMockCNI(ctrl)
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	commonPlatform := &common{
+		os:         osWrapper,
+		cniClient:  cniClient,
+		stateDBDir: "dummy-db-dir",
+		exec:       exec,
+	}
+	bridgeConfig := tasknetworkconfig.NewBridgeConfig(taskID, TaskBridgeName, DefaultTaskBridgeSubnet)
+	bridgeConfig.PortMappings = []tasknetworkconfig.PortMapping{
+		{ContainerPort: 80, HostPort: 8080, Protocol: "tcp"},
+	}
+	rulesExist := false
+	commands := recordIPTablesCommands(ctrl, exec, func() bool { return rulesExist })
+
+	ip, assignedIP, _ := net.ParseCIDR("172.30.0.2/16")
+	assignedIP.IP = ip
+	cniResult := &currentCNITypes.Result{
+		IPs: []*currentCNITypes.IPConfig{
+			{
+				Address: *assignedIP,
+			},
+		},
+	}
+	gomock.InOrder(
+		osWrapper.EXPECT().Setenv("ECS_CNI_LOG_FILE", ecscni.PluginLogPath).Times(1),
+		osWrapper.EXPECT().Setenv("IPAM_DB_PATH", filepath.Join(commonPlatform.stateDBDir, "eni-ipam.db")),
+		cniClient.EXPECT().Add(gomock.Any(), createTaskBridgePluginConfig(netNSPath, bridgeConfig)).
+			Return(cniResult, nil).Times(1),
+	)
+	err := commonPlatform.configureBridge(ctx, netNSPath, bridgeConfig)
+	require.NoError(t, err)
+	assert.Equal(t, assignedIP.String(), bridgeConfig.IPV4Address)
+
+	// The traffic of the task is masqueraded, forwarded, and the mapped port is forwarded to it.
+	comment := " -m comment --comment ecs-task-bridge:" + taskID
+	expectedRules := []string{
+		"-t nat %s POSTROUTING -s 172.30.0.2/32 ! -o ecs-task-bridge -j MASQUERADE" + comment,
+		"-t filter %s FORWARD -s 172.30.0.2/32 -i ecs-task-bridge -j ACCEPT" + comment,
+		"-t filter %s FORWARD -d 172.30.0.2/32 -o ecs-task-bridge -j ACCEPT" + comment,
+		"-t nat %s PREROUTING -p tcp -m addrtype --dst-type LOCAL --dport 8080 -j DNAT --to-destination 172.30.0.2:80" + comment,
+		"-t nat %s OUTPUT ! -d 127.0.0.0/8 -p tcp -m addrtype --dst-type LOCAL --dport 8080 -j DNAT --to-destination 172.30.0.2:80" + comment,
+	}
+	var expectedCommands []string
+	for i, rule := range expectedRules {
+		action := "-A"
+		if i == 1 || i == 2 {
+			action = "-I"
+		}
+		expectedCommands = append(expectedCommands, "-w 5 "+fmt.Sprintf(rule, "-C"), "-w 5 "+fmt.Sprintf(rule, action))
+	}
+	assert.Equal(t, expectedCommands, *commands)
+	rulesExist = true
+	*commands = nil
+
+	// No plugin is invoked for the READY state.
+	bridgeConfig.DesiredStatus = status.NetworkReady
+	err = commonPlatform.configureBridge(ctx, netNSPath, bridgeConfig)
+	require.NoError(t, err)
+
+	// Delete workflow releases the assigned address.
+	bridgeConfig.DesiredStatus = status.NetworkDeleted
+	gomock.InOrder(
+		osWrapper.EXPECT().Setenv("ECS_CNI_LOG_FILE", ecscni.PluginLogPath).Times(1),
+		osWrapper.EXPECT().Setenv("IPAM_DB_PATH", filepath.Join(commonPlatform.stateDBDir, "eni-ipam.db")),
+		cniClient.EXPECT().Del(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, cfg ecscni.PluginConfig) error {
+				assert.Equal(t, assignedIP.String(), cfg.(*ecscni.BridgeConfig).IPAM.IPV4Address)
+				return nil
+			}).Times(1),
+	)
+	err = commonPlatform.configureBridge(ctx, netNSPath, bridgeConfig)
+	require.NoError(t, err)
+
+	expectedCommands = nil
+	for _, rule := range expectedRules {
+		expectedCommands = append(expectedCommands, "-w 5 "+fmt.Sprintf(rule, "-C"), "-w 5 "+fmt.Sprintf(rule, "-D"))
+	}
+	assert.Equal(t, expectedCommands, *commands)
+}
+
+// TestCommon_SetEgressPolicy verifies that the egress policy specified by the docker labels
+// of the task overrides the default policy of the platform.
+func TestCommon_SetEgressPolicy(t *testing.T) {
+	defaultPolicy := &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionAllow}
+	commonPlatform := &common{
+		egressPolicy: defaultPolicy,
+	}
+
+	netNS := &tasknetworkconfig.NetworkNamespace{}
+	err := commonPlatform.setEgressPolicy(&ecsacs.Task{
+		Containers: []*ecsacs.Container{{}},
+	}, []*tasknetworkconfig.NetworkNamespace{netNS})
+	require.NoError(t, err)
+	assert.Equal(t, defaultPolicy, netNS.EgressPolicy)
+
+	containerConfig := `{"Labels":{"com.amazonaws.ecs.egress-policy":"{\"DefaultAction\":\"DENY\"}"}}`
+	err = commonPlatform.setEgressPolicy(&ecsacs.Task{
+		Containers: []*ecsacs.Container{
+			{
+				DockerConfig: &ecsacs.DockerConfig{
+					Config: aws.String(containerConfig),
+				},
+			},
+		},
+	}, []*tasknetworkconfig.NetworkNamespace{netNS})
+	require.NoError(t, err)
+	assert.Equal(t, &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionDeny}, netNS.EgressPolicy)
+
+	err = commonPlatform.setEgressPolicy(&ecsacs.Task{
+		Containers: []*ecsacs.Container{
+			{
+				DockerConfig: &ecsacs.DockerConfig{
+					Config: aws.String(`{"Labels":{"com.amazonaws.ecs.egress-policy":"invalid"}}`),
+				},
+			},
+		},
+	}, []*tasknetworkconfig.NetworkNamespace{netNS})
+	require.Error(t, err)
+}
+
+// TestCommon_ConfigureEgressPolicy verifies that IPv6 traffic is subject to the egress policy
+// only if the task has IPv6 connectivity or the policy has IPv6 rules.
+func TestCommon_ConfigureEgressPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	enforcer := mock_egresspolicy.NewMockEnforcer(ctrl)
+	commonPlatform := &common{
+		egressPolicyEnforcer: enforcer,
+	}
+	policy := &egresspolicy.EgressPolicy{DefaultAction: egresspolicy.ActionDeny}
+	ipv4Iface := &networkinterface.NetworkInterface{}
+	ipv6Iface := &networkinterface.NetworkInterface{
+		IPV6Addresses: []*networkinterface.IPV6Address{
+			{
+				Address: "2600:1f13:4d9:e611::1",
+			},
+		},
+	}
+
+	enforcer.EXPECT().Apply(ctx, netNSPath, policy, false).Return(nil).Times(1)
+	require.NoError(t, commonPlatform.configureEgressPolicy(ctx, netNSPath, ipv4Iface, policy))
+
+	enforcer.EXPECT().Apply(ctx, netNSPath, policy, true).Return(nil).Times(1)
+	require.NoError(t, commonPlatform.configureEgressPolicy(ctx, netNSPath, ipv6Iface, policy))
+
+	enforcer.EXPECT().Apply(ctx, netNSPath, policy, false).Return(errors.New("err")).Times(1)
+	require.Error(t, commonPlatform.configureEgressPolicy(ctx, netNSPath, ipv4Iface, policy))
+
+	ipv6Policy := &egresspolicy.EgressPolicy{
+		DefaultAction: egresspolicy.ActionAllow,
+		Rules: []egresspolicy.Rule{
+			{
+				Action: egresspolicy.ActionDeny,
+				CIDR:   "2600:1f13::/32",
+			},
+		},
+	}
+	enforcer.EXPECT().Remove(ctx, netNSPath, true).Return(nil).Times(1)
+	require.NoError(t, commonPlatform.deleteEgressPolicy(ctx, netNSPath, ipv4Iface, ipv6Policy))
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
@@ -62,3 +63,29 @@ func (c *containerd) ConfigureServiceConnect(
 ) error {
 	return c.common.configureServiceConnect(ctx, netNSPath, primaryIf, scConfig)
 }
+
+func (c *containerd) ConfigureEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	return c.common.configureEgressPolicy(ctx, netNSPath, primaryIf, policy)
+}
+
+func (c *containerd) DeleteEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	return c.common.deleteEgressPolicy(ctx, netNSPath, primaryIf, policy)
+}
+
+func (c *containerd) ConfigureBridge(
+	ctx context.Context,
+	netNSPath string,
+	bridgeConfig *tasknetworkconfig.BridgeConfig,
+) error {
+	return c.common.configureBridge(ctx, netNSPath, bridgeConfig)
+}
@@ -24,6 +24,7 @@ import (
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
@@ -241,6 +242,32 @@ func (c *containerd) ConfigureServiceConnect(
 	return errors.New("not implemented")
 }
 
+func (c *containerd) ConfigureEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	return errors.New("not implemented")
+}
+
+func (c *containerd) DeleteEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	return errors.New("not implemented")
+}
+
+func (c *containerd) ConfigureBridge(
+	ctx context.Context,
+	netNSPath string,
+	bridgeConfig *tasknetworkconfig.BridgeConfig,
+) error {
+	return errors.New("not implemented")
+}
+
 // configureRegularENI configures a network interface for an ENI.
 func (c *containerd) configureRegularENI(ctx context.Context, netNSID string, iface *networkinterface.NetworkInterface) error {
 	var cniNetConf []ecscni.PluginConfig
@@ -0,0 +1,31 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+// ec2Docker implements platform API methods for the ECS agent running tasks with docker.
+// The network namespace of a task is the one of its pause container, which docker creates
+// and deletes along with the container.
+type ec2Docker struct {
+	containerd
+}
+
+// CreateNetNS is a no-op, as docker creates the network namespace of the pause container.
+func (e *ec2Docker) CreateNetNS(netNSPath string) error {
+	return nil
+}
+
+// DeleteNetNS is a no-op, as docker deletes the network namespace of the pause container.
+func (e *ec2Docker) DeleteNetNS(netNSPath string) error {
+	return nil
+}
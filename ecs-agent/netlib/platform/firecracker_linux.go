@@ -23,6 +23,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
@@ -100,6 +101,32 @@ func (f *firecraker) ConfigureServiceConnect(
 	return errors.New("not implemented")
 }
 
+func (f *firecraker) ConfigureEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	return errors.New("not implemented")
+}
+
+func (f *firecraker) DeleteEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	return errors.New("not implemented")
+}
+
+func (f *firecraker) ConfigureBridge(
+	ctx context.Context,
+	netNSPath string,
+	bridgeConfig *tasknetworkconfig.BridgeConfig,
+) error {
+	return errors.New("not implemented")
+}
+
 // configureSecondaryDNSConfig creates DNS config files for secondary interfaces. This is required because
 // on FoF, secondary interfaces reside in their own network namespace inside the microVM. The DNS config
 // inside the namespace will need to be the secondary interface DNS config.
@@ -11,6 +11,7 @@ import (
 	loggerfield "github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
@@ -49,11 +50,19 @@ func (m *managedLinux) BuildTaskNetworkConfiguration(
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to translate network configuration")
 		}
+		if err = m.common.setEgressPolicy(taskPayload, netNSs); err != nil {
+			return nil, err
+		}
 	case types.NetworkModeHost:
 		netNSs, err = m.buildDefaultNetworkNamespace(taskID)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create network namespace with host eni")
 		}
+	case types.NetworkModeBridge:
+		netNSs, err = m.common.buildBridgeNetworkNamespaces(taskID, taskPayload)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to translate network configuration")
+		}
 	default:
 		return nil, errors.New("invalid network mode: " + string(mode))
 	}
@@ -92,6 +101,32 @@ func (m *managedLinux) ConfigureServiceConnect(
 	return m.common.configureServiceConnect(ctx, netNSPath, primaryIf, scConfig)
 }
 
+func (m *managedLinux) ConfigureEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	return m.common.configureEgressPolicy(ctx, netNSPath, primaryIf, policy)
+}
+
+func (m *managedLinux) DeleteEgressPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *egresspolicy.EgressPolicy,
+) error {
+	return m.common.deleteEgressPolicy(ctx, netNSPath, primaryIf, policy)
+}
+
+func (m *managedLinux) ConfigureBridge(
+	ctx context.Context,
+	netNSPath string,
+	bridgeConfig *tasknetworkconfig.BridgeConfig,
+) error {
+	return m.common.configureBridge(ctx, netNSPath, bridgeConfig)
+}
+
 // buildDefaultNetworkNamespace return default network namespace of host ENI for host mode.
 func (m *managedLinux) buildDefaultNetworkNamespace(taskID string) ([]*tasknetworkconfig.NetworkNamespace, error) {
 	privateIpv4, err1 := m.client.GetMetadata(PrivateIPv4Resource)
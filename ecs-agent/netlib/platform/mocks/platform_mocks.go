@@ -25,6 +25,7 @@ import (
 	ecsacs "github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	appmesh "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	egresspolicy "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	networkinterface "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	serviceconnect "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	tasknetworkconfig "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
@@ -83,6 +84,34 @@ func (mr *MockAPIMockRecorder) ConfigureAppMesh(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureAppMesh", reflect.TypeOf((*MockAPI)(nil).ConfigureAppMesh), arg0, arg1, arg2)
 }
 
+// ConfigureBridge mocks base method.
+func (m *MockAPI) ConfigureBridge(arg0 context.Context, arg1 string, arg2 *tasknetworkconfig.BridgeConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureBridge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureBridge indicates an expected call of ConfigureBridge.
+func (mr *MockAPIMockRecorder) ConfigureBridge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureBridge", reflect.TypeOf((*MockAPI)(nil).ConfigureBridge), arg0, arg1, arg2)
+}
+
+// ConfigureEgressPolicy mocks base method.
+func (m *MockAPI) ConfigureEgressPolicy(arg0 context.Context, arg1 string, arg2 *networkinterface.NetworkInterface, arg3 *egresspolicy.EgressPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureEgressPolicy", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureEgressPolicy indicates an expected call of ConfigureEgressPolicy.
+func (mr *MockAPIMockRecorder) ConfigureEgressPolicy(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureEgressPolicy", reflect.TypeOf((*MockAPI)(nil).ConfigureEgressPolicy), arg0, arg1, arg2, arg3)
+}
+
 // ConfigureInterface mocks base method.
 func (m *MockAPI) ConfigureInterface(arg0 context.Context, arg1 string, arg2 *networkinterface.NetworkInterface, arg3 data.NetworkDataClient) error {
 	m.ctrl.T.Helper()
@@ -153,6 +182,20 @@ func (mr *MockAPIMockRecorder) DeleteDNSConfig(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDNSConfig", reflect.TypeOf((*MockAPI)(nil).DeleteDNSConfig), arg0)
 }
 
+// DeleteEgressPolicy mocks base method.
+func (m *MockAPI) DeleteEgressPolicy(arg0 context.Context, arg1 string, arg2 *networkinterface.NetworkInterface, arg3 *egresspolicy.EgressPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEgressPolicy", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEgressPolicy indicates an expected call of DeleteEgressPolicy.
+func (mr *MockAPIMockRecorder) DeleteEgressPolicy(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEgressPolicy", reflect.TypeOf((*MockAPI)(nil).DeleteEgressPolicy), arg0, arg1, arg2, arg3)
+}
+
 // DeleteNetNS mocks base method.
 func (m *MockAPI) DeleteNetNS(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,178 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+const (
+	// dnsResponderPort is the loopback port on which the agent answers the DNS queries redirected by the
+	// network DNS fault in the nxdomain failure mode.
+	dnsResponderPort = 51653
+	dnsHeaderLength  = 12
+	// dnsMaxUDPMessageSize is the largest DNS message the responder reads, queries using EDNS can be
+	// larger than the 512 bytes of plain DNS over UDP.
+	dnsMaxUDPMessageSize = 4096
+	// dnsResponderNetNSCheckInterval is how often the responder checks that the network namespace it listens
+	// in still exists, since its socket would otherwise keep the namespace of a stopped task alive.
+	dnsResponderNetNSCheckInterval = time.Minute
+
+	dnsFlagResponse            = 0x8000
+	dnsFlagsOpcodeAndRD        = 0x7900
+	dnsFlagRecursionAvail      = 0x0080
+	dnsRcodeNameError          = 3
+	dnsLabelPointerMask   byte = 0xc0
+)
+
+// dnsResponder answers the DNS queries redirected to it with NXDOMAIN responses.
+type dnsResponder interface {
+	// Start makes the responder listen on dnsResponderPort of the loopback interface of the network namespace
+	// at netNSPath, or of the network namespace of the agent if netNSPath is empty. It is a no-op if the
+	// responder already listens there.
+	Start(netNSPath string) error
+	// Stop makes the responder stop listening in the network namespace at netNSPath.
+	Stop(netNSPath string)
+}
+
+// nxdomainResponder implements dnsResponder with a UDP socket per network namespace.
+type nxdomainResponder struct {
+	lock  sync.Mutex
+	conns map[string]net.PacketConn
+}
+
+func newDNSResponder() dnsResponder {
+	return &nxdomainResponder{
+		conns: make(map[string]net.PacketConn),
+	}
+}
+
+func (r *nxdomainResponder) Start(netNSPath string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.conns[netNSPath]; ok {
+		return nil
+	}
+	conn, err := listenUDPInNetNS(netNSPath, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: dnsResponderPort})
+	if err != nil {
+		return err
+	}
+	r.conns[netNSPath] = conn
+	go r.serve(netNSPath, conn)
+	return nil
+}
+
+func (r *nxdomainResponder) Stop(netNSPath string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if conn, ok := r.conns[netNSPath]; ok {
+		conn.Close()
+		delete(r.conns, netNSPath)
+	}
+}
+
+// serve answers the queries received on conn until it is closed, or until the network namespace at
+// netNSPath no longer exists.
+func (r *nxdomainResponder) serve(netNSPath string, conn net.PacketConn) {
+	buf := make([]byte, dnsMaxUDPMessageSize)
+	for {
+		conn.SetReadDeadline(time.Now().Add(dnsResponderNetNSCheckInterval))
+		n, addr, err := conn.ReadFrom(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			if netNSPath != "" {
+				if _, statErr := os.Stat(netNSPath); os.IsNotExist(statErr) {
+					r.release(netNSPath, conn)
+					return
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return
+		}
+		response, ok := nxdomainResponse(buf[:n])
+		if !ok {
+			continue
+		}
+		if _, err := conn.WriteTo(response, addr); err != nil {
+			logger.Warn("Unable to answer DNS query", logger.Fields{
+				"address":   addr.String(),
+				field.Error: err,
+			})
+		}
+	}
+}
+
+// release closes conn if it is still the socket of the responder in the network namespace at netNSPath.
+func (r *nxdomainResponder) release(netNSPath string, conn net.PacketConn) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.conns[netNSPath] == conn {
+		delete(r.conns, netNSPath)
+	}
+	conn.Close()
+}
+
+// nxdomainResponse returns the NXDOMAIN response to a DNS query. The response has the ID, the opcode, the
+// recursion desired flag and the question of the query, without any record. It returns false if the
+// message isn't a query with a question.
+func nxdomainResponse(query []byte) ([]byte, bool) {
+	if len(query) < dnsHeaderLength {
+		return nil, false
+	}
+	flags := binary.BigEndian.Uint16(query[2:4])
+	if flags&dnsFlagResponse != 0 || binary.BigEndian.Uint16(query[4:6]) == 0 {
+		return nil, false
+	}
+	// Find the end of the first question, a name made of length-prefixed labels followed by its type and class.
+	end := dnsHeaderLength
+	for {
+		if end >= len(query) {
+			return nil, false
+		}
+		labelLength := query[end]
+		if labelLength == 0 {
+			end++
+			break
+		}
+		// Queries don't compress the name of their question.
+		if labelLength&dnsLabelPointerMask != 0 {
+			return nil, false
+		}
+		end += 1 + int(labelLength)
+	}
+	end += 4
+	if end > len(query) {
+		return nil, false
+	}
+
+	response := make([]byte, end)
+	copy(response, query[:end])
+	binary.BigEndian.PutUint16(response[2:4],
+		dnsFlagResponse|flags&dnsFlagsOpcodeAndRD|dnsFlagRecursionAvail|dnsRcodeNameError)
+	// One question, and no answer, authority or additional record.
+	binary.BigEndian.PutUint16(response[4:6], 1)
+	for i := 6; i < dnsHeaderLength; i++ {
+		response[i] = 0
+	}
+	return response, true
+}
@@ -0,0 +1,77 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenUDPInNetNS opens a UDP socket bound to addr in the network namespace at netNSPath, or in the network
+// namespace of the agent if netNSPath is empty. A socket stays in the network namespace it was created in.
+func listenUDPInNetNS(netNSPath string, addr *net.UDPAddr) (net.PacketConn, error) {
+	if netNSPath == "" {
+		return net.ListenUDP("udp4", addr)
+	}
+
+	type result struct {
+		conn net.PacketConn
+		err  error
+	}
+	results := make(chan result, 1)
+	// The network namespace is switched on a dedicated thread. If it can't be switched back, the goroutine
+	// exits with the thread still locked, so that the thread is terminated rather than reused.
+	go func() {
+		runtime.LockOSThread()
+		origin, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()))
+		if err != nil {
+			runtime.UnlockOSThread()
+			results <- result{err: err}
+			return
+		}
+		defer origin.Close()
+		target, err := os.Open(netNSPath)
+		if err != nil {
+			runtime.UnlockOSThread()
+			results <- result{err: err}
+			return
+		}
+		defer target.Close()
+
+		if err := unix.Setns(int(target.Fd()), unix.CLONE_NEWNET); err != nil {
+			runtime.UnlockOSThread()
+			results <- result{err: fmt.Errorf("failed to enter network namespace %s: %w", netNSPath, err)}
+			return
+		}
+		conn, err := net.ListenUDP("udp4", addr)
+		if restoreErr := unix.Setns(int(origin.Fd()), unix.CLONE_NEWNET); restoreErr != nil {
+			if conn != nil {
+				conn.Close()
+			}
+			results <- result{err: fmt.Errorf("failed to restore network namespace: %w", restoreErr)}
+			return
+		}
+		runtime.UnlockOSThread()
+		results <- result{conn: conn, err: err}
+	}()
+	res := <-results
+	return res.conn, res.err
+}
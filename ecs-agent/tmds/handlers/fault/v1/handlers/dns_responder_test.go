@@ -0,0 +1,85 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// testDNSQueryHeader is the header of a query with ID 0x1234, recursion desired and one question.
+	testDNSQueryHeader = []byte{0x12, 0x34, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	// testDNSQuestion is the question for the A record of example.com.
+	testDNSQuestion = []byte{0x07, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 0x03, 'c', 'o', 'm', 0x00, 0x00, 0x01, 0x00, 0x01}
+	// testNXDOMAINResponseHeader is the header of the response to the query, with the response, recursion
+	// desired and recursion available flags, and the NXDOMAIN response code.
+	testNXDOMAINResponseHeader = []byte{0x12, 0x34, 0x81, 0x83, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+)
+
+func TestNXDOMAINResponse(t *testing.T) {
+	query := append(append([]byte{}, testDNSQueryHeader...), testDNSQuestion...)
+	// The additional records of the query, such as an EDNS record, aren't part of the response.
+	queryWithAdditional := append([]byte{}, query...)
+	queryWithAdditional[11] = 0x01
+	queryWithAdditional = append(queryWithAdditional, 0x00, 0x00, 0x29, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	expected := append(append([]byte{}, testNXDOMAINResponseHeader...), testDNSQuestion...)
+
+	for _, tc := range []struct {
+		name     string
+		message  []byte
+		expected []byte
+	}{
+		{name: "query", message: query, expected: expected},
+		{name: "query with additional record", message: queryWithAdditional, expected: expected},
+		{name: "truncated header", message: query[:dnsHeaderLength-1]},
+		{name: "truncated question", message: query[:len(query)-1]},
+		{name: "response", message: append(append([]byte{}, testNXDOMAINResponseHeader...), testDNSQuestion...)},
+		{name: "no question", message: []byte{0x12, 0x34, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{name: "compressed name", message: append(append([]byte{}, testDNSQueryHeader...), 0xc0, 0x0c, 0x00, 0x01, 0x00, 0x01)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			response, ok := nxdomainResponse(tc.message)
+			assert.Equal(t, tc.expected != nil, ok)
+			assert.Equal(t, tc.expected, response)
+		})
+	}
+}
+
+func TestNXDOMAINResponderServe(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	responder := &nxdomainResponder{conns: map[string]net.PacketConn{"": conn}}
+	go responder.serve("", conn)
+	defer responder.Stop("")
+
+	client, err := net.Dial("udp4", conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = client.Write(append(append([]byte{}, testDNSQueryHeader...), testDNSQuestion...))
+	require.NoError(t, err)
+
+	buf := make([]byte, dnsMaxUDPMessageSize)
+	n, err := client.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, append(append([]byte{}, testNXDOMAINResponseHeader...), testDNSQuestion...), buf[:n])
+}
@@ -0,0 +1,330 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/fault/v1/types"
+	v2 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v2"
+	state "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// Option configures optional behavior of the FaultHandler.
+type Option func(*FaultHandler)
+
+// ActiveFaultStore persists the active faults, so that they still expire after the agent restarts.
+type ActiveFaultStore interface {
+	// SaveActiveFault saves a fault, replacing any fault saved with the same key.
+	SaveActiveFault(*types.ActiveFault) error
+	// DeleteActiveFault deletes the fault saved with the key.
+	DeleteActiveFault(string) error
+	// GetActiveFaults gets all the saved faults.
+	GetActiveFaults() ([]*types.ActiveFault, error)
+}
+
+// WithActiveFaultStore sets the store in which the active faults are persisted.
+func WithActiveFaultStore(store ActiveFaultStore) Option {
+	return func(h *FaultHandler) {
+		h.activeFaultStore = store
+	}
+}
+
+// withTime sets the clock used to expire faults.
+func withTime(t ttime.Time) Option {
+	return func(h *FaultHandler) {
+		h.time = t
+	}
+}
+
+// withDNSResponder sets the DNS responder used by the network DNS faults in the nxdomain failure mode.
+func withDNSResponder(responder dnsResponder) Option {
+	return func(h *FaultHandler) {
+		h.dnsResponder = responder
+	}
+}
+
+// activeFaultTaskMetadata returns the task metadata needed by the commands that stop the fault.
+func activeFaultTaskMetadata(fault *types.ActiveFault) *state.TaskResponse {
+	return &state.TaskResponse{
+		TaskResponse:      &v2.TaskResponse{TaskARN: fault.TaskARN},
+		TaskNetworkConfig: fault.TaskNetworkConfig,
+	}
+}
+
+// setFaultDuration starts tracking the fault if it was started with a duration, so that it gets stopped
+// once the duration elapses. A fault started without a duration runs until it is stopped, so any previous
+// expiry of the same fault is discarded. It is still tracked if it relies on the DNS responder, so that the
+// responder is started again after the agent restarts.
+func (h *FaultHandler) setFaultDuration(taskMetadata *state.TaskResponse, faultType, id string,
+	request types.NetworkFaultRequest, durationSeconds *uint64) {
+	if durationSeconds == nil && !usesDNSResponder(faultType, request) {
+		h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, faultType, id))
+		return
+	}
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		logger.Error("Unable to track fault duration", logger.Fields{
+			field.TaskARN: taskMetadata.TaskARN,
+			field.Request: request.ToString(),
+			field.Error:   err,
+		})
+		return
+	}
+	fault := &types.ActiveFault{
+		FaultType:         faultType,
+		ID:                id,
+		TaskARN:           taskMetadata.TaskARN,
+		TaskNetworkConfig: taskMetadata.TaskNetworkConfig,
+		Request:           requestJSON,
+	}
+	if durationSeconds != nil {
+		fault.ExpiresAt = h.time.Now().Add(time.Duration(aws.ToUint64(durationSeconds)) * time.Second)
+	}
+	h.trackFault(fault)
+}
+
+// usesDNSResponder returns true if the fault is a network DNS fault in the nxdomain failure mode.
+func usesDNSResponder(faultType string, request types.NetworkFaultRequest) bool {
+	dnsRequest, ok := request.(types.NetworkDNSRequest)
+	return ok && faultType == types.DNSFaultType &&
+		aws.ToString(dnsRequest.FailureMode) == types.DNSFailureModeNXDOMAIN
+}
+
+// trackFault records the fault and schedules its expiry, replacing any previous record of the same fault.
+func (h *FaultHandler) trackFault(fault *types.ActiveFault) {
+	h.activeFaultsLock.Lock()
+	defer h.activeFaultsLock.Unlock()
+	key := fault.Key()
+	if timer, ok := h.expiryTimers[key]; ok {
+		timer.Stop()
+	}
+	h.activeFaults[key] = fault
+	h.scheduleExpiry(key, fault)
+	if h.activeFaultStore == nil {
+		return
+	}
+	if err := h.activeFaultStore.SaveActiveFault(fault); err != nil {
+		logger.Error("Unable to save active fault", logger.Fields{
+			field.TaskARN:     fault.TaskARN,
+			field.RequestType: fmt.Sprintf(startFaultRequestType, fault.FaultType),
+			field.Error:       err,
+		})
+	}
+}
+
+// untrackFault removes the record of the fault and cancels its expiry.
+func (h *FaultHandler) untrackFault(key string) {
+	h.activeFaultsLock.Lock()
+	defer h.activeFaultsLock.Unlock()
+	if _, ok := h.activeFaults[key]; !ok {
+		return
+	}
+	if timer, ok := h.expiryTimers[key]; ok {
+		timer.Stop()
+		delete(h.expiryTimers, key)
+	}
+	delete(h.activeFaults, key)
+	h.deleteActiveFault(key)
+}
+
+// deleteActiveFault deletes the fault from the store of active faults.
+func (h *FaultHandler) deleteActiveFault(key string) {
+	if h.activeFaultStore == nil {
+		return
+	}
+	if err := h.activeFaultStore.DeleteActiveFault(key); err != nil {
+		logger.Error("Unable to delete active fault", logger.Fields{
+			"key":       key,
+			field.Error: err,
+		})
+	}
+}
+
+// remainingSeconds returns the number of seconds, rounded up, until the fault expires. It returns nil if the
+// fault was started without a duration.
+func (h *FaultHandler) remainingSeconds(taskARN, faultType, id string) *uint64 {
+	h.activeFaultsLock.Lock()
+	defer h.activeFaultsLock.Unlock()
+	fault, ok := h.activeFaults[types.ActiveFaultKey(taskARN, faultType, id)]
+	if !ok || fault.ExpiresAt.IsZero() {
+		return nil
+	}
+	remaining := fault.ExpiresAt.Sub(h.time.Now()).Seconds()
+	if remaining < 0 {
+		remaining = 0
+	}
+	return aws.Uint64(uint64(math.Ceil(remaining)))
+}
+
+// scheduleExpiry sets a timer that stops the fault once it expires, unless it was started without a
+// duration. Callers must hold activeFaultsLock.
+func (h *FaultHandler) scheduleExpiry(key string, fault *types.ActiveFault) {
+	if fault.ExpiresAt.IsZero() {
+		return
+	}
+	delay := fault.ExpiresAt.Sub(h.time.Now())
+	if delay < 0 {
+		delay = 0
+	}
+	h.expiryTimers[key] = h.time.AfterFunc(delay, func() {
+		h.expireFault(fault)
+	})
+}
+
+// expireFault stops the fault unless it was stopped or restarted in the meantime.
+func (h *FaultHandler) expireFault(fault *types.ActiveFault) {
+	key := fault.Key()
+	// To avoid manipulating the network resource while a request does.
+	rwMu := h.loadLock(fault.TaskNetworkConfig.NetworkNamespaces[0].Path)
+	rwMu.Lock()
+	defer rwMu.Unlock()
+
+	h.activeFaultsLock.Lock()
+	current := h.activeFaults[key]
+	h.activeFaultsLock.Unlock()
+	if current != fault {
+		return
+	}
+
+	ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
+	defer cancel()
+	if err := h.stopExpiredFault(ctx, fault); err != nil {
+		// The task network namespace may be gone already, in which case there's nothing left to stop.
+		logger.Error("Unable to stop expired fault", logger.Fields{
+			field.TaskARN:     fault.TaskARN,
+			field.RequestType: fmt.Sprintf(stopFaultRequestType, fault.FaultType),
+			field.Request:     string(fault.Request),
+			field.Error:       err,
+		})
+	} else {
+		logger.Info("Stopped expired fault", logger.Fields{
+			field.TaskARN:     fault.TaskARN,
+			field.RequestType: fmt.Sprintf(stopFaultRequestType, fault.FaultType),
+			field.Request:     string(fault.Request),
+		})
+	}
+	h.untrackFault(key)
+}
+
+// stopExpiredFault stops the fault with the same commands used by the stop endpoint of its type.
+func (h *FaultHandler) stopExpiredFault(ctx context.Context, fault *types.ActiveFault) error {
+	taskMetadata := activeFaultTaskMetadata(fault)
+	networkMode := ecstypes.NetworkMode(fault.TaskNetworkConfig.NetworkMode)
+	networkNSPath := fault.TaskNetworkConfig.NetworkNamespaces[0].Path
+	switch fault.FaultType {
+	case types.BlackHolePortFaultType:
+		var request types.NetworkBlackholePortRequest
+		if err := json.Unmarshal(fault.Request, &request); err != nil {
+			return err
+		}
+		port := strconv.FormatUint(uint64(aws.ToUint16(request.Port)), 10)
+		_, err := h.stopNetworkBlackHolePort(ctx, aws.ToString(request.Protocol), port, fault.ID,
+			networkMode, networkNSPath, blackHolePortInsertTable(aws.ToString(request.TrafficType)), fault.TaskARN)
+		return err
+	case types.LatencyFaultType, types.PacketLossFaultType, types.BandwidthFaultType:
+		latencyFaultExists, packetLossFaultExists, bandwidthFaultExists, err := h.checkTCFault(ctx, taskMetadata)
+		if err != nil {
+			return err
+		}
+		// Only stop the fault if it is the one that expired, it may have been replaced by another tc fault.
+		if (fault.FaultType == types.LatencyFaultType && latencyFaultExists) ||
+			(fault.FaultType == types.PacketLossFaultType && packetLossFaultExists) ||
+			(fault.FaultType == types.BandwidthFaultType && bandwidthFaultExists) {
+			return h.stopTCFault(ctx, taskMetadata)
+		}
+		return nil
+	case types.DNSFaultType:
+		dnsFaultExists, err := h.checkNetworkDNSFault(ctx, taskMetadata)
+		if err != nil || !dnsFaultExists {
+			return err
+		}
+		return h.stopNetworkDNSFault(ctx, taskMetadata)
+	default:
+		return fmt.Errorf("unknown fault type %s", fault.FaultType)
+	}
+}
+
+// loadActiveFaults reads the faults persisted by a previous run of the agent and schedules their expiry.
+// Faults that expired while the agent was not running are stopped right away. The DNS responder of the
+// network DNS faults in the nxdomain failure mode is started again.
+func (h *FaultHandler) loadActiveFaults() {
+	faults, err := h.activeFaultStore.GetActiveFaults()
+	if err != nil {
+		logger.Error("Unable to load active faults", logger.Fields{
+			field.Error: err,
+		})
+		return
+	}
+
+	h.activeFaultsLock.Lock()
+	defer h.activeFaultsLock.Unlock()
+	for _, fault := range faults {
+		// The commands stopping the faults need the network namespace and the interface of the task
+		if err := validateTaskNetworkConfig(fault.TaskNetworkConfig); err != nil {
+			logger.Warn("Ignoring persisted fault with an invalid task network config", logger.Fields{
+				field.TaskARN:     fault.TaskARN,
+				field.RequestType: fmt.Sprintf(startFaultRequestType, fault.FaultType),
+				field.Error:       err,
+			})
+			h.deleteActiveFault(fault.Key())
+			continue
+		}
+		if err := h.restoreDNSResponder(fault); err != nil {
+			// The task may have stopped while the agent was not running.
+			logger.Warn("Ignoring persisted fault whose DNS responder can't be started", logger.Fields{
+				field.TaskARN:     fault.TaskARN,
+				field.RequestType: fmt.Sprintf(startFaultRequestType, fault.FaultType),
+				field.Error:       err,
+			})
+			h.deleteActiveFault(fault.Key())
+			continue
+		}
+		key := fault.Key()
+		h.activeFaults[key] = fault
+		h.scheduleExpiry(key, fault)
+		logger.Info("Restored active fault", logger.Fields{
+			field.TaskARN:     fault.TaskARN,
+			field.RequestType: fmt.Sprintf(startFaultRequestType, fault.FaultType),
+			"expiresAt":       fault.ExpiresAt.Format(time.RFC3339),
+		})
+	}
+}
+
+// restoreDNSResponder starts the DNS responder of the fault if it is a network DNS fault in the nxdomain
+// failure mode.
+func (h *FaultHandler) restoreDNSResponder(fault *types.ActiveFault) error {
+	if fault.FaultType != types.DNSFaultType {
+		return nil
+	}
+	var request types.NetworkDNSRequest
+	if err := json.Unmarshal(fault.Request, &request); err != nil {
+		return err
+	}
+	if !usesDNSResponder(fault.FaultType, request) {
+		return nil
+	}
+	return h.dnsResponder.Start(dnsResponderNetNS(activeFaultTaskMetadata(fault)))
+}
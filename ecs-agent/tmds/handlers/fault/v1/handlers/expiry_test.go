@@ -0,0 +1,317 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mock_metrics "github.com/aws/amazon-ecs-agent/ecs-agent/metrics/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/fault/v1/types"
+	state "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
+	mock_state "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/utils/netconfig"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime"
+	mock_ttime "github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime/mocks"
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeActiveFaultStore keeps the active faults in memory.
+type fakeActiveFaultStore struct {
+	faults map[string]*types.ActiveFault
+}
+
+func newFakeActiveFaultStore(faults ...*types.ActiveFault) *fakeActiveFaultStore {
+	store := &fakeActiveFaultStore{faults: make(map[string]*types.ActiveFault)}
+	for _, fault := range faults {
+		store.faults[fault.Key()] = fault
+	}
+	return store
+}
+
+func (s *fakeActiveFaultStore) SaveActiveFault(fault *types.ActiveFault) error {
+	s.faults[fault.Key()] = fault
+	return nil
+}
+
+func (s *fakeActiveFaultStore) DeleteActiveFault(key string) error {
+	delete(s.faults, key)
+	return nil
+}
+
+func (s *fakeActiveFaultStore) GetActiveFaults() ([]*types.ActiveFault, error) {
+	var faults []*types.ActiveFault
+	for _, fault := range s.faults {
+		faults = append(faults, fault)
+	}
+	return faults, nil
+}
+
+// expectStopLatencyFault sets the expectations of stopping a running network latency fault.
+func expectStopLatencyFault(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+	ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+	mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+	gomock.InOrder(
+		exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+		exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+		mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcLatencyFaultExistsCommandOutput), nil),
+	)
+	exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).Return(mockCMD)
+	mockCMD.EXPECT().CombinedOutput().Times(2).Return([]byte{}, nil)
+}
+
+func TestFaultExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockTimer := mock_ttime.NewMockTimer(ctrl)
+	mockExec := mock_execwrapper.NewMockExec(ctrl)
+	store := newFakeActiveFaultStore()
+
+	var expire func()
+	mockTime.EXPECT().Now().Return(testNow).AnyTimes()
+	mockTime.EXPECT().AfterFunc(time.Minute, gomock.Any()).DoAndReturn(
+		func(d time.Duration, f func()) ttime.Timer {
+			expire = f
+			return mockTimer
+		})
+
+	handler := New(mock_state.NewMockAgentState(ctrl), mock_metrics.NewMockEntryFactory(ctrl), mockExec,
+		withTime(mockTime), WithActiveFaultStore(store))
+	request := types.NetworkLatencyRequest{
+		DelayMilliseconds:  aws.Uint64(delayMilliseconds),
+		JitterMilliseconds: aws.Uint64(jitterMilliseconds),
+		Sources:            aws.StringSlice(ipSources),
+		DurationSeconds:    aws.Uint64(60),
+	}
+	handler.setFaultDuration(&happyTaskResponse, types.LatencyFaultType, "", request, request.DurationSeconds)
+
+	assert.Equal(t, aws.Uint64(60), handler.remainingSeconds(taskARN, types.LatencyFaultType, ""))
+	assert.Nil(t, handler.remainingSeconds(taskARN, types.PacketLossFaultType, ""))
+	require.Len(t, store.faults, 1)
+	fault := store.faults[types.ActiveFaultKey(taskARN, types.LatencyFaultType, "")]
+	require.NotNil(t, fault)
+	assert.Equal(t, "/some/path", fault.TaskNetworkConfig.NetworkNamespaces[0].Path)
+	assert.True(t, testNow.Add(time.Minute).Equal(fault.ExpiresAt))
+
+	// The fault gets stopped once the timer fires.
+	expectStopLatencyFault(mockExec, ctrl)
+	mockTimer.EXPECT().Stop().Return(false)
+	require.NotNil(t, expire)
+	expire()
+
+	assert.Nil(t, handler.remainingSeconds(taskARN, types.LatencyFaultType, ""))
+	assert.Empty(t, store.faults)
+}
+
+func TestFaultExpiryCanceledByStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockTimer := mock_ttime.NewMockTimer(ctrl)
+	mockTime.EXPECT().Now().Return(testNow).AnyTimes()
+	mockTime.EXPECT().AfterFunc(10*time.Second, gomock.Any()).Return(mockTimer)
+	mockTimer.EXPECT().Stop().Return(true)
+
+	handler := New(mock_state.NewMockAgentState(ctrl), mock_metrics.NewMockEntryFactory(ctrl),
+		mock_execwrapper.NewMockExec(ctrl), withTime(mockTime))
+	request := types.NetworkDNSRequest{
+		Domains:         aws.StringSlice([]string{dnsDomain}),
+		FailureMode:     aws.String(types.DNSFailureModeTimeout),
+		DurationSeconds: aws.Uint64(10),
+	}
+	handler.setFaultDuration(&happyTaskResponse, types.DNSFaultType, "", request, request.DurationSeconds)
+	assert.Equal(t, aws.Uint64(10), handler.remainingSeconds(taskARN, types.DNSFaultType, ""))
+
+	handler.untrackFault(types.ActiveFaultKey(taskARN, types.DNSFaultType, ""))
+	assert.Nil(t, handler.remainingSeconds(taskARN, types.DNSFaultType, ""))
+}
+
+func TestFaultExpiryAfterRestart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	requestJSON, err := json.Marshal(types.NetworkLatencyRequest{
+		DelayMilliseconds:  aws.Uint64(delayMilliseconds),
+		JitterMilliseconds: aws.Uint64(jitterMilliseconds),
+		Sources:            aws.StringSlice(ipSources),
+		DurationSeconds:    aws.Uint64(60),
+	})
+	require.NoError(t, err)
+	store := newFakeActiveFaultStore(&types.ActiveFault{
+		FaultType:         types.LatencyFaultType,
+		TaskARN:           taskARN,
+		TaskNetworkConfig: &happyTaskNetworkConfig,
+		Request:           requestJSON,
+		// The fault expired while the agent wasn't running.
+		ExpiresAt: testNow.Add(-time.Minute),
+	})
+
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockTimer := mock_ttime.NewMockTimer(ctrl)
+	mockExec := mock_execwrapper.NewMockExec(ctrl)
+	var expire func()
+	mockTime.EXPECT().Now().Return(testNow).AnyTimes()
+	mockTime.EXPECT().AfterFunc(time.Duration(0), gomock.Any()).DoAndReturn(
+		func(d time.Duration, f func()) ttime.Timer {
+			expire = f
+			return mockTimer
+		})
+
+	handler := New(mock_state.NewMockAgentState(ctrl), mock_metrics.NewMockEntryFactory(ctrl), mockExec,
+		withTime(mockTime), WithActiveFaultStore(store))
+	assert.Equal(t, aws.Uint64(0), handler.remainingSeconds(taskARN, types.LatencyFaultType, ""))
+
+	expectStopLatencyFault(mockExec, ctrl)
+	mockTimer.EXPECT().Stop().Return(false)
+	require.NotNil(t, expire)
+	expire()
+
+	assert.Nil(t, handler.remainingSeconds(taskARN, types.LatencyFaultType, ""))
+	assert.Empty(t, store.faults)
+}
+
+func TestFaultExpiryAfterRestartWithoutNetworkInterface(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := newFakeActiveFaultStore(&types.ActiveFault{
+		FaultType: types.LatencyFaultType,
+		TaskARN:   taskARN,
+		TaskNetworkConfig: &state.TaskNetworkConfig{
+			NetworkMode: awsvpcNetworkMode,
+			NetworkNamespaces: []*state.NetworkNamespace{
+				{Path: "/some/path"},
+			},
+		},
+		ExpiresAt: testNow.Add(time.Minute),
+	})
+
+	// The fault can't be stopped without a network interface, so no expiry is scheduled.
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockTime.EXPECT().Now().Return(testNow).AnyTimes()
+	handler := New(mock_state.NewMockAgentState(ctrl), mock_metrics.NewMockEntryFactory(ctrl),
+		mock_execwrapper.NewMockExec(ctrl), withTime(mockTime), WithActiveFaultStore(store))
+	assert.Nil(t, handler.remainingSeconds(taskARN, types.LatencyFaultType, ""))
+	assert.Empty(t, store.faults)
+}
+
+func TestNXDOMAINFaultRestoredAfterRestart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The fault has no duration, so no expiry is scheduled.
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockTime.EXPECT().Now().Return(testNow).AnyTimes()
+	store := newFakeActiveFaultStore()
+	handler := New(mock_state.NewMockAgentState(ctrl), mock_metrics.NewMockEntryFactory(ctrl),
+		mock_execwrapper.NewMockExec(ctrl), withTime(mockTime), WithActiveFaultStore(store),
+		withDNSResponder(&fakeDNSResponder{}))
+	request := types.NetworkDNSRequest{
+		Domains:     aws.StringSlice([]string{dnsDomain}),
+		FailureMode: aws.String(types.DNSFailureModeNXDOMAIN),
+	}
+	handler.setFaultDuration(&happyTaskResponse, types.DNSFaultType, "", request, request.DurationSeconds)
+	assert.Nil(t, handler.remainingSeconds(taskARN, types.DNSFaultType, ""))
+	require.Len(t, store.faults, 1)
+
+	// The DNS responder is started again once the agent restarts.
+	responder := &fakeDNSResponder{}
+	New(mock_state.NewMockAgentState(ctrl), mock_metrics.NewMockEntryFactory(ctrl),
+		mock_execwrapper.NewMockExec(ctrl), withTime(mockTime), WithActiveFaultStore(store),
+		withDNSResponder(responder))
+	assert.Equal(t, []string{"/some/path"}, responder.running)
+}
+
+func TestCheckNetworkLatencyRemainingSeconds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	agentState := mock_state.NewMockAgentState(ctrl)
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockExec := mock_execwrapper.NewMockExec(ctrl)
+	gomock.InOrder(
+		mockTime.EXPECT().Now().Return(testNow).Times(2),
+		// The status is checked 15 and a half seconds after the fault was started.
+		mockTime.EXPECT().Now().Return(testNow.Add(15500*time.Millisecond)).Times(1),
+	)
+	mockTime.EXPECT().AfterFunc(time.Minute, gomock.Any()).Return(mock_ttime.NewMockTimer(ctrl))
+
+	handler := New(agentState, mock_metrics.NewMockEntryFactory(ctrl), mockExec, withTime(mockTime))
+	router := mux.NewRouter()
+	router.HandleFunc(NetworkFaultPath(types.LatencyFaultType, types.StartNetworkFaultPostfix),
+		handler.StartNetworkLatency()).Methods(http.MethodPost)
+	router.HandleFunc(NetworkFaultPath(types.LatencyFaultType, types.CheckNetworkFaultPostfix),
+		handler.CheckNetworkLatency()).Methods(http.MethodPost)
+
+	agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netconfig.NewNetworkConfigClient()).
+		Return(happyTaskResponse, nil).Times(2)
+	ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+	mockExec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(2).Return(ctx, cancel)
+	checkCMD := mock_execwrapper.NewMockCmd(ctrl)
+	checkCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcCommandEmptyOutput), nil)
+	startCMD := mock_execwrapper.NewMockCmd(ctrl)
+	startCMD.EXPECT().CombinedOutput().Times(5).Return([]byte{}, nil)
+	statusCMD := mock_execwrapper.NewMockCmd(ctrl)
+	statusCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcLatencyFaultExistsCommandOutput), nil)
+	gomock.InOrder(
+		mockExec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(checkCMD),
+		mockExec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(5).Return(startCMD),
+		mockExec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(statusCMD),
+	)
+
+	reqBody := map[string]interface{}{
+		"DelayMilliseconds":  delayMilliseconds,
+		"JitterMilliseconds": jitterMilliseconds,
+		"Sources":            ipSources,
+		"SourcesToFilter":    ipSourcesToFilter,
+		"DurationSeconds":    60,
+	}
+	reqBodyBytes, err := json.Marshal(reqBody)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost,
+		fmt.Sprintf("/api/%s/fault/v1/network-latency/start", endpointId), bytes.NewReader(reqBodyBytes))
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, happyFaultRunningResponse, recorder.Body.String())
+
+	req, err = http.NewRequest(http.MethodPost,
+		fmt.Sprintf("/api/%s/fault/v1/network-latency/status", endpointId), nil)
+	require.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `{"Status":"running","RemainingSeconds":45}`, recorder.Body.String())
+}
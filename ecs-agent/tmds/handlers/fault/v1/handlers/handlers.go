@@ -36,6 +36,7 @@ import (
 	state "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/utils/netconfig"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
@@ -54,6 +55,8 @@ const (
 	requestTimedOutError               = "%s: request timed out"
 	latencyFaultAlreadyRunningError    = "There is already one network latency fault running"
 	packetLossFaultAlreadyRunningError = "There is already one network packet loss fault running"
+	bandwidthFaultAlreadyRunningError  = "There is already one network bandwidth fault running"
+	dnsFaultAlreadyRunningError        = "There is already one network DNS fault running"
 	// This is our initial assumption of how much time it would take for the Linux commands used to inject faults
 	// to finish. This will be confirmed/updated after more testing.
 	requestTimeoutSeconds = 5
@@ -65,11 +68,21 @@ const (
 	iptablesClearChainCmd            = "iptables -w %d -F %s"
 	iptablesDeleteFromTableCmd       = "iptables -w %d -D %s -j %s"
 	iptablesDeleteChainCmd           = "iptables -w %d -X %s"
+	iptablesListChainCmd             = "iptables -w %d -L %s -n"
+	iptablesAppendDNSChainRuleCmd    = "iptables -w %d -A %s -p %s --dport %d -m string --algo bm --icase --hex-string %s -j %s"
+	iptablesNATNewChainCmd           = "iptables -w %d -t nat -N %s"
+	iptablesNATInsertChainCmd        = "iptables -w %d -t nat -I %s -j %s"
+	iptablesNATClearChainCmd         = "iptables -w %d -t nat -F %s"
+	iptablesNATDeleteFromTableCmd    = "iptables -w %d -t nat -D %s -j %s"
+	iptablesNATDeleteChainCmd        = "iptables -w %d -t nat -X %s"
+	iptablesNATListChainCmd          = "iptables -w %d -t nat -L %s -n"
+	iptablesNATRedirectDNSRuleCmd    = "iptables -w %d -t nat -A %s -p udp --dport %d -m string --algo bm --icase --hex-string %s -j REDIRECT --to-ports %d"
 	nsenterCommandString             = "nsenter --net=%s "
 	tcCheckInjectionCommandString    = "tc -j q show dev %s parent 1:1"
 	tcAddQdiscRootCommandString      = "tc qdisc add dev %s root handle 1: prio priomap 2 2 2 2 2 2 2 2 2 2 2 2 2 2 2 2"
 	tcAddQdiscLatencyCommandString   = "tc qdisc add dev %s parent 1:1 handle 10: netem delay %dms %dms"
 	tcAddQdiscLossCommandString      = "tc qdisc add dev %s parent 1:1 handle 10: netem loss %d%%"
+	tcAddQdiscBandwidthCommandString = "tc qdisc add dev %s parent 1:1 handle 10: tbf rate %dkbit burst %dkb latency %dms"
 	tcAllowlistIPCommandString       = "tc filter add dev %s protocol ip parent 1:0 prio 1 u32 match ip dst %s flowid 1:3"
 	tcAddFilterForIPCommandString    = "tc filter add dev %s protocol ip parent 1:0 prio 2 u32 match ip dst %s flowid 1:1"
 	tcDeleteQdiscParentCommandString = "tc qdisc del dev %s parent 1:1 handle 10:"
@@ -77,6 +90,15 @@ const (
 	allIPv4CIDR                      = "0.0.0.0/0"
 	dropTarget                       = "DROP"
 	acceptTarget                     = "ACCEPT"
+	tcpResetTarget                   = "REJECT --reject-with tcp-reset"
+	// dnsFaultChain is the iptables chain holding the rules of the network DNS fault. It is inserted
+	// into the built-in OUTPUT table so that only the queries sent by the task are affected. The
+	// nxdomain failure mode also has a chain of the same name in the nat table.
+	dnsFaultChain = "dns-fault"
+	dnsPort       = 53
+	// bandwidthFaultLatencyMs is the maximum time a packet can wait in the token bucket filter before
+	// it gets dropped.
+	bandwidthFaultLatencyMs = 100
 )
 
 type FaultHandler struct {
@@ -87,15 +109,35 @@ type FaultHandler struct {
 	AgentState     state.AgentState
 	MetricsFactory metrics.EntryFactory
 	osExecWrapper  execwrapper.Exec
+	time           ttime.Time
+	dnsResponder   dnsResponder
+	// activeFaults holds the faults started with a duration and the network DNS faults in the nxdomain
+	// failure mode, keyed by task ARN, fault type and fault ID. They are persisted to activeFaultStore
+	// if it is set.
+	activeFaultsLock sync.Mutex
+	activeFaults     map[string]*types.ActiveFault
+	expiryTimers     map[string]ttime.Timer
+	activeFaultStore ActiveFaultStore
 }
 
-func New(agentState state.AgentState, mf metrics.EntryFactory, execWrapper execwrapper.Exec) *FaultHandler {
-	return &FaultHandler{
+func New(agentState state.AgentState, mf metrics.EntryFactory, execWrapper execwrapper.Exec, opts ...Option) *FaultHandler {
+	h := &FaultHandler{
 		AgentState:     agentState,
 		MetricsFactory: mf,
 		mutexMap:       sync.Map{},
 		osExecWrapper:  execWrapper,
+		time:           &ttime.DefaultTime{},
+		dnsResponder:   newDNSResponder(),
+		activeFaults:   make(map[string]*types.ActiveFault),
+		expiryTimers:   make(map[string]ttime.Timer),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.activeFaultStore != nil {
+		h.loadActiveFaults()
+	}
+	return h
 }
 
 // NetworkFaultPath will take in a fault type and return the TMDS endpoint path
@@ -158,10 +200,7 @@ func (h *FaultHandler) StartNetworkBlackholePort() func(http.ResponseWriter, *ht
 		stringToBeLogged := "Failed to start fault"
 		port := strconv.FormatUint(uint64(aws.ToUint16(request.Port)), 10)
 		chainName := fmt.Sprintf("%s-%s-%s", aws.ToString(request.TrafficType), aws.ToString(request.Protocol), port)
-		insertTable := "INPUT"
-		if aws.ToString(request.TrafficType) == "egress" {
-			insertTable = "OUTPUT"
-		}
+		insertTable := blackHolePortInsertTable(aws.ToString(request.TrafficType))
 
 		_, cmdErr := h.startNetworkBlackholePort(ctxWithTimeout, aws.ToString(request.Protocol),
 			port, aws.ToStringSlice(request.SourcesToFilter), chainName,
@@ -176,6 +215,7 @@ func (h *FaultHandler) StartNetworkBlackholePort() func(http.ResponseWriter, *ht
 			statusCode = http.StatusOK
 			responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
 			stringToBeLogged = "Successfully started fault"
+			h.setFaultDuration(taskMetadata, types.BlackHolePortFaultType, chainName, request, request.DurationSeconds)
 		}
 		logger.Info(stringToBeLogged, logger.Fields{
 			field.RequestType: requestType,
@@ -343,10 +383,7 @@ func (h *FaultHandler) StopNetworkBlackHolePort() func(http.ResponseWriter, *htt
 		stringToBeLogged := "Failed to stop fault"
 		port := strconv.FormatUint(uint64(aws.ToUint16(request.Port)), 10)
 		chainName := fmt.Sprintf("%s-%s-%s", aws.ToString(request.TrafficType), aws.ToString(request.Protocol), port)
-		insertTable := "INPUT"
-		if aws.ToString(request.TrafficType) == "egress" {
-			insertTable = "OUTPUT"
-		}
+		insertTable := blackHolePortInsertTable(aws.ToString(request.TrafficType))
 
 		_, cmdErr := h.stopNetworkBlackHolePort(ctxWithTimeout, aws.ToString(request.Protocol), port, chainName,
 			networkMode, networkNSPath, insertTable, taskArn)
@@ -361,6 +398,7 @@ func (h *FaultHandler) StopNetworkBlackHolePort() func(http.ResponseWriter, *htt
 			statusCode = http.StatusOK
 			responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
 			stringToBeLogged = "Successfully stopped fault"
+			h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.BlackHolePortFaultType, chainName))
 		}
 		logger.Info(stringToBeLogged, logger.Fields{
 			field.RequestType: requestType,
@@ -376,6 +414,14 @@ func (h *FaultHandler) StopNetworkBlackHolePort() func(http.ResponseWriter, *htt
 	}
 }
 
+// blackHolePortInsertTable returns the built-in table in which the chain of a black hole port fault is inserted.
+func blackHolePortInsertTable(trafficType string) string {
+	if trafficType == types.TrafficTypeEgress {
+		return "OUTPUT"
+	}
+	return "INPUT"
+}
+
 // stopNetworkBlackHolePort will stop a black hole port fault based on the chain name which is generated via "<trafficType>-<protocol>-<port>".
 // The general workflow is as followed:
 // 1. Checks if there's a running chain with the specified protocol and port number via checkNetworkBlackHolePort()
@@ -523,6 +569,7 @@ func (h *FaultHandler) CheckNetworkBlackHolePort() func(http.ResponseWriter, *ht
 			statusCode = http.StatusOK
 			if running {
 				responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
+				responseBody.RemainingSeconds = h.remainingSeconds(taskMetadata.TaskARN, types.BlackHolePortFaultType, chainName)
 			} else {
 				responseBody = types.NewNetworkFaultInjectionSuccessResponse("not-running")
 			}
@@ -624,7 +671,7 @@ func (h *FaultHandler) StartNetworkLatency() func(http.ResponseWriter, *http.Req
 		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
 		defer cancel()
 		// Check the status of current fault injection.
-		latencyFaultExists, packetLossFaultExists, err := h.checkTCFault(ctx, taskMetadata)
+		latencyFaultExists, packetLossFaultExists, bandwidthFaultExists, err := h.checkTCFault(ctx, taskMetadata)
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
 			httpStatusCode = http.StatusInternalServerError
@@ -639,6 +686,9 @@ func (h *FaultHandler) StartNetworkLatency() func(http.ResponseWriter, *http.Req
 			} else if packetLossFaultExists {
 				responseBody = types.NewNetworkFaultInjectionErrorResponse(packetLossFaultAlreadyRunningError)
 				httpStatusCode = http.StatusConflict
+			} else if bandwidthFaultExists {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(bandwidthFaultAlreadyRunningError)
+				httpStatusCode = http.StatusConflict
 			} else {
 				// Invoke the start fault injection functionality if not running.
 				err := h.startNetworkLatencyFault(ctx, taskMetadata, request)
@@ -650,6 +700,7 @@ func (h *FaultHandler) StartNetworkLatency() func(http.ResponseWriter, *http.Req
 					httpStatusCode = http.StatusInternalServerError
 				} else {
 					stringToBeLogged = "Successfully started fault"
+					h.setFaultDuration(taskMetadata, types.LatencyFaultType, "", request, request.DurationSeconds)
 					responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
 					httpStatusCode = http.StatusOK
 				}
@@ -696,7 +747,7 @@ func (h *FaultHandler) StopNetworkLatency() func(http.ResponseWriter, *http.Requ
 		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
 		defer cancel()
 		// Check the status of current fault injection.
-		latencyFaultExists, _, err := h.checkTCFault(ctx, taskMetadata)
+		latencyFaultExists, _, _, err := h.checkTCFault(ctx, taskMetadata)
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
 			httpStatusCode = http.StatusInternalServerError
@@ -707,6 +758,7 @@ func (h *FaultHandler) StopNetworkLatency() func(http.ResponseWriter, *http.Requ
 			// If there doesn't already exist a network-latency fault
 			if !latencyFaultExists {
 				stringToBeLogged = "No fault running"
+				h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.LatencyFaultType, ""))
 				responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
 				httpStatusCode = http.StatusOK
 			} else {
@@ -720,6 +772,7 @@ func (h *FaultHandler) StopNetworkLatency() func(http.ResponseWriter, *http.Requ
 					httpStatusCode = http.StatusInternalServerError
 				} else {
 					stringToBeLogged = "Successfully stopped fault"
+					h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.LatencyFaultType, ""))
 					responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
 					httpStatusCode = http.StatusOK
 				}
@@ -767,7 +820,7 @@ func (h *FaultHandler) CheckNetworkLatency() func(http.ResponseWriter, *http.Req
 		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
 		defer cancel()
 		// Check the status of current fault injection.
-		latencyFaultExists, _, err := h.checkTCFault(ctx, taskMetadata)
+		latencyFaultExists, _, _, err := h.checkTCFault(ctx, taskMetadata)
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
 			httpStatusCode = http.StatusInternalServerError
@@ -779,6 +832,7 @@ func (h *FaultHandler) CheckNetworkLatency() func(http.ResponseWriter, *http.Req
 			// If there already exists a fault in the task network namespace.
 			if latencyFaultExists {
 				responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
+				responseBody.RemainingSeconds = h.remainingSeconds(taskMetadata.TaskARN, types.LatencyFaultType, "")
 				httpStatusCode = http.StatusOK
 			} else {
 				responseBody = types.NewNetworkFaultInjectionSuccessResponse("not-running")
@@ -837,7 +891,7 @@ func (h *FaultHandler) StartNetworkPacketLoss() func(http.ResponseWriter, *http.
 		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
 		defer cancel()
 		// Check the status of current fault injection.
-		latencyFaultExists, packetLossFaultExists, err := h.checkTCFault(ctx, taskMetadata)
+		latencyFaultExists, packetLossFaultExists, bandwidthFaultExists, err := h.checkTCFault(ctx, taskMetadata)
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
 			httpStatusCode = http.StatusInternalServerError
@@ -852,6 +906,9 @@ func (h *FaultHandler) StartNetworkPacketLoss() func(http.ResponseWriter, *http.
 			} else if packetLossFaultExists {
 				responseBody = types.NewNetworkFaultInjectionErrorResponse(packetLossFaultAlreadyRunningError)
 				httpStatusCode = http.StatusConflict
+			} else if bandwidthFaultExists {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(bandwidthFaultAlreadyRunningError)
+				httpStatusCode = http.StatusConflict
 			} else {
 				// Invoke the start fault injection functionality if not running.
 				err := h.startNetworkPacketLossFault(ctx, taskMetadata, request)
@@ -863,6 +920,7 @@ func (h *FaultHandler) StartNetworkPacketLoss() func(http.ResponseWriter, *http.
 					httpStatusCode = http.StatusInternalServerError
 				} else {
 					stringToBeLogged = "Successfully started fault"
+					h.setFaultDuration(taskMetadata, types.PacketLossFaultType, "", request, request.DurationSeconds)
 					responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
 					httpStatusCode = http.StatusOK
 				}
@@ -909,7 +967,7 @@ func (h *FaultHandler) StopNetworkPacketLoss() func(http.ResponseWriter, *http.R
 		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
 		defer cancel()
 		// Check the status of current fault injection.
-		_, packetLossFaultExists, err := h.checkTCFault(ctx, taskMetadata)
+		_, packetLossFaultExists, _, err := h.checkTCFault(ctx, taskMetadata)
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
 			httpStatusCode = http.StatusInternalServerError
@@ -920,6 +978,7 @@ func (h *FaultHandler) StopNetworkPacketLoss() func(http.ResponseWriter, *http.R
 			// If there doesn't already exist a network-packet-loss fault
 			if !packetLossFaultExists {
 				stringToBeLogged = "No fault running"
+				h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.PacketLossFaultType, ""))
 				responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
 				httpStatusCode = http.StatusOK
 			} else {
@@ -933,6 +992,7 @@ func (h *FaultHandler) StopNetworkPacketLoss() func(http.ResponseWriter, *http.R
 					httpStatusCode = http.StatusInternalServerError
 				} else {
 					stringToBeLogged = "Successfully stopped fault"
+					h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.PacketLossFaultType, ""))
 					responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
 					httpStatusCode = http.StatusOK
 				}
@@ -980,7 +1040,7 @@ func (h *FaultHandler) CheckNetworkPacketLoss() func(http.ResponseWriter, *http.
 		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
 		defer cancel()
 		// Check the status of current fault injection.
-		_, packetLossFaultExists, err := h.checkTCFault(ctx, taskMetadata)
+		_, packetLossFaultExists, _, err := h.checkTCFault(ctx, taskMetadata)
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
 			httpStatusCode = http.StatusInternalServerError
@@ -992,6 +1052,7 @@ func (h *FaultHandler) CheckNetworkPacketLoss() func(http.ResponseWriter, *http.
 			// If there already exists a fault in the task network namespace.
 			if packetLossFaultExists {
 				responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
+				responseBody.RemainingSeconds = h.remainingSeconds(taskMetadata.TaskARN, types.PacketLossFaultType, "")
 				httpStatusCode = http.StatusOK
 			} else {
 				responseBody = types.NewNetworkFaultInjectionSuccessResponse("not-running")
@@ -1012,6 +1073,431 @@ func (h *FaultHandler) CheckNetworkPacketLoss() func(http.ResponseWriter, *http.
 	}
 }
 
+// StartNetworkBandwidth starts a network bandwidth fault in the associated ENI if no existing same fault.
+func (h *FaultHandler) StartNetworkBandwidth() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request types.NetworkBandwidthRequest
+		requestType := fmt.Sprintf(startFaultRequestType, types.BandwidthFaultType)
+
+		// Parse the fault request
+		err := decodeRequest(w, &request, requestType, r)
+		if err != nil {
+			return
+		}
+
+		// Validate the fault request
+		err = validateRequest(w, request, requestType)
+		if err != nil {
+			return
+		}
+
+		// Obtain the task metadata via the endpoint container ID
+		taskMetadata, err := validateTaskMetadata(w, h.AgentState, requestType, r)
+		if err != nil {
+			return
+		}
+
+		// To avoid multiple requests to manipulate same network resource
+		networkNSPath := taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path
+		rwMu := h.loadLock(networkNSPath)
+		rwMu.Lock()
+		defer rwMu.Unlock()
+
+		var responseBody types.NetworkFaultInjectionResponse
+		var httpStatusCode int
+		stringToBeLogged := "Failed to start fault"
+		// All command executions for the start network bandwidth workflow all together should finish within 5 seconds.
+		// Thus, create the context here so that it can be shared by all os/exec calls.
+		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
+		defer cancel()
+		// Check the status of current fault injection.
+		latencyFaultExists, packetLossFaultExists, bandwidthFaultExists, err := h.checkTCFault(ctx, taskMetadata)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+			httpStatusCode = http.StatusInternalServerError
+		} else if err != nil {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+			httpStatusCode = http.StatusInternalServerError
+		} else {
+			// If there already exists a fault in the task network namespace.
+			if latencyFaultExists {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(latencyFaultAlreadyRunningError)
+				httpStatusCode = http.StatusConflict
+			} else if packetLossFaultExists {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(packetLossFaultAlreadyRunningError)
+				httpStatusCode = http.StatusConflict
+			} else if bandwidthFaultExists {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(bandwidthFaultAlreadyRunningError)
+				httpStatusCode = http.StatusConflict
+			} else {
+				// Invoke the start fault injection functionality if not running.
+				err := h.startNetworkBandwidthFault(ctx, taskMetadata, request)
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+					httpStatusCode = http.StatusInternalServerError
+				} else if err != nil {
+					responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+					httpStatusCode = http.StatusInternalServerError
+				} else {
+					stringToBeLogged = "Successfully started fault"
+					h.setFaultDuration(taskMetadata, types.BandwidthFaultType, "", request, request.DurationSeconds)
+					responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
+					httpStatusCode = http.StatusOK
+				}
+			}
+		}
+		logger.Info(stringToBeLogged, logger.Fields{
+			field.RequestType: requestType,
+			field.Request:     request.ToString(),
+			field.Response:    responseBody.ToString(),
+		})
+		utils.WriteJSONResponse(
+			w,
+			httpStatusCode,
+			responseBody,
+			requestType,
+		)
+	}
+}
+
+// StopNetworkBandwidth stops a network bandwidth fault in the associated ENI if there is one existing same fault.
+func (h *FaultHandler) StopNetworkBandwidth() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request types.NetworkBandwidthRequest
+		requestType := fmt.Sprintf(stopFaultRequestType, types.BandwidthFaultType)
+		logRequest(requestType, r)
+
+		// Obtain the task metadata via the endpoint container ID
+		taskMetadata, err := validateTaskMetadata(w, h.AgentState, requestType, r)
+		if err != nil {
+			return
+		}
+
+		// To avoid multiple requests to manipulate same network resource
+		networkNSPath := taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path
+		rwMu := h.loadLock(networkNSPath)
+		rwMu.Lock()
+		defer rwMu.Unlock()
+
+		var responseBody types.NetworkFaultInjectionResponse
+		var httpStatusCode int
+		stringToBeLogged := "Failed to stop fault"
+		// All command executions for the stop network bandwidth workflow all together should finish within 5 seconds.
+		// Thus, create the context here so that it can be shared by all os/exec calls.
+		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
+		defer cancel()
+		// Check the status of current fault injection.
+		_, _, bandwidthFaultExists, err := h.checkTCFault(ctx, taskMetadata)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+			httpStatusCode = http.StatusInternalServerError
+		} else if err != nil {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+			httpStatusCode = http.StatusInternalServerError
+		} else {
+			// If there doesn't already exist a network-bandwidth fault
+			if !bandwidthFaultExists {
+				stringToBeLogged = "No fault running"
+				h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.BandwidthFaultType, ""))
+				responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
+				httpStatusCode = http.StatusOK
+			} else {
+				// Invoke the stop fault injection functionality if running.
+				err := h.stopTCFault(ctx, taskMetadata)
+				if errors.Is(err, context.DeadlineExceeded) {
+					responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+					httpStatusCode = http.StatusInternalServerError
+				} else if err != nil {
+					responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+					httpStatusCode = http.StatusInternalServerError
+				} else {
+					stringToBeLogged = "Successfully stopped fault"
+					h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.BandwidthFaultType, ""))
+					responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
+					httpStatusCode = http.StatusOK
+				}
+			}
+		}
+		logger.Info(stringToBeLogged, logger.Fields{
+			field.RequestType: requestType,
+			field.Request:     request.ToString(),
+			field.Response:    responseBody.ToString(),
+		})
+		utils.WriteJSONResponse(
+			w,
+			httpStatusCode,
+			responseBody,
+			requestType,
+		)
+	}
+}
+
+// CheckNetworkBandwidth checks the status of given network bandwidth fault.
+func (h *FaultHandler) CheckNetworkBandwidth() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request types.NetworkBandwidthRequest
+		requestType := fmt.Sprintf(checkStatusFaultRequestType, types.BandwidthFaultType)
+		logRequest(requestType, r)
+
+		// Obtain the task metadata via the endpoint container ID.
+		taskMetadata, err := validateTaskMetadata(w, h.AgentState, requestType, r)
+		if err != nil {
+			return
+		}
+
+		// To avoid multiple requests to manipulate same network resource.
+		networkNSPath := taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path
+		rwMu := h.loadLock(networkNSPath)
+		rwMu.RLock()
+		defer rwMu.RUnlock()
+
+		// Check and return the status of current fault injection.
+		var responseBody types.NetworkFaultInjectionResponse
+		var httpStatusCode int
+		stringToBeLogged := "Failed to check status for fault"
+		// All command executions for the check network bandwidth workflow all together should finish within 5 seconds.
+		// Thus, create the context here so that it can be shared by all os/exec calls.
+		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
+		defer cancel()
+		// Check the status of current fault injection.
+		_, _, bandwidthFaultExists, err := h.checkTCFault(ctx, taskMetadata)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+			httpStatusCode = http.StatusInternalServerError
+		} else if err != nil {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+			httpStatusCode = http.StatusInternalServerError
+		} else {
+			stringToBeLogged = "Successfully checked fault status"
+			// If there already exists a fault in the task network namespace.
+			if bandwidthFaultExists {
+				responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
+				responseBody.RemainingSeconds = h.remainingSeconds(taskMetadata.TaskARN, types.BandwidthFaultType, "")
+				httpStatusCode = http.StatusOK
+			} else {
+				responseBody = types.NewNetworkFaultInjectionSuccessResponse("not-running")
+				httpStatusCode = http.StatusOK
+			}
+		}
+		logger.Info(stringToBeLogged, logger.Fields{
+			field.RequestType: requestType,
+			field.Request:     request.ToString(),
+			field.Response:    responseBody.ToString(),
+		})
+		utils.WriteJSONResponse(
+			w,
+			httpStatusCode,
+			responseBody,
+			requestType,
+		)
+	}
+}
+
+// StartNetworkDNS starts a network DNS fault in the task network namespace if no existing same fault.
+func (h *FaultHandler) StartNetworkDNS() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request types.NetworkDNSRequest
+		requestType := fmt.Sprintf(startFaultRequestType, types.DNSFaultType)
+
+		// Parse the fault request
+		err := decodeRequest(w, &request, requestType, r)
+		if err != nil {
+			return
+		}
+
+		// Validate the fault request
+		err = validateRequest(w, request, requestType)
+		if err != nil {
+			return
+		}
+
+		// Obtain the task metadata via the endpoint container ID
+		taskMetadata, err := validateTaskMetadata(w, h.AgentState, requestType, r)
+		if err != nil {
+			return
+		}
+
+		// To avoid multiple requests to manipulate same network resource
+		networkNSPath := taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path
+		rwMu := h.loadLock(networkNSPath)
+		rwMu.Lock()
+		defer rwMu.Unlock()
+
+		var responseBody types.NetworkFaultInjectionResponse
+		var httpStatusCode int
+		stringToBeLogged := "Failed to start fault"
+		// All command executions for the start network DNS workflow all together should finish within 5 seconds.
+		// Thus, create the context here so that it can be shared by all os/exec calls.
+		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
+		defer cancel()
+		// Check the status of current fault injection.
+		dnsFaultExists, err := h.checkNetworkDNSFault(ctx, taskMetadata)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+			httpStatusCode = http.StatusInternalServerError
+		} else if err != nil {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+			httpStatusCode = http.StatusInternalServerError
+		} else if dnsFaultExists {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(dnsFaultAlreadyRunningError)
+			httpStatusCode = http.StatusConflict
+		} else {
+			// Invoke the start fault injection functionality if not running.
+			err := h.startNetworkDNSFault(ctx, taskMetadata, request)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+				httpStatusCode = http.StatusInternalServerError
+			} else if err != nil {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+				httpStatusCode = http.StatusInternalServerError
+			} else {
+				stringToBeLogged = "Successfully started fault"
+				h.setFaultDuration(taskMetadata, types.DNSFaultType, "", request, request.DurationSeconds)
+				responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
+				httpStatusCode = http.StatusOK
+			}
+		}
+		logger.Info(stringToBeLogged, logger.Fields{
+			field.RequestType: requestType,
+			field.Request:     request.ToString(),
+			field.Response:    responseBody.ToString(),
+		})
+		utils.WriteJSONResponse(
+			w,
+			httpStatusCode,
+			responseBody,
+			requestType,
+		)
+	}
+}
+
+// StopNetworkDNS stops a network DNS fault in the task network namespace if there is one existing same fault.
+func (h *FaultHandler) StopNetworkDNS() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request types.NetworkDNSRequest
+		requestType := fmt.Sprintf(stopFaultRequestType, types.DNSFaultType)
+		logRequest(requestType, r)
+
+		// Obtain the task metadata via the endpoint container ID
+		taskMetadata, err := validateTaskMetadata(w, h.AgentState, requestType, r)
+		if err != nil {
+			return
+		}
+
+		// To avoid multiple requests to manipulate same network resource
+		networkNSPath := taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path
+		rwMu := h.loadLock(networkNSPath)
+		rwMu.Lock()
+		defer rwMu.Unlock()
+
+		var responseBody types.NetworkFaultInjectionResponse
+		var httpStatusCode int
+		stringToBeLogged := "Failed to stop fault"
+		// All command executions for the stop network DNS workflow all together should finish within 5 seconds.
+		// Thus, create the context here so that it can be shared by all os/exec calls.
+		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
+		defer cancel()
+		// Check the status of current fault injection.
+		dnsFaultExists, err := h.checkNetworkDNSFault(ctx, taskMetadata)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+			httpStatusCode = http.StatusInternalServerError
+		} else if err != nil {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+			httpStatusCode = http.StatusInternalServerError
+		} else if !dnsFaultExists {
+			stringToBeLogged = "No fault running"
+			h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.DNSFaultType, ""))
+			responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
+			httpStatusCode = http.StatusOK
+		} else {
+			// Invoke the stop fault injection functionality if running.
+			err := h.stopNetworkDNSFault(ctx, taskMetadata)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+				httpStatusCode = http.StatusInternalServerError
+			} else if err != nil {
+				responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+				httpStatusCode = http.StatusInternalServerError
+			} else {
+				stringToBeLogged = "Successfully stopped fault"
+				h.untrackFault(types.ActiveFaultKey(taskMetadata.TaskARN, types.DNSFaultType, ""))
+				responseBody = types.NewNetworkFaultInjectionSuccessResponse("stopped")
+				httpStatusCode = http.StatusOK
+			}
+		}
+		logger.Info(stringToBeLogged, logger.Fields{
+			field.RequestType: requestType,
+			field.Request:     request.ToString(),
+			field.Response:    responseBody.ToString(),
+		})
+		utils.WriteJSONResponse(
+			w,
+			httpStatusCode,
+			responseBody,
+			requestType,
+		)
+	}
+}
+
+// CheckNetworkDNS checks the status of given network DNS fault.
+func (h *FaultHandler) CheckNetworkDNS() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request types.NetworkDNSRequest
+		requestType := fmt.Sprintf(checkStatusFaultRequestType, types.DNSFaultType)
+		logRequest(requestType, r)
+
+		// Obtain the task metadata via the endpoint container ID.
+		taskMetadata, err := validateTaskMetadata(w, h.AgentState, requestType, r)
+		if err != nil {
+			return
+		}
+
+		// To avoid multiple requests to manipulate same network resource.
+		networkNSPath := taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path
+		rwMu := h.loadLock(networkNSPath)
+		rwMu.RLock()
+		defer rwMu.RUnlock()
+
+		var responseBody types.NetworkFaultInjectionResponse
+		var httpStatusCode int
+		stringToBeLogged := "Failed to check status for fault"
+		// All command executions for the check network DNS workflow all together should finish within 5 seconds.
+		// Thus, create the context here so that it can be shared by all os/exec calls.
+		ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
+		defer cancel()
+		// Check the status of current fault injection.
+		dnsFaultExists, err := h.checkNetworkDNSFault(ctx, taskMetadata)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(requestTimedOutError, requestType))
+			httpStatusCode = http.StatusInternalServerError
+		} else if err != nil {
+			responseBody = types.NewNetworkFaultInjectionErrorResponse(internalError)
+			httpStatusCode = http.StatusInternalServerError
+		} else {
+			stringToBeLogged = "Successfully checked fault status"
+			if dnsFaultExists {
+				responseBody = types.NewNetworkFaultInjectionSuccessResponse("running")
+				responseBody.RemainingSeconds = h.remainingSeconds(taskMetadata.TaskARN, types.DNSFaultType, "")
+			} else {
+				responseBody = types.NewNetworkFaultInjectionSuccessResponse("not-running")
+			}
+			httpStatusCode = http.StatusOK
+		}
+		logger.Info(stringToBeLogged, logger.Fields{
+			field.RequestType: requestType,
+			field.Request:     request.ToString(),
+			field.Response:    responseBody.ToString(),
+		})
+		utils.WriteJSONResponse(
+			w,
+			httpStatusCode,
+			responseBody,
+			requestType,
+		)
+	}
+}
+
 // decodeRequest will log the request and then translate/unmarshal an incoming fault injection request into
 // one of the network fault structs which requires the reqeust body to non-empty.
 func decodeRequest(w http.ResponseWriter, request types.NetworkFaultRequest, requestType string, r *http.Request) error {
@@ -1407,8 +1893,9 @@ func (h *FaultHandler) stopTCFault(ctx context.Context, taskMetadata *state.Task
 	return nil
 }
 
-// checkTCFault check if there's existing network-latency fault or network-packet-loss fault.
-func (h *FaultHandler) checkTCFault(ctx context.Context, taskMetadata *state.TaskResponse) (bool, bool, error) {
+// checkTCFault check if there's existing network-latency fault, network-packet-loss fault or
+// network-bandwidth fault.
+func (h *FaultHandler) checkTCFault(ctx context.Context, taskMetadata *state.TaskResponse) (bool, bool, bool, error) {
 	interfaceName := taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].NetworkInterfaces[0].DeviceName
 	networkMode := ecstypes.NetworkMode(taskMetadata.TaskNetworkConfig.NetworkMode)
 	// If task's network mode is awsvpc, we need to run nsenter to access the task's network namespace.
@@ -1431,7 +1918,7 @@ func (h *FaultHandler) checkTCFault(ctx context.Context, taskMetadata *state.Tas
 			field.CommandOutput: string(cmdOutput[:]),
 			field.TaskARN:       taskMetadata.TaskARN,
 		})
-		return false, false, fmt.Errorf("failed to check existing network fault: '%s' command failed with the following error: '%s'. std output: '%s'. TaskArn: %s",
+		return false, false, false, fmt.Errorf("failed to check existing network fault: '%s' command failed with the following error: '%s'. std output: '%s'. TaskArn: %s",
 			tcCheckInjectionCommandComposed, err, string(cmdOutput[:]), taskMetadata.TaskARN)
 	}
 	// Log the command output to better help us debug.
@@ -1440,21 +1927,22 @@ func (h *FaultHandler) checkTCFault(ctx context.Context, taskMetadata *state.Tas
 		field.CommandOutput: string(cmdOutput[:]),
 	})
 
-	// Check whether latency fault exists and whether packet loss fault exists separately.
+	// Check whether latency fault, packet loss fault and bandwidth fault exist separately.
 	var outputUnmarshalled []map[string]interface{}
 	err = json.Unmarshal(cmdOutput, &outputUnmarshalled)
 	if err != nil {
-		return false, false, fmt.Errorf("failed to check existing network fault: failed to unmarshal tc command output: %s. TaskArn: %s", err.Error(), taskMetadata.TaskARN)
+		return false, false, false, fmt.Errorf("failed to check existing network fault: failed to unmarshal tc command output: %s. TaskArn: %s", err.Error(), taskMetadata.TaskARN)
 	}
 	latencyFaultExists, err := checkLatencyFault(outputUnmarshalled)
 	if err != nil {
-		return false, false, fmt.Errorf("failed to check existing network fault: failed to unmarshal tc command output: %s. TaskArn: %s", err.Error(), taskMetadata.TaskARN)
+		return false, false, false, fmt.Errorf("failed to check existing network fault: failed to unmarshal tc command output: %s. TaskArn: %s", err.Error(), taskMetadata.TaskARN)
 	}
 	packetLossFaultExists, err := checkPacketLossFault(outputUnmarshalled)
 	if err != nil {
-		return false, false, fmt.Errorf("failed to check existing network fault: failed to unmarshal tc command output: %s. TaskArn: %s", err.Error(), taskMetadata.TaskARN)
+		return false, false, false, fmt.Errorf("failed to check existing network fault: failed to unmarshal tc command output: %s. TaskArn: %s", err.Error(), taskMetadata.TaskARN)
 	}
-	return latencyFaultExists, packetLossFaultExists, nil
+	bandwidthFaultExists := checkBandwidthFault(outputUnmarshalled)
+	return latencyFaultExists, packetLossFaultExists, bandwidthFaultExists, nil
 }
 
 // checkLatencyFault parses the tc command output and checks if there's existing network-latency fault running.
@@ -1489,6 +1977,269 @@ func checkPacketLossFault(outputUnmarshalled []map[string]interface{}) (bool, er
 	return false, nil
 }
 
+// checkBandwidthFault parses the tc command output and checks if there's existing network-bandwidth fault running.
+func checkBandwidthFault(outputUnmarshalled []map[string]interface{}) bool {
+	for _, line := range outputUnmarshalled {
+		// The bandwidth fault is the only one using the token bucket filter as its queueing discipline.
+		if line["kind"] == "tbf" {
+			return true
+		}
+	}
+	return false
+}
+
+// startNetworkBandwidthFault invokes the linux TC utility tool to start the network-bandwidth fault.
+func (h *FaultHandler) startNetworkBandwidthFault(ctx context.Context, taskMetadata *state.TaskResponse, request types.NetworkBandwidthRequest) error {
+	interfaceName := taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].NetworkInterfaces[0].DeviceName
+	networkMode := ecstypes.NetworkMode(taskMetadata.TaskNetworkConfig.NetworkMode)
+	// If task's network mode is awsvpc, we need to run nsenter to access the task's network namespace.
+	nsenterPrefix := ""
+	if networkMode == ecstypes.NetworkModeAwsvpc {
+		nsenterPrefix = fmt.Sprintf(nsenterCommandString, taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path)
+	}
+	rateKbps := aws.ToUint64(request.RateKbps)
+	burstKilobytes := uint64(types.DefaultBandwidthBurstKilobytes)
+	if request.BurstKilobytes != nil {
+		burstKilobytes = aws.ToUint64(request.BurstKilobytes)
+	}
+
+	// Command to be executed:
+	// <nsenterPrefix> tc qdisc add dev <interfaceName> root handle 1: prio priomap 2 2 2 2 2 2 2 2 2 2 2 2 2 2 2 2
+	// <nsenterPrefix> tc qdisc add dev <interfaceName> parent 1:1 handle 10: tbf rate <rate>kbit burst <burst>kb latency <latency>ms
+	if err := h.runCommands(ctx, []string{
+		nsenterPrefix + fmt.Sprintf(tcAddQdiscRootCommandString, interfaceName),
+		nsenterPrefix + fmt.Sprintf(tcAddQdiscBandwidthCommandString, interfaceName, rateKbps, burstKilobytes,
+			bandwidthFaultLatencyMs),
+	}, taskMetadata.TaskARN); err != nil {
+		return err
+	}
+	// After creating the queueing discipline, create filters to associate the IPs in the request with the handle.
+	// First redirect the allowlisted ip addresses to band 1:3 where is no network impairments.
+	if err := h.addIPAddressesToFilter(ctx, request.SourcesToFilter, taskMetadata, nsenterPrefix, tcAllowlistIPCommandString, interfaceName); err != nil {
+		return err
+	}
+	// After processing the allowlisted ips, associate the ip addresses in Sources with the qdisc.
+	if err := h.addIPAddressesToFilter(ctx, request.Sources, taskMetadata, nsenterPrefix, tcAddFilterForIPCommandString, interfaceName); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// startNetworkDNSFault invokes iptables to start the network-dns fault.
+// The general workflow is as followed:
+// 1. Creates the dns-fault chain via `iptables -N dns-fault`
+// 2. Appends a rule per domain and protocol that matches the encoded domain name in queries sent to port 53,
+// dropping them for the timeout failure mode
+// 3. Inserts the chain into the built-in OUTPUT table
+// For the nxdomain failure mode, the agent answers the matching UDP queries itself: it starts a DNS responder
+// on the loopback interface of the task network namespace, and the UDP rules are in a dns-fault chain of the
+// nat table that redirects the queries to the responder. TCP queries can't be redirected based on the domain
+// name, since only the first packet of a connection goes through the nat table, so they are reset instead.
+// If a command fails once the chain is created, the chains are deleted so that the fault can be started again.
+func (h *FaultHandler) startNetworkDNSFault(ctx context.Context, taskMetadata *state.TaskResponse, request types.NetworkDNSRequest) error {
+	nsenterPrefix := dnsFaultNsenterPrefix(taskMetadata)
+	nxdomain := aws.ToString(request.FailureMode) == types.DNSFailureModeNXDOMAIN
+	if nxdomain {
+		if err := h.dnsResponder.Start(dnsResponderNetNS(taskMetadata)); err != nil {
+			logger.Error("Failed to start the DNS responder", logger.Fields{
+				field.TaskARN: taskMetadata.TaskARN,
+				field.Error:   err,
+			})
+			return err
+		}
+	}
+
+	if err := h.runCommands(ctx, []string{
+		nsenterPrefix + fmt.Sprintf(iptablesNewChainCmd, requestTimeoutSeconds, dnsFaultChain),
+	}, taskMetadata.TaskARN); err != nil {
+		if nxdomain {
+			h.dnsResponder.Stop(dnsResponderNetNS(taskMetadata))
+		}
+		return err
+	}
+	var commands []string
+	if nxdomain {
+		commands = append(commands,
+			nsenterPrefix+fmt.Sprintf(iptablesNATNewChainCmd, requestTimeoutSeconds, dnsFaultChain))
+	}
+	for _, domain := range request.Domains {
+		encodedDomain := encodeDNSName(aws.ToString(domain))
+		if nxdomain {
+			// A client reusing its UDP socket keeps being redirected until the connection tracking entry of the
+			// socket expires, even for the queries of other domains.
+			commands = append(commands,
+				nsenterPrefix+fmt.Sprintf(iptablesNATRedirectDNSRuleCmd,
+					requestTimeoutSeconds, dnsFaultChain, dnsPort, encodedDomain, dnsResponderPort),
+				nsenterPrefix+fmt.Sprintf(iptablesAppendDNSChainRuleCmd,
+					requestTimeoutSeconds, dnsFaultChain, "tcp", dnsPort, encodedDomain, tcpResetTarget))
+			continue
+		}
+		for _, protocol := range []string{"udp", "tcp"} {
+			commands = append(commands, nsenterPrefix+fmt.Sprintf(iptablesAppendDNSChainRuleCmd,
+				requestTimeoutSeconds, dnsFaultChain, protocol, dnsPort, encodedDomain, dropTarget))
+		}
+	}
+	if nxdomain {
+		commands = append(commands,
+			nsenterPrefix+fmt.Sprintf(iptablesNATInsertChainCmd, requestTimeoutSeconds, "OUTPUT", dnsFaultChain))
+	}
+	commands = append(commands,
+		nsenterPrefix+fmt.Sprintf(iptablesInsertChainCmd, requestTimeoutSeconds, "OUTPUT", dnsFaultChain))
+	if err := h.runCommands(ctx, commands, taskMetadata.TaskARN); err != nil {
+		h.rollbackNetworkDNSFault(taskMetadata, nxdomain)
+		return err
+	}
+	return nil
+}
+
+// rollbackNetworkDNSFault deletes the dns-fault chains of a fault that failed to start. The chain of the
+// filter table isn't inserted into the OUTPUT table yet at that point, since it's the last step, but the
+// chain of the nat table may be. A new context is used since the context of the request may have expired.
+func (h *FaultHandler) rollbackNetworkDNSFault(taskMetadata *state.TaskResponse, nxdomain bool) {
+	ctx, cancel := h.osExecWrapper.NewExecContextWithTimeout(context.Background(), requestTimeoutSeconds*time.Second)
+	defer cancel()
+	nsenterPrefix := dnsFaultNsenterPrefix(taskMetadata)
+	if err := h.runCommands(ctx, []string{
+		nsenterPrefix + fmt.Sprintf(iptablesClearChainCmd, requestTimeoutSeconds, dnsFaultChain),
+		nsenterPrefix + fmt.Sprintf(iptablesDeleteChainCmd, requestTimeoutSeconds, dnsFaultChain),
+	}, taskMetadata.TaskARN); err != nil {
+		logger.Error("Failed to roll back the network DNS fault", logger.Fields{
+			field.TaskARN: taskMetadata.TaskARN,
+			field.Error:   err,
+		})
+	}
+	if !nxdomain {
+		return
+	}
+	// The commands are run independently, since the chain of the nat table may not be created or inserted
+	// into the OUTPUT table yet.
+	for _, command := range []string{
+		nsenterPrefix + fmt.Sprintf(iptablesNATDeleteFromTableCmd, requestTimeoutSeconds, "OUTPUT", dnsFaultChain),
+		nsenterPrefix + fmt.Sprintf(iptablesNATClearChainCmd, requestTimeoutSeconds, dnsFaultChain),
+		nsenterPrefix + fmt.Sprintf(iptablesNATDeleteChainCmd, requestTimeoutSeconds, dnsFaultChain),
+	} {
+		h.runCommands(ctx, []string{command}, taskMetadata.TaskARN)
+	}
+	h.dnsResponder.Stop(dnsResponderNetNS(taskMetadata))
+}
+
+// stopNetworkDNSFault removes the dns-fault chains from the built-in OUTPUT tables and deletes them. The
+// chain of the nat table only exists for the nxdomain failure mode, along with the DNS responder.
+func (h *FaultHandler) stopNetworkDNSFault(ctx context.Context, taskMetadata *state.TaskResponse) error {
+	nsenterPrefix := dnsFaultNsenterPrefix(taskMetadata)
+	if err := h.runCommands(ctx, []string{
+		nsenterPrefix + fmt.Sprintf(iptablesClearChainCmd, requestTimeoutSeconds, dnsFaultChain),
+		nsenterPrefix + fmt.Sprintf(iptablesDeleteFromTableCmd, requestTimeoutSeconds, "OUTPUT", dnsFaultChain),
+		nsenterPrefix + fmt.Sprintf(iptablesDeleteChainCmd, requestTimeoutSeconds, dnsFaultChain),
+	}, taskMetadata.TaskARN); err != nil {
+		return err
+	}
+	natChainExists, err := h.dnsFaultChainExists(ctx, taskMetadata, iptablesNATListChainCmd)
+	if err != nil {
+		return err
+	}
+	if natChainExists {
+		if err := h.runCommands(ctx, []string{
+			nsenterPrefix + fmt.Sprintf(iptablesNATClearChainCmd, requestTimeoutSeconds, dnsFaultChain),
+			nsenterPrefix + fmt.Sprintf(iptablesNATDeleteFromTableCmd, requestTimeoutSeconds, "OUTPUT", dnsFaultChain),
+			nsenterPrefix + fmt.Sprintf(iptablesNATDeleteChainCmd, requestTimeoutSeconds, dnsFaultChain),
+		}, taskMetadata.TaskARN); err != nil {
+			return err
+		}
+	}
+	h.dnsResponder.Stop(dnsResponderNetNS(taskMetadata))
+	return nil
+}
+
+// checkNetworkDNSFault checks if there's existing network-dns fault running, which is the case when the
+// dns-fault chain exists. It does so by calling `iptables -L dns-fault -n`.
+func (h *FaultHandler) checkNetworkDNSFault(ctx context.Context, taskMetadata *state.TaskResponse) (bool, error) {
+	return h.dnsFaultChainExists(ctx, taskMetadata, iptablesListChainCmd)
+}
+
+// dnsFaultChainExists checks if the dns-fault chain exists by listing it with the given command.
+func (h *FaultHandler) dnsFaultChainExists(ctx context.Context, taskMetadata *state.TaskResponse, listChainCmd string) (bool, error) {
+	cmdString := dnsFaultNsenterPrefix(taskMetadata) + fmt.Sprintf(listChainCmd, requestTimeoutSeconds, dnsFaultChain)
+	cmdOutput, err := h.runExecCommand(ctx, strings.Split(cmdString, " "))
+	if err != nil {
+		if exitErr, eok := h.osExecWrapper.ConvertToExitError(err); eok {
+			logger.Info("DNS fault chain not found", logger.Fields{
+				field.CommandString: cmdString,
+				field.CommandOutput: string(cmdOutput[:]),
+				field.TaskARN:       taskMetadata.TaskARN,
+				"exitCode":          h.osExecWrapper.GetExitCode(exitErr),
+			})
+			return false, nil
+		}
+		logger.Error("Command execution failed", logger.Fields{
+			field.CommandString: cmdString,
+			field.Error:         err,
+			field.CommandOutput: string(cmdOutput[:]),
+			field.TaskARN:       taskMetadata.TaskARN,
+		})
+		return false, fmt.Errorf("failed to check existing network fault: '%s' command failed with the following error: '%s'. std output: '%s'. TaskArn: %s",
+			cmdString, err, string(cmdOutput[:]), taskMetadata.TaskARN)
+	}
+	logger.Info("DNS fault chain found", logger.Fields{
+		field.CommandString: cmdString,
+		field.CommandOutput: string(cmdOutput[:]),
+		field.TaskARN:       taskMetadata.TaskARN,
+	})
+	return true, nil
+}
+
+// dnsFaultNsenterPrefix returns the nsenter prefix needed to run commands in the task network namespace.
+// For host mode, the task network namespace is the host network namespace (i.e. we don't need to run nsenter).
+func dnsFaultNsenterPrefix(taskMetadata *state.TaskResponse) string {
+	if ecstypes.NetworkMode(taskMetadata.TaskNetworkConfig.NetworkMode) == ecstypes.NetworkModeAwsvpc {
+		return fmt.Sprintf(nsenterCommandString, taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path)
+	}
+	return ""
+}
+
+// dnsResponderNetNS returns the network namespace path in which the DNS responder of the task listens, which
+// is empty for host mode.
+func dnsResponderNetNS(taskMetadata *state.TaskResponse) string {
+	if ecstypes.NetworkMode(taskMetadata.TaskNetworkConfig.NetworkMode) == ecstypes.NetworkModeAwsvpc {
+		return taskMetadata.TaskNetworkConfig.NetworkNamespaces[0].Path
+	}
+	return ""
+}
+
+// encodeDNSName returns the domain name in the wire format used by DNS queries, as an iptables hex string.
+// For example "example.com" is encoded as "|07|example|03|com|00|". Since the encoded name ends with the
+// root label, it matches queries for the domain and for all of its subdomains.
+func encodeDNSName(domain string) string {
+	var sb strings.Builder
+	for _, label := range strings.Split(strings.TrimSuffix(domain, "."), ".") {
+		fmt.Fprintf(&sb, "|%02x|%s", len(label), label)
+	}
+	sb.WriteString("|00|")
+	return sb.String()
+}
+
+// runCommands runs the commands in order and stops at the first one that fails.
+func (h *FaultHandler) runCommands(ctx context.Context, commands []string, taskArn string) error {
+	for _, commandComposed := range commands {
+		cmdOutput, err := h.runExecCommand(ctx, strings.Split(commandComposed, " "))
+		if err != nil {
+			logger.Error("Command execution failed", logger.Fields{
+				field.CommandString: commandComposed,
+				field.Error:         err,
+				field.CommandOutput: string(cmdOutput[:]),
+				field.TaskARN:       taskArn,
+			})
+			return err
+		}
+		logger.Info("Command execution completed", logger.Fields{
+			field.CommandString: commandComposed,
+			field.CommandOutput: string(cmdOutput[:]),
+		})
+	}
+	return nil
+}
+
 func (h *FaultHandler) addIPAddressesToFilter(
 	ctx context.Context, ipAddressList []*string, taskMetadata *state.TaskResponse,
 	nsenterPrefix, commandString, interfaceName string) error {
@@ -56,10 +56,14 @@ const (
 	deviceName         = "eth0"
 	invalidNetworkMode = "invalid"
 	// Fault injection tooling errors output
-	iptablesChainNotFoundError        = "iptables: Bad rule (does a matching rule exist in that chain?)."
-	tcLatencyFaultExistsCommandOutput = `[{"kind":"netem","handle":"10:","parent":"1:1","options":{"limit":1000,"delay":{"delay":123456789,"jitter":4567,"correlation":0},"ecn":false,"gap":0}}]`
-	tcLossFaultExistsCommandOutput    = `[{"kind":"netem","handle":"10:","dev":"eth0","parent":"1:1","options":{"limit":1000,"loss-random":{"loss":0.06,"correlation":0},"ecn":false,"gap":0}}]`
-	tcCommandEmptyOutput              = `[]`
+	iptablesChainNotFoundError          = "iptables: Bad rule (does a matching rule exist in that chain?)."
+	tcLatencyFaultExistsCommandOutput   = `[{"kind":"netem","handle":"10:","parent":"1:1","options":{"limit":1000,"delay":{"delay":123456789,"jitter":4567,"correlation":0},"ecn":false,"gap":0}}]`
+	tcLossFaultExistsCommandOutput      = `[{"kind":"netem","handle":"10:","dev":"eth0","parent":"1:1","options":{"limit":1000,"loss-random":{"loss":0.06,"correlation":0},"ecn":false,"gap":0}}]`
+	tcBandwidthFaultExistsCommandOutput = `[{"kind":"tbf","handle":"10:","parent":"1:1","options":{"rate":125000,"burst":32768,"lat":100000}}]`
+	tcCommandEmptyOutput                = `[]`
+	iptablesChainNotExistError          = "iptables: No chain/target/match by that name."
+	rateKbps                            = 1000
+	dnsDomain                           = "example.com"
 	// Common Fault injection JSON responses
 	happyFaultRunningResponse    = `{"Status":"running"}`
 	happyFaultStoppedResponse    = `{"Status":"stopped"}`
@@ -128,6 +132,17 @@ var (
 		"SourcesToFilter": ipSourcesToFilter,
 	}
 
+	happyNetworkBandwidthReqBody = map[string]interface{}{
+		"RateKbps":        rateKbps,
+		"Sources":         ipSources,
+		"SourcesToFilter": ipSourcesToFilter,
+	}
+
+	happyNetworkDNSReqBody = map[string]interface{}{
+		"Domains":     []string{dnsDomain},
+		"FailureMode": types.DNSFailureModeTimeout,
+	}
+
 	ipSources = []string{"52.95.154.1", "52.95.154.2"}
 
 	ipSourcesToFilter = []string{"8.8.8.8"}
@@ -141,6 +156,8 @@ var (
 	startNetworkPacketLossTestPrefix    = fmt.Sprintf(startFaultRequestType, types.PacketLossFaultType)
 	stopNetworkPacketLossTestPrefix     = fmt.Sprintf(stopFaultRequestType, types.PacketLossFaultType)
 	checkNetworkPacketLossTestPrefix    = fmt.Sprintf(checkStatusFaultRequestType, types.PacketLossFaultType)
+	startNetworkBandwidthTestPrefix     = fmt.Sprintf(startFaultRequestType, types.BandwidthFaultType)
+	startNetworkDNSTestPrefix           = fmt.Sprintf(startFaultRequestType, types.DNSFaultType)
 
 	ctxTimeoutDuration = requestTimeoutSeconds * time.Second
 
@@ -157,6 +174,33 @@ type networkFaultInjectionTestCase struct {
 	setAgentStateExpectations func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient)
 	setExecExpectations       func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller)
 	expectedResponseJSON      string
+	// dnsResponder replaces the DNS responder of the handler if set, expectedDNSResponders are the network
+	// namespace paths in which it is expected to be running once the request is handled.
+	dnsResponder          *fakeDNSResponder
+	expectedDNSResponders []string
+}
+
+// fakeDNSResponder records the network namespaces in which the DNS responder is running.
+type fakeDNSResponder struct {
+	startErr error
+	running  []string
+}
+
+func (r *fakeDNSResponder) Start(netNSPath string) error {
+	if r.startErr != nil {
+		return r.startErr
+	}
+	r.running = append(r.running, netNSPath)
+	return nil
+}
+
+func (r *fakeDNSResponder) Stop(netNSPath string) {
+	for i, path := range r.running {
+		if path == netNSPath {
+			r.running = append(r.running[:i], r.running[i+1:]...)
+			return
+		}
+	}
 }
 
 // Tests the path for Fault Network Faults API
@@ -172,14 +216,26 @@ func TestFaultLatencyFaultPath(t *testing.T) {
 	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-latency/status", NetworkFaultPath(types.LatencyFaultType, types.CheckNetworkFaultPostfix))
 }
 
+func TestFaultBandwidthFaultPath(t *testing.T) {
+	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-bandwidth/start", NetworkFaultPath(types.BandwidthFaultType, types.StartNetworkFaultPostfix))
+	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-bandwidth/stop", NetworkFaultPath(types.BandwidthFaultType, types.StopNetworkFaultPostfix))
+	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-bandwidth/status", NetworkFaultPath(types.BandwidthFaultType, types.CheckNetworkFaultPostfix))
+}
+
+func TestFaultDNSFaultPath(t *testing.T) {
+	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-dns/start", NetworkFaultPath(types.DNSFaultType, types.StartNetworkFaultPostfix))
+	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-dns/stop", NetworkFaultPath(types.DNSFaultType, types.StopNetworkFaultPostfix))
+	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-dns/status", NetworkFaultPath(types.DNSFaultType, types.CheckNetworkFaultPostfix))
+}
+
 func TestFaultPacketLossFaultPath(t *testing.T) {
 	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-packet-loss/start", NetworkFaultPath(types.PacketLossFaultType, types.StartNetworkFaultPostfix))
 	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-packet-loss/stop", NetworkFaultPath(types.PacketLossFaultType, types.StopNetworkFaultPostfix))
 	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/fault/v1/network-packet-loss/status", NetworkFaultPath(types.PacketLossFaultType, types.CheckNetworkFaultPostfix))
 }
 
-// testNetworkFaultInjectionCommon will be used by unit tests for all 9 fault injection Network Fault APIs.
-// Unit tests for all 9 APIs interact with the TMDS server and share similar logic.
+// testNetworkFaultInjectionCommon will be used by unit tests for all 15 fault injection Network Fault APIs.
+// Unit tests for all 15 APIs interact with the TMDS server and share similar logic.
 // Thus, use a shared base method to reduce duplicated code.
 func testNetworkFaultInjectionCommon(t *testing.T,
 	tcs []networkFaultInjectionTestCase, tmdsEndpoint string) {
@@ -194,7 +250,11 @@ func testNetworkFaultInjectionCommon(t *testing.T,
 
 			router := mux.NewRouter()
 			mockExec := mock_execwrapper.NewMockExec(ctrl)
-			handler := New(agentState, metricsFactory, mockExec)
+			var opts []Option
+			if tc.dnsResponder != nil {
+				opts = append(opts, withDNSResponder(tc.dnsResponder))
+			}
+			handler := New(agentState, metricsFactory, mockExec, opts...)
 			networkConfigClient := netconfig.NewNetworkConfigClient()
 
 			if tc.setAgentStateExpectations != nil {
@@ -234,6 +294,24 @@ func testNetworkFaultInjectionCommon(t *testing.T,
 			case NetworkFaultPath(types.PacketLossFaultType, types.CheckNetworkFaultPostfix):
 				tmdsAPI = "/api/%s/fault/v1/network-packet-loss/status"
 				handleMethod = handler.CheckNetworkPacketLoss()
+			case NetworkFaultPath(types.BandwidthFaultType, types.StartNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-bandwidth/start"
+				handleMethod = handler.StartNetworkBandwidth()
+			case NetworkFaultPath(types.BandwidthFaultType, types.StopNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-bandwidth/stop"
+				handleMethod = handler.StopNetworkBandwidth()
+			case NetworkFaultPath(types.BandwidthFaultType, types.CheckNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-bandwidth/status"
+				handleMethod = handler.CheckNetworkBandwidth()
+			case NetworkFaultPath(types.DNSFaultType, types.StartNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-dns/start"
+				handleMethod = handler.StartNetworkDNS()
+			case NetworkFaultPath(types.DNSFaultType, types.StopNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-dns/stop"
+				handleMethod = handler.StopNetworkDNS()
+			case NetworkFaultPath(types.DNSFaultType, types.CheckNetworkFaultPostfix):
+				tmdsAPI = "/api/%s/fault/v1/network-dns/status"
+				handleMethod = handler.CheckNetworkDNS()
 			default:
 				t.Error("Unrecognized TMDS Endpoint")
 			}
@@ -265,7 +343,9 @@ func testNetworkFaultInjectionCommon(t *testing.T,
 
 			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
 			assert.Equal(t, tc.expectedResponseBody, actualResponseBody)
-
+			if tc.dnsResponder != nil {
+				assert.Equal(t, tc.expectedDNSResponders, tc.dnsResponder.running)
+			}
 		})
 	}
 }
@@ -1315,6 +1395,23 @@ func generateStartNetworkLatencyTestCases() []networkFaultInjectionTestCase {
 			},
 			expectedResponseJSON: fmt.Sprintf(errorResponse, packetLossFaultAlreadyRunningError),
 		},
+		{
+			name:                 "existing-network-bandwidth-fault",
+			expectedStatusCode:   409,
+			requestBody:          happyNetworkLatencyReqBody,
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(bandwidthFaultAlreadyRunningError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcBandwidthFaultExistsCommandOutput), nil)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, bandwidthFaultAlreadyRunningError),
+		},
 		{
 			name:               "unknown-request-body-no-existing-fault",
 			expectedStatusCode: 200,
@@ -2297,3 +2394,632 @@ func TestCheckNetworkPacketLoss(t *testing.T) {
 	tcs := generateCheckNetworkPacketLossTestCases()
 	testNetworkFaultInjectionCommon(t, tcs, NetworkFaultPath(types.PacketLossFaultType, types.CheckNetworkFaultPostfix))
 }
+
+func generateStartNetworkBandwidthTestCases() []networkFaultInjectionTestCase {
+	return []networkFaultInjectionTestCase{
+		{
+			name:                 "no-existing-fault",
+			expectedStatusCode:   200,
+			requestBody:          happyNetworkBandwidthReqBody,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("running"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcCommandEmptyOutput), nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "tc", "qdisc", "add", "dev", deviceName,
+						"root", "handle", "1:", "prio", "priomap", "2", "2", "2", "2", "2", "2", "2", "2", "2", "2", "2", "2", "2", "2", "2", "2").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "tc", "qdisc", "add", "dev", deviceName,
+						"parent", "1:1", "handle", "10:", "tbf", "rate", "1000kbit", "burst", "32kb", "latency", "100ms").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+				)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(3).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(3).Return([]byte{}, nil)
+			},
+			expectedResponseJSON: happyFaultRunningResponse,
+		},
+		{
+			name:                 "existing-network-bandwidth-fault",
+			expectedStatusCode:   409,
+			requestBody:          happyNetworkBandwidthReqBody,
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(bandwidthFaultAlreadyRunningError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcBandwidthFaultExistsCommandOutput), nil)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, bandwidthFaultAlreadyRunningError),
+		},
+		{
+			name:                 "existing-network-latency-fault",
+			expectedStatusCode:   409,
+			requestBody:          happyNetworkBandwidthReqBody,
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(latencyFaultAlreadyRunningError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcLatencyFaultExistsCommandOutput), nil)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, latencyFaultAlreadyRunningError),
+		},
+		{
+			name:               fmt.Sprintf("%s invalid rate", startNetworkBandwidthTestPrefix),
+			expectedStatusCode: 400,
+			requestBody: map[string]interface{}{
+				"RateKbps": 0,
+				"Sources":  ipSources,
+			},
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(types.InvalidValueError, "0", "RateKbps")),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Times(0)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, fmt.Sprintf(types.InvalidValueError, "0", "RateKbps")),
+		},
+		{
+			name:               fmt.Sprintf("%s missing sources", startNetworkBandwidthTestPrefix),
+			expectedStatusCode: 400,
+			requestBody: map[string]interface{}{
+				"RateKbps": rateKbps,
+			},
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(types.MissingRequiredFieldError, "Sources")),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Times(0)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, fmt.Sprintf(types.MissingRequiredFieldError, "Sources")),
+		},
+	}
+}
+
+func generateStopNetworkBandwidthTestCases() []networkFaultInjectionTestCase {
+	return []networkFaultInjectionTestCase{
+		{
+			name:                 "existing-network-bandwidth-fault",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("stopped"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcBandwidthFaultExistsCommandOutput), nil),
+				)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(2).Return([]byte(tcCommandEmptyOutput), nil)
+			},
+			expectedResponseJSON: happyFaultStoppedResponse,
+		},
+		{
+			name:                 "existing-network-latency-fault",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("stopped"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcLatencyFaultExistsCommandOutput), nil),
+				)
+			},
+			expectedResponseJSON: happyFaultStoppedResponse,
+		},
+	}
+}
+
+func generateCheckNetworkBandwidthTestCases() []networkFaultInjectionTestCase {
+	return []networkFaultInjectionTestCase{
+		{
+			name:                 "existing-network-bandwidth-fault",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("running"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcBandwidthFaultExistsCommandOutput), nil)
+			},
+			expectedResponseJSON: happyFaultRunningResponse,
+		},
+		{
+			name:                 "no-existing-fault",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("not-running"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(tcCommandEmptyOutput), nil)
+			},
+			expectedResponseJSON: happyFaultNotRunningResponse,
+		},
+	}
+}
+
+func TestStartNetworkBandwidth(t *testing.T) {
+	tcs := generateStartNetworkBandwidthTestCases()
+	testNetworkFaultInjectionCommon(t, tcs, NetworkFaultPath(types.BandwidthFaultType, types.StartNetworkFaultPostfix))
+}
+
+func TestStopNetworkBandwidth(t *testing.T) {
+	tcs := generateStopNetworkBandwidthTestCases()
+	testNetworkFaultInjectionCommon(t, tcs, NetworkFaultPath(types.BandwidthFaultType, types.StopNetworkFaultPostfix))
+}
+
+func TestCheckNetworkBandwidth(t *testing.T) {
+	tcs := generateCheckNetworkBandwidthTestCases()
+	testNetworkFaultInjectionCommon(t, tcs, NetworkFaultPath(types.BandwidthFaultType, types.CheckNetworkFaultPostfix))
+}
+
+func generateStartNetworkDNSTestCases() []networkFaultInjectionTestCase {
+	return []networkFaultInjectionTestCase{
+		{
+			name:                 "no-existing-fault",
+			expectedStatusCode:   200,
+			requestBody:          happyNetworkDNSReqBody,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("running"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-L", dnsFaultChain, "-n").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1")),
+					exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+					exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-N", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-A", dnsFaultChain,
+						"-p", "udp", "--dport", "53", "-m", "string", "--algo", "bm", "--icase", "--hex-string", "|07|example|03|com|00|", "-j", "DROP").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-A", dnsFaultChain,
+						"-p", "tcp", "--dport", "53", "-m", "string", "--algo", "bm", "--icase", "--hex-string", "|07|example|03|com|00|", "-j", "DROP").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-I", "OUTPUT", "-j", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+				)
+			},
+			expectedResponseJSON: happyFaultRunningResponse,
+		},
+		{
+			name:               "nxdomain-failure-mode",
+			expectedStatusCode: 200,
+			requestBody: map[string]interface{}{
+				"Domains":     []string{dnsDomain},
+				"FailureMode": types.DNSFailureModeNXDOMAIN,
+			},
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("running"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1")),
+					exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+					exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-N", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-N", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-A", dnsFaultChain,
+						"-p", "udp", "--dport", "53", "-m", "string", "--algo", "bm", "--icase", "--hex-string", "|07|example|03|com|00|",
+						"-j", "REDIRECT", "--to-ports", "51653").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-A", dnsFaultChain,
+						"-p", "tcp", "--dport", "53", "-m", "string", "--algo", "bm", "--icase", "--hex-string", "|07|example|03|com|00|",
+						"-j", "REJECT", "--reject-with", "tcp-reset").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-I", "OUTPUT", "-j", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-I", "OUTPUT", "-j", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+				)
+			},
+			expectedResponseJSON:  happyFaultRunningResponse,
+			dnsResponder:          &fakeDNSResponder{},
+			expectedDNSResponders: []string{"/some/path"},
+		},
+		{
+			name:               "nxdomain-failure-mode-failed-to-start-dns-responder",
+			expectedStatusCode: 500,
+			requestBody: map[string]interface{}{
+				"Domains":     []string{dnsDomain},
+				"FailureMode": types.DNSFailureModeNXDOMAIN,
+			},
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(internalError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1")),
+					exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+					exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+				)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, internalError),
+			dnsResponder:         &fakeDNSResponder{startErr: errors.New("address already in use")},
+		},
+		{
+			name:               "nxdomain-failure-mode-failed-to-insert-chain-rolls-back",
+			expectedStatusCode: 500,
+			requestBody: map[string]interface{}{
+				"Domains":     []string{dnsDomain},
+				"FailureMode": types.DNSFailureModeNXDOMAIN,
+			},
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(internalError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				rollbackCtx, rollbackCancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1")),
+					exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+					exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-I", "OUTPUT", "-j", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, errors.New("exit status 2")),
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(rollbackCtx, rollbackCancel),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-F", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-X", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-D", "OUTPUT", "-j", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-F", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-X", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+				)
+			},
+			expectedResponseJSON:  fmt.Sprintf(errorResponse, internalError),
+			dnsResponder:          &fakeDNSResponder{},
+			expectedDNSResponders: []string{},
+		},
+		{
+			name:                 "existing-network-dns-fault",
+			expectedStatusCode:   409,
+			requestBody:          happyNetworkDNSReqBody,
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(dnsFaultAlreadyRunningError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, dnsFaultAlreadyRunningError),
+		},
+		{
+			name:                 "failed-to-create-chain",
+			expectedStatusCode:   500,
+			requestBody:          happyNetworkDNSReqBody,
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(internalError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1")),
+					exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+					exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, errors.New("exit status 4")),
+				)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, internalError),
+		},
+		{
+			name:                 "failed-to-append-rule-rolls-back-chain",
+			expectedStatusCode:   500,
+			requestBody:          happyNetworkDNSReqBody,
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(internalError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				rollbackCtx, rollbackCancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1")),
+					exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+					exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-N", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, errors.New("exit status 2")),
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(rollbackCtx, rollbackCancel),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-F", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-X", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+				)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, internalError),
+		},
+		{
+			name:               fmt.Sprintf("%s invalid failure mode", startNetworkDNSTestPrefix),
+			expectedStatusCode: 400,
+			requestBody: map[string]interface{}{
+				"Domains":     []string{dnsDomain},
+				"FailureMode": "servfail",
+			},
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(types.InvalidValueError, "servfail", "FailureMode")),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Times(0)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, fmt.Sprintf(types.InvalidValueError, "servfail", "FailureMode")),
+		},
+		{
+			name:               fmt.Sprintf("%s missing domains", startNetworkDNSTestPrefix),
+			expectedStatusCode: 400,
+			requestBody: map[string]interface{}{
+				"FailureMode": types.DNSFailureModeTimeout,
+			},
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(fmt.Sprintf(types.MissingRequiredFieldError, "Domains")),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Times(0)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, fmt.Sprintf(types.MissingRequiredFieldError, "Domains")),
+		},
+	}
+}
+
+func generateStopNetworkDNSTestCases() []networkFaultInjectionTestCase {
+	return []networkFaultInjectionTestCase{
+		{
+			name:                 "existing-network-dns-fault",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("stopped"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-F", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-D", "OUTPUT", "-j", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-X", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-L", dnsFaultChain, "-n").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1")),
+					exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+					exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+				)
+			},
+			expectedResponseJSON: happyFaultStoppedResponse,
+		},
+		{
+			name:                 "existing-network-dns-fault-nxdomain-failure-mode",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("stopped"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-L", dnsFaultChain, "-n").
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-F", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-D", "OUTPUT", "-j", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+					exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/some/path", "iptables", "-w", "5", "-t", "nat", "-X", dnsFaultChain).
+						Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil),
+				)
+			},
+			expectedResponseJSON:  happyFaultStoppedResponse,
+			dnsResponder:          &fakeDNSResponder{running: []string{"/some/path"}},
+			expectedDNSResponders: []string{},
+		},
+		{
+			name:                 "no-existing-fault",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("stopped"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				gomock.InOrder(
+					exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel),
+					exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD),
+					mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1")),
+					exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true),
+					exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1),
+				)
+			},
+			expectedResponseJSON: happyFaultStoppedResponse,
+		},
+	}
+}
+
+func generateCheckNetworkDNSTestCases() []networkFaultInjectionTestCase {
+	return []networkFaultInjectionTestCase{
+		{
+			name:                 "existing-network-dns-fault",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("running"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, nil)
+			},
+			expectedResponseJSON: happyFaultRunningResponse,
+		},
+		{
+			name:                 "no-existing-fault",
+			expectedStatusCode:   200,
+			expectedResponseBody: types.NewNetworkFaultInjectionSuccessResponse("not-running"),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte(iptablesChainNotExistError), errors.New("exit status 1"))
+				exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, true)
+				exec.EXPECT().GetExitCode(gomock.Any()).Times(1).Return(1)
+			},
+			expectedResponseJSON: happyFaultNotRunningResponse,
+		},
+		{
+			name:                 "failed-to-check",
+			expectedStatusCode:   500,
+			expectedResponseBody: types.NewNetworkFaultInjectionErrorResponse(internalError),
+			setAgentStateExpectations: func(agentState *mock_state.MockAgentState, netConfigClient *netconfig.NetworkConfigClient) {
+				agentState.EXPECT().GetTaskMetadataWithTaskNetworkConfig(endpointId, netConfigClient).Return(happyTaskResponse, nil)
+			},
+			setExecExpectations: func(exec *mock_execwrapper.MockExec, ctrl *gomock.Controller) {
+				ctx, cancel := context.WithTimeout(context.Background(), ctxTimeoutDuration)
+				mockCMD := mock_execwrapper.NewMockCmd(ctrl)
+				exec.EXPECT().NewExecContextWithTimeout(gomock.Any(), gomock.Any()).Times(1).Return(ctx, cancel)
+				exec.EXPECT().CommandContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(mockCMD)
+				mockCMD.EXPECT().CombinedOutput().Times(1).Return([]byte{}, errors.New("signal: killed"))
+				exec.EXPECT().ConvertToExitError(gomock.Any()).Times(1).Return(nil, false)
+			},
+			expectedResponseJSON: fmt.Sprintf(errorResponse, internalError),
+		},
+	}
+}
+
+func TestStartNetworkDNS(t *testing.T) {
+	tcs := generateStartNetworkDNSTestCases()
+	testNetworkFaultInjectionCommon(t, tcs, NetworkFaultPath(types.DNSFaultType, types.StartNetworkFaultPostfix))
+}
+
+func TestStopNetworkDNS(t *testing.T) {
+	tcs := generateStopNetworkDNSTestCases()
+	testNetworkFaultInjectionCommon(t, tcs, NetworkFaultPath(types.DNSFaultType, types.StopNetworkFaultPostfix))
+}
+
+func TestCheckNetworkDNS(t *testing.T) {
+	tcs := generateCheckNetworkDNSTestCases()
+	testNetworkFaultInjectionCommon(t, tcs, NetworkFaultPath(types.DNSFaultType, types.CheckNetworkFaultPostfix))
+}
+
+func TestEncodeDNSName(t *testing.T) {
+	assert.Equal(t, "|07|example|03|com|00|", encodeDNSName("example.com"))
+	assert.Equal(t, "|07|example|03|com|00|", encodeDNSName("example.com."))
+	assert.Equal(t, "|03|api|0f|my-service-name|05|local|00|", encodeDNSName("api.my-service-name.local"))
+}
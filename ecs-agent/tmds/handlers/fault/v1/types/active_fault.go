@@ -0,0 +1,47 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	state "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
+)
+
+// ActiveFault is a fault that was started with a duration, or that needs the agent to keep running, such
+// as a network DNS fault in the nxdomain failure mode. It is stopped by the agent once it expires.
+type ActiveFault struct {
+	FaultType string
+	// ID distinguishes the faults of the same type that can run at the same time for a task, i.e. the
+	// chain name of network blackhole port faults.
+	ID                string `json:",omitempty"`
+	TaskARN           string
+	TaskNetworkConfig *state.TaskNetworkConfig
+	// Request is the request that started the fault, it has the parameters needed to stop it.
+	Request json.RawMessage
+	// ExpiresAt is zero if the fault was started without a duration.
+	ExpiresAt time.Time
+}
+
+// Key returns the key identifying the fault among the active faults.
+func (f *ActiveFault) Key() string {
+	return ActiveFaultKey(f.TaskARN, f.FaultType, f.ID)
+}
+
+// ActiveFaultKey returns the key identifying the fault of a task among the active faults.
+func ActiveFaultKey(taskARN, faultType, id string) string {
+	return fmt.Sprintf("%s|%s|%s", taskARN, faultType, id)
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
@@ -28,15 +29,24 @@ const (
 	BlackHolePortFaultType   = "network-blackhole-port"
 	LatencyFaultType         = "network-latency"
 	PacketLossFaultType      = "network-packet-loss"
+	DNSFaultType             = "network-dns"
+	BandwidthFaultType       = "network-bandwidth"
 	StartNetworkFaultPostfix = "start"
 	StopNetworkFaultPostfix  = "stop"
 	CheckNetworkFaultPostfix = "status"
 	TrafficTypeIngress       = "ingress"
 	TrafficTypeEgress        = "egress"
+	DNSFailureModeTimeout    = "timeout"
+	DNSFailureModeNXDOMAIN   = "nxdomain"
+	// MaxDurationSeconds is the longest duration a fault can be started for, 24 hours
+	MaxDurationSeconds = 24 * 60 * 60
 	// Request Payload Errors
 	MissingRequiredFieldError = "required parameter %s is missing"
 	MissingRequestBodyError   = "required request body is missing"
 	InvalidValueError         = "invalid value %s for parameter %s"
+
+	maxDomainLength      = 253
+	maxDomainLabelLength = 63
 )
 
 type NetworkFaultRequest interface {
@@ -51,11 +61,16 @@ type NetworkBlackholePortRequest struct {
 	// SourcesToFilter is a list including IPv4 addresses or IPv4 CIDR blocks that will be excluded
 	// from the fault.
 	SourcesToFilter []*string `json:"SourcesToFilter,omitempty"`
+	// DurationSeconds is optional. When set, the fault is stopped automatically once it has been running
+	// for that many seconds, up to MaxDurationSeconds.
+	DurationSeconds *uint64 `json:"DurationSeconds,omitempty"`
 }
 
 type NetworkFaultInjectionResponse struct {
 	Status string `json:"Status,omitempty"`
-	Error  string `json:"Error,omitempty"`
+	// RemainingSeconds is the time left until a running fault that was started with a duration expires.
+	RemainingSeconds *uint64 `json:"RemainingSeconds,omitempty"`
+	Error            string  `json:"Error,omitempty"`
 }
 
 func (request NetworkBlackholePortRequest) ValidateRequest() error {
@@ -80,6 +95,9 @@ func (request NetworkBlackholePortRequest) ValidateRequest() error {
 		return err
 	}
 
+	if err := validateDurationSeconds(request.DurationSeconds); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -121,6 +139,9 @@ type NetworkLatencyRequest struct {
 	// SourcesToFilter is a list including IPv4 addresses or IPv4 CIDR blocks that will be excluded from the
 	// network latency fault.
 	SourcesToFilter []*string `json:"SourcesToFilter,omitempty"`
+	// DurationSeconds is optional. When set, the fault is stopped automatically once it has been running
+	// for that many seconds, up to MaxDurationSeconds.
+	DurationSeconds *uint64 `json:"DurationSeconds,omitempty"`
 }
 
 // ValidateRequest validates required fields are present and its value.
@@ -140,6 +161,9 @@ func (request NetworkLatencyRequest) ValidateRequest() error {
 	if err := validateNetworkFaultRequestSources(request.SourcesToFilter, "SourcesToFilter"); err != nil {
 		return err
 	}
+	if err := validateDurationSeconds(request.DurationSeconds); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -159,6 +183,9 @@ type NetworkPacketLossRequest struct {
 	// SourcesToFilter is a list including IPv4 addresses or IPv4 CIDR blocks that will be excluded from the
 	// network packet loss fault.
 	SourcesToFilter []*string `json:"SourcesToFilter,omitempty"`
+	// DurationSeconds is optional. When set, the fault is stopped automatically once it has been running
+	// for that many seconds, up to MaxDurationSeconds.
+	DurationSeconds *uint64 `json:"DurationSeconds,omitempty"`
 }
 
 // ValidateRequest validates required fields are present and its value.
@@ -179,6 +206,9 @@ func (request NetworkPacketLossRequest) ValidateRequest() error {
 	if err := validateNetworkFaultRequestSources(request.SourcesToFilter, "SourcesToFilter"); err != nil {
 		return err
 	}
+	if err := validateDurationSeconds(request.DurationSeconds); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -190,6 +220,102 @@ func (request NetworkPacketLossRequest) ToString() string {
 	return string(data)
 }
 
+// NetworkDNSRequest is struct for the network DNS fault request.
+type NetworkDNSRequest struct {
+	// Domains is a list of domain names whose lookups will fail. Lookups of their subdomains fail as well.
+	Domains []*string `json:"Domains"`
+	// FailureMode is either "timeout", in which case the matching queries are dropped so that lookups time out,
+	// or "nxdomain", in which case the matching queries sent over UDP are answered by the agent with a
+	// non-existent domain (NXDOMAIN) response, and the ones sent over TCP are reset.
+	FailureMode *string `json:"FailureMode"`
+	// DurationSeconds is optional. When set, the fault is stopped automatically once it has been running
+	// for that many seconds, up to MaxDurationSeconds.
+	DurationSeconds *uint64 `json:"DurationSeconds,omitempty"`
+}
+
+// ValidateRequest validates required fields are present and its value.
+func (request NetworkDNSRequest) ValidateRequest() error {
+	if len(request.Domains) == 0 {
+		return fmt.Errorf(MissingRequiredFieldError, "Domains")
+	}
+	for _, domain := range request.Domains {
+		if err := validateDomain(aws.ToString(domain)); err != nil {
+			return err
+		}
+	}
+	if request.FailureMode == nil || *request.FailureMode == "" {
+		return fmt.Errorf(MissingRequiredFieldError, "FailureMode")
+	}
+	if *request.FailureMode != DNSFailureModeTimeout && *request.FailureMode != DNSFailureModeNXDOMAIN {
+		return fmt.Errorf(InvalidValueError, *request.FailureMode, "FailureMode")
+	}
+	if err := validateDurationSeconds(request.DurationSeconds); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (request NetworkDNSRequest) ToString() string {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Sprintf("Error: Unable to parse %s request with error %v.", DNSFaultType, err)
+	}
+	return string(data)
+}
+
+// NetworkBandwidthRequest is struct for the network bandwidth limit fault request.
+type NetworkBandwidthRequest struct {
+	RateKbps *uint64 `json:"RateKbps"`
+	// BurstKilobytes is the size of the token bucket. It is optional and defaults to DefaultBandwidthBurstKilobytes.
+	BurstKilobytes *uint64 `json:"BurstKilobytes,omitempty"`
+	// Sources is a list including IPv4 addresses or IPv4 CIDR blocks.
+	Sources []*string `json:"Sources"`
+	// SourcesToFilter is a list including IPv4 addresses or IPv4 CIDR blocks that will be excluded from the
+	// network bandwidth fault.
+	SourcesToFilter []*string `json:"SourcesToFilter,omitempty"`
+	// DurationSeconds is optional. When set, the fault is stopped automatically once it has been running
+	// for that many seconds, up to MaxDurationSeconds.
+	DurationSeconds *uint64 `json:"DurationSeconds,omitempty"`
+}
+
+// DefaultBandwidthBurstKilobytes is the token bucket size used when the bandwidth fault request doesn't
+// specify one. It is large enough for the bucket to refill at rates up to a few hundred Mbps.
+const DefaultBandwidthBurstKilobytes = 32
+
+// ValidateRequest validates required fields are present and its value.
+func (request NetworkBandwidthRequest) ValidateRequest() error {
+	if request.RateKbps == nil {
+		return fmt.Errorf(MissingRequiredFieldError, "RateKbps")
+	}
+	if *request.RateKbps == 0 {
+		return fmt.Errorf(InvalidValueError, strconv.FormatUint(*request.RateKbps, 10), "RateKbps")
+	}
+	if request.BurstKilobytes != nil && *request.BurstKilobytes == 0 {
+		return fmt.Errorf(InvalidValueError, strconv.FormatUint(*request.BurstKilobytes, 10), "BurstKilobytes")
+	}
+	if len(request.Sources) == 0 {
+		return fmt.Errorf(MissingRequiredFieldError, "Sources")
+	}
+	if err := validateNetworkFaultRequestSources(request.Sources, "Sources"); err != nil {
+		return err
+	}
+	if err := validateNetworkFaultRequestSources(request.SourcesToFilter, "SourcesToFilter"); err != nil {
+		return err
+	}
+	if err := validateDurationSeconds(request.DurationSeconds); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (request NetworkBandwidthRequest) ToString() string {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Sprintf("Error: Unable to parse %s request with error %v.", BandwidthFaultType, err)
+	}
+	return string(data)
+}
+
 func NewNetworkFaultInjectionSuccessResponse(status string) NetworkFaultInjectionResponse {
 	return NetworkFaultInjectionResponse{
 		Status: status,
@@ -202,6 +328,16 @@ func NewNetworkFaultInjectionErrorResponse(err string) NetworkFaultInjectionResp
 	}
 }
 
+func validateDurationSeconds(durationSeconds *uint64) error {
+	if durationSeconds == nil {
+		return nil
+	}
+	if *durationSeconds == 0 || *durationSeconds > MaxDurationSeconds {
+		return fmt.Errorf(InvalidValueError, strconv.FormatUint(*durationSeconds, 10), "DurationSeconds")
+	}
+	return nil
+}
+
 func validateNetworkFaultRequestSources(sources []*string, sourcesType string) error {
 	for _, element := range sources {
 		if err := validateNetworkFaultRequestSource(aws.ToString(element), sourcesType); err != nil {
@@ -230,3 +366,23 @@ func validateNetworkFaultRequestSource(source string, sourceType string) error {
 
 	return fmt.Errorf(InvalidValueError, source, sourceType)
 }
+
+// validateDomain checks that the domain is a valid DNS name, the labels of which will be matched
+// against the queries sent by the task.
+func validateDomain(domain string) error {
+	name := strings.TrimSuffix(domain, ".")
+	if name == "" || len(name) > maxDomainLength {
+		return fmt.Errorf(InvalidValueError, domain, "Domains")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > maxDomainLabelLength {
+			return fmt.Errorf(InvalidValueError, domain, "Domains")
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return fmt.Errorf(InvalidValueError, domain, "Domains")
+			}
+		}
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ package types
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -68,3 +69,60 @@ func TestValidateNetworkFaultRequestSources(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateDomain(t *testing.T) {
+	tcs := []struct {
+		Name          string
+		Input         string
+		ShouldSucceed bool
+	}{
+		{"domain", "example.com", true},
+		{"fully qualified domain", "example.com.", true},
+		{"subdomain", "api.my-service_1.example.com", true},
+		{"single label", "localhost", true},
+		{"empty input", "", false},
+		{"empty label", "example..com", false},
+		{"invalid character", "exa|mple.com", false},
+		{"label too long", strings.Repeat("a", 64) + ".com", false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := validateDomain(tc.Input)
+			if tc.ShouldSucceed {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, fmt.Sprintf("invalid value %s for parameter Domains", tc.Input))
+			}
+		})
+	}
+}
+
+func TestNetworkBandwidthRequestValidateRequest(t *testing.T) {
+	sources := aws.StringSlice([]string{"1.2.3.4"})
+	require.NoError(t, NetworkBandwidthRequest{RateKbps: aws.Uint64(100), Sources: sources}.ValidateRequest())
+	require.NoError(t, NetworkBandwidthRequest{RateKbps: aws.Uint64(100), BurstKilobytes: aws.Uint64(64),
+		Sources: sources}.ValidateRequest())
+	require.EqualError(t, NetworkBandwidthRequest{Sources: sources}.ValidateRequest(),
+		"required parameter RateKbps is missing")
+	require.EqualError(t, NetworkBandwidthRequest{RateKbps: aws.Uint64(0), Sources: sources}.ValidateRequest(),
+		"invalid value 0 for parameter RateKbps")
+	require.EqualError(t, NetworkBandwidthRequest{RateKbps: aws.Uint64(100), BurstKilobytes: aws.Uint64(0),
+		Sources: sources}.ValidateRequest(), "invalid value 0 for parameter BurstKilobytes")
+	require.EqualError(t, NetworkBandwidthRequest{RateKbps: aws.Uint64(100)}.ValidateRequest(),
+		"required parameter Sources is missing")
+}
+
+func TestValidateDurationSeconds(t *testing.T) {
+	request := NetworkPacketLossRequest{
+		LossPercent:     aws.Uint64(6),
+		Sources:         aws.StringSlice([]string{"1.2.3.4"}),
+		DurationSeconds: aws.Uint64(0),
+	}
+	require.EqualError(t, request.ValidateRequest(), "invalid value 0 for parameter DurationSeconds")
+	request.DurationSeconds = aws.Uint64(MaxDurationSeconds + 1)
+	require.EqualError(t, request.ValidateRequest(), "invalid value 86401 for parameter DurationSeconds")
+	request.DurationSeconds = aws.Uint64(30)
+	require.NoError(t, request.ValidateRequest())
+	request.DurationSeconds = aws.Uint64(MaxDurationSeconds)
+	require.NoError(t, request.ValidateRequest())
+}
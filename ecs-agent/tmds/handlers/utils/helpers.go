@@ -43,6 +43,9 @@ const (
 	// RequestTypeContainerStats specifies the container stats request type of StatsHandler.
 	RequestTypeContainerStats = "container stats"
 
+	// RequestTypeTaskStatsHistory specifies the task stats history request type of TaskStatsHistoryHandler.
+	RequestTypeTaskStatsHistory = "task stats history"
+
 	// RequestTypeAgentMetadata specifies the Agent metadata request type of AgentMetadataHandler.
 	RequestTypeAgentMetadata = "agent metadata"
 
@@ -16,6 +16,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
@@ -28,10 +31,16 @@ import (
 
 // v3EndpointIDMuxName is the key that's used in gorilla/mux to get the v3 endpoint ID.
 const (
-	EndpointContainerIDMuxName = "endpointContainerIDMuxName"
-	version                    = "v4"
-	containerStatsErrorPrefix  = "V4 container stats handler"
-	taskStatsErrorPrefix       = "V4 task stats handler"
+	EndpointContainerIDMuxName  = "endpointContainerIDMuxName"
+	version                     = "v4"
+	containerStatsErrorPrefix   = "V4 container stats handler"
+	taskStatsErrorPrefix        = "V4 task stats handler"
+	taskStatsHistoryErrorPrefix = "V4 task stats history handler"
+
+	// Query parameters of the task stats history endpoint.
+	statsHistorySummaryQueryParam     = "summary"
+	statsHistoryPercentilesQueryParam = "percentiles"
+	statsHistoryWindowQueryParam      = "window"
 )
 
 // ContainerMetadataPath specifies the relative URI path for serving container metadata.
@@ -65,6 +74,12 @@ func TaskStatsPath() string {
 		utils.ConstructMuxVar(EndpointContainerIDMuxName, utils.AnythingButSlashRegEx))
 }
 
+// Returns a standard URI path for v4 task stats history endpoint.
+func TaskStatsHistoryPath() string {
+	return fmt.Sprintf("/v4/%s/task/stats/history",
+		utils.ConstructMuxVar(EndpointContainerIDMuxName, utils.AnythingButSlashRegEx))
+}
+
 // ContainerMetadataHandler returns the HTTP handler function for handling container metadata requests.
 func ContainerMetadataHandler(
 	agentState state.AgentState,
@@ -267,3 +282,111 @@ func getStatsErrorResponse(endpointContainerID string, err error, errorPrefix st
 	})
 	return http.StatusInternalServerError, "failed to get stats"
 }
+
+// statsHistoryQuery holds the query parameters of a task stats history request.
+type statsHistoryQuery struct {
+	// summary is whether a summary of the samples is returned instead of the samples.
+	summary bool
+	// percentiles are the percentiles to add to the summary.
+	percentiles []float64
+	// window restricts the samples to the most recent ones when set.
+	window time.Duration
+}
+
+// parseStatsHistoryQuery parses the query parameters of a task stats history request. Asking
+// for percentiles implies a summary.
+func parseStatsHistoryQuery(r *http.Request) (statsHistoryQuery, error) {
+	var query statsHistoryQuery
+	values := r.URL.Query()
+	if summary := values.Get(statsHistorySummaryQueryParam); summary != "" {
+		var err error
+		if query.summary, err = strconv.ParseBool(summary); err != nil {
+			return query, fmt.Errorf("invalid value for %s query parameter: %s", statsHistorySummaryQueryParam, summary)
+		}
+	}
+	if percentiles := values.Get(statsHistoryPercentilesQueryParam); percentiles != "" {
+		for _, p := range strings.Split(percentiles, ",") {
+			percentile, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil || percentile <= 0 || percentile > 100 {
+				return query, fmt.Errorf("invalid value for %s query parameter: %s, expected values between 0 and 100",
+					statsHistoryPercentilesQueryParam, p)
+			}
+			query.percentiles = append(query.percentiles, percentile)
+		}
+		query.summary = true
+	}
+	if window := values.Get(statsHistoryWindowQueryParam); window != "" {
+		var err error
+		if query.window, err = time.ParseDuration(window); err != nil || query.window <= 0 {
+			return query, fmt.Errorf("invalid value for %s query parameter: %s, expected a positive duration such as 60s",
+				statsHistoryWindowQueryParam, window)
+		}
+	}
+	return query, nil
+}
+
+// Returns an HTTP handler for v4 task stats history endpoint. It responds with the stats samples
+// buffered for each container of the task, or with a summary of them.
+func TaskStatsHistoryHandler(
+	agentState state.AgentState,
+	metricsFactory metrics.EntryFactory,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		endpointContainerID := mux.Vars(r)[EndpointContainerIDMuxName]
+
+		query, err := parseStatsHistoryQuery(r)
+		if err != nil {
+			utils.WriteJSONResponse(w, http.StatusBadRequest,
+				fmt.Sprintf("%s: %s", taskStatsHistoryErrorPrefix, err), utils.RequestTypeTaskStatsHistory)
+			return
+		}
+
+		history, err := agentState.GetTaskStatsHistory(endpointContainerID)
+		if err != nil {
+			logger.Error("Failed to get v4 task stats history", logger.Fields{
+				field.TMDSEndpointContainerID: endpointContainerID,
+				field.Error:                   err,
+			})
+
+			responseCode, responseBody := getStatsErrorResponse(endpointContainerID, err, taskStatsHistoryErrorPrefix)
+			utils.WriteJSONResponse(w, responseCode, responseBody, utils.RequestTypeTaskStatsHistory)
+
+			if utils.Is5XXStatus(responseCode) {
+				metricsFactory.New(metrics.InternalServerErrorMetricName).Done(err)
+			}
+
+			return
+		}
+
+		now := time.Now()
+		resp := make(map[string]*state.StatsHistoryResponse, len(history))
+		for containerID, samples := range history {
+			if query.window > 0 {
+				samples = samplesSince(samples, now.Add(-query.window))
+			}
+			if query.summary {
+				resp[containerID] = &state.StatsHistoryResponse{
+					Summary: state.SummarizeStatsSamples(samples, query.percentiles),
+				}
+			} else {
+				resp[containerID] = &state.StatsHistoryResponse{Samples: samples}
+			}
+		}
+
+		logger.Info("Writing response for v4 task stats history", logger.Fields{
+			field.TMDSEndpointContainerID: endpointContainerID,
+		})
+		utils.WriteJSONResponse(w, http.StatusOK, resp, utils.RequestTypeTaskStatsHistory)
+	}
+}
+
+// samplesSince returns the samples taken at or after the given time.
+func samplesSince(samples []state.StatsSample, since time.Time) []state.StatsSample {
+	var recent []state.StatsSample
+	for _, sample := range samples {
+		if !sample.Timestamp.Before(since) {
+			recent = append(recent, sample)
+		}
+	}
+	return recent
+}
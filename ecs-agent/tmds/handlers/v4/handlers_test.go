@@ -537,12 +537,101 @@ func TestTaskStats(t *testing.T) {
 	})
 }
 
+func TestTaskStatsHistoryPath(t *testing.T) {
+	assert.Equal(t, "/v4/{endpointContainerIDMuxName:[^/]*}/task/stats/history", TaskStatsHistoryPath())
+}
+
+func TestTaskStatsHistory(t *testing.T) {
+	path := fmt.Sprintf("/v4/%s/task/stats/history", endpointContainerID)
+	now := time.Now().UTC().Truncate(time.Second)
+	samples := []state.StatsSample{
+		{Timestamp: now.Add(-2 * time.Minute), CPUUsagePercent: 90, MemoryUsageMiB: 300},
+		{Timestamp: now.Add(-20 * time.Second), CPUUsagePercent: 10, MemoryUsageMiB: 100},
+		{Timestamp: now.Add(-10 * time.Second), CPUUsagePercent: 30, MemoryUsageMiB: 200},
+	}
+	history := map[string][]state.StatsSample{containerID: samples}
+
+	setup := func(t *testing.T) (*mock_state.MockAgentState, *mock_metrics.MockEntryFactory, http.Handler) {
+		ctrl := gomock.NewController(t)
+		agentState := mock_state.NewMockAgentState(ctrl)
+		metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+		router := mux.NewRouter()
+		router.HandleFunc(TaskStatsHistoryPath(), TaskStatsHistoryHandler(agentState, metricsFactory))
+		return agentState, metricsFactory, router
+	}
+
+	t.Run("stats lookup failure", func(t *testing.T) {
+		agentState, _, handler := setup(t)
+		agentState.EXPECT().
+			GetTaskStatsHistory(endpointContainerID).
+			Return(nil, state.NewErrorStatsLookupFailure(externalReason))
+		testTMDSRequest(t, handler, TMDSTestCase[string]{
+			path:                 path,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: "V4 task stats history handler: " + externalReason,
+		})
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, _, handler := setup(t)
+		testTMDSRequest(t, handler, TMDSTestCase[string]{
+			path:               path + "?percentiles=101",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: "V4 task stats history handler: invalid value for percentiles query parameter: " +
+				"101, expected values between 0 and 100",
+		})
+	})
+
+	t.Run("samples within window", func(t *testing.T) {
+		agentState, _, handler := setup(t)
+		agentState.EXPECT().GetTaskStatsHistory(endpointContainerID).Return(history, nil)
+		testTMDSRequest(t, handler, TMDSTestCase[map[string]*state.StatsHistoryResponse]{
+			path:               path + "?window=1m",
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: map[string]*state.StatsHistoryResponse{
+				containerID: {Samples: samples[1:]},
+			},
+		})
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		agentState, _, handler := setup(t)
+		agentState.EXPECT().GetTaskStatsHistory(endpointContainerID).Return(history, nil)
+		testTMDSRequest(t, handler, TMDSTestCase[map[string]*state.StatsHistoryResponse]{
+			path:               path + "?percentiles=50,90",
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: map[string]*state.StatsHistoryResponse{
+				containerID: {
+					Summary: &state.StatsSummary{
+						SampleCount: 3,
+						From:        samples[0].Timestamp,
+						To:          samples[2].Timestamp,
+						CPUUsagePercent: state.MetricSummary{
+							Min:         10,
+							Max:         90,
+							Average:     130.0 / 3,
+							Percentiles: map[string]float64{"p50": 30, "p90": 90},
+						},
+						MemoryUsageMiB: state.MetricSummary{
+							Min:         100,
+							Max:         300,
+							Average:     200,
+							Percentiles: map[string]float64{"p50": 200, "p90": 300},
+						},
+					},
+				},
+			},
+		})
+	})
+}
+
 type TMDSResponse interface {
 	string |
 		state.ContainerResponse |
 		state.TaskResponse |
 		state.StatsResponse |
-		map[string]*state.StatsResponse
+		map[string]*state.StatsResponse |
+		map[string]*state.StatsHistoryResponse
 }
 
 type TMDSTestCase[R TMDSResponse] struct {
@@ -138,3 +138,18 @@ func (mr *MockAgentStateMockRecorder) GetTaskStats(arg0 interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskStats", reflect.TypeOf((*MockAgentState)(nil).GetTaskStats), arg0)
 }
+
+// GetTaskStatsHistory mocks base method.
+func (m *MockAgentState) GetTaskStatsHistory(arg0 string) (map[string][]state.StatsSample, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskStatsHistory", arg0)
+	ret0, _ := ret[0].(map[string][]state.StatsSample)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskStatsHistory indicates an expected call of GetTaskStatsHistory.
+func (mr *MockAgentStateMockRecorder) GetTaskStatsHistory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskStatsHistory", reflect.TypeOf((*MockAgentState)(nil).GetTaskStatsHistory), arg0)
+}
@@ -15,6 +15,7 @@ package state
 import (
 	"time"
 
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/egresspolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/response"
 	v2 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v2"
@@ -31,14 +32,15 @@ const (
 // with the v2 task response object.
 type TaskResponse struct {
 	*v2.TaskResponse
-	Containers              []ContainerResponse      `json:"Containers,omitempty"`
-	VPCID                   string                   `json:"VPCID,omitempty"`
-	ServiceName             string                   `json:"ServiceName,omitempty"`
-	ClockDrift              *ClockDrift              `json:"ClockDrift,omitempty"`
-	EphemeralStorageMetrics *EphemeralStorageMetrics `json:"EphemeralStorageMetrics,omitempty"`
-	CredentialsID           string                   `json:"-"`
-	TaskNetworkConfig       *TaskNetworkConfig       `json:"-"`
-	FaultInjectionEnabled   bool                     `json:"FaultInjectionEnabled"`
+	Containers              []ContainerResponse        `json:"Containers,omitempty"`
+	VPCID                   string                     `json:"VPCID,omitempty"`
+	ServiceName             string                     `json:"ServiceName,omitempty"`
+	ClockDrift              *ClockDrift                `json:"ClockDrift,omitempty"`
+	EphemeralStorageMetrics *EphemeralStorageMetrics   `json:"EphemeralStorageMetrics,omitempty"`
+	EgressPolicy            *egresspolicy.EgressPolicy `json:"EgressPolicy,omitempty"`
+	CredentialsID           string                     `json:"-"`
+	TaskNetworkConfig       *TaskNetworkConfig         `json:"-"`
+	FaultInjectionEnabled   bool                       `json:"FaultInjectionEnabled"`
 }
 
 // TaskNetworkConfig contains required network configurations for network faults injection.
@@ -95,6 +97,10 @@ type ContainerResponse struct {
 	Networks     []Network `json:"Networks,omitempty"`
 	Snapshotter  string    `json:"Snapshotter,omitempty"`
 	RestartCount *int      `json:"RestartCount,omitempty"`
+	// RestartBackoffSeconds is the delay applied before the next restart of the container.
+	RestartBackoffSeconds *int `json:"RestartBackoffSeconds,omitempty"`
+	// NextRestartAt is the time at which a restart delayed by the backoff is due.
+	NextRestartAt *time.Time `json:"NextRestartAt,omitempty"`
 }
 
 // Network is the v4 Network response. It adds a bunch of information about network
@@ -137,3 +143,43 @@ type StatsResponse struct {
 	*types.StatsJSON
 	Network_rate_stats *stats.NetworkStatsPerSec `json:"network_rate_stats,omitempty"`
 }
+
+// StatsSample is a sample of the utilization of a container, from the window of stats buffered by the agent.
+type StatsSample struct {
+	Timestamp         time.Time `json:"Timestamp"`
+	CPUUsagePercent   float64   `json:"CPUUsagePercent"`
+	MemoryUsageMiB    uint64    `json:"MemoryUsageMiB"`
+	StorageReadBytes  uint64    `json:"StorageReadBytes"`
+	StorageWriteBytes uint64    `json:"StorageWriteBytes"`
+	// The network rates are only available for containers that have their own network stats,
+	// i.e. not for containers of awsvpc tasks.
+	NetworkRxBytesPerSecond *float64 `json:"NetworkRxBytesPerSecond,omitempty"`
+	NetworkTxBytesPerSecond *float64 `json:"NetworkTxBytesPerSecond,omitempty"`
+}
+
+// StatsHistoryResponse is the v4 stats history response for a container. It has either the
+// buffered samples of the container or a summary of them.
+type StatsHistoryResponse struct {
+	Samples []StatsSample `json:"Samples,omitempty"`
+	Summary *StatsSummary `json:"Summary,omitempty"`
+}
+
+// StatsSummary summarizes the utilization of a container over a window of samples.
+type StatsSummary struct {
+	SampleCount             int            `json:"SampleCount"`
+	From                    time.Time      `json:"From"`
+	To                      time.Time      `json:"To"`
+	CPUUsagePercent         MetricSummary  `json:"CPUUsagePercent"`
+	MemoryUsageMiB          MetricSummary  `json:"MemoryUsageMiB"`
+	NetworkRxBytesPerSecond *MetricSummary `json:"NetworkRxBytesPerSecond,omitempty"`
+	NetworkTxBytesPerSecond *MetricSummary `json:"NetworkTxBytesPerSecond,omitempty"`
+}
+
+// MetricSummary has the statistics of a metric over a window of samples. Percentiles are keyed
+// by their name, e.g. "p90".
+type MetricSummary struct {
+	Min         float64            `json:"Min"`
+	Max         float64            `json:"Max"`
+	Average     float64            `json:"Average"`
+	Percentiles map[string]float64 `json:"Percentiles,omitempty"`
+}
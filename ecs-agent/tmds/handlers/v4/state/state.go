@@ -147,4 +147,10 @@ type AgentState interface {
 	// Returns ErrorStatsLookupFailure if container lookup fails.
 	// Returns ErrorStatsFetchFailure if something else goes wrong.
 	GetTaskStats(endpointContainerID string) (map[string]*StatsResponse, error)
+
+	// Returns the stats samples buffered for each container of the task identified by the
+	// provided endpointContainerID, keyed by container ID and ordered from the oldest.
+	// Returns ErrorStatsLookupFailure if container lookup fails.
+	// Returns ErrorStatsFetchFailure if something else goes wrong.
+	GetTaskStatsHistory(endpointContainerID string) (map[string][]StatsSample, error)
 }
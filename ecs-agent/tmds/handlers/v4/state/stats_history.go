@@ -0,0 +1,91 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package state
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// SummarizeStatsSamples returns the summary of the samples, with the given percentiles of each
+// metric. It returns nil if there are no samples.
+func SummarizeStatsSamples(samples []StatsSample, percentiles []float64) *StatsSummary {
+	if len(samples) == 0 {
+		return nil
+	}
+	cpu := make([]float64, 0, len(samples))
+	memory := make([]float64, 0, len(samples))
+	var rx, tx []float64
+	summary := &StatsSummary{
+		SampleCount: len(samples),
+		From:        samples[0].Timestamp,
+		To:          samples[0].Timestamp,
+	}
+	for _, sample := range samples {
+		if sample.Timestamp.Before(summary.From) {
+			summary.From = sample.Timestamp
+		}
+		if sample.Timestamp.After(summary.To) {
+			summary.To = sample.Timestamp
+		}
+		cpu = append(cpu, sample.CPUUsagePercent)
+		memory = append(memory, float64(sample.MemoryUsageMiB))
+		if sample.NetworkRxBytesPerSecond != nil {
+			rx = append(rx, *sample.NetworkRxBytesPerSecond)
+		}
+		if sample.NetworkTxBytesPerSecond != nil {
+			tx = append(tx, *sample.NetworkTxBytesPerSecond)
+		}
+	}
+	summary.CPUUsagePercent = *summarizeMetric(cpu, percentiles)
+	summary.MemoryUsageMiB = *summarizeMetric(memory, percentiles)
+	summary.NetworkRxBytesPerSecond = summarizeMetric(rx, percentiles)
+	summary.NetworkTxBytesPerSecond = summarizeMetric(tx, percentiles)
+	return summary
+}
+
+// summarizeMetric returns the statistics of the values, or nil if there are none.
+// Percentiles are computed with the nearest-rank method.
+func summarizeMetric(values []float64, percentiles []float64) *MetricSummary {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	summary := &MetricSummary{
+		Min:     sorted[0],
+		Max:     sorted[len(sorted)-1],
+		Average: sum / float64(len(sorted)),
+	}
+	if len(percentiles) > 0 {
+		summary.Percentiles = make(map[string]float64, len(percentiles))
+		for _, percentile := range percentiles {
+			rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+			if rank < 1 {
+				rank = 1
+			}
+			summary.Percentiles[percentileName(percentile)] = sorted[rank-1]
+		}
+	}
+	return summary
+}
+
+// percentileName returns the key of the percentile in MetricSummary.Percentiles, e.g. "p99.9".
+func percentileName(percentile float64) string {
+	return "p" + strconv.FormatFloat(percentile, 'f', -1, 64)
+}
@@ -0,0 +1,58 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeStatsSamples(t *testing.T) {
+	now := time.Now()
+	var samples []StatsSample
+	for i := 1; i <= 10; i++ {
+		samples = append(samples, StatsSample{
+			Timestamp:               now.Add(time.Duration(i) * time.Second),
+			CPUUsagePercent:         float64(i * 10),
+			MemoryUsageMiB:          uint64(i),
+			NetworkRxBytesPerSecond: aws.Float64(float64(i * 100)),
+		})
+	}
+
+	summary := SummarizeStatsSamples(samples, []float64{50, 99.9})
+	require.NotNil(t, summary)
+	assert.Equal(t, 10, summary.SampleCount)
+	assert.Equal(t, samples[0].Timestamp, summary.From)
+	assert.Equal(t, samples[9].Timestamp, summary.To)
+	assert.Equal(t, MetricSummary{
+		Min:         10,
+		Max:         100,
+		Average:     55,
+		Percentiles: map[string]float64{"p50": 50, "p99.9": 100},
+	}, summary.CPUUsagePercent)
+	assert.Equal(t, 5.5, summary.MemoryUsageMiB.Average)
+	require.NotNil(t, summary.NetworkRxBytesPerSecond)
+	assert.Equal(t, float64(1000), summary.NetworkRxBytesPerSecond.Max)
+	assert.Nil(t, summary.NetworkTxBytesPerSecond, "no samples have tx rates")
+}
+
+func TestSummarizeStatsSamplesEmpty(t *testing.T) {
+	assert.Nil(t, SummarizeStatsSamples(nil, []float64{90}))
+}
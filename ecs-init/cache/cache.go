@@ -312,6 +312,31 @@ func (d *Downloader) RecordCachedAgent() error {
 	return d.fs.WriteFile(config.CacheState(), data, orwPerm)
 }
 
+// SavePreviousAgent writes the image of the Agent loaded before an update to the cache, so
+// that the update can be rolled back
+func (d *Downloader) SavePreviousAgent(image io.Reader) error {
+	tempFile, err := d.fs.TempFile(config.CacheDirectory(), "ecs-agent-previous")
+	if err != nil {
+		return err
+	}
+	tempFileName := tempFile.Name()
+	_, err = d.fs.Copy(tempFile, image)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Debugf("Removing temp file %s", tempFileName)
+		d.fs.Remove(tempFileName)
+		return err
+	}
+	return d.fs.Rename(tempFileName, config.PreviousAgentTarball())
+}
+
+// LoadPreviousAgent returns an io.ReadCloser of the Agent image saved before the last update
+func (d *Downloader) LoadPreviousAgent() (io.ReadCloser, error) {
+	return d.fs.Open(config.PreviousAgentTarball())
+}
+
 // LoadDesiredAgent returns an io.ReadCloser of the Agent indicated by the desiredImageLocatorFile
 // (/var/cache/ecs/desired-image). The desiredImageLocatorFile must contain as the beginning of the file the name of
 // the file containing the desired image (interpreted as a basename) and ending in a newline.  Only the first line is
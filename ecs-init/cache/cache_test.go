@@ -481,3 +481,61 @@ func TestLoadDesiredAgent(t *testing.T) {
 
 	d.LoadDesiredAgent()
 }
+
+func TestSavePreviousAgent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	tempFile, err := os.CreateTemp(t.TempDir(), "ecs-agent-previous")
+	assert.NoError(t, err)
+
+	gomock.InOrder(
+		mockFS.EXPECT().TempFile(config.CacheDirectory(), "ecs-agent-previous").Return(tempFile, nil),
+		mockFS.EXPECT().Copy(tempFile, gomock.Any()).DoAndReturn(io.Copy),
+		mockFS.EXPECT().Rename(tempFile.Name(), config.PreviousAgentTarball()),
+	)
+
+	d := &Downloader{
+		fs: mockFS,
+	}
+	err = d.SavePreviousAgent(bytes.NewBufferString("previous-agent"))
+	assert.NoError(t, err)
+	data, err := os.ReadFile(tempFile.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, "previous-agent", string(data))
+}
+
+func TestSavePreviousAgentCopyFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	tempFile, err := os.CreateTemp(t.TempDir(), "ecs-agent-previous")
+	assert.NoError(t, err)
+
+	gomock.InOrder(
+		mockFS.EXPECT().TempFile(config.CacheDirectory(), "ecs-agent-previous").Return(tempFile, nil),
+		mockFS.EXPECT().Copy(tempFile, gomock.Any()).Return(int64(0), errors.New("test error")),
+		mockFS.EXPECT().Remove(tempFile.Name()),
+	)
+
+	d := &Downloader{
+		fs: mockFS,
+	}
+	err = d.SavePreviousAgent(bytes.NewBufferString("previous-agent"))
+	assert.Error(t, err, "Expect error to be returned when unable to write the previous agent")
+}
+
+func TestLoadPreviousAgent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockFS.EXPECT().Open(config.PreviousAgentTarball())
+
+	d := &Downloader{
+		fs: mockFS,
+	}
+	d.LoadPreviousAgent()
+}
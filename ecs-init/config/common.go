@@ -19,7 +19,9 @@ import (
 	"io"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-init/config/awsrulesfn"
 	"github.com/cihub/seelog"
@@ -81,6 +83,17 @@ const (
 	// set in agentLogDriverEnvVar
 	defaultLogDriver = "json-file"
 
+	// agentUpdateRollbackThresholdEnvVar is the environment variable that may be used to
+	// override the number of consecutive failed healthchecks of an updated Agent after
+	// which the Agent image loaded before the update is restored. 0 disables the rollback.
+	agentUpdateRollbackThresholdEnvVar = "ECS_INIT_UPDATE_ROLLBACK_THRESHOLD"
+	// defaultAgentUpdateRollbackThreshold is the default value of agentUpdateRollbackThresholdEnvVar
+	defaultAgentUpdateRollbackThreshold = 3
+
+	// secretRotationIntervalEnvVar is the environment variable that enables the secret files
+	// of the Agent, which are written to the tmpfs mounted at SecretFilesDirectory
+	secretRotationIntervalEnvVar = "ECS_SECRET_ROTATION_INTERVAL"
+
 	// GPUSupportEnvVar indicates that the AMI has support for GPU
 	GPUSupportEnvVar = "ECS_ENABLE_GPU_SUPPORT"
 
@@ -217,6 +230,12 @@ func AgentRemoteTarballMD5Key() (string, error) {
 	return tarballKey + ".md5", nil
 }
 
+// PreviousAgentTarball returns the location on disk of the Agent image that was loaded before
+// the last update, kept to roll the update back
+func PreviousAgentTarball() string {
+	return CacheDirectory() + "/ecs-agent-previous.tar"
+}
+
 // DesiredImageLocatorFile returns the location on disk of a well-known file describing an Agent image to load
 func DesiredImageLocatorFile() string {
 	return CacheDirectory() + "/desired-image"
@@ -343,6 +362,35 @@ func RunningInExternal() bool {
 	return envVar == "true"
 }
 
+// AgentUpdateRollbackThreshold returns the number of consecutive failed healthchecks of an
+// updated Agent after which the update is rolled back, or 0 if updates aren't rolled back
+func AgentUpdateRollbackThreshold() int {
+	envVar := strings.TrimSpace(os.Getenv(agentUpdateRollbackThresholdEnvVar))
+	if envVar == "" {
+		return defaultAgentUpdateRollbackThreshold
+	}
+	threshold, err := strconv.Atoi(envVar)
+	if err != nil || threshold < 0 {
+		seelog.Warnf("Input value for \"%s\" is not a non-negative integer, using the default of %d",
+			agentUpdateRollbackThresholdEnvVar, defaultAgentUpdateRollbackThreshold)
+		return defaultAgentUpdateRollbackThreshold
+	}
+	return threshold
+}
+
+// SecretFilesEnabled returns whether the Agent writes the secrets of containers to files,
+// which requires the tmpfs at SecretFilesDirectory
+func SecretFilesEnabled() bool {
+	interval, err := time.ParseDuration(strings.TrimSpace(os.Getenv(secretRotationIntervalEnvVar)))
+	return err == nil && interval > 0
+}
+
+// SecretFilesDirectory returns the location of the tmpfs the Agent writes the secret files of
+// containers to
+func SecretFilesDirectory() string {
+	return directoryPrefix + "/var/lib/ecs/secrets"
+}
+
 // ECSAgentApparmorProfileName returns the name of the AppArmor profile to use.
 func ECSAgentAppArmorProfileName() string {
 	envVar := os.Getenv(ECSAgentAppArmorProfileNameEnvVar)
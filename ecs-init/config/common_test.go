@@ -264,6 +264,38 @@ func TestAgentRunningInExternal(t *testing.T) {
 	assert.True(t, RunningInExternal())
 }
 
+func TestAgentUpdateRollbackThreshold(t *testing.T) {
+	defer os.Unsetenv(agentUpdateRollbackThresholdEnvVar)
+	cases := map[string]int{
+		"":        defaultAgentUpdateRollbackThreshold,
+		"5":       5,
+		" 1 ":     1,
+		"0":       0,
+		"-1":      defaultAgentUpdateRollbackThreshold,
+		"invalid": defaultAgentUpdateRollbackThreshold,
+	}
+	for value, expected := range cases {
+		os.Setenv(agentUpdateRollbackThresholdEnvVar, value)
+		assert.Equal(t, expected, AgentUpdateRollbackThreshold(), "Testcase (%s)", value)
+	}
+}
+
+func TestSecretFilesEnabled(t *testing.T) {
+	defer os.Unsetenv(secretRotationIntervalEnvVar)
+	cases := map[string]bool{
+		"":        false,
+		"15m":     true,
+		" 1h ":    true,
+		"0":       false,
+		"-1m":     false,
+		"invalid": false,
+	}
+	for value, expected := range cases {
+		os.Setenv(secretRotationIntervalEnvVar, value)
+		assert.Equal(t, expected, SecretFilesEnabled(), "Testcase (%s)", value)
+	}
+}
+
 func TestCredentialsFetcherUnixSocketWithoutCredentialsFetcherHost(t *testing.T) {
 	credentialsFetcherUnixSocketSourcePath := credentialsFetcherUnixSocket()
 
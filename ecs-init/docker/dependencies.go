@@ -47,7 +47,10 @@ type dockerclient interface {
 	StartContainer(id string, hostConfig *godocker.HostConfig) error
 	WaitContainer(id string) (int, error)
 	StopContainer(id string, timeout uint) error
+	InspectContainer(id string) (*godocker.Container, error)
+	ExportImage(opts godocker.ExportImageOptions) error
 	Ping() error
+	Info() (*godocker.DockerInfo, error)
 }
 
 type _dockerclient struct {
@@ -213,10 +216,22 @@ func (d *_dockerclient) StopContainer(id string, timeout uint) error {
 	return d.docker.StopContainer(id, timeout)
 }
 
+func (d *_dockerclient) InspectContainer(id string) (*godocker.Container, error) {
+	return d.docker.InspectContainer(id)
+}
+
+func (d *_dockerclient) ExportImage(opts godocker.ExportImageOptions) error {
+	return d.docker.ExportImage(opts)
+}
+
 func (d *_dockerclient) Ping() error {
 	return d.docker.Ping()
 }
 
+func (d *_dockerclient) Info() (*godocker.DockerInfo, error) {
+	return d.docker.Info()
+}
+
 type fileSystem interface {
 	ReadFile(filename string) ([]byte, error)
 }
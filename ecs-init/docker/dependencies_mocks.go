@@ -62,6 +62,50 @@ func (mr *MockdockerclientMockRecorder) CreateContainer(opts interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContainer", reflect.TypeOf((*Mockdockerclient)(nil).CreateContainer), opts)
 }
 
+// ExportImage mocks base method.
+func (m *Mockdockerclient) ExportImage(opts docker.ExportImageOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportImage", opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportImage indicates an expected call of ExportImage.
+func (mr *MockdockerclientMockRecorder) ExportImage(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportImage", reflect.TypeOf((*Mockdockerclient)(nil).ExportImage), opts)
+}
+
+// Info mocks base method.
+func (m *Mockdockerclient) Info() (*docker.DockerInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Info")
+	ret0, _ := ret[0].(*docker.DockerInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Info indicates an expected call of Info.
+func (mr *MockdockerclientMockRecorder) Info() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*Mockdockerclient)(nil).Info))
+}
+
+// InspectContainer mocks base method.
+func (m *Mockdockerclient) InspectContainer(id string) (*docker.Container, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InspectContainer", id)
+	ret0, _ := ret[0].(*docker.Container)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectContainer indicates an expected call of InspectContainer.
+func (mr *MockdockerclientMockRecorder) InspectContainer(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectContainer", reflect.TypeOf((*Mockdockerclient)(nil).InspectContainer), id)
+}
+
 // ListContainers mocks base method.
 func (m *Mockdockerclient) ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error) {
 	m.ctrl.T.Helper()
@@ -118,6 +118,10 @@ const (
 	// GPU devices are present.
 	nvidiaGPUDevicesPresentMaxRetries = 10
 
+	// agentContainerRunningState is the state Docker reports for a running
+	// Agent container
+	agentContainerRunningState = "running"
+
 	// lsblk lists information about block devices. This is used by the ECS agent for the EBS task attach functionality.
 	// Ref: https://man7.org/linux/man-pages/man8/lsblk.8.html
 	lsblkDir = "/usr/bin/lsblk"
@@ -130,6 +134,14 @@ const (
 	// fault inject functionality. Ref: https://man7.org/linux/man-pages/man8/modinfo.8.html
 	modInfoSbinDir    = "/sbin/modinfo"
 	modInfoUsrSbinDir = "/usr/sbin/modinfo"
+
+	// dockerRootDirContainerPath is where the Docker root dir is mounted in the Agent container,
+	// so that the Agent can check the usage of its filesystem for the disk pressure image cleanup
+	dockerRootDirContainerPath = "/host/docker-root"
+	// imageCleanupHighWatermarkEnvVar enables the disk pressure image cleanup of the Agent
+	imageCleanupHighWatermarkEnvVar = "ECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT"
+	// imageCleanupDiskPathEnvVar is the path of the Docker root dir as seen by the Agent
+	imageCleanupDiskPathEnvVar = "ECS_IMAGE_CLEANUP_DISK_PATH"
 )
 
 // Do NOT include "CAP_" in capability string
@@ -212,6 +224,14 @@ func (c *client) LoadImage(image io.Reader) error {
 	return c.docker.LoadImage(godocker.LoadImageOptions{InputStream: image})
 }
 
+// SaveAgentImage writes the Agent image loaded in Docker to w as a tarball
+func (c *client) SaveAgentImage(w io.Writer) error {
+	return c.docker.ExportImage(godocker.ExportImageOptions{
+		Name:         config.AgentImageName,
+		OutputStream: w,
+	})
+}
+
 // RemoveExistingAgentContainer removes any existing container named
 // "ecs-agent" or returns without error if none is found
 func (c *client) RemoveExistingAgentContainer() error {
@@ -231,6 +251,35 @@ func (c *client) RemoveExistingAgentContainer() error {
 	return err
 }
 
+// IsAgentRunning returns true if the Agent container exists and is running
+func (c *client) IsAgentRunning() (bool, error) {
+	containers, err := c.docker.ListContainers(godocker.ListContainersOptions{
+		All: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	agentContainerName := "/" + config.AgentContainerName
+	for _, container := range containers {
+		for _, name := range container.Names {
+			if name == agentContainerName {
+				return container.State == agentContainerRunningState, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// GetAgentHealth returns the healthcheck status of the Agent container, empty if the
+// container has no healthcheck, and its number of consecutive failed healthchecks
+func (c *client) GetAgentHealth() (string, int, error) {
+	container, err := c.docker.InspectContainer(config.AgentContainerName)
+	if err != nil {
+		return "", 0, err
+	}
+	return container.State.Health.Status, container.State.Health.FailingStreak, nil
+}
+
 func (c *client) findAgentContainer() (string, error) {
 	// TODO pagination
 	containers, err := c.docker.ListContainers(godocker.ListContainersOptions{
@@ -257,8 +306,12 @@ func (c *client) findAgentContainer() (string, error) {
 // StartAgent starts the Agent in Docker and returns the exit code from the container
 func (c *client) StartAgent() (int, error) {
 	envVarsFromFiles := c.LoadEnvVars()
+	dockerRootDirBind := c.getDockerRootDirBind(envVarsFromFiles)
 
 	hostConfig := c.getHostConfig(envVarsFromFiles)
+	if dockerRootDirBind != "" {
+		hostConfig.Binds = append(hostConfig.Binds, dockerRootDirBind)
+	}
 
 	container, err := c.docker.CreateContainer(godocker.CreateContainerOptions{
 		Name:       config.AgentContainerName,
@@ -495,6 +548,12 @@ func (c *client) getHostConfig(envVarsFromFiles map[string]string) *godocker.Hos
 		}
 	}
 
+	// bind mount the tmpfs of the secret files at the same path, as the Agent uses it as the
+	// source of the binds of the secret files into containers
+	if config.SecretFilesEnabled() {
+		binds = append(binds, config.SecretFilesDirectory()+":"+config.SecretFilesDirectory())
+	}
+
 	binds = append(binds, getDockerPluginDirBinds()...)
 
 	// only add bind mounts when the src file/directory exists on host; otherwise docker API create an empty directory on host
@@ -503,6 +562,22 @@ func (c *client) getHostConfig(envVarsFromFiles map[string]string) *godocker.Hos
 	return createHostConfig(binds)
 }
 
+// getDockerRootDirBind returns the read-only bind of the Docker root dir when the disk pressure
+// image cleanup is enabled without a disk path, and sets the disk path to where it's mounted.
+// The Agent disables the disk pressure image cleanup when the Docker root dir can't be mounted.
+func (c *client) getDockerRootDirBind(envVarsFromFiles map[string]string) string {
+	if envVarsFromFiles[imageCleanupHighWatermarkEnvVar] == "" || envVarsFromFiles[imageCleanupDiskPathEnvVar] != "" {
+		return ""
+	}
+	info, err := c.docker.Info()
+	if err != nil || info.DockerRootDir == "" {
+		log.Warnf("Unable to get the Docker root dir, disk pressure image cleanup will be disabled: %v", err)
+		return ""
+	}
+	envVarsFromFiles[imageCleanupDiskPathEnvVar] = dockerRootDirContainerPath
+	return info.DockerRootDir + ":" + dockerRootDirContainerPath + readOnly
+}
+
 // getCredentialsFetcherSocketBind returns the corresponding bind for credentials fetcher socket.
 func getCredentialsFetcherSocketBind() (string, bool) {
 	credentialsFetcherUnixSocketHostPath, ok := config.HostCredentialsFetcherPath()
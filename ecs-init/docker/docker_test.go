@@ -14,6 +14,7 @@
 package docker
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -97,6 +98,111 @@ func TestLoadImage(t *testing.T) {
 	assert.NoError(t, err, "no errors should be returned on load image with nil image")
 }
 
+func TestSaveAgentImage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerclient(mockCtrl)
+
+	var image bytes.Buffer
+	mockDocker.EXPECT().ExportImage(godocker.ExportImageOptions{
+		Name:         config.AgentImageName,
+		OutputStream: &image,
+	})
+
+	client := &client{
+		docker: mockDocker,
+	}
+	err := client.SaveAgentImage(&image)
+	assert.NoError(t, err, "no errors should be returned on save agent image")
+}
+
+func TestGetAgentHealth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerclient(mockCtrl)
+
+	gomock.InOrder(
+		mockDocker.EXPECT().InspectContainer(config.AgentContainerName).Return(&godocker.Container{
+			State: godocker.State{
+				Health: godocker.Health{
+					Status:        "unhealthy",
+					FailingStreak: 3,
+				},
+			},
+		}, nil),
+		mockDocker.EXPECT().InspectContainer(config.AgentContainerName).Return(nil, &godocker.NoSuchContainer{}),
+	)
+
+	client := &client{
+		docker: mockDocker,
+	}
+	status, failingStreak, err := client.GetAgentHealth()
+	assert.NoError(t, err)
+	assert.Equal(t, "unhealthy", status)
+	assert.Equal(t, 3, failingStreak)
+
+	_, _, err = client.GetAgentHealth()
+	assert.Error(t, err, "error should be returned when the agent container doesn't exist")
+}
+
+func TestIsAgentRunningListContainersFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerclient(mockCtrl)
+
+	mockDocker.EXPECT().ListContainers(godocker.ListContainersOptions{All: true}).Return(nil, errors.New("test error"))
+
+	client := &client{
+		docker: mockDocker,
+	}
+	running, err := client.IsAgentRunning()
+	assert.Error(t, err, "error should be returned when list containers fails")
+	assert.False(t, running, "IsAgentRunning should return false if list containers fails")
+}
+
+func TestIsAgentRunning(t *testing.T) {
+	testCases := []struct {
+		name       string
+		containers []godocker.APIContainers
+		running    bool
+	}{
+		{
+			name:       "no agent container",
+			containers: []godocker.APIContainers{{Names: []string{"/foo"}, State: "running"}},
+			running:    false,
+		},
+		{
+			name:       "agent container exited",
+			containers: []godocker.APIContainers{{Names: []string{"/" + config.AgentContainerName}, State: "exited"}},
+			running:    false,
+		},
+		{
+			name:       "agent container running",
+			containers: []godocker.APIContainers{{Names: []string{"/" + config.AgentContainerName}, State: "running"}},
+			running:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockDocker := NewMockdockerclient(mockCtrl)
+			mockDocker.EXPECT().ListContainers(godocker.ListContainersOptions{All: true}).Return(tc.containers, nil)
+
+			client := &client{
+				docker: mockDocker,
+			}
+			running, err := client.IsAgentRunning()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.running, running)
+		})
+	}
+}
+
 func TestRemoveExistingAgentContainerListContainersFailure(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -364,6 +470,77 @@ func TestStartAgentEnvFile(t *testing.T) {
 		t.Error("Error should not be returned")
 	}
 }
+func TestStartAgentDockerRootDirBind(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	isPathValid = func(path string, isDir bool) bool {
+		return false
+	}
+	defer func() {
+		isPathValid = defaultIsPathValid
+	}()
+
+	config.OsStat = func(name string) (os.FileInfo, error) {
+		return nil, nil
+	}
+	defer func() {
+		config.OsStat = os.Stat
+	}()
+
+	envFile := "\nECS_IMAGE_CLEANUP_HIGH_WATERMARK_PERCENT=85\n"
+	containerID := "container id"
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockDocker := NewMockdockerclient(mockCtrl)
+
+	mockFS.EXPECT().ReadFile(config.InstanceConfigFile()).Return(nil, errors.New("not found")).AnyTimes()
+	mockFS.EXPECT().ReadFile(config.AgentConfigFile()).Return([]byte(envFile), nil).AnyTimes()
+	mockDocker.EXPECT().Info().Return(&godocker.DockerInfo{DockerRootDir: "/data/docker"}, nil)
+	mockDocker.EXPECT().CreateContainer(gomock.Any()).Do(func(opts godocker.CreateContainerOptions) {
+		validateCommonCreateContainerOptions(t, opts)
+		envVariables := make(map[string]struct{})
+		for _, envVar := range opts.Config.Env {
+			envVariables[envVar] = struct{}{}
+		}
+		expectKey("ECS_IMAGE_CLEANUP_DISK_PATH="+dockerRootDirContainerPath, envVariables, t)
+		assert.Contains(t, opts.HostConfig.Binds, "/data/docker:"+dockerRootDirContainerPath+readOnly)
+	}).Return(&godocker.Container{
+		ID: containerID,
+	}, nil)
+	mockDocker.EXPECT().StartContainer(containerID, nil)
+	mockDocker.EXPECT().WaitContainer(containerID)
+
+	client := &client{
+		docker: mockDocker,
+		fs:     mockFS,
+	}
+
+	_, err := client.StartAgent()
+	assert.NoError(t, err)
+}
+
+func TestGetDockerRootDirBindNotNeeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockDocker := NewMockdockerclient(mockCtrl)
+	client := &client{docker: mockDocker}
+
+	// Docker isn't asked for its root dir when the disk pressure image cleanup is disabled,
+	// or when the disk path is already set
+	assert.Empty(t, client.getDockerRootDirBind(map[string]string{}))
+	envVars := map[string]string{
+		imageCleanupHighWatermarkEnvVar: "85",
+		imageCleanupDiskPathEnvVar:      "/host/custom",
+	}
+	assert.Empty(t, client.getDockerRootDirBind(envVars))
+	assert.Equal(t, "/host/custom", envVars[imageCleanupDiskPathEnvVar])
+
+	mockDocker.EXPECT().Info().Return(nil, errors.New("no docker"))
+	envVars = map[string]string{imageCleanupHighWatermarkEnvVar: "85"}
+	assert.Empty(t, client.getDockerRootDirBind(envVars))
+	assert.NotContains(t, envVars, imageCleanupDiskPathEnvVar)
+}
+
 func TestStartAgentWithGPUConfig(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -880,6 +1057,27 @@ func TestGetHostConfigExternal(t *testing.T) {
 	assert.NotEmpty(t, hostConfig.CapAdd)
 }
 
+func TestGetHostConfigSecretFiles(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockFS.EXPECT().ReadFile(config.InstanceConfigFile()).Return(nil, errors.New("not found")).AnyTimes()
+	mockFS.EXPECT().ReadFile(config.AgentConfigFile()).Return(nil, errors.New("test error")).AnyTimes()
+
+	secretFilesBind := config.SecretFilesDirectory() + ":" + config.SecretFilesDirectory()
+
+	client := &client{
+		fs: mockFS,
+	}
+	hostConfig := client.getHostConfig(map[string]string{})
+	assert.NotContains(t, hostConfig.Binds, secretFilesBind)
+
+	t.Setenv("ECS_SECRET_ROTATION_INTERVAL", "15m")
+	hostConfig = client.getHostConfig(map[string]string{})
+	assert.Contains(t, hostConfig.Binds, secretFilesBind)
+}
+
 func TestStartAgentWithExecBinds(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -34,6 +34,8 @@ const (
 	STOP     = "stop"
 	POSTSTOP = "post-stop"
 	RECACHE  = "reload-cache"
+	STATUS   = "status"
+	DIAGNOSE = "diagnose"
 )
 
 func main() {
@@ -109,6 +111,14 @@ func actions(engine *engine.Engine) map[string]action {
 			function:    engine.PostStop,
 			description: "Cleanup procedure for the ECS Agent",
 		},
+		STATUS: action{
+			function:    engine.Status,
+			description: "Report the cache, container and health status of the ECS Agent",
+		},
+		DIAGNOSE: action{
+			function:    engine.Diagnose,
+			description: "Gather the ECS Agent status and host setup into a support bundle",
+		},
 	}
 }
 
@@ -26,14 +26,19 @@ type downloader interface {
 	DownloadAgent() error
 	LoadCachedAgent() (io.ReadCloser, error)
 	LoadDesiredAgent() (io.ReadCloser, error)
+	SavePreviousAgent(image io.Reader) error
+	LoadPreviousAgent() (io.ReadCloser, error)
 	RecordCachedAgent() error
 	AgentCacheStatus() cache.CacheStatus
 }
 
 type dockerClient interface {
 	GetContainerLogTail(logWindowSize string) string
+	GetAgentHealth() (string, int, error)
 	IsAgentImageLoaded() (bool, error)
+	IsAgentRunning() (bool, error)
 	LoadImage(image io.Reader) error
+	SaveAgentImage(w io.Writer) error
 	RemoveExistingAgentContainer() error
 	StartAgent() (int, error)
 	StopAgent() error
@@ -120,6 +120,21 @@ func (mr *MockdownloaderMockRecorder) LoadDesiredAgent() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadDesiredAgent", reflect.TypeOf((*Mockdownloader)(nil).LoadDesiredAgent))
 }
 
+// LoadPreviousAgent mocks base method.
+func (m *Mockdownloader) LoadPreviousAgent() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPreviousAgent")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPreviousAgent indicates an expected call of LoadPreviousAgent.
+func (mr *MockdownloaderMockRecorder) LoadPreviousAgent() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPreviousAgent", reflect.TypeOf((*Mockdownloader)(nil).LoadPreviousAgent))
+}
+
 // RecordCachedAgent mocks base method.
 func (m *Mockdownloader) RecordCachedAgent() error {
 	m.ctrl.T.Helper()
@@ -134,6 +149,20 @@ func (mr *MockdownloaderMockRecorder) RecordCachedAgent() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCachedAgent", reflect.TypeOf((*Mockdownloader)(nil).RecordCachedAgent))
 }
 
+// SavePreviousAgent mocks base method.
+func (m *Mockdownloader) SavePreviousAgent(image io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePreviousAgent", image)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePreviousAgent indicates an expected call of SavePreviousAgent.
+func (mr *MockdownloaderMockRecorder) SavePreviousAgent(image interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreviousAgent", reflect.TypeOf((*Mockdownloader)(nil).SavePreviousAgent), image)
+}
+
 // MockdockerClient is a mock of dockerClient interface.
 type MockdockerClient struct {
 	ctrl     *gomock.Controller
@@ -157,6 +186,22 @@ func (m *MockdockerClient) EXPECT() *MockdockerClientMockRecorder {
 	return m.recorder
 }
 
+// GetAgentHealth mocks base method.
+func (m *MockdockerClient) GetAgentHealth() (string, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAgentHealth")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAgentHealth indicates an expected call of GetAgentHealth.
+func (mr *MockdockerClientMockRecorder) GetAgentHealth() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAgentHealth", reflect.TypeOf((*MockdockerClient)(nil).GetAgentHealth))
+}
+
 // GetContainerLogTail mocks base method.
 func (m *MockdockerClient) GetContainerLogTail(logWindowSize string) string {
 	m.ctrl.T.Helper()
@@ -186,6 +231,21 @@ func (mr *MockdockerClientMockRecorder) IsAgentImageLoaded() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAgentImageLoaded", reflect.TypeOf((*MockdockerClient)(nil).IsAgentImageLoaded))
 }
 
+// IsAgentRunning mocks base method.
+func (m *MockdockerClient) IsAgentRunning() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAgentRunning")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAgentRunning indicates an expected call of IsAgentRunning.
+func (mr *MockdockerClientMockRecorder) IsAgentRunning() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAgentRunning", reflect.TypeOf((*MockdockerClient)(nil).IsAgentRunning))
+}
+
 // LoadEnvVars mocks base method.
 func (m *MockdockerClient) LoadEnvVars() map[string]string {
 	m.ctrl.T.Helper()
@@ -228,6 +288,20 @@ func (mr *MockdockerClientMockRecorder) RemoveExistingAgentContainer() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExistingAgentContainer", reflect.TypeOf((*MockdockerClient)(nil).RemoveExistingAgentContainer))
 }
 
+// SaveAgentImage mocks base method.
+func (m *MockdockerClient) SaveAgentImage(w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAgentImage", w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAgentImage indicates an expected call of SaveAgentImage.
+func (mr *MockdockerClientMockRecorder) SaveAgentImage(w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAgentImage", reflect.TypeOf((*MockdockerClient)(nil).SaveAgentImage), w)
+}
+
 // StartAgent mocks base method.
 func (m *MockdockerClient) StartAgent() (int, error) {
 	m.ctrl.T.Helper()
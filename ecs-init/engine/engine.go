@@ -70,6 +70,12 @@ type Engine struct {
 	credentialsProxyRoute    credentialsProxyRoute
 	ipv6RouterAdvertisements ipv6RouterAdvertisements
 	nvidiaGPUManager         gpu.GPUManager
+	cmdExec                  exec.Exec
+	// updateRollbackThreshold is the number of consecutive failed healthchecks of an
+	// updated Agent after which the update is rolled back, 0 if updates aren't rolled back
+	updateRollbackThreshold int
+	// pendingUpdate is set from the update of the Agent until it reports healthy
+	pendingUpdate *pendingUpdate
 }
 
 type TerminalError struct {
@@ -107,6 +113,8 @@ func New() (*Engine, error) {
 		credentialsProxyRoute:    credentialsProxyRoute,
 		ipv6RouterAdvertisements: ipv6RouterAdvertisements,
 		nvidiaGPUManager:         gpu.NewNvidiaGPUManager(),
+		cmdExec:                  cmdExec,
+		updateRollbackThreshold:  config.AgentUpdateRollbackThreshold(),
 	}, nil
 }
 
@@ -149,6 +157,16 @@ func (e *Engine) PreStart() error {
 		// If directory creation fails, set ECS_EBSTA_SUPPORTED=false in docker/docker.go
 		log.Error("could not create EBS mount directory", err)
 	}
+	// Mount the tmpfs the Agent writes the secret files of containers to
+	if config.SecretFilesEnabled() {
+		log.Info("pre-start: mounting secret files directory")
+		err = mountSecretFilesDirectory(config.SecretFilesDirectory())
+		if err != nil {
+			// Log error and continue
+			// The Agent fails to create the containers with secret files if it's not a tmpfs
+			log.Errorf("could not mount secret files directory: %v", err)
+		}
+	}
 
 	docker, err := getDockerClient()
 	if err != nil {
@@ -263,7 +281,8 @@ func (e *Engine) load(docker dockerClient, agentLoader func() (io.ReadCloser, er
 	return e.downloader.RecordCachedAgent()
 }
 
-// StartSupervised starts the ECS Agent and ensures it stays running, except for terminal errors (indicated by an agent exit code of 5)
+// StartSupervised starts the ECS Agent and ensures it stays running, except for terminal errors (indicated by an agent exit code of 5).
+// An update of the Agent is rolled back if the updated Agent fails its healthcheck too many times in a row before it's healthy.
 func (e *Engine) StartSupervised() error {
 	docker, err := getDockerClient()
 	if err != nil {
@@ -279,12 +298,23 @@ func (e *Engine) StartSupervised() error {
 		}
 
 		log.Info("Starting Amazon Elastic Container Service Agent")
-		agentExitCode, err = docker.StartAgent()
+		var rollback bool
+		agentExitCode, rollback, err = e.startAgent(docker)
 		if err != nil {
 			return engineError("could not start Agent", err)
 		}
 		log.Infof("Agent exited with code %d", agentExitCode)
 
+		if rollback {
+			err = e.rollbackAgent(docker)
+			if err != nil {
+				log.Error("could not roll back agent update", err)
+			} else {
+				// continuing here because the previous Agent doesn't need to backoff retries
+				continue
+			}
+		}
+
 		switch agentExitCode {
 		case upgradeAgentExitCode:
 			err = e.upgradeAgent(docker)
@@ -314,8 +344,26 @@ func (e *Engine) StartSupervised() error {
 }
 
 func (e *Engine) upgradeAgent(docker dockerClient) error {
+	// The image saved before an update that's still pending is kept, the current image
+	// isn't known to be healthy
+	canRollback := e.pendingUpdate != nil
+	if e.updateRollbackThreshold > 0 && !canRollback {
+		log.Info("Saving the current Amazon Elastic Container Service Agent to roll back the update")
+		err := e.savePreviousAgent(docker)
+		if err != nil {
+			log.Warnf("Could not save the current Agent, the update won't be rolled back: %v", err)
+		}
+		canRollback = err == nil
+	}
 	log.Info("Loading new desired Amazon Elastic Container Service Agent into Docker")
-	return e.load(docker, e.downloader.LoadDesiredAgent)
+	err := e.load(docker, e.downloader.LoadDesiredAgent)
+	if err != nil {
+		return err
+	}
+	if canRollback {
+		e.pendingUpdate = &pendingUpdate{}
+	}
+	return nil
 }
 
 // PreStop sends commands to Docker to stop the ECS Agent
@@ -17,15 +17,24 @@
 package engine
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-init/apparmor"
 	"github.com/aws/amazon-ecs-agent/ecs-init/cache"
+	"github.com/aws/amazon-ecs-agent/ecs-init/config"
+	"github.com/aws/amazon-ecs-agent/ecs-init/exec/sysctl"
 	"github.com/aws/amazon-ecs-agent/ecs-init/gpu"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -633,3 +642,458 @@ func TestPreStartAppArmorSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteStatus(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	introspectionMetadataURLBkp := introspectionMetadataURL
+	introspectionMetadataURL = server.URL
+	defer func() { introspectionMetadataURL = introspectionMetadataURLBkp }()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDownloader := NewMockdownloader(mockCtrl)
+	mockDownloader.EXPECT().AgentCacheStatus().Return(cache.StatusCached)
+	mockDocker.EXPECT().IsAgentRunning().Return(true, nil)
+	mockDocker.EXPECT().GetContainerLogTail(statusLogWindowSize).Return("agent log line")
+
+	engine := &Engine{
+		downloader: mockDownloader,
+	}
+	var status bytes.Buffer
+	assert.NoError(t, engine.writeStatus(&status, mockDocker, statusLogWindowSize))
+	assert.Equal(t, "Agent image cache: cached\n"+
+		"Agent container: running\n"+
+		"Agent introspection: healthy\n"+
+		"Agent container log tail:\n"+
+		"agent log line\n", status.String())
+}
+
+func TestWriteStatusAgentUnhealthy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	introspectionMetadataURLBkp := introspectionMetadataURL
+	introspectionMetadataURL = server.URL
+	defer func() { introspectionMetadataURL = introspectionMetadataURLBkp }()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDownloader := NewMockdownloader(mockCtrl)
+	mockDownloader.EXPECT().AgentCacheStatus().Return(cache.StatusUncached)
+	mockDocker.EXPECT().IsAgentRunning().Return(false, errors.New("docker error"))
+	mockDocker.EXPECT().GetContainerLogTail(statusLogWindowSize).Return("")
+
+	engine := &Engine{
+		downloader: mockDownloader,
+	}
+	var status bytes.Buffer
+	err := engine.writeStatus(&status, mockDocker, statusLogWindowSize)
+	assert.EqualError(t, err, "unable to determine whether the agent container is running: docker error")
+	assert.Contains(t, status.String(), "Agent image cache: uncached\n")
+	assert.Contains(t, status.String(), "Agent container: unknown (docker error)\n")
+	assert.Contains(t, status.String(), "Agent introspection: unhealthy (unexpected status code 500)\n")
+}
+
+func TestWriteStatusAgentNotRunning(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	introspectionMetadataURLBkp := introspectionMetadataURL
+	introspectionMetadataURL = server.URL
+	defer func() { introspectionMetadataURL = introspectionMetadataURLBkp }()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDownloader := NewMockdownloader(mockCtrl)
+	mockDownloader.EXPECT().AgentCacheStatus().Return(cache.StatusCached)
+	mockDocker.EXPECT().IsAgentRunning().Return(false, nil)
+	mockDocker.EXPECT().GetContainerLogTail(statusLogWindowSize).Return("")
+
+	engine := &Engine{
+		downloader: mockDownloader,
+	}
+	var status bytes.Buffer
+	err := engine.writeStatus(&status, mockDocker, statusLogWindowSize)
+	assert.EqualError(t, err, "agent container is not running")
+	assert.Contains(t, status.String(), "Agent container: not running\n")
+}
+
+func TestWriteStatusIntrospectionUnhealthy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	introspectionMetadataURLBkp := introspectionMetadataURL
+	introspectionMetadataURL = server.URL
+	defer func() { introspectionMetadataURL = introspectionMetadataURLBkp }()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDownloader := NewMockdownloader(mockCtrl)
+	mockDownloader.EXPECT().AgentCacheStatus().Return(cache.StatusCached)
+	mockDocker.EXPECT().IsAgentRunning().Return(true, nil)
+	mockDocker.EXPECT().GetContainerLogTail(statusLogWindowSize).Return("")
+
+	engine := &Engine{
+		downloader: mockDownloader,
+	}
+	var status bytes.Buffer
+	err := engine.writeStatus(&status, mockDocker, statusLogWindowSize)
+	assert.EqualError(t, err, "agent introspection is unhealthy: unexpected status code 503")
+}
+
+func TestCreateSupportBundle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	introspectionMetadataURLBkp := introspectionMetadataURL
+	introspectionMetadataURL = "http://127.0.0.1:0"
+	defer func() { introspectionMetadataURL = introspectionMetadataURLBkp }()
+	gpuInfoFilePathBkp := gpuInfoFilePath
+	gpuInfoFilePath = filepath.Join(t.TempDir(), "nvidia-gpu-info.json")
+	defer func() { gpuInfoFilePath = gpuInfoFilePathBkp }()
+	assert.NoError(t, os.WriteFile(gpuInfoFilePath, []byte(`{"DriverVersion":"396.44"}`), 0600))
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDownloader := NewMockdownloader(mockCtrl)
+	mockExec := sysctl.NewMockExec(mockCtrl)
+	mockCmd := sysctl.NewMockCmd(mockCtrl)
+	mockDownloader.EXPECT().AgentCacheStatus().Return(cache.StatusCached)
+	mockDocker.EXPECT().IsAgentRunning().Return(false, nil)
+	mockDocker.EXPECT().GetContainerLogTail(diagnoseLogWindowSize).Return("")
+	mockDocker.EXPECT().LoadEnvVars().Return(map[string]string{config.GPUSupportEnvVar: "true"})
+	mockExec.EXPECT().Command("iptables", "-t", "nat", "-S").Return(mockCmd)
+	mockExec.EXPECT().Command("iptables", "-t", "filter", "-S").Return(mockCmd)
+	mockExec.EXPECT().Command("sysctl", gomock.Any()).Return(mockCmd)
+	gomock.InOrder(
+		mockCmd.EXPECT().CombinedOutput().Return([]byte("-P PREROUTING ACCEPT\n"), nil),
+		mockCmd.EXPECT().CombinedOutput().Return(nil, errors.New("iptables error")),
+		mockCmd.EXPECT().CombinedOutput().Return([]byte("net.ipv4.conf.all.route_localnet = 1\n"), nil),
+	)
+
+	engine := &Engine{
+		downloader: mockDownloader,
+		cmdExec:    mockExec,
+	}
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	bundlePath, err := engine.createSupportBundle(t.TempDir(), mockDocker, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "ecs-init-diagnose-20230102T030405Z.tar.gz", filepath.Base(bundlePath))
+
+	file, err := os.Open(bundlePath)
+	assert.NoError(t, err)
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	assert.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+	contents := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		content, err := io.ReadAll(tarReader)
+		assert.NoError(t, err)
+		contents[header.Name] = string(content)
+	}
+	assert.Len(t, contents, 5)
+	assert.Contains(t, contents["status.txt"], "Agent container: not running")
+	assert.Equal(t, "$ iptables -t nat -S\n-P PREROUTING ACCEPT\n", contents["iptables-nat.txt"])
+	assert.Contains(t, contents["iptables-filter.txt"], "error: iptables error")
+	assert.Contains(t, contents["sysctl.txt"], "net.ipv4.conf.all.route_localnet = 1")
+	assert.Contains(t, contents["gpu.txt"], config.GPUSupportEnvVar+": true")
+	assert.Contains(t, contents["gpu.txt"], "396.44")
+}
+
+// expectSavePreviousAgent expects the Agent image exported from Docker to be saved to the
+// cache. The image is exported concurrently, so only the call saving it can be ordered.
+func expectSavePreviousAgent(t *testing.T, mockDocker *MockdockerClient, mockDownloader *Mockdownloader) *gomock.Call {
+	mockDocker.EXPECT().SaveAgentImage(gomock.Any()).DoAndReturn(func(w io.Writer) error {
+		_, err := w.Write([]byte("previous-agent"))
+		return err
+	})
+	return mockDownloader.EXPECT().SavePreviousAgent(gomock.Any()).DoAndReturn(func(image io.Reader) error {
+		data, err := io.ReadAll(image)
+		assert.Equal(t, "previous-agent", string(data))
+		return err
+	})
+}
+
+func setAgentHealthPollInterval(interval time.Duration) func() {
+	agentHealthPollIntervalBkp := agentHealthPollInterval
+	agentHealthPollInterval = interval
+	return func() {
+		agentHealthPollInterval = agentHealthPollIntervalBkp
+	}
+}
+
+func TestStartSupervisedUpgradeRollbackOnFailedHealthchecks(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	defer setAgentHealthPollInterval(time.Millisecond)()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	stopped := make(chan struct{})
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		expectSavePreviousAgent(t, mockDocker, mockDownloader),
+		mockDownloader.EXPECT().LoadDesiredAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		// The updated Agent runs until it's stopped for failing its healthchecks
+		mockDocker.EXPECT().StartAgent().DoAndReturn(func() (int, error) {
+			<-stopped
+			return terminalSuccessAgentExitCode, nil
+		}),
+		mockDocker.EXPECT().GetAgentHealth().Return("starting", 0, nil),
+		mockDocker.EXPECT().GetAgentHealth().Return("starting", 1, nil),
+		mockDocker.EXPECT().GetAgentHealth().Return("unhealthy", 2, nil),
+		mockDocker.EXPECT().StopAgent().Do(func() { close(stopped) }),
+		mockDownloader.EXPECT().LoadPreviousAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil),
+	)
+
+	engine := &Engine{
+		downloader:              mockDownloader,
+		updateRollbackThreshold: 2,
+	}
+	err := engine.StartSupervised()
+	assert.NoError(t, err)
+	assert.Nil(t, engine.pendingUpdate)
+}
+
+func TestStartSupervisedUpgradeRollbackOnExits(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	defer setAgentHealthPollInterval(time.Hour)()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		expectSavePreviousAgent(t, mockDocker, mockDownloader),
+		mockDownloader.EXPECT().LoadDesiredAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		// Each exit of the updated Agent before it's healthy counts as a failed healthcheck
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(containerFailureAgentExitCode, nil),
+		mockDocker.EXPECT().GetContainerLogTail(gomock.Any()),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(containerFailureAgentExitCode, nil),
+		mockDownloader.EXPECT().LoadPreviousAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil),
+	)
+
+	engine := &Engine{
+		downloader:              mockDownloader,
+		updateRollbackThreshold: 2,
+	}
+	err := engine.StartSupervised()
+	assert.NoError(t, err)
+}
+
+func TestStartSupervisedUpgradeRollbackOnTerminalFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	defer setAgentHealthPollInterval(time.Hour)()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		expectSavePreviousAgent(t, mockDocker, mockDownloader),
+		mockDownloader.EXPECT().LoadDesiredAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(TerminalFailureAgentExitCode, nil),
+		mockDownloader.EXPECT().LoadPreviousAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil),
+	)
+
+	engine := &Engine{
+		downloader:              mockDownloader,
+		updateRollbackThreshold: 3,
+	}
+	err := engine.StartSupervised()
+	assert.NoError(t, err)
+}
+
+func TestStartSupervisedUpgradeHealthy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	defer setAgentHealthPollInterval(time.Millisecond)()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	healthy := make(chan struct{})
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		expectSavePreviousAgent(t, mockDocker, mockDownloader),
+		mockDownloader.EXPECT().LoadDesiredAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().DoAndReturn(func() (int, error) {
+			<-healthy
+			return TerminalFailureAgentExitCode, nil
+		}),
+		mockDocker.EXPECT().GetAgentHealth().Return("unhealthy", 1, nil),
+		mockDocker.EXPECT().GetAgentHealth().DoAndReturn(func() (string, int, error) {
+			close(healthy)
+			return "healthy", 0, nil
+		}),
+	)
+
+	// The update isn't rolled back once the updated Agent was healthy
+	engine := &Engine{
+		downloader:              mockDownloader,
+		updateRollbackThreshold: 3,
+	}
+	err := engine.StartSupervised()
+	assert.IsType(t, &TerminalError{}, err)
+	assert.Nil(t, engine.pendingUpdate)
+}
+
+func TestStartSupervisedUpgradeNoHealthcheck(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	defer setAgentHealthPollInterval(time.Millisecond)()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	checked := make(chan struct{})
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		expectSavePreviousAgent(t, mockDocker, mockDownloader),
+		mockDownloader.EXPECT().LoadDesiredAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().DoAndReturn(func() (int, error) {
+			<-checked
+			return TerminalFailureAgentExitCode, nil
+		}),
+		mockDocker.EXPECT().GetAgentHealth().DoAndReturn(func() (string, int, error) {
+			close(checked)
+			return "", 0, nil
+		}),
+	)
+
+	// The update isn't tracked anymore once the updated Agent turns out to have no healthcheck
+	engine := &Engine{
+		downloader:              mockDownloader,
+		updateRollbackThreshold: 3,
+	}
+	err := engine.StartSupervised()
+	assert.IsType(t, &TerminalError{}, err)
+	assert.Nil(t, engine.pendingUpdate)
+}
+
+func TestStartSupervisedUpgradeSavePreviousAgentFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	mockDocker.EXPECT().SaveAgentImage(gomock.Any()).Return(errors.New("test error"))
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		mockDownloader.EXPECT().SavePreviousAgent(gomock.Any()).DoAndReturn(func(image io.Reader) error {
+			_, err := io.ReadAll(image)
+			return err
+		}),
+		mockDownloader.EXPECT().LoadDesiredAgent().Return(io.NopCloser(&bytes.Buffer{}), nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(TerminalFailureAgentExitCode, nil),
+	)
+
+	// The update goes ahead without the previous Agent, and can't be rolled back
+	engine := &Engine{
+		downloader:              mockDownloader,
+		updateRollbackThreshold: 3,
+	}
+	err := engine.StartSupervised()
+	assert.IsType(t, &TerminalError{}, err)
+}
+
+func TestMountSecretFilesDirectory(t *testing.T) {
+	defer func() {
+		statfs = syscall.Statfs
+		mount = syscall.Mount
+	}()
+	path := filepath.Join(t.TempDir(), "secrets")
+	var mounted []string
+	mount = func(source, target, fstype string, flags uintptr, data string) error {
+		mounted = append(mounted, target)
+		assert.Equal(t, "tmpfs", fstype)
+		assert.Equal(t, "mode=0700,size=67108864", data)
+		return nil
+	}
+
+	// The tmpfs is mounted over the directory
+	statfs = func(path string, stat *syscall.Statfs_t) error {
+		stat.Type = 0xef53
+		return nil
+	}
+	assert.NoError(t, mountSecretFilesDirectory(path))
+	assert.Equal(t, []string{path}, mounted)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(secretFilesDirectoryPermission), info.Mode().Perm())
+
+	// The tmpfs isn't mounted again
+	statfs = func(path string, stat *syscall.Statfs_t) error {
+		stat.Type = tmpfsMagic
+		return nil
+	}
+	assert.NoError(t, mountSecretFilesDirectory(path))
+	assert.Len(t, mounted, 1)
+
+	statfs = func(path string, stat *syscall.Statfs_t) error {
+		return errors.New("statfs error")
+	}
+	assert.Error(t, mountSecretFilesDirectory(path))
+}
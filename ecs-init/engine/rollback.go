@@ -0,0 +1,164 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"io"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+const (
+	agentHealthyStatus = "healthy"
+	// agentNoHealthcheckStatus is the health status of a container without healthcheck;
+	// Docker reports "starting" for a container whose healthcheck hasn't run yet
+	agentNoHealthcheckStatus = ""
+)
+
+// Injection point for testing purposes
+var agentHealthPollInterval = 10 * time.Second
+
+// pendingUpdate tracks the Agent loaded by an update until its container reports healthy.
+// It's kept in memory, an update pending when ecs-init restarts isn't rolled back.
+type pendingUpdate struct {
+	// failures is the number of consecutive failed healthchecks of the Agent containers
+	// started since the update
+	failures int
+}
+
+// updatedAgentHealth is the health of an Agent container started after an update, as
+// observed while it was running
+type updatedAgentHealth struct {
+	// healthy is set once the container reported healthy
+	healthy bool
+	// noHealthcheck is set if the container has no healthcheck, its health can't be watched
+	noHealthcheck bool
+	// stopped is set if the container was stopped because its failed healthchecks reached
+	// the rollback threshold
+	stopped bool
+	// failingStreak is the last number of consecutive failed healthchecks of the container
+	failingStreak int
+}
+
+// savePreviousAgent saves the Agent image loaded in Docker to the cache before it's replaced
+// by an update
+func (e *Engine) savePreviousAgent(docker dockerClient) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(docker.SaveAgentImage(writer))
+	}()
+	err := e.downloader.SavePreviousAgent(reader)
+	// Unblock the export if the image couldn't be written to the cache
+	reader.Close()
+	return err
+}
+
+// rollbackAgent loads the Agent image saved before the pending update into Docker
+func (e *Engine) rollbackAgent(docker dockerClient) error {
+	log.Warn("Rolling back the update of the Amazon Elastic Container Service Agent")
+	e.pendingUpdate = nil
+	return e.load(docker, e.downloader.LoadPreviousAgent)
+}
+
+// startAgent starts the Agent and waits for it to stop. While an update is pending, the
+// health of the Agent is watched, and it returns whether the update should be rolled back.
+func (e *Engine) startAgent(docker dockerClient) (int, bool, error) {
+	if e.pendingUpdate == nil {
+		agentExitCode, err := docker.StartAgent()
+		return agentExitCode, false, err
+	}
+
+	done := make(chan struct{})
+	result := make(chan updatedAgentHealth, 1)
+	go func() {
+		result <- e.watchUpdatedAgent(docker, e.pendingUpdate.failures, done)
+	}()
+	agentExitCode, err := docker.StartAgent()
+	close(done)
+	health := <-result
+	if err != nil {
+		return agentExitCode, false, err
+	}
+	return agentExitCode, e.shouldRollback(agentExitCode, health), nil
+}
+
+// watchUpdatedAgent polls the health of the Agent container started after an update until
+// done is closed, the container reports healthy or it turns out to have no healthcheck. The container is stopped once its failed
+// healthchecks, added to the failures of the containers started before it since the update,
+// reach the rollback threshold.
+func (e *Engine) watchUpdatedAgent(docker dockerClient, failures int, done <-chan struct{}) updatedAgentHealth {
+	var health updatedAgentHealth
+	ticker := time.NewTicker(agentHealthPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return health
+		case <-ticker.C:
+		}
+		status, failingStreak, err := docker.GetAgentHealth()
+		if err != nil {
+			log.Debugf("Could not get the health of the updated Agent: %v", err)
+			continue
+		}
+		switch status {
+		case agentHealthyStatus:
+			health.healthy = true
+			return health
+		case agentNoHealthcheckStatus:
+			health.noHealthcheck = true
+			return health
+		}
+		health.failingStreak = failingStreak
+		if failures+failingStreak >= e.updateRollbackThreshold {
+			log.Warnf("The updated Agent failed %d consecutive healthchecks, stopping it",
+				failures+failingStreak)
+			health.stopped = true
+			if err := docker.StopAgent(); err != nil {
+				log.Errorf("could not stop the updated Agent: %v", err)
+			}
+			return health
+		}
+	}
+}
+
+// shouldRollback returns whether the pending update should be rolled back after the Agent
+// exited. An exit before the Agent reported healthy counts as a failed healthcheck, except
+// for the exits requested by the Agent, and a terminal failure rolls the update back
+// immediately.
+func (e *Engine) shouldRollback(agentExitCode int, health updatedAgentHealth) bool {
+	switch {
+	case health.healthy:
+		log.Info("The updated Agent is healthy, the update won't be rolled back")
+		e.pendingUpdate = nil
+		return false
+	case health.noHealthcheck:
+		log.Warn("The updated Agent container has no healthcheck, the update won't be rolled back")
+		e.pendingUpdate = nil
+		return false
+	case health.stopped:
+		return true
+	}
+	switch agentExitCode {
+	case terminalSuccessAgentExitCode, upgradeAgentExitCode:
+		return false
+	case TerminalFailureAgentExitCode:
+		return true
+	}
+	e.pendingUpdate.failures += health.failingStreak + 1
+	log.Warnf("The updated Agent exited before it was healthy, %d of %d consecutive failed healthchecks before the update is rolled back",
+		e.pendingUpdate.failures, e.updateRollbackThreshold)
+	return e.pendingUpdate.failures >= e.updateRollbackThreshold
+}
@@ -0,0 +1,51 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+const (
+	secretFilesDirectoryPermission = 0700
+	// secretFilesDirectorySize bounds the memory used by the secret files tmpfs
+	secretFilesDirectorySize = 64 * 1024 * 1024
+	// tmpfsMagic is the type of tmpfs filesystems returned by statfs
+	tmpfsMagic = 0x01021994
+)
+
+// Injection points for testing purposes
+var (
+	statfs = syscall.Statfs
+	mount  = syscall.Mount
+)
+
+// mountSecretFilesDirectory mounts the tmpfs the Agent writes the secret files of containers
+// to, unless it's already mounted
+func mountSecretFilesDirectory(path string) error {
+	if err := os.MkdirAll(path, secretFilesDirectoryPermission); err != nil {
+		return err
+	}
+	var stat syscall.Statfs_t
+	if err := statfs(path, &stat); err != nil {
+		return err
+	}
+	if stat.Type == tmpfsMagic {
+		return nil
+	}
+	return mount("tmpfs", path, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC,
+		fmt.Sprintf("mode=0700,size=%d", secretFilesDirectorySize))
+}
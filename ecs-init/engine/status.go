@@ -0,0 +1,249 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/cache"
+	"github.com/aws/amazon-ecs-agent/ecs-init/config"
+	"github.com/aws/amazon-ecs-agent/ecs-init/gpu"
+
+	log "github.com/cihub/seelog"
+)
+
+const (
+	statusLogWindowSize         = "50"
+	diagnoseLogWindowSize       = "1000"
+	introspectionRequestTimeout = 5 * time.Second
+	diagnoseBundleNameFormat    = "ecs-init-diagnose-%s.tar.gz"
+	diagnoseTimestampFormat     = "20060102T150405Z"
+	diagnoseBundleFilePerm      = 0600
+)
+
+// Injection points for testing purposes
+var (
+	introspectionMetadataURL = "http://localhost:51678/v1/metadata"
+	gpuInfoFilePath          = gpu.NvidiaGPUInfoFilePath
+)
+
+// diagnosticCommand is an external command whose output is included in the
+// support bundle created by Diagnose
+type diagnosticCommand struct {
+	fileName string
+	name     string
+	args     []string
+}
+
+// bundleFile is a file in the support bundle created by Diagnose
+type bundleFile struct {
+	name    string
+	content []byte
+}
+
+// diagnosticCommands captures the host state configured during pre-start
+var diagnosticCommands = []diagnosticCommand{
+	{
+		fileName: "iptables-nat.txt",
+		name:     "iptables",
+		args:     []string{"-t", "nat", "-S"},
+	},
+	{
+		fileName: "iptables-filter.txt",
+		name:     "iptables",
+		args:     []string{"-t", "filter", "-S"},
+	},
+	{
+		fileName: "sysctl.txt",
+		name:     "sysctl",
+		args: []string{
+			"net.ipv4.conf.all.route_localnet",
+			"net.ipv4.conf.default.route_localnet",
+			"net.ipv6.conf.docker0.accept_ra",
+		},
+	},
+}
+
+// Status prints whether the Agent image is cached, whether the Agent
+// container is running, the Agent's introspection health and the tail of the
+// Agent container's logs. An error is returned when the Agent container isn't
+// running or its introspection server is unhealthy, so that the status action
+// exits with a non-zero code.
+func (e *Engine) Status() error {
+	docker, err := getDockerClient()
+	if err != nil {
+		return dockerError(err)
+	}
+	if err := e.writeStatus(os.Stdout, docker, statusLogWindowSize); err != nil {
+		return engineError("agent is unhealthy", err)
+	}
+	return nil
+}
+
+// Diagnose gathers the output of Status along with the iptables, sysctl and
+// GPU state set up during pre-start into a support bundle tarball in the log
+// directory
+func (e *Engine) Diagnose() error {
+	docker, err := getDockerClient()
+	if err != nil {
+		return dockerError(err)
+	}
+	bundlePath, err := e.createSupportBundle(config.LogDirectory(), docker, time.Now())
+	if err != nil {
+		return engineError("could not create support bundle", err)
+	}
+	fmt.Printf("Support bundle written to %s\n", bundlePath)
+	return nil
+}
+
+// writeStatus writes the status of the Agent to w and returns an error if the
+// Agent container isn't running or its introspection server is unhealthy
+func (e *Engine) writeStatus(w io.Writer, docker dockerClient, logWindowSize string) error {
+	fmt.Fprintf(w, "Agent image cache: %s\n", cacheStatusName(e.downloader.AgentCacheStatus()))
+
+	var statusErr error
+	running, err := docker.IsAgentRunning()
+	switch {
+	case err != nil:
+		fmt.Fprintf(w, "Agent container: unknown (%v)\n", err)
+		statusErr = fmt.Errorf("unable to determine whether the agent container is running: %w", err)
+	case running:
+		fmt.Fprintln(w, "Agent container: running")
+	default:
+		fmt.Fprintln(w, "Agent container: not running")
+		statusErr = errors.New("agent container is not running")
+	}
+
+	if err := checkIntrospectionHealth(); err != nil {
+		fmt.Fprintf(w, "Agent introspection: unhealthy (%v)\n", err)
+		if statusErr == nil {
+			statusErr = fmt.Errorf("agent introspection is unhealthy: %w", err)
+		}
+	} else {
+		fmt.Fprintln(w, "Agent introspection: healthy")
+	}
+
+	fmt.Fprintln(w, "Agent container log tail:")
+	fmt.Fprintln(w, docker.GetContainerLogTail(logWindowSize))
+	return statusErr
+}
+
+func cacheStatusName(status cache.CacheStatus) string {
+	switch status {
+	case cache.StatusUncached:
+		return "uncached"
+	case cache.StatusCached:
+		return "cached"
+	case cache.StatusReloadNeeded:
+		return "reload needed"
+	default:
+		return fmt.Sprintf("unknown (%d)", status)
+	}
+}
+
+// checkIntrospectionHealth returns an error if the Agent's introspection
+// server doesn't respond successfully to a metadata request
+func checkIntrospectionHealth() error {
+	client := &http.Client{Timeout: introspectionRequestTimeout}
+	resp, err := client.Get(introspectionMetadataURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// createSupportBundle writes the support bundle to dir and returns its path.
+// Failures to gather individual pieces of state are recorded in the bundle
+// rather than aborting its creation.
+func (e *Engine) createSupportBundle(dir string, docker dockerClient, now time.Time) (string, error) {
+	bundlePath := filepath.Join(dir, fmt.Sprintf(diagnoseBundleNameFormat, now.UTC().Format(diagnoseTimestampFormat)))
+	file, err := os.OpenFile(bundlePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, diagnoseBundleFilePerm)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	var status bytes.Buffer
+	// An unhealthy Agent is reported in the status file; it's what the bundle is for
+	e.writeStatus(&status, docker, diagnoseLogWindowSize)
+	files := []bundleFile{{name: "status.txt", content: status.Bytes()}}
+	for _, command := range diagnosticCommands {
+		files = append(files, bundleFile{name: command.fileName, content: e.runDiagnosticCommand(command)})
+	}
+	files = append(files, bundleFile{name: "gpu.txt", content: gpuState(docker)})
+
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    f.name,
+			Mode:    diagnoseBundleFilePerm,
+			Size:    int64(len(f.content)),
+			ModTime: now,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return "", err
+		}
+		if _, err := tarWriter.Write(f.content); err != nil {
+			return "", err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return "", err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return "", err
+	}
+	return bundlePath, nil
+}
+
+func (e *Engine) runDiagnosticCommand(command diagnosticCommand) []byte {
+	commandLine := strings.Join(append([]string{command.name}, command.args...), " ")
+	out, err := e.cmdExec.Command(command.name, command.args...).CombinedOutput()
+	if err != nil {
+		log.Warnf("diagnose: error running '%s': %v", commandLine, err)
+		return []byte(fmt.Sprintf("$ %s\n%s\nerror: %v\n", commandLine, out, err))
+	}
+	return []byte(fmt.Sprintf("$ %s\n%s", commandLine, out))
+}
+
+// gpuState returns whether GPU support is enabled and the GPU information
+// saved during pre-start
+func gpuState(docker dockerClient) []byte {
+	var state bytes.Buffer
+	enabled := docker.LoadEnvVars()[config.GPUSupportEnvVar] == "true"
+	fmt.Fprintf(&state, "%s: %t\n", config.GPUSupportEnvVar, enabled)
+	info, err := os.ReadFile(gpuInfoFilePath)
+	if err != nil {
+		fmt.Fprintf(&state, "%s: %v\n", gpuInfoFilePath, err)
+		return state.Bytes()
+	}
+	fmt.Fprintf(&state, "%s:\n%s\n", gpuInfoFilePath, info)
+	return state.Bytes()
+}
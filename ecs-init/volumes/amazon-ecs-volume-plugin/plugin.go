@@ -17,13 +17,19 @@ import (
 	"os"
 	"os/user"
 	"strconv"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-init/volumes"
 	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/logger"
 	"github.com/cihub/seelog"
 	"github.com/docker/go-plugins-helpers/volume"
+	docker "github.com/fsouza/go-dockerclient"
 )
 
+// dockerClientTimeout bounds the time the plugin waits for Docker when reconciling its state
+// on startup, as Docker might itself be waiting for the plugin.
+const dockerClientTimeout = 10 * time.Second
+
 func main() {
 	plugin := volumes.NewAmazonECSVolumePlugin()
 	logger.Setup()
@@ -31,9 +37,24 @@ func main() {
 	if err := plugin.LoadState(); err != nil {
 		os.Exit(1)
 	}
+	if _, err := plugin.Reconcile(newDockerClient()); err != nil {
+		seelog.Errorf("Could not reconcile plugin state: %v", err)
+	}
 	handler := volume.NewHandler(plugin)
 	rootUser, _ := user.Lookup("root")
 	gid, _ := strconv.Atoi(rootUser.Gid)
 	seelog.Info("Starting volume plugin..")
 	handler.ServeUnix("amazon-ecs-volume-plugin", gid)
 }
+
+// newDockerClient returns the client used to find the containers using the volumes of the
+// plugin, or nil if Docker cannot be reached.
+func newDockerClient() volumes.DockerClient {
+	client, err := docker.NewClientFromEnv()
+	if err != nil {
+		seelog.Warnf("Could not create docker client: %v", err)
+		return nil
+	}
+	client.SetTimeout(dockerClientTimeout)
+	return client
+}
@@ -33,6 +33,13 @@ type VolumeDriver interface {
 	IsMounted(volumeName string) bool
 }
 
+// VolumeDeleter is implemented by volume drivers that keep the data of a volume on the host
+// while it is not mounted. The data is deleted when the volume is removed.
+type VolumeDeleter interface {
+	// Delete deletes the data of the volume from the host.
+	Delete(deleteRequest *DeleteRequest) error
+}
+
 // CreateRequest holds fields necessary for creating a volume
 type CreateRequest struct {
 	Name    string
@@ -44,3 +51,8 @@ type CreateRequest struct {
 type RemoveRequest struct {
 	Name string
 }
+
+// DeleteRequest holds fields necessary for deleting the data of a volume
+type DeleteRequest struct {
+	Name string
+}
@@ -101,3 +101,40 @@ func (mr *MockVolumeDriverMockRecorder) Setup(volumeName, volume interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Setup", reflect.TypeOf((*MockVolumeDriver)(nil).Setup), volumeName, volume)
 }
+
+// MockVolumeDeleter is a mock of VolumeDeleter interface.
+type MockVolumeDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockVolumeDeleterMockRecorder
+}
+
+// MockVolumeDeleterMockRecorder is the mock recorder for MockVolumeDeleter.
+type MockVolumeDeleterMockRecorder struct {
+	mock *MockVolumeDeleter
+}
+
+// NewMockVolumeDeleter creates a new mock instance.
+func NewMockVolumeDeleter(ctrl *gomock.Controller) *MockVolumeDeleter {
+	mock := &MockVolumeDeleter{ctrl: ctrl}
+	mock.recorder = &MockVolumeDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVolumeDeleter) EXPECT() *MockVolumeDeleterMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockVolumeDeleter) Delete(deleteRequest *driver.DeleteRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", deleteRequest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockVolumeDeleterMockRecorder) Delete(deleteRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockVolumeDeleter)(nil).Delete), deleteRequest)
+}
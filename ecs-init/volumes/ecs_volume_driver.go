@@ -37,13 +37,16 @@ const (
 // ECSVolumeDriver holds mount helper and methods for different Volume Mounts
 type ECSVolumeDriver struct {
 	volumeMounts map[string]*MountHelper
-	lock         sync.RWMutex
+	// newMountHelper builds the mount helper of a volume from the volume options
+	newMountHelper func(options map[string]string) *MountHelper
+	lock           sync.RWMutex
 }
 
 // NewECSVolumeDriver initializes fields for volume mounts
 func NewECSVolumeDriver() *ECSVolumeDriver {
 	return &ECSVolumeDriver{
-		volumeMounts: make(map[string]*MountHelper),
+		volumeMounts:   make(map[string]*MountHelper),
+		newMountHelper: setOptions,
 	}
 }
 
@@ -55,7 +58,7 @@ func (e *ECSVolumeDriver) Setup(name string, v *types.Volume) {
 	if _, ok := e.volumeMounts[name]; ok {
 		seelog.Warnf("Volume %s mount already exists", name)
 	}
-	mnt := setOptions(v.Options)
+	mnt := e.newMountHelper(v.Options)
 
 	mnt.Target = v.Path
 	e.volumeMounts[name] = mnt
@@ -66,7 +69,7 @@ func (e *ECSVolumeDriver) Create(r *driver.CreateRequest) error {
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
-	mnt := setOptions(r.Options)
+	mnt := e.newMountHelper(r.Options)
 	mnt.Target = r.Path
 
 	seelog.Infof("Validating create options for volume %s", r.Name)
@@ -108,8 +111,7 @@ func (e *ECSVolumeDriver) Remove(req *driver.RemoveRequest) error {
 	}
 	err := mnt.Unmount()
 	if err != nil {
-		if strings.Contains(err.Error(), notMountedErrMsg) ||
-			strings.Contains(err.Error(), noMountPointSpecifiedErrMsg) {
+		if isNotMountedError(err) {
 			seelog.Infof("Unmounting volume %s failed because it's not mounted.", req.Name)
 			delete(e.volumeMounts, req.Name)
 			return nil
@@ -121,6 +123,12 @@ func (e *ECSVolumeDriver) Remove(req *driver.RemoveRequest) error {
 	return err
 }
 
+// isNotMountedError returns true if unmounting failed because the target is not mounted.
+func isNotMountedError(err error) bool {
+	return strings.Contains(err.Error(), notMountedErrMsg) ||
+		strings.Contains(err.Error(), noMountPointSpecifiedErrMsg)
+}
+
 // Method to check if a volume is currently mounted.
 func (e *ECSVolumeDriver) IsMounted(volumeName string) bool {
 	e.lock.RLock()
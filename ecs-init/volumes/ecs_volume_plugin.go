@@ -45,7 +45,10 @@ type AmazonECSVolumePlugin struct {
 func NewAmazonECSVolumePlugin() *AmazonECSVolumePlugin {
 	plugin := &AmazonECSVolumePlugin{
 		volumeDrivers: map[string]driver.VolumeDriver{
-			"efs": NewECSVolumeDriver(),
+			"efs":              NewECSVolumeDriver(),
+			NFSDriverType:      NewNFSVolumeDriver(),
+			TmpfsDriverType:    NewTmpfsVolumeDriver(),
+			LoopbackDriverType: NewLoopbackVolumeDriver(),
 		},
 		volumes: make(map[string]*types.Volume),
 		state:   NewStateManager(),
@@ -359,6 +362,14 @@ func (a *AmazonECSVolumePlugin) Remove(r *volume.RemoveRequest) error {
 		}
 	}
 
+	// delete the data the volume driver keeps on the host while the volume is not mounted
+	if deleter, ok := volDriver.(driver.VolumeDeleter); ok {
+		if err := deleter.Delete(&driver.DeleteRequest{Name: r.Name}); err != nil {
+			seelog.Errorf("Volume %s data deletion failure: %v", r.Name, err)
+			return err
+		}
+	}
+
 	// remove the volume information
 	delete(a.volumes, r.Name)
 	// cleanup the volume's host mount path
@@ -357,6 +357,52 @@ func TestVolumeRemoveHappyPath(t *testing.T) {
 	assert.Len(t, plugin.state.VolState.Volumes, 0)
 }
 
+func TestVolumeRemoveDeletesLoopbackImage(t *testing.T) {
+	volName := "vol"
+	path := VolumeMountPathPrefix + volName
+	vol := &types.Volume{
+		Path:    path,
+		Type:    LoopbackDriverType,
+		Options: map[string]string{"size": "1g"},
+	}
+	plugin := &AmazonECSVolumePlugin{
+		volumeDrivers: map[string]driver.VolumeDriver{
+			LoopbackDriverType: NewLoopbackVolumeDriver(),
+		},
+		volumes: map[string]*types.Volume{
+			volName: vol,
+		},
+		state: NewStateManager(),
+	}
+	var removedImage string
+	removeLoopbackImage = func(path string) error {
+		removedImage = path
+		return nil
+	}
+	removeMountPath = func(path string) error {
+		return nil
+	}
+	saveStateToDisk = func(b []byte) error {
+		return nil
+	}
+	defer func() {
+		removeLoopbackImage = deleteLoopbackImage
+		removeMountPath = deleteMountPath
+		saveStateToDisk = saveState
+	}()
+	assert.NoError(t, plugin.Remove(&volume.RemoveRequest{Name: volName}))
+	assert.Equal(t, LoopbackImagePathPrefix+"vol.img", removedImage)
+	assert.Len(t, plugin.volumes, 0)
+
+	// The volume is kept if its image cannot be deleted.
+	plugin.volumes[volName] = vol
+	removeLoopbackImage = func(path string) error {
+		return errors.New("device or resource busy")
+	}
+	assert.Error(t, plugin.Remove(&volume.RemoveRequest{Name: volName}))
+	assert.Len(t, plugin.volumes, 1)
+}
+
 func TestVolumeRemoveFailure(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
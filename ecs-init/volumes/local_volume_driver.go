@@ -0,0 +1,222 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/types"
+	"github.com/cihub/seelog"
+	"github.com/docker/go-units"
+)
+
+const (
+	// TmpfsDriverType is the volume type of local volumes backed by memory
+	TmpfsDriverType = "tmpfs"
+	// LoopbackDriverType is the volume type of local volumes backed by an image file
+	// on the host, which is attached to a loop device
+	LoopbackDriverType = "loopback"
+
+	// LoopbackImagePathPrefix is the host path where the image files of loopback volumes
+	// are stored
+	LoopbackImagePathPrefix = "/var/lib/ecs/volume-images/"
+	// MkfsBinary is the binary name used to format the image files of loopback volumes
+	MkfsBinary = "mkfs.ext4"
+
+	loopbackFileSystem = "ext4"
+	loopbackImageExt   = ".img"
+	sizeOption         = "size"
+)
+
+// LocalVolumeDriver holds the mount helpers of local scratch volumes, which are limited
+// to the size specified by the "size" volume option, such as "512m" or "2g". The data of
+// tmpfs volumes is lost when they are unmounted, whereas the data of loopback volumes is
+// kept until the volume is removed.
+type LocalVolumeDriver struct {
+	driverType   string
+	volumeMounts map[string]*MountHelper
+	lock         sync.RWMutex
+}
+
+// NewTmpfsVolumeDriver initializes the volume driver for tmpfs volumes
+func NewTmpfsVolumeDriver() *LocalVolumeDriver {
+	return &LocalVolumeDriver{
+		driverType:   TmpfsDriverType,
+		volumeMounts: make(map[string]*MountHelper),
+	}
+}
+
+// NewLoopbackVolumeDriver initializes the volume driver for loopback volumes
+func NewLoopbackVolumeDriver() *LocalVolumeDriver {
+	return &LocalVolumeDriver{
+		driverType:   LoopbackDriverType,
+		volumeMounts: make(map[string]*MountHelper),
+	}
+}
+
+// Setup creates the mount helper
+func (l *LocalVolumeDriver) Setup(name string, v *types.Volume) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, ok := l.volumeMounts[name]; ok {
+		seelog.Warnf("Volume %s mount already exists", name)
+	}
+	mnt, _, err := l.mountHelper(name, v.Path, v.Options)
+	if err != nil {
+		seelog.Warnf("Volume %s has invalid options: %v", name, err)
+		return
+	}
+	l.volumeMounts[name] = mnt
+}
+
+// Create implements LocalVolumeDriver's Create volume method
+func (l *LocalVolumeDriver) Create(r *driver.CreateRequest) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	seelog.Infof("Validating create options for volume %s", r.Name)
+	mnt, size, err := l.mountHelper(r.Name, r.Path, r.Options)
+	if err != nil {
+		return err
+	}
+	if err := mnt.Validate(); err != nil {
+		return err
+	}
+
+	if l.driverType == LoopbackDriverType {
+		if err := prepareLoopbackImage(mnt.Device, size); err != nil {
+			return fmt.Errorf("preparing loopback image failed: %v", err)
+		}
+	}
+
+	seelog.Infof("Mounting volume %s of type %s at path %s", r.Name, l.driverType, mnt.Target)
+	if err := mnt.Mount(); err != nil {
+		return fmt.Errorf("mounting volume failed: %v", err)
+	}
+	l.volumeMounts[r.Name] = mnt
+	return nil
+}
+
+// mountHelper returns the mount helper of a volume along with the size limit of the volume
+// in bytes.
+func (l *LocalVolumeDriver) mountHelper(name, target string, options map[string]string) (*MountHelper, int64, error) {
+	sizeValue, ok := options[sizeOption]
+	if !ok {
+		return nil, 0, fmt.Errorf("missing required fields: [%s]", sizeOption)
+	}
+	size, err := units.RAMInBytes(sizeValue)
+	if err != nil || size <= 0 {
+		return nil, 0, fmt.Errorf("invalid size %q", sizeValue)
+	}
+
+	mnt := &MountHelper{
+		Target: target,
+	}
+	switch l.driverType {
+	case TmpfsDriverType:
+		mnt.MountType = TmpfsDriverType
+		mnt.Device = TmpfsDriverType
+		mnt.Options = fmt.Sprintf("size=%d", size)
+	case LoopbackDriverType:
+		mnt.MountType = loopbackFileSystem
+		mnt.Device = loopbackImagePath(name)
+		mnt.Options = "loop"
+	}
+	if o := options["o"]; o != "" {
+		mnt.Options += "," + o
+	}
+	return mnt, size, nil
+}
+
+func loopbackImagePath(name string) string {
+	return filepath.Join(LoopbackImagePathPrefix, name+loopbackImageExt)
+}
+
+var prepareLoopbackImage = createLoopbackImage
+
+// createLoopbackImage creates a sparse image file of the given size and formats it, unless
+// the image already exists because the volume was mounted before.
+func createLoopbackImage(path string, size int64) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), FilePerm); err != nil {
+		return err
+	}
+	image, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, FilePerm)
+	if err != nil {
+		return err
+	}
+	err = image.Truncate(size)
+	image.Close()
+	if err == nil {
+		err = runCmd(exec.Command(MkfsBinary, "-q", "-F", path))
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// Remove implements LocalVolumeDriver's Remove volume method
+func (l *LocalVolumeDriver) Remove(req *driver.RemoveRequest) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	mnt, ok := l.volumeMounts[req.Name]
+	if !ok {
+		return fmt.Errorf("volume not found")
+	}
+	if err := mnt.Unmount(); err != nil {
+		if !isNotMountedError(err) {
+			return fmt.Errorf("unmounting volume failed: %v", err)
+		}
+		seelog.Infof("Unmounting volume %s failed because it's not mounted.", req.Name)
+	}
+	delete(l.volumeMounts, req.Name)
+	seelog.Infof("Unmounted volume %s successfully.", req.Name)
+	return nil
+}
+
+// Delete deletes the image file of a loopback volume. It is a no-op for tmpfs volumes.
+func (l *LocalVolumeDriver) Delete(req *driver.DeleteRequest) error {
+	if l.driverType != LoopbackDriverType {
+		return nil
+	}
+	seelog.Infof("Deleting image of volume %s", req.Name)
+	return removeLoopbackImage(loopbackImagePath(req.Name))
+}
+
+var removeLoopbackImage = deleteLoopbackImage
+
+func deleteLoopbackImage(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsMounted checks if a volume is currently mounted
+func (l *LocalVolumeDriver) IsMounted(volumeName string) bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	_, exists := l.volumeMounts[volumeName]
+	return exists
+}
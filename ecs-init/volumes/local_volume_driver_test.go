@@ -0,0 +1,166 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTmpfsVolumeDriverCreate(t *testing.T) {
+	var args []string
+	runMount = func(a []string) error {
+		args = a
+		return nil
+	}
+	defer func() {
+		runMount = runMountCommand
+	}()
+	l := NewTmpfsVolumeDriver()
+	require.NoError(t, l.Create(&driver.CreateRequest{
+		Name: "vol",
+		Path: VolumeMountPathPrefix + "vol",
+		Options: map[string]string{
+			"type": TmpfsDriverType,
+			"size": "64m",
+			"o":    "mode=0755",
+		},
+	}))
+	assert.Equal(t, []string{"-t", "tmpfs", "-o", "size=67108864,mode=0755", "tmpfs", VolumeMountPathPrefix + "vol"}, args)
+	assert.True(t, l.IsMounted("vol"))
+}
+
+func TestLoopbackVolumeDriverCreate(t *testing.T) {
+	var args []string
+	var imagePath string
+	var imageSize int64
+	runMount = func(a []string) error {
+		args = a
+		return nil
+	}
+	prepareLoopbackImage = func(path string, size int64) error {
+		imagePath, imageSize = path, size
+		return nil
+	}
+	defer func() {
+		runMount = runMountCommand
+		prepareLoopbackImage = createLoopbackImage
+	}()
+	l := NewLoopbackVolumeDriver()
+	require.NoError(t, l.Create(&driver.CreateRequest{
+		Name: "vol",
+		Path: VolumeMountPathPrefix + "vol",
+		Options: map[string]string{
+			"type": LoopbackDriverType,
+			"size": "1g",
+		},
+	}))
+	assert.Equal(t, LoopbackImagePathPrefix+"vol.img", imagePath)
+	assert.Equal(t, int64(1<<30), imageSize)
+	assert.Equal(t, []string{"-t", "ext4", "-o", "loop", imagePath, VolumeMountPathPrefix + "vol"}, args)
+	assert.True(t, l.IsMounted("vol"))
+}
+
+func TestLoopbackVolumeDriverCreateImageFailure(t *testing.T) {
+	prepareLoopbackImage = func(string, int64) error {
+		return errors.New("no space left on device")
+	}
+	defer func() {
+		prepareLoopbackImage = createLoopbackImage
+	}()
+	l := NewLoopbackVolumeDriver()
+	assert.Error(t, l.Create(&driver.CreateRequest{
+		Name:    "vol",
+		Path:    VolumeMountPathPrefix + "vol",
+		Options: map[string]string{"size": "1g"},
+	}))
+	assert.False(t, l.IsMounted("vol"))
+}
+
+func TestLocalVolumeDriverCreateInvalidSize(t *testing.T) {
+	tcs := []struct {
+		name    string
+		options map[string]string
+	}{
+		{
+			name:    "missing size",
+			options: map[string]string{},
+		},
+		{
+			name:    "invalid size",
+			options: map[string]string{"size": "big"},
+		},
+		{
+			name:    "zero size",
+			options: map[string]string{"size": "0"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewTmpfsVolumeDriver()
+			assert.Error(t, l.Create(&driver.CreateRequest{
+				Name:    "vol",
+				Path:    VolumeMountPathPrefix + "vol",
+				Options: tc.options,
+			}))
+			assert.False(t, l.IsMounted("vol"))
+		})
+	}
+}
+
+func TestLocalVolumeDriverSetupAndRemove(t *testing.T) {
+	l := NewLoopbackVolumeDriver()
+	l.Setup("vol", &types.Volume{
+		Type:    LoopbackDriverType,
+		Path:    VolumeMountPathPrefix + "vol",
+		Options: map[string]string{"size": "1g"},
+	})
+	require.True(t, l.IsMounted("vol"))
+
+	lookPath = func(string) (string, error) {
+		return "path", nil
+	}
+	runUnmount = func(string, string) error {
+		return errors.New("umount: " + VolumeMountPathPrefix + "vol: not mounted")
+	}
+	defer func() {
+		lookPath = getPath
+		runUnmount = runUnmountCommand
+	}()
+	assert.NoError(t, l.Remove(&driver.RemoveRequest{Name: "vol"}))
+	assert.False(t, l.IsMounted("vol"))
+	assert.Error(t, l.Remove(&driver.RemoveRequest{Name: "vol"}))
+}
+
+func TestLocalVolumeDriverDelete(t *testing.T) {
+	var removedPath string
+	removeLoopbackImage = func(path string) error {
+		removedPath = path
+		return nil
+	}
+	defer func() {
+		removeLoopbackImage = deleteLoopbackImage
+	}()
+
+	assert.NoError(t, NewTmpfsVolumeDriver().Delete(&driver.DeleteRequest{Name: "vol"}))
+	assert.Empty(t, removedPath)
+
+	assert.NoError(t, NewLoopbackVolumeDriver().Delete(&driver.DeleteRequest{Name: "vol"}))
+	assert.Equal(t, LoopbackImagePathPrefix+"vol.img", removedPath)
+}
@@ -0,0 +1,43 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+const (
+	// NFSDriverType is the volume type of NFS volumes
+	NFSDriverType = "nfs"
+	// nfsMountType is the file system type NFS volumes are mounted with
+	nfsMountType = "nfs4"
+	// defaultNFSMountOptions are the options NFS volumes are mounted with unless the volume
+	// specifies its own. These are the options recommended for NFSv4.1 shares by EFS, which
+	// also suit most NFSv4 servers.
+	defaultNFSMountOptions = "vers=4.1,rsize=1048576,wsize=1048576,hard,timeo=600,retrans=2,noresvport"
+)
+
+// NewNFSVolumeDriver initializes the volume driver for NFSv4 volumes. The device of an
+// NFS volume is the export of the NFS server, such as "nfs.example.com:/export".
+func NewNFSVolumeDriver() *ECSVolumeDriver {
+	return &ECSVolumeDriver{
+		volumeMounts:   make(map[string]*MountHelper),
+		newMountHelper: nfsMountHelper,
+	}
+}
+
+func nfsMountHelper(options map[string]string) *MountHelper {
+	mnt := setOptions(options)
+	mnt.MountType = nfsMountType
+	if mnt.Options == "" {
+		mnt.Options = defaultNFSMountOptions
+	}
+	return mnt
+}
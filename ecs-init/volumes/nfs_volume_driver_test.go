@@ -0,0 +1,100 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNFSVolumeDriverCreate(t *testing.T) {
+	tcs := []struct {
+		name         string
+		options      map[string]string
+		expectedArgs []string
+	}{
+		{
+			name: "default options",
+			options: map[string]string{
+				"type":   NFSDriverType,
+				"device": "nfs.example.com:/export",
+			},
+			expectedArgs: []string{"-t", "nfs4", "-o", defaultNFSMountOptions,
+				"nfs.example.com:/export", VolumeMountPathPrefix + "vol"},
+		},
+		{
+			name: "custom options",
+			options: map[string]string{
+				"type":   NFSDriverType,
+				"o":      "vers=4.0,ro",
+				"device": "nfs.example.com:/export",
+			},
+			expectedArgs: []string{"-t", "nfs4", "-o", "vers=4.0,ro",
+				"nfs.example.com:/export", VolumeMountPathPrefix + "vol"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var args []string
+			runMount = func(a []string) error {
+				args = a
+				return nil
+			}
+			defer func() {
+				runMount = runMountCommand
+			}()
+			n := NewNFSVolumeDriver()
+			require.NoError(t, n.Create(&driver.CreateRequest{
+				Name:    "vol",
+				Path:    VolumeMountPathPrefix + "vol",
+				Options: tc.options,
+			}))
+			assert.Equal(t, tc.expectedArgs, args)
+			assert.True(t, n.IsMounted("vol"))
+		})
+	}
+}
+
+func TestNFSVolumeDriverCreateMissingDevice(t *testing.T) {
+	n := NewNFSVolumeDriver()
+	err := n.Create(&driver.CreateRequest{
+		Name:    "vol",
+		Path:    VolumeMountPathPrefix + "vol",
+		Options: map[string]string{"type": NFSDriverType},
+	})
+	assert.Error(t, err)
+	assert.False(t, n.IsMounted("vol"))
+}
+
+func TestNFSVolumeDriverSetup(t *testing.T) {
+	n := NewNFSVolumeDriver()
+	n.Setup("vol", &types.Volume{
+		Type: NFSDriverType,
+		Path: VolumeMountPathPrefix + "vol",
+		Options: map[string]string{
+			"type":   NFSDriverType,
+			"device": "nfs.example.com:/export",
+		},
+	})
+	assert.Equal(t, &MountHelper{
+		MountType: nfsMountType,
+		Device:    "nfs.example.com:/export",
+		Target:    VolumeMountPathPrefix + "vol",
+		Options:   defaultNFSMountOptions,
+	}, n.volumeMounts["vol"])
+}
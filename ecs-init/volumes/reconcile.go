@@ -0,0 +1,287 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/cihub/seelog"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	// MountInfoPath is the path of the mount table of the plugin process
+	MountInfoPath = "/proc/self/mountinfo"
+
+	// mountPointField is the index of the mount point in a line of the mount table
+	mountPointField = 4
+)
+
+// DiscrepancyKind is the kind of inconsistency found between the recorded state of a volume
+// and the state of the host.
+type DiscrepancyKind string
+
+const (
+	// DiscrepancyLeakedMounts means that the volume has recorded mounts but no running
+	// container uses it, typically because Docker restarted without unmounting it.
+	DiscrepancyLeakedMounts DiscrepancyKind = "LeakedMounts"
+	// DiscrepancyMissingMount means that the volume has recorded mounts but is not mounted
+	// on the host, typically because the host rebooted.
+	DiscrepancyMissingMount DiscrepancyKind = "MissingMount"
+	// DiscrepancyStaleMount means that the volume is mounted on the host but has no recorded
+	// mounts and no running container uses it.
+	DiscrepancyStaleMount DiscrepancyKind = "StaleMount"
+	// DiscrepancyUntrackedUsers means that running containers use the volume but the number
+	// of recorded mounts of the volume doesn't match the number of containers.
+	DiscrepancyUntrackedUsers DiscrepancyKind = "UntrackedUsers"
+	// DiscrepancyUnknownMount means that a path under the volume mount path prefix is
+	// mounted on the host but belongs to no recorded volume.
+	DiscrepancyUnknownMount DiscrepancyKind = "UnknownMount"
+)
+
+const (
+	actionNone        = "none"
+	actionUnmounted   = "unmounted"
+	actionRemounted   = "remounted"
+	actionMountsFreed = "mounts released"
+)
+
+// Discrepancy is an inconsistency found by Reconcile along with the action taken to fix it.
+type Discrepancy struct {
+	Volume string
+	Path   string
+	Kind   DiscrepancyKind
+	Detail string
+	Action string
+}
+
+func (d Discrepancy) String() string {
+	return fmt.Sprintf("volume=%q path=%q kind=%s detail=%q action=%q", d.Volume, d.Path, d.Kind, d.Detail, d.Action)
+}
+
+// DockerClient lists the containers using the volumes of the plugin
+type DockerClient interface {
+	ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error)
+}
+
+// Reconcile compares the recorded volumes and their mounts with the mount table of the host
+// and with the running containers using the volumes, and fixes the inconsistencies left by
+// a crash of the plugin or of the host. It should be called after LoadState and before the
+// plugin starts serving requests. The docker client may be nil if Docker is unavailable, in
+// which case only the inconsistencies that don't depend on the users of the volumes are
+// fixed, and the others are only reported.
+func (a *AmazonECSVolumePlugin) Reconcile(client DockerClient) ([]Discrepancy, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	seelog.Info("Reconciling plugin state with the host")
+
+	mountInfo, err := readMountInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not read mount table: %v", err)
+	}
+	mountPoints := parseMountPoints(mountInfo)
+
+	var users map[string]int
+	if client != nil {
+		users, err = volumeUsers(client)
+		if err != nil {
+			seelog.Warnf("Could not list containers using volumes, reconciling with mount table only: %v", err)
+			users = nil
+		}
+	}
+
+	volNames := make([]string, 0, len(a.volumes))
+	for volName := range a.volumes {
+		volNames = append(volNames, volName)
+	}
+	sort.Strings(volNames)
+
+	var discrepancies []Discrepancy
+	recordedPaths := make(map[string]bool)
+	for _, volName := range volNames {
+		vol := a.volumes[volName]
+		recordedPaths[filepath.Clean(vol.Path)] = true
+		volDriver, err := a.getVolumeDriver(vol.Type)
+		if err != nil {
+			seelog.Errorf("Could not reconcile volume %s: %v", volName, err)
+			continue
+		}
+		if d := a.reconcileVolume(volName, volDriver, mountPoints[filepath.Clean(vol.Path)], users); d != nil {
+			discrepancies = append(discrepancies, *d)
+		}
+	}
+
+	// Mounts under the plugin's mount path that belong to no volume can't be used through
+	// the plugin anymore.
+	var unknownMounts []string
+	for mountPoint := range mountPoints {
+		if strings.HasPrefix(mountPoint, VolumeMountPathPrefix) && !recordedPaths[mountPoint] {
+			unknownMounts = append(unknownMounts, mountPoint)
+		}
+	}
+	sort.Strings(unknownMounts)
+	for _, mountPoint := range unknownMounts {
+		d := Discrepancy{
+			Volume: strings.TrimPrefix(mountPoint, VolumeMountPathPrefix),
+			Path:   mountPoint,
+			Kind:   DiscrepancyUnknownMount,
+			Detail: "path is mounted but belongs to no volume",
+			Action: actionUnmounted,
+		}
+		if err := (&MountHelper{Target: mountPoint}).Unmount(); err != nil && !isNotMountedError(err) {
+			d.Action = fmt.Sprintf("unmount failed: %v", err)
+		}
+		discrepancies = append(discrepancies, d)
+	}
+
+	for _, d := range discrepancies {
+		seelog.Warnf("Volume plugin state discrepancy: %s", d)
+	}
+	seelog.Infof("Reconciled plugin state with the host, found %d discrepancies", len(discrepancies))
+	return discrepancies, nil
+}
+
+// reconcileVolume reconciles a volume given whether it is mounted on the host and the number
+// of running containers using each volume, which is nil if unknown. It returns the
+// discrepancy found, if any.
+func (a *AmazonECSVolumePlugin) reconcileVolume(
+	volName string,
+	volDriver driver.VolumeDriver,
+	mounted bool,
+	users map[string]int,
+) *Discrepancy {
+	vol := a.volumes[volName]
+	usersKnown := users != nil
+	volUsers := users[volName]
+	d := &Discrepancy{
+		Volume: volName,
+		Path:   vol.Path,
+		Action: actionNone,
+	}
+
+	switch {
+	case len(vol.Mounts) > 0 && usersKnown && volUsers == 0:
+		d.Kind = DiscrepancyLeakedMounts
+		d.Detail = fmt.Sprintf("%d recorded mounts but no running container uses the volume", len(vol.Mounts))
+		if mounted {
+			if err := volDriver.Remove(&driver.RemoveRequest{Name: volName}); err != nil {
+				d.Action = fmt.Sprintf("unmount failed: %v", err)
+				return d
+			}
+		}
+		d.Action = actionMountsFreed
+		vol.Mounts = map[string]int{}
+		if err := a.state.recordVolume(volName, vol); err != nil {
+			seelog.Errorf("Error saving state of volume %s: %v", volName, err)
+		}
+	case len(vol.Mounts) > 0 && !mounted:
+		d.Kind = DiscrepancyMissingMount
+		d.Detail = fmt.Sprintf("%d recorded mounts but the volume is not mounted", len(vol.Mounts))
+		createReq := &driver.CreateRequest{Name: volName, Path: vol.Path, Options: vol.Options}
+		if err := volDriver.Create(createReq); err != nil {
+			d.Action = fmt.Sprintf("remount failed: %v", err)
+			return d
+		}
+		d.Action = actionRemounted
+	case len(vol.Mounts) == 0 && mounted:
+		d.Kind = DiscrepancyStaleMount
+		d.Detail = "volume is mounted but has no recorded mounts"
+		if !usersKnown {
+			d.Detail += ", users of the volume are unknown"
+			return d
+		}
+		if volUsers > 0 {
+			d.Kind = DiscrepancyUntrackedUsers
+			d.Detail = fmt.Sprintf("%d running containers use the volume but it has no recorded mounts", volUsers)
+			return d
+		}
+		// Register the mount with the driver first, in case it was never set up.
+		volDriver.Setup(volName, vol)
+		if err := volDriver.Remove(&driver.RemoveRequest{Name: volName}); err != nil {
+			d.Action = fmt.Sprintf("unmount failed: %v", err)
+			return d
+		}
+		d.Action = actionUnmounted
+	case usersKnown && volUsers != len(vol.Mounts):
+		d.Kind = DiscrepancyUntrackedUsers
+		d.Detail = fmt.Sprintf("%d running containers use the volume but it has %d recorded mounts",
+			volUsers, len(vol.Mounts))
+	default:
+		return nil
+	}
+	return d
+}
+
+// volumeUsers returns the number of running containers using each volume.
+func volumeUsers(client DockerClient) (map[string]int, error) {
+	containers, err := client.ListContainers(docker.ListContainersOptions{})
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[string]int)
+	for _, container := range containers {
+		for _, mount := range container.Mounts {
+			if mount.Name != "" {
+				users[mount.Name]++
+			}
+		}
+	}
+	return users, nil
+}
+
+var readMountInfo = readMountInfoFile
+
+func readMountInfoFile() ([]byte, error) {
+	return os.ReadFile(MountInfoPath)
+}
+
+// parseMountPoints returns the set of mount points of a mount table in the format of
+// /proc/<pid>/mountinfo.
+func parseMountPoints(mountInfo []byte) map[string]bool {
+	mountPoints := make(map[string]bool)
+	for _, line := range strings.Split(string(mountInfo), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) <= mountPointField {
+			continue
+		}
+		mountPoints[filepath.Clean(unescapeMountPoint(fields[mountPointField]))] = true
+	}
+	return mountPoints
+}
+
+// unescapeMountPoint decodes the octal escape sequences of white spaces and backslashes in
+// the mount points of the mount table.
+func unescapeMountPoint(mountPoint string) string {
+	if !strings.Contains(mountPoint, `\`) {
+		return mountPoint
+	}
+	var b strings.Builder
+	for i := 0; i < len(mountPoint); i++ {
+		if mountPoint[i] == '\\' && i+4 <= len(mountPoint) {
+			if c, err := strconv.ParseUint(mountPoint[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(mountPoint[i])
+	}
+	return b.String()
+}
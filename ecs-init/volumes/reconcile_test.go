@@ -0,0 +1,236 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	mock_driver "github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver/mock"
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/types"
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMountInfo = `22 1 202:1 / / rw,noatime shared:1 - xfs /dev/xvda1 rw
+100 22 0:50 / /var/lib/ecs/volumes/mounted rw,relatime shared:60 - nfs4 fs-123:/ rw,vers=4.1
+101 22 0:51 / /var/lib/ecs/volumes/stale rw,relatime shared:61 - nfs4 fs-123:/ rw,vers=4.1
+102 22 0:52 / /var/lib/ecs/volumes/in-use rw,relatime shared:62 - nfs4 fs-123:/ rw,vers=4.1
+103 22 0:53 / /var/lib/ecs/volumes/leaked rw,relatime shared:63 - nfs4 fs-123:/ rw,vers=4.1
+104 22 0:54 / /var/lib/ecs/volumes/unknown rw,relatime shared:64 - tmpfs tmpfs rw
+`
+
+// fakeDockerClient returns the configured containers when listing containers
+type fakeDockerClient struct {
+	containers []docker.APIContainers
+	err        error
+}
+
+func (f *fakeDockerClient) ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error) {
+	return f.containers, f.err
+}
+
+func containerUsing(volNames ...string) docker.APIContainers {
+	container := docker.APIContainers{}
+	for _, volName := range volNames {
+		container.Mounts = append(container.Mounts, docker.APIMount{Name: volName})
+	}
+	return container
+}
+
+func newReconcileTestPlugin(volDriver driver.VolumeDriver) *AmazonECSVolumePlugin {
+	return &AmazonECSVolumePlugin{
+		volumeDrivers: map[string]driver.VolumeDriver{
+			"efs": volDriver,
+		},
+		volumes: map[string]*types.Volume{
+			// consistent: mounted and used by a container
+			"mounted": {Type: "efs", Path: VolumeMountPathPrefix + "mounted", Mounts: map[string]int{"id1": 1}},
+			// consistent: not mounted and not used
+			"unused": {Type: "efs", Path: VolumeMountPathPrefix + "unused", Mounts: map[string]int{}},
+			// used by a container but not mounted after a reboot
+			"missing": {Type: "efs", Path: VolumeMountPathPrefix + "missing", Mounts: map[string]int{"id2": 1}},
+			// mounted without any recorded mount
+			"stale": {Type: "efs", Path: VolumeMountPathPrefix + "stale", Mounts: map[string]int{}},
+			// used by two containers but with a single recorded mount
+			"in-use": {Type: "efs", Path: VolumeMountPathPrefix + "in-use", Mounts: map[string]int{"id3": 1}},
+			// mounted with recorded mounts but not used anymore
+			"leaked": {Type: "efs", Path: VolumeMountPathPrefix + "leaked", Mounts: map[string]int{"id4": 1}},
+		},
+		state: NewStateManager(),
+	}
+}
+
+func setupReconcileTest(t *testing.T) {
+	readMountInfo = func() ([]byte, error) {
+		return []byte(testMountInfo), nil
+	}
+	saveStateToDisk = func(b []byte) error {
+		return nil
+	}
+	lookPath = func(string) (string, error) {
+		return "umount", nil
+	}
+	runUnmount = func(string, string) error {
+		return nil
+	}
+	t.Cleanup(func() {
+		readMountInfo = readMountInfoFile
+		saveStateToDisk = saveState
+		lookPath = getPath
+		runUnmount = runUnmountCommand
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	setupReconcileTest(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	volDriver := mock_driver.NewMockVolumeDriver(ctrl)
+	plugin := newReconcileTestPlugin(volDriver)
+
+	volDriver.EXPECT().Create(&driver.CreateRequest{Name: "missing", Path: VolumeMountPathPrefix + "missing"}).Return(nil)
+	volDriver.EXPECT().Setup("stale", plugin.volumes["stale"])
+	volDriver.EXPECT().Remove(&driver.RemoveRequest{Name: "stale"}).Return(nil)
+	volDriver.EXPECT().Remove(&driver.RemoveRequest{Name: "leaked"}).Return(nil)
+
+	client := &fakeDockerClient{
+		containers: []docker.APIContainers{
+			containerUsing("mounted", "missing", "in-use"),
+			containerUsing("in-use"),
+		},
+	}
+	discrepancies, err := plugin.Reconcile(client)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Discrepancy{
+		{
+			Volume: "in-use",
+			Path:   VolumeMountPathPrefix + "in-use",
+			Kind:   DiscrepancyUntrackedUsers,
+			Detail: "2 running containers use the volume but it has 1 recorded mounts",
+			Action: actionNone,
+		},
+		{
+			Volume: "leaked",
+			Path:   VolumeMountPathPrefix + "leaked",
+			Kind:   DiscrepancyLeakedMounts,
+			Detail: "1 recorded mounts but no running container uses the volume",
+			Action: actionMountsFreed,
+		},
+		{
+			Volume: "missing",
+			Path:   VolumeMountPathPrefix + "missing",
+			Kind:   DiscrepancyMissingMount,
+			Detail: "1 recorded mounts but the volume is not mounted",
+			Action: actionRemounted,
+		},
+		{
+			Volume: "stale",
+			Path:   VolumeMountPathPrefix + "stale",
+			Kind:   DiscrepancyStaleMount,
+			Detail: "volume is mounted but has no recorded mounts",
+			Action: actionUnmounted,
+		},
+		{
+			Volume: "unknown",
+			Path:   VolumeMountPathPrefix + "unknown",
+			Kind:   DiscrepancyUnknownMount,
+			Detail: "path is mounted but belongs to no volume",
+			Action: actionUnmounted,
+		},
+	}, discrepancies)
+	assert.Empty(t, plugin.volumes["leaked"].Mounts)
+	assert.Empty(t, plugin.state.VolState.Volumes["leaked"].Mounts)
+}
+
+func TestReconcileWithoutDocker(t *testing.T) {
+	setupReconcileTest(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	volDriver := mock_driver.NewMockVolumeDriver(ctrl)
+	plugin := newReconcileTestPlugin(volDriver)
+
+	// Only the missing mount is fixed, as the other volumes might be in use.
+	volDriver.EXPECT().Create(&driver.CreateRequest{Name: "missing", Path: VolumeMountPathPrefix + "missing"}).Return(nil)
+
+	discrepancies, err := plugin.Reconcile(&fakeDockerClient{err: errors.New("cannot connect to docker")})
+	require.NoError(t, err)
+
+	kinds := map[string]DiscrepancyKind{}
+	for _, d := range discrepancies {
+		kinds[d.Volume] = d.Kind
+	}
+	assert.Equal(t, map[string]DiscrepancyKind{
+		"missing": DiscrepancyMissingMount,
+		"stale":   DiscrepancyStaleMount,
+		"unknown": DiscrepancyUnknownMount,
+	}, kinds)
+	assert.Len(t, plugin.volumes["leaked"].Mounts, 1)
+}
+
+func TestReconcileRemountFailure(t *testing.T) {
+	setupReconcileTest(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	volDriver := mock_driver.NewMockVolumeDriver(ctrl)
+	plugin := &AmazonECSVolumePlugin{
+		volumeDrivers: map[string]driver.VolumeDriver{"efs": volDriver},
+		volumes: map[string]*types.Volume{
+			"missing": {Type: "efs", Path: VolumeMountPathPrefix + "missing", Mounts: map[string]int{"id": 1}},
+		},
+		state: NewStateManager(),
+	}
+	readMountInfo = func() ([]byte, error) {
+		return nil, nil
+	}
+	volDriver.EXPECT().Create(gomock.Any()).Return(errors.New("mount error"))
+
+	discrepancies, err := plugin.Reconcile(nil)
+	require.NoError(t, err)
+	require.Len(t, discrepancies, 1)
+	assert.Equal(t, DiscrepancyMissingMount, discrepancies[0].Kind)
+	assert.Equal(t, "remount failed: mount error", discrepancies[0].Action)
+}
+
+func TestReconcileMountInfoFailure(t *testing.T) {
+	readMountInfo = func() ([]byte, error) {
+		return nil, errors.New("no such file")
+	}
+	defer func() {
+		readMountInfo = readMountInfoFile
+	}()
+	plugin := NewAmazonECSVolumePlugin()
+	_, err := plugin.Reconcile(nil)
+	assert.Error(t, err)
+}
+
+func TestParseMountPoints(t *testing.T) {
+	mountPoints := parseMountPoints([]byte(testMountInfo +
+		`105 22 0:55 / /var/lib/ecs/volumes/with\040space rw - tmpfs tmpfs rw
+malformed line
+`))
+	assert.Equal(t, map[string]bool{
+		"/":                               true,
+		"/var/lib/ecs/volumes/mounted":    true,
+		"/var/lib/ecs/volumes/stale":      true,
+		"/var/lib/ecs/volumes/in-use":     true,
+		"/var/lib/ecs/volumes/leaked":     true,
+		"/var/lib/ecs/volumes/unknown":    true,
+		"/var/lib/ecs/volumes/with space": true,
+	}, mountPoints)
+}